@@ -0,0 +1,92 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cni-benchcmp compares two runs of the CNI library benchmarks and
+// fails if any benchmark regressed, so that slowdowns of the code on the
+// pod-start path are caught before a release:
+//
+//	go test -run '^$' -bench . -benchmem -count 5 ./libcni ./pkg/... > new.txt
+//	cni-benchcmp -tolerance 0.1 old.txt new.txt
+//
+// Each file is either the output of "go test -bench" or a JSON report
+// saved from pkg/bench.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/containernetworking/cni/pkg/bench"
+)
+
+func main() {
+	tolerance := flag.Float64("tolerance", 0.1, "fraction by which a benchmark may exceed the baseline")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <baseline> <new>\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 || *tolerance < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), flag.Arg(1), *tolerance); err != nil {
+		fmt.Fprintf(os.Stderr, "cni-benchcmp: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(baselinePath, newPath string, tolerance float64) error {
+	baseline, err := readReport(baselinePath)
+	if err != nil {
+		return err
+	}
+	report, err := readReport(newPath)
+	if err != nil {
+		return err
+	}
+	if err := report.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := report.Compare(baseline, tolerance); err != nil {
+		return fmt.Errorf("regressions against %s:\n%w", baselinePath, err)
+	}
+	return nil
+}
+
+// readReport reads a JSON report of pkg/bench, or the output of go test
+func readReport(path string) (*bench.Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	report := &bench.Report{}
+	if err := json.NewDecoder(f).Decode(report); err == nil {
+		return report, nil
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	report, err = bench.ParseGoTest(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,25 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cni-daemon-shim is the generic shim of daemon plugins. Installed
+// in CNI_PATH under a plugin's name, it forwards invocations to the daemon
+// listening on /run/cni/<name>.sock, or on the socket set by the
+// "daemonSocket" key of the network configuration.
+package main
+
+import "github.com/containernetworking/cni/pkg/daemon"
+
+func main() {
+	daemon.ShimMain("")
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cni-scaffold generates a ready-to-build CNI plugin project: the
+// skel wiring, a strictly parsed configuration, the supported versions,
+// stubs for every command and table-driven tests.
+//
+//	cni-scaffold -name my-plugin -module github.com/me/my-plugin
+//
+// It can also be run by go:generate:
+//
+//	//go:generate go run github.com/containernetworking/cni/cni-scaffold -name my-plugin -dir .
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	var p Project
+	flag.StringVar(&p.Name, "name", "", "name of the plugin binary (required)")
+	flag.StringVar(&p.Module, "module", "", "Go module path of the project (default \"example.com/<name>\")")
+	flag.StringVar(&p.Replace, "replace", "", "path of a local checkout of the CNI module to build against")
+	dir := flag.String("dir", "", "directory to generate the project in (default \"./<name>\")")
+	force := flag.Bool("force", false, "overwrite existing files")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -name <plugin> [flags]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if p.Name == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *dir == "" {
+		*dir = p.Name
+	}
+
+	written, err := p.Write(*dir, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cni-scaffold: %v\n", err)
+		os.Exit(1)
+	}
+	for _, path := range written {
+		fmt.Println(path)
+	}
+}
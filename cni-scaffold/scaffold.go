@@ -0,0 +1,120 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// pluginNameRegexp matches names usable as both a binary and a "type"
+var pluginNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Project describes the plugin project to generate
+type Project struct {
+	// Name is the plugin binary, and the "type" of its configurations
+	Name string
+	// Module is the Go module path of the project
+	Module string
+	// Replace, if set, points the CNI module at a local checkout
+	Replace string
+}
+
+// Validate checks the project and fills in the default module path
+func (p *Project) Validate() error {
+	if !pluginNameRegexp.MatchString(p.Name) {
+		return fmt.Errorf("invalid plugin name %q: must be lowercase letters, digits, '-' or '_'", p.Name)
+	}
+	if p.Module == "" {
+		p.Module = "example.com/" + p.Name
+	}
+	if strings.ContainsAny(p.Module, " \t\n\"") {
+		return fmt.Errorf("invalid module path %q", p.Module)
+	}
+	return nil
+}
+
+// Render returns the contents of the project's files, keyed by path
+func (p *Project) Render() (map[string][]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	tmpls, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, tmpl := range tmpls.Templates() {
+		buf := &bytes.Buffer{}
+		if err := tmpl.Execute(buf, p); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+		}
+		name := strings.TrimSuffix(tmpl.Name(), ".tmpl")
+		data := buf.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			if data, err = format.Source(data); err != nil {
+				return nil, fmt.Errorf("generated %s is invalid: %w", name, err)
+			}
+		}
+		files[name] = data
+	}
+	return files, nil
+}
+
+// Write renders the project into dir. Existing files are an error unless
+// overwrite is set, so that a project is not clobbered by mistake.
+func (p *Project) Write(dir string, overwrite bool) ([]string, error) {
+	files, err := p.Render()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !overwrite {
+		for _, name := range names {
+			if _, err := os.Lstat(filepath.Join(dir, name)); err == nil {
+				return nil, fmt.Errorf("%s already exists", filepath.Join(dir, name))
+			}
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	written := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, files[name], 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
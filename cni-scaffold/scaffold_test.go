@@ -0,0 +1,98 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Project", func() {
+	It("renders a plugin project", func() {
+		p := &Project{Name: "my-plugin"}
+		files, err := p.Render()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(HaveLen(4))
+		Expect(files).To(HaveKey("README.md"))
+
+		Expect(string(files["go.mod"])).To(HavePrefix("module example.com/my-plugin\n"))
+		Expect(string(files["go.mod"])).NotTo(ContainSubstring("replace"))
+		Expect(string(files["main.go"])).To(ContainSubstring(`"CNI my-plugin plugin"`))
+		Expect(string(files["main_test.go"])).To(ContainSubstring(`"type": "my-plugin"`))
+
+		fset := token.NewFileSet()
+		for _, name := range []string{"main.go", "main_test.go"} {
+			_, err := parser.ParseFile(fset, name, files[name], parser.AllErrors)
+			Expect(err).NotTo(HaveOccurred(), name)
+		}
+	})
+
+	It("points the CNI module at a local checkout", func() {
+		p := &Project{Name: "my-plugin", Module: "github.com/me/my-plugin", Replace: "../cni"}
+		files, err := p.Render()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(files["go.mod"])).To(Equal("module github.com/me/my-plugin\n\ngo 1.21\n\nreplace github.com/containernetworking/cni => ../cni\n"))
+	})
+
+	It("rejects invalid names", func() {
+		for _, name := range []string{"", "My-Plugin", "-plugin", "a/b"} {
+			p := &Project{Name: name}
+			Expect(p.Validate()).To(MatchError(ContainSubstring("invalid plugin name")), name)
+		}
+		p := &Project{Name: "ok", Module: "example.com/a b"}
+		Expect(p.Validate()).To(MatchError(ContainSubstring("invalid module path")))
+	})
+
+	Describe("Write", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "cni-scaffold")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes the project and does not overwrite it", func() {
+			p := &Project{Name: "my-plugin"}
+			target := filepath.Join(dir, "my-plugin")
+			written, err := p.Write(target, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(written).To(HaveLen(4))
+			Expect(filepath.Join(target, "main.go")).To(BeARegularFile())
+
+			Expect(os.WriteFile(filepath.Join(target, "main.go"), []byte("package main\n"), 0o644)).To(Succeed())
+			_, err = p.Write(target, false)
+			Expect(err).To(MatchError(ContainSubstring("already exists")))
+			data, err := os.ReadFile(filepath.Join(target, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("package main\n"))
+
+			_, err = p.Write(target, true)
+			Expect(err).NotTo(HaveOccurred())
+			data, err = os.ReadFile(filepath.Join(target, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("skel.PluginMainFuncs"))
+		})
+	})
+})
@@ -0,0 +1,85 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command cni-schemagen generates Go structs for the configuration of a
+// plugin from its JSON Schema, such as one written with the
+// pkg/types/schema package, so that meta-plugins and runtimes can decode
+// third-party plugin configurations into checked types.
+//
+//	cni-schemagen -in bridge.json -package bridgeconf -type NetConf -out bridgeconf/types.go
+//
+// The structs are validated after decoding with schema.ValidateStruct.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/containernetworking/cni/pkg/types/schema"
+)
+
+func main() {
+	var opts schema.GoOptions
+	in := flag.String("in", "-", "JSON Schema to read, or - for stdin")
+	out := flag.String("out", "-", "Go file to write, or - for stdout")
+	flag.StringVar(&opts.Package, "package", "", "package of the generated file (required)")
+	flag.StringVar(&opts.TypeName, "type", "NetConf", "name of the top-level struct")
+	flag.BoolVar(&opts.EmbedNetConf, "embed-netconf", true, "embed types.NetConf in place of the standard configuration keys")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s -package <name> [flags]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if opts.Package == "" || flag.NArg() > 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*in, *out, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "cni-schemagen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out string, opts schema.GoOptions) error {
+	var data []byte
+	var err error
+	if in == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(in)
+	}
+	if err != nil {
+		return err
+	}
+
+	s := &schema.Schema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("failed to parse schema: %w", err)
+	}
+	src, err := schema.GenerateGo(s, opts)
+	if err != nil {
+		return err
+	}
+
+	if out == "-" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/containernetworking/cni/libcni"
+)
+
+// manifest lists attachments to add, or delete, in one run of apply
+type manifest struct {
+	Attachments []manifestEntry `json:"attachments"`
+}
+
+type manifestEntry struct {
+	Network string `json:"network"`
+	NetNS   string `json:"netns"`
+	// IfName defaults to "eth0"
+	IfName         string                 `json:"ifName,omitempty"`
+	Args           map[string]string      `json:"args,omitempty"`
+	CapabilityArgs map[string]interface{} `json:"capabilityArgs,omitempty"`
+}
+
+// cniArgs returns the entry's args in CNI_ARGS order, sorted by key so
+// that runs are reproducible
+func (e *manifestEntry) cniArgs() [][2]string {
+	keys := make([]string, 0, len(e.Args))
+	for k := range e.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var args [][2]string
+	for _, k := range keys {
+		args = append(args, [2]string{k, e.Args[k]})
+	}
+	return args
+}
+
+// apply adds every attachment of a manifest in order or, with -del,
+// deletes them in reverse order. All entries are attempted; the outcome of
+// each is recorded in r.Entries.
+func apply(r *report, args []string) error {
+	fs := flag.NewFlagSet(CmdApply, flag.ExitOnError)
+	fs.Usage = usage
+	file := fs.String("f", "", "manifest file")
+	del := fs.Bool("del", false, "delete the attachments instead of adding them")
+	_ = fs.Parse(args)
+	if *file == "" {
+		usage()
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("failed to parse manifest %q: %w", *file, err)
+	}
+
+	command := CmdAdd
+	entries := m.Attachments
+	if *del {
+		command = CmdDel
+		entries = make([]manifestEntry, len(m.Attachments))
+		for i, e := range m.Attachments {
+			entries[len(entries)-1-i] = e
+		}
+	}
+
+	cninet := libcni.NewCNIConfig(filepath.SplitList(os.Getenv(EnvCNIPath)), nil)
+	failed := 0
+	for _, e := range entries {
+		ifName := e.IfName
+		if ifName == "" {
+			ifName = "eth0"
+		}
+		entry := &report{Command: command, Network: e.Network, NetNS: e.NetNS, IfName: ifName}
+		r.Entries = append(r.Entries, entry)
+
+		netconf, err := loadNetwork(e.Network)
+		if err == nil {
+			err = attach(cninet, entry, netconf, e.NetNS, ifName, e.cniArgs(), e.CapabilityArgs)
+		}
+		if err != nil {
+			entry.fail(err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d attachments failed", failed, len(entries))
+	}
+	return nil
+}
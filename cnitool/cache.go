@@ -0,0 +1,256 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// cacheEntry describes an attachment cached by libcni
+type cacheEntry struct {
+	Network     string `json:"network"`
+	ContainerID string `json:"containerId"`
+	IfName      string `json:"ifName"`
+	NetNS       string `json:"netns,omitempty"`
+	// Stale is whether the namespace of the attachment no longer exists
+	Stale          bool                   `json:"stale"`
+	CniArgs        [][2]string            `json:"cniArgs,omitempty"`
+	CapabilityArgs map[string]interface{} `json:"capabilityArgs,omitempty"`
+	Metadata       map[string]string      `json:"metadata,omitempty"`
+
+	// Config and Result are only set by cache show
+	Config json.RawMessage `json:"config,omitempty"`
+	Result types.Result    `json:"result,omitempty"`
+}
+
+func newCacheEntry(a *libcni.NetworkAttachment) *cacheEntry {
+	e := &cacheEntry{
+		Network:        a.Network,
+		ContainerID:    a.ContainerID,
+		IfName:         a.IfName,
+		NetNS:          a.NetNS,
+		CniArgs:        a.CniArgs,
+		CapabilityArgs: a.CapabilityArgs,
+		Metadata:       a.Metadata,
+	}
+	if a.NetNS != "" {
+		_, err := os.Stat(a.NetNS)
+		e.Stale = os.IsNotExist(err)
+	}
+	return e
+}
+
+// cacheCommand implements cache list, show, rm, export and import, which
+// inspect, clean up and move the attachments libcni caches in CacheDir
+func cacheCommand(r *report, args []string) error {
+	if len(args) < 1 {
+		usage()
+	}
+	r.Command = CmdCache + " " + args[0]
+	cninet := libcni.NewCNIConfig(filepath.SplitList(os.Getenv(EnvCNIPath)), nil)
+
+	switch args[0] {
+	case "list":
+		if len(args) > 2 {
+			usage()
+		}
+		attachments, err := cachedAttachments(cninet, "")
+		if err != nil {
+			return err
+		}
+		for _, a := range attachments {
+			if len(args) == 2 && a.Network != args[1] {
+				continue
+			}
+			r.Cache = append(r.Cache, newCacheEntry(a))
+		}
+		return nil
+	case "show":
+		if len(args) < 2 || len(args) > 3 {
+			usage()
+		}
+		attachments, err := selectAttachments(cninet, args[1], args[2:])
+		if err != nil {
+			return err
+		}
+		for _, a := range attachments {
+			e := newCacheEntry(a)
+			e.Config = a.Config
+			e.Result, err = cachedResult(cninet, a)
+			if err != nil {
+				return err
+			}
+			r.Cache = append(r.Cache, e)
+		}
+		return nil
+	case "rm":
+		return removeCached(cninet, r, args[1:])
+	case "export":
+		if len(args) != 2 {
+			usage()
+		}
+		return exportCache(cninet, r, args[1])
+	case "import":
+		return importCache(cninet, r, args[1:])
+	}
+	usage()
+	return nil
+}
+
+// removeCached removes the cached attachments of a container or, with
+// -stale, those of every container whose namespace no longer exists
+func removeCached(cninet *libcni.CNIConfig, r *report, args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ExitOnError)
+	fs.Usage = usage
+	stale := fs.Bool("stale", false, "remove the attachments whose namespace no longer exists")
+	_ = fs.Parse(args)
+	args = fs.Args()
+
+	var attachments []*libcni.NetworkAttachment
+	var err error
+	switch {
+	case *stale && len(args) <= 1:
+		attachments, err = cachedAttachments(cninet, "")
+	case !*stale && (len(args) == 1 || len(args) == 2):
+		attachments, err = selectAttachments(cninet, args[0], args[1:])
+	default:
+		usage()
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, a := range attachments {
+		e := newCacheEntry(a)
+		if *stale && (!e.Stale || (len(args) == 1 && a.Network != args[0])) {
+			continue
+		}
+		rt := &libcni.RuntimeConf{ContainerID: a.ContainerID, IfName: a.IfName}
+		if err := cninet.RemoveCachedAttachment(a.Network, rt); err != nil {
+			return err
+		}
+		r.Cache = append(r.Cache, e)
+	}
+	return nil
+}
+
+// exportCache writes the cached attachments to an archive file
+func exportCache(cninet *libcni.CNIConfig, r *report, file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	attachments, err := cninet.ExportCache(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(file)
+		return err
+	}
+	for _, a := range attachments {
+		r.Cache = append(r.Cache, newCacheEntry(a))
+	}
+	return nil
+}
+
+// importCache restores the attachments of an archive file written by
+// cache export
+func importCache(cninet *libcni.CNIConfig, r *report, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.Usage = usage
+	overwrite := fs.Bool("overwrite", false, "replace the attachments which are already cached")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	attachments, err := cninet.ImportCache(f, &libcni.CacheImportOptions{Overwrite: *overwrite})
+	if err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		r.Cache = append(r.Cache, newCacheEntry(a))
+	}
+	return nil
+}
+
+// cachedAttachments returns the cached attachments of a container, or of
+// all containers if containerID is empty
+func cachedAttachments(cninet *libcni.CNIConfig, containerID string) ([]*libcni.NetworkAttachment, error) {
+	attachments, err := cninet.GetCachedAttachments(containerID)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return attachments, err
+}
+
+// selectAttachments returns the cached attachments of a container, given
+// by its ID or, as for add, by the path of its namespace, optionally
+// restricted to one interface
+func selectAttachments(cninet *libcni.CNIConfig, container string, ifName []string) ([]*libcni.NetworkAttachment, error) {
+	if strings.ContainsRune(container, os.PathSeparator) {
+		netns, err := filepath.Abs(container)
+		if err != nil {
+			return nil, err
+		}
+		container = containerID(netns)
+	}
+
+	attachments, err := cachedAttachments(cninet, container)
+	if err != nil {
+		return nil, err
+	}
+	var selected []*libcni.NetworkAttachment
+	for _, a := range attachments {
+		if len(ifName) == 0 || a.IfName == ifName[0] {
+			selected = append(selected, a)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no cached attachment for container %q", container)
+	}
+	return selected, nil
+}
+
+// cachedResult loads the cached result of an attachment in the version of
+// its cached config
+func cachedResult(cninet *libcni.CNIConfig, a *libcni.NetworkAttachment) (types.Result, error) {
+	list, err := libcni.ConfListFromBytes(a.Config)
+	if err != nil {
+		conf, cerr := libcni.ConfFromBytes(a.Config)
+		if cerr != nil {
+			return nil, fmt.Errorf("failed to parse cached config of %s %s: %w", a.ContainerID, a.IfName, err)
+		}
+		if list, err = libcni.ConfListFromConf(conf); err != nil {
+			return nil, err
+		}
+	}
+	list.Name = a.Network
+	return cninet.GetNetworkListCachedResult(list, &libcni.RuntimeConf{ContainerID: a.ContainerID, IfName: a.IfName})
+}
@@ -18,12 +18,14 @@ import (
 	"context"
 	"crypto/sha512"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
 )
 
 // Protocol parameters are passed to the plugins via OS environment variables.
@@ -36,11 +38,22 @@ const (
 
 	DefaultNetDir = "/etc/cni/net.d"
 
-	CmdAdd    = "add"
-	CmdCheck  = "check"
-	CmdDel    = "del"
-	CmdGC     = "gc"
-	CmdStatus = "status"
+	// NetNSDir is where netns create pins namespaces, as iproute2 does
+	NetNSDir = "/var/run/netns"
+
+	OutputText = "text"
+	OutputJSON = "json"
+
+	CmdAdd      = "add"
+	CmdCheck    = "check"
+	CmdDel      = "del"
+	CmdGC       = "gc"
+	CmdStatus   = "status"
+	CmdVersions = "versions"
+	CmdApply    = "apply"
+	CmdCache    = "cache"
+	CmdNetNS    = "netns"
+	CmdConvert  = "convert"
 )
 
 func parseArgs(args string) ([][2]string, error) {
@@ -60,33 +73,126 @@ func parseArgs(args string) ([][2]string, error) {
 }
 
 func main() {
-	if len(os.Args) < 4 {
+	output := flag.String("output", OutputText, "output format, \"text\" or \"json\"")
+	dryRun := flag.Bool("dry-run", false, "print the plugin invocations of add or del instead of running them")
+	var rtFlags runtimeFlags
+	flag.Var(&rtFlags.args, "args", "add K=V to CNI_ARGS of add, check or del (repeatable)")
+	flag.Var(&rtFlags.capabilities, "capability", "pass the capability argument name=json to add, check or del (repeatable)")
+	flag.Usage = usage
+	flag.Parse()
+	if *output != OutputText && *output != OutputJSON {
+		usage()
+	}
+	args := flag.Args()
+	if *dryRun && (len(args) == 0 || (args[0] != CmdAdd && args[0] != CmdDel)) {
+		usage()
+	}
+	if !rtFlags.empty() && (len(args) == 0 || (args[0] != CmdAdd && args[0] != CmdCheck && args[0] != CmdDel)) {
+		usage()
+	}
+
+	if len(args) >= 1 && args[0] == CmdVersions {
+		survey := surveyVersions(filepath.SplitList(os.Getenv(EnvCNIPath)))
+		exit(printVersions(os.Stdout, survey, *output == OutputJSON))
+	}
+	if len(args) >= 1 && args[0] == CmdApply {
+		r := &report{Command: CmdApply}
+		r.fail(apply(r, args[1:]))
+		finish(r, *output)
+	}
+	if len(args) >= 1 && args[0] == CmdCache {
+		r := &report{Command: CmdCache}
+		r.fail(cacheCommand(r, args[1:]))
+		finish(r, *output)
+	}
+	if len(args) >= 1 && args[0] == CmdNetNS {
+		r := &report{Command: CmdNetNS}
+		r.fail(netnsCommand(r, args[1:]))
+		finish(r, *output)
+	}
+	if len(args) >= 1 && args[0] == CmdConvert {
+		r := &report{Command: CmdConvert}
+		r.fail(convert(r, args[1:]))
+		finish(r, *output)
+	}
+	if len(args) < 2 {
+		usage()
+	}
+	switch args[0] {
+	case CmdAdd, CmdCheck, CmdDel:
+		if len(args) < 3 {
+			usage()
+		}
+	case CmdGC, CmdStatus:
+	default:
 		usage()
 	}
 
+	r := &report{Command: args[0], Network: args[1], DryRun: *dryRun}
+	r.fail(run(r, args, &rtFlags))
+	finish(r, *output)
+}
+
+// run executes the command, recording its outcome in r
+func run(r *report, args []string, rtFlags *runtimeFlags) error {
+	netconf, err := loadNetwork(args[1])
+	if err != nil {
+		return err
+	}
+
+	ifName, cniArgs, capabilityArgs, err := runtimeFromEnv()
+	if err != nil {
+		return err
+	}
+	cniArgs, capabilityArgs = rtFlags.apply(cniArgs, capabilityArgs)
+
+	cninet := libcni.NewCNIConfig(filepath.SplitList(os.Getenv(EnvCNIPath)), nil)
+
+	switch args[0] {
+	case CmdGC:
+		gcArgs, err := validAttachments(cninet, netconf.Name, args[2:], ifName)
+		if err != nil {
+			return err
+		}
+		r.ValidAttachments = gcArgs.ValidAttachments
+		gcReport, err := cninet.GCNetworkListWithReport(context.TODO(), netconf, gcArgs)
+		if gcReport != nil {
+			r.Leaks = gcReport.ByContainer()
+		}
+		return r.gcResult(netconf, err)
+	case CmdStatus:
+		return r.status(cninet, netconf)
+	}
+
+	return attach(cninet, r, netconf, args[2], ifName, cniArgs, capabilityArgs)
+}
+
+func loadNetwork(name string) (*libcni.NetworkConfigList, error) {
 	netdir := os.Getenv(EnvNetDir)
 	if netdir == "" {
 		netdir = DefaultNetDir
 	}
-	netconf, err := libcni.LoadConfList(netdir, os.Args[2])
-	if err != nil {
-		exit(err)
-	}
+	return libcni.LoadConfList(netdir, name)
+}
 
+// runtimeFromEnv returns the interface name, CNI_ARGS and capability
+// arguments set in the environment
+func runtimeFromEnv() (string, [][2]string, map[string]interface{}, error) {
 	var capabilityArgs map[string]interface{}
 	capabilityArgsValue := os.Getenv(EnvCapabilityArgs)
 	if len(capabilityArgsValue) > 0 {
-		if err = json.Unmarshal([]byte(capabilityArgsValue), &capabilityArgs); err != nil {
-			exit(err)
+		if err := json.Unmarshal([]byte(capabilityArgsValue), &capabilityArgs); err != nil {
+			return "", nil, nil, err
 		}
 	}
 
 	var cniArgs [][2]string
-	args := os.Getenv(EnvCNIArgs)
-	if len(args) > 0 {
-		cniArgs, err = parseArgs(args)
+	cniArgsValue := os.Getenv(EnvCNIArgs)
+	if len(cniArgsValue) > 0 {
+		var err error
+		cniArgs, err = parseArgs(cniArgsValue)
 		if err != nil {
-			exit(err)
+			return "", nil, nil, err
 		}
 	}
 
@@ -94,56 +200,118 @@ func main() {
 	if !ok {
 		ifName = "eth0"
 	}
+	return ifName, cniArgs, capabilityArgs, nil
+}
 
-	netns := os.Args[3]
-	netns, err = filepath.Abs(netns)
+// attach runs add, check or del, as given by r.Command, for the attachment
+// of netns to the network
+func attach(cninet *libcni.CNIConfig, r *report, netconf *libcni.NetworkConfigList, netns, ifName string, cniArgs [][2]string, capabilityArgs map[string]interface{}) error {
+	netns, err := filepath.Abs(netns)
 	if err != nil {
-		exit(err)
+		return err
 	}
-
-	// Generate the containerid by hashing the netns path
-	s := sha512.Sum512([]byte(netns))
-	containerID := fmt.Sprintf("cnitool-%x", s[:10])
-
-	cninet := libcni.NewCNIConfig(filepath.SplitList(os.Getenv(EnvCNIPath)), nil)
+	r.NetNS = netns
 
 	rt := &libcni.RuntimeConf{
-		ContainerID:    containerID,
+		ContainerID:    containerID(netns),
 		NetNS:          netns,
 		IfName:         ifName,
 		Args:           cniArgs,
 		CapabilityArgs: capabilityArgs,
 	}
 
-	switch os.Args[1] {
+	switch r.Command {
 	case CmdAdd:
-		result, err := cninet.AddNetworkList(context.TODO(), netconf, rt)
-		if result != nil {
-			_ = result.Print()
+		if r.DryRun {
+			r.Plan, err = cninet.PlanAddNetworkList(netconf, rt)
+			return err
 		}
-		exit(err)
+		r.Result, err = cninet.AddNetworkList(context.TODO(), netconf, rt)
+		return err
 	case CmdCheck:
-		err := cninet.CheckNetworkList(context.TODO(), netconf, rt)
-		exit(err)
+		err = cninet.CheckNetworkList(context.TODO(), netconf, rt)
+		if err != nil {
+			var derr error
+			r.Drift, derr = drift(cninet, netconf, rt)
+			if derr != nil {
+				r.Errors = append(r.Errors, toError(fmt.Errorf("cannot compare with the cached result: %v", derr)))
+			}
+		}
+		return err
 	case CmdDel:
-		exit(cninet.DelNetworkList(context.TODO(), netconf, rt))
-	case CmdGC:
-		// Currently just invoke GC without args, hence all network interface should be GC'ed!
-		exit(cninet.GCNetworkList(context.TODO(), netconf, nil))
-	case CmdStatus:
-		exit(cninet.GetStatusNetworkList(context.TODO(), netconf))
+		if r.DryRun {
+			r.Plan, err = cninet.PlanDelNetworkList(netconf, rt)
+			return err
+		}
+		return cninet.DelNetworkList(context.TODO(), netconf, rt)
+	}
+	return fmt.Errorf("unknown command %q", r.Command)
+}
+
+// containerID generates the container ID of an attachment by hashing the
+// absolute path of its netns
+func containerID(netns string) string {
+	s := sha512.Sum512([]byte(netns))
+	return fmt.Sprintf("cnitool-%x", s[:10])
+}
+
+// validAttachments returns the attachments GC must keep: those of the given
+// namespaces or, when there are none, the cached attachments of the network
+// whose namespace still exists
+func validAttachments(cninet *libcni.CNIConfig, netName string, netnses []string, ifName string) (*libcni.GCArgs, error) {
+	args := &libcni.GCArgs{ValidAttachments: []types.GCAttachment{}}
+	if len(netnses) > 0 {
+		for _, netns := range netnses {
+			netns, err := filepath.Abs(netns)
+			if err != nil {
+				return nil, err
+			}
+			args.ValidAttachments = append(args.ValidAttachments, types.GCAttachment{ContainerID: containerID(netns), IfName: ifName})
+		}
+		return args, nil
+	}
+
+	attachments, err := cninet.GetCachedAttachments("")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return args, nil
+		}
+		return nil, err
+	}
+	for _, a := range attachments {
+		if a.Network != netName {
+			continue
+		}
+		if _, err := os.Stat(a.NetNS); err == nil {
+			args.ValidAttachments = append(args.ValidAttachments, types.GCAttachment{ContainerID: a.ContainerID, IfName: a.IfName})
+		}
 	}
+	return args, nil
 }
 
 func usage() {
 	exe := filepath.Base(os.Args[0])
 
 	fmt.Fprintf(os.Stderr, "%s: Add, check, remove, gc or status network interfaces from a network namespace\n", exe)
-	fmt.Fprintf(os.Stderr, "  %s add    <net> <netns>\n", exe)
-	fmt.Fprintf(os.Stderr, "  %s check  <net> <netns>\n", exe)
-	fmt.Fprintf(os.Stderr, "  %s del    <net> <netns>\n", exe)
-	fmt.Fprintf(os.Stderr, "  %s gc     <net> <netns>\n", exe)
-	fmt.Fprintf(os.Stderr, "  %s status <net> <netns>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] [--dry-run] [runtime flags] add <net> <netns>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] [runtime flags] check <net> <netns>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] [--dry-run] [runtime flags] del <net> <netns>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] gc     <net> [<netns>...]\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] status <net>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] apply  [-del] -f <manifest>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] versions\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  list [<net>]\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  show <container-id|netns> [<ifname>]\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  rm   <container-id|netns> [<ifname>]\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  rm   -stale [<net>]\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  export <file>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] cache  import [-overwrite] <file>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] netns  create <name>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] netns  delete <name>\n", exe)
+	fmt.Fprintf(os.Stderr, "  %s [--output=text|json] convert [-cni-version <version>] [-w] <file.conf>\n", exe)
+	fmt.Fprintf(os.Stderr, "runtime flags, added to %s and %s:\n", EnvCNIArgs, EnvCapabilityArgs)
+	fmt.Fprintf(os.Stderr, "  --args K=V              repeatable\n")
+	fmt.Fprintf(os.Stderr, "  --capability name=json  repeatable\n")
 	os.Exit(1)
 }
 
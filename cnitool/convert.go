@@ -0,0 +1,86 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/libcni"
+)
+
+// convert implements convert, which turns a single-plugin .conf file into
+// a .conflist. The list is printed unless -w is given, in which case it is
+// written next to the file, which is moved aside so that runtimes do not
+// load the network twice.
+func convert(r *report, args []string) error {
+	fs := flag.NewFlagSet(CmdConvert, flag.ExitOnError)
+	fs.Usage = usage
+	cniVersion := fs.String("cni-version", "", "upgrade the list to this CNI version")
+	write := fs.Bool("w", false, "write the list next to the file and move the file to <file>.bak")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	file := fs.Arg(0)
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	list, err := libcni.ConvertConfToConfList(data, *cniVersion)
+	if err != nil {
+		return err
+	}
+	if parsed, err := libcni.ConfListFromBytes(list); err == nil {
+		r.Network = parsed.Name
+	}
+	r.ConfList = list
+	if !*write {
+		return nil
+	}
+
+	listFile := strings.TrimSuffix(file, filepath.Ext(file)) + ".conflist"
+	if _, err := os.Lstat(listFile); err == nil {
+		return &os.PathError{Op: "convert", Path: listFile, Err: os.ErrExist}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".convert-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(list); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(file); err == nil {
+		_ = os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err := os.Rename(tmp.Name(), listFile); err != nil {
+		return err
+	}
+	if err := os.Rename(file, file+".bak"); err != nil {
+		_ = os.Remove(listFile)
+		return err
+	}
+	r.Written = listFile
+	r.Backup = file + ".bak"
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// drift compares the cached result of the attachment with what is actually
+// configured in its namespace. Only what can be observed from inside the
+// namespace is compared: the name and MAC of its interfaces, their
+// addresses and the destination and gateway of its routes.
+func drift(cninet *libcni.CNIConfig, netconf *libcni.NetworkConfigList, rt *libcni.RuntimeConf) ([]string, error) {
+	cached, err := cninet.GetNetworkListCachedResult(netconf, rt)
+	if err != nil {
+		return nil, err
+	}
+	if cached == nil {
+		return nil, fmt.Errorf("no cached result for network %q in %s", netconf.Name, rt.NetNS)
+	}
+	expected, err := projectResult(cached, rt.NetNS)
+	if err != nil {
+		return nil, err
+	}
+	observed, err := observeNetNS(rt.NetNS)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := types.DiffResults(expected, observed)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		lines = append(lines, d.String())
+	}
+	return lines, nil
+}
+
+// projectResult reduces a cached result to the fields observeNetNS reports.
+// Host-side interfaces are dropped, and routes without a gateway are given
+// the gateway of the first address of their family, as plugins do when
+// installing them.
+func projectResult(cached types.Result, netns string) (*current.Result, error) {
+	res, err := current.NewResultFromResult(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	projected := &current.Result{CNIVersion: current.ImplementedSpecVersion}
+	indexes := map[int]int{}
+	for i, iface := range res.Interfaces {
+		if iface.Sandbox == "" {
+			continue
+		}
+		mac := iface.Mac
+		if hw, err := net.ParseMAC(mac); err == nil {
+			mac = hw.String()
+		}
+		indexes[i] = len(projected.Interfaces)
+		projected.Interfaces = append(projected.Interfaces, &current.Interface{Name: iface.Name, Mac: mac, Sandbox: netns})
+	}
+
+	var gw4, gw6 net.IP
+	for _, ipc := range res.IPs {
+		if ipc.Interface != nil {
+			idx, ok := indexes[*ipc.Interface]
+			if !ok {
+				continue
+			}
+			ipc = &current.IPConfig{Interface: current.Int(idx), Address: ipc.Address, Gateway: ipc.Gateway}
+		}
+		switch {
+		case ipc.Gateway == nil:
+		case ipc.Gateway.To4() != nil && gw4 == nil:
+			gw4 = ipc.Gateway
+		case ipc.Gateway.To4() == nil && gw6 == nil:
+			gw6 = ipc.Gateway
+		}
+		projected.IPs = append(projected.IPs, &current.IPConfig{Interface: ipc.Interface, Address: ipc.Address})
+	}
+
+	for _, route := range res.Routes {
+		gw := route.GW
+		if gw == nil {
+			if route.Dst.IP.To4() != nil {
+				gw = gw4
+			} else {
+				gw = gw6
+			}
+		}
+		projected.Routes = append(projected.Routes, &types.Route{Dst: route.Dst, GW: gw})
+	}
+	return projected, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// runtimeFlags are the CNI_ARGS and capability arguments given on the
+// command line. They are added to those from the environment, replacing
+// any of the same name.
+type runtimeFlags struct {
+	args         argsFlag
+	capabilities capabilityFlag
+}
+
+func (f *runtimeFlags) empty() bool {
+	return len(f.args) == 0 && len(f.capabilities) == 0
+}
+
+// apply merges the flags into the arguments read from the environment
+func (f *runtimeFlags) apply(cniArgs [][2]string, capabilityArgs map[string]interface{}) ([][2]string, map[string]interface{}) {
+	for _, kv := range f.args {
+		replaced := false
+		for i := range cniArgs {
+			if cniArgs[i][0] == kv[0] {
+				cniArgs[i][1] = kv[1]
+				replaced = true
+			}
+		}
+		if !replaced {
+			cniArgs = append(cniArgs, kv)
+		}
+	}
+
+	if len(f.capabilities) > 0 && capabilityArgs == nil {
+		capabilityArgs = map[string]interface{}{}
+	}
+	for name, value := range f.capabilities {
+		capabilityArgs[name] = value
+	}
+	return cniArgs, capabilityArgs
+}
+
+// argsFlag collects repeated --args K=V flags, in order
+type argsFlag [][2]string
+
+func (a *argsFlag) String() string {
+	pairs := make([]string, 0, len(*a))
+	for _, kv := range *a {
+		pairs = append(pairs, kv[0]+"="+kv[1])
+	}
+	return strings.Join(pairs, ";")
+}
+
+func (a *argsFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" || v == "" || strings.ContainsAny(value, ";") || strings.Contains(v, "=") {
+		return fmt.Errorf("invalid CNI_ARGS pair %q", value)
+	}
+	*a = append(*a, [2]string{k, v})
+	return nil
+}
+
+// capabilityFlag collects repeated --capability name=json flags
+type capabilityFlag map[string]interface{}
+
+func (c *capabilityFlag) String() string {
+	if c == nil || len(*c) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(*c)
+	return string(data)
+}
+
+func (c *capabilityFlag) Set(value string) error {
+	name, raw, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("invalid capability %q, expected name=json", value)
+	}
+	if _, dup := (*c)[name]; dup {
+		return fmt.Errorf("capability %q given more than once", name)
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(raw), &v); err != nil {
+		return fmt.Errorf("invalid value for capability %q: %w", name, err)
+	}
+	if *c == nil {
+		*c = capabilityFlag{}
+	}
+	(*c)[name] = v
+	return nil
+}
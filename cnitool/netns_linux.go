@@ -0,0 +1,52 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+// netnsCommand implements netns create and delete, which manage namespaces
+// pinned under NetNSDir in the same way as `ip netns add` and `ip netns
+// delete`, for nodes without iproute2
+func netnsCommand(r *report, args []string) error {
+	if len(args) != 2 {
+		usage()
+	}
+	r.Command = CmdNetNS + " " + args[0]
+	name := args[1]
+
+	switch args[0] {
+	case "create":
+		path, err := ns.NewPinnedNS(NetNSDir, name)
+		if err != nil {
+			return err
+		}
+		r.NetNS = path
+		return nil
+	case "delete":
+		if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+			return fmt.Errorf("invalid network namespace name %q", name)
+		}
+		r.NetNS = filepath.Join(NetNSDir, name)
+		return ns.UnpinNS(r.NetNS)
+	}
+	usage()
+	return nil
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/ns"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// observeNetNS describes the interfaces, addresses and routes configured in
+// netns as a result. Loopback, link-local addresses and the routes the
+// kernel adds for local and connected subnets are left out, since plugins
+// do not report them.
+func observeNetNS(netns string) (*current.Result, error) {
+	res := &current.Result{CNIVersion: current.ImplementedSpecVersion}
+	err := ns.Do(netns, func() error {
+		ifaces, err := net.Interfaces()
+		if err != nil {
+			return err
+		}
+		indexes := map[string]int{}
+		var connected []*net.IPNet
+		for _, iface := range ifaces {
+			if iface.Flags&net.FlagLoopback != 0 {
+				continue
+			}
+			idx := len(res.Interfaces)
+			indexes[iface.Name] = idx
+			res.Interfaces = append(res.Interfaces, &current.Interface{Name: iface.Name, Mac: iface.HardwareAddr.String(), Sandbox: netns})
+
+			addrs, err := iface.Addrs()
+			if err != nil {
+				return fmt.Errorf("failed to list addresses of %s: %v", iface.Name, err)
+			}
+			for _, addr := range addrs {
+				ipn, ok := addr.(*net.IPNet)
+				if !ok || ipn.IP.IsLinkLocalUnicast() {
+					continue
+				}
+				res.IPs = append(res.IPs, &current.IPConfig{Interface: current.Int(idx), Address: *ipn})
+				connected = append(connected, &net.IPNet{IP: ipn.IP.Mask(ipn.Mask), Mask: ipn.Mask})
+			}
+		}
+
+		routes, err := readRoutes()
+		if err != nil {
+			return err
+		}
+		for _, route := range routes {
+			if _, ok := indexes[route.iface]; !ok {
+				continue
+			}
+			if route.GW == nil && isConnected(&route.Dst, connected) {
+				continue
+			}
+			r := route.Route
+			res.Routes = append(res.Routes, &r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func isConnected(dst *net.IPNet, connected []*net.IPNet) bool {
+	for _, c := range connected {
+		if dst.String() == c.String() {
+			return true
+		}
+	}
+	return false
+}
+
+type observedRoute struct {
+	types.Route
+	iface string
+}
+
+// readRoutes reads the routes of the main table from the namespace of the
+// calling thread
+func readRoutes() ([]observedRoute, error) {
+	routes, err := readRouteFile("/proc/thread-self/net/route", parseRoute4)
+	if err != nil {
+		return nil, err
+	}
+	routes6, err := readRouteFile("/proc/thread-self/net/ipv6_route", parseRoute6)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return append(routes, routes6...), nil
+}
+
+func readRouteFile(path string, parse func([]string) (*observedRoute, error)) ([]observedRoute, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var routes []observedRoute
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		route, err := parse(strings.Fields(scanner.Text()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+		}
+		if route != nil {
+			routes = append(routes, *route)
+		}
+	}
+	return routes, scanner.Err()
+}
+
+// parseRoute4 parses a line of /proc/net/route, whose addresses are
+// printed as hex words in host byte order
+func parseRoute4(fields []string) (*observedRoute, error) {
+	if len(fields) < 8 || fields[0] == "Iface" {
+		return nil, nil
+	}
+	dst, err := parseHexIPv4(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	gw, err := parseHexIPv4(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	mask, err := parseHexIPv4(fields[7])
+	if err != nil {
+		return nil, err
+	}
+
+	route := &observedRoute{iface: fields[0]}
+	route.Dst = net.IPNet{IP: dst, Mask: net.IPMask(mask)}
+	if !gw.Equal(net.IPv4zero) {
+		route.GW = gw
+	}
+	return route, nil
+}
+
+func parseHexIPv4(s string) (net.IP, error) {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.NativeEndian.PutUint32(ip, uint32(v))
+	return ip, nil
+}
+
+// parseRoute6 parses a line of /proc/net/ipv6_route. Routes on loopback,
+// host routes and the link-local and multicast prefixes are skipped.
+func parseRoute6(fields []string) (*observedRoute, error) {
+	if len(fields) < 10 {
+		return nil, nil
+	}
+	dst, err := hex.DecodeString(fields[0])
+	if err != nil || len(dst) != net.IPv6len {
+		return nil, fmt.Errorf("invalid destination %q", fields[0])
+	}
+	prefixLen, err := strconv.ParseUint(fields[1], 16, 8)
+	if err != nil || prefixLen > 128 {
+		return nil, fmt.Errorf("invalid prefix length %q", fields[1])
+	}
+	gw, err := hex.DecodeString(fields[4])
+	if err != nil || len(gw) != net.IPv6len {
+		return nil, fmt.Errorf("invalid next hop %q", fields[4])
+	}
+
+	route := &observedRoute{iface: fields[9]}
+	route.Dst = net.IPNet{IP: net.IP(dst), Mask: net.CIDRMask(int(prefixLen), 128)}
+	if route.iface == "lo" || prefixLen == 128 || route.Dst.IP.IsLinkLocalUnicast() || route.Dst.IP.IsMulticast() {
+		return nil, nil
+	}
+	if !net.IP(gw).Equal(net.IPv6zero) {
+		route.GW = net.IP(gw)
+	}
+	return route, nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// report is the outcome of a command, printed as text or, with
+// --output=json, as a single JSON object
+type report struct {
+	Command string `json:"command"`
+	Network string `json:"network,omitempty"`
+	NetNS   string `json:"netns,omitempty"`
+	IfName  string `json:"ifName,omitempty"`
+	// DryRun is whether add or del only planned the plugin invocations
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Result is the result of add
+	Result types.Result `json:"result,omitempty"`
+	// Plan are the plugin invocations add or del would perform
+	Plan []*libcni.PluginInvocation `json:"plan,omitempty"`
+	// ValidAttachments are the attachments kept by gc
+	ValidAttachments []types.GCAttachment `json:"validAttachments,omitempty"`
+	// Leaks are what gc deleted, by container
+	Leaks []libcni.GCContainerReport `json:"leaks,omitempty"`
+	// Plugins are the per-plugin outcomes of gc and status
+	Plugins []pluginReport `json:"plugins,omitempty"`
+	// Ready is whether every plugin passed status
+	Ready *bool `json:"ready,omitempty"`
+	// Drift lists how the namespace differs from the cached result when
+	// check fails
+	Drift []string `json:"drift,omitempty"`
+	// Cache are the attachments listed, shown or removed by cache
+	Cache []*cacheEntry `json:"cache,omitempty"`
+	// ConfList is the configuration list produced by convert
+	ConfList json.RawMessage `json:"confList,omitempty"`
+	// Written and Backup are the files convert -w wrote the list to and
+	// moved the original file to
+	Written string `json:"written,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+	// Entries are the outcomes of each attachment of apply
+	Entries []*report `json:"entries,omitempty"`
+
+	// Errors are gc errors not attributed to a plugin, and other errors
+	// that did not cause the command to fail
+	Errors []*types.Error `json:"errors,omitempty"`
+	// Error is why the command failed
+	Error *types.Error `json:"error,omitempty"`
+}
+
+type pluginReport struct {
+	Type  string       `json:"type"`
+	Error *types.Error `json:"error,omitempty"`
+}
+
+// toError converts err to a CNI error, keeping the code of any CNI error
+// it wraps
+func toError(err error) *types.Error {
+	if err == nil {
+		return nil
+	}
+	code, ok := types.CodeOf(err)
+	if !ok {
+		code = types.ErrInternal
+	}
+	return types.NewError(code, err.Error(), "")
+}
+
+func (r *report) fail(err error) {
+	if err != nil && r.Error == nil {
+		r.Error = toError(err)
+	}
+}
+
+// gcResult records the outcome of GC for each plugin of the network. The
+// errors of GCNetworkList name the plugin they come from; the others, such
+// as errors deleting stale attachments, are recorded separately.
+func (r *report) gcResult(netconf *libcni.NetworkConfigList, err error) error {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else if err != nil {
+		errs = []error{err}
+	}
+
+	reported := make([]bool, len(errs))
+	for _, plugin := range netconf.Plugins {
+		pr := pluginReport{Type: plugin.Network.Type}
+		for i, e := range errs {
+			if strings.Contains(e.Error(), fmt.Sprintf("GC plugin %s:", plugin.Network.Type)) {
+				pr.Error = toError(e)
+				reported[i] = true
+			}
+		}
+		r.Plugins = append(r.Plugins, pr)
+	}
+	for i, e := range errs {
+		if !reported[i] {
+			r.Errors = append(r.Errors, toError(e))
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("GC of network %q failed", netconf.Name)
+	}
+	return nil
+}
+
+// status runs STATUS against each plugin of the network in turn, recording
+// whether it is ready or the error it returned
+func (r *report) status(cninet *libcni.CNIConfig, netconf *libcni.NetworkConfigList) error {
+	ready := true
+	r.Ready = &ready
+	if supported, _ := version.Supports(version.FeatureStatus, netconf.CNIVersion); !supported {
+		return nil
+	}
+
+	for _, plugin := range netconf.Plugins {
+		single := &libcni.NetworkConfigList{
+			Name:       netconf.Name,
+			CNIVersion: netconf.CNIVersion,
+			Plugins:    []*libcni.NetworkConfig{plugin},
+		}
+		err := cninet.GetStatusNetworkList(context.TODO(), single)
+		if err != nil {
+			ready = false
+		}
+		r.Plugins = append(r.Plugins, pluginReport{Type: plugin.Network.Type, Error: toError(err)})
+	}
+
+	if !ready {
+		return fmt.Errorf("network %q is not ready", r.Network)
+	}
+	return nil
+}
+
+// finish prints the report in the given format and exits, with a nonzero
+// status if the command failed
+func finish(r *report, output string) {
+	if output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(r); err != nil {
+			exit(err)
+		}
+		if r.Error != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	r.printText()
+	if r.Error != nil {
+		exit(r.Error)
+	}
+	exit(nil)
+}
+
+func (r *report) printText() {
+	for _, e := range r.Entries {
+		status := "ok"
+		if e.Error != nil {
+			status = e.Error.Error()
+		}
+		fmt.Printf("%s %s %s %s: %s\n", e.Command, e.Network, e.NetNS, e.IfName, status)
+	}
+	if r.Result != nil {
+		_ = r.Result.Print()
+	}
+	for _, inv := range r.Plan {
+		printInvocation(inv)
+	}
+	if len(r.Drift) > 0 {
+		fmt.Println("differences from the cached result:")
+		for _, d := range r.Drift {
+			fmt.Printf("  %s\n", d)
+		}
+	}
+	r.printSubcommand()
+	for _, a := range r.ValidAttachments {
+		fmt.Printf("keeping %s %s\n", a.ContainerID, a.IfName)
+	}
+	for _, l := range r.Leaks {
+		printLeak(l)
+	}
+	for _, p := range r.Plugins {
+		switch {
+		case p.Error == nil && r.Command == CmdStatus:
+			fmt.Printf("%s: ready\n", p.Type)
+		case p.Error == nil:
+			fmt.Printf("%s: ok\n", p.Type)
+		case r.Command == CmdStatus:
+			fmt.Printf("%s: not ready: code %d: %s\n", p.Type, p.Error.Code, p.Error)
+		default:
+			fmt.Printf("%s: %s\n", p.Type, p.Error)
+		}
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("error: %s\n", e)
+	}
+	if r.Ready != nil && *r.Ready {
+		if len(r.Plugins) == 0 {
+			fmt.Printf("network %q does not support STATUS, assuming ready\n", r.Network)
+		} else {
+			fmt.Printf("network %q is ready\n", r.Network)
+		}
+	}
+}
+
+// printLeak prints what gc deleted for a container
+func printLeak(l libcni.GCContainerReport) {
+	var parts []string
+	for _, field := range []struct {
+		name   string
+		values []string
+	}{
+		{"ifnames", l.IfNames},
+		{"interfaces", l.Interfaces},
+		{"ips", l.IPs},
+		{"other", l.Other},
+		{"reported by", l.Plugins},
+	} {
+		if len(field.values) > 0 {
+			parts = append(parts, field.name+" "+strings.Join(field.values, ","))
+		}
+	}
+	fmt.Printf("deleted for %s: %s\n", l.ContainerID, strings.Join(parts, "; "))
+}
+
+// printSubcommand prints the outcome of the cache and netns subcommands
+func (r *report) printSubcommand() {
+	switch r.Command {
+	case CmdCache + " list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NETWORK\tCONTAINER\tIFNAME\tNETNS\tSTALE")
+		for _, e := range r.Cache {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", e.Network, e.ContainerID, e.IfName, e.NetNS, e.Stale)
+		}
+		_ = w.Flush()
+	case CmdCache + " show":
+		for _, e := range r.Cache {
+			fmt.Printf("network %s container %s ifName %s netns %s\n", e.Network, e.ContainerID, e.IfName, e.NetNS)
+			if e.Stale {
+				fmt.Println("namespace no longer exists")
+			}
+			printIndented("config", e.Config)
+			if e.Result != nil {
+				printIndented("result", e.Result)
+			}
+		}
+	case CmdCache + " rm":
+		for _, e := range r.Cache {
+			fmt.Printf("removed %s %s %s\n", e.Network, e.ContainerID, e.IfName)
+		}
+	case CmdCache + " export", CmdCache + " import":
+		verb := strings.TrimPrefix(r.Command, CmdCache+" ") + "ed"
+		for _, e := range r.Cache {
+			fmt.Printf("%s %s %s %s\n", verb, e.Network, e.ContainerID, e.IfName)
+		}
+	case CmdConvert:
+		switch {
+		case r.Written != "":
+			fmt.Printf("wrote %s, moved the original to %s\n", r.Written, r.Backup)
+		case r.ConfList != nil:
+			_, _ = os.Stdout.Write(r.ConfList)
+		}
+	case CmdNetNS + " create":
+		if r.Error == nil {
+			fmt.Printf("created %s\n", r.NetNS)
+		}
+	case CmdNetNS + " delete":
+		if r.Error == nil {
+			fmt.Printf("deleted %s\n", r.NetNS)
+		}
+	}
+}
+
+func printIndented(name string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		fmt.Printf("%s: %v\n", name, err)
+		return
+	}
+	fmt.Printf("%s:\n%s\n", name, data)
+}
+
+func printInvocation(inv *libcni.PluginInvocation) {
+	fmt.Printf("%s (%s)\n", inv.Type, inv.Path)
+	for _, kv := range inv.Env {
+		fmt.Printf("    %s\n", kv)
+	}
+	var stdin bytes.Buffer
+	if err := json.Indent(&stdin, inv.StdinData, "    ", "    "); err != nil {
+		stdin.Reset()
+		stdin.Write(inv.StdinData)
+	}
+	fmt.Printf("    %s\n", stdin.String())
+}
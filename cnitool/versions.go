@@ -0,0 +1,122 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+)
+
+// pluginTimeout bounds each plugin execution, since CNI_PATH may contain
+// binaries that are not CNI plugins
+const pluginTimeout = 5 * time.Second
+
+// pluginVersion is the VERSION survey entry of one plugin binary
+type pluginVersion struct {
+	Name              string   `json:"name"`
+	Path              string   `json:"path"`
+	SupportedVersions []string `json:"supportedVersions,omitempty"`
+	About             string   `json:"about,omitempty"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// surveyVersions runs VERSION on every executable in the given directories.
+// A plugin name found in several directories is only reported for the
+// first, as that is the one runtimes execute.
+func surveyVersions(dirs []string) []pluginVersion {
+	seen := map[string]bool{}
+	var survey []pluginVersion
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 || seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			survey = append(survey, surveyPlugin(entry.Name(), filepath.Join(dir, entry.Name())))
+		}
+	}
+	sort.Slice(survey, func(i, j int) bool { return survey[i].Name < survey[j].Name })
+	return survey
+}
+
+func surveyPlugin(name, path string) pluginVersion {
+	pv := pluginVersion{Name: name, Path: path}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	info, err := invoke.GetVersionInfo(ctx, path, nil)
+	if err != nil {
+		pv.Error = err.Error()
+		return pv
+	}
+	pv.SupportedVersions = info.SupportedVersions()
+	pv.About = pluginAbout(path)
+	return pv
+}
+
+// pluginAbout returns the first line plugins built with skel print to
+// stderr when run without CNI_COMMAND, such as "CNI plugin bridge v1.4.0"
+func pluginAbout(path string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+
+	stderr := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Env = []string{}
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(stderr.String(), "\n")
+	return strings.TrimSpace(line)
+}
+
+func printVersions(w io.Writer, survey []pluginVersion, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(survey)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSIONS\tABOUT")
+	for _, pv := range survey {
+		versions := strings.Join(pv.SupportedVersions, ",")
+		about := pv.About
+		if pv.Error != "" {
+			versions = "-"
+			about = "error: " + pv.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", pv.Name, versions, about)
+	}
+	return tw.Flush()
+}
@@ -23,15 +23,22 @@ package libcni
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/containernetworking/cni/pkg/audit"
 	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/lock"
+	"github.com/containernetworking/cni/pkg/strictjson"
+	"github.com/containernetworking/cni/pkg/tracing"
 	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
 	"github.com/containernetworking/cni/pkg/types/create"
 	"github.com/containernetworking/cni/pkg/utils"
 	"github.com/containernetworking/cni/pkg/version"
@@ -62,6 +69,21 @@ type RuntimeConf struct {
 	// in this map which match the capabilities of the plugin are passed
 	// to the plugin
 	CapabilityArgs map[string]interface{}
+	// Metadata is stored with the cached result of ADD, for finding the
+	// attachment later with GetCachedAttachmentsByMetadata, e.g. by pod
+	// name or tenant. It is not passed to plugins.
+	Metadata map[string]string
+	// CDIDevices are the fully-qualified CDI device names allocated to
+	// the attachment, e.g. by kubelet's device manager. They are passed to
+	// every plugin in the CNI_CDI_DEVICES environment variable, and to
+	// plugins with the "cdiDevices" capability in runtimeConfig, unless
+	// CapabilityArgs has its own "cdiDevices".
+	CDIDevices []string
+	// CNIVersion, if set, forces the cniVersion of the network for this
+	// invocation, overriding the configuration and CNIConfig's
+	// VersionOverrides. Every plugin of the network must support it. The
+	// version is not cached: pass it again to CHECK and DEL.
+	CNIVersion string
 
 	// DEPRECATED. Will be removed in a future release.
 	CacheDir string
@@ -73,8 +95,11 @@ type NetworkConfig struct {
 }
 
 type NetworkConfigList struct {
-	Name         string
-	CNIVersion   string
+	Name       string
+	CNIVersion string
+	// CNIVersions are all the versions declared by the configuration, from
+	// both "cniVersion" and "cniVersions", highest first
+	CNIVersions  []string
 	DisableCheck bool
 	Plugins      []*NetworkConfig
 	Bytes        []byte
@@ -88,11 +113,12 @@ type NetworkAttachment struct {
 	NetNS          string
 	CniArgs        [][2]string
 	CapabilityArgs map[string]interface{}
+	Metadata       map[string]string
+	CDIDevices     []string
 }
 
-type GCArgs struct {
-	ValidAttachments []types.GCAttachment
-}
+// GCArgs are the arguments to GCNetworkList
+type GCArgs = types.GCArgs
 
 type CNI interface {
 	AddNetworkList(ctx context.Context, net *NetworkConfigList, rt *RuntimeConf) (types.Result, error)
@@ -122,6 +148,90 @@ type CNIConfig struct {
 	Path     []string
 	exec     invoke.Exec
 	cacheDir string
+
+	// versionCache holds the VERSION responses of plugin binaries
+	versionCache *invoke.VersionCache
+	// writeBehind, if set, holds the changes to the results cache which
+	// are not yet written
+	writeBehind *writeBehind
+
+	// DeprecationWarnings, if set, receives a warning whenever a network
+	// using a deprecated CNI version is added
+	DeprecationWarnings io.Writer
+
+	// NamePolicy validates container IDs and network names; nil means
+	// utils.DefaultNamePolicy
+	NamePolicy *utils.NamePolicy
+
+	// Tracer, if set, traces network list operations and every plugin
+	// execution
+	Tracer tracing.Tracer
+
+	// Metrics, if set, is given measurements of network list operations
+	// and every plugin execution
+	Metrics Metrics
+
+	// Logger, if set, logs plugin executions, version negotiation, cache
+	// operations and validation failures
+	Logger *slog.Logger
+
+	// Audit, if set, receives a record of every network list operation,
+	// as for the audit log in pkg/audit. Records which cannot be written
+	// are logged, without failing the operation.
+	Audit audit.Sink
+
+	// FeatureGates enables features of draft spec versions. Results are
+	// returned without the fields of disabled draft features, though they
+	// are cached and passed to plugins whole.
+	FeatureGates version.FeatureGates
+
+	// VersionOverrides forces the cniVersion of networks, by name,
+	// overriding their configuration, for serving runtimes of differing
+	// capabilities with one configuration. Every plugin of the network
+	// must support the version.
+	VersionOverrides map[string]string
+
+	// TeardownParallelism is the number of containers TeardownAll
+	// detaches at once; DefaultTeardownParallelism if zero
+	TeardownParallelism int
+
+	// WorkerPool, if set, runs plugins in place of the exec, such as a
+	// daemon.Pool keeping pre-started workers of the plugins built to
+	// serve many attachments, which saves starting their binaries and Go
+	// runtimes on busy nodes. It runs the other plugins itself, so it
+	// should fall back to the same exec. VERSION is still run by the exec.
+	// Workers are not started by the exec, so they escape its confinement
+	// and other process options, such as invoke.WithSeccomp or
+	// invoke.WithNetNS; a daemon.Pool refuses to run workers when its
+	// fallback exec has such options.
+	WorkerPool invoke.Exec
+
+	// StrictDecoding, if set, makes the CNIConfig check plugin results,
+	// cached attachments and the cached configurations of TeardownAll
+	// with strictjson.Check within these limits before decoding them, for
+	// runtimes whose configuration comes from untrusted sources. Pass
+	// WithStrictDecoding to the configuration loaders for the same checks.
+	StrictDecoding *strictjson.Limits
+}
+
+// discardLogger is used when a CNIConfig has no Logger
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (c *CNIConfig) log() *slog.Logger {
+	if c.Logger == nil {
+		return discardLogger
+	}
+	return c.Logger
+}
+
+// Metrics receives measurements of a CNIConfig's operations, such as the
+// Prometheus collector in pkg/metrics. It must be safe for concurrent use.
+type Metrics interface {
+	// ObserveOperation is called when AddNetworkList, CheckNetworkList,
+	// DelNetworkList or GCNetworkList returns
+	ObserveOperation(operation, network string, duration time.Duration, err error)
+	// ObservePlugin is called when a plugin execution returns
+	ObservePlugin(pluginType, command string, duration time.Duration, err error)
 }
 
 // CNIConfig implements the CNI interface
@@ -140,9 +250,10 @@ func NewCNIConfig(path []string, exec invoke.Exec) *CNIConfig {
 // The given cache directory will be used for temporary data storage when needed.
 func NewCNIConfigWithCacheDir(path []string, cacheDir string, exec invoke.Exec) *CNIConfig {
 	return &CNIConfig{
-		Path:     path,
-		cacheDir: cacheDir,
-		exec:     exec,
+		Path:         path,
+		cacheDir:     cacheDir,
+		exec:         exec,
+		versionCache: invoke.NewVersionCache(),
 	}
 }
 
@@ -182,9 +293,17 @@ func buildOneConfig(name, cniVersion string, orig *NetworkConfig, prevResult typ
 // capabilities include "portMappings", and the CapabilityArgs map includes a
 // "portMappings" key, that key and its value are added to the "runtimeConfig"
 // dictionary to be passed to the plugin's stdin.
+//
+// The well-known capability arguments are validated first, so that a
+// plugin is not run with arguments it cannot apply.
 func injectRuntimeConfig(orig *NetworkConfig, rt *RuntimeConf) (*NetworkConfig, error) {
 	var err error
 
+	// CDI devices are passed to every plugin in the environment
+	if _, err := capabilities.ParseCDIDevices(rt.CDIDevices); err != nil {
+		return nil, err
+	}
+
 	rc := make(map[string]interface{})
 	for capability, supported := range orig.Network.Capabilities {
 		if !supported {
@@ -192,9 +311,15 @@ func injectRuntimeConfig(orig *NetworkConfig, rt *RuntimeConf) (*NetworkConfig,
 		}
 		if data, ok := rt.CapabilityArgs[capability]; ok {
 			rc[capability] = data
+		} else if capability == capabilities.CDIDevicesKey && len(rt.CDIDevices) > 0 {
+			rc[capability] = rt.CDIDevices
 		}
 	}
 
+	if err := capabilities.ValidateCapabilityArgs(rc); err != nil {
+		return nil, fmt.Errorf("invalid capability arguments for plugin %s: %w", orig.Network.Type, err)
+	}
+
 	if len(rc) > 0 {
 		orig, err = InjectConf(orig, map[string]interface{}{"runtimeConfig": rc})
 		if err != nil {
@@ -216,6 +341,112 @@ func (c *CNIConfig) ensureExec() invoke.Exec {
 	return c.exec
 }
 
+// pluginExec returns the exec to run plugins with, through the worker
+// pool if enabled, traced if there is a Tracer and observed if there are
+// Metrics
+func (c *CNIConfig) pluginExec() invoke.Exec {
+	exec := c.ensureExec()
+	if c.WorkerPool != nil {
+		exec = c.WorkerPool
+	}
+	if c.Metrics != nil {
+		exec = invoke.ObservedExec(exec, c.Metrics.ObservePlugin)
+	}
+	if c.Logger != nil {
+		exec = invoke.ObservedExec(exec, c.logPlugin)
+	}
+	if c.Tracer != nil {
+		exec = invoke.TracedExec(exec, c.Tracer)
+	}
+	if c.StrictDecoding != nil {
+		exec = invoke.CheckedExec(exec, c.checkStrict)
+	}
+	return exec
+}
+
+// checkStrict checks data with strictjson.Check if StrictDecoding is set
+func (c *CNIConfig) checkStrict(data []byte) error {
+	return checkStrict(data, c.StrictDecoding)
+}
+
+// checkStrict checks data with strictjson.Check if limits is not nil
+func checkStrict(data []byte, limits *strictjson.Limits) error {
+	if limits == nil {
+		return nil
+	}
+	return strictjson.Check(data, *limits)
+}
+
+// logPlugin logs a plugin execution
+func (c *CNIConfig) logPlugin(pluginType, command string, duration time.Duration, err error) {
+	if err != nil {
+		c.Logger.Warn("plugin failed", "plugin", pluginType, "command", command, "duration", duration, "error", err)
+		return
+	}
+	c.Logger.Debug("plugin succeeded", "plugin", pluginType, "command", command, "duration", duration)
+}
+
+// startOperation starts tracing, measuring and auditing an operation on a
+// network list; the returned func ends it, with its result if any
+func (c *CNIConfig) startOperation(ctx context.Context, name string, list *NetworkConfigList, rt *RuntimeConf) (context.Context, func(types.Result, error)) {
+	start := time.Now()
+	if c.Audit != nil && audit.CorrelationID(ctx) == "" {
+		ctx = audit.WithCorrelationID(ctx, audit.NewCorrelationID())
+	}
+	ctx, span := c.startSpan(ctx, name, list, rt)
+	return ctx, func(result types.Result, err error) {
+		tracing.End(span, err)
+		if c.Metrics != nil {
+			c.Metrics.ObserveOperation(name, list.Name, time.Since(start), err)
+		}
+		if c.Audit != nil {
+			c.audit(ctx, name, list, rt, start, result, err)
+		}
+	}
+}
+
+// audit writes the record of an operation to the Audit sink
+func (c *CNIConfig) audit(ctx context.Context, name string, list *NetworkConfigList, rt *RuntimeConf, start time.Time, result types.Result, err error) {
+	r := &audit.Record{
+		Time:          start,
+		CorrelationID: audit.CorrelationID(ctx),
+		Source:        audit.SourceRuntime,
+		Actor:         audit.CurrentActor(),
+		Operation:     name,
+		Network:       list.Name,
+		CNIVersion:    list.CNIVersion,
+		ConfigHash:    audit.Hash(list.Bytes),
+		Duration:      time.Since(start).Seconds(),
+	}
+	if rt != nil {
+		r.ContainerID = rt.ContainerID
+		r.IfName = rt.IfName
+		r.NetNS = rt.NetNS
+	}
+	if result != nil {
+		r.ResultHash = audit.Hash(result)
+	}
+	r.SetOutcome(err)
+	if werr := c.Audit.Write(r); werr != nil {
+		c.log().Warn("failed to write audit record", "operation", name, "network", list.Name, "error", werr)
+	}
+}
+
+// startSpan starts a span for an operation on a network list
+func (c *CNIConfig) startSpan(ctx context.Context, name string, list *NetworkConfigList, rt *RuntimeConf) (context.Context, tracing.Span) {
+	attrs := []tracing.Attribute{
+		tracing.String(tracing.AttrNetworkName, list.Name),
+		tracing.String(tracing.AttrCNIVersion, list.CNIVersion),
+	}
+	if rt != nil {
+		attrs = append(attrs,
+			tracing.String(tracing.AttrContainerID, rt.ContainerID),
+			tracing.String(tracing.AttrIfName, rt.IfName),
+		)
+	}
+	return tracing.Start(ctx, c.Tracer, name, attrs...)
+}
+
 type cachedInfo struct {
 	Kind           string                 `json:"kind"`
 	ContainerID    string                 `json:"containerId"`
@@ -225,6 +456,8 @@ type cachedInfo struct {
 	NetNS          string                 `json:"netns,omitempty"`
 	CniArgs        [][2]string            `json:"cniArgs,omitempty"`
 	CapabilityArgs map[string]interface{} `json:"capabilityArgs,omitempty"`
+	Metadata       map[string]string      `json:"metadata,omitempty"`
+	CDIDevices     []string               `json:"cdiDevices,omitempty"`
 	RawResult      map[string]interface{} `json:"result,omitempty"`
 	Result         types.Result           `json:"-"`
 }
@@ -250,7 +483,7 @@ func (c *CNIConfig) getCacheFilePath(netName string, rt *RuntimeConf) (string, e
 	return filepath.Join(c.getCacheDir(rt), "results", fmt.Sprintf("%s-%s-%s", netName, rt.ContainerID, rt.IfName)), nil
 }
 
-func (c *CNIConfig) cacheAdd(result types.Result, config []byte, netName string, rt *RuntimeConf) error {
+func (c *CNIConfig) cacheAdd(ctx context.Context, result types.Result, config []byte, netName string, rt *RuntimeConf) error {
 	cached := cachedInfo{
 		Kind:           CNICacheV1,
 		ContainerID:    rt.ContainerID,
@@ -260,6 +493,8 @@ func (c *CNIConfig) cacheAdd(result types.Result, config []byte, netName string,
 		NetNS:          rt.NetNS,
 		CniArgs:        rt.Args,
 		CapabilityArgs: rt.CapabilityArgs,
+		Metadata:       rt.Metadata,
+		CDIDevices:     rt.CDIDevices,
 	}
 
 	// We need to get type.Result into cachedInfo as JSON map
@@ -283,20 +518,56 @@ func (c *CNIConfig) cacheAdd(result types.Result, config []byte, netName string,
 	if err != nil {
 		return err
 	}
+	if c.writeBehind != nil {
+		if err := c.writeBehind.write(fname, newBytes); err != nil {
+			return err
+		}
+		c.log().Debug("cached result", "network", netName, "containerID", rt.ContainerID, "ifName", rt.IfName, "path", fname)
+		return nil
+	}
+	unlock, err := c.lockCache(ctx, rt)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	if err := os.MkdirAll(filepath.Dir(fname), 0o700); err != nil {
 		return err
 	}
 
-	return os.WriteFile(fname, newBytes, 0o600)
+	if err := os.WriteFile(fname, newBytes, 0o600); err != nil {
+		return err
+	}
+	c.log().Debug("cached result", "network", netName, "containerID", rt.ContainerID, "ifName", rt.IfName, "path", fname)
+	return nil
+}
+
+// lockCache takes the lock that serializes changes to the cache, shared
+// with other processes using the same cache directory, and returns the
+// func releasing it. It gives up when ctx is done, as a holder that never
+// releases the lock is only reported, not broken.
+func (c *CNIConfig) lockCache(ctx context.Context, rt *RuntimeConf) (func(), error) {
+	l, err := lock.New(filepath.Join(c.getCacheDir(rt), "cache.lock"))
+	if err != nil {
+		return nil, err
+	}
+	if err := l.Lock(ctx); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return func() { _ = l.Close() }, nil
 }
 
-func (c *CNIConfig) cacheDel(netName string, rt *RuntimeConf) error {
+func (c *CNIConfig) cacheDel(ctx context.Context, netName string, rt *RuntimeConf) error {
 	fname, err := c.getCacheFilePath(netName, rt)
 	if err != nil {
 		// Ignore error
 		return nil
 	}
-	return os.Remove(fname)
+	if err := c.removeCacheFile(ctx, fname, rt); err != nil {
+		return err
+	}
+	c.log().Debug("removed cached result", "network", netName, "containerID", rt.ContainerID, "ifName", rt.IfName, "path", fname)
+	return nil
 }
 
 func (c *CNIConfig) getCachedConfig(netName string, rt *RuntimeConf) ([]byte, *RuntimeConf, error) {
@@ -306,12 +577,15 @@ func (c *CNIConfig) getCachedConfig(netName string, rt *RuntimeConf) ([]byte, *R
 	if err != nil {
 		return nil, nil, err
 	}
-	bytes, err = os.ReadFile(fname)
+	bytes, err = c.readCacheFile(fname)
 	if err != nil {
 		// Ignore read errors; the cached result may not exist on-disk
 		return nil, nil, nil
 	}
 
+	if err := c.checkStrict(bytes); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal cached network %q config: %w", netName, err)
+	}
 	unmarshaled := cachedInfo{}
 	if err := json.Unmarshal(bytes, &unmarshaled); err != nil {
 		return nil, nil, fmt.Errorf("failed to unmarshal cached network %q config: %w", netName, err)
@@ -325,6 +599,8 @@ func (c *CNIConfig) getCachedConfig(netName string, rt *RuntimeConf) ([]byte, *R
 		newRt.Args = unmarshaled.CniArgs
 	}
 	newRt.CapabilityArgs = unmarshaled.CapabilityArgs
+	newRt.Metadata = unmarshaled.Metadata
+	newRt.CDIDevices = unmarshaled.CDIDevices
 
 	return unmarshaled.Config, &newRt, nil
 }
@@ -334,7 +610,7 @@ func (c *CNIConfig) getLegacyCachedResult(netName, cniVersion string, rt *Runtim
 	if err != nil {
 		return nil, err
 	}
-	data, err := os.ReadFile(fname)
+	data, err := c.readCacheFile(fname)
 	if err != nil {
 		// Ignore read errors; the cached result may not exist on-disk
 		return nil, nil
@@ -362,12 +638,15 @@ func (c *CNIConfig) getCachedResult(netName, cniVersion string, rt *RuntimeConf)
 	if err != nil {
 		return nil, err
 	}
-	fdata, err := os.ReadFile(fname)
+	fdata, err := c.readCacheFile(fname)
 	if err != nil {
 		// Ignore read errors; the cached result may not exist on-disk
 		return nil, nil
 	}
 
+	if err := c.checkStrict(fdata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached network %q result: %w", netName, err)
+	}
 	cachedInfo := cachedInfo{}
 	if err := json.Unmarshal(fdata, &cachedInfo); err != nil || cachedInfo.Kind != CNICacheV1 {
 		return c.getLegacyCachedResult(netName, cniVersion, rt)
@@ -398,13 +677,15 @@ func (c *CNIConfig) getCachedResult(netName, cniVersion string, rt *RuntimeConf)
 // GetNetworkListCachedResult returns the cached Result of the previous
 // AddNetworkList() operation for a network list, or an error.
 func (c *CNIConfig) GetNetworkListCachedResult(list *NetworkConfigList, rt *RuntimeConf) (types.Result, error) {
-	return c.getCachedResult(list.Name, list.CNIVersion, rt)
+	result, err := c.getCachedResult(list.Name, list.CNIVersion, rt)
+	return c.gateResult(result), err
 }
 
 // GetNetworkCachedResult returns the cached Result of the previous
 // AddNetwork() operation for a network, or an error.
 func (c *CNIConfig) GetNetworkCachedResult(net *NetworkConfig, rt *RuntimeConf) (types.Result, error) {
-	return c.getCachedResult(net.Network.Name, net.Network.CNIVersion, rt)
+	result, err := c.getCachedResult(net.Network.Name, net.Network.CNIVersion, rt)
+	return c.gateResult(result), err
 }
 
 // GetNetworkListCachedConfig copies the input RuntimeConf to output
@@ -423,17 +704,11 @@ func (c *CNIConfig) GetNetworkCachedConfig(net *NetworkConfig, rt *RuntimeConf)
 // The returned list will be filtered by the containerID if the value is not empty.
 func (c *CNIConfig) GetCachedAttachments(containerID string) ([]*NetworkAttachment, error) {
 	dirPath := filepath.Join(c.getCacheDir(&RuntimeConf{}), "results")
-	entries, err := os.ReadDir(dirPath)
+	fileNames, err := c.readCacheDir(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
-	fileNames := make([]string, 0, len(entries))
-	for _, e := range entries {
-		fileNames = append(fileNames, e.Name())
-	}
-	sort.Strings(fileNames)
-
 	attachments := []*NetworkAttachment{}
 	for _, fname := range fileNames {
 		if len(containerID) > 0 {
@@ -445,11 +720,14 @@ func (c *CNIConfig) GetCachedAttachments(containerID string) ([]*NetworkAttachme
 		}
 
 		cacheFile := filepath.Join(dirPath, fname)
-		bytes, err := os.ReadFile(cacheFile)
+		bytes, err := c.readCacheFile(cacheFile)
 		if err != nil {
 			continue
 		}
 
+		if c.checkStrict(bytes) != nil {
+			continue
+		}
 		cachedInfo := cachedInfo{}
 
 		if err := json.Unmarshal(bytes, &cachedInfo); err != nil {
@@ -473,24 +751,99 @@ func (c *CNIConfig) GetCachedAttachments(containerID string) ([]*NetworkAttachme
 			NetNS:          cachedInfo.NetNS,
 			CniArgs:        cachedInfo.CniArgs,
 			CapabilityArgs: cachedInfo.CapabilityArgs,
+			Metadata:       cachedInfo.Metadata,
+			CDIDevices:     cachedInfo.CDIDevices,
 		})
 	}
 	return attachments, nil
 }
 
-func (c *CNIConfig) addNetwork(ctx context.Context, name, cniVersion string, net *NetworkConfig, prevResult types.Result, rt *RuntimeConf) (types.Result, error) {
-	c.ensureExec()
-	pluginPath, err := c.exec.FindInPath(net.Network.Type, c.Path)
+// GetCachedAttachmentsByMetadata returns the cached attachments whose
+// RuntimeConf.Metadata held every given key with the given value. An
+// empty selector matches every attachment.
+func (c *CNIConfig) GetCachedAttachmentsByMetadata(selector map[string]string) ([]*NetworkAttachment, error) {
+	attachments, err := c.GetCachedAttachments("")
 	if err != nil {
 		return nil, err
 	}
-	if err := utils.ValidateContainerID(rt.ContainerID); err != nil {
+	matching := []*NetworkAttachment{}
+	for _, a := range attachments {
+		if matchesMetadata(a.Metadata, selector) {
+			matching = append(matching, a)
+		}
+	}
+	return matching, nil
+}
+
+func matchesMetadata(metadata, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := metadata[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// RemoveCachedAttachment deletes the cached result and config of an
+// attachment without invoking any plugin, for cleaning up entries whose
+// container is gone. The error satisfies os.IsNotExist if nothing is cached.
+func (c *CNIConfig) RemoveCachedAttachment(netName string, rt *RuntimeConf) error {
+	fname, err := c.getCacheFilePath(netName, rt)
+	if err != nil {
+		return err
+	}
+	return c.removeCacheFile(context.Background(), fname, rt)
+}
+
+// readCacheFile reads a file of the results cache, or its pending contents
+// with write-behind
+func (c *CNIConfig) readCacheFile(fname string) ([]byte, error) {
+	if c.writeBehind != nil {
+		return c.writeBehind.read(fname)
+	}
+	return os.ReadFile(fname)
+}
+
+// readCacheDir returns the sorted names of the files of a directory of the
+// results cache, including those pending with write-behind
+func (c *CNIConfig) readCacheDir(dirPath string) ([]string, error) {
+	if c.writeBehind != nil {
+		return c.writeBehind.readDir(dirPath)
+	}
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
 		return nil, err
 	}
-	if err := utils.ValidateNetworkName(name); err != nil {
+	fileNames := make([]string, 0, len(entries))
+	for _, e := range entries {
+		fileNames = append(fileNames, e.Name())
+	}
+	sort.Strings(fileNames)
+	return fileNames, nil
+}
+
+// removeCacheFile removes a file of the results cache, or makes its removal
+// pending with write-behind
+func (c *CNIConfig) removeCacheFile(ctx context.Context, fname string, rt *RuntimeConf) error {
+	if c.writeBehind != nil {
+		return c.writeBehind.remove(fname)
+	}
+	unlock, err := c.lockCache(ctx, rt)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return os.Remove(fname)
+}
+
+func (c *CNIConfig) addNetwork(ctx context.Context, name, cniVersion string, net *NetworkConfig, prevResult types.Result, rt *RuntimeConf) (types.Result, error) {
+	c.ensureExec()
+	pluginPath, err := c.exec.FindInPath(net.Network.Type, c.Path)
+	if err != nil {
 		return nil, err
 	}
-	if err := utils.ValidateInterfaceName(rt.IfName); err != nil {
+	if err := c.validateAttachment(name, rt); err != nil {
+		c.log().Warn("invalid attachment", "network", name, "containerID", rt.ContainerID, "ifName", rt.IfName, "error", err)
 		return nil, err
 	}
 
@@ -499,12 +852,58 @@ func (c *CNIConfig) addNetwork(ctx context.Context, name, cniVersion string, net
 		return nil, err
 	}
 
-	return invoke.ExecPluginWithResult(ctx, pluginPath, newConf.Bytes, c.args("ADD", rt), c.exec)
+	return invoke.ExecPluginWithResult(ctx, pluginPath, newConf.Bytes, c.args(ctx, "ADD", rt), c.pluginExec())
+}
+
+// validateAttachment checks the container ID, network name and interface
+// name of an attachment
+func (c *CNIConfig) validateAttachment(name string, rt *RuntimeConf) error {
+	policy := c.namePolicy()
+	if err := policy.ValidateContainerID(rt.ContainerID); err != nil {
+		return err
+	}
+	if err := policy.ValidateNetworkName(name); err != nil {
+		return err
+	}
+	if err := utils.ValidateInterfaceName(rt.IfName); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *CNIConfig) namePolicy() *utils.NamePolicy {
+	if c.NamePolicy == nil {
+		return &utils.DefaultNamePolicy
+	}
+	return c.NamePolicy
+}
+
+// warnDeprecated writes a warning to DeprecationWarnings if the network
+// uses a deprecated CNI version
+func (c *CNIConfig) warnDeprecated(name, cniVersion string) {
+	if c.DeprecationWarnings == nil {
+		return
+	}
+	if warning := version.DeprecationWarning(cniVersion); warning != "" {
+		_, _ = fmt.Fprintf(c.DeprecationWarnings, "WARNING: network %q: %s\n", name, warning)
+	}
 }
 
 // AddNetworkList executes a sequence of plugins with the ADD command
 func (c *CNIConfig) AddNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) (types.Result, error) {
-	var err error
+	ctx, end := c.startOperation(ctx, "AddNetworkList", list, rt)
+	result, err := c.addNetworkList(ctx, list, rt)
+	end(result, err)
+	return c.gateResult(result), err
+}
+
+func (c *CNIConfig) addNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) (types.Result, error) {
+	list, err := c.pinVersion(ctx, list, rt)
+	if err != nil {
+		return nil, err
+	}
+	c.warnDeprecated(list.Name, list.CNIVersion)
+
 	var result types.Result
 	for _, net := range list.Plugins {
 		result, err = c.addNetwork(ctx, list.Name, list.CNIVersion, net, result, rt)
@@ -513,7 +912,7 @@ func (c *CNIConfig) AddNetworkList(ctx context.Context, list *NetworkConfigList,
 		}
 	}
 
-	if err = c.cacheAdd(result, list.Bytes, list.Name, rt); err != nil {
+	if err = c.cacheAdd(ctx, result, list.Bytes, list.Name, rt); err != nil {
 		return nil, fmt.Errorf("failed to set network %q cached result: %w", list.Name, err)
 	}
 
@@ -532,15 +931,25 @@ func (c *CNIConfig) checkNetwork(ctx context.Context, name, cniVersion string, n
 		return err
 	}
 
-	return invoke.ExecPluginWithoutResult(ctx, pluginPath, newConf.Bytes, c.args("CHECK", rt), c.exec)
+	return invoke.ExecPluginWithoutResult(ctx, pluginPath, newConf.Bytes, c.args(ctx, "CHECK", rt), c.pluginExec())
 }
 
 // CheckNetworkList executes a sequence of plugins with the CHECK command
 func (c *CNIConfig) CheckNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) error {
-	// CHECK was added in CNI spec version 0.4.0 and higher
-	if gtet, err := version.GreaterThanOrEqualTo(list.CNIVersion, "0.4.0"); err != nil {
+	ctx, end := c.startOperation(ctx, "CheckNetworkList", list, rt)
+	err := c.checkNetworkList(ctx, list, rt)
+	end(nil, err)
+	return err
+}
+
+func (c *CNIConfig) checkNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) error {
+	list, err := c.pinVersion(ctx, list, rt)
+	if err != nil {
+		return err
+	}
+	if supported, err := c.FeatureGates.Supports(version.FeatureCheck, list.CNIVersion); err != nil {
 		return err
-	} else if !gtet {
+	} else if !supported {
 		return fmt.Errorf("configuration version %q %w", list.CNIVersion, ErrorCheckNotSupp)
 	}
 
@@ -574,19 +983,30 @@ func (c *CNIConfig) delNetwork(ctx context.Context, name, cniVersion string, net
 		return err
 	}
 
-	return invoke.ExecPluginWithoutResult(ctx, pluginPath, newConf.Bytes, c.args("DEL", rt), c.exec)
+	return invoke.ExecPluginWithoutResult(ctx, pluginPath, newConf.Bytes, c.args(ctx, "DEL", rt), c.pluginExec())
 }
 
 // DelNetworkList executes a sequence of plugins with the DEL command
 func (c *CNIConfig) DelNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) error {
+	ctx, end := c.startOperation(ctx, "DelNetworkList", list, rt)
+	err := c.delNetworkList(ctx, list, rt)
+	end(nil, err)
+	return err
+}
+
+func (c *CNIConfig) delNetworkList(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) error {
+	list, err := c.pinVersion(ctx, list, rt)
+	if err != nil {
+		return err
+	}
 	var cachedResult types.Result
 
-	// Cached result on DEL was added in CNI spec version 0.4.0 and higher
-	if gtet, err := version.GreaterThanOrEqualTo(list.CNIVersion, "0.4.0"); err != nil {
+	if supported, err := c.FeatureGates.Supports(version.FeatureDelPrevResult, list.CNIVersion); err != nil {
 		return err
-	} else if gtet {
+	} else if supported {
 		if cachedResult, err = c.getCachedResult(list.Name, list.CNIVersion, rt); err != nil {
-			_ = c.cacheDel(list.Name, rt)
+			c.log().Warn("discarding unusable cached result", "network", list.Name, "containerID", rt.ContainerID, "ifName", rt.IfName, "error", err)
+			_ = c.cacheDel(ctx, list.Name, rt)
 			cachedResult = nil
 		}
 	}
@@ -599,12 +1019,64 @@ func (c *CNIConfig) DelNetworkList(ctx context.Context, list *NetworkConfigList,
 	}
 
 	if cachedResult != nil {
-		_ = c.cacheDel(list.Name, rt)
+		_ = c.cacheDel(ctx, list.Name, rt)
 	}
 
 	return nil
 }
 
+// pinnedVersion returns the cniVersion forced for a network by the
+// RuntimeConf or VersionOverrides, once every plugin is known to support
+// it, or "" when the configured version is used
+func (c *CNIConfig) pinnedVersion(ctx context.Context, name, configured string, plugins []*NetworkConfig, rt *RuntimeConf) (string, error) {
+	pinned := c.versionPin(name, rt)
+	if pinned == "" || pinned == configured {
+		return "", nil
+	}
+	if _, err := version.ParseSemver(pinned); err != nil {
+		return "", fmt.Errorf("cannot pin network %q to version %q: %w", name, pinned, err)
+	}
+	for _, net := range plugins {
+		if err := c.validatePlugin(ctx, net.Network.Type, pinned); err != nil {
+			return "", fmt.Errorf("cannot pin network %q to version %q: %w", name, pinned, err)
+		}
+	}
+	c.log().Debug("pinned CNI version", "network", name, "version", pinned, "configured", configured)
+	return pinned, nil
+}
+
+// versionPin returns the cniVersion forced for a network, unchecked, or ""
+func (c *CNIConfig) versionPin(name string, rt *RuntimeConf) string {
+	if rt != nil && rt.CNIVersion != "" {
+		return rt.CNIVersion
+	}
+	return c.VersionOverrides[name]
+}
+
+// pinVersion returns a copy of the list with its pinned cniVersion, or
+// the list itself if none is pinned
+func (c *CNIConfig) pinVersion(ctx context.Context, list *NetworkConfigList, rt *RuntimeConf) (*NetworkConfigList, error) {
+	pinned, err := c.pinnedVersion(ctx, list.Name, list.CNIVersion, list.Plugins, rt)
+	if err != nil || pinned == "" {
+		return list, err
+	}
+	pinnedList := *list
+	pinnedList.CNIVersion = pinned
+	return &pinnedList, nil
+}
+
+// pinNetworkVersion returns a copy of the network with its pinned
+// cniVersion, or the network itself if none is pinned
+func (c *CNIConfig) pinNetworkVersion(ctx context.Context, net *NetworkConfig, rt *RuntimeConf) (*NetworkConfig, error) {
+	pinned, err := c.pinnedVersion(ctx, net.Network.Name, net.Network.CNIVersion, []*NetworkConfig{net}, rt)
+	if err != nil || pinned == "" {
+		return net, err
+	}
+	netConf := *net.Network
+	netConf.CNIVersion = pinned
+	return &NetworkConfig{Network: &netConf, Bytes: net.Bytes}, nil
+}
+
 func pluginDescription(net *types.NetConf) string {
 	if net == nil {
 		return "<missing>"
@@ -620,24 +1092,33 @@ func pluginDescription(net *types.NetConf) string {
 
 // AddNetwork executes the plugin with the ADD command
 func (c *CNIConfig) AddNetwork(ctx context.Context, net *NetworkConfig, rt *RuntimeConf) (types.Result, error) {
+	net, err := c.pinNetworkVersion(ctx, net, rt)
+	if err != nil {
+		return nil, err
+	}
+	c.warnDeprecated(net.Network.Name, net.Network.CNIVersion)
+
 	result, err := c.addNetwork(ctx, net.Network.Name, net.Network.CNIVersion, net, nil, rt)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = c.cacheAdd(result, net.Bytes, net.Network.Name, rt); err != nil {
+	if err = c.cacheAdd(ctx, result, net.Bytes, net.Network.Name, rt); err != nil {
 		return nil, fmt.Errorf("failed to set network %q cached result: %w", net.Network.Name, err)
 	}
 
-	return result, nil
+	return c.gateResult(result), nil
 }
 
 // CheckNetwork executes the plugin with the CHECK command
 func (c *CNIConfig) CheckNetwork(ctx context.Context, net *NetworkConfig, rt *RuntimeConf) error {
-	// CHECK was added in CNI spec version 0.4.0 and higher
-	if gtet, err := version.GreaterThanOrEqualTo(net.Network.CNIVersion, "0.4.0"); err != nil {
+	net, err := c.pinNetworkVersion(ctx, net, rt)
+	if err != nil {
+		return err
+	}
+	if supported, err := c.FeatureGates.Supports(version.FeatureCheck, net.Network.CNIVersion); err != nil {
 		return err
-	} else if !gtet {
+	} else if !supported {
 		return fmt.Errorf("configuration version %q %w", net.Network.CNIVersion, ErrorCheckNotSupp)
 	}
 
@@ -650,12 +1131,15 @@ func (c *CNIConfig) CheckNetwork(ctx context.Context, net *NetworkConfig, rt *Ru
 
 // DelNetwork executes the plugin with the DEL command
 func (c *CNIConfig) DelNetwork(ctx context.Context, net *NetworkConfig, rt *RuntimeConf) error {
+	net, err := c.pinNetworkVersion(ctx, net, rt)
+	if err != nil {
+		return err
+	}
 	var cachedResult types.Result
 
-	// Cached result on DEL was added in CNI spec version 0.4.0 and higher
-	if gtet, err := version.GreaterThanOrEqualTo(net.Network.CNIVersion, "0.4.0"); err != nil {
+	if supported, err := c.FeatureGates.Supports(version.FeatureDelPrevResult, net.Network.CNIVersion); err != nil {
 		return err
-	} else if gtet {
+	} else if supported {
 		cachedResult, err = c.getCachedResult(net.Network.Name, net.Network.CNIVersion, rt)
 		if err != nil {
 			return fmt.Errorf("failed to get network %q cached result: %w", net.Network.Name, err)
@@ -665,13 +1149,15 @@ func (c *CNIConfig) DelNetwork(ctx context.Context, net *NetworkConfig, rt *Runt
 	if err := c.delNetwork(ctx, net.Network.Name, net.Network.CNIVersion, net, cachedResult, rt); err != nil {
 		return err
 	}
-	_ = c.cacheDel(net.Network.Name, rt)
+	_ = c.cacheDel(ctx, net.Network.Name, rt)
 	return nil
 }
 
 // ValidateNetworkList checks that a configuration is reasonably valid.
 // - all the specified plugins exist on disk
 // - every plugin supports the desired version.
+// - every capability enabled is supported by a plugin, for plugins which
+// advertise their capabilities (see ProbeCapabilities)
 //
 // Returns a list of all capabilities supported by the configuration, or error
 func (c *CNIConfig) ValidateNetworkList(ctx context.Context, list *NetworkConfigList) ([]string, error) {
@@ -696,6 +1182,9 @@ func (c *CNIConfig) ValidateNetworkList(ctx context.Context, list *NetworkConfig
 	if len(errs) > 0 {
 		return nil, fmt.Errorf("%v", errs)
 	}
+	if err := c.unsupportedCapabilities(ctx, list); err != nil {
+		return nil, err
+	}
 
 	// make caps list
 	cc := make([]string, 0, len(caps))
@@ -706,6 +1195,35 @@ func (c *CNIConfig) ValidateNetworkList(ctx context.Context, list *NetworkConfig
 	return cc, nil
 }
 
+// NegotiateNetworkListVersion picks the highest of the versions declared by
+// the configuration's "cniVersions" that every plugin in the list supports,
+// and sets it as the list's CNIVersion. Lists without "cniVersions" are
+// left unchanged.
+func (c *CNIConfig) NegotiateNetworkListVersion(ctx context.Context, list *NetworkConfigList) (string, error) {
+	if len(list.CNIVersions) == 0 {
+		return list.CNIVersion, nil
+	}
+
+	best, candidates := "", list.CNIVersions
+	for _, net := range list.Plugins {
+		vi, err := c.GetVersionInfo(ctx, net.Network.Type)
+		if err != nil {
+			return "", err
+		}
+		nextBest, nextCandidates, err := version.Negotiate(candidates, vi.SupportedVersions())
+		if err != nil {
+			c.log().Warn("version negotiation failed", "network", list.Name, "plugin", net.Network.Type,
+				"supported", vi.SupportedVersions(), "candidates", candidates, "error", err)
+			return "", fmt.Errorf("plugin %s: %w", net.Network.Type, err)
+		}
+		best, candidates = nextBest, nextCandidates
+	}
+
+	c.log().Info("negotiated CNI version", "network", list.Name, "version", best, "declared", list.CNIVersions)
+	list.CNIVersion = best
+	return list.CNIVersion, nil
+}
+
 // ValidateNetwork checks that a configuration is reasonably valid.
 // It uses the same logic as ValidateNetworkList)
 // Returns a list of capabilities
@@ -719,6 +1237,10 @@ func (c *CNIConfig) ValidateNetwork(ctx context.Context, net *NetworkConfig) ([]
 	if err := c.validatePlugin(ctx, net.Network.Type, net.Network.CNIVersion); err != nil {
 		return nil, err
 	}
+	list := &NetworkConfigList{Name: net.Network.Name, Plugins: []*NetworkConfig{net}}
+	if err := c.unsupportedCapabilities(ctx, list); err != nil {
+		return nil, err
+	}
 	return caps, nil
 }
 
@@ -733,7 +1255,7 @@ func (c *CNIConfig) validatePlugin(ctx context.Context, pluginName, expectedVers
 		expectedVersion = "0.1.0"
 	}
 
-	vi, err := invoke.GetVersionInfo(ctx, pluginPath, c.exec)
+	vi, err := c.versionCache.GetVersionInfo(ctx, pluginPath, c.pluginExec())
 	if err != nil {
 		return err
 	}
@@ -742,6 +1264,7 @@ func (c *CNIConfig) validatePlugin(ctx context.Context, pluginName, expectedVers
 			return nil
 		}
 	}
+	c.log().Warn("plugin does not support config version", "plugin", pluginName, "version", expectedVersion, "supported", vi.SupportedVersions())
 	return fmt.Errorf("plugin %s does not support config version %q", pluginName, expectedVersion)
 }
 
@@ -754,39 +1277,50 @@ func (c *CNIConfig) GetVersionInfo(ctx context.Context, pluginType string) (vers
 		return nil, err
 	}
 
-	return invoke.GetVersionInfo(ctx, pluginPath, c.exec)
+	return c.versionCache.GetVersionInfo(ctx, pluginPath, c.pluginExec())
 }
 
 // GCNetworkList will do two things
 // - dump the list of cached attachments, and issue deletes as necessary
 // - issue a GC to the underlying plugins (if the version is high enough)
 func (c *CNIConfig) GCNetworkList(ctx context.Context, list *NetworkConfigList, args *GCArgs) error {
-	// First, get the list of cached attachments
-	cachedAttachments, err := c.GetCachedAttachments("")
+	_, err := c.GCNetworkListWithReport(ctx, list, args)
+	return err
+}
+
+// GCNetworkListWithReport is GCNetworkList, also reporting what was
+// deleted: the stale attachments and, for plugins which print a
+// types.GCResponse, the resources they released. The report covers what
+// succeeded even when an error is returned. The errors of each attachment
+// and plugin are returned together as a *types.ErrorList.
+func (c *CNIConfig) GCNetworkListWithReport(ctx context.Context, list *NetworkConfigList, args *GCArgs) (*GCReport, error) {
+	ctx, end := c.startOperation(ctx, "GCNetworkList", list, nil)
+	report, err := c.gcNetworkList(ctx, list, args)
+	end(nil, err)
+	return report, err
+}
+
+func (c *CNIConfig) gcNetworkList(ctx context.Context, list *NetworkConfigList, args *GCArgs) (*GCReport, error) {
+	report := &GCReport{Network: list.Name}
+	pinned, err := c.pinVersion(ctx, list, nil)
 	if err != nil {
-		return nil
+		return report, err
 	}
 
-	var validAttachments map[types.GCAttachment]interface{}
-	if args != nil {
-		validAttachments = make(map[types.GCAttachment]interface{}, len(args.ValidAttachments))
-		for _, a := range args.ValidAttachments {
-			validAttachments[a] = nil
-		}
+	// First, get the list of cached attachments
+	cachedAttachments, err := c.GetCachedAttachments("")
+	if err != nil && !os.IsNotExist(err) {
+		return report, fmt.Errorf("failed to read cached attachments: %w", err)
 	}
 
-	var errs []error
+	var errs types.ErrorList
 
 	for _, cachedAttachment := range cachedAttachments {
 		if cachedAttachment.Network != list.Name {
 			continue
 		}
 		// we found this attachment
-		gca := types.GCAttachment{
-			ContainerID: cachedAttachment.ContainerID,
-			IfName:      cachedAttachment.IfName,
-		}
-		if _, ok := validAttachments[gca]; ok {
+		if args.IsValid(cachedAttachment.ContainerID, cachedAttachment.IfName) {
 			continue
 		}
 		// otherwise, this attachment wasn't valid and we should issue a CNI DEL
@@ -796,51 +1330,79 @@ func (c *CNIConfig) GCNetworkList(ctx context.Context, list *NetworkConfigList,
 			IfName:         cachedAttachment.IfName,
 			Args:           cachedAttachment.CniArgs,
 			CapabilityArgs: cachedAttachment.CapabilityArgs,
+			Metadata:       cachedAttachment.Metadata,
+			CDIDevices:     cachedAttachment.CDIDevices,
 		}
 		if err := c.DelNetworkList(ctx, list, &rt); err != nil {
-			errs = append(errs, fmt.Errorf("failed to delete stale attachment %s %s: %w", rt.ContainerID, rt.IfName, err))
+			errs.Append(fmt.Errorf("failed to delete stale attachment %s %s: %w", rt.ContainerID, rt.IfName, err))
+			continue
 		}
+		report.StaleAttachments = append(report.StaleAttachments, cachedAttachment)
 	}
 
 	// now, if the version supports it, issue a GC
-	if gt, _ := version.GreaterThanOrEqualTo(list.CNIVersion, "1.1.0"); gt {
+	if supported, _ := c.FeatureGates.Supports(version.FeatureGC, pinned.CNIVersion); supported {
 		inject := map[string]interface{}{
 			"name":       list.Name,
-			"cniVersion": list.CNIVersion,
+			"cniVersion": pinned.CNIVersion,
 		}
 		if args != nil {
-			inject["cni.dev/valid-attachments"] = args.ValidAttachments
+			inject[types.ValidAttachmentsKey] = args.ValidAttachments
 		}
 
 		for _, plugin := range list.Plugins {
+			if !c.pluginOffers(ctx, plugin.Network.Type, version.FeatureGC, pinned.CNIVersion) {
+				c.log().Debug("skipping GC of plugin not offering it", "plugin", plugin.Network.Type)
+				continue
+			}
 			// build config here
 			pluginConfig, err := InjectConf(plugin, inject)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("failed to generate configuration to GC plugin %s: %w", plugin.Network.Type, err))
+				errs.Append(fmt.Errorf("failed to generate configuration to GC plugin %s: %w", plugin.Network.Type, err))
+				continue
+			}
+			response, err := c.gcNetwork(ctx, pluginConfig)
+			if err != nil {
+				errs.Append(fmt.Errorf("failed to GC plugin %s: %w", plugin.Network.Type, err))
+				continue
 			}
-			if err := c.gcNetwork(ctx, pluginConfig); err != nil {
-				errs = append(errs, fmt.Errorf("failed to GC plugin %s: %w", plugin.Network.Type, err))
+			pr := GCPluginReport{Plugin: plugin.Network.Type}
+			if response != nil {
+				pr.Deleted = response.Deleted
 			}
+			report.Plugins = append(report.Plugins, pr)
 		}
 	}
 
-	return errors.Join(errs...)
+	return report, errs.ErrOrNil()
 }
 
-func (c *CNIConfig) gcNetwork(ctx context.Context, net *NetworkConfig) error {
+// gcNetwork issues a GC to the plugin and returns its response, if any.
+// A response which cannot be parsed is logged and ignored, since the
+// response is optional and the GC itself succeeded.
+func (c *CNIConfig) gcNetwork(ctx context.Context, net *NetworkConfig) (*types.GCResponse, error) {
 	c.ensureExec()
 	pluginPath, err := c.exec.FindInPath(net.Network.Type, c.Path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	args := c.args("GC", &RuntimeConf{})
+	args := c.args(ctx, "GC", &RuntimeConf{})
 
-	return invoke.ExecPluginWithoutResult(ctx, pluginPath, net.Bytes, args, c.exec)
+	stdout, err := c.pluginExec().ExecPlugin(ctx, pluginPath, net.Bytes, args.AsEnv())
+	if err != nil {
+		return nil, err
+	}
+	response, err := types.ParseGCResponse(stdout)
+	if err != nil {
+		c.log().Warn("ignoring GC response", "plugin", net.Network.Type, "error", err)
+		return nil, nil
+	}
+	return response, nil
 }
 
 func (c *CNIConfig) GetStatusNetworkList(ctx context.Context, list *NetworkConfigList) error {
 	// If the version doesn't support status, abort.
-	if gt, _ := version.GreaterThanOrEqualTo(list.CNIVersion, "1.1.0"); !gt {
+	if supported, _ := c.FeatureGates.Supports(version.FeatureStatus, list.CNIVersion); !supported {
 		return nil
 	}
 
@@ -850,6 +1412,10 @@ func (c *CNIConfig) GetStatusNetworkList(ctx context.Context, list *NetworkConfi
 	}
 
 	for _, plugin := range list.Plugins {
+		if !c.pluginOffers(ctx, plugin.Network.Type, version.FeatureStatus, list.CNIVersion) {
+			c.log().Debug("skipping STATUS of plugin not offering it", "plugin", plugin.Network.Type)
+			continue
+		}
 		// build config here
 		pluginConfig, err := InjectConf(plugin, inject)
 		if err != nil {
@@ -868,19 +1434,21 @@ func (c *CNIConfig) getStatusNetwork(ctx context.Context, net *NetworkConfig) er
 	if err != nil {
 		return err
 	}
-	args := c.args("STATUS", &RuntimeConf{})
+	args := c.args(ctx, "STATUS", &RuntimeConf{})
 
-	return invoke.ExecPluginWithoutResult(ctx, pluginPath, net.Bytes, args, c.exec)
+	return invoke.ExecPluginWithoutResult(ctx, pluginPath, net.Bytes, args, c.pluginExec())
 }
 
 // =====
-func (c *CNIConfig) args(action string, rt *RuntimeConf) *invoke.Args {
+func (c *CNIConfig) args(ctx context.Context, action string, rt *RuntimeConf) *invoke.Args {
 	return &invoke.Args{
-		Command:     action,
-		ContainerID: rt.ContainerID,
-		NetNS:       rt.NetNS,
-		PluginArgs:  rt.Args,
-		IfName:      rt.IfName,
-		Path:        strings.Join(c.Path, string(os.PathListSeparator)),
+		Command:       action,
+		ContainerID:   rt.ContainerID,
+		NetNS:         rt.NetNS,
+		PluginArgs:    rt.Args,
+		IfName:        rt.IfName,
+		Path:          strings.Join(c.Path, string(os.PathListSeparator)),
+		CDIDevices:    rt.CDIDevices,
+		CorrelationID: audit.CorrelationID(ctx),
 	}
 }
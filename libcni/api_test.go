@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
@@ -31,9 +32,14 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/audit"
+	"github.com/containernetworking/cni/pkg/lock"
 	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/strictjson"
+	"github.com/containernetworking/cni/pkg/tracing"
 	"github.com/containernetworking/cni/pkg/types"
 	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
 	"github.com/containernetworking/cni/pkg/version"
 	noop_debug "github.com/containernetworking/cni/plugins/test/noop/debug"
 )
@@ -151,6 +157,35 @@ func makePluginList(cniVersion, ipResult string, runtimeConfig map[string]interf
 	return netConfigList, plugins
 }
 
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]interface{}
+	err    error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...tracing.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	span := &recordedSpan{name: name, attrs: map[string]interface{}{}}
+	span.parent, _ = tracing.SpanFromContext(ctx).(*recordedSpan)
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
 func resultCacheFilePath(cacheDirPath, netName string, rt *libcni.RuntimeConf) string {
 	fName := fmt.Sprintf("%s-%s-%s", netName, rt.ContainerID, rt.IfName)
 	return filepath.Join(cacheDirPath, "results", fName)
@@ -464,6 +499,188 @@ var _ = Describe("Invoking plugins", func() {
 				})
 			})
 
+			Context("when the configuration uses a deprecated version", func() {
+				var warnings *bytes.Buffer
+
+				BeforeEach(func() {
+					debug.ReportResult = `{ "cniVersion": "0.2.0", "ip4": { "ip": "10.1.2.3/24" } }`
+					Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+
+					var err error
+					netConfig, err = libcni.ConfFromBytes([]byte(`{ "cniVersion": "0.2.0", "name": "apitest", "type": "noop" }`))
+					Expect(err).NotTo(HaveOccurred())
+					warnings = &bytes.Buffer{}
+				})
+
+				It("does not warn by default", func() {
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(warnings.String()).To(BeEmpty())
+				})
+
+				It("writes a warning when enabled", func() {
+					cniConfig.DeprecationWarnings = warnings
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(warnings.String()).To(Equal("WARNING: network \"apitest\": CNI version 0.2.0 is deprecated and will be removed in a future release; set cniVersion to 0.3.0 or later\n"))
+				})
+			})
+
+			Context("when the attachment has CDI devices", func() {
+				BeforeEach(func() {
+					runtimeConfig.CDIDevices = []string{"intel.com/sriov=vf-3"}
+				})
+
+				It("passes them to the plugin in the environment", func() {
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+
+					debug, err := noop_debug.ReadDebug(debugFilePath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(debug.CmdArgs.CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3"}))
+					Expect(string(debug.CmdArgs.StdinData)).NotTo(ContainSubstring("cdiDevices"))
+
+					attachments, err := cniConfig.GetCachedAttachments(runtimeConfig.ContainerID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(attachments).To(HaveLen(1))
+					Expect(attachments[0].CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3"}))
+				})
+
+				It("passes them in runtimeConfig to plugins with the capability", func() {
+					var err error
+					netConfig, err = libcni.ConfFromBytes([]byte(fmt.Sprintf(`{
+						"type": "noop",
+						"name": "apitest",
+						"cniVersion": "%s",
+						"capabilities": { "cdiDevices": true }
+					}`, version.Current())))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+
+					debug, err := noop_debug.ReadDebug(debugFilePath)
+					Expect(err).NotTo(HaveOccurred())
+					rc, err := capabilities.FromNetConf(debug.CmdArgs.StdinData)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(rc.CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3"}))
+				})
+
+				It("returns an error for malformed devices without executing the plugin", func() {
+					runtimeConfig.CDIDevices = []string{"vf-3"}
+
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).To(MatchError(`CDI device "vf-3" is not of the form vendor/class=name`))
+
+					debug, err := noop_debug.ReadDebug(debugFilePath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(debug.Command).To(BeEmpty())
+				})
+			})
+
+			Context("when the capability arguments are invalid", func() {
+				It("returns an error without executing the plugin", func() {
+					runtimeConfig.CapabilityArgs["portMappings"] = []portMapping{
+						{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+						{HostPort: 70000, ContainerPort: 80, Protocol: "tcp"},
+					}
+
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).To(MatchError("invalid capability arguments for plugin noop: portMappings[1].hostPort: must be between 1 and 65535, not 70000"))
+					var verr *capabilities.ValidationError
+					Expect(errors.As(err, &verr)).To(BeTrue())
+					Expect(verr.Path).To(Equal("portMappings[1].hostPort"))
+
+					debug, err := noop_debug.ReadDebug(debugFilePath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(debug.Command).To(BeEmpty())
+				})
+			})
+
+			Context("when the result has fields of draft features", func() {
+				BeforeEach(func() {
+					debug.ReportResult = `{
+						"cniVersion": "1.2.0",
+						"interfaces": [{ "name": "eth0", "annotations": { "bridge": "cni0" }, "vfIndex": 3, "rdmaDevice": "mlx5_3" }],
+						"ips": [{ "address": "10.1.2.3/24", "interface": 0 }],
+						"routes": [{ "dst": "0.0.0.0/0", "gw": "10.1.2.1", "interface": 0 }],
+						"extensions": { "io.example.foo": { "bar": 1 } }
+					}`
+					debug.ReportVersionSupport = []string{"1.2.0"}
+					Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+
+					var err error
+					netConfig, err = libcni.ConfFromBytes([]byte(`{ "cniVersion": "1.2.0", "name": "apitest", "type": "noop" }`))
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("drops them unless their gates are set", func() {
+					r, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+					result, err := current.GetResult(r)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Extensions).To(BeNil())
+					Expect(result.Routes).To(HaveLen(1))
+					Expect(result.Routes[0].Interface).To(BeNil())
+					Expect(result.Interfaces[0].Annotations).To(BeNil())
+					Expect(result.Interfaces[0].VFIndex).To(BeNil())
+					Expect(result.Interfaces[0].RDMADevice).To(BeEmpty())
+
+					By("caching them whole")
+					cniConfig.FeatureGates = version.FeatureGates{
+						version.FeatureExtensions:           true,
+						version.FeatureRouteInterface:       true,
+						version.FeatureInterfaceAnnotations: true,
+						version.FeatureInterfaceDevice:      true,
+					}
+					r, err = cniConfig.GetNetworkCachedResult(netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+					result, err = current.GetResult(r)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Extensions).To(HaveKey("io.example.foo"))
+					Expect(*result.Routes[0].Interface).To(Equal(0))
+					Expect(result.Interfaces[0].Annotations).To(Equal(map[string]string{"bridge": "cni0"}))
+					Expect(result.Interfaces[0].VFIndex).To(Equal(current.Int(3)))
+					Expect(result.Interfaces[0].RDMADevice).To(Equal("mlx5_3"))
+				})
+
+				It("returns the fields whose gates are set", func() {
+					cniConfig.FeatureGates = version.FeatureGates{version.FeatureExtensions: true}
+
+					r, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+					result, err := current.GetResult(r)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Extensions).To(HaveKey("io.example.foo"))
+					Expect(result.Routes[0].Interface).To(BeNil())
+					Expect(result.Interfaces[0].Annotations).To(BeNil())
+					Expect(result.Interfaces[0].VFIndex).To(BeNil())
+				})
+			})
+
+			Context("when decoding strictly", func() {
+				BeforeEach(func() {
+					cniConfig.StrictDecoding = &strictjson.DefaultLimits
+				})
+
+				It("rejects a plugin result with a duplicate key", func() {
+					debug.ReportResult = `{ "cniVersion": "1.0.0", "ips": [], "ips": [{ "address": "10.1.2.3/24" }] }`
+					Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+
+					result, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(result).To(BeNil())
+					var cniErr *types.Error
+					Expect(errors.As(err, &cniErr)).To(BeTrue())
+					Expect(cniErr.Code).To(Equal(uint(types.ErrDecodingFailure)))
+					Expect(cniErr.Details).To(Equal(`duplicate key "ips" at $`))
+				})
+
+				It("accepts a valid plugin result", func() {
+					_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
 			Context("when the cache directory cannot be accessed", func() {
 				It("returns an error", func() {
 					// Make the results directory inaccessible by making it a
@@ -1486,6 +1703,79 @@ var _ = Describe("Invoking plugins", func() {
 				})
 			})
 		})
+
+		Describe("PlanAddNetworkList", func() {
+			It("describes the ADD of every plugin without executing them", func() {
+				plan, err := cniConfig.PlanAddNetworkList(netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan).To(HaveLen(len(plugins)))
+
+				for i, inv := range plan {
+					Expect(inv.Type).To(Equal("noop"))
+					Expect(inv.Path).To(Equal(pluginPaths["noop"]))
+					Expect(inv.Env).To(ContainElements(
+						"CNI_COMMAND=ADD",
+						"CNI_CONTAINERID=some-container-id",
+						"CNI_NETNS=/some/netns/path",
+						"CNI_IFNAME=some-eth0",
+						"CNI_ARGS=FOO=BAR",
+						"CNI_PATH="+cniBinPath,
+					))
+
+					debug, err := noop_debug.ReadDebug(plugins[i].debugFilePath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(debug.Command).To(BeEmpty())
+				}
+				// The later plugins' expected stdin has the prevResult that
+				// only running the earlier plugins would produce
+				Expect(plan[0].StdinData).To(MatchJSON(plugins[0].stdinData))
+
+				cachedResult, err := cniConfig.GetNetworkListCachedResult(netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cachedResult).To(BeNil())
+			})
+
+			Context("when a plugin cannot be found", func() {
+				BeforeEach(func() {
+					netConfigList.Plugins[1].Network.Type = "does-not-exist"
+				})
+
+				It("returns the error", func() {
+					_, err := cniConfig.PlanAddNetworkList(netConfigList, runtimeConfig)
+					Expect(err).To(MatchError(ContainSubstring(`failed to find plugin "does-not-exist"`)))
+				})
+			})
+		})
+
+		Describe("PlanDelNetworkList", func() {
+			It("describes the DEL of every plugin in reverse order with the cached result", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				plan, err := cniConfig.PlanDelNetworkList(netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plan).To(HaveLen(len(plugins)))
+
+				cachedResult, err := cniConfig.GetNetworkListCachedResult(netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cachedResult).NotTo(BeNil())
+				cachedJSON, err := json.Marshal(cachedResult)
+				Expect(err).NotTo(HaveOccurred())
+
+				for i, inv := range plan {
+					Expect(inv.Env).To(ContainElement("CNI_COMMAND=DEL"))
+
+					var conf, expected map[string]interface{}
+					Expect(json.Unmarshal(inv.StdinData, &conf)).To(Succeed())
+					Expect(json.Unmarshal(plugins[len(plugins)-1-i].stdinData, &expected)).To(Succeed())
+					prevResult, err := json.Marshal(conf["prevResult"])
+					Expect(err).NotTo(HaveOccurred())
+					Expect(prevResult).To(MatchJSON(cachedJSON))
+					Expect(conf["some-key"]).To(Equal(expected["some-key"]))
+				}
+			})
+		})
+
 		Describe("ValidateNetworkList", func() {
 			It("Checks that all plugins exist", func() {
 				caps, err := cniConfig.ValidateNetworkList(ctx, netConfigList)
@@ -1505,6 +1795,28 @@ var _ = Describe("Invoking plugins", func() {
 				Expect(err).To(MatchError("[plugin noop does not support config version \"broken\" plugin noop does not support config version \"broken\" plugin noop does not support config version \"broken\"]"))
 			})
 		})
+
+		Describe("NegotiateNetworkListVersion", func() {
+			It("picks the highest version supported by every plugin", func() {
+				netConfigList.CNIVersions = []string{"99.0.0", version.Current(), "0.4.0"}
+				v, err := cniConfig.NegotiateNetworkListVersion(ctx, netConfigList)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v).To(Equal(version.Current()))
+				Expect(netConfigList.CNIVersion).To(Equal(version.Current()))
+			})
+
+			It("leaves lists without cniVersions unchanged", func() {
+				v, err := cniConfig.NegotiateNetworkListVersion(ctx, netConfigList)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(v).To(Equal(version.Current()))
+			})
+
+			It("fails when no version is supported by a plugin", func() {
+				netConfigList.CNIVersions = []string{"99.0.0", "98.0.0"}
+				_, err := cniConfig.NegotiateNetworkListVersion(ctx, netConfigList)
+				Expect(err).To(MatchError(ContainSubstring(`plugin noop: incompatible CNI versions: config is "99.0.0,98.0.0"`)))
+			})
+		})
 		Describe("GCNetworkList", func() {
 			It("issues a DEL and GC as necessary", func() {
 				By("doing a CNI ADD")
@@ -1577,6 +1889,88 @@ var _ = Describe("Invoking plugins", func() {
 					c.fn(commands[i])
 				}
 			})
+
+			It("releases the CDI devices of stale attachments", func() {
+				plugin := newPluginInfo("1.1.0", "some-value", "", true, ipResult, rcMap, []string{"cdiDevices"})
+				list, err := libcni.ConfListFromBytes([]byte(fmt.Sprintf(`{
+					"name": "cdi-list",
+					"cniVersion": "1.1.0",
+					"plugins": [%s]
+				}`, plugin.config)))
+				Expect(err).NotTo(HaveOccurred())
+				plugins = append(plugins, plugin)
+
+				runtimeConfig.CDIDevices = []string{"intel.com/sriov=vf-3"}
+				runtimeConfig.Metadata = map[string]string{"pod": "web"}
+				_, err = cniConfig.AddNetworkList(ctx, list, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				report, err := cniConfig.GCNetworkListWithReport(ctx, list, &libcni.GCArgs{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.StaleAttachments).To(HaveLen(1))
+				Expect(report.StaleAttachments[0].Metadata).To(Equal(map[string]string{"pod": "web"}))
+
+				commands, err := noop_debug.ReadCommandLog(plugin.commandFilePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(commands).To(HaveLen(3))
+				del := commands[1]
+				Expect(del.Command).To(Equal("DEL"))
+				Expect(del.CmdArgs.CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3"}))
+				rc, err := capabilities.FromNetConf(del.CmdArgs.StdinData)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rc.CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3"}))
+			})
+
+			It("issues a GC when nothing has been cached yet", func() {
+				err := cniConfig.GCNetworkList(ctx, netConfigList, &libcni.GCArgs{})
+				Expect(err).NotTo(HaveOccurred())
+
+				commands, err := noop_debug.ReadCommandLog(plugins[0].commandFilePath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(commands).To(HaveLen(1))
+				Expect(commands[0].Command).To(Equal("GC"))
+			})
+
+			It("reports what was deleted, by container", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				plugins[0].debug.ReportResult = fmt.Sprintf(`{
+					"cniVersion": "1.1.0",
+					"deleted": [
+						{"containerID": "leaked", "ifname": "eth0", "interfaces": ["veth1"], "ips": ["10.1.2.3/24"]},
+						{"containerID": %q, "ifname": %q, "ips": ["10.1.2.4/24"], "other": ["chain CNI-1234"]}
+					]
+				}`, runtimeConfig.ContainerID, runtimeConfig.IfName)
+				Expect(plugins[0].debug.WriteDebug(plugins[0].debugFilePath)).To(Succeed())
+
+				report, err := cniConfig.GCNetworkListWithReport(ctx, netConfigList, &libcni.GCArgs{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(report.Network).To(Equal(netConfigList.Name))
+				Expect(report.StaleAttachments).To(HaveLen(1))
+				Expect(report.StaleAttachments[0].ContainerID).To(Equal(runtimeConfig.ContainerID))
+				Expect(report.Plugins).To(HaveLen(3))
+				Expect(report.Plugins[0].Deleted).To(HaveLen(2))
+				// the other plugins print a result, which reports nothing
+				Expect(report.Plugins[1].Deleted).To(BeEmpty())
+
+				Expect(report.ByContainer()).To(Equal([]libcni.GCContainerReport{
+					{
+						ContainerID: "leaked",
+						IfNames:     []string{"eth0"},
+						Interfaces:  []string{"veth1"},
+						IPs:         []string{"10.1.2.3/24"},
+						Plugins:     []string{"noop"},
+					},
+					{
+						ContainerID: runtimeConfig.ContainerID,
+						IfNames:     []string{runtimeConfig.IfName},
+						IPs:         []string{"10.1.2.4/24"},
+						Other:       []string{"chain CNI-1234"},
+						Plugins:     []string{"noop"},
+					},
+				}))
+			})
 		})
 		Describe("GetStatusNetworkList", func() {
 			It("issues a STATUS request", func() {
@@ -1616,6 +2010,174 @@ var _ = Describe("Invoking plugins", func() {
 				Expect(debug.Command).To(Equal(""))
 			})
 		})
+
+		Describe("Tracing", func() {
+			var tracer *recordingTracer
+
+			BeforeEach(func() {
+				tracer = &recordingTracer{}
+				cniConfig.Tracer = tracer
+			})
+
+			It("traces the operation and each plugin execution", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tracer.spans).To(HaveLen(4))
+				op := tracer.spans[0]
+				Expect(op.name).To(Equal("AddNetworkList"))
+				Expect(op.parent).To(BeNil())
+				Expect(op.attrs).To(HaveKeyWithValue(tracing.AttrNetworkName, "some-list"))
+				Expect(op.attrs).To(HaveKeyWithValue(tracing.AttrContainerID, "some-container-id"))
+				Expect(op.attrs).To(HaveKeyWithValue(tracing.AttrIfName, "some-eth0"))
+				Expect(op.ended).To(BeTrue())
+				Expect(op.err).NotTo(HaveOccurred())
+
+				for _, span := range tracer.spans[1:] {
+					Expect(span.name).To(Equal("CNI ADD"))
+					Expect(span.parent).To(BeIdenticalTo(op))
+					Expect(span.attrs).To(HaveKeyWithValue(tracing.AttrCommand, "ADD"))
+					Expect(span.attrs).To(HaveKeyWithValue(tracing.AttrPluginType, "noop"))
+					Expect(span.attrs).To(HaveKeyWithValue(tracing.AttrExitCode, 0))
+					Expect(span.ended).To(BeTrue())
+				}
+			})
+
+			It("records a failing plugin's error and exit code", func() {
+				plugins[1].debug.ReportError = "plugin error: banana"
+				plugins[1].debug.ReportErrorCode = 50
+				Expect(plugins[1].debug.WriteDebug(plugins[1].debugFilePath)).To(Succeed())
+
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).To(HaveOccurred())
+
+				Expect(tracer.spans).To(HaveLen(3))
+				Expect(tracer.spans[0].err).To(MatchError(err))
+				failed := tracer.spans[2]
+				Expect(failed.err).To(MatchError("plugin error: banana"))
+				Expect(failed.attrs).To(HaveKeyWithValue(tracing.AttrErrorCode, 50))
+				Expect(failed.attrs).To(HaveKeyWithValue(tracing.AttrExitCode, 1))
+				Expect(failed.ended).To(BeTrue())
+			})
+
+			It("nests the DEL of stale attachments in the GC span", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				tracer.spans = nil
+
+				Expect(cniConfig.GCNetworkList(ctx, netConfigList, &libcni.GCArgs{})).To(Succeed())
+				Expect(tracer.spans[0].name).To(Equal("GCNetworkList"))
+				Expect(tracer.spans[1].name).To(Equal("DelNetworkList"))
+				Expect(tracer.spans[1].parent).To(BeIdenticalTo(tracer.spans[0]))
+				Expect(tracer.spans[2].name).To(Equal("CNI DEL"))
+				Expect(tracer.spans[2].parent).To(BeIdenticalTo(tracer.spans[1]))
+			})
+		})
+
+		Describe("Auditing", func() {
+			var records []*audit.Record
+
+			BeforeEach(func() {
+				records = nil
+				cniConfig.Audit = audit.SinkFunc(func(r *audit.Record) error {
+					records = append(records, r)
+					return nil
+				})
+			})
+
+			It("records the operation with its configuration and result", func() {
+				result, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(records).To(HaveLen(1))
+				r := records[0]
+				Expect(r.Source).To(Equal(audit.SourceRuntime))
+				Expect(r.Operation).To(Equal("AddNetworkList"))
+				Expect(r.Network).To(Equal("some-list"))
+				Expect(r.ContainerID).To(Equal("some-container-id"))
+				Expect(r.IfName).To(Equal("some-eth0"))
+				Expect(r.CorrelationID).To(HaveLen(32))
+				Expect(r.ConfigHash).To(Equal(audit.Hash(netConfigList.Bytes)))
+				Expect(r.ResultHash).To(Equal(audit.Hash(result)))
+				Expect(r.Outcome).To(Equal(audit.OutcomeSuccess))
+				Expect(r.Actor.PID).To(Equal(os.Getpid()))
+			})
+
+			It("records failures", func() {
+				plugins[1].debug.ReportError = "plugin error: banana"
+				plugins[1].debug.ReportErrorCode = 50
+				Expect(plugins[1].debug.WriteDebug(plugins[1].debugFilePath)).To(Succeed())
+
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).To(HaveOccurred())
+
+				Expect(records).To(HaveLen(1))
+				Expect(records[0].Outcome).To(Equal(audit.OutcomeFailure))
+				Expect(records[0].ErrorCode).To(Equal(uint(50)))
+				Expect(records[0].ResultHash).To(BeEmpty())
+			})
+
+			It("records nested operations with the caller's correlation ID", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				records = nil
+
+				gcCtx := audit.WithCorrelationID(ctx, "some-request")
+				Expect(cniConfig.GCNetworkList(gcCtx, netConfigList, &libcni.GCArgs{})).To(Succeed())
+				Expect(records).To(HaveLen(2))
+				Expect(records[0].Operation).To(Equal("DelNetworkList"))
+				Expect(records[1].Operation).To(Equal("GCNetworkList"))
+				for _, r := range records {
+					Expect(r.CorrelationID).To(Equal("some-request"))
+				}
+			})
+		})
+
+		Describe("Logging", func() {
+			var logs *bytes.Buffer
+
+			BeforeEach(func() {
+				logs = &bytes.Buffer{}
+				cniConfig.Logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			})
+
+			It("logs plugin executions and cache operations", func() {
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cniConfig.DelNetworkList(ctx, netConfigList, runtimeConfig)).To(Succeed())
+
+				Expect(strings.Count(logs.String(), `msg="plugin succeeded" plugin=noop command=ADD`)).To(Equal(3))
+				Expect(strings.Count(logs.String(), `msg="plugin succeeded" plugin=noop command=DEL`)).To(Equal(3))
+				Expect(logs.String()).To(ContainSubstring(`level=DEBUG msg="cached result" network=some-list containerID=some-container-id ifName=some-eth0`))
+				Expect(logs.String()).To(ContainSubstring(`level=DEBUG msg="removed cached result" network=some-list containerID=some-container-id ifName=some-eth0`))
+			})
+
+			It("logs failed plugins", func() {
+				plugins[1].debug.ReportError = "plugin error: banana"
+				Expect(plugins[1].debug.WriteDebug(plugins[1].debugFilePath)).To(Succeed())
+
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).To(HaveOccurred())
+				Expect(logs.String()).To(ContainSubstring(`level=WARN msg="plugin failed" plugin=noop command=ADD`))
+				Expect(logs.String()).To(ContainSubstring(`error="plugin error: banana"`))
+			})
+
+			It("logs invalid attachments", func() {
+				runtimeConfig.ContainerID = "some/container"
+
+				_, err := cniConfig.AddNetworkList(ctx, netConfigList, runtimeConfig)
+				Expect(err).To(HaveOccurred())
+				Expect(logs.String()).To(ContainSubstring(`level=WARN msg="invalid attachment" network=some-list containerID=some/container`))
+			})
+
+			It("logs the negotiated version", func() {
+				netConfigList.CNIVersions = []string{"1.0.0", version.Current()}
+
+				_, err := cniConfig.NegotiateNetworkListVersion(ctx, netConfigList)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logs.String()).To(ContainSubstring(fmt.Sprintf(`level=INFO msg="negotiated CNI version" network=some-list version=%s`, version.Current())))
+			})
+		})
 	})
 
 	Describe("Invoking a sleep plugin", func() {
@@ -1847,6 +2409,38 @@ var _ = Describe("Invoking plugins", func() {
 			Expect(os.RemoveAll(debugFilePath)).To(Succeed())
 		})
 
+		It("waits for the cache lock before changing the cache", func() {
+			cacheLock, err := lock.New(filepath.Join(cacheDirPath, "cache.lock"))
+			Expect(err).NotTo(HaveOccurred())
+			defer cacheLock.Close()
+			Expect(cacheLock.Lock(ctx)).To(Succeed())
+
+			added := make(chan error, 1)
+			go func() {
+				_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+				added <- err
+			}()
+			Consistently(added, "200ms").ShouldNot(Receive())
+
+			Expect(cacheLock.Unlock()).To(Succeed())
+			Eventually(added, "5s").Should(Receive(BeNil()))
+			_, err = os.Stat(resultCacheFilePath(cacheDirPath, netName, runtimeConfig))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("stops waiting for the cache lock when the context is done", func() {
+			cacheLock, err := lock.New(filepath.Join(cacheDirPath, "cache.lock"))
+			Expect(err).NotTo(HaveOccurred())
+			defer cacheLock.Close()
+			Expect(cacheLock.Lock(ctx)).To(Succeed())
+
+			deadlineCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			_, err = cniConfig.AddNetwork(deadlineCtx, netConfig, runtimeConfig)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("held by process %d", os.Getpid()))))
+		})
+
 		It("creates separate result cache files for multiple attachments to the same network", func() {
 			_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
 			Expect(err).NotTo(HaveOccurred())
@@ -1917,6 +2511,60 @@ var _ = Describe("Invoking plugins", func() {
 			Expect(foundCABytes).To(MatchJSON(expectedCABytes))
 		})
 
+		It("finds cached attachments by metadata", func() {
+			runtimeConfig.Metadata = map[string]string{"namespace": "ns1", "pod": "pod1"}
+			_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+			runtimeConfig.IfName = secondIfname
+			runtimeConfig.Metadata = map[string]string{"namespace": "ns1", "pod": "pod2"}
+			_, err = cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+			Expect(err).NotTo(HaveOccurred())
+
+			attachments, err := cniConfig.GetCachedAttachmentsByMetadata(map[string]string{"namespace": "ns1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachments).To(HaveLen(2))
+
+			attachments, err = cniConfig.GetCachedAttachmentsByMetadata(map[string]string{"namespace": "ns1", "pod": "pod2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachments).To(HaveLen(1))
+			Expect(attachments[0].IfName).To(Equal(secondIfname))
+			Expect(attachments[0].Metadata).To(Equal(map[string]string{"namespace": "ns1", "pod": "pod2"}))
+
+			attachments, err = cniConfig.GetCachedAttachmentsByMetadata(map[string]string{"namespace": "ns2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachments).To(BeEmpty())
+
+			// the metadata is not passed to the plugin
+			recorded, err := noop_debug.ReadDebug(debugFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(recorded.CmdArgs.StdinData)).NotTo(ContainSubstring("pod2"))
+
+			rt := &libcni.RuntimeConf{ContainerID: containerID, IfName: secondIfname}
+			_, newRt, err := cniConfig.GetNetworkListCachedConfig(&libcni.NetworkConfigList{Name: netName}, rt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newRt.Metadata).To(HaveKeyWithValue("pod", "pod2"))
+		})
+
+		It("removes a cached attachment without invoking the plugin", func() {
+			_, err := cniConfig.AddNetwork(ctx, netConfig, runtimeConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Remove(debugFilePath)).To(Succeed())
+
+			rt := &libcni.RuntimeConf{ContainerID: containerID, IfName: firstIfname}
+			Expect(cniConfig.RemoveCachedAttachment(netName, rt)).To(Succeed())
+
+			attachments, err := cniConfig.GetCachedAttachments(containerID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachments).To(BeEmpty())
+			_, err = os.Stat(debugFilePath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			err = cniConfig.RemoveCachedAttachment(netName, rt)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
 		Context("when the RuntimeConf is incomplete", func() {
 			var (
 				testRt          *libcni.RuntimeConf
@@ -0,0 +1,319 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// CacheArchiveKind identifies the manifest of an archive written by
+	// ExportCache
+	CacheArchiveKind = "cniCacheArchive"
+	// CacheArchiveVersion is the version of the archive format
+	CacheArchiveVersion = 1
+
+	cacheArchiveManifest = "manifest.json"
+	cacheArchiveResults  = "results"
+	// maxCacheArchiveEntry bounds the size of an imported entry
+	maxCacheArchiveEntry = 16 << 20
+)
+
+// cacheManifest is the first file of a cache archive
+type cacheManifest struct {
+	Kind      string    `json:"kind"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Entries   int       `json:"entries"`
+}
+
+// CacheImportOptions control ImportCache
+type CacheImportOptions struct {
+	// Overwrite replaces the cached attachments which are also in the
+	// archive. Without it the import fails if any is.
+	Overwrite bool
+}
+
+// ExportCache writes the cached attachments, with their results, configs
+// and metadata, to a gzipped tar archive which ImportCache restores into
+// another cache directory, for reprovisioning a node or backing up its
+// network state. Entries which InspectCache finds corrupt and legacy
+// entries, which lack the attachment, are left out. The exported
+// attachments are returned.
+func (c *CNIConfig) ExportCache(w io.Writer) ([]*NetworkAttachment, error) {
+	if err := c.FlushCache(); err != nil {
+		return nil, err
+	}
+	dirPath := filepath.Join(c.getCacheDir(&RuntimeConf{}), cacheArchiveResults)
+	unlock, err := c.lockCache(context.Background(), &RuntimeConf{})
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	type exported struct {
+		name string
+		data []byte
+	}
+	var files []exported
+	attachments := []*NetworkAttachment{}
+	for _, de := range dirEntries {
+		if !de.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dirPath, de.Name()))
+		if err != nil {
+			return nil, err
+		}
+		attachment, err := c.decodeCacheEntry(data)
+		if err != nil {
+			continue
+		}
+		files = append(files, exported{name: de.Name(), data: data})
+		attachments = append(attachments, attachment)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now().UTC()
+	manifest, err := json.Marshal(&cacheManifest{
+		Kind:      CacheArchiveKind,
+		Version:   CacheArchiveVersion,
+		CreatedAt: now,
+		Entries:   len(files),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, cacheArchiveManifest, manifest, now); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		if err := writeTarFile(tw, path.Join(cacheArchiveResults, f.name), f.data, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o600,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// ImportCache restores the attachments of an archive written by
+// ExportCache into the cache, and returns them. Every entry is checked
+// before any is written, and entries are stored under the name the
+// cache expects for their attachment, whatever their name in the archive.
+// If an entry cannot be stored, those already stored are removed and the
+// entries they overwrote restored.
+func (c *CNIConfig) ImportCache(r io.Reader, opts *CacheImportOptions) ([]*NetworkAttachment, error) {
+	if opts == nil {
+		opts = &CacheImportOptions{}
+	}
+	type imported struct {
+		attachment *NetworkAttachment
+		target     string
+		data       []byte
+	}
+	var entries []imported
+	targets := map[string]string{}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	sawManifest := false
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading cache archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("cache archive entry %s is not a regular file", hdr.Name)
+		}
+		if hdr.Size > maxCacheArchiveEntry {
+			return nil, fmt.Errorf("cache archive entry %s is too large", hdr.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, maxCacheArchiveEntry))
+		if err != nil {
+			return nil, fmt.Errorf("error reading cache archive: %w", err)
+		}
+
+		if !sawManifest {
+			if hdr.Name != cacheArchiveManifest {
+				return nil, errors.New("cache archive has no manifest")
+			}
+			manifest := cacheManifest{}
+			if err := json.Unmarshal(data, &manifest); err != nil {
+				return nil, fmt.Errorf("invalid cache archive manifest: %w", err)
+			}
+			if manifest.Kind != CacheArchiveKind || manifest.Version != CacheArchiveVersion {
+				return nil, fmt.Errorf("unsupported cache archive %q version %d", manifest.Kind, manifest.Version)
+			}
+			sawManifest = true
+			continue
+		}
+		if path.Dir(hdr.Name) != cacheArchiveResults {
+			return nil, fmt.Errorf("unexpected cache archive entry %s", hdr.Name)
+		}
+
+		attachment, err := c.decodeCacheEntry(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache archive entry %s: %w", hdr.Name, err)
+		}
+		target, err := c.getCacheFilePath(attachment.Network, &RuntimeConf{
+			ContainerID: attachment.ContainerID,
+			IfName:      attachment.IfName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := targets[target]; ok {
+			return nil, fmt.Errorf("cache archive entries %s and %s are the same attachment", other, hdr.Name)
+		}
+		targets[target] = hdr.Name
+		entries = append(entries, imported{attachment: attachment, target: target, data: data})
+	}
+	if !sawManifest {
+		return nil, errors.New("cache archive has no manifest")
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].target < entries[j].target })
+
+	if err := c.FlushCache(); err != nil {
+		return nil, err
+	}
+	cacheDir := c.getCacheDir(&RuntimeConf{})
+	unlock, err := c.lockCache(context.Background(), &RuntimeConf{})
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	if !opts.Overwrite {
+		for _, entry := range entries {
+			if _, err := os.Lstat(entry.target); err == nil {
+				return nil, fmt.Errorf("attachment %s/%s/%s is already cached", entry.attachment.Network, entry.attachment.ContainerID, entry.attachment.IfName)
+			}
+		}
+	}
+
+	// Entries are written aside, then moved into place once all are. The
+	// entries they overwrite are moved aside until every entry is in place.
+	resultsDir := filepath.Join(cacheDir, cacheArchiveResults)
+	if err := os.MkdirAll(resultsDir, 0o700); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(cacheDir, "import-")
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if err := os.WriteFile(filepath.Join(staging, strconv.Itoa(i)), entry.data, 0o600); err != nil {
+			_ = os.RemoveAll(staging)
+			return nil, err
+		}
+	}
+	var undo [][2]string
+	rollback := func(cause error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := os.Rename(undo[i][1], undo[i][0]); err != nil {
+				return fmt.Errorf("%w; restoring the cache failed, overwritten entries are kept in %s: %v", cause, staging, err)
+			}
+		}
+		_ = os.RemoveAll(staging)
+		return cause
+	}
+
+	for i, entry := range entries {
+		if info, err := os.Lstat(entry.target); err == nil {
+			if !info.Mode().IsRegular() {
+				return nil, rollback(fmt.Errorf("cannot import cache entry %s: it is not a regular file", entry.target))
+			}
+			old := filepath.Join(staging, "old-"+strconv.Itoa(i))
+			if err := os.Rename(entry.target, old); err != nil {
+				return nil, rollback(err)
+			}
+			undo = append(undo, [2]string{entry.target, old})
+		}
+		imported := filepath.Join(staging, strconv.Itoa(i))
+		if err := os.Rename(imported, entry.target); err != nil {
+			return nil, rollback(err)
+		}
+		undo = append(undo, [2]string{imported, entry.target})
+	}
+
+	if err := os.RemoveAll(staging); err != nil {
+		c.log().Warn("failed to remove overwritten cache entries", "path", staging, "error", err)
+	}
+	attachments := make([]*NetworkAttachment, 0, len(entries))
+	for _, entry := range entries {
+		attachments = append(attachments, entry.attachment)
+		c.log().Info("imported cache entry", "path", entry.target)
+	}
+	return attachments, nil
+}
+
+// decodeCacheEntry parses a cache entry of kind CNICacheV1 and returns
+// its attachment
+func (c *CNIConfig) decodeCacheEntry(data []byte) (*NetworkAttachment, error) {
+	if err := c.checkStrict(data); err != nil {
+		return nil, err
+	}
+	cached := cachedInfo{}
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if cached.Kind != CNICacheV1 {
+		return nil, fmt.Errorf("unknown kind %q", cached.Kind)
+	}
+	return cachedAttachment(&cached)
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+const benchConfList = `{
+	"cniVersion": "1.0.0",
+	"name": "bench",
+	"plugins": [
+		{
+			"type": "bridge",
+			"bridge": "cni0",
+			"isGateway": true,
+			"ipMasq": true,
+			"ipam": {
+				"type": "host-local",
+				"ranges": [[{"subnet": "10.22.0.0/16"}]],
+				"routes": [{"dst": "0.0.0.0/0"}]
+			}
+		},
+		{
+			"type": "portmap",
+			"capabilities": {"portMappings": true}
+		},
+		{
+			"type": "bandwidth",
+			"capabilities": {"bandwidth": true}
+		}
+	]
+}`
+
+const benchResult = `{
+	"cniVersion": "1.0.0",
+	"interfaces": [
+		{"name": "cni0", "mac": "00:11:22:33:44:55"},
+		{"name": "veth1234", "mac": "00:11:22:33:44:66"},
+		{"name": "eth0", "mac": "00:11:22:33:44:77", "sandbox": "/var/run/netns/bench"}
+	],
+	"ips": [{"interface": 2, "address": "10.22.0.5/16", "gateway": "10.22.0.1"}],
+	"routes": [{"dst": "0.0.0.0/0", "gw": "10.22.0.1"}],
+	"dns": {}
+}`
+
+// benchExec is an invoke.Exec answering every plugin with benchResult, so
+// that benchmarks measure libcni rather than the plugins
+type benchExec struct {
+	version.PluginDecoder
+}
+
+func (*benchExec) ExecPlugin(_ context.Context, _ string, _ []byte, _ []string) ([]byte, error) {
+	return []byte(benchResult), nil
+}
+
+func (*benchExec) FindInPath(plugin string, _ []string) (string, error) {
+	return filepath.Join("/opt/cni/bin", plugin), nil
+}
+
+func benchRuntimeConf(i int) *libcni.RuntimeConf {
+	return &libcni.RuntimeConf{
+		ContainerID: fmt.Sprintf("bench-%d", i),
+		NetNS:       "/var/run/netns/bench",
+		IfName:      "eth0",
+		Args:        [][2]string{{"K8S_POD_NAMESPACE", "default"}, {"K8S_POD_NAME", "bench"}},
+		CapabilityArgs: map[string]interface{}{
+			"portMappings": []map[string]interface{}{{"hostPort": 8080, "containerPort": 80, "protocol": "tcp"}},
+		},
+	}
+}
+
+func BenchmarkConfListFromBytes(b *testing.B) {
+	data := []byte(benchConfList)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := libcni.ConfListFromBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddNetworkList(b *testing.B) {
+	list, err := libcni.ConfListFromBytes([]byte(benchConfList))
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, b.TempDir(), &benchExec{})
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.AddNetworkList(ctx, list, benchRuntimeConf(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAddNetworkListWriteBehind(b *testing.B) {
+	list, err := libcni.ConfListFromBytes([]byte(benchConfList))
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, b.TempDir(), &benchExec{})
+	if err := c.EnableWriteBehind(nil); err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.AddNetworkList(ctx, list, benchRuntimeConf(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	if err := c.DisableWriteBehind(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkDelNetworkList(b *testing.B) {
+	list, err := libcni.ConfListFromBytes([]byte(benchConfList))
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, b.TempDir(), &benchExec{})
+	ctx := context.Background()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.AddNetworkList(ctx, list, benchRuntimeConf(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := c.DelNetworkList(ctx, list, benchRuntimeConf(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetNetworkListCachedResult(b *testing.B) {
+	list, err := libcni.ConfListFromBytes([]byte(benchConfList))
+	if err != nil {
+		b.Fatal(err)
+	}
+	c := libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, b.TempDir(), &benchExec{})
+	rt := benchRuntimeConf(0)
+	if _, err := c.AddNetworkList(context.Background(), list, rt); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.GetNetworkListCachedResult(list, rt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
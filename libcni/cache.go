@@ -0,0 +1,321 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types/create"
+)
+
+// CacheProblem classifies the cache entries found by InspectCache
+type CacheProblem string
+
+const (
+	// CacheOK entries are usable as they are
+	CacheOK CacheProblem = ""
+	// CacheCorrupt entries cannot be parsed, have an unknown kind, lack
+	// the network name, container ID or interface name, or hold an
+	// unusable result. Repair removes them.
+	CacheCorrupt CacheProblem = "corrupt"
+	// CacheOrphaned entries belong to a container which is gone. Repair
+	// removes them.
+	CacheOrphaned CacheProblem = "orphaned"
+	// CacheDuplicate entries are older entries for a container ID and
+	// interface name which has a newer entry. Repair removes them.
+	CacheDuplicate CacheProblem = "duplicate"
+	// CacheMisnamed entries are valid but stored under a file name which
+	// does not match their contents, so lookups miss them. Repair
+	// renames them.
+	CacheMisnamed CacheProblem = "misnamed"
+)
+
+// CacheEntry is one file of the results cache, as found by InspectCache
+type CacheEntry struct {
+	Path    string
+	ModTime time.Time
+	Problem CacheProblem
+	// Reason describes the problem
+	Reason string
+	// Attachment is nil for corrupt entries and for entries written in
+	// the legacy format, which held only the result
+	Attachment *NetworkAttachment
+
+	readable bool
+	digest   [sha256.Size]byte
+	// target is the path a misnamed entry is renamed to
+	target string
+}
+
+// CacheInspectOptions control InspectCache
+type CacheInspectOptions struct {
+	// Orphaned reports whether the container of an attachment is gone.
+	// By default an attachment is orphaned when its network namespace
+	// path no longer exists, as happens to namespaces mounted in a tmpfs
+	// after an unclean shutdown. Runtimes which know their containers
+	// should check those instead.
+	Orphaned func(*NetworkAttachment) bool
+}
+
+func netnsGone(att *NetworkAttachment) bool {
+	if att.NetNS == "" {
+		return false
+	}
+	_, err := os.Stat(att.NetNS)
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// InspectCache scans the results cache and classifies every entry, sorted
+// by path. Entries are only read: RepairCache acts on the problems found.
+func (c *CNIConfig) InspectCache(opts *CacheInspectOptions) ([]*CacheEntry, error) {
+	orphaned := netnsGone
+	if opts != nil && opts.Orphaned != nil {
+		orphaned = opts.Orphaned
+	}
+	if err := c.FlushCache(); err != nil {
+		return nil, err
+	}
+
+	dirPath := filepath.Join(c.getCacheDir(&RuntimeConf{}), "results")
+	dirEntries, err := os.ReadDir(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries := []*CacheEntry{}
+	for _, de := range dirEntries {
+		if de.IsDir() {
+			continue
+		}
+		entry := &CacheEntry{Path: filepath.Join(dirPath, de.Name())}
+		if info, err := de.Info(); err == nil {
+			entry.ModTime = info.ModTime()
+		}
+		classifyCacheEntry(entry)
+		if entry.Attachment != nil && entry.Problem == CacheOK && orphaned(entry.Attachment) {
+			entry.Problem = CacheOrphaned
+			entry.Reason = "the container is gone"
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+
+	// An interface can only be attached once: keep the newest entry
+	byInterface := map[[2]string][]*CacheEntry{}
+	for _, entry := range entries {
+		if entry.Attachment == nil || entry.Problem != CacheOK {
+			continue
+		}
+		key := [2]string{entry.Attachment.ContainerID, entry.Attachment.IfName}
+		byInterface[key] = append(byInterface[key], entry)
+	}
+	for _, group := range byInterface {
+		newest := group[0]
+		for _, entry := range group[1:] {
+			if !entry.ModTime.Before(newest.ModTime) {
+				newest = entry
+			}
+		}
+		for _, entry := range group {
+			if entry != newest {
+				entry.Problem = CacheDuplicate
+				entry.Reason = fmt.Sprintf("superseded by %s", filepath.Base(newest.Path))
+			}
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Attachment == nil || entry.Problem != CacheOK {
+			continue
+		}
+		target, err := c.getCacheFilePath(entry.Attachment.Network, &RuntimeConf{
+			ContainerID: entry.Attachment.ContainerID,
+			IfName:      entry.Attachment.IfName,
+		})
+		if err == nil && target != entry.Path {
+			entry.Problem = CacheMisnamed
+			entry.Reason = fmt.Sprintf("should be named %s", filepath.Base(target))
+			entry.target = target
+		}
+	}
+	return entries, nil
+}
+
+// classifyCacheEntry reads the entry's file and sets its digest, attachment
+// and whether it is corrupt
+func classifyCacheEntry(entry *CacheEntry) {
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		entry.Problem = CacheCorrupt
+		entry.Reason = err.Error()
+		return
+	}
+	entry.readable = true
+	entry.digest = sha256.Sum256(data)
+
+	cached := cachedInfo{}
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Kind != CNICacheV1 {
+		if _, legacyErr := create.CreateFromBytes(data); legacyErr == nil {
+			return
+		}
+		entry.Problem = CacheCorrupt
+		if err != nil {
+			entry.Reason = fmt.Sprintf("invalid JSON: %v", err)
+		} else {
+			entry.Reason = fmt.Sprintf("unknown kind %q", cached.Kind)
+		}
+		return
+	}
+	attachment, err := cachedAttachment(&cached)
+	if err != nil {
+		entry.Problem = CacheCorrupt
+		entry.Reason = err.Error()
+		return
+	}
+	entry.Attachment = attachment
+}
+
+// cachedAttachment checks a cache entry of kind CNICacheV1 and returns its
+// attachment
+func cachedAttachment(cached *cachedInfo) (*NetworkAttachment, error) {
+	if cached.NetworkName == "" || cached.ContainerID == "" || cached.IfName == "" {
+		return nil, errors.New("missing network name, container ID or interface name")
+	}
+	if cached.RawResult != nil {
+		resultBytes, err := json.Marshal(cached.RawResult)
+		if err == nil {
+			_, err = create.CreateFromBytes(resultBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unusable result: %v", err)
+		}
+	}
+
+	return &NetworkAttachment{
+		ContainerID:    cached.ContainerID,
+		Network:        cached.NetworkName,
+		IfName:         cached.IfName,
+		Config:         cached.Config,
+		NetNS:          cached.NetNS,
+		CniArgs:        cached.CniArgs,
+		CapabilityArgs: cached.CapabilityArgs,
+		Metadata:       cached.Metadata,
+		CDIDevices:     cached.CDIDevices,
+	}, nil
+}
+
+// unchanged reports whether the entry's file is as it was inspected
+func (e *CacheEntry) unchanged() bool {
+	data, err := os.ReadFile(e.Path)
+	if err != nil {
+		return !e.readable && !errors.Is(err, os.ErrNotExist)
+	}
+	return e.readable && sha256.Sum256(data) == e.digest
+}
+
+// RepairCache removes the corrupt, orphaned and duplicate entries and
+// renames the misnamed ones, as classified by InspectCache. Healthy
+// entries are ignored.
+//
+// The repair is all or nothing: it holds the cache lock, fails without
+// changing anything if an entry changed since it was inspected, and
+// restores the entries already handled if an operation fails.
+func (c *CNIConfig) RepairCache(entries []*CacheEntry) error {
+	var removals, renames []*CacheEntry
+	for _, entry := range entries {
+		switch entry.Problem {
+		case CacheOK:
+		case CacheMisnamed:
+			renames = append(renames, entry)
+		default:
+			removals = append(removals, entry)
+		}
+	}
+	if len(removals) == 0 && len(renames) == 0 {
+		return nil
+	}
+	if err := c.FlushCache(); err != nil {
+		return err
+	}
+
+	cacheDir := c.getCacheDir(&RuntimeConf{})
+	unlock, err := c.lockCache(context.Background(), &RuntimeConf{})
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, entry := range append(removals, renames...) {
+		if !entry.unchanged() {
+			return fmt.Errorf("cache entry %s changed since it was inspected", entry.Path)
+		}
+	}
+
+	// Removed entries are moved aside until every operation succeeded
+	staging, err := os.MkdirTemp(cacheDir, "repair-")
+	if err != nil {
+		return err
+	}
+	var undo [][2]string
+	rollback := func(cause error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err := os.Rename(undo[i][1], undo[i][0]); err != nil {
+				return fmt.Errorf("%w; restoring the cache failed, removed entries are kept in %s: %v", cause, staging, err)
+			}
+		}
+		_ = os.RemoveAll(staging)
+		return cause
+	}
+
+	for i, entry := range removals {
+		moved := filepath.Join(staging, strconv.Itoa(i))
+		if err := os.Rename(entry.Path, moved); err != nil {
+			return rollback(err)
+		}
+		undo = append(undo, [2]string{entry.Path, moved})
+	}
+	for _, entry := range renames {
+		if _, err := os.Lstat(entry.target); err == nil {
+			return rollback(fmt.Errorf("cannot rename cache entry %s: %s exists", entry.Path, entry.target))
+		}
+		if err := os.Rename(entry.Path, entry.target); err != nil {
+			return rollback(err)
+		}
+		undo = append(undo, [2]string{entry.Path, entry.target})
+	}
+
+	if err := os.RemoveAll(staging); err != nil {
+		c.log().Warn("failed to remove repaired cache entries", "path", staging, "error", err)
+	}
+	for _, entry := range removals {
+		c.log().Info("removed cache entry", "path", entry.Path, "problem", string(entry.Problem), "reason", entry.Reason)
+	}
+	for _, entry := range renames {
+		c.log().Info("renamed cache entry", "path", entry.Path, "target", entry.target)
+	}
+	return nil
+}
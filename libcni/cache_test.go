@@ -0,0 +1,288 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+)
+
+var _ = Describe("Cache inspection and repair", func() {
+	var (
+		cacheDir   string
+		resultsDir string
+		cniConfig  *libcni.CNIConfig
+		netnsPath  string
+	)
+
+	writeEntry := func(fname, network, containerID, ifName, netns string, age time.Duration) string {
+		data, err := json.Marshal(map[string]interface{}{
+			"kind":        libcni.CNICacheV1,
+			"containerId": containerID,
+			"config":      []byte(`{"cniVersion":"1.0.0","name":"` + network + `","type":"noop"}`),
+			"ifName":      ifName,
+			"networkName": network,
+			"netns":       netns,
+			"result":      map[string]interface{}{"cniVersion": "1.0.0"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		path := filepath.Join(resultsDir, fname)
+		Expect(os.WriteFile(path, data, 0o600)).To(Succeed())
+		mtime := time.Now().Add(-age)
+		Expect(os.Chtimes(path, mtime, mtime)).To(Succeed())
+		return path
+	}
+
+	problems := func(entries []*libcni.CacheEntry) map[string]libcni.CacheProblem {
+		m := map[string]libcni.CacheProblem{}
+		for _, e := range entries {
+			m[filepath.Base(e.Path)] = e.Problem
+		}
+		return m
+	}
+
+	BeforeEach(func() {
+		var err error
+		cacheDir, err = os.MkdirTemp("", "cni-cache")
+		Expect(err).NotTo(HaveOccurred())
+		resultsDir = filepath.Join(cacheDir, "results")
+		Expect(os.MkdirAll(resultsDir, 0o700)).To(Succeed())
+		netnsPath = filepath.Join(cacheDir, "netns")
+		Expect(os.WriteFile(netnsPath, nil, 0o600)).To(Succeed())
+		cniConfig = libcni.NewCNIConfigWithCacheDir(nil, cacheDir, nil)
+
+		writeEntry("net1-ctr1-eth0", "net1", "ctr1", "eth0", netnsPath, 0)
+		Expect(os.WriteFile(filepath.Join(resultsDir, "net1-ctr2-eth0"), []byte(`{"kind":`), 0o600)).To(Succeed())
+		writeEntry("net1-ctr3-eth0", "net1", "ctr3", "eth0", filepath.Join(cacheDir, "gone"), 0)
+		writeEntry("net1-ctr4-eth0", "net1", "ctr4", "eth0", netnsPath, time.Hour)
+		writeEntry("net2-ctr4-eth0", "net2", "ctr4", "eth0", netnsPath, 0)
+		writeEntry("renamed", "net1", "ctr5", "eth0", netnsPath, 0)
+		// results cached before the attachment format are not corrupt
+		Expect(os.WriteFile(filepath.Join(resultsDir, "net1-ctr6-eth0"), []byte(`{"cniVersion":"0.4.0"}`), 0o600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(cacheDir)).To(Succeed())
+	})
+
+	It("classifies the cache entries", func() {
+		entries, err := cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems(entries)).To(Equal(map[string]libcni.CacheProblem{
+			"net1-ctr1-eth0": libcni.CacheOK,
+			"net1-ctr2-eth0": libcni.CacheCorrupt,
+			"net1-ctr3-eth0": libcni.CacheOrphaned,
+			"net1-ctr4-eth0": libcni.CacheDuplicate,
+			"net2-ctr4-eth0": libcni.CacheOK,
+			"renamed":        libcni.CacheMisnamed,
+			"net1-ctr6-eth0": libcni.CacheOK,
+		}))
+		Expect(entries[0].Attachment.ContainerID).To(Equal("ctr1"))
+	})
+
+	It("lets the caller decide which containers are gone", func() {
+		entries, err := cniConfig.InspectCache(&libcni.CacheInspectOptions{
+			Orphaned: func(att *libcni.NetworkAttachment) bool { return att.ContainerID == "ctr1" },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(problems(entries)).To(HaveKeyWithValue("net1-ctr1-eth0", libcni.CacheOrphaned))
+		Expect(problems(entries)).To(HaveKeyWithValue("net1-ctr3-eth0", libcni.CacheOK))
+	})
+
+	It("repairs the cache", func() {
+		entries, err := cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.RepairCache(entries)).To(Succeed())
+
+		files, err := os.ReadDir(resultsDir)
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		Expect(names).To(ConsistOf("net1-ctr1-eth0", "net2-ctr4-eth0", "net1-ctr5-eth0", "net1-ctr6-eth0"))
+
+		entries, err = cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		for _, e := range entries {
+			Expect(e.Problem).To(Equal(libcni.CacheOK), e.Path)
+		}
+
+		// no staging directory is left behind
+		dirs, err := filepath.Glob(filepath.Join(cacheDir, "repair-*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dirs).To(BeEmpty())
+	})
+
+	It("changes nothing if an entry changed since it was inspected", func() {
+		entries, err := cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		writeEntry("net1-ctr4-eth0", "net1", "ctr4", "eth1", netnsPath, 0)
+
+		Expect(cniConfig.RepairCache(entries)).To(MatchError(ContainSubstring("changed since it was inspected")))
+		Expect(filepath.Join(resultsDir, "net1-ctr2-eth0")).To(BeAnExistingFile())
+		Expect(filepath.Join(resultsDir, "renamed")).To(BeAnExistingFile())
+	})
+
+	It("restores the cache if an operation fails", func() {
+		entries, err := cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		// the misnamed entry's target appears after the inspection
+		writeEntry("net1-ctr5-eth0", "net1", "ctr5", "eth0", netnsPath, 0)
+
+		err = cniConfig.RepairCache(entries)
+		Expect(err).To(MatchError(ContainSubstring("exists")))
+		for _, name := range []string{"net1-ctr2-eth0", "net1-ctr3-eth0", "net1-ctr4-eth0", "renamed"} {
+			Expect(filepath.Join(resultsDir, name)).To(BeAnExistingFile(), fmt.Sprintf("%s was not restored", name))
+		}
+		dirs, err := filepath.Glob(filepath.Join(cacheDir, "repair-*"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dirs).To(BeEmpty())
+	})
+
+	It("finds nothing in a missing cache directory", func() {
+		cniConfig = libcni.NewCNIConfigWithCacheDir(nil, filepath.Join(cacheDir, "missing"), nil)
+		entries, err := cniConfig.InspectCache(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+	})
+
+	Describe("export and import", func() {
+		var (
+			importDir string
+			importer  *libcni.CNIConfig
+		)
+
+		attachmentKeys := func(attachments []*libcni.NetworkAttachment) []string {
+			keys := []string{}
+			for _, a := range attachments {
+				keys = append(keys, a.Network+"-"+a.ContainerID+"-"+a.IfName)
+			}
+			return keys
+		}
+
+		BeforeEach(func() {
+			importDir = filepath.Join(cacheDir, "imported")
+			importer = libcni.NewCNIConfigWithCacheDir(nil, importDir, nil)
+		})
+
+		It("moves the valid attachments to another cache directory", func() {
+			archive := &bytes.Buffer{}
+			exported, err := cniConfig.ExportCache(archive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachmentKeys(exported)).To(ConsistOf(
+				"net1-ctr1-eth0", "net1-ctr3-eth0", "net1-ctr4-eth0", "net2-ctr4-eth0", "net1-ctr5-eth0"))
+
+			imported, err := importer.ImportCache(bytes.NewReader(archive.Bytes()), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachmentKeys(imported)).To(ConsistOf(attachmentKeys(exported)))
+
+			attachments, err := importer.GetCachedAttachments("ctr5")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attachments).To(HaveLen(1))
+			Expect(attachments[0].NetNS).To(Equal(netnsPath))
+			Expect(filepath.Join(importDir, "results", "net1-ctr5-eth0")).To(BeAnExistingFile())
+
+			original, err := os.ReadFile(filepath.Join(resultsDir, "net1-ctr1-eth0"))
+			Expect(err).NotTo(HaveOccurred())
+			copied, err := os.ReadFile(filepath.Join(importDir, "results", "net1-ctr1-eth0"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(copied).To(Equal(original))
+		})
+
+		It("refuses attachments which are already cached unless overwriting", func() {
+			archive := &bytes.Buffer{}
+			_, err := cniConfig.ExportCache(archive)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = cniConfig.ImportCache(bytes.NewReader(archive.Bytes()), nil)
+			Expect(err).To(MatchError("attachment net1/ctr1/eth0 is already cached"))
+			Expect(filepath.Join(resultsDir, "net1-ctr5-eth0")).NotTo(BeAnExistingFile())
+
+			_, err = cniConfig.ImportCache(bytes.NewReader(archive.Bytes()), &libcni.CacheImportOptions{Overwrite: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(resultsDir, "net1-ctr5-eth0")).To(BeAnExistingFile())
+		})
+
+		It("restores the cache if an entry cannot be imported", func() {
+			archive := &bytes.Buffer{}
+			_, err := cniConfig.ExportCache(archive)
+			Expect(err).NotTo(HaveOccurred())
+
+			importResults := filepath.Join(importDir, "results")
+			Expect(os.MkdirAll(filepath.Join(importResults, "net2-ctr4-eth0", "busy"), 0o700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(importResults, "net1-ctr1-eth0"), []byte("previous"), 0o600)).To(Succeed())
+
+			_, err = importer.ImportCache(bytes.NewReader(archive.Bytes()), &libcni.CacheImportOptions{Overwrite: true})
+			Expect(err).To(MatchError(ContainSubstring("is not a regular file")))
+			previous, err := os.ReadFile(filepath.Join(importResults, "net1-ctr1-eth0"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(previous)).To(Equal("previous"))
+			for _, name := range []string{"net1-ctr3-eth0", "net1-ctr4-eth0", "net1-ctr5-eth0"} {
+				Expect(filepath.Join(importResults, name)).NotTo(BeAnExistingFile(), fmt.Sprintf("%s was imported", name))
+			}
+			dirs, err := filepath.Glob(filepath.Join(importDir, "import-*"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dirs).To(BeEmpty())
+		})
+
+		It("exports an empty archive from a missing cache directory", func() {
+			cniConfig = libcni.NewCNIConfigWithCacheDir(nil, filepath.Join(cacheDir, "missing"), nil)
+			archive := &bytes.Buffer{}
+			exported, err := cniConfig.ExportCache(archive)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exported).To(BeEmpty())
+
+			imported, err := importer.ImportCache(archive, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imported).To(BeEmpty())
+		})
+
+		It("rejects invalid archives without writing anything", func() {
+			archive := &bytes.Buffer{}
+			gz := gzip.NewWriter(archive)
+			tw := tar.NewWriter(gz)
+			for _, f := range [][2]string{
+				{"manifest.json", `{"kind":"cniCacheArchive","version":1}`},
+				{"results/a", `{"kind":"cniCacheV1","containerId":"ctr1","ifName":"eth0","networkName":"net1"}`},
+				{"results/b", `{"kind":"cniCacheV1","containerId":"ctr1","ifName":"eth0"}`},
+			} {
+				Expect(tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: f[0], Mode: 0o600, Size: int64(len(f[1]))})).To(Succeed())
+				_, err := tw.Write([]byte(f[1]))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(tw.Close()).To(Succeed())
+			Expect(gz.Close()).To(Succeed())
+
+			_, err := importer.ImportCache(archive, nil)
+			Expect(err).To(MatchError("invalid cache archive entry results/b: missing network name, container ID or interface name"))
+			Expect(filepath.Join(importDir, "results")).NotTo(BeADirectory())
+
+			_, err = importer.ImportCache(bytes.NewReader([]byte("not an archive")), nil)
+			Expect(err).To(MatchError(HavePrefix("error reading cache archive: ")))
+		})
+	})
+})
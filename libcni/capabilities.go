@@ -0,0 +1,240 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// PluginCapabilities describes what a plugin supports, from its VERSION
+// output
+type PluginCapabilities struct {
+	// Versions are the spec versions the plugin supports
+	Versions []string `json:"versions"`
+	// Capabilities are those the plugin advertises, or nil if it does not
+	// advertise them
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Verbs are the commands the plugin supports at any of its versions,
+	// such as "CHECK", within the FeatureGates of the CNIConfig, sorted
+	Verbs []string `json:"verbs"`
+	// Features maps each version to the optional features the plugin
+	// offers with it, or is nil if the plugin does not advertise them
+	Features map[string][]version.Feature `json:"features,omitempty"`
+}
+
+// Advertised reports whether the plugin advertises its capabilities. If
+// not, whether it supports any is unknown.
+func (p *PluginCapabilities) Advertised() bool {
+	return p.Capabilities != nil
+}
+
+// Supports reports whether the plugin advertises the capability
+func (p *PluginCapabilities) Supports(capability string) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Offers reports whether the plugin offers the feature with
+// configurations of the given version, as advertised or, if the plugin
+// does not advertise features, as defined by the spec
+func (p *PluginCapabilities) Offers(feature version.Feature, cniVersion string) bool {
+	if p.Features == nil {
+		ok, _ := version.Supports(feature, cniVersion)
+		return ok
+	}
+	return featureListed(p.Features[cniVersion], feature)
+}
+
+func featureListed(features []version.Feature, feature version.Feature) bool {
+	for _, f := range features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsVerb reports whether the plugin supports the command
+func (p *PluginCapabilities) SupportsVerb(verb string) bool {
+	for _, v := range p.Verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
+
+// verbFeatures are the commands introduced after the first spec version.
+// Those which are optional are only supported by plugins advertising
+// features if they list them.
+var verbFeatures = []struct {
+	verb     string
+	feature  version.Feature
+	optional bool
+}{
+	{"CHECK", version.FeatureCheck, false},
+	{"GC", version.FeatureGC, true},
+	{"STATUS", version.FeatureStatus, true},
+}
+
+// pluginOffers reports whether the plugin offers the feature with
+// configurations of the given version, when it advertises its features.
+// Otherwise, or if its VERSION output cannot be had, the feature is
+// assumed offered since the spec version was already checked.
+func (c *CNIConfig) pluginOffers(ctx context.Context, pluginType string, feature version.Feature, cniVersion string) bool {
+	vi, err := c.GetVersionInfo(ctx, pluginType)
+	if err != nil {
+		return true
+	}
+	fi, ok := vi.(version.FeatureInfo)
+	if !ok {
+		return true
+	}
+	features, advertised := fi.Features(cniVersion)
+	return !advertised || featureListed(features, feature)
+}
+
+// GetPluginCapabilities runs the plugin's VERSION command and reports the
+// versions, capabilities and commands it supports, so that runtimes can
+// adapt to it, e.g. by not passing bandwidth limits to a chain whose
+// plugins do not support them.
+func (c *CNIConfig) GetPluginCapabilities(ctx context.Context, pluginName string) (*PluginCapabilities, error) {
+	vi, err := c.GetVersionInfo(ctx, pluginName)
+	if err != nil {
+		return nil, err
+	}
+	pc := &PluginCapabilities{
+		Versions: vi.SupportedVersions(),
+		Verbs:    []string{"ADD", "DEL", "VERSION"},
+	}
+	if ci, ok := vi.(version.CapabilityInfo); ok {
+		pc.Capabilities = ci.Capabilities()
+	}
+	if fi, ok := vi.(version.FeatureInfo); ok {
+		for _, v := range pc.Versions {
+			features, advertised := fi.Features(v)
+			if !advertised {
+				break
+			}
+			if pc.Features == nil {
+				pc.Features = map[string][]version.Feature{}
+			}
+			pc.Features[v] = features
+		}
+	}
+	for _, vf := range verbFeatures {
+		for _, v := range pc.Versions {
+			if vf.optional && pc.Features != nil && !featureListed(pc.Features[v], vf.feature) {
+				continue
+			}
+			if ok, _ := c.FeatureGates.Supports(vf.feature, v); ok {
+				pc.Verbs = append(pc.Verbs, vf.verb)
+				break
+			}
+		}
+	}
+	sort.Strings(pc.Verbs)
+	return pc, nil
+}
+
+// CapabilityReport describes the capabilities a network configuration list
+// enables and which of its plugins support them, as advertised in their
+// VERSION output
+type CapabilityReport struct {
+	// Requested are the capabilities enabled by any plugin of the list
+	Requested []string `json:"requested"`
+	// Supported maps each requested capability to the plugins advertising
+	// support for it
+	Supported map[string][]string `json:"supported,omitempty"`
+	// Unsupported are the requested capabilities which no plugin of the
+	// list supports, although every plugin enabling them advertises its
+	// capabilities
+	Unsupported []string `json:"unsupported,omitempty"`
+	// Unadvertised are the plugins which do not advertise their
+	// capabilities, so whether they support those they enable is unknown
+	Unadvertised []string `json:"unadvertised,omitempty"`
+}
+
+// ProbeCapabilities asks each plugin of the list for the capabilities it
+// supports, with GetPluginCapabilities, and reports those the list enables
+// which no plugin supports
+func (c *CNIConfig) ProbeCapabilities(ctx context.Context, list *NetworkConfigList) (*CapabilityReport, error) {
+	report := &CapabilityReport{Requested: []string{}, Supported: map[string][]string{}}
+	// unknown holds the capabilities enabled by a plugin not advertising
+	// its own
+	unknown := map[string]bool{}
+	requested := map[string]bool{}
+	for _, net := range list.Plugins {
+		pc, err := c.GetPluginCapabilities(ctx, net.Network.Type)
+		if err != nil {
+			return nil, err
+		}
+		advertised := pc.Capabilities
+		if !pc.Advertised() {
+			report.Unadvertised = append(report.Unadvertised, net.Network.Type)
+		}
+		for _, capability := range advertised {
+			report.Supported[capability] = append(report.Supported[capability], net.Network.Type)
+		}
+		for capability, enabled := range net.Network.Capabilities {
+			if !enabled {
+				continue
+			}
+			requested[capability] = true
+			if advertised == nil {
+				unknown[capability] = true
+			}
+		}
+	}
+
+	for capability := range requested {
+		report.Requested = append(report.Requested, capability)
+		if len(report.Supported[capability]) == 0 && !unknown[capability] {
+			report.Unsupported = append(report.Unsupported, capability)
+		}
+	}
+	for capability := range report.Supported {
+		if !requested[capability] {
+			delete(report.Supported, capability)
+		}
+	}
+	sort.Strings(report.Requested)
+	sort.Strings(report.Unsupported)
+	if len(report.Unsupported) > 0 {
+		c.log().Warn("network enables unsupported capabilities", "network", list.Name, "capabilities", report.Unsupported)
+	}
+	return report, nil
+}
+
+// unsupportedCapabilities returns an error if the list enables
+// capabilities that no plugin supports
+func (c *CNIConfig) unsupportedCapabilities(ctx context.Context, list *NetworkConfigList) error {
+	report, err := c.ProbeCapabilities(ctx, list)
+	if err != nil {
+		return err
+	}
+	if len(report.Unsupported) > 0 {
+		return fmt.Errorf("capabilities %q are not supported by any plugin", report.Unsupported)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Capability probing", func() {
+	var (
+		cniConfig *libcni.CNIConfig
+		pluginDir string
+		ctx       context.Context
+	)
+
+	// plugin is a plugin configuration enabling the given capability
+	plugin := func(pluginType, capability string) string {
+		return fmt.Sprintf(`{"type": %q, "capabilities": {%q: true, "disabled": false}}`, pluginType, capability)
+	}
+
+	network := func(plugins ...string) *libcni.NetworkConfigList {
+		conf := `{"cniVersion": "1.0.0", "name": "net", "plugins": [`
+		for i, p := range plugins {
+			if i > 0 {
+				conf += ","
+			}
+			conf += p
+		}
+		list, err := libcni.ConfListFromBytes([]byte(conf + "]}"))
+		Expect(err).NotTo(HaveOccurred())
+		return list
+	}
+
+	BeforeEach(func() {
+		pluginDir = GinkgoT().TempDir()
+		for name, capabilities := range map[string][]string{
+			"bridge":      {"ips", "mac"},
+			"portmap":     {"portMappings"},
+			"bandwidth":   {"bandwidth"},
+			"unannounced": nil,
+		} {
+			_, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: name, Capabilities: capabilities})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		cniConfig = libcni.NewCNIConfig([]string{pluginDir}, nil)
+		ctx = context.TODO()
+	})
+
+	It("reports the capabilities and verbs of a plugin", func() {
+		pc, err := cniConfig.GetPluginCapabilities(ctx, "bridge")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc.Versions).To(Equal(version.All.SupportedVersions()))
+		Expect(pc.Capabilities).To(Equal([]string{"ips", "mac"}))
+		Expect(pc.Verbs).To(Equal([]string{"ADD", "CHECK", "DEL", "GC", "STATUS", "VERSION"}))
+		Expect(pc.Advertised()).To(BeTrue())
+		Expect(pc.Supports("mac")).To(BeTrue())
+		Expect(pc.Supports("bandwidth")).To(BeFalse())
+		Expect(pc.SupportsVerb("GC")).To(BeTrue())
+
+		pc, err = cniConfig.GetPluginCapabilities(ctx, "unannounced")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc.Advertised()).To(BeFalse())
+		Expect(pc.Supports("bandwidth")).To(BeFalse())
+	})
+
+	It("only reports verbs of the versions the plugin supports", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: "old", Versions: []string{"0.3.1", "0.4.0"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		pc, err := cniConfig.GetPluginCapabilities(ctx, "old")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pc.Verbs).To(Equal([]string{"ADD", "CHECK", "DEL", "VERSION"}))
+		Expect(pc.SupportsVerb("STATUS")).To(BeFalse())
+	})
+
+	It("fails for plugins which cannot be found", func() {
+		_, err := cniConfig.GetPluginCapabilities(ctx, "missing")
+		Expect(err).To(MatchError(ContainSubstring(`failed to find plugin "missing"`)))
+	})
+
+	It("reports the plugins supporting each requested capability", func() {
+		report, err := cniConfig.ProbeCapabilities(ctx, network(plugin("bridge", "ips"), plugin("portmap", "portMappings"), plugin("bandwidth", "bandwidth")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report).To(Equal(&libcni.CapabilityReport{
+			Requested: []string{"bandwidth", "ips", "portMappings"},
+			Supported: map[string][]string{
+				"bandwidth":    {"bandwidth"},
+				"ips":          {"bridge"},
+				"portMappings": {"portmap"},
+			},
+		}))
+	})
+
+	It("reports requested capabilities no plugin supports", func() {
+		list := network(plugin("bridge", "dns"), plugin("portmap", "portMappings"))
+		report, err := cniConfig.ProbeCapabilities(ctx, list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Requested).To(Equal([]string{"dns", "portMappings"}))
+		Expect(report.Unsupported).To(Equal([]string{"dns"}))
+
+		_, err = cniConfig.ValidateNetworkList(ctx, list)
+		Expect(err).To(MatchError(`capabilities ["dns"] are not supported by any plugin`))
+		_, err = cniConfig.ValidateNetwork(ctx, list.Plugins[0])
+		Expect(err).To(MatchError(`capabilities ["dns"] are not supported by any plugin`))
+	})
+
+	It("accepts capabilities supported by another plugin of the chain", func() {
+		list := network(plugin("bridge", "portMappings"), plugin("portmap", "portMappings"))
+		report, err := cniConfig.ProbeCapabilities(ctx, list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Unsupported).To(BeEmpty())
+
+		caps, err := cniConfig.ValidateNetworkList(ctx, list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caps).To(ConsistOf("portMappings"))
+	})
+
+	It("does not judge plugins which do not advertise their capabilities", func() {
+		list := network(plugin("unannounced", "dns"), plugin("portmap", "portMappings"))
+		report, err := cniConfig.ProbeCapabilities(ctx, list)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Unadvertised).To(Equal([]string{"unannounced"}))
+		Expect(report.Unsupported).To(BeEmpty())
+
+		_, err = cniConfig.ValidateNetworkList(ctx, list)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails if a plugin cannot be found", func() {
+		_, err := cniConfig.ProbeCapabilities(ctx, network(plugin("missing", "ips")))
+		Expect(err).To(MatchError(ContainSubstring(`failed to find plugin "missing"`)))
+	})
+
+	Context("when plugins advertise their features", func() {
+		var gcOnly, statusOnly *plugintest.Fake
+
+		BeforeEach(func() {
+			var err error
+			gcOnly, err = plugintest.Install(pluginDir, plugintest.Plugin{Name: "gc-only", Features: []version.Feature{version.FeatureGC}})
+			Expect(err).NotTo(HaveOccurred())
+			statusOnly, err = plugintest.Install(pluginDir, plugintest.Plugin{Name: "status-only", Features: []version.Feature{version.FeatureStatus, version.FeatureIPv6Only}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		commands := func(fake *plugintest.Fake) []string {
+			invocations, err := fake.Invocations()
+			Expect(err).NotTo(HaveOccurred())
+			cmds := []string{}
+			for _, inv := range invocations {
+				if inv.Command != "VERSION" {
+					cmds = append(cmds, inv.Command)
+				}
+			}
+			return cmds
+		}
+
+		list := func() *libcni.NetworkConfigList {
+			list, err := libcni.ConfListFromBytes([]byte(`{"cniVersion": "1.1.0", "name": "net", "plugins": [{"type": "gc-only"}, {"type": "status-only"}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			return list
+		}
+
+		It("reports the verbs and features they list", func() {
+			pc, err := cniConfig.GetPluginCapabilities(ctx, "status-only")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pc.Verbs).To(Equal([]string{"ADD", "CHECK", "DEL", "STATUS", "VERSION"}))
+			Expect(pc.Features["1.1.0"]).To(Equal([]version.Feature{version.FeatureStatus, version.FeatureIPv6Only}))
+			Expect(pc.Features["0.4.0"]).To(Equal([]version.Feature{version.FeatureIPv6Only}))
+			Expect(pc.Offers(version.FeatureIPv6Only, "1.0.0")).To(BeTrue())
+			Expect(pc.Offers(version.FeatureGC, "1.1.0")).To(BeFalse())
+
+			pc, err = cniConfig.GetPluginCapabilities(ctx, "bridge")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pc.Features).To(BeNil())
+			Expect(pc.Offers(version.FeatureGC, "1.1.0")).To(BeTrue())
+			Expect(pc.Offers(version.FeatureGC, "1.0.0")).To(BeFalse())
+		})
+
+		It("only issues GC to the plugins offering it", func() {
+			Expect(cniConfig.GCNetworkList(ctx, list(), &libcni.GCArgs{})).To(Succeed())
+			Expect(commands(gcOnly)).To(Equal([]string{"GC"}))
+			Expect(commands(statusOnly)).To(BeEmpty())
+		})
+
+		It("only issues STATUS to the plugins offering it", func() {
+			Expect(cniConfig.GetStatusNetworkList(ctx, list())).To(Succeed())
+			Expect(commands(gcOnly)).To(BeEmpty())
+			Expect(commands(statusOnly)).To(Equal([]string{"STATUS"}))
+		})
+	})
+})
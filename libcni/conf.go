@@ -15,9 +15,11 @@
 package libcni
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
@@ -25,10 +27,36 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 
+	"github.com/containernetworking/cni/pkg/strictjson"
 	"github.com/containernetworking/cni/pkg/types"
 	"github.com/containernetworking/cni/pkg/version"
 )
 
+// ConfOption configures how network configurations are loaded
+type ConfOption func(*confOptions)
+
+type confOptions struct {
+	strictDecoding *strictjson.Limits
+}
+
+// WithStrictDecoding makes the loaders check configurations with
+// strictjson.Check within limits before decoding them, for runtimes whose
+// configuration comes from untrusted sources. A nil limits disables the
+// checks.
+func WithStrictDecoding(limits *strictjson.Limits) ConfOption {
+	return func(o *confOptions) {
+		o.strictDecoding = limits
+	}
+}
+
+func newConfOptions(opts []ConfOption) *confOptions {
+	o := &confOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 type NotFoundError struct {
 	Dir  string
 	Name string
@@ -46,7 +74,10 @@ func (e NoConfigsFoundError) Error() string {
 	return fmt.Sprintf(`no net configurations found in %s`, e.Dir)
 }
 
-func ConfFromBytes(bytes []byte) (*NetworkConfig, error) {
+func ConfFromBytes(bytes []byte, opts ...ConfOption) (*NetworkConfig, error) {
+	if err := checkStrict(bytes, newConfOptions(opts).strictDecoding); err != nil {
+		return nil, fmt.Errorf("error parsing configuration: %w", err)
+	}
 	conf := &NetworkConfig{Bytes: bytes, Network: &types.NetConf{}}
 	if err := json.Unmarshal(bytes, conf.Network); err != nil {
 		return nil, fmt.Errorf("error parsing configuration: %w", err)
@@ -57,15 +88,18 @@ func ConfFromBytes(bytes []byte) (*NetworkConfig, error) {
 	return conf, nil
 }
 
-func ConfFromFile(filename string) (*NetworkConfig, error) {
+func ConfFromFile(filename string, opts ...ConfOption) (*NetworkConfig, error) {
 	bytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error reading %s: %w", filename, err)
 	}
-	return ConfFromBytes(bytes)
+	return ConfFromBytes(bytes, opts...)
 }
 
-func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
+func ConfListFromBytes(bytes []byte, opts ...ConfOption) (*NetworkConfigList, error) {
+	if err := checkStrict(bytes, newConfOptions(opts).strictDecoding); err != nil {
+		return nil, fmt.Errorf("error parsing configuration list: %w", err)
+	}
 	rawList := make(map[string]interface{})
 	if err := json.Unmarshal(bytes, &rawList); err != nil {
 		return nil, fmt.Errorf("error parsing configuration list: %w", err)
@@ -81,6 +115,7 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 	}
 
 	var cniVersion string
+	var cniVersions []string
 	rawVersion, ok := rawList["cniVersion"]
 	if ok {
 		cniVersion, ok = rawVersion.(string)
@@ -99,7 +134,7 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 
 		rvs, ok := rawVersions.([]interface{})
 		if !ok {
-			return nil, fmt.Errorf("error parsing configuration list: invalid type for cniVersions: %T", rvs)
+			return nil, fmt.Errorf("error parsing configuration list: invalid type for cniVersions: %T", rawVersions)
 		}
 		vs := make([]*semver.Version, 0, len(rvs))
 		for i, rv := range rvs {
@@ -107,11 +142,11 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 			if !ok {
 				return nil, fmt.Errorf("error parsing configuration list: invalid type for cniVersions index %d: %T", i, rv)
 			}
-			if v, err := semver.NewVersion(v); err != nil {
+			if sv, err := semver.NewVersion(v); err != nil {
 				return nil, fmt.Errorf("error parsing configuration list: invalid cniVersions entry %s at index %d: %w", v, i, err)
-			} else if !v.GreaterThan(currentVersion) {
+			} else if !sv.GreaterThan(currentVersion) {
 				// Skip versions "greater" than this implementation of the spec
-				vs = append(vs, v)
+				vs = append(vs, sv)
 			}
 		}
 
@@ -124,9 +159,14 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 				vs = append(vs, v)
 			}
 		}
-		sort.Sort(semver.Collection(vs))
-		if len(vs) > 0 {
-			cniVersion = vs[len(vs)-1].String()
+		sort.Sort(sort.Reverse(semver.Collection(vs)))
+		for _, v := range vs {
+			if s := v.String(); len(cniVersions) == 0 || cniVersions[len(cniVersions)-1] != s {
+				cniVersions = append(cniVersions, s)
+			}
+		}
+		if len(cniVersions) > 0 {
+			cniVersion = cniVersions[0]
 		}
 	}
 
@@ -153,6 +193,7 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 		Name:         name,
 		DisableCheck: disableCheck,
 		CNIVersion:   cniVersion,
+		CNIVersions:  cniVersions,
 		Bytes:        bytes,
 	}
 
@@ -184,12 +225,100 @@ func ConfListFromBytes(bytes []byte) (*NetworkConfigList, error) {
 	return list, nil
 }
 
-func ConfListFromFile(filename string) (*NetworkConfigList, error) {
+// ConfListFromFile loads a network configuration list from a file, merged
+// with the fragments in its drop-in directory, if any: the directory of
+// the same name with DropInSuffix appended, e.g. "10-net.conflist.d".
+// Fragments are the ".json" files in that directory, merged in lexical
+// order by MergeConfList, so that several components can contribute to
+// one network configuration without sharing a file.
+//
+// Note that merely creating that directory changes what is loaded from
+// filename, with no change to the file itself: anyone able to write next
+// to the configuration can add plugins to it.
+func ConfListFromFile(filename string, opts ...ConfOption) (*NetworkConfigList, error) {
 	bytes, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error reading %s: %w", filename, err)
 	}
-	return ConfListFromBytes(bytes)
+	fragmentFiles, err := ConfFiles(filename+DropInSuffix, []string{".json"})
+	if err != nil {
+		return nil, fmt.Errorf("error reading drop-in directory of %s: %w", filename, err)
+	}
+	if len(fragmentFiles) > 0 {
+		sort.Strings(fragmentFiles)
+		fragments := make([][]byte, 0, len(fragmentFiles))
+		for _, file := range fragmentFiles {
+			fragment, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s: %w", file, err)
+			}
+			fragments = append(fragments, fragment)
+		}
+		if bytes, err = MergeConfList(bytes, fragments, opts...); err != nil {
+			return nil, fmt.Errorf("error merging drop-ins of %s: %w", filename, err)
+		}
+	}
+	return ConfListFromBytes(bytes, opts...)
+}
+
+// DropInSuffix is appended to the name of a network configuration list
+// file to find its drop-in directory
+const DropInSuffix = ".d"
+
+// MergeConfList merges fragments, in order, into a network configuration
+// list. The plugins of a fragment are appended to those of the list, and
+// its other keys replace those of the list, except for the name, which a
+// fragment may only repeat.
+func MergeConfList(base []byte, fragments [][]byte, opts ...ConfOption) ([]byte, error) {
+	limits := newConfOptions(opts).strictDecoding
+	if err := checkStrict(base, limits); err != nil {
+		return nil, err
+	}
+	merged := map[string]interface{}{}
+	if err := unmarshalNumbers(base, &merged); err != nil {
+		return nil, err
+	}
+	for i, fragment := range fragments {
+		if err := checkStrict(fragment, limits); err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i, err)
+		}
+		values := map[string]interface{}{}
+		if err := unmarshalNumbers(fragment, &values); err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i, err)
+		}
+		for key, value := range values {
+			switch key {
+			case "name":
+				if value != merged["name"] {
+					return nil, fmt.Errorf("fragment %d: cannot change the name from %v to %v", i, merged["name"], value)
+				}
+			case "plugins":
+				plugins, ok := value.([]interface{})
+				if !ok {
+					return nil, fmt.Errorf("fragment %d: invalid 'plugins' type %T", i, value)
+				}
+				existing, _ := merged["plugins"].([]interface{})
+				merged["plugins"] = append(existing, plugins...)
+			default:
+				merged[key] = value
+			}
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// unmarshalNumbers is json.Unmarshal keeping numbers as json.Number, so
+// that re-encoding does not round integers beyond 2^53 through float64
+func unmarshalNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("invalid data after top-level value")
+	}
+	return nil
 }
 
 func ConfFiles(dir string, extensions []string) ([]string, error) {
@@ -218,7 +347,7 @@ func ConfFiles(dir string, extensions []string) ([]string, error) {
 	return confFiles, nil
 }
 
-func LoadConf(dir, name string) (*NetworkConfig, error) {
+func LoadConf(dir, name string, opts ...ConfOption) (*NetworkConfig, error) {
 	files, err := ConfFiles(dir, []string{".conf", ".json"})
 	switch {
 	case err != nil:
@@ -229,7 +358,7 @@ func LoadConf(dir, name string) (*NetworkConfig, error) {
 	sort.Strings(files)
 
 	for _, confFile := range files {
-		conf, err := ConfFromFile(confFile)
+		conf, err := ConfFromFile(confFile, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -240,7 +369,7 @@ func LoadConf(dir, name string) (*NetworkConfig, error) {
 	return nil, NotFoundError{dir, name}
 }
 
-func LoadConfList(dir, name string) (*NetworkConfigList, error) {
+func LoadConfList(dir, name string, opts ...ConfOption) (*NetworkConfigList, error) {
 	files, err := ConfFiles(dir, []string{".conflist"})
 	if err != nil {
 		return nil, err
@@ -248,7 +377,7 @@ func LoadConfList(dir, name string) (*NetworkConfigList, error) {
 	sort.Strings(files)
 
 	for _, confFile := range files {
-		conf, err := ConfListFromFile(confFile)
+		conf, err := ConfListFromFile(confFile, opts...)
 		if err != nil {
 			return nil, err
 		}
@@ -259,7 +388,7 @@ func LoadConfList(dir, name string) (*NetworkConfigList, error) {
 
 	// Try and load a network configuration file (instead of list)
 	// from the same name, then upconvert.
-	singleConf, err := LoadConf(dir, name)
+	singleConf, err := LoadConf(dir, name, opts...)
 	if err != nil {
 		// A little extra logic so the error makes sense
 		var ncfErr NoConfigsFoundError
@@ -318,6 +447,9 @@ func ConfListFromConf(original *NetworkConfig) (*NetworkConfigList, error) {
 		"cniVersion": original.Network.CNIVersion,
 		"plugins":    []interface{}{rawConfig},
 	}
+	if cniVersions, ok := rawConfig["cniVersions"]; ok {
+		rawConfigList["cniVersions"] = cniVersions
+	}
 
 	b, err := json.Marshal(rawConfigList)
 	if err != nil {
@@ -325,3 +457,76 @@ func ConfListFromConf(original *NetworkConfig) (*NetworkConfigList, error) {
 	}
 	return ConfListFromBytes(b)
 }
+
+// minConfListVersion is the first spec version to define configuration lists
+const minConfListVersion = "0.3.0"
+
+// ConvertConfToConfList converts the contents of a single-plugin .conf
+// file into those of the equivalent .conflist, for migrating off the
+// deprecated format. The network name and versions move to the list, and
+// every other field stays in the plugin's configuration, including fields
+// libcni does not know.
+//
+// The list declares cniVersion, or the version of the configuration if it
+// is empty. Versions are only upgraded: a cniVersion lower than that of the
+// configuration is an error, and configurations older than 0.3.0, the
+// first version with lists, are upgraded to it.
+func ConvertConfToConfList(bytes []byte, cniVersion string) ([]byte, error) {
+	conf, err := ConfFromBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+	if conf.Network.Name == "" {
+		return nil, fmt.Errorf("error converting network config: no name")
+	}
+
+	target := conf.Network.CNIVersion
+	if cniVersion != "" {
+		if target != "" {
+			upgrade, err := version.GreaterThanOrEqualTo(cniVersion, target)
+			if err != nil {
+				return nil, fmt.Errorf("error converting network config: %w", err)
+			}
+			if !upgrade {
+				return nil, fmt.Errorf("error converting network config: cannot downgrade from version %s to %s", target, cniVersion)
+			}
+		}
+		target = cniVersion
+	}
+	if target == "" {
+		target = minConfListVersion
+	} else if atLeast, err := version.GreaterThanOrEqualTo(target, minConfListVersion); err != nil {
+		return nil, fmt.Errorf("error converting network config: %w", err)
+	} else if !atLeast {
+		target = minConfListVersion
+	}
+	if newer, err := version.GreaterThanOrEqualTo(version.Current(), target); err != nil || !newer {
+		return nil, fmt.Errorf("error converting network config: unsupported version %s", target)
+	}
+
+	plugin := make(map[string]interface{})
+	if err := json.Unmarshal(conf.Bytes, &plugin); err != nil {
+		return nil, err
+	}
+	list := map[string]interface{}{
+		"name":       conf.Network.Name,
+		"cniVersion": target,
+	}
+	if cniVersions, ok := plugin["cniVersions"]; ok {
+		list["cniVersions"] = cniVersions
+	}
+	for _, key := range []string{"name", "cniVersion", "cniVersions"} {
+		delete(plugin, key)
+	}
+	list["plugins"] = []interface{}{plugin}
+
+	out, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// Check the list loads as the network it replaces
+	if _, err := ConfListFromBytes(out); err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -24,6 +24,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/strictjson"
 	"github.com/containernetworking/cni/pkg/types"
 )
 
@@ -179,6 +180,31 @@ var _ = Describe("Loading configuration from disk", func() {
 				Expect(err).To(MatchError(`error parsing configuration: missing 'type'`))
 			})
 		})
+
+		Context("when decoding strictly", func() {
+			strict := libcni.WithStrictDecoding(&strictjson.DefaultLimits)
+
+			It("rejects a duplicate key", func() {
+				_, err := libcni.ConfFromBytes([]byte(`{ "name": "some-plugin", "type": "foo", "type": "bar" }`), strict)
+				Expect(err).To(MatchError(`error parsing configuration: duplicate key "type" at $`))
+			})
+
+			It("rejects a duplicate key in a list", func() {
+				_, err := libcni.ConfListFromBytes([]byte(`{ "name": "some-list", "plugins": [{ "type": "foo", "mtu": 1, "mtu": 2 }] }`), strict)
+				Expect(err).To(MatchError(`error parsing configuration list: duplicate key "mtu" at $.plugins[0]`))
+			})
+
+			It("accepts a duplicate key when not asked to", func() {
+				_, err := libcni.ConfFromBytes([]byte(`{ "name": "some-plugin", "type": "foo", "type": "bar" }`))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("accepts a valid config", func() {
+				conf, err := libcni.ConfFromBytes([]byte(`{ "name": "some-plugin", "type": "foo" }`), strict)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(conf.Network.Type).To(Equal("foo"))
+			})
+		})
 	})
 
 	Describe("ConfFromBytes", func() {
@@ -398,6 +424,96 @@ var _ = Describe("Loading configuration from disk", func() {
 				Expect(err).To(MatchError(HavePrefix(`error reading /tmp/nope/not-here: open /tmp/nope/not-here`)))
 			})
 		})
+
+		Context("when the file has a drop-in directory", func() {
+			var (
+				configDir string
+				listFile  string
+				dropInDir string
+			)
+
+			BeforeEach(func() {
+				var err error
+				configDir, err = os.MkdirTemp("", "plugin-conf")
+				Expect(err).NotTo(HaveOccurred())
+
+				listFile = filepath.Join(configDir, "10-net.conflist")
+				Expect(os.WriteFile(listFile, []byte(`{
+  "name": "some-list",
+  "cniVersion": "0.4.0",
+  "plugins": [{ "type": "bridge" }]
+}`), 0o600)).To(Succeed())
+				dropInDir = listFile + libcni.DropInSuffix
+				Expect(os.Mkdir(dropInDir, 0o700)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				Expect(os.RemoveAll(configDir)).To(Succeed())
+			})
+
+			It("appends plugins and overrides keys in lexical order", func() {
+				Expect(os.WriteFile(filepath.Join(dropInDir, "20-tuning.json"),
+					[]byte(`{ "cniVersion": "1.0.0", "plugins": [{ "type": "tuning" }] }`), 0o600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dropInDir, "10-portmap.json"),
+					[]byte(`{ "cniVersion": "0.4.0", "disableCheck": true, "plugins": [{ "type": "portmap" }] }`), 0o600)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dropInDir, "30-ignored.conf"),
+					[]byte(`{ "plugins": [{ "type": "ignored" }] }`), 0o600)).To(Succeed())
+
+				list, err := libcni.ConfListFromFile(listFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list.Name).To(Equal("some-list"))
+				Expect(list.CNIVersion).To(Equal("1.0.0"))
+				Expect(list.DisableCheck).To(BeTrue())
+				pluginTypes := []string{}
+				for _, plugin := range list.Plugins {
+					pluginTypes = append(pluginTypes, plugin.Network.Type)
+				}
+				Expect(pluginTypes).To(Equal([]string{"bridge", "portmap", "tuning"}))
+			})
+
+			It("merges large integers exactly", func() {
+				Expect(os.WriteFile(filepath.Join(dropInDir, "10-portmap.json"),
+					[]byte(`{ "plugins": [{ "type": "portmap", "mark": 9007199254740993 }] }`), 0o600)).To(Succeed())
+
+				list, err := libcni.ConfListFromFile(listFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(list.Bytes)).To(ContainSubstring(`"mark":9007199254740993`))
+			})
+
+			It("is found by LoadConfList", func() {
+				Expect(os.WriteFile(filepath.Join(dropInDir, "10-portmap.json"),
+					[]byte(`{ "plugins": [{ "type": "portmap" }] }`), 0o600)).To(Succeed())
+
+				list, err := libcni.LoadConfList(configDir, "some-list")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list.Plugins).To(HaveLen(2))
+				Expect(list.Plugins[1].Network.Type).To(Equal("portmap"))
+			})
+
+			It("uses the file unchanged when the directory is empty", func() {
+				list, err := libcni.ConfListFromFile(listFile)
+				Expect(err).NotTo(HaveOccurred())
+				bytes, err := os.ReadFile(listFile)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list.Bytes).To(Equal(bytes))
+			})
+
+			It("refuses a fragment that changes the name", func() {
+				Expect(os.WriteFile(filepath.Join(dropInDir, "10-rename.json"),
+					[]byte(`{ "name": "other-list" }`), 0o600)).To(Succeed())
+
+				_, err := libcni.ConfListFromFile(listFile)
+				Expect(err).To(MatchError(fmt.Sprintf("error merging drop-ins of %s: fragment 0: cannot change the name from some-list to other-list", listFile)))
+			})
+
+			It("refuses a fragment whose plugins are not a list", func() {
+				Expect(os.WriteFile(filepath.Join(dropInDir, "10-bad.json"),
+					[]byte(`{ "plugins": { "type": "portmap" } }`), 0o600)).To(Succeed())
+
+				_, err := libcni.ConfListFromFile(listFile)
+				Expect(err).To(MatchError(ContainSubstring("fragment 0: invalid 'plugins' type map[string]interface {}")))
+			})
+		})
 	})
 
 	Describe("InjectConf", func() {
@@ -542,6 +658,22 @@ var _ = Describe("ConfListFromBytes", func() {
 			conf, err := libcni.ConfListFromBytes([]byte(`{"name": "test", "cniVersion": "1.0.0", "cniVersions": ["0.1.0", "0.4.0"], "plugins": [{"type": "foo"}]}`))
 			Expect(err).NotTo(HaveOccurred())
 			Expect(conf.CNIVersion).To(Equal("1.0.0"))
+			Expect(conf.CNIVersions).To(Equal([]string{"1.0.0", "0.4.0", "0.1.0"}))
+		})
+
+		It("records all usable versions, highest first", func() {
+			conf, err := libcni.ConfListFromBytes(makeConfig("0.4.0", "99.0.0", "1.1.0", "0.4.0"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(conf.CNIVersions).To(Equal([]string{"1.1.0", "0.4.0"}))
+		})
+
+		It("keeps cniVersions when converting a single config", func() {
+			conf, err := libcni.ConfFromBytes([]byte(`{"name": "test", "cniVersions": ["0.4.0", "1.0.0"], "type": "foo"}`))
+			Expect(err).NotTo(HaveOccurred())
+			list, err := libcni.ConfListFromConf(conf)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.CNIVersion).To(Equal("1.0.0"))
+			Expect(list.CNIVersions).To(Equal([]string{"1.0.0", "0.4.0"}))
 		})
 
 		It("handles an empty cniVersions array", func() {
@@ -587,3 +719,79 @@ var _ = Describe("ConfListFromConf", func() {
 		Expect(ncl2).To(Equal(ncl))
 	})
 })
+
+var _ = Describe("ConvertConfToConfList", func() {
+	It("moves the name and versions to the list and keeps every other field", func() {
+		conf := []byte(`{"name": "mynet", "cniVersion": "0.4.0", "type": "bridge", "bridge": "cni0", "ipam": {"type": "host-local"}, "vendorThing": [1, 2]}`)
+		out, err := libcni.ConvertConfToConfList(conf, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`{
+			"name": "mynet",
+			"cniVersion": "0.4.0",
+			"plugins": [
+				{"type": "bridge", "bridge": "cni0", "ipam": {"type": "host-local"}, "vendorThing": [1, 2]}
+			]
+		}`))
+		Expect(string(out)).To(HaveSuffix("}\n"))
+
+		list, err := libcni.ConfListFromBytes(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Name).To(Equal("mynet"))
+		Expect(list.Plugins[0].Network.Type).To(Equal("bridge"))
+	})
+
+	It("upgrades the version", func() {
+		conf := []byte(`{"name": "mynet", "cniVersion": "0.3.1", "type": "bridge"}`)
+		out, err := libcni.ConvertConfToConfList(conf, "1.0.0")
+		Expect(err).NotTo(HaveOccurred())
+		list, err := libcni.ConfListFromBytes(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.CNIVersion).To(Equal("1.0.0"))
+	})
+
+	It("raises versions without lists to 0.3.0", func() {
+		for _, conf := range []string{
+			`{"name": "mynet", "cniVersion": "0.2.0", "type": "bridge"}`,
+			`{"name": "mynet", "type": "bridge"}`,
+		} {
+			out, err := libcni.ConvertConfToConfList([]byte(conf), "")
+			Expect(err).NotTo(HaveOccurred())
+			list, err := libcni.ConfListFromBytes(out)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list.CNIVersion).To(Equal("0.3.0"))
+		}
+	})
+
+	It("keeps cniVersions", func() {
+		conf := []byte(`{"name": "mynet", "cniVersion": "1.0.0", "cniVersions": ["1.0.0", "1.1.0"], "type": "bridge"}`)
+		out, err := libcni.ConvertConfToConfList(conf, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`{
+			"name": "mynet",
+			"cniVersion": "1.0.0",
+			"cniVersions": ["1.0.0", "1.1.0"],
+			"plugins": [{"type": "bridge"}]
+		}`))
+	})
+
+	It("refuses to downgrade", func() {
+		conf := []byte(`{"name": "mynet", "cniVersion": "1.0.0", "type": "bridge"}`)
+		_, err := libcni.ConvertConfToConfList(conf, "0.4.0")
+		Expect(err).To(MatchError(ContainSubstring("cannot downgrade from version 1.0.0 to 0.4.0")))
+	})
+
+	It("rejects unknown and invalid versions", func() {
+		conf := []byte(`{"name": "mynet", "cniVersion": "1.0.0", "type": "bridge"}`)
+		_, err := libcni.ConvertConfToConfList(conf, "9.0.0")
+		Expect(err).To(MatchError(ContainSubstring("unsupported version 9.0.0")))
+		_, err = libcni.ConvertConfToConfList(conf, "one")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects invalid configurations", func() {
+		_, err := libcni.ConvertConfToConfList([]byte(`{"name": "mynet"}`), "")
+		Expect(err).To(MatchError(ContainSubstring("missing 'type'")))
+		_, err = libcni.ConvertConfToConfList([]byte(`{"type": "bridge"}`), "")
+		Expect(err).To(MatchError(ContainSubstring("no name")))
+	})
+})
@@ -0,0 +1,94 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// gateResult returns the result without the fields of draft features
+// whose gate is not set. The result itself is not modified, as it is
+// cached and passed to plugins whole.
+func (c *CNIConfig) gateResult(result types.Result) types.Result {
+	r, ok := result.(*current.Result)
+	if !ok {
+		return result
+	}
+	stripExtensions := r.Extensions != nil && !c.FeatureGates.Enabled(version.FeatureExtensions)
+	stripRouteInterfaces := false
+	if !c.FeatureGates.Enabled(version.FeatureRouteInterface) {
+		for _, route := range r.Routes {
+			if route.Interface != nil {
+				stripRouteInterfaces = true
+			}
+		}
+	}
+	stripAnnotations, stripDevice := false, false
+	annotationsEnabled := c.FeatureGates.Enabled(version.FeatureInterfaceAnnotations)
+	deviceEnabled := c.FeatureGates.Enabled(version.FeatureInterfaceDevice)
+	for _, intf := range r.Interfaces {
+		if intf == nil {
+			continue
+		}
+		if !annotationsEnabled && intf.Annotations != nil {
+			stripAnnotations = true
+		}
+		if !deviceEnabled && (intf.PFIndex != nil || intf.VFIndex != nil || intf.RDMADevice != "") {
+			stripDevice = true
+		}
+	}
+	if !stripExtensions && !stripRouteInterfaces && !stripAnnotations && !stripDevice {
+		return result
+	}
+
+	gated := *r
+	if stripExtensions {
+		c.log().Debug("dropping result extensions, as the feature gate is not set", "feature", version.FeatureExtensions)
+		gated.Extensions = nil
+	}
+	if stripRouteInterfaces {
+		c.log().Debug("dropping route interfaces, as the feature gate is not set", "feature", version.FeatureRouteInterface)
+		gated.Routes = make([]*types.Route, 0, len(r.Routes))
+		for _, route := range r.Routes {
+			route := *route
+			route.Interface = nil
+			gated.Routes = append(gated.Routes, &route)
+		}
+	}
+	if stripAnnotations {
+		c.log().Debug("dropping interface annotations, as the feature gate is not set", "feature", version.FeatureInterfaceAnnotations)
+	}
+	if stripDevice {
+		c.log().Debug("dropping interface device info, as the feature gate is not set", "feature", version.FeatureInterfaceDevice)
+	}
+	if stripAnnotations || stripDevice {
+		gated.Interfaces = make([]*current.Interface, 0, len(r.Interfaces))
+		for _, intf := range r.Interfaces {
+			if intf != nil {
+				intf = intf.Copy()
+				if stripAnnotations {
+					intf.Annotations = nil
+				}
+				if stripDevice {
+					intf.PFIndex, intf.VFIndex, intf.RDMADevice = nil, nil, ""
+				}
+			}
+			gated.Interfaces = append(gated.Interfaces, intf)
+		}
+	}
+	return &gated
+}
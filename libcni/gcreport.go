@@ -0,0 +1,109 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"sort"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// GCReport is what GCNetworkListWithReport deleted
+type GCReport struct {
+	Network string
+	// StaleAttachments are the cached attachments which were not valid
+	// and were deleted with DEL
+	StaleAttachments []*NetworkAttachment
+	// Plugins are the plugins which succeeded to GC, in order
+	Plugins []GCPluginReport
+}
+
+// GCPluginReport is the response of one plugin to GC. Deleted is empty
+// for plugins which do not report what they deleted.
+type GCPluginReport struct {
+	Plugin  string            `json:"plugin"`
+	Deleted []types.GCDeleted `json:"deleted,omitempty"`
+}
+
+// GCContainerReport is everything a GC deleted for one container, so
+// that operators can tell which sandbox leaked what
+type GCContainerReport struct {
+	ContainerID string `json:"containerID"`
+	// IfNames are the interfaces of its stale attachments and those the
+	// plugins reported
+	IfNames    []string `json:"ifNames,omitempty"`
+	Interfaces []string `json:"interfaces,omitempty"`
+	IPs        []string `json:"ips,omitempty"`
+	Other      []string `json:"other,omitempty"`
+	// Plugins are those which reported deleting some of its resources
+	Plugins []string `json:"plugins,omitempty"`
+}
+
+// ByContainer aggregates the report by container ID, sorted. Values are
+// sorted and deduplicated.
+func (r *GCReport) ByContainer() []GCContainerReport {
+	byID := map[string]*GCContainerReport{}
+	get := func(containerID string) *GCContainerReport {
+		cr, ok := byID[containerID]
+		if !ok {
+			cr = &GCContainerReport{ContainerID: containerID}
+			byID[containerID] = cr
+		}
+		return cr
+	}
+
+	for _, a := range r.StaleAttachments {
+		cr := get(a.ContainerID)
+		cr.IfNames = append(cr.IfNames, a.IfName)
+	}
+	for _, p := range r.Plugins {
+		for _, d := range p.Deleted {
+			cr := get(d.ContainerID)
+			if d.IfName != "" {
+				cr.IfNames = append(cr.IfNames, d.IfName)
+			}
+			cr.Interfaces = append(cr.Interfaces, d.Interfaces...)
+			cr.IPs = append(cr.IPs, d.IPs...)
+			cr.Other = append(cr.Other, d.Other...)
+			cr.Plugins = append(cr.Plugins, p.Plugin)
+		}
+	}
+
+	reports := make([]GCContainerReport, 0, len(byID))
+	for _, cr := range byID {
+		cr.IfNames = sortedUnique(cr.IfNames)
+		cr.Interfaces = sortedUnique(cr.Interfaces)
+		cr.IPs = sortedUnique(cr.IPs)
+		cr.Other = sortedUnique(cr.Other)
+		cr.Plugins = sortedUnique(cr.Plugins)
+		reports = append(reports, *cr)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ContainerID < reports[j].ContainerID })
+	return reports
+}
+
+func sortedUnique(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	sort.Strings(values)
+	unique := values[:1]
+	for _, v := range values[1:] {
+		if v != unique[len(unique)-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -16,14 +16,24 @@ package libcni_test
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gexec"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
 )
 
+func TestMain(m *testing.M) {
+	plugintest.Main()
+	os.Exit(m.Run())
+}
+
 func TestLibcni(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Libcni Suite")
@@ -60,3 +70,24 @@ var _ = SynchronizedBeforeSuite(func() []byte {
 var _ = SynchronizedAfterSuite(func() {}, func() {
 	gexec.CleanupBuildArtifacts()
 })
+
+// installFakes installs the fake plugins in a temporary directory and returns
+// a CNIConfig that finds them there, along with the fakes by name
+func installFakes(plugins ...plugintest.Plugin) (*libcni.CNIConfig, map[string]*plugintest.Fake) {
+	pluginDir := GinkgoT().TempDir()
+	fakes, err := plugintest.InstallAll(pluginDir, plugins...)
+	Expect(err).NotTo(HaveOccurred())
+	return libcni.NewCNIConfigWithCacheDir([]string{pluginDir}, GinkgoT().TempDir(), nil), fakes
+}
+
+// singlePluginNetwork returns a network configuration list with a single
+// plugin of the given type
+func singlePluginNetwork(name, pluginType string) *libcni.NetworkConfigList {
+	list, err := libcni.ConfListFromBytes([]byte(fmt.Sprintf(`{
+		"cniVersion": "1.0.0",
+		"name": %q,
+		"plugins": [{"type": %q}]
+	}`, name, pluginType)))
+	Expect(err).NotTo(HaveOccurred())
+	return list
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/containernetworking/cni/pkg/chain"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// NetworkSelection is one of the networks AddNetworks attaches a container to
+type NetworkSelection struct {
+	Network *NetworkConfigList
+	// IfName is the interface name in the container. When empty, the
+	// first network gets the IfName of the RuntimeConf, "eth0" by default,
+	// and the others "net1", "net2" and so on.
+	IfName string
+	// DefaultRoute elects the network whose default routes are kept in the
+	// combined result. At most one network may set it; the first network
+	// is the default otherwise.
+	DefaultRoute bool
+	// CapabilityArgs are added to those of the RuntimeConf for this
+	// network only, replacing those with the same key
+	CapabilityArgs map[string]interface{}
+}
+
+// MultiNetworkResult is the outcome of AddNetworks
+type MultiNetworkResult struct {
+	// IfNames are the interface names assigned to each network, in order
+	IfNames []string
+	// Results are the results of each network, in order
+	Results []types.Result
+	// DefaultNetwork is the name of the network elected for the default routes
+	DefaultNetwork string
+	// Result combines the interfaces, IPs, routes and DNS settings of every
+	// network. The default routes of networks other than DefaultNetwork
+	// are left out; removing them from the container is up to the runtime
+	// or the plugins.
+	Result *current.Result
+}
+
+// multiRuntimeConfs returns the RuntimeConf of each network and the index of
+// the default network
+func multiRuntimeConfs(networks []*NetworkSelection, rt *RuntimeConf) ([]*RuntimeConf, int, error) {
+	if len(networks) == 0 {
+		return nil, 0, fmt.Errorf("no networks to attach")
+	}
+
+	defaultIdx := -1
+	taken := map[string]bool{}
+	for i, sel := range networks {
+		if sel == nil || sel.Network == nil {
+			return nil, 0, fmt.Errorf("network %d has no configuration", i)
+		}
+		if sel.DefaultRoute {
+			if defaultIdx >= 0 {
+				return nil, 0, fmt.Errorf("networks %q and %q both claim the default route", networks[defaultIdx].Network.Name, sel.Network.Name)
+			}
+			defaultIdx = i
+		}
+		if sel.IfName != "" {
+			if taken[sel.IfName] {
+				return nil, 0, fmt.Errorf("interface name %q is used by several networks", sel.IfName)
+			}
+			taken[sel.IfName] = true
+		}
+	}
+	if defaultIdx < 0 {
+		defaultIdx = 0
+	}
+
+	rts := make([]*RuntimeConf, len(networks))
+	next := 1
+	for i, sel := range networks {
+		ifName := sel.IfName
+		switch {
+		case ifName != "":
+		case i == 0 && rt.IfName != "":
+			ifName = rt.IfName
+		case i == 0:
+			ifName = "eth0"
+		default:
+			for taken[fmt.Sprintf("net%d", next)] {
+				next++
+			}
+			ifName = fmt.Sprintf("net%d", next)
+			next++
+		}
+		if sel.IfName == "" {
+			if taken[ifName] {
+				return nil, 0, fmt.Errorf("interface name %q is used by several networks", ifName)
+			}
+			taken[ifName] = true
+		}
+
+		netRt := *rt
+		netRt.IfName = ifName
+		if len(sel.CapabilityArgs) > 0 {
+			netRt.CapabilityArgs = make(map[string]interface{}, len(rt.CapabilityArgs)+len(sel.CapabilityArgs))
+			for k, v := range rt.CapabilityArgs {
+				netRt.CapabilityArgs[k] = v
+			}
+			for k, v := range sel.CapabilityArgs {
+				netRt.CapabilityArgs[k] = v
+			}
+		}
+		rts[i] = &netRt
+	}
+	return rts, defaultIdx, nil
+}
+
+// AddNetworks attaches a container to an ordered set of networks as one
+// unit. The networks are added in order; if one fails, those already added
+// and the failed one are deleted, in reverse order, and the error is
+// returned along with any error of that rollback.
+func (c *CNIConfig) AddNetworks(ctx context.Context, networks []*NetworkSelection, rt *RuntimeConf) (*MultiNetworkResult, error) {
+	rts, defaultIdx, err := multiRuntimeConfs(networks, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	multi := &MultiNetworkResult{
+		IfNames:        make([]string, len(networks)),
+		DefaultNetwork: networks[defaultIdx].Network.Name,
+	}
+	for i, sel := range networks {
+		multi.IfNames[i] = rts[i].IfName
+		result, err := c.AddNetworkList(ctx, sel.Network, rts[i])
+		if err != nil {
+			err = fmt.Errorf("network %q (%s) failed (add): %w", sel.Network.Name, rts[i].IfName, err)
+			if rbErr := c.delNetworks(ctx, networks[:i+1], rts[:i+1]); rbErr != nil {
+				return nil, rollbackError(err, rbErr)
+			}
+			return nil, err
+		}
+		multi.Results = append(multi.Results, result)
+	}
+
+	combined, err := combineResults(multi.Results, defaultIdx)
+	if err != nil {
+		if rbErr := c.delNetworks(ctx, networks, rts); rbErr != nil {
+			return nil, rollbackError(err, rbErr)
+		}
+		return nil, err
+	}
+	multi.Result = combined
+	return multi, nil
+}
+
+// DelNetworks detaches a container from the networks given to AddNetworks,
+// in reverse order. Every network is deleted even if some fail, and their
+// errors are returned together.
+func (c *CNIConfig) DelNetworks(ctx context.Context, networks []*NetworkSelection, rt *RuntimeConf) error {
+	rts, _, err := multiRuntimeConfs(networks, rt)
+	if err != nil {
+		return err
+	}
+	return c.delNetworks(ctx, networks, rts)
+}
+
+// CheckNetworks checks a container's attachments to the networks given to
+// AddNetworks, in order. Every network is checked even if some fail, and
+// their errors are returned together.
+func (c *CNIConfig) CheckNetworks(ctx context.Context, networks []*NetworkSelection, rt *RuntimeConf) error {
+	rts, _, err := multiRuntimeConfs(networks, rt)
+	if err != nil {
+		return err
+	}
+	var errs types.ErrorList
+	for i, sel := range networks {
+		if err := c.CheckNetworkList(ctx, sel.Network, rts[i]); err != nil {
+			errs.Append(fmt.Errorf("network %q (%s) failed (check): %w", sel.Network.Name, rts[i].IfName, err))
+		}
+	}
+	return errs.ErrOrNil()
+}
+
+func (c *CNIConfig) delNetworks(ctx context.Context, networks []*NetworkSelection, rts []*RuntimeConf) error {
+	var errs types.ErrorList
+	for i := len(networks) - 1; i >= 0; i-- {
+		if err := c.DelNetworkList(ctx, networks[i].Network, rts[i]); err != nil {
+			errs.Append(fmt.Errorf("network %q (%s) failed (delete): %w", networks[i].Network.Name, rts[i].IfName, err))
+		}
+	}
+	return errs.ErrOrNil()
+}
+
+// rollbackError returns the error of an operation along with that of the
+// rollback which followed it
+func rollbackError(err, rbErr error) error {
+	var errs types.ErrorList
+	errs.Append(err)
+	errs.Append(fmt.Errorf("rollback failed: %w", rbErr))
+	return &errs
+}
+
+// combineResults merges the results of every network, keeping only the
+// default routes of the default network
+func combineResults(results []types.Result, defaultIdx int) (*current.Result, error) {
+	link := &chain.Link{
+		CNIVersion: current.ImplementedSpecVersion,
+		Result:     &current.Result{CNIVersion: current.ImplementedSpecVersion},
+	}
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		res, err := current.NewResultFromResult(result)
+		if err != nil {
+			return nil, err
+		}
+		if i != defaultIdx {
+			filtered := *res
+			filtered.Routes = nil
+			for _, route := range res.Routes {
+				if ones, _ := route.Dst.Mask.Size(); ones != 0 {
+					filtered.Routes = append(filtered.Routes, route)
+				}
+			}
+			res = &filtered
+		}
+		if err := link.Merge(res); err != nil {
+			return nil, err
+		}
+	}
+	return link.Result, nil
+}
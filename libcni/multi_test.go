@@ -0,0 +1,276 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Multiple networks", func() {
+	var (
+		cniConfig *libcni.CNIConfig
+		fakes     map[string]*plugintest.Fake
+		rt        *libcni.RuntimeConf
+		ctx       context.Context
+	)
+
+	// addResult is a result with an interface, an address in 10.<n>.0.0/16,
+	// a default route and a route to 10.<n>.0.0/16
+	addResult := func(n int) string {
+		return fmt.Sprintf(`{
+			"cniVersion": "{{.CNIVersion}}",
+			"interfaces": [{"name": "{{.IfName}}", "sandbox": "{{.NetNS}}"}],
+			"ips": [{"interface": 0, "address": "10.%[1]d.0.2/16", "gateway": "10.%[1]d.0.1"}],
+			"routes": [{"dst": "0.0.0.0/0", "gw": "10.%[1]d.0.1"}, {"dst": "10.%[1]d.0.0/16"}],
+			"dns": {"nameservers": ["10.%[1]d.0.10"]}
+		}`, n)
+	}
+
+	commands := func(pluginType string) []string {
+		invocations, err := fakes[pluginType].Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		cmds := []string{}
+		for _, inv := range invocations {
+			cmds = append(cmds, inv.Command+" "+inv.IfName)
+		}
+		return cmds
+	}
+
+	BeforeEach(func() {
+		plugins := []plugintest.Plugin{}
+		for i, name := range []string{"fake-a", "fake-b", "fake-c"} {
+			plugins = append(plugins, plugintest.Plugin{
+				Name:      name,
+				Responses: map[string]plugintest.Response{"ADD": {Result: addResult(i + 1)}},
+			})
+		}
+		plugins = append(plugins, plugintest.Plugin{
+			Name: "failing",
+			Responses: map[string]plugintest.Response{
+				"ADD": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"},
+				"DEL": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"},
+			},
+		})
+		cniConfig, fakes = installFakes(plugins...)
+
+		rt = &libcni.RuntimeConf{
+			ContainerID: "some-container-id",
+			NetNS:       "/some/netns/path",
+		}
+		ctx = context.TODO()
+	})
+
+	It("assigns interface names", func() {
+		networks := []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-b", "fake-b")},
+			{Network: singlePluginNetwork("net-c", "fake-c"), IfName: "net1"},
+		}
+		multi, err := cniConfig.AddNetworks(ctx, networks, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(multi.IfNames).To(Equal([]string{"eth0", "net2", "net1"}))
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net2"}))
+
+		rt.IfName = "ens3"
+		multi, err = cniConfig.AddNetworks(ctx, networks[:1], rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(multi.IfNames).To(Equal([]string{"ens3"}))
+	})
+
+	It("combines the results, keeping the default routes of the elected network", func() {
+		networks := []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-b", "fake-b"), DefaultRoute: true},
+		}
+		multi, err := cniConfig.AddNetworks(ctx, networks, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(multi.Results).To(HaveLen(2))
+		Expect(multi.DefaultNetwork).To(Equal("net-b"))
+
+		res := multi.Result
+		Expect(res.Interfaces).To(HaveLen(2))
+		Expect(res.Interfaces[1].Name).To(Equal("net1"))
+		Expect(res.IPs).To(HaveLen(2))
+		Expect(*res.IPs[1].Interface).To(Equal(1))
+		Expect(res.IPs[1].Address.String()).To(Equal("10.2.0.2/16"))
+
+		routes := []string{}
+		for _, r := range res.Routes {
+			routes = append(routes, r.String())
+		}
+		Expect(routes).To(ConsistOf(
+			ContainSubstring("Dst:{IP:10.1.0.0"),
+			ContainSubstring("Dst:{IP:0.0.0.0 Mask:00000000} GW:10.2.0.1"),
+			ContainSubstring("Dst:{IP:10.2.0.0"),
+		))
+		Expect(res.DNS.Nameservers).To(Equal([]string{"10.1.0.10", "10.2.0.10"}))
+	})
+
+	It("renumbers the route interfaces of every network in the combined result", func() {
+		for i, name := range []string{"fake-a", "fake-b"} {
+			fake, err := plugintest.Install(filepath.Dir(fakes[name].Path), plugintest.Plugin{
+				Name:     name,
+				Versions: []string{"1.2.0"},
+				Responses: map[string]plugintest.Response{"ADD": {Result: fmt.Sprintf(`{
+					"cniVersion": "{{.CNIVersion}}",
+					"interfaces": [{"name": "host%[1]d"}, {"name": "{{.IfName}}", "sandbox": "{{.NetNS}}"}],
+					"routes": [{"dst": "10.%[1]d.0.0/16", "interface": 1}]
+				}`, i+1)}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			fakes[name] = fake
+		}
+		cniConfig.FeatureGates = version.FeatureGates{version.FeatureRouteInterface: true}
+		networks := []*libcni.NetworkSelection{}
+		for _, name := range []string{"a", "b"} {
+			list, err := libcni.ConfListFromBytes([]byte(fmt.Sprintf(`{
+				"cniVersion": "1.2.0",
+				"name": "net-%[1]s",
+				"plugins": [{"type": "fake-%[1]s"}]
+			}`, name)))
+			Expect(err).NotTo(HaveOccurred())
+			networks = append(networks, &libcni.NetworkSelection{Network: list})
+		}
+
+		multi, err := cniConfig.AddNetworks(ctx, networks, rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		res := multi.Result
+		Expect(res.Interfaces).To(HaveLen(4))
+		Expect(res.Routes).To(HaveLen(2))
+		Expect(*res.Routes[0].Interface).To(Equal(1))
+		Expect(res.Interfaces[1].Name).To(Equal("eth0"))
+		Expect(*res.Routes[1].Interface).To(Equal(3))
+		Expect(res.Interfaces[3].Name).To(Equal("net1"))
+	})
+
+	It("rolls back every network when one fails", func() {
+		networks := []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-b", "fake-b")},
+			{Network: singlePluginNetwork("net-f", "failing")},
+			{Network: singlePluginNetwork("net-c", "fake-c")},
+		}
+		_, err := cniConfig.AddNetworks(ctx, networks, rt)
+		Expect(err).To(MatchError(ContainSubstring(`network "net-f" (net2) failed (add)`)))
+		// the failing plugin's DEL also fails
+		Expect(err).To(MatchError(ContainSubstring("rollback failed")))
+		var e *types.Error
+		Expect(errors.As(err, &e)).To(BeTrue())
+		Expect(e.Code).To(Equal(types.ErrTryAgainLater))
+
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0", "DEL eth0"}))
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1", "DEL net1"}))
+		Expect(commands("failing")).To(Equal([]string{"ADD net2", "DEL net2"}))
+		Expect(commands("fake-c")).To(BeEmpty())
+
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(BeEmpty())
+	})
+
+	It("deletes every network in reverse order, even if one fails", func() {
+		networks := []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-f", "failing")},
+			{Network: singlePluginNetwork("net-b", "fake-b")},
+		}
+		err := cniConfig.DelNetworks(ctx, networks, rt)
+		Expect(err).To(MatchError(ContainSubstring(`network "net-f" (net1) failed (delete)`)))
+		Expect(commands("fake-a")).To(Equal([]string{"DEL eth0"}))
+		Expect(commands("fake-b")).To(Equal([]string{"DEL net2"}))
+	})
+
+	It("checks every network in order, even if one fails", func() {
+		networks := []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-b", "fake-b")},
+		}
+		_, err := cniConfig.AddNetworks(ctx, networks, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.CheckNetworks(ctx, networks, rt)).To(Succeed())
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0", "CHECK eth0"}))
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1", "CHECK net1"}))
+
+		failing, err := plugintest.Install(filepath.Dir(fakes["failing"].Path), plugintest.Plugin{
+			Name:      "failing",
+			Responses: map[string]plugintest.Response{"CHECK": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"}},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		fakes["failing"] = failing
+		networks = []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a")},
+			{Network: singlePluginNetwork("net-f", "failing")},
+			{Network: singlePluginNetwork("net-b", "fake-b")},
+		}
+		err = cniConfig.CheckNetworks(ctx, networks, rt)
+		Expect(err).To(MatchError(ContainSubstring(`network "net-f" (net1) failed (check)`)))
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0", "CHECK eth0", "CHECK eth0"}))
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1", "CHECK net1", "CHECK net2"}))
+	})
+
+	It("rejects invalid selections without running any plugin", func() {
+		_, err := cniConfig.AddNetworks(ctx, []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a"), DefaultRoute: true},
+			{Network: singlePluginNetwork("net-b", "fake-b"), DefaultRoute: true},
+		}, rt)
+		Expect(err).To(MatchError(`networks "net-a" and "net-b" both claim the default route`))
+
+		_, err = cniConfig.AddNetworks(ctx, []*libcni.NetworkSelection{
+			{Network: singlePluginNetwork("net-a", "fake-a"), IfName: "eth1"},
+			{Network: singlePluginNetwork("net-b", "fake-b"), IfName: "eth1"},
+		}, rt)
+		Expect(err).To(MatchError(`interface name "eth1" is used by several networks`))
+
+		_, err = cniConfig.AddNetworks(ctx, nil, rt)
+		Expect(err).To(MatchError("no networks to attach"))
+
+		Expect(commands("fake-a")).To(BeEmpty())
+		Expect(commands("fake-b")).To(BeEmpty())
+	})
+
+	It("passes per-network capability arguments", func() {
+		list, err := libcni.ConfListFromBytes([]byte(`{
+			"cniVersion": "1.0.0",
+			"name": "net-a",
+			"plugins": [{"type": "fake-a", "capabilities": {"mac": true, "ips": true}}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		rt.CapabilityArgs = map[string]interface{}{"mac": "00:11:22:33:44:55", "ips": []string{"10.1.0.5/16"}}
+
+		_, err = cniConfig.AddNetworks(ctx, []*libcni.NetworkSelection{
+			{Network: list, CapabilityArgs: map[string]interface{}{"mac": "00:11:22:33:44:66"}},
+		}, rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		invocations, err := fakes["fake-a"].Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invocations).To(HaveLen(1))
+		Expect(invocations[0].StdinData).To(ContainSubstring(`"mac":"00:11:22:33:44:66"`))
+		Expect(invocations[0].StdinData).To(ContainSubstring(`"ips":["10.1.0.5/16"]`))
+		Expect(rt.CapabilityArgs["mac"]).To(Equal("00:11:22:33:44:55"))
+	})
+})
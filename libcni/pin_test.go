@@ -0,0 +1,117 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
+)
+
+var _ = Describe("Pinning the version of a network", func() {
+	var (
+		cniConfig *libcni.CNIConfig
+		fake      *plugintest.Fake
+		list      *libcni.NetworkConfigList
+		rt        *libcni.RuntimeConf
+		ctx       context.Context
+	)
+
+	// versions returns the cniVersion each invocation was given
+	versions := func() []string {
+		invocations, err := fake.Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		vs := []string{}
+		for _, inv := range invocations {
+			conf := struct {
+				CNIVersion string `json:"cniVersion"`
+			}{}
+			Expect(json.Unmarshal(inv.StdinData, &conf)).To(Succeed())
+			vs = append(vs, inv.Command+" "+conf.CNIVersion)
+		}
+		return vs
+	}
+
+	BeforeEach(func() {
+		var fakes map[string]*plugintest.Fake
+		cniConfig, fakes = installFakes(plugintest.Plugin{
+			Name:     "fake",
+			Versions: []string{"0.4.0", "1.0.0"},
+		})
+		fake = fakes["fake"]
+		list = singlePluginNetwork("pinned-net", "fake")
+		rt = &libcni.RuntimeConf{ContainerID: "some-container-id", NetNS: "/some/netns", IfName: "eth0"}
+		ctx = context.TODO()
+	})
+
+	It("uses the version of the RuntimeConf", func() {
+		rt.CNIVersion = "0.4.0"
+		result, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Version()).To(Equal("0.4.0"))
+		Expect(cniConfig.CheckNetworkList(ctx, list, rt)).To(Succeed())
+		Expect(cniConfig.DelNetworkList(ctx, list, rt)).To(Succeed())
+
+		Expect(versions()).To(Equal([]string{"ADD 0.4.0", "CHECK 0.4.0", "DEL 0.4.0"}))
+		Expect(list.CNIVersion).To(Equal("1.0.0"))
+	})
+
+	It("uses the overrides of the CNIConfig, unless the RuntimeConf has its own", func() {
+		cniConfig.VersionOverrides = map[string]string{"pinned-net": "0.4.0", "other-net": "0.3.1"}
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		rt.CNIVersion = "1.0.0"
+		Expect(cniConfig.DelNetworkList(ctx, list, rt)).To(Succeed())
+
+		Expect(versions()).To(Equal([]string{"ADD 0.4.0", "DEL 1.0.0"}))
+	})
+
+	It("pins single networks", func() {
+		net, err := libcni.ConfFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "pinned-net", "type": "fake"}`))
+		Expect(err).NotTo(HaveOccurred())
+		rt.CNIVersion = "0.4.0"
+		_, err = cniConfig.AddNetwork(ctx, net, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.DelNetwork(ctx, net, rt)).To(Succeed())
+
+		Expect(versions()).To(Equal([]string{"ADD 0.4.0", "DEL 0.4.0"}))
+		Expect(net.Network.CNIVersion).To(Equal("1.0.0"))
+	})
+
+	It("plans with the pinned version", func() {
+		rt.CNIVersion = "0.4.0"
+		plan, err := cniConfig.PlanAddNetworkList(list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan).To(HaveLen(1))
+		Expect(string(plan[0].StdinData)).To(ContainSubstring(`"cniVersion":"0.4.0"`))
+	})
+
+	It("refuses versions the plugins do not support", func() {
+		rt.CNIVersion = "0.3.1"
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).To(MatchError(`cannot pin network "pinned-net" to version "0.3.1": plugin fake does not support config version "0.3.1"`))
+
+		rt.CNIVersion = "latest"
+		_, err = cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).To(MatchError(HavePrefix(`cannot pin network "pinned-net" to version "latest": `)))
+		Expect(versions()).To(BeEmpty())
+	})
+})
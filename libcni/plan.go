@@ -0,0 +1,134 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/utils"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// PluginInvocation describes one plugin execution of a network operation:
+// the binary, the CNI_* environment variables and the configuration
+// passed on stdin
+type PluginInvocation struct {
+	Type      string          `json:"type"`
+	Path      string          `json:"path"`
+	Env       []string        `json:"env"`
+	StdinData json.RawMessage `json:"stdinData"`
+}
+
+// PlanAddNetworkList returns the plugin invocations AddNetworkList would
+// perform, in order, without executing any plugin or touching the cache.
+// Each plugin after the first would receive the result of the previous one
+// as prevResult; since that is only known by running them, it is left out.
+// A pinned cniVersion is used without checking that the plugins support it.
+func (c *CNIConfig) PlanAddNetworkList(list *NetworkConfigList, rt *RuntimeConf) ([]*PluginInvocation, error) {
+	list = c.plannedList(list, rt)
+	policy := c.namePolicy()
+	if err := policy.ValidateContainerID(rt.ContainerID); err != nil {
+		return nil, err
+	}
+	if err := policy.ValidateNetworkName(list.Name); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateInterfaceName(rt.IfName); err != nil {
+		return nil, err
+	}
+
+	plan := make([]*PluginInvocation, 0, len(list.Plugins))
+	for _, net := range list.Plugins {
+		inv, err := c.planNetwork("ADD", list.Name, list.CNIVersion, net, nil, rt)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s failed (add): %w", pluginDescription(net.Network), err)
+		}
+		plan = append(plan, inv)
+	}
+	return plan, nil
+}
+
+// PlanDelNetworkList returns the plugin invocations DelNetworkList would
+// perform, in order, without executing any plugin or touching the cache.
+// As for DelNetworkList, the cached result is passed as prevResult. A
+// pinned cniVersion is used without checking that the plugins support it.
+func (c *CNIConfig) PlanDelNetworkList(list *NetworkConfigList, rt *RuntimeConf) ([]*PluginInvocation, error) {
+	list = c.plannedList(list, rt)
+	var cachedResult types.Result
+	if supported, err := c.FeatureGates.Supports(version.FeatureDelPrevResult, list.CNIVersion); err != nil {
+		return nil, err
+	} else if supported {
+		if cachedResult, err = c.getCachedResult(list.Name, list.CNIVersion, rt); err != nil {
+			cachedResult = nil
+		}
+	}
+
+	plan := make([]*PluginInvocation, 0, len(list.Plugins))
+	for i := len(list.Plugins) - 1; i >= 0; i-- {
+		net := list.Plugins[i]
+		inv, err := c.planNetwork("DEL", list.Name, list.CNIVersion, net, cachedResult, rt)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s failed (delete): %w", pluginDescription(net.Network), err)
+		}
+		plan = append(plan, inv)
+	}
+	return plan, nil
+}
+
+// plannedList returns the list with its pinned cniVersion, unchecked
+func (c *CNIConfig) plannedList(list *NetworkConfigList, rt *RuntimeConf) *NetworkConfigList {
+	pinned := c.versionPin(list.Name, rt)
+	if pinned == "" || pinned == list.CNIVersion {
+		return list
+	}
+	pinnedList := *list
+	pinnedList.CNIVersion = pinned
+	return &pinnedList
+}
+
+func (c *CNIConfig) planNetwork(action, name, cniVersion string, net *NetworkConfig, prevResult types.Result, rt *RuntimeConf) (*PluginInvocation, error) {
+	c.ensureExec()
+	pluginPath, err := c.exec.FindInPath(net.Network.Type, c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	newConf, err := buildOneConfig(name, cniVersion, net, prevResult, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	// The plugin inherits the rest of the environment; only the CNI
+	// variables, which may also come from our own environment, matter
+	var env []string
+	for _, kv := range c.args(context.Background(), action, rt).AsEnv() {
+		if strings.HasPrefix(kv, "CNI_") {
+			env = append(env, kv)
+		}
+	}
+	sort.Strings(env)
+
+	return &PluginInvocation{
+		Type:      net.Network.Type,
+		Path:      pluginPath,
+		Env:       env,
+		StdinData: newConf.Bytes,
+	}, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/daemon"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+var _ = Describe("Worker pool", func() {
+	var (
+		ctx       context.Context
+		pluginDir string
+		cniConfig *libcni.CNIConfig
+		list      *libcni.NetworkConfigList
+		rt        *libcni.RuntimeConf
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		pluginDir = GinkgoT().TempDir()
+		cniConfig = libcni.NewCNIConfigWithCacheDir([]string{pluginDir}, GinkgoT().TempDir(), nil)
+		list, err = libcni.ConfListFromBytes([]byte(`{
+			"cniVersion": "1.0.0",
+			"name": "pooled",
+			"plugins": [{"type": "worker"}, {"type": "binary"}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		rt = &libcni.RuntimeConf{ContainerID: "ctr1", NetNS: "/some/netns/path", IfName: "eth0"}
+	})
+
+	It("runs the pooled plugins as workers", func() {
+		worker, err := plugintest.Install(pluginDir, plugintest.Plugin{
+			Name:   "worker",
+			Worker: true,
+			Responses: map[string]plugintest.Response{
+				"ADD": {Result: `{"cniVersion": "{{.CNIVersion}}", "interfaces": [{"name": "{{.IfName}}"}]}`},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = plugintest.Install(pluginDir, plugintest.Plugin{Name: "binary"})
+		Expect(err).NotTo(HaveOccurred())
+
+		socketDir, err := os.MkdirTemp("", "pool")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, socketDir)
+		pool := daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter, SocketDir: socketDir})
+		cniConfig.WorkerPool = pool
+
+		r, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		result, err := current.GetResult(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Interfaces).To(HaveLen(1))
+		Expect(result.Interfaces[0].Name).To(Equal("eth0"))
+		Expect(cniConfig.DelNetworkList(ctx, list, rt)).To(Succeed())
+
+		invocations, err := worker.Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invocations).To(HaveLen(2))
+		Expect(invocations[0].Command).To(Equal("ADD"))
+		Expect(invocations[1].Command).To(Equal("DEL"))
+
+		Expect(filepath.Join(socketDir, "worker-1.sock")).To(BeAnExistingFile())
+		Expect(pool.Close()).To(Succeed())
+		Expect(filepath.Join(socketDir, "worker-1.sock")).NotTo(BeAnExistingFile())
+	})
+})
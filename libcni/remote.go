@@ -0,0 +1,293 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/strictjson"
+)
+
+// DefaultRemoteTimeout bounds a RemoteSource fetch when it has no Timeout
+const DefaultRemoteTimeout = 10 * time.Second
+
+// DefaultRemoteMaxResponseSize bounds the responses a RemoteSource reads,
+// in bytes, when it has no MaxResponseSize
+const DefaultRemoteMaxResponseSize = 4 << 20
+
+// RemoteSource is a ConfigSource fetching configuration lists from an
+// HTTPS endpoint, for fleets which manage network configuration
+// centrally. The endpoint serves a JSON array of configuration lists, or
+// a single list.
+//
+// Responses are cached by ETag: the cached lists are reused when the
+// server answers 304 Not Modified. With a CacheDir the last good response
+// is also kept on disk, and used when the endpoint cannot be reached or
+// fails, including after a restart.
+type RemoteSource struct {
+	// URL of the endpoint; it must be https
+	URL string
+	// CAFile, if set, is a PEM bundle of the certificate authorities
+	// trusted to serve URL, instead of the system ones
+	CAFile string
+	// Client, if set, is used for requests instead of one built from
+	// CAFile, which refuses redirects to URLs that are not https; a
+	// Client must set its own CheckRedirect policy
+	Client *http.Client
+	// CacheDir, if set, holds the last good response
+	CacheDir string
+	// Timeout bounds each fetch; DefaultRemoteTimeout if zero
+	Timeout time.Duration
+	// MaxResponseSize bounds the responses read, in bytes; larger ones
+	// fail the fetch. DefaultRemoteMaxResponseSize if zero.
+	MaxResponseSize int64
+	// Logger, if set, logs the use of the fallback cache
+	Logger *slog.Logger
+	// StrictDecoding, if set, checks the responses as WithStrictDecoding
+	// does
+	StrictDecoding *strictjson.Limits
+
+	mu     sync.Mutex
+	client *http.Client
+	cached *remoteCache
+}
+
+// remoteCache is a response of the endpoint, as stored in CacheDir
+type remoteCache struct {
+	URL       string          `json:"url"`
+	ETag      string          `json:"etag,omitempty"`
+	ConfLists json.RawMessage `json:"confLists"`
+}
+
+var _ ConfigSource = &RemoteSource{}
+
+// ConfLists fetches the configuration lists, falling back to the cached
+// ones when the endpoint cannot be reached or fails
+func (s *RemoteSource) ConfLists(ctx context.Context) ([]*NetworkConfigList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config source URL: %w", err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("config source URL %q is not https", s.URL)
+	}
+	if s.cached == nil {
+		s.cached = s.readCache()
+	}
+
+	lists, err := s.fetch(ctx)
+	if err == nil {
+		return lists, nil
+	}
+	if s.cached == nil {
+		return nil, fmt.Errorf("error fetching %s: %w", s.URL, err)
+	}
+	lists, cacheErr := parseRemoteConfLists(s.cached.ConfLists, s.StrictDecoding)
+	if cacheErr != nil {
+		return nil, fmt.Errorf("error fetching %s: %w", s.URL, err)
+	}
+	if s.Logger != nil {
+		s.Logger.Warn("using cached network configuration", "url", s.URL, "error", err)
+	}
+	return lists, nil
+}
+
+// fetch requests the endpoint and updates the cache. A 304 response
+// returns the cached lists.
+func (s *RemoteSource) fetch(ctx context.Context) ([]*NetworkConfigList, error) {
+	client, err := s.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = DefaultRemoteTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.cached != nil && s.cached.ETag != "" {
+		req.Header.Set("If-None-Match", s.cached.ETag)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if s.cached == nil {
+			return nil, fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return parseRemoteConfLists(s.cached.ConfLists, s.StrictDecoding)
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	maxSize := s.MaxResponseSize
+	if maxSize == 0 {
+		maxSize = DefaultRemoteMaxResponseSize
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxSize)
+	}
+	lists, err := parseRemoteConfLists(body, s.StrictDecoding)
+	if err != nil {
+		return nil, err
+	}
+	s.cached = &remoteCache{URL: s.URL, ETag: resp.Header.Get("ETag"), ConfLists: body}
+	if err := s.writeCache(); err != nil && s.Logger != nil {
+		s.Logger.Warn("failed to cache network configuration", "url", s.URL, "error", err)
+	}
+	return lists, nil
+}
+
+func (s *RemoteSource) httpClient() (*http.Client, error) {
+	if s.Client != nil {
+		return s.Client, nil
+	}
+	if s.client != nil {
+		return s.client, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.CAFile != "" {
+		pem, err := os.ReadFile(s.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", s.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", s.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	s.client = &http.Client{Transport: transport, CheckRedirect: checkRedirect}
+	return s.client, nil
+}
+
+// checkRedirect refuses redirects to URLs which are not https, so that a
+// compromised or misconfigured endpoint cannot send the fetch, and the
+// network configuration, over plain HTTP
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to non-https URL %q", req.URL.Redacted())
+	}
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	return nil
+}
+
+// cachePath names the cache file of the URL in CacheDir
+func (s *RemoteSource) cachePath() string {
+	sum := sha256.Sum256([]byte(s.URL))
+	return filepath.Join(s.CacheDir, "remote-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// readCache returns the cached response for the URL, or nil
+func (s *RemoteSource) readCache() *remoteCache {
+	if s.CacheDir == "" {
+		return nil
+	}
+	data, err := os.ReadFile(s.cachePath())
+	if err != nil {
+		return nil
+	}
+	cached := &remoteCache{}
+	if err := json.Unmarshal(data, cached); err != nil || cached.URL != s.URL {
+		return nil
+	}
+	return cached
+}
+
+// writeCache atomically replaces the cache file with the cached response
+func (s *RemoteSource) writeCache() error {
+	if s.CacheDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(s.cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.CacheDir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.CacheDir, ".remote-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.cachePath())
+}
+
+// parseRemoteConfLists parses a JSON array of configuration lists, or a
+// single list
+func parseRemoteConfLists(data []byte, limits *strictjson.Limits) ([]*NetworkConfigList, error) {
+	data = bytes.TrimSpace(data)
+	var raws []json.RawMessage
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return nil, fmt.Errorf("error parsing configuration lists: %w", err)
+		}
+	} else {
+		raws = []json.RawMessage{data}
+	}
+	lists := make([]*NetworkConfigList, 0, len(raws))
+	for _, raw := range raws {
+		list, err := ConfListFromBytes(raw, WithStrictDecoding(limits))
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/containernetworking/cni/pkg/strictjson"
+)
+
+// ConfigSource provides the network configuration lists a runtime
+// should use
+type ConfigSource interface {
+	ConfLists(ctx context.Context) ([]*NetworkConfigList, error)
+}
+
+// DirSource is a ConfigSource reading the configuration lists in a
+// directory, as LoadConfList does, in lexical order. Single-plugin
+// ".conf" and ".json" files are converted to lists.
+type DirSource struct {
+	Dir string
+	// StrictDecoding, if set, checks the files as WithStrictDecoding does
+	StrictDecoding *strictjson.Limits
+}
+
+var _ ConfigSource = DirSource{}
+
+// ConfLists returns the configuration lists found in the directory
+func (s DirSource) ConfLists(_ context.Context) ([]*NetworkConfigList, error) {
+	files, err := ConfFiles(s.Dir, []string{".conf", ".conflist", ".json"})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	lists := make([]*NetworkConfigList, 0, len(files))
+	for _, file := range files {
+		if filepath.Ext(file) == ".conflist" {
+			list, err := ConfListFromFile(file, WithStrictDecoding(s.StrictDecoding))
+			if err != nil {
+				return nil, err
+			}
+			lists = append(lists, list)
+			continue
+		}
+		conf, err := ConfFromFile(file, WithStrictDecoding(s.StrictDecoding))
+		if err != nil {
+			return nil, err
+		}
+		list, err := ConfListFromConf(conf)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, list)
+	}
+	return lists, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/strictjson"
+)
+
+func listNames(lists []*libcni.NetworkConfigList) []string {
+	names := []string{}
+	for _, list := range lists {
+		names = append(names, list.Name)
+	}
+	return names
+}
+
+var _ = Describe("Config sources", func() {
+	var cacheDir string
+
+	BeforeEach(func() {
+		var err error
+		cacheDir, err = os.MkdirTemp("", "config-source")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(cacheDir)).To(Succeed())
+	})
+
+	Describe("DirSource", func() {
+		It("loads lists and converts single configs in lexical order", func() {
+			Expect(os.WriteFile(filepath.Join(cacheDir, "20-b.conf"),
+				[]byte(`{ "cniVersion": "1.0.0", "name": "b", "type": "bridge" }`), 0o600)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(cacheDir, "10-a.conflist"),
+				[]byte(`{ "cniVersion": "1.0.0", "name": "a", "plugins": [{ "type": "bridge" }] }`), 0o600)).To(Succeed())
+
+			lists, err := libcni.DirSource{Dir: cacheDir}.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"a", "b"}))
+			Expect(lists[1].Plugins[0].Network.Type).To(Equal("bridge"))
+		})
+
+		It("checks the files when decoding strictly", func() {
+			Expect(os.WriteFile(filepath.Join(cacheDir, "10-a.conflist"),
+				[]byte(`{ "cniVersion": "1.0.0", "name": "a", "name": "b", "plugins": [{ "type": "bridge" }] }`), 0o600)).To(Succeed())
+
+			_, err := libcni.DirSource{Dir: cacheDir}.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			source := libcni.DirSource{Dir: cacheDir, StrictDecoding: &strictjson.DefaultLimits}
+			_, err = source.ConfLists(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring(`duplicate key "name" at $`)))
+		})
+	})
+
+	Describe("RemoteSource", func() {
+		var (
+			server   *httptest.Server
+			mu       sync.Mutex
+			body     string
+			etag     string
+			status   int
+			requests []*http.Request
+		)
+
+		BeforeEach(func() {
+			body = `[
+  { "cniVersion": "1.0.0", "name": "a", "plugins": [{ "type": "bridge" }] },
+  { "cniVersion": "1.0.0", "name": "b", "plugins": [{ "type": "macvlan" }] }
+]`
+			etag = `"v1"`
+			status = http.StatusOK
+			requests = nil
+			server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				requests = append(requests, r)
+				if status == http.StatusFound {
+					http.Redirect(w, r, "http://"+r.Host+"/plain", status)
+					return
+				}
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					return
+				}
+				if r.Header.Get("If-None-Match") == etag {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Header().Set("ETag", etag)
+				_, _ = w.Write([]byte(body))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("fetches the lists and revalidates them by ETag", func() {
+			source := &libcni.RemoteSource{URL: server.URL, Client: server.Client()}
+			lists, err := source.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"a", "b"}))
+
+			lists, err = source.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"a", "b"}))
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+
+			mu.Lock()
+			body = `{ "cniVersion": "1.0.0", "name": "c", "plugins": [{ "type": "ipvlan" }] }`
+			etag = `"v2"`
+			mu.Unlock()
+			lists, err = source.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"c"}))
+		})
+
+		It("verifies the server against CAFile", func() {
+			_, err := (&libcni.RemoteSource{URL: server.URL}).ConfLists(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("certificate")))
+
+			caFile := filepath.Join(cacheDir, "ca.pem")
+			Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.Certificate().Raw,
+			}), 0o600)).To(Succeed())
+			lists, err := (&libcni.RemoteSource{URL: server.URL, CAFile: caFile}).ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"a", "b"}))
+		})
+
+		It("refuses URLs which are not https", func() {
+			_, err := (&libcni.RemoteSource{URL: "http://example.com/net"}).ConfLists(context.TODO())
+			Expect(err).To(MatchError(`config source URL "http://example.com/net" is not https`))
+		})
+
+		It("refuses redirects to URLs which are not https", func() {
+			caFile := filepath.Join(cacheDir, "ca.pem")
+			Expect(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.Certificate().Raw,
+			}), 0o600)).To(Succeed())
+			status = http.StatusFound
+			_, err := (&libcni.RemoteSource{URL: server.URL, CAFile: caFile}).ConfLists(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring(`redirect to non-https URL "http://`)))
+			Expect(requests).To(HaveLen(1))
+		})
+
+		It("refuses responses larger than MaxResponseSize", func() {
+			source := &libcni.RemoteSource{URL: server.URL, Client: server.Client(), MaxResponseSize: int64(len(body) - 1)}
+			_, err := source.ConfLists(context.TODO())
+			Expect(err).To(MatchError(fmt.Sprintf("error fetching %s: response exceeds %d bytes", server.URL, len(body)-1)))
+
+			source = &libcni.RemoteSource{URL: server.URL, Client: server.Client(), MaxResponseSize: int64(len(body))}
+			_, err = source.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("falls back to the cache on disk when the endpoint fails", func() {
+			source := &libcni.RemoteSource{URL: server.URL, Client: server.Client(), CacheDir: cacheDir}
+			_, err := source.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			mu.Lock()
+			status = http.StatusServiceUnavailable
+			mu.Unlock()
+			restarted := &libcni.RemoteSource{URL: server.URL, Client: server.Client(), CacheDir: cacheDir}
+			lists, err := restarted.ConfLists(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listNames(lists)).To(Equal([]string{"a", "b"}))
+			Expect(requests[len(requests)-1].Header.Get("If-None-Match")).To(Equal(`"v1"`))
+		})
+
+		It("fails without a cache when the endpoint fails", func() {
+			status = http.StatusInternalServerError
+			source := &libcni.RemoteSource{URL: server.URL, Client: server.Client(), CacheDir: cacheDir}
+			_, err := source.ConfLists(context.TODO())
+			Expect(err).To(MatchError("error fetching " + server.URL + ": unexpected status 500 Internal Server Error"))
+		})
+
+		It("does not cache an invalid response", func() {
+			body = `[{ "name": "a" }]`
+			source := &libcni.RemoteSource{URL: server.URL, Client: server.Client(), CacheDir: cacheDir}
+			_, err := source.ConfLists(context.TODO())
+			Expect(err).To(MatchError(ContainSubstring("no 'plugins' key")))
+			entries, err := os.ReadDir(cacheDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,132 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// DefaultTeardownParallelism is the number of containers TeardownAll
+// detaches at once when CNIConfig.TeardownParallelism is not set
+const DefaultTeardownParallelism = 8
+
+// TeardownResult is the outcome of deleting one attachment in TeardownAll
+type TeardownResult struct {
+	Attachment *NetworkAttachment
+	// Err is why the attachment could not be deleted, or nil
+	Err      error
+	Duration time.Duration
+}
+
+// TeardownAll deletes many attachments, such as those returned by
+// GetCachedAttachments when draining a node, using the configuration
+// cached with each. Containers are detached concurrently, at most
+// TeardownParallelism at once; the attachments of one container are
+// deleted one after the other, in reverse order, as DelNetworks does.
+//
+// Every attachment is attempted even if some fail. The outcome of each is
+// returned in the order given, along with their errors as a
+// *types.ErrorList. When ctx is done, attachments which were not started
+// fail with its error.
+func (c *CNIConfig) TeardownAll(ctx context.Context, attachments []*NetworkAttachment) ([]TeardownResult, error) {
+	results := make([]TeardownResult, len(attachments))
+
+	// Group the attachments by container, keeping the order of each
+	var groups [][]int
+	byContainer := map[string]int{}
+	for i, a := range attachments {
+		results[i].Attachment = a
+		g, ok := byContainer[a.ContainerID]
+		if !ok {
+			g = len(groups)
+			byContainer[a.ContainerID] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	parallelism := c.TeardownParallelism
+	if parallelism <= 0 {
+		parallelism = DefaultTeardownParallelism
+	}
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for _, group := range groups {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for _, i := range group {
+				results[i].Err = ctx.Err()
+			}
+			continue
+		}
+		wg.Add(1)
+		go func(group []int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for j := len(group) - 1; j >= 0; j-- {
+				i := group[j]
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				start := time.Now()
+				results[i].Err = c.teardown(ctx, attachments[i])
+				results[i].Duration = time.Since(start)
+			}
+		}(group)
+	}
+	wg.Wait()
+
+	var errs types.ErrorList
+	for _, r := range results {
+		if r.Err != nil {
+			a := r.Attachment
+			errs.Append(fmt.Errorf("network %q of container %s (%s) failed (delete): %w", a.Network, a.ContainerID, a.IfName, r.Err))
+		}
+	}
+	return results, errs.ErrOrNil()
+}
+
+// teardown deletes an attachment using its cached configuration
+func (c *CNIConfig) teardown(ctx context.Context, a *NetworkAttachment) error {
+	list, err := ConfListFromBytes(a.Config, WithStrictDecoding(c.StrictDecoding))
+	if err != nil {
+		conf, cerr := ConfFromBytes(a.Config, WithStrictDecoding(c.StrictDecoding))
+		if cerr != nil {
+			return fmt.Errorf("failed to parse the cached configuration: %w", err)
+		}
+		if list, err = ConfListFromConf(conf); err != nil {
+			return err
+		}
+	}
+	list.Name = a.Network
+	return c.DelNetworkList(ctx, list, &RuntimeConf{
+		ContainerID:    a.ContainerID,
+		NetNS:          a.NetNS,
+		IfName:         a.IfName,
+		Args:           a.CniArgs,
+		CapabilityArgs: a.CapabilityArgs,
+		Metadata:       a.Metadata,
+		CDIDevices:     a.CDIDevices,
+	})
+}
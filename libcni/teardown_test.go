@@ -0,0 +1,170 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+var _ = Describe("TeardownAll", func() {
+	const delay = time.Second
+
+	var (
+		cniConfig *libcni.CNIConfig
+		slow      *plugintest.Fake
+		failing   *plugintest.Fake
+		ctx       context.Context
+	)
+
+	add := func(list *libcni.NetworkConfigList, containerID, ifName string) {
+		_, err := cniConfig.AddNetworkList(ctx, list, &libcni.RuntimeConf{
+			ContainerID: containerID,
+			NetNS:       "/some/netns/" + containerID,
+			IfName:      ifName,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	deletions := func(fake *plugintest.Fake) []string {
+		invocations, err := fake.Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		dels := []string{}
+		for _, inv := range invocations {
+			if inv.Command == "DEL" {
+				dels = append(dels, inv.ContainerID+" "+inv.IfName)
+			}
+		}
+		return dels
+	}
+
+	BeforeEach(func() {
+		var fakes map[string]*plugintest.Fake
+		cniConfig, fakes = installFakes(
+			plugintest.Plugin{
+				Name: "slow",
+				Responses: map[string]plugintest.Response{
+					"ADD": {Result: `{"cniVersion": "{{.CNIVersion}}"}`},
+					"DEL": {Delay: delay},
+				},
+			},
+			plugintest.Plugin{
+				Name: "failing",
+				Responses: map[string]plugintest.Response{
+					"ADD": {Result: `{"cniVersion": "{{.CNIVersion}}"}`},
+					"DEL": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"},
+				},
+			},
+		)
+		slow, failing = fakes["slow"], fakes["failing"]
+		ctx = context.TODO()
+	})
+
+	It("deletes containers in parallel and the attachments of each in reverse order", func() {
+		for i := 0; i < 4; i++ {
+			add(singlePluginNetwork("net-a", "slow"), fmt.Sprintf("ctr%d", i), "eth0")
+		}
+		add(singlePluginNetwork("net-b", "slow"), "ctr0", "net1")
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(HaveLen(5))
+
+		start := time.Now()
+		results, err := cniConfig.TeardownAll(ctx, attachments)
+		Expect(err).NotTo(HaveOccurred())
+		// ctr0 takes two deletions, the others run alongside: done one
+		// after the other, the five would take at least five delays
+		Expect(time.Since(start)).To(BeNumerically("<", 5*delay))
+		Expect(results).To(HaveLen(5))
+		for i, r := range results {
+			Expect(r.Attachment).To(BeIdenticalTo(attachments[i]))
+			Expect(r.Err).NotTo(HaveOccurred())
+			Expect(r.Duration).To(BeNumerically(">=", delay))
+		}
+
+		Expect(deletions(slow)).To(ConsistOf("ctr0 eth0", "ctr0 net1", "ctr1 eth0", "ctr2 eth0", "ctr3 eth0"))
+		ctr0 := []string{}
+		for _, d := range deletions(slow) {
+			if d[:4] == "ctr0" {
+				ctr0 = append(ctr0, d)
+			}
+		}
+		// net-a-ctr0-eth0 is cached, and listed, before net-b-ctr0-net1
+		Expect(ctr0).To(Equal([]string{"ctr0 net1", "ctr0 eth0"}))
+
+		remaining, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining).To(BeEmpty())
+	})
+
+	It("bounds the number of containers detached at once", func() {
+		for i := 0; i < 2; i++ {
+			add(singlePluginNetwork("net-a", "slow"), fmt.Sprintf("ctr%d", i), "eth0")
+		}
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+
+		cniConfig.TeardownParallelism = 1
+		start := time.Now()
+		_, err = cniConfig.TeardownAll(ctx, attachments)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 2*delay))
+	})
+
+	It("reports the error of each attachment and deletes the others", func() {
+		add(singlePluginNetwork("net-a", "slow"), "ctr0", "eth0")
+		add(singlePluginNetwork("net-b", "failing"), "ctr1", "eth0")
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+
+		results, err := cniConfig.TeardownAll(ctx, attachments)
+		Expect(err).To(MatchError(ContainSubstring(`network "net-b" of container ctr1 (eth0) failed (delete): `)))
+		Expect(err).To(MatchError(ContainSubstring("busy")))
+		Expect(results[0].Err).NotTo(HaveOccurred())
+		Expect(results[1].Err).To(HaveOccurred())
+		code, ok := types.CodeOf(results[1].Err)
+		Expect(ok).To(BeTrue())
+		Expect(code).To(Equal(uint(types.ErrTryAgainLater)))
+		Expect(deletions(slow)).To(Equal([]string{"ctr0 eth0"}))
+
+		var list *types.ErrorList
+		Expect(errors.As(err, &list)).To(BeTrue())
+		Expect(list.Errors).To(HaveLen(1))
+		Expect(list.Errors[0].Code).To(Equal(uint(types.ErrTryAgainLater)))
+		Expect(deletions(failing)).To(Equal([]string{"ctr1 eth0"}))
+	})
+
+	It("fails the attachments not started when the context is done", func() {
+		add(singlePluginNetwork("net-a", "slow"), "ctr0", "eth0")
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		results, err := cniConfig.TeardownAll(cancelled, attachments)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(results[0].Err).To(MatchError(context.Canceled))
+		Expect(deletions(slow)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,571 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Durability is how far the results cache survives a crash while
+// write-behind is enabled
+type Durability int
+
+const (
+	// DurabilityJournal appends every change to a journal before the
+	// operation returns, without syncing it, so that changes survive a
+	// crash of the runtime but not of the node
+	DurabilityJournal Durability = iota
+	// DurabilitySync also syncs the journal before the operation returns,
+	// and the cache files before the journal is truncated, so that changes
+	// survive a crash of the node. One sync of an append-only journal per
+	// change is cheaper than writing and syncing a file.
+	DurabilitySync
+	// DurabilityNone only keeps changes in memory until they are flushed,
+	// losing those of the last FlushInterval on a crash
+	DurabilityNone
+)
+
+const (
+	// DefaultFlushInterval is how often pending cache changes are written
+	// when WriteBehindOptions.FlushInterval is zero
+	DefaultFlushInterval = time.Second
+	// DefaultMaxPending is the number of pending cache changes which
+	// triggers a flush when WriteBehindOptions.MaxPending is zero
+	DefaultMaxPending = 256
+
+	// journalFile is the name of the write-behind journal in the cache
+	// directory
+	journalFile = "results.journal"
+)
+
+// WriteBehindOptions control EnableWriteBehind
+type WriteBehindOptions struct {
+	// FlushInterval is how often pending changes are written to the cache
+	// files; DefaultFlushInterval if zero
+	FlushInterval time.Duration
+	// MaxPending is the number of pending changes which triggers a flush
+	// before FlushInterval elapses; DefaultMaxPending if zero
+	MaxPending int
+	// Durability defaults to DurabilityJournal
+	Durability Durability
+}
+
+// writeBehind holds the changes to the results cache which are not yet
+// written to the cache files, by path. A nil change is a removal.
+type writeBehind struct {
+	c    *CNIConfig
+	opts WriteBehindOptions
+
+	// flushMu serializes flushes, which write without holding mu
+	flushMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[string][]byte
+	// flushing holds the changes being written by a flush, which lookups
+	// still see
+	flushing map[string][]byte
+	journal  *os.File
+	// torn is set when an append to the journal failed, possibly leaving
+	// a partial record to be terminated by the next append
+	torn bool
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// journalRecord is a line of the journal: the new contents of a cache
+// file, or its removal
+type journalRecord struct {
+	Path   string `json:"path"`
+	Data   []byte `json:"data,omitempty"`
+	Remove bool   `json:"remove,omitempty"`
+	// Sum is the CRC-32 of Path and Data, which tells a record torn by a
+	// crash from a complete one
+	Sum uint32 `json:"sum"`
+}
+
+func (r *journalRecord) sum() uint32 {
+	h := crc32.NewIEEE()
+	_, _ = h.Write([]byte(r.Path))
+	_, _ = h.Write(r.Data)
+	return h.Sum32()
+}
+
+// EnableWriteBehind keeps changes to the results cache in memory and
+// writes them to the cache files in the background, which saves runtimes
+// the cost of writing a file, and syncing it for DurabilitySync, for every
+// attachment during pod churn. Lookups see pending changes at once. Changes
+// left in the journal by a crash are written first.
+//
+// Other processes, such as cnitool, only see changes once they are
+// flushed, so write-behind suits runtimes which own their cache directory.
+// It requires the CNIConfig to have one, as set by
+// NewCNIConfigWithCacheDir, which then also holds the journal and the
+// cache lock of every change: RuntimeConf.CacheDir, which could put the
+// changes of a call under another lock, is ignored.
+// InspectCache, RepairCache, ExportCache and ImportCache flush first.
+// DisableWriteBehind flushes the pending changes and stops. Neither may be
+// called concurrently with other operations of the CNIConfig.
+func (c *CNIConfig) EnableWriteBehind(opts *WriteBehindOptions) error {
+	if c.writeBehind != nil {
+		return errors.New("write-behind is already enabled")
+	}
+	if c.cacheDir == "" {
+		return errors.New("write-behind requires the cache directory of NewCNIConfigWithCacheDir")
+	}
+	wb := &writeBehind{
+		c:       c,
+		pending: map[string][]byte{},
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if opts != nil {
+		wb.opts = *opts
+	}
+	if wb.opts.FlushInterval <= 0 {
+		wb.opts.FlushInterval = DefaultFlushInterval
+	}
+	if wb.opts.MaxPending <= 0 {
+		wb.opts.MaxPending = DefaultMaxPending
+	}
+
+	if err := os.MkdirAll(c.cacheDir, 0o700); err != nil {
+		return err
+	}
+	journalPath := filepath.Join(c.cacheDir, journalFile)
+	if err := wb.replay(journalPath); err != nil {
+		return err
+	}
+	if err := wb.flush(); err != nil {
+		return fmt.Errorf("failed to write the changes of the cache journal: %w", err)
+	}
+	if wb.opts.Durability != DurabilityNone {
+		journal, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			return err
+		}
+		wb.journal = journal
+	}
+
+	c.writeBehind = wb
+	go wb.run()
+	return nil
+}
+
+// DisableWriteBehind stops the write-behind of EnableWriteBehind, once the
+// pending changes are written to the cache files
+func (c *CNIConfig) DisableWriteBehind() error {
+	wb := c.writeBehind
+	if wb == nil {
+		return nil
+	}
+	close(wb.stop)
+	<-wb.done
+	err := wb.flush()
+	if err != nil {
+		// The changes are still in the journal, to be written once
+		// write-behind is enabled again
+		err = fmt.Errorf("failed to flush the results cache: %w", err)
+	}
+	if wb.journal != nil {
+		if cerr := wb.journal.Close(); err == nil {
+			err = cerr
+		}
+	}
+	c.writeBehind = nil
+	return err
+}
+
+// FlushCache writes the pending changes of write-behind to the cache files.
+// It does nothing unless write-behind is enabled.
+func (c *CNIConfig) FlushCache() error {
+	if c.writeBehind == nil {
+		return nil
+	}
+	return c.writeBehind.flush()
+}
+
+// run flushes the pending changes every FlushInterval, and whenever there
+// are MaxPending of them, until stopped
+func (wb *writeBehind) run() {
+	defer close(wb.done)
+	ticker := time.NewTicker(wb.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wb.stop:
+			return
+		case <-ticker.C:
+		case <-wb.kick:
+		}
+		if err := wb.flush(); err != nil {
+			wb.c.log().Warn("failed to flush the results cache", "error", err)
+		}
+	}
+}
+
+// replay reads the changes of the journal into pending, in order. Records
+// torn by a crash or a failed append are skipped, as the operations they
+// record did not return.
+func (wb *writeBehind) replay(journalPath string) error {
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	replayed, torn := 0, 0
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		record := journalRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Path == "" || record.sum() != record.Sum {
+			torn++
+			continue
+		}
+		if record.Remove {
+			wb.pending[record.Path] = nil
+		} else {
+			wb.pending[record.Path] = record.Data
+		}
+		replayed++
+	}
+	if torn > 0 {
+		wb.c.log().Warn("ignoring torn records of the cache journal", "path", journalPath, "records", torn)
+	}
+	if replayed > 0 {
+		wb.c.log().Info("replayed the cache journal", "path", journalPath, "records", replayed)
+	}
+	return nil
+}
+
+// journalLine encodes the change as a line of the journal
+func journalLine(path string, data []byte) ([]byte, error) {
+	record := journalRecord{Path: path, Data: data, Remove: data == nil}
+	record.Sum = record.sum()
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+// record appends the change to the journal and makes it pending
+func (wb *writeBehind) record(path string, data []byte) error {
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+
+	if wb.journal != nil {
+		line, err := journalLine(path, data)
+		if err != nil {
+			return err
+		}
+		if wb.torn {
+			line = append([]byte{'\n'}, line...)
+		}
+		if _, err := wb.journal.Write(line); err != nil {
+			wb.torn = true
+			return fmt.Errorf("failed to write the cache journal: %w", err)
+		}
+		wb.torn = false
+		if wb.opts.Durability == DurabilitySync {
+			if err := wb.journal.Sync(); err != nil {
+				return fmt.Errorf("failed to sync the cache journal: %w", err)
+			}
+		}
+	}
+	wb.pending[path] = data
+	if len(wb.pending) >= wb.opts.MaxPending {
+		select {
+		case wb.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// write makes data the pending contents of the cache file
+func (wb *writeBehind) write(path string, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	return wb.record(path, data)
+}
+
+// remove makes the removal of the cache file pending. As for os.Remove,
+// the error satisfies os.IsNotExist if there is no such file.
+func (wb *writeBehind) remove(path string) error {
+	if _, err := wb.read(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+		}
+		return err
+	}
+	return wb.record(path, nil)
+}
+
+// read returns the contents of the cache file, pending or written
+func (wb *writeBehind) read(path string) ([]byte, error) {
+	wb.mu.Lock()
+	data, ok := wb.pending[path]
+	if !ok {
+		data, ok = wb.flushing[path]
+	}
+	wb.mu.Unlock()
+	if !ok {
+		return os.ReadFile(path)
+	}
+	if data == nil {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return data, nil
+}
+
+// readDir returns the sorted names of the files in the directory, pending
+// or written
+func (wb *writeBehind) readDir(dirPath string) ([]string, error) {
+	names := map[string]bool{}
+	entries, err := os.ReadDir(dirPath)
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+
+	wb.mu.Lock()
+	pendingInDir := false
+	for _, changes := range []map[string][]byte{wb.flushing, wb.pending} {
+		for path, data := range changes {
+			if filepath.Dir(path) != dirPath {
+				continue
+			}
+			pendingInDir = true
+			names[filepath.Base(path)] = data != nil
+		}
+	}
+	wb.mu.Unlock()
+
+	if err != nil && !(errors.Is(err, os.ErrNotExist) && pendingInDir) {
+		return nil, err
+	}
+	fileNames := make([]string, 0, len(names))
+	for name, exists := range names {
+		if exists {
+			fileNames = append(fileNames, name)
+		}
+	}
+	sort.Strings(fileNames)
+	return fileNames, nil
+}
+
+// flush writes the pending changes to the cache files, holding the cache
+// lock, then rewrites the journal with the changes made meanwhile. The
+// changes are taken from pending first, so that operations need not wait
+// for the cache lock and the writes. Changes which cannot be written stay
+// pending.
+func (wb *writeBehind) flush() error {
+	wb.flushMu.Lock()
+	defer wb.flushMu.Unlock()
+
+	wb.mu.Lock()
+	if len(wb.pending) == 0 {
+		wb.mu.Unlock()
+		return nil
+	}
+	wb.flushing, wb.pending = wb.pending, map[string][]byte{}
+	wb.mu.Unlock()
+
+	failed, err := wb.writeFlushing()
+
+	wb.mu.Lock()
+	defer wb.mu.Unlock()
+	for path, data := range failed {
+		if _, ok := wb.pending[path]; !ok {
+			wb.pending[path] = data
+		}
+	}
+	wb.flushing = nil
+	if err != nil {
+		// The journal still holds the changes which failed
+		return err
+	}
+
+	if wb.journal != nil {
+		if err := wb.compactJournal(); err != nil {
+			return fmt.Errorf("failed to rewrite the cache journal: %w", err)
+		}
+	} else if err := os.Remove(filepath.Join(wb.c.cacheDir, journalFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the cache journal: %w", err)
+	}
+	return nil
+}
+
+// writeFlushing writes the flushing changes to the cache files, holding the
+// cache lock but not mu, and returns those which could not be written
+func (wb *writeBehind) writeFlushing() (map[string][]byte, error) {
+	// The cache directory of the CNIConfig overrides that of any
+	// RuntimeConf, so this is the lock of every change
+	unlock, err := wb.c.lockCache(context.Background(), &RuntimeConf{})
+	if err != nil {
+		return wb.flushing, err
+	}
+	defer unlock()
+
+	sync := wb.opts.Durability == DurabilitySync
+	dirs := map[string]bool{}
+	failed := map[string][]byte{}
+	var errs []error
+	for path, data := range wb.flushing {
+		if data == nil {
+			err = os.Remove(path)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			}
+		} else {
+			err = writeFileAtomic(path, data, sync)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			failed[path] = data
+			continue
+		}
+		dirs[filepath.Dir(path)] = true
+	}
+	if sync {
+		for dir := range dirs {
+			if err := syncDir(dir); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return failed, errors.Join(errs...)
+	}
+	wb.c.log().Debug("flushed the results cache", "changes", len(wb.flushing))
+	return nil, nil
+}
+
+// compactJournal replaces the journal with the records of the pending
+// changes, through a temporary file so that a crash leaves either journal.
+// It is called with mu held.
+func (wb *writeBehind) compactJournal() error {
+	if len(wb.pending) == 0 {
+		if err := wb.journal.Truncate(0); err != nil {
+			return err
+		}
+		wb.torn = false
+		return nil
+	}
+
+	journalPath := wb.journal.Name()
+	var buf bytes.Buffer
+	for path, data := range wb.pending {
+		line, err := journalLine(path, data)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+	}
+	sync := wb.opts.Durability == DurabilitySync
+	tmp, err := os.CreateTemp(filepath.Dir(journalPath), ".journal-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), journalPath); err != nil {
+		return err
+	}
+	if sync {
+		if err := syncDir(filepath.Dir(journalPath)); err != nil {
+			return err
+		}
+	}
+	journal, err := os.OpenFile(journalPath, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	_ = wb.journal.Close()
+	wb.journal = journal
+	wb.torn = false
+	return nil
+}
+
+// writeFileAtomic replaces the file with data through a temporary file,
+// synced first if sync is set, so that a crash leaves either contents. The
+// temporary file is created in the parent of the file's directory, where
+// lookups of the results cache do not see it.
+func writeFileAtomic(path string, data []byte, sync bool) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dir), ".writebehind-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if sync {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
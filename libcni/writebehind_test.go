@@ -0,0 +1,227 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package libcni_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/lock"
+)
+
+var _ = Describe("Write-behind of the results cache", func() {
+	var (
+		ctx       context.Context
+		cacheDir  string
+		cacheFile string
+		list      *libcni.NetworkConfigList
+		rt        *libcni.RuntimeConf
+		cniConfig *libcni.CNIConfig
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		cacheDir = GinkgoT().TempDir()
+		list, err = libcni.ConfListFromBytes([]byte(`{
+			"cniVersion": "1.0.0",
+			"name": "wbnet",
+			"plugins": [{"type": "bridge"}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+		rt = &libcni.RuntimeConf{ContainerID: "ctr1", NetNS: "/var/run/netns/ctr1", IfName: "eth0"}
+		cacheFile = filepath.Join(cacheDir, "results", "wbnet-ctr1-eth0")
+		cniConfig = libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, cacheDir, &benchExec{})
+	})
+
+	AfterEach(func() {
+		Expect(cniConfig.DisableWriteBehind()).To(Succeed())
+	})
+
+	enable := func(opts *libcni.WriteBehindOptions) {
+		if opts.FlushInterval == 0 {
+			opts.FlushInterval = time.Hour
+		}
+		Expect(cniConfig.EnableWriteBehind(opts)).To(Succeed())
+	}
+
+	It("serves pending changes until they are flushed", func() {
+		enable(&libcni.WriteBehindOptions{})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cacheFile).NotTo(BeAnExistingFile())
+
+		result, err := cniConfig.GetNetworkListCachedResult(list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+		attachments, err := cniConfig.GetCachedAttachments("ctr1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(HaveLen(1))
+		Expect(attachments[0].Network).To(Equal("wbnet"))
+
+		Expect(cniConfig.FlushCache()).To(Succeed())
+		Expect(cacheFile).To(BeAnExistingFile())
+		journal, err := os.ReadFile(filepath.Join(cacheDir, "results.journal"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(journal).To(BeEmpty())
+	})
+
+	It("removes attachments", func() {
+		enable(&libcni.WriteBehindOptions{})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.FlushCache()).To(Succeed())
+
+		Expect(cniConfig.DelNetworkList(ctx, list, rt)).To(Succeed())
+		Expect(cacheFile).To(BeAnExistingFile())
+		result, err := cniConfig.GetNetworkListCachedResult(list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(BeNil())
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(BeEmpty())
+		err = cniConfig.RemoveCachedAttachment("wbnet", rt)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		Expect(cniConfig.FlushCache()).To(Succeed())
+		Expect(cacheFile).NotTo(BeAnExistingFile())
+	})
+
+	It("replays the journal after a crash", func() {
+		enable(&libcni.WriteBehindOptions{Durability: libcni.DurabilitySync})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cacheFile).NotTo(BeAnExistingFile())
+
+		// a record torn by the crash is skipped
+		f, err := os.OpenFile(filepath.Join(cacheDir, "results.journal"), os.O_WRONLY|os.O_APPEND, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteString(`{"path":"` + filepath.Join(cacheDir, "results", "wbnet-ctr2-eth0") + `","da`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		restarted := libcni.NewCNIConfigWithCacheDir([]string{"/opt/cni/bin"}, cacheDir, &benchExec{})
+		Expect(restarted.EnableWriteBehind(nil)).To(Succeed())
+		defer func() {
+			Expect(restarted.DisableWriteBehind()).To(Succeed())
+		}()
+		Expect(cacheFile).To(BeAnExistingFile())
+		Expect(filepath.Join(cacheDir, "results", "wbnet-ctr2-eth0")).NotTo(BeAnExistingFile())
+		result, err := restarted.GetNetworkListCachedResult(list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).NotTo(BeNil())
+	})
+
+	It("keeps no journal without durability", func() {
+		enable(&libcni.WriteBehindOptions{Durability: libcni.DurabilityNone})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filepath.Join(cacheDir, "results.journal")).NotTo(BeAnExistingFile())
+
+		Expect(cniConfig.DisableWriteBehind()).To(Succeed())
+		Expect(cacheFile).To(BeAnExistingFile())
+	})
+
+	It("flushes once enough changes are pending", func() {
+		enable(&libcni.WriteBehindOptions{MaxPending: 1})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(cacheFile).Should(BeAnExistingFile())
+	})
+
+	It("flushes before inspecting the cache", func() {
+		enable(&libcni.WriteBehindOptions{})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		entries, err := cniConfig.InspectCache(&libcni.CacheInspectOptions{
+			Orphaned: func(*libcni.NetworkAttachment) bool { return false },
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Path).To(Equal(cacheFile))
+		Expect(entries[0].Problem).To(Equal(libcni.CacheOK))
+	})
+
+	It("does not hold up changes while a flush waits for the cache lock", func() {
+		enable(&libcni.WriteBehindOptions{})
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		l, err := lock.New(filepath.Join(cacheDir, "cache.lock"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(l.Lock(ctx)).To(Succeed())
+		flushed := make(chan error, 1)
+		go func() {
+			flushed <- cniConfig.FlushCache()
+		}()
+		Consistently(flushed).ShouldNot(Receive())
+
+		rt2 := &libcni.RuntimeConf{ContainerID: "ctr2", NetNS: "/var/run/netns/ctr2", IfName: "eth0"}
+		added := make(chan error, 1)
+		go func() {
+			_, err := cniConfig.AddNetworkList(ctx, list, rt2)
+			added <- err
+		}()
+		Eventually(added).Should(Receive(BeNil()))
+		attachments, err := cniConfig.GetCachedAttachments("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(HaveLen(2))
+		Expect(flushed).NotTo(Receive())
+
+		Expect(l.Close()).To(Succeed())
+		Eventually(flushed).Should(Receive(BeNil()))
+		Expect(cacheFile).To(BeAnExistingFile())
+		Expect(filepath.Join(cacheDir, "results", "wbnet-ctr2-eth0")).NotTo(BeAnExistingFile())
+
+		// the change made during the flush is still journaled
+		journal, err := os.ReadFile(filepath.Join(cacheDir, "results.journal"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(journal)).To(ContainSubstring("wbnet-ctr2-eth0"))
+		Expect(string(journal)).NotTo(ContainSubstring("wbnet-ctr1-eth0"))
+		Expect(cniConfig.FlushCache()).To(Succeed())
+		Expect(filepath.Join(cacheDir, "results", "wbnet-ctr2-eth0")).To(BeAnExistingFile())
+	})
+
+	It("requires a cache directory of the CNIConfig", func() {
+		defaultDir := libcni.CacheDir
+		libcni.CacheDir = filepath.Join(GinkgoT().TempDir(), "default")
+		defer func() { libcni.CacheDir = defaultDir }()
+
+		plain := libcni.NewCNIConfig([]string{"/opt/cni/bin"}, &benchExec{})
+		Expect(plain.EnableWriteBehind(nil)).To(MatchError("write-behind requires the cache directory of NewCNIConfigWithCacheDir"))
+		Expect(libcni.CacheDir).NotTo(BeAnExistingFile())
+	})
+
+	It("keeps the changes of calls with another cache directory under its own", func() {
+		enable(&libcni.WriteBehindOptions{})
+		rt.CacheDir = GinkgoT().TempDir()
+		_, err := cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.FlushCache()).To(Succeed())
+		Expect(cacheFile).To(BeAnExistingFile())
+		Expect(os.ReadDir(rt.CacheDir)).To(BeEmpty())
+	})
+
+	It("refuses to be enabled twice", func() {
+		enable(&libcni.WriteBehindOptions{})
+		Expect(cniConfig.EnableWriteBehind(nil)).To(MatchError("write-behind is already enabled"))
+	})
+})
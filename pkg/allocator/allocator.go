@@ -0,0 +1,188 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package allocator implements the address allocation of host-local style
+// IPAM plugins, so that plugin authors compose it with their own
+// configuration instead of forking host-local.
+//
+// An Allocator hands out addresses from a RangeSet, recording them in a
+// Store; DiskStore shares its on-disk layout with the host-local plugin.
+//
+//	store, err := allocator.NewDiskStore("", netConf.Name)
+//	defer store.Close()
+//	alloc := allocator.New(&rangeset, store, "0")
+//	ipConf, err := alloc.Get(ctx, args.ContainerID, args.IfName, nil)
+package allocator
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// Allocator allocates addresses from a range set
+type Allocator struct {
+	rangeset *RangeSet
+	store    Store
+	rangeID  string
+}
+
+// New returns an Allocator of the addresses in a canonical range set,
+// recording them in store. rangeID tells the range set apart from others
+// allocated from in the same store, such as a network's IPv4 and IPv6
+// ranges.
+func New(rangeset *RangeSet, store Store, rangeID string) *Allocator {
+	return &Allocator{rangeset: rangeset, store: store, rangeID: rangeID}
+}
+
+// Get allocates an address to the container's interface: requestedIP if
+// set, or else the first free address after the last one allocated
+func (a *Allocator) Get(ctx context.Context, id, ifname string, requestedIP net.IP) (*current.IPConfig, error) {
+	if err := a.store.Lock(ctx); err != nil {
+		return nil, err
+	}
+	defer func() { _ = a.store.Unlock() }()
+
+	if requestedIP != nil {
+		requestedIP = canonical(requestedIP)
+		r, err := a.rangeset.RangeFor(requestedIP)
+		if err != nil {
+			return nil, err
+		}
+		if requestedIP.Equal(r.Gateway) {
+			return nil, fmt.Errorf("requested IP %s is the gateway of subnet %s", requestedIP, r.subnet())
+		}
+		reserved, err := a.store.Reserve(id, ifname, requestedIP, a.rangeID)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved {
+			return nil, fmt.Errorf("requested IP %s is already allocated", requestedIP)
+		}
+		return ipConfig(requestedIP, r), nil
+	}
+
+	allocated, err := a.store.GetByID(id, ifname)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range allocated {
+		// the same container and interface may have addresses in other
+		// range sets of the store
+		if _, err := a.rangeset.RangeFor(ip); err == nil {
+			return nil, fmt.Errorf("%s is already allocated to %s %s", ip, id, ifname)
+		}
+	}
+
+	it, err := a.iterator()
+	if err != nil {
+		return nil, err
+	}
+	for ip, r := it.next(); ip != nil; ip, r = it.next() {
+		if ip.Equal(r.Gateway) {
+			continue
+		}
+		reserved, err := a.store.Reserve(id, ifname, ip, a.rangeID)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return ipConfig(ip, r), nil
+		}
+	}
+	return nil, fmt.Errorf("no IP addresses available in range set %s", a.rangeset)
+}
+
+// Release frees the addresses allocated to the container's interface
+func (a *Allocator) Release(ctx context.Context, id, ifname string) error {
+	if err := a.store.Lock(ctx); err != nil {
+		return err
+	}
+	defer func() { _ = a.store.Unlock() }()
+	return a.store.ReleaseByID(id, ifname)
+}
+
+func ipConfig(ip net.IP, r *Range) *current.IPConfig {
+	return &current.IPConfig{
+		Address: net.IPNet{IP: ip, Mask: r.Subnet.Mask},
+		Gateway: r.Gateway,
+	}
+}
+
+// iterator visits every address of the range set once, starting after the
+// last one reserved
+type iterator struct {
+	rangeset *RangeSet
+	rangeIdx int
+	cur      net.IP
+	startIdx int
+	startIP  net.IP
+	started  bool
+}
+
+func (a *Allocator) iterator() (*iterator, error) {
+	it := &iterator{rangeset: a.rangeset, startIP: (*a.rangeset)[0].RangeStart}
+	last, err := a.store.LastReservedIP(a.rangeID)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		for i := range *a.rangeset {
+			r := &(*a.rangeset)[i]
+			if !r.Contains(last) {
+				continue
+			}
+			if last.Equal(r.RangeEnd) {
+				it.startIdx = (i + 1) % len(*a.rangeset)
+				it.startIP = (*a.rangeset)[it.startIdx].RangeStart
+			} else {
+				it.startIdx = i
+				it.startIP = nextIP(canonical(last))
+			}
+			break
+		}
+	}
+	it.rangeIdx = it.startIdx
+	return it, nil
+}
+
+// next returns the next address and its range, or nil once every address
+// has been visited
+func (it *iterator) next() (net.IP, *Range) {
+	r := &(*it.rangeset)[it.rangeIdx]
+	if !it.started {
+		it.started = true
+		it.cur = it.startIP
+		return it.cur, r
+	}
+
+	if it.cur.Equal(r.RangeEnd) {
+		it.rangeIdx = (it.rangeIdx + 1) % len(*it.rangeset)
+		r = &(*it.rangeset)[it.rangeIdx]
+		it.cur = r.RangeStart
+	} else {
+		it.cur = nextIP(it.cur)
+	}
+	if it.rangeIdx == it.startIdx && it.cur.Equal(it.startIP) {
+		return nil, nil
+	}
+	return it.cur, r
+}
+
+func canonical(ip net.IP) net.IP {
+	_ = canonicalizeIP(&ip)
+	return ip
+}
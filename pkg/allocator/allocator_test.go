@@ -0,0 +1,185 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/allocator"
+)
+
+var _ = Describe("Allocator", func() {
+	var (
+		dataDir  string
+		store    allocator.Store
+		rangeset allocator.RangeSet
+		alloc    *allocator.Allocator
+		ctx      context.Context
+	)
+
+	newAllocator := func() {
+		Expect(rangeset.Canonicalize()).To(Succeed())
+		alloc = allocator.New(&rangeset, store, "0")
+	}
+
+	BeforeEach(func() {
+		dataDir = GinkgoT().TempDir()
+		diskStore, err := allocator.NewDiskStore(dataDir, "some-net")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(diskStore.Close)
+		store = diskStore
+
+		rangeset = allocator.RangeSet{{
+			Subnet:     mustSubnet("10.1.2.0/29"),
+			RangeStart: net.ParseIP("10.1.2.1"),
+			RangeEnd:   net.ParseIP("10.1.2.3"),
+		}}
+		ctx = context.TODO()
+	})
+
+	It("allocates addresses in order, skipping the gateway", func() {
+		newAllocator()
+
+		first, err := alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Address.String()).To(Equal("10.1.2.2/29"))
+		Expect(first.Gateway.String()).To(Equal("10.1.2.1"))
+
+		second, err := alloc.Get(ctx, "c2", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Address.String()).To(Equal("10.1.2.3/29"))
+
+		_, err = alloc.Get(ctx, "c3", "eth0", nil)
+		Expect(err).To(MatchError("no IP addresses available in range set 10.1.2.1-10.1.2.3"))
+	})
+
+	It("continues after the last reserved address once released", func() {
+		newAllocator()
+		_, err := alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(alloc.Release(ctx, "c1", "eth0")).To(Succeed())
+
+		// 10.1.2.2 is free again, but the next address is preferred so
+		// that addresses are not reused straight away
+		ipConf, err := alloc.Get(ctx, "c2", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipConf.Address.IP.String()).To(Equal("10.1.2.3"))
+
+		ipConf, err = alloc.Get(ctx, "c3", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipConf.Address.IP.String()).To(Equal("10.1.2.2"))
+	})
+
+	It("moves on to the next range", func() {
+		rangeset = append(rangeset, allocator.Range{Subnet: mustSubnet("10.1.3.0/30")})
+		newAllocator()
+
+		var ips []string
+		for _, id := range []string{"c1", "c2", "c3"} {
+			ipConf, err := alloc.Get(ctx, id, "eth0", nil)
+			Expect(err).NotTo(HaveOccurred())
+			ips = append(ips, ipConf.Address.String())
+		}
+		Expect(ips).To(Equal([]string{"10.1.2.2/29", "10.1.2.3/29", "10.1.3.2/30"}))
+	})
+
+	It("allocates a requested address", func() {
+		newAllocator()
+
+		ipConf, err := alloc.Get(ctx, "c1", "eth0", net.ParseIP("10.1.2.3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipConf.Address.String()).To(Equal("10.1.2.3/29"))
+
+		_, err = alloc.Get(ctx, "c2", "eth0", net.ParseIP("10.1.2.3"))
+		Expect(err).To(MatchError("requested IP 10.1.2.3 is already allocated"))
+		_, err = alloc.Get(ctx, "c2", "eth0", net.ParseIP("10.1.2.1"))
+		Expect(err).To(MatchError("requested IP 10.1.2.1 is the gateway of subnet 10.1.2.0/29"))
+		_, err = alloc.Get(ctx, "c2", "eth0", net.ParseIP("10.1.2.6"))
+		Expect(err).To(MatchError(ContainSubstring("is not in range set")))
+	})
+
+	It("rejects a second allocation to the same interface", func() {
+		newAllocator()
+		_, err := alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).To(MatchError("10.1.2.2 is already allocated to c1 eth0"))
+	})
+
+	It("keeps the host-local plugin's layout on disk", func() {
+		newAllocator()
+		_, err := alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := os.ReadFile(filepath.Join(dataDir, "some-net", "10.1.2.2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("c1\r\neth0"))
+		data, err = os.ReadFile(filepath.Join(dataDir, "some-net", "last_reserved_ip.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("10.1.2.2"))
+
+		Expect(alloc.Release(ctx, "c1", "eth0")).To(Succeed())
+		_, err = os.Stat(filepath.Join(dataDir, "some-net", "10.1.2.2"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("waits for the pool's lock", func() {
+		newAllocator()
+		other, err := allocator.NewDiskStore(dataDir, "some-net")
+		Expect(err).NotTo(HaveOccurred())
+		defer other.Close()
+		Expect(other.Lock(ctx)).To(Succeed())
+
+		timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err = alloc.Get(timeout, "c1", "eth0", nil)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		Expect(other.Unlock()).To(Succeed())
+		_, err = alloc.Get(ctx, "c1", "eth0", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("with a memory store", func() {
+		BeforeEach(func() {
+			store = allocator.NewMemoryStore()
+		})
+
+		It("allocates and releases addresses", func() {
+			newAllocator()
+			ipConf, err := alloc.Get(ctx, "c1", "eth0", nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ipConf.Address.String()).To(Equal("10.1.2.2/29"))
+
+			ips, err := store.GetByID("c1", "eth0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ips).To(HaveLen(1))
+			Expect(ips[0].Equal(net.ParseIP("10.1.2.2"))).To(BeTrue())
+
+			Expect(alloc.Release(ctx, "c1", "eth0")).To(Succeed())
+			ips, err = store.GetByID("c1", "eth0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ips).To(BeEmpty())
+		})
+	})
+})
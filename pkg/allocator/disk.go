@@ -0,0 +1,178 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/lock"
+)
+
+// DefaultDataDir is where DiskStore keeps its networks by default, as the
+// host-local plugin does
+const DefaultDataDir = "/var/lib/cni/networks"
+
+const (
+	lastReservedPrefix = "last_reserved_ip."
+	lockFileName       = "lock"
+	// lineBreak separates the container ID and interface name in the
+	// file of an allocated address
+	lineBreak = "\r\n"
+)
+
+// DiskStore is a Store keeping a network's allocations in a directory, in
+// the layout of the host-local plugin: a file named after each allocated
+// address holds the container ID and interface name it is allocated to.
+type DiskStore struct {
+	dir  string
+	lock *lock.FileLock
+}
+
+var _ Store = &DiskStore{}
+
+// NewDiskStore returns the store of network in dataDir, or DefaultDataDir
+// if dataDir is empty
+func NewDiskStore(dataDir, network string) (*DiskStore, error) {
+	if dataDir == "" {
+		dataDir = DefaultDataDir
+	}
+	dir := filepath.Join(dataDir, network)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	l, err := lock.New(filepath.Join(dir, lockFileName))
+	if err != nil {
+		return nil, err
+	}
+	return &DiskStore{dir: dir, lock: l}, nil
+}
+
+// Lock implements Store
+func (s *DiskStore) Lock(ctx context.Context) error {
+	return s.lock.Lock(ctx)
+}
+
+// Unlock implements Store
+func (s *DiskStore) Unlock() error {
+	return s.lock.Unlock()
+}
+
+// Close implements Store
+func (s *DiskStore) Close() error {
+	return s.lock.Close()
+}
+
+// Reserve implements Store
+func (s *DiskStore) Reserve(id, ifname string, ip net.IP, rangeID string) (bool, error) {
+	f, err := os.OpenFile(s.ipPath(ip), os.O_RDWR|os.O_EXCL|os.O_CREATE, 0o644)
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := f.WriteString(strings.TrimSpace(id) + lineBreak + ifname); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return false, err
+	}
+
+	if err := os.WriteFile(filepath.Join(s.dir, lastReservedPrefix+rangeID), []byte(ip.String()), 0o644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// LastReservedIP implements Store
+func (s *DiskStore) LastReservedIP(rangeID string) (net.IP, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, lastReservedPrefix+rangeID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return net.ParseIP(string(data)), nil
+}
+
+// ReleaseByID implements Store
+func (s *DiskStore) ReleaseByID(id, ifname string) error {
+	return s.walk(id, ifname, func(path string, _ net.IP) error {
+		return os.Remove(path)
+	})
+}
+
+// GetByID implements Store
+func (s *DiskStore) GetByID(id, ifname string) ([]net.IP, error) {
+	var ips []net.IP
+	err := s.walk(id, ifname, func(_ string, ip net.IP) error {
+		ips = append(ips, ip)
+		return nil
+	})
+	return ips, err
+}
+
+// walk calls fn for every address allocated to the container's interface
+func (s *DiskStore) walk(id, ifname string, fn func(path string, ip net.IP) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	match := strings.TrimSpace(id) + lineBreak + ifname
+	for _, entry := range entries {
+		ip := parseIPFileName(entry.Name())
+		if ip == nil || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.dir, entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(string(data)) != match {
+			continue
+		}
+		if err := fn(path, ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DiskStore) ipPath(ip net.IP) string {
+	name := ip.String()
+	if runtime.GOOS == "windows" {
+		// colons are not allowed in file names
+		name = strings.ReplaceAll(name, ":", "_")
+	}
+	return filepath.Join(s.dir, name)
+}
+
+func parseIPFileName(name string) net.IP {
+	if runtime.GOOS == "windows" {
+		name = strings.ReplaceAll(name, "_", ":")
+	}
+	return net.ParseIP(name)
+}
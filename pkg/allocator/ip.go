@@ -0,0 +1,70 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+)
+
+// canonicalizeIP makes an IPv4 address 4 bytes long
+func canonicalizeIP(ip *net.IP) error {
+	if ip4 := ip.To4(); ip4 != nil {
+		*ip = ip4
+		return nil
+	}
+	if ip16 := ip.To16(); ip16 != nil {
+		*ip = ip16
+		return nil
+	}
+	return fmt.Errorf("invalid IP address %q", *ip)
+}
+
+// compareIPs compares two canonical addresses of the same family
+func compareIPs(a, b net.IP) int {
+	return bytes.Compare(a, b)
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := append(net.IP(nil), ip...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func prevIP(ip net.IP) net.IP {
+	prev := append(net.IP(nil), ip...)
+	for i := len(prev) - 1; i >= 0; i-- {
+		prev[i]--
+		if prev[i] != 0xff {
+			break
+		}
+	}
+	return prev
+}
+
+// lastIP returns the last address of a canonical subnet
+func lastIP(subnet net.IPNet) net.IP {
+	last := make(net.IP, len(subnet.IP))
+	for i := range subnet.IP {
+		last[i] = subnet.IP[i] | ^subnet.Mask[len(subnet.Mask)-len(subnet.IP)+i]
+	}
+	return last
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a Store keeping allocations in memory, for tests and as a
+// base for stores backed by a database. Its lock only excludes users of
+// the same MemoryStore.
+type MemoryStore struct {
+	// locked holds a value between Lock and Unlock
+	locked chan struct{}
+
+	// data guards the maps, so that methods called without the lock are
+	// still race free
+	data         sync.Mutex
+	allocations  map[string]allocation
+	lastReserved map[string]net.IP
+}
+
+type allocation struct {
+	id, ifname string
+}
+
+var _ Store = &MemoryStore{}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		locked:       make(chan struct{}, 1),
+		allocations:  map[string]allocation{},
+		lastReserved: map[string]net.IP{},
+	}
+}
+
+// Lock implements Store
+func (s *MemoryStore) Lock(ctx context.Context) error {
+	select {
+	case s.locked <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Unlock implements Store
+func (s *MemoryStore) Unlock() error {
+	<-s.locked
+	return nil
+}
+
+// Close implements Store
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Reserve implements Store
+func (s *MemoryStore) Reserve(id, ifname string, ip net.IP, rangeID string) (bool, error) {
+	s.data.Lock()
+	defer s.data.Unlock()
+	if _, ok := s.allocations[ip.String()]; ok {
+		return false, nil
+	}
+	s.allocations[ip.String()] = allocation{id, ifname}
+	s.lastReserved[rangeID] = ip
+	return true, nil
+}
+
+// LastReservedIP implements Store
+func (s *MemoryStore) LastReservedIP(rangeID string) (net.IP, error) {
+	s.data.Lock()
+	defer s.data.Unlock()
+	return s.lastReserved[rangeID], nil
+}
+
+// ReleaseByID implements Store
+func (s *MemoryStore) ReleaseByID(id, ifname string) error {
+	s.data.Lock()
+	defer s.data.Unlock()
+	for ip, a := range s.allocations {
+		if a == (allocation{id, ifname}) {
+			delete(s.allocations, ip)
+		}
+	}
+	return nil
+}
+
+// GetByID implements Store
+func (s *MemoryStore) GetByID(id, ifname string) ([]net.IP, error) {
+	s.data.Lock()
+	defer s.data.Unlock()
+	var ips []net.IP
+	for ip, a := range s.allocations {
+		if a == (allocation{id, ifname}) {
+			ips = append(ips, net.ParseIP(ip))
+		}
+	}
+	sort.Slice(ips, func(i, j int) bool { return ips[i].String() < ips[j].String() })
+	return ips, nil
+}
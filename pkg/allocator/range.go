@@ -0,0 +1,162 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// Range is a range of addresses in a subnet that may be allocated
+type Range struct {
+	RangeStart net.IP      `json:"rangeStart,omitempty"`
+	RangeEnd   net.IP      `json:"rangeEnd,omitempty"`
+	Subnet     types.IPNet `json:"subnet"`
+	Gateway    net.IP      `json:"gateway,omitempty"`
+}
+
+// RangeSet is a set of ranges of the same address family, allocated from
+// in order
+type RangeSet []Range
+
+// Canonicalize checks the range and fills in its defaults: the range spans
+// the subnet, without its network and broadcast addresses, and the gateway
+// is the subnet's first address. The gateway is never allocated.
+func (r *Range) Canonicalize() error {
+	if err := canonicalizeIP(&r.Subnet.IP); err != nil {
+		return err
+	}
+	ones, bits := r.Subnet.Mask.Size()
+	if bits == 0 {
+		return fmt.Errorf("invalid subnet mask %s", net.IP(r.Subnet.Mask))
+	}
+	if len(r.Subnet.IP) != bits/8 {
+		return fmt.Errorf("subnet %s has a mask of the wrong address family", r.subnet())
+	}
+	if bits-ones < 2 {
+		return fmt.Errorf("subnet %s is too small to allocate from", r.subnet())
+	}
+	if !r.Subnet.IP.Mask(r.Subnet.Mask).Equal(r.Subnet.IP) {
+		return fmt.Errorf("subnet %s has host bits set", r.subnet())
+	}
+
+	if r.Gateway == nil {
+		r.Gateway = nextIP(r.Subnet.IP)
+	} else if err := r.canonicalizeInSubnet(&r.Gateway, "gateway"); err != nil {
+		return err
+	}
+
+	if r.RangeStart == nil {
+		r.RangeStart = nextIP(r.Subnet.IP)
+	} else if err := r.canonicalizeInSubnet(&r.RangeStart, "rangeStart"); err != nil {
+		return err
+	}
+
+	if r.RangeEnd == nil {
+		r.RangeEnd = lastIP(net.IPNet(r.Subnet))
+		if len(r.RangeEnd) == net.IPv4len {
+			// the broadcast address
+			r.RangeEnd = prevIP(r.RangeEnd)
+		}
+	} else if err := r.canonicalizeInSubnet(&r.RangeEnd, "rangeEnd"); err != nil {
+		return err
+	}
+
+	if compareIPs(r.RangeStart, r.RangeEnd) > 0 {
+		return fmt.Errorf("rangeStart %s is after rangeEnd %s", r.RangeStart, r.RangeEnd)
+	}
+	return nil
+}
+
+func (r *Range) canonicalizeInSubnet(ip *net.IP, field string) error {
+	if err := canonicalizeIP(ip); err != nil {
+		return fmt.Errorf("invalid %s: %w", field, err)
+	}
+	if !r.subnet().Contains(*ip) {
+		return fmt.Errorf("%s %s is not in subnet %s", field, *ip, r.subnet())
+	}
+	return nil
+}
+
+// Contains reports whether ip is in the range
+func (r *Range) Contains(ip net.IP) bool {
+	if err := canonicalizeIP(&ip); err != nil || len(ip) != len(r.RangeStart) {
+		return false
+	}
+	return compareIPs(ip, r.RangeStart) >= 0 && compareIPs(ip, r.RangeEnd) <= 0
+}
+
+// Overlaps reports whether two canonical ranges share addresses
+func (r *Range) Overlaps(other *Range) bool {
+	if len(r.RangeStart) != len(other.RangeStart) {
+		return false
+	}
+	return r.Contains(other.RangeStart) || r.Contains(other.RangeEnd) ||
+		other.Contains(r.RangeStart) || other.Contains(r.RangeEnd)
+}
+
+func (r *Range) subnet() *net.IPNet {
+	return (*net.IPNet)(&r.Subnet)
+}
+
+func (r *Range) String() string {
+	return fmt.Sprintf("%s-%s", r.RangeStart, r.RangeEnd)
+}
+
+// Canonicalize canonicalizes every range, and checks that they are of the
+// same family and do not overlap
+func (s *RangeSet) Canonicalize() error {
+	if len(*s) == 0 {
+		return fmt.Errorf("empty range set")
+	}
+	for i := range *s {
+		r := &(*s)[i]
+		if err := r.Canonicalize(); err != nil {
+			return err
+		}
+		if len(r.RangeStart) != len((*s)[0].RangeStart) {
+			return fmt.Errorf("range set mixes address families")
+		}
+		for j := 0; j < i; j++ {
+			if r.Overlaps(&(*s)[j]) {
+				return fmt.Errorf("range %s overlaps range %s", r, &(*s)[j])
+			}
+		}
+	}
+	return nil
+}
+
+// RangeFor returns the range containing ip
+func (s *RangeSet) RangeFor(ip net.IP) (*Range, error) {
+	for i := range *s {
+		if (*s)[i].Contains(ip) {
+			return &(*s)[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not in range set %s", ip, s)
+}
+
+func (s *RangeSet) String() string {
+	out := ""
+	for i := range *s {
+		if i > 0 {
+			out += ","
+		}
+		out += (*s)[i].String()
+	}
+	return out
+}
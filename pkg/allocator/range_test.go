@@ -0,0 +1,89 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/allocator"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+func mustSubnet(s string) types.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	Expect(err).NotTo(HaveOccurred())
+	return types.IPNet(*n)
+}
+
+var _ = Describe("Range", func() {
+	It("defaults to the subnet without its network, gateway and broadcast addresses", func() {
+		r := allocator.Range{Subnet: mustSubnet("10.1.2.0/24")}
+		Expect(r.Canonicalize()).To(Succeed())
+		Expect(r.RangeStart.String()).To(Equal("10.1.2.1"))
+		Expect(r.RangeEnd.String()).To(Equal("10.1.2.254"))
+		Expect(r.Gateway.String()).To(Equal("10.1.2.1"))
+		Expect(r.RangeStart).To(HaveLen(net.IPv4len))
+	})
+
+	It("includes the last address of IPv6 subnets", func() {
+		r := allocator.Range{Subnet: mustSubnet("2001:db8::/120")}
+		Expect(r.Canonicalize()).To(Succeed())
+		Expect(r.RangeEnd.String()).To(Equal("2001:db8::ff"))
+	})
+
+	It("rejects invalid ranges", func() {
+		for _, r := range []allocator.Range{
+			{Subnet: mustSubnet("10.1.2.0/31")},
+			{Subnet: types.IPNet{IP: net.ParseIP("10.1.2.3"), Mask: net.CIDRMask(24, 32)}},
+			{Subnet: mustSubnet("10.1.2.0/24"), RangeStart: net.ParseIP("10.1.3.1")},
+			{Subnet: mustSubnet("10.1.2.0/24"), Gateway: net.ParseIP("10.1.3.1")},
+			{Subnet: mustSubnet("10.1.2.0/24"), RangeStart: net.ParseIP("10.1.2.9"), RangeEnd: net.ParseIP("10.1.2.8")},
+		} {
+			Expect(r.Canonicalize()).NotTo(Succeed(), "range %+v", r)
+		}
+	})
+
+	It("rejects overlapping and mixed range sets", func() {
+		overlapping := allocator.RangeSet{
+			{Subnet: mustSubnet("10.1.2.0/24")},
+			{Subnet: mustSubnet("10.1.2.0/25")},
+		}
+		Expect(overlapping.Canonicalize()).To(MatchError(ContainSubstring("overlaps")))
+
+		mixed := allocator.RangeSet{
+			{Subnet: mustSubnet("10.1.2.0/24")},
+			{Subnet: mustSubnet("2001:db8::/64")},
+		}
+		Expect(mixed.Canonicalize()).To(MatchError("range set mixes address families"))
+	})
+
+	It("finds the range of an address", func() {
+		set := allocator.RangeSet{
+			{Subnet: mustSubnet("10.1.2.0/24")},
+			{Subnet: mustSubnet("10.1.4.0/24")},
+		}
+		Expect(set.Canonicalize()).To(Succeed())
+
+		r, err := set.RangeFor(net.ParseIP("10.1.4.7"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r).To(BeIdenticalTo(&set[1]))
+
+		_, err = set.RangeFor(net.ParseIP("10.1.3.7"))
+		Expect(err).To(MatchError("10.1.3.7 is not in range set 10.1.2.1-10.1.2.254,10.1.4.1-10.1.4.254"))
+	})
+})
@@ -0,0 +1,44 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package allocator
+
+import (
+	"context"
+	"net"
+)
+
+// Store records which addresses of a network are allocated to which
+// container interfaces. Its methods other than Lock, Unlock and Close must
+// be called with the store locked.
+type Store interface {
+	// Lock waits until it takes the network's lock, shared with other
+	// processes using the same store, or ctx is done
+	Lock(ctx context.Context) error
+	Unlock() error
+	Close() error
+
+	// Reserve records ip as allocated to the container's interface, and
+	// as the last address reserved in the range with rangeID. It returns
+	// false if ip is already allocated.
+	Reserve(id, ifname string, ip net.IP, rangeID string) (bool, error)
+	// LastReservedIP returns the last address reserved in the range with
+	// rangeID, or nil if there is none
+	LastReservedIP(rangeID string) (net.IP, error)
+	// ReleaseByID releases the addresses allocated to the container's
+	// interface
+	ReleaseByID(id, ifname string) error
+	// GetByID returns the addresses allocated to the container's interface
+	GetByID(id, ifname string) ([]net.IP, error)
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audit records CNI operations for security and compliance review
+// of network changes: who made which change, when, to which container,
+// with which configuration and result, and whether it succeeded.
+//
+// Runtimes set a Sink, such as a FileSink appending JSON lines, as the
+// Audit of their libcni.CNIConfig, and plugins pass one to skel.WithAudit:
+//
+//	sink, err := audit.NewFileSink("/var/log/cni/audit.jsonl")
+//	cniConfig.Audit = sink
+//
+// Every record carries a correlation ID. libcni creates one per operation,
+// unless the context already has one from WithCorrelationID, and passes
+// it to the plugins it runs in CorrelationIDEnv, so that the records of a
+// runtime and of its plugins can be joined.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// The sources of records
+const (
+	SourceRuntime = "runtime"
+	SourcePlugin  = "plugin"
+)
+
+// The outcomes of operations
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Record describes one CNI operation: a libcni network list operation,
+// such as "AddNetworkList", or a plugin command, such as "ADD"
+type Record struct {
+	Time          time.Time `json:"time"`
+	CorrelationID string    `json:"correlationId,omitempty"`
+	// Source is SourceRuntime or SourcePlugin
+	Source string `json:"source"`
+	Actor  Actor  `json:"actor"`
+
+	Operation   string `json:"operation"`
+	Network     string `json:"network,omitempty"`
+	CNIVersion  string `json:"cniVersion,omitempty"`
+	Plugin      string `json:"plugin,omitempty"`
+	ContainerID string `json:"containerId,omitempty"`
+	IfName      string `json:"ifName,omitempty"`
+	NetNS       string `json:"netns,omitempty"`
+
+	// ConfigHash and ResultHash are the Hash of the network
+	// configuration and of the result, if any
+	ConfigHash string `json:"configHash,omitempty"`
+	ResultHash string `json:"resultHash,omitempty"`
+
+	// Outcome is OutcomeSuccess or OutcomeFailure. Failures have the
+	// error and, for CNI errors, its code.
+	Outcome   string  `json:"outcome"`
+	ErrorCode uint    `json:"errorCode,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Duration  float64 `json:"durationSeconds"`
+}
+
+// SetOutcome sets the outcome of the record from the error the operation
+// returned
+func (r *Record) SetOutcome(err error) {
+	if err == nil {
+		r.Outcome = OutcomeSuccess
+		return
+	}
+	r.Outcome = OutcomeFailure
+	r.Error = err.Error()
+	if code, ok := types.CodeOf(err); ok {
+		r.ErrorCode = code
+	}
+}
+
+// Actor identifies the process making a change
+type Actor struct {
+	// Executable is the base name of the process's executable
+	Executable string `json:"executable"`
+	PID        int    `json:"pid"`
+	UID        int    `json:"uid"`
+}
+
+// CurrentActor returns the Actor of the calling process
+func CurrentActor() Actor {
+	a := Actor{PID: os.Getpid(), UID: os.Getuid()}
+	if exe, err := os.Executable(); err == nil {
+		a.Executable = filepath.Base(exe)
+	}
+	return a
+}
+
+// Sink receives audit records. It must be safe for concurrent use.
+type Sink interface {
+	Write(r *Record) error
+}
+
+// SinkFunc is a Sink calling a function
+type SinkFunc func(r *Record) error
+
+func (f SinkFunc) Write(r *Record) error {
+	return f(r)
+}
+
+// Hash returns "sha256:" and the hex SHA-256 digest of the canonical JSON
+// encoding of v, so that equal configurations or results hash alike
+// whatever their formatting. v may be JSON bytes. It returns "" for nil or
+// empty values, and for those which cannot be encoded.
+func Hash(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if b, ok := v.([]byte); ok && len(b) == 0 {
+		return ""
+	}
+	canonical, err := types.CanonicalJSON(v)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(canonical)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
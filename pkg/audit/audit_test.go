@@ -0,0 +1,121 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/audit"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+var _ = Describe("Audit", func() {
+	Describe("Hash", func() {
+		It("hashes equal JSON alike whatever its formatting", func() {
+			h := audit.Hash([]byte(`{"name": "net", "cniVersion": "1.0.0"}`))
+			Expect(h).To(HavePrefix("sha256:"))
+			Expect(h).To(HaveLen(len("sha256:") + 64))
+			Expect(audit.Hash([]byte(`{"cniVersion":"1.0.0","name":"net"}`))).To(Equal(h))
+			Expect(audit.Hash(map[string]string{"name": "net", "cniVersion": "1.0.0"})).To(Equal(h))
+			Expect(audit.Hash([]byte(`{"name": "other", "cniVersion": "1.0.0"}`))).NotTo(Equal(h))
+		})
+
+		It("returns nothing for missing values", func() {
+			Expect(audit.Hash(nil)).To(BeEmpty())
+			Expect(audit.Hash([]byte{})).To(BeEmpty())
+			Expect(audit.Hash([]byte(`not json`))).To(BeEmpty())
+		})
+	})
+
+	Describe("Record", func() {
+		It("sets the outcome from the error", func() {
+			r := &audit.Record{}
+			r.SetOutcome(nil)
+			Expect(r.Outcome).To(Equal(audit.OutcomeSuccess))
+
+			r = &audit.Record{}
+			r.SetOutcome(types.NewError(types.ErrTryAgainLater, "busy", ""))
+			Expect(r.Outcome).To(Equal(audit.OutcomeFailure))
+			Expect(r.ErrorCode).To(Equal(types.ErrTryAgainLater))
+			Expect(r.Error).To(Equal("busy"))
+
+			r = &audit.Record{}
+			r.SetOutcome(errors.New("boom"))
+			Expect(r.ErrorCode).To(BeZero())
+			Expect(r.Error).To(Equal("boom"))
+		})
+
+		It("identifies the calling process", func() {
+			actor := audit.CurrentActor()
+			Expect(actor.PID).To(Equal(os.Getpid()))
+			Expect(actor.UID).To(Equal(os.Getuid()))
+			Expect(actor.Executable).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("correlation IDs", func() {
+		It("are carried by contexts", func() {
+			ctx := context.Background()
+			Expect(audit.CorrelationID(ctx)).To(BeEmpty())
+			Expect(audit.CorrelationID(audit.WithCorrelationID(ctx, "req-1"))).To(Equal("req-1"))
+		})
+
+		It("are random", func() {
+			id := audit.NewCorrelationID()
+			Expect(id).To(MatchRegexp(`^[0-9a-f]{32}$`))
+			Expect(audit.NewCorrelationID()).NotTo(Equal(id))
+		})
+	})
+
+	Describe("FileSink", func() {
+		It("appends records as JSON lines", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "log", "audit.jsonl")
+			start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			for _, op := range []string{"ADD", "DEL"} {
+				sink, err := audit.NewFileSink(path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(sink.Write(&audit.Record{Time: start, Source: audit.SourcePlugin, Operation: op, Outcome: audit.OutcomeSuccess})).To(Succeed())
+				Expect(sink.Close()).To(Succeed())
+			}
+
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+			f, err := os.Open(path)
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+			var ops []string
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var r audit.Record
+				Expect(json.Unmarshal(scanner.Bytes(), &r)).To(Succeed())
+				Expect(r.Time).To(Equal(start))
+				ops = append(ops, r.Operation)
+			}
+			Expect(ops).To(Equal([]string{"ADD", "DEL"}))
+		})
+	})
+})
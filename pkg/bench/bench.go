@@ -0,0 +1,248 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench repeatedly runs a plugin's commands and reports the
+// latency distribution and allocations of each, so plugin authors can
+// detect performance regressions in CI:
+//
+//	report, err := bench.Run(ctx, bench.Config{
+//		PluginPath: "./bin/myplugin",
+//		NetConf:    `{"cniVersion": "1.0.0", "name": "bench", "type": "myplugin"}`,
+//		NetNS:      "/var/run/netns/bench",
+//	})
+//	...
+//	if err := report.Compare(baseline, 0.2); err != nil {
+//		// a command got more than 20% slower, or allocates more
+//	}
+//
+// Plugins run as binaries by default; InProcess runs their funcs in the
+// benchmark's process instead, without the cost of starting a process.
+// Allocations are those of the benchmark's process, so they only include
+// the plugin's own for plugins run in process.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// Config describes the plugin to benchmark and how to run it
+type Config struct {
+	// Exec runs the plugin. It defaults to running PluginPath as a binary.
+	Exec invoke.Exec
+	// PluginPath is the plugin binary, or the name of an in-process plugin
+	PluginPath string
+	// NetConf is a text/template of the network configuration, executed
+	// with a TemplateData for each iteration, so that configurations can
+	// vary between iterations
+	NetConf string
+	// Commands are run in order in each iteration. They default to ADD,
+	// CHECK and DEL. The result of ADD is passed as prevResult to the
+	// following commands, for versions that support it.
+	Commands []string
+	// Iterations defaults to 100
+	Iterations int
+	// Warmup is the number of iterations run, but not measured, first
+	Warmup int
+	// NetNS is the network namespace commands run against, which plugins
+	// using pkg/skel require
+	NetNS string
+	// IfName defaults to "eth0"
+	IfName string
+	// CNIPath is passed to the plugin as CNI_PATH, for plugins that
+	// delegate. It defaults to the directory of PluginPath.
+	CNIPath []string
+}
+
+// TemplateData is what the NetConf template is executed with
+type TemplateData struct {
+	// Iteration counts from 0, warmup iterations included
+	Iteration int
+	// ContainerID is the container ID of the iteration
+	ContainerID string
+}
+
+// Run benchmarks the plugin. It stops at the first command that fails, as
+// the timings of a failing plugin say nothing about a working one.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Exec == nil {
+		cfg.Exec = &invoke.DefaultExec{RawExec: &invoke.RawExec{Stderr: io.Discard}}
+	}
+	if len(cfg.Commands) == 0 {
+		cfg.Commands = []string{"ADD", "CHECK", "DEL"}
+	}
+	if cfg.Iterations <= 0 {
+		cfg.Iterations = 100
+	}
+	if cfg.IfName == "" {
+		cfg.IfName = "eth0"
+	}
+	if len(cfg.CNIPath) == 0 {
+		cfg.CNIPath = []string{filepath.Dir(cfg.PluginPath)}
+	}
+	tmpl, err := template.New("netconf").Option("missingkey=error").Parse(cfg.NetConf)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network configuration template: %w", err)
+	}
+
+	samples := make(map[string][]sample, len(cfg.Commands))
+	for i := 0; i < cfg.Warmup+cfg.Iterations; i++ {
+		data := TemplateData{Iteration: i, ContainerID: fmt.Sprintf("bench-%d", i)}
+		netconf := &bytes.Buffer{}
+		if err := tmpl.Execute(netconf, data); err != nil {
+			return nil, fmt.Errorf("iteration %d: failed to generate network configuration: %w", i, err)
+		}
+		it, err := newIteration(netconf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("iteration %d: %w", i, err)
+		}
+
+		for _, command := range cfg.Commands {
+			stdin, err := it.stdin(command)
+			if err != nil {
+				return nil, fmt.Errorf("iteration %d: %s: %w", i, command, err)
+			}
+			environ := (&invoke.Args{
+				Command:     command,
+				ContainerID: data.ContainerID,
+				NetNS:       cfg.NetNS,
+				IfName:      cfg.IfName,
+				Path:        strings.Join(cfg.CNIPath, string(os.PathListSeparator)),
+			}).AsEnv()
+
+			s, stdout, err := measure(func() ([]byte, error) {
+				return cfg.Exec.ExecPlugin(ctx, cfg.PluginPath, stdin, environ)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("iteration %d: %s: %w", i, command, err)
+			}
+			if command == "ADD" && len(stdout) > 0 {
+				it.prevResult = stdout
+			}
+			if i >= cfg.Warmup {
+				samples[command] = append(samples[command], s)
+			}
+		}
+	}
+
+	report := &Report{Plugin: cfg.PluginPath, Iterations: cfg.Iterations}
+	for _, command := range cfg.Commands {
+		report.Commands = append(report.Commands, summarize(command, samples[command]))
+	}
+	return report, nil
+}
+
+// sample is the cost of a single run of a command
+type sample struct {
+	duration time.Duration
+	allocs   uint64
+	bytes    uint64
+}
+
+func measure(run func() ([]byte, error)) (sample, []byte, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	stdout, err := run()
+	duration := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return sample{
+		duration: duration,
+		allocs:   after.Mallocs - before.Mallocs,
+		bytes:    after.TotalAlloc - before.TotalAlloc,
+	}, stdout, err
+}
+
+// iteration holds the network configuration of an iteration and the
+// result of its ADD
+type iteration struct {
+	netconf    map[string]interface{}
+	cniVersion string
+	prevResult []byte
+}
+
+func newIteration(netconf []byte) (*iteration, error) {
+	conf := map[string]interface{}{}
+	if err := json.Unmarshal(netconf, &conf); err != nil {
+		return nil, fmt.Errorf("invalid network configuration: %w", err)
+	}
+	cniVersion, _ := conf["cniVersion"].(string)
+	return &iteration{netconf: conf, cniVersion: cniVersion}, nil
+}
+
+// stdin returns the network configuration of the command
+func (it *iteration) stdin(command string) ([]byte, error) {
+	if command == "ADD" || it.prevResult == nil {
+		return json.Marshal(it.netconf)
+	}
+	if ok, _ := version.Supports(version.FeatureDelPrevResult, it.cniVersion); !ok {
+		return json.Marshal(it.netconf)
+	}
+	conf := make(map[string]interface{}, len(it.netconf)+1)
+	for k, v := range it.netconf {
+		conf[k] = v
+	}
+	conf["prevResult"] = json.RawMessage(it.prevResult)
+	return json.Marshal(conf)
+}
+
+// InProcess returns an Exec which runs the plugin funcs in the calling
+// process, whatever the plugin path. As with pkg/daemon, the funcs must
+// print their results with skel.PrintResult.
+func InProcess(funcs skel.CNIFuncs, versionInfo version.PluginInfo) invoke.Exec {
+	return &inProcessExec{funcs: funcs, versionInfo: versionInfo}
+}
+
+type inProcessExec struct {
+	version.PluginDecoder
+	funcs       skel.CNIFuncs
+	versionInfo version.PluginInfo
+}
+
+func (e *inProcessExec) ExecPlugin(_ context.Context, _ string, stdinData []byte, environ []string) ([]byte, error) {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			env[k] = v
+		}
+	}
+	stdout := &bytes.Buffer{}
+	pio := skel.IO{
+		Getenv: func(key string) string { return env[key] },
+		Stdin:  bytes.NewReader(stdinData),
+		Stdout: stdout,
+		Stderr: io.Discard,
+	}
+	if err := skel.PluginMainFuncsWithIO(pio, e.funcs, e.versionInfo, ""); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+func (e *inProcessExec) FindInPath(plugin string, _ []string) (string, error) {
+	return plugin, nil
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/bench"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Run", func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Context("with a plugin binary", func() {
+		var fake *plugintest.Fake
+
+		BeforeEach(func() {
+			var err error
+			fake, err = plugintest.Install(GinkgoT().TempDir(), plugintest.Plugin{
+				Name: "fake",
+				Responses: map[string]plugintest.Response{"ADD": {Result: `{
+					"cniVersion": "{{.CNIVersion}}",
+					"ips": [{"address": "10.0.0.{{.Config.host}}/24"}]
+				}`}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("runs each command of each iteration with generated configurations", func() {
+			report, err := bench.Run(ctx, bench.Config{
+				PluginPath: fake.Path,
+				NetConf:    `{"cniVersion": "1.0.0", "name": "bench", "type": "fake", "host": {{.Iteration}}}`,
+				Iterations: 3,
+				Warmup:     1,
+				NetNS:      "/var/run/netns/bench",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Plugin).To(Equal(fake.Path))
+			Expect(report.Iterations).To(Equal(3))
+			Expect(report.Commands).To(HaveLen(3))
+			for i, command := range []string{"ADD", "CHECK", "DEL"} {
+				stats := report.Commands[i]
+				Expect(stats.Command).To(Equal(command))
+				Expect(stats.Runs).To(Equal(3))
+				Expect(stats.Min).To(BeNumerically(">", 0))
+				Expect(stats.Min).To(BeNumerically("<=", stats.P50))
+				Expect(stats.P50).To(BeNumerically("<=", stats.P90))
+				Expect(stats.P99).To(BeNumerically("<=", stats.Max))
+			}
+
+			invocations, err := fake.Invocations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invocations).To(HaveLen(12))
+			last := invocations[11]
+			Expect(last.Command).To(Equal("DEL"))
+			Expect(last.ContainerID).To(Equal("bench-3"))
+			Expect(last.NetNS).To(Equal("/var/run/netns/bench"))
+			Expect(last.IfName).To(Equal("eth0"))
+			conf := map[string]interface{}{}
+			Expect(json.Unmarshal(last.StdinData, &conf)).To(Succeed())
+			Expect(conf["host"]).To(BeEquivalentTo(3))
+			Expect(conf["prevResult"]).To(HaveKeyWithValue("ips", ConsistOf(HaveKeyWithValue("address", "10.0.0.3/24"))))
+		})
+
+		It("does not pass prevResult to versions without it", func() {
+			_, err := bench.Run(ctx, bench.Config{
+				PluginPath: fake.Path,
+				NetConf:    `{"cniVersion": "0.3.1", "name": "bench", "type": "fake", "host": 1}`,
+				Commands:   []string{"ADD", "DEL"},
+				Iterations: 1,
+				NetNS:      "/var/run/netns/bench",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			invocations, err := fake.Invocations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invocations).To(HaveLen(2))
+			Expect(string(invocations[1].StdinData)).NotTo(ContainSubstring("prevResult"))
+		})
+
+		It("stops at the first failure", func() {
+			_, err := plugintest.Install(filepath.Dir(fake.Path), plugintest.Plugin{
+				Name:      "fake",
+				Responses: map[string]plugintest.Response{"CHECK": {ErrorCode: 100, ErrorMsg: "broken"}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = bench.Run(ctx, bench.Config{
+				PluginPath: fake.Path,
+				NetConf:    `{"cniVersion": "1.0.0", "name": "bench", "type": "fake"}`,
+				NetNS:      "/var/run/netns/bench",
+			})
+			Expect(err).To(MatchError(ContainSubstring("iteration 0: CHECK: broken")))
+			invocations, err := fake.Invocations()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(invocations).To(HaveLen(2))
+		})
+	})
+
+	Context("with an in-process plugin", func() {
+		var calls map[string]int
+
+		BeforeEach(func() {
+			calls = map[string]int{}
+		})
+
+		funcs := func() skel.CNIFuncs {
+			return skel.CNIFuncs{
+				Add: func(args *skel.CmdArgs) error {
+					calls["ADD "+args.ContainerID]++
+					return skel.PrintResult(args, &current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+				},
+				Check: func(args *skel.CmdArgs) error {
+					calls["CHECK"]++
+					return nil
+				},
+				Del: func(args *skel.CmdArgs) error {
+					calls["DEL"]++
+					return nil
+				},
+			}
+		}
+
+		It("measures the plugin's runs and allocations", func() {
+			report, err := bench.Run(ctx, bench.Config{
+				Exec:       bench.InProcess(funcs(), version.All),
+				PluginPath: "in-process",
+				NetConf:    `{"cniVersion": "1.0.0", "name": "bench", "type": "in-process"}`,
+				Iterations: 5,
+				NetNS:      "/var/run/netns/bench",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(HaveLen(7))
+			Expect(calls).To(HaveKeyWithValue("ADD bench-4", 1))
+			Expect(calls).To(HaveKeyWithValue("CHECK", 5))
+			Expect(calls).To(HaveKeyWithValue("DEL", 5))
+			add := report.Stats("ADD")
+			Expect(add).NotTo(BeNil())
+			Expect(add.Runs).To(Equal(5))
+			Expect(add.AllocsPerRun).To(BeNumerically(">", 0))
+			Expect(add.BytesPerRun).To(BeNumerically(">", 0))
+			Expect(report.Stats("GC")).To(BeNil())
+		})
+
+		It("reports the plugin's errors", func() {
+			f := funcs()
+			f.Add = func(*skel.CmdArgs) error {
+				return types.NewError(types.ErrTryAgainLater, "busy", "")
+			}
+			_, err := bench.Run(ctx, bench.Config{
+				Exec:    bench.InProcess(f, version.All),
+				NetConf: `{"cniVersion": "1.0.0", "name": "bench", "type": "in-process"}`,
+				NetNS:   "/var/run/netns/bench",
+			})
+			Expect(err).To(MatchError("iteration 0: ADD: busy"))
+			var cniErr *types.Error
+			Expect(errors.As(err, &cniErr)).To(BeTrue())
+			Expect(cniErr.Code).To(Equal(uint(types.ErrTryAgainLater)))
+		})
+	})
+
+	It("rejects bad network configurations", func() {
+		_, err := bench.Run(ctx, bench.Config{NetConf: `{{.Nope`})
+		Expect(err).To(MatchError(ContainSubstring("invalid network configuration template")))
+
+		_, err = bench.Run(ctx, bench.Config{NetConf: `{{.Nope}}`})
+		Expect(err).To(MatchError(ContainSubstring("iteration 0: failed to generate network configuration")))
+
+		_, err = bench.Run(ctx, bench.Config{NetConf: `nope`})
+		Expect(err).To(MatchError(ContainSubstring("iteration 0: invalid network configuration")))
+	})
+})
+
+var _ = Describe("Report", func() {
+	var baseline, report *bench.Report
+
+	BeforeEach(func() {
+		baseline = &bench.Report{Plugin: "fake", Iterations: 10, Commands: []bench.Stats{
+			{Command: "ADD", Runs: 10, P50: 10 * time.Millisecond, AllocsPerRun: 100, BytesPerRun: 4096},
+			{Command: "DEL", Runs: 10, P50: 5 * time.Millisecond},
+		}}
+		report = &bench.Report{Plugin: "fake", Iterations: 10, Commands: []bench.Stats{
+			{Command: "ADD", Runs: 10, P50: 11 * time.Millisecond, AllocsPerRun: 110, BytesPerRun: 4096},
+			{Command: "DEL", Runs: 10, P50: 5 * time.Millisecond, AllocsPerRun: 1},
+			{Command: "GC", Runs: 10, P50: time.Second},
+		}}
+	})
+
+	It("accepts costs within the tolerance", func() {
+		report.Commands[1].AllocsPerRun = 0
+		Expect(report.Compare(baseline, 0.1)).To(Succeed())
+	})
+
+	It("describes regressions", func() {
+		err := report.Compare(baseline, 0.05)
+		Expect(err).To(HaveOccurred())
+		Expect(strings.Split(err.Error(), "\n")).To(Equal([]string{
+			"ADD: p50 latency 11ms exceeds baseline 10ms by 10%",
+			"ADD: allocs/run 110 exceeds baseline 100 by 10%",
+			"DEL: allocs/run 1 exceeds baseline of 0",
+		}))
+	})
+
+	It("round trips through JSON", func() {
+		data, err := json.Marshal(report)
+		Expect(err).NotTo(HaveOccurred())
+		decoded := &bench.Report{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+		Expect(decoded).To(Equal(report))
+	})
+
+	It("prints a table", func() {
+		out := &bytes.Buffer{}
+		Expect(report.Print(out)).To(Succeed())
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(Equal("fake: 10 iterations"))
+		Expect(strings.Fields(lines[1])).To(Equal([]string{"command", "runs", "min", "mean", "p50", "p90", "p99", "max", "allocs/run", "B/run"}))
+		Expect(strings.Fields(lines[2])).To(Equal([]string{"ADD", "10", "0s", "0s", "11ms", "0s", "0s", "0s", "110", "4096"}))
+	})
+})
+
+var _ = Describe("ParseGoTest", func() {
+	const output = `goos: linux
+goarch: amd64
+pkg: github.com/containernetworking/cni/libcni
+cpu: Intel(R) Xeon(R) CPU @ 2.20GHz
+BenchmarkAddNetworkList-8   	    2000	    500000 ns/op	  120000 B/op	    2000 allocs/op
+BenchmarkAddNetworkList-8   	    2000	    520000 ns/op	  120000 B/op	    2000 allocs/op
+BenchmarkAddNetworkList-8   	    2000	    510000 ns/op	  120000 B/op	    2001 allocs/op
+PASS
+ok  	github.com/containernetworking/cni/libcni	3.000s
+pkg: github.com/containernetworking/cni/pkg/types/100
+BenchmarkGetAsVersion/0.4.0 	 1000000	       650.5 ns/op
+PASS
+`
+
+	It("reports each benchmark as a command", func() {
+		report, err := bench.ParseGoTest(strings.NewReader(output))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Iterations).To(Equal(3))
+		Expect(report.Commands).To(HaveLen(2))
+
+		add := report.Stats("libcni.AddNetworkList")
+		Expect(add).NotTo(BeNil())
+		Expect(add.Runs).To(Equal(3))
+		Expect(add.Min).To(Equal(500 * time.Microsecond))
+		Expect(add.P50).To(Equal(510 * time.Microsecond))
+		Expect(add.Max).To(Equal(520 * time.Microsecond))
+		Expect(add.AllocsPerRun).To(BeEquivalentTo(2000))
+		Expect(add.BytesPerRun).To(BeEquivalentTo(120000))
+
+		convert := report.Stats("100.GetAsVersion/0.4.0")
+		Expect(convert).NotTo(BeNil())
+		Expect(convert.P50).To(Equal(650 * time.Nanosecond))
+		Expect(convert.AllocsPerRun).To(BeZero())
+	})
+
+	It("compares with a baseline", func() {
+		baseline, err := bench.ParseGoTest(strings.NewReader(output))
+		Expect(err).NotTo(HaveOccurred())
+		report, err := bench.ParseGoTest(strings.NewReader(strings.ReplaceAll(output, "2000 allocs/op", "2500 allocs/op")))
+		Expect(err).NotTo(HaveOccurred())
+		err = report.Compare(baseline, 0.1)
+		Expect(err).To(MatchError("libcni.AddNetworkList: allocs/run 2333 exceeds baseline 2000 by 17%"))
+	})
+
+	It("fails without benchmark results", func() {
+		_, err := bench.ParseGoTest(strings.NewReader("PASS\nok  \tgithub.com/containernetworking/cni/libcni\t0.1s\n"))
+		Expect(err).To(MatchError("no benchmark results found"))
+	})
+
+	It("fails on malformed results", func() {
+		_, err := bench.ParseGoTest(strings.NewReader("BenchmarkAdd-8 100 fast ns/op\n"))
+		Expect(err).To(MatchError(ContainSubstring("invalid benchmark result")))
+	})
+})
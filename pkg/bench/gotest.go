@@ -0,0 +1,112 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// goBenchLine matches the result of a Go benchmark, as printed by
+// "go test -bench", and its GOMAXPROCS suffix
+var goBenchLine = regexp.MustCompile(`^Benchmark(\S+?)(?:-\d+)?\s+(\d+)\s+(.*)$`)
+
+// ParseGoTest reads the output of "go test -bench", preferably with
+// -benchmem and -count, into a report with a command per benchmark, named
+// after the base of its package and the benchmark without its "Benchmark"
+// prefix, such as "libcni.AddNetworkList". Each line of a benchmark is a
+// run, whose latency is its ns/op, so that the p50 of a report is the
+// median of the -count lines and reports can be compared like those of Run.
+func ParseGoTest(r io.Reader) (*Report, error) {
+	samples := map[string][]sample{}
+	var commands []string
+	pkg := ""
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := strings.CutPrefix(line, "pkg: "); ok {
+			pkg = path.Base(strings.TrimSpace(p))
+			continue
+		}
+		m := goBenchLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		s, err := parseGoBenchMetrics(m[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid benchmark result %q: %w", line, err)
+		}
+		command := m[1]
+		if pkg != "" {
+			command = pkg + "." + command
+		}
+		if _, ok := samples[command]; !ok {
+			commands = append(commands, command)
+		}
+		samples[command] = append(samples[command], s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("no benchmark results found")
+	}
+
+	report := &Report{Plugin: "go test"}
+	for _, command := range commands {
+		report.Commands = append(report.Commands, summarize(command, samples[command]))
+		if n := len(samples[command]); n > report.Iterations {
+			report.Iterations = n
+		}
+	}
+	return report, nil
+}
+
+// parseGoBenchMetrics parses the "value unit" pairs following the iteration
+// count of a benchmark result. Units other than ns/op, B/op and allocs/op
+// are ignored.
+func parseGoBenchMetrics(metrics string) (sample, error) {
+	var s sample
+	fields := strings.Fields(metrics)
+	if len(fields)%2 != 0 {
+		return s, fmt.Errorf("unpaired value %q", fields[len(fields)-1])
+	}
+	seen := false
+	for i := 0; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return s, err
+		}
+		switch fields[i+1] {
+		case "ns/op":
+			s.duration = time.Duration(value)
+			seen = true
+		case "B/op":
+			s.bytes = uint64(value)
+		case "allocs/op":
+			s.allocs = uint64(value)
+		}
+	}
+	if !seen {
+		return s, fmt.Errorf("missing ns/op")
+	}
+	return s, nil
+}
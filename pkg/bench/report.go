@@ -0,0 +1,148 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Stats are the costs of the runs of a command
+type Stats struct {
+	Command string `json:"command"`
+	Runs    int    `json:"runs"`
+
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+
+	// AllocsPerRun and BytesPerRun are the mean heap allocations of a run
+	AllocsPerRun uint64 `json:"allocsPerRun"`
+	BytesPerRun  uint64 `json:"bytesPerRun"`
+}
+
+// Report is the outcome of a benchmark. It may be saved as JSON to serve
+// as the baseline of later benchmarks.
+type Report struct {
+	Plugin     string  `json:"plugin"`
+	Iterations int     `json:"iterations"`
+	Commands   []Stats `json:"commands"`
+}
+
+func summarize(command string, samples []sample) Stats {
+	stats := Stats{Command: command, Runs: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+	durations := make([]time.Duration, len(samples))
+	var total time.Duration
+	var allocs, bytes uint64
+	for i, s := range samples {
+		durations[i] = s.duration
+		total += s.duration
+		allocs += s.allocs
+		bytes += s.bytes
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	n := len(durations)
+	stats.Min = durations[0]
+	stats.Max = durations[n-1]
+	stats.Mean = total / time.Duration(n)
+	stats.P50 = percentile(durations, 50)
+	stats.P90 = percentile(durations, 90)
+	stats.P99 = percentile(durations, 99)
+	stats.AllocsPerRun = allocs / uint64(n)
+	stats.BytesPerRun = bytes / uint64(n)
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats returns the stats of the command, or nil if it was not run
+func (r *Report) Stats(command string) *Stats {
+	for i := range r.Commands {
+		if r.Commands[i].Command == command {
+			return &r.Commands[i]
+		}
+	}
+	return nil
+}
+
+// Print writes the stats of each command as a table
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s: %d iterations\n", r.Plugin, r.Iterations)
+	fmt.Fprintln(tw, "command\truns\tmin\tmean\tp50\tp90\tp99\tmax\tallocs/run\tB/run")
+	for _, s := range r.Commands {
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%v\t%v\t%v\t%v\t%v\t%d\t%d\n",
+			s.Command, s.Runs, s.Min, s.Mean, s.P50, s.P90, s.P99, s.Max, s.AllocsPerRun, s.BytesPerRun)
+	}
+	return tw.Flush()
+}
+
+// Compare returns an error describing every command whose median latency,
+// allocations or allocated bytes per run exceed those of the baseline by
+// more than tolerance, a fraction (0.2 allows 20% more). Commands missing
+// from the baseline are not compared.
+func (r *Report) Compare(baseline *Report, tolerance float64) error {
+	var errs []error
+	for _, s := range r.Commands {
+		base := baseline.Stats(s.Command)
+		if base == nil {
+			continue
+		}
+		if err := regression(s.Command, "p50 latency", float64(s.P50), float64(base.P50), tolerance, func(v float64) string {
+			return time.Duration(v).String()
+		}); err != nil {
+			errs = append(errs, err)
+		}
+		if err := regression(s.Command, "allocs/run", float64(s.AllocsPerRun), float64(base.AllocsPerRun), tolerance, nil); err != nil {
+			errs = append(errs, err)
+		}
+		if err := regression(s.Command, "B/run", float64(s.BytesPerRun), float64(base.BytesPerRun), tolerance, nil); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func regression(command, metric string, value, base, tolerance float64, format func(float64) string) error {
+	if value <= base*(1+tolerance) {
+		return nil
+	}
+	if format == nil {
+		format = func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	}
+	if base == 0 {
+		return fmt.Errorf("%s: %s %s exceeds baseline of 0", command, metric, format(value))
+	}
+	return fmt.Errorf("%s: %s %s exceeds baseline %s by %.0f%%",
+		command, metric, format(value), format(base), (value/base-1)*100)
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chain holds the boilerplate of plugins which run in the middle
+// of a chain: they read the result of the plugins before them from the
+// prevResult, add what they created and print the result again, in the
+// version of the configuration.
+//
+//	link, err := chain.Require(args.StdinData)
+//	if err != nil {
+//		return err
+//	}
+//	idx := link.AddInterface(&current.Interface{Name: "tap0"})
+//	link.AddIP(&current.IPConfig{Address: addr}, idx)
+//	return link.Print()
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// Link is the part of a chain seen by one plugin: the result so far,
+// and the version in which it must be passed on.
+type Link struct {
+	// CNIVersion is the version of the network configuration, which
+	// the result must be printed in
+	CNIVersion string
+	// Result is the prevResult, converted to the current version so
+	// that it can be edited whatever version the configuration has
+	Result *current.Result
+}
+
+// Load parses the network configuration in stdinData and returns its
+// prevResult. The Link is nil if the configuration has no prevResult,
+// which is allowed for DEL.
+func Load(stdinData []byte) (*Link, error) {
+	conf := &types.NetConf{}
+	if err := json.Unmarshal(stdinData, conf); err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, "failed to parse network configuration", err.Error())
+	}
+	return FromNetConf(conf)
+}
+
+// Require is Load for the commands which need a prevResult, such as ADD
+// and CHECK. A missing prevResult is an invalid configuration error.
+func Require(stdinData []byte) (*Link, error) {
+	link, err := Load(stdinData)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, errNoPrevResult()
+	}
+	return link, nil
+}
+
+// FromNetConf returns the prevResult of an already parsed configuration,
+// whether or not version.ParsePrevResult was called on it. The Link is
+// nil if the configuration has no prevResult.
+func FromNetConf(conf *types.NetConf) (*Link, error) {
+	if conf.PrevResult == nil {
+		if conf.RawPrevResult == nil {
+			return nil, nil
+		}
+		// Parse a copy, leaving the caller's configuration as it was
+		parsed := *conf
+		parsed.RawPrevResult = make(map[string]interface{}, len(conf.RawPrevResult))
+		for k, v := range conf.RawPrevResult {
+			parsed.RawPrevResult[k] = v
+		}
+		if err := version.ParsePrevResult(&parsed); err != nil {
+			return nil, types.NewError(types.ErrDecodingFailure, "failed to parse prevResult", err.Error())
+		}
+		conf = &parsed
+	}
+
+	result, err := current.NewResultFromResult(conf.PrevResult)
+	if err != nil {
+		return nil, types.NewError(types.ErrIncompatibleCNIVersion, "failed to convert prevResult", err.Error())
+	}
+	return &Link{CNIVersion: conf.CNIVersion, Result: result}, nil
+}
+
+// RequireFromNetConf is FromNetConf for the commands which need a
+// prevResult.
+func RequireFromNetConf(conf *types.NetConf) (*Link, error) {
+	link, err := FromNetConf(conf)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, errNoPrevResult()
+	}
+	return link, nil
+}
+
+func errNoPrevResult() *types.Error {
+	return types.NewError(types.ErrInvalidNetworkConfig, "missing prevResult", "the plugin must be called as part of a chain")
+}
+
+// Interface returns the index and the result's interface with the given
+// name in the given sandbox, an empty sandbox meaning the host. The index
+// is -1 if there is no such interface.
+func (l *Link) Interface(name, sandbox string) (int, *current.Interface) {
+	for i, intf := range l.Result.Interfaces {
+		if intf.Name == name && intf.Sandbox == sandbox {
+			return i, intf
+		}
+	}
+	return -1, nil
+}
+
+// AddInterface appends intf to the result and returns its index, which
+// IPs configured on it refer to.
+func (l *Link) AddInterface(intf *current.Interface) int {
+	l.Result.Interfaces = append(l.Result.Interfaces, intf)
+	return len(l.Result.Interfaces) - 1
+}
+
+// AddIP appends ip to the result, configured on the interface at index
+// ifIndex. A negative index leaves the IP without an interface.
+func (l *Link) AddIP(ip *current.IPConfig, ifIndex int) {
+	if ifIndex >= 0 {
+		ip.Interface = current.Int(ifIndex)
+	} else {
+		ip.Interface = nil
+	}
+	l.Result.IPs = append(l.Result.IPs, ip)
+}
+
+// Merge adds a copy of the interfaces, IPs, routes and DNS settings of
+// another result, typically returned by an IPAM plugin or a delegate,
+// to the result. Its IPs and routes are renumbered to refer to its
+// interfaces at their new indexes. Extensions are not merged. Nothing is merged if the
+// result is invalid.
+func (l *Link) Merge(other types.Result) error {
+	res, err := current.NewResultFromResult(other)
+	if err != nil {
+		return fmt.Errorf("failed to convert result to merge: %w", err)
+	}
+
+	for _, ip := range res.IPs {
+		if ip.Interface != nil && (*ip.Interface < 0 || *ip.Interface >= len(res.Interfaces)) {
+			return fmt.Errorf("IP %s refers to missing interface %d", ip.Address.String(), *ip.Interface)
+		}
+	}
+	for _, route := range res.Routes {
+		if route.Interface != nil && (*route.Interface < 0 || *route.Interface >= len(res.Interfaces)) {
+			return fmt.Errorf("route %s refers to missing interface %d", route.Dst.String(), *route.Interface)
+		}
+	}
+
+	offset := len(l.Result.Interfaces)
+	for _, intf := range res.Interfaces {
+		l.Result.Interfaces = append(l.Result.Interfaces, intf.Copy())
+	}
+	for _, ip := range res.IPs {
+		ip = ip.Copy()
+		if ip.Interface != nil {
+			ip.Interface = current.Int(*ip.Interface + offset)
+		}
+		l.Result.IPs = append(l.Result.IPs, ip)
+	}
+	for _, route := range res.Routes {
+		route = route.Copy()
+		if route.Interface != nil {
+			route.Interface = current.Int(*route.Interface + offset)
+		}
+		l.Result.Routes = append(l.Result.Routes, route)
+	}
+	mergeDNS(&l.Result.DNS, &res.DNS)
+	return nil
+}
+
+func mergeDNS(dst, src *types.DNS) {
+	if dst.Domain == "" {
+		dst.Domain = src.Domain
+	}
+	dst.Nameservers = appendMissing(dst.Nameservers, src.Nameservers)
+	dst.Search = appendMissing(dst.Search, src.Search)
+	dst.Options = appendMissing(dst.Options, src.Options)
+}
+
+func appendMissing(dst, src []string) []string {
+	for _, s := range src {
+		found := false
+		for _, d := range dst {
+			if d == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst = append(dst, s)
+		}
+	}
+	return dst
+}
+
+// Print prints the result to stdout in the version of the configuration
+func (l *Link) Print() error {
+	return types.PrintResult(l.Result, l.CNIVersion)
+}
+
+// PrintTo prints the result to writer in the version of the configuration
+func (l *Link) PrintTo(writer io.Writer) error {
+	res, err := l.Result.GetAsVersion(l.CNIVersion)
+	if err != nil {
+		return err
+	}
+	return res.PrintTo(writer)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chain_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/chain"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+const prev040 = `{
+  "cniVersion": "0.4.0",
+  "name": "mynet",
+  "type": "tuning",
+  "prevResult": {
+    "cniVersion": "0.4.0",
+    "interfaces": [
+      {"name": "cni0"},
+      {"name": "eth0", "sandbox": "/var/run/netns/test"}
+    ],
+    "ips": [
+      {"version": "4", "interface": 1, "address": "10.1.2.3/24", "gateway": "10.1.2.1"}
+    ],
+    "dns": {"nameservers": ["10.1.0.10"]}
+  }
+}`
+
+var _ = Describe("Chain", func() {
+	It("converts the prevResult to the current version", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.CNIVersion).To(Equal("0.4.0"))
+		Expect(link.Result.Interfaces).To(HaveLen(2))
+		Expect(link.Result.IPs).To(HaveLen(1))
+		Expect(*link.Result.IPs[0].Interface).To(Equal(1))
+
+		idx, intf := link.Interface("eth0", "/var/run/netns/test")
+		Expect(idx).To(Equal(1))
+		Expect(intf.Name).To(Equal("eth0"))
+		idx, _ = link.Interface("eth0", "")
+		Expect(idx).To(Equal(-1))
+	})
+
+	It("returns no link when there is no prevResult", func() {
+		link, err := chain.Load([]byte(`{"cniVersion": "1.0.0", "name": "mynet"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link).To(BeNil())
+	})
+
+	It("requires a prevResult", func() {
+		_, err := chain.Require([]byte(`{"cniVersion": "1.0.0", "name": "mynet"}`))
+		var e *types.Error
+		Expect(err).To(BeAssignableToTypeOf(e))
+		Expect(err.(*types.Error).Code).To(Equal(uint(types.ErrInvalidNetworkConfig)))
+	})
+
+	It("fails on a malformed configuration", func() {
+		_, err := chain.Load([]byte(`{"cniVersion": `))
+		Expect(err).To(BeAssignableToTypeOf(&types.Error{}))
+		Expect(err.(*types.Error).Code).To(Equal(uint(types.ErrDecodingFailure)))
+	})
+
+	It("accepts configurations whose prevResult was already parsed", func() {
+		conf := &types.NetConf{}
+		Expect(json.Unmarshal([]byte(prev040), conf)).To(Succeed())
+		raw := conf.RawPrevResult
+
+		link, err := chain.RequireFromNetConf(conf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Result.Interfaces).To(HaveLen(2))
+		// the caller's configuration is left untouched
+		Expect(conf.RawPrevResult).To(Equal(raw))
+		Expect(conf.PrevResult).To(BeNil())
+
+		conf.PrevResult = link.Result
+		link, err = chain.FromNetConf(conf)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(link.Result.IPs).To(HaveLen(1))
+	})
+
+	It("adds interfaces and IPs and prints in the configuration version", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+
+		idx := link.AddInterface(&current.Interface{Name: "tap0", Sandbox: "/var/run/netns/test"})
+		Expect(idx).To(Equal(2))
+		_, addr, _ := net.ParseCIDR("10.1.3.4/24")
+		link.AddIP(&current.IPConfig{Address: *addr}, idx)
+
+		buf := &bytes.Buffer{}
+		Expect(link.PrintTo(buf)).To(Succeed())
+		out := map[string]interface{}{}
+		Expect(json.Unmarshal(buf.Bytes(), &out)).To(Succeed())
+		Expect(out["cniVersion"]).To(Equal("0.4.0"))
+		ips := out["ips"].([]interface{})
+		Expect(ips).To(HaveLen(2))
+		// 0.4.0 results carry the IP version
+		Expect(ips[1]).To(HaveKeyWithValue("version", "4"))
+		Expect(ips[1]).To(HaveKeyWithValue("interface", BeNumerically("==", 2)))
+	})
+
+	It("merges another result, renumbering its interfaces", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, addr, _ := net.ParseCIDR("fd00::5/64")
+		other := &current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			Interfaces: []*current.Interface{{Name: "net1", Sandbox: "/var/run/netns/test"}},
+			IPs: []*current.IPConfig{
+				{Interface: current.Int(0), Address: *addr},
+				{Address: *addr},
+			},
+			Routes: []*types.Route{{Dst: *addr}},
+			DNS:    types.DNS{Nameservers: []string{"10.1.0.10", "fd00::10"}, Domain: "example.com"},
+		}
+		Expect(link.Merge(other)).To(Succeed())
+
+		Expect(link.Result.Interfaces).To(HaveLen(3))
+		Expect(link.Result.IPs).To(HaveLen(3))
+		Expect(*link.Result.IPs[1].Interface).To(Equal(2))
+		Expect(link.Result.IPs[2].Interface).To(BeNil())
+		Expect(link.Result.Routes).To(HaveLen(1))
+		Expect(link.Result.DNS.Nameservers).To(Equal([]string{"10.1.0.10", "fd00::10"}))
+		Expect(link.Result.DNS.Domain).To(Equal("example.com"))
+		// the merged result is copied
+		Expect(*other.IPs[0].Interface).To(Equal(0))
+	})
+
+	It("rejects results whose IPs refer to missing interfaces", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, addr, _ := net.ParseCIDR("10.9.0.2/24")
+		other := &current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			IPs:        []*current.IPConfig{{Interface: current.Int(3), Address: *addr}},
+		}
+		Expect(link.Merge(other)).To(MatchError(ContainSubstring("missing interface 3")))
+		Expect(link.Result.IPs).To(HaveLen(1))
+	})
+
+	It("renumbers the interfaces of merged routes", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, dst, _ := net.ParseCIDR("10.9.0.0/16")
+		other := &current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			Interfaces: []*current.Interface{{Name: "net1"}, {Name: "net2"}},
+			Routes: []*types.Route{
+				{Dst: *dst, Interface: current.Int(1)},
+				{Dst: *dst},
+			},
+		}
+		Expect(link.Merge(other)).To(Succeed())
+
+		Expect(link.Result.Routes).To(HaveLen(2))
+		Expect(*link.Result.Routes[0].Interface).To(Equal(3))
+		Expect(link.Result.Routes[1].Interface).To(BeNil())
+		Expect(*other.Routes[0].Interface).To(Equal(1))
+	})
+
+	It("rejects results whose routes refer to missing interfaces", func() {
+		link, err := chain.Require([]byte(prev040))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, dst, _ := net.ParseCIDR("10.9.0.0/16")
+		other := &current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			Routes:     []*types.Route{{Dst: *dst, Interface: current.Int(0)}},
+		}
+		Expect(link.Merge(other)).To(MatchError("route 10.9.0.0/16 refers to missing interface 0"))
+		Expect(link.Result.Routes).To(BeEmpty())
+	})
+})
@@ -0,0 +1,387 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cnipb converts CNI results, network configurations and runtime
+// configurations to and from the protobuf messages defined in cni.proto.
+// This allows daemon-based runtimes and plugins to exchange CNI data over
+// gRPC without wrapping JSON inside their own messages.
+package cnipb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// MarshalResult encodes a result as a cni.v1.Result message. Results of
+// versions before 1.0.0 are converted to the current version first; 1.x
+// results keep their version.
+func MarshalResult(result types.Result) ([]byte, error) {
+	r, ok := result.(*current.Result)
+	if !ok {
+		var err error
+		if r, err = current.NewResultFromResult(result); err != nil {
+			return nil, err
+		}
+	}
+
+	e := &encoder{}
+	e.string(1, r.CNIVersion)
+	for _, intf := range r.Interfaces {
+		e.message(2, func(e *encoder) {
+			e.string(1, intf.Name)
+			e.string(2, intf.Mac)
+			e.scalarInt32(3, intf.Mtu)
+			e.string(4, intf.Sandbox)
+			e.string(5, intf.SocketPath)
+			e.string(6, intf.PciID)
+			e.optionalInt32(7, intf.PFIndex)
+			e.optionalInt32(8, intf.VFIndex)
+			e.string(9, intf.RDMADevice)
+			for _, key := range sortedKeys(intf.Annotations) {
+				e.message(10, func(e *encoder) {
+					e.string(1, key)
+					e.string(2, intf.Annotations[key])
+				})
+			}
+		})
+	}
+	for _, ip := range r.IPs {
+		e.message(3, func(e *encoder) {
+			e.optionalInt32(1, ip.Interface)
+			e.string(2, ip.Address.String())
+			if ip.Gateway != nil {
+				e.string(3, ip.Gateway.String())
+			}
+		})
+	}
+	for _, route := range r.Routes {
+		e.message(4, func(e *encoder) {
+			e.string(1, route.Dst.String())
+			if route.GW != nil {
+				e.string(2, route.GW.String())
+			}
+			e.scalarInt32(3, route.MTU)
+			e.scalarInt32(4, route.AdvMSS)
+			e.scalarInt32(5, route.Priority)
+			e.optionalInt32(6, route.Table)
+			e.optionalInt32(7, route.Scope)
+			e.optionalInt32(8, route.Interface)
+		})
+	}
+	if !r.DNS.IsEmpty() {
+		e.message(5, func(e *encoder) {
+			e.strings(1, r.DNS.Nameservers)
+			e.string(2, r.DNS.Domain)
+			e.strings(3, r.DNS.Search)
+			e.strings(4, r.DNS.Options)
+		})
+	}
+	for _, key := range sortedKeys(r.Extensions) {
+		e.message(6, func(e *encoder) {
+			e.string(1, key)
+			e.bytes(2, r.Extensions[key])
+		})
+	}
+	return e.buf, nil
+}
+
+// UnmarshalResult decodes a cni.v1.Result message
+func UnmarshalResult(data []byte) (*current.Result, error) {
+	r := &current.Result{}
+	err := decodeFields(data, func(field int, _ uint64, value []byte) error {
+		switch field {
+		case 1:
+			r.CNIVersion = string(value)
+		case 2:
+			intf, err := unmarshalInterface(value)
+			if err != nil {
+				return err
+			}
+			r.Interfaces = append(r.Interfaces, intf)
+		case 3:
+			ip, err := unmarshalIPConfig(value)
+			if err != nil {
+				return err
+			}
+			r.IPs = append(r.IPs, ip)
+		case 4:
+			route, err := unmarshalRoute(value)
+			if err != nil {
+				return err
+			}
+			r.Routes = append(r.Routes, route)
+		case 5:
+			return decodeFields(value, func(field int, _ uint64, value []byte) error {
+				switch field {
+				case 1:
+					r.DNS.Nameservers = append(r.DNS.Nameservers, string(value))
+				case 2:
+					r.DNS.Domain = string(value)
+				case 3:
+					r.DNS.Search = append(r.DNS.Search, string(value))
+				case 4:
+					r.DNS.Options = append(r.DNS.Options, string(value))
+				}
+				return nil
+			})
+		case 6:
+			var key string
+			var ext json.RawMessage
+			err := decodeFields(value, func(field int, _ uint64, value []byte) error {
+				switch field {
+				case 1:
+					key = string(value)
+				case 2:
+					ext = append(json.RawMessage{}, value...)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if r.Extensions == nil {
+				r.Extensions = make(map[string]json.RawMessage)
+			}
+			r.Extensions[key] = ext
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return r, nil
+}
+
+func unmarshalInterface(data []byte) (*current.Interface, error) {
+	intf := &current.Interface{}
+	err := decodeFields(data, func(field int, num uint64, value []byte) error {
+		switch field {
+		case 1:
+			intf.Name = string(value)
+		case 2:
+			intf.Mac = string(value)
+		case 3:
+			intf.Mtu = toInt(num)
+		case 4:
+			intf.Sandbox = string(value)
+		case 5:
+			intf.SocketPath = string(value)
+		case 6:
+			intf.PciID = string(value)
+		case 7:
+			intf.PFIndex = intPtr(num)
+		case 8:
+			intf.VFIndex = intPtr(num)
+		case 9:
+			intf.RDMADevice = string(value)
+		case 10:
+			var key, val string
+			err := decodeFields(value, func(field int, _ uint64, value []byte) error {
+				switch field {
+				case 1:
+					key = string(value)
+				case 2:
+					val = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if intf.Annotations == nil {
+				intf.Annotations = make(map[string]string)
+			}
+			intf.Annotations[key] = val
+		}
+		return nil
+	})
+	return intf, err
+}
+
+func unmarshalIPConfig(data []byte) (*current.IPConfig, error) {
+	ip := &current.IPConfig{}
+	err := decodeFields(data, func(field int, num uint64, value []byte) error {
+		switch field {
+		case 1:
+			ip.Interface = intPtr(num)
+		case 2:
+			ipn, err := types.ParseCIDR(string(value))
+			if err != nil {
+				return err
+			}
+			ip.Address = *ipn
+		case 3:
+			ip.Gateway = net.ParseIP(string(value))
+			if ip.Gateway == nil {
+				return fmt.Errorf("invalid gateway %q", string(value))
+			}
+		}
+		return nil
+	})
+	return ip, err
+}
+
+func unmarshalRoute(data []byte) (*types.Route, error) {
+	route := &types.Route{}
+	err := decodeFields(data, func(field int, num uint64, value []byte) error {
+		switch field {
+		case 1:
+			dst, err := types.ParseCIDR(string(value))
+			if err != nil {
+				return err
+			}
+			route.Dst = *dst
+		case 2:
+			route.GW = net.ParseIP(string(value))
+			if route.GW == nil {
+				return fmt.Errorf("invalid route gateway %q", string(value))
+			}
+		case 3:
+			route.MTU = toInt(num)
+		case 4:
+			route.AdvMSS = toInt(num)
+		case 5:
+			route.Priority = toInt(num)
+		case 6:
+			route.Table = intPtr(num)
+		case 7:
+			route.Scope = intPtr(num)
+		case 8:
+			route.Interface = intPtr(num)
+		}
+		return nil
+	})
+	return route, err
+}
+
+// MarshalNetworkConfig encodes a network configuration as a cni.v1.NetConf
+// message. The raw configuration bytes are carried unmodified.
+func MarshalNetworkConfig(conf *libcni.NetworkConfig) ([]byte, error) {
+	if conf == nil || conf.Network == nil {
+		return nil, fmt.Errorf("network configuration must not be nil")
+	}
+	e := &encoder{}
+	e.string(1, conf.Network.CNIVersion)
+	e.string(2, conf.Network.Name)
+	e.string(3, conf.Network.Type)
+	e.bytes(4, conf.Bytes)
+	return e.buf, nil
+}
+
+// UnmarshalNetworkConfig decodes a cni.v1.NetConf message
+func UnmarshalNetworkConfig(data []byte) (*libcni.NetworkConfig, error) {
+	var raw []byte
+	err := decodeFields(data, func(field int, _ uint64, value []byte) error {
+		if field == 4 {
+			raw = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode network configuration: %w", err)
+	}
+	return libcni.ConfFromBytes(raw)
+}
+
+// MarshalRuntimeConf encodes a runtime configuration as a cni.v1.RuntimeConf
+// message
+func MarshalRuntimeConf(rt *libcni.RuntimeConf) ([]byte, error) {
+	e := &encoder{}
+	e.string(1, rt.ContainerID)
+	e.string(2, rt.NetNS)
+	e.string(3, rt.IfName)
+	for _, arg := range rt.Args {
+		e.message(4, func(e *encoder) {
+			e.string(1, arg[0])
+			e.string(2, arg[1])
+		})
+	}
+	if len(rt.CapabilityArgs) > 0 {
+		capArgs, err := json.Marshal(rt.CapabilityArgs)
+		if err != nil {
+			return nil, err
+		}
+		e.bytes(5, capArgs)
+	}
+	e.strings(6, rt.CDIDevices)
+	for _, key := range sortedKeys(rt.Metadata) {
+		e.message(7, func(e *encoder) {
+			e.string(1, key)
+			e.string(2, rt.Metadata[key])
+		})
+	}
+	e.string(8, rt.CNIVersion)
+	return e.buf, nil
+}
+
+// UnmarshalRuntimeConf decodes a cni.v1.RuntimeConf message
+func UnmarshalRuntimeConf(data []byte) (*libcni.RuntimeConf, error) {
+	rt := &libcni.RuntimeConf{}
+	err := decodeFields(data, func(field int, _ uint64, value []byte) error {
+		switch field {
+		case 1:
+			rt.ContainerID = string(value)
+		case 2:
+			rt.NetNS = string(value)
+		case 3:
+			rt.IfName = string(value)
+		case 4:
+			var arg [2]string
+			err := decodeFields(value, func(field int, _ uint64, value []byte) error {
+				if field == 1 || field == 2 {
+					arg[field-1] = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			rt.Args = append(rt.Args, arg)
+		case 5:
+			return json.Unmarshal(value, &rt.CapabilityArgs)
+		case 6:
+			rt.CDIDevices = append(rt.CDIDevices, string(value))
+		case 7:
+			var key, val string
+			err := decodeFields(value, func(field int, _ uint64, value []byte) error {
+				switch field {
+				case 1:
+					key = string(value)
+				case 2:
+					val = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if rt.Metadata == nil {
+				rt.Metadata = make(map[string]string)
+			}
+			rt.Metadata[key] = val
+		case 8:
+			rt.CNIVersion = string(value)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode runtime configuration: %w", err)
+	}
+	return rt, nil
+}
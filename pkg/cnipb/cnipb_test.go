@@ -0,0 +1,147 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnipb_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/cnipb"
+	"github.com/containernetworking/cni/pkg/types/create"
+)
+
+var _ = Describe("Protobuf encoding", func() {
+	It("round-trips a result", func() {
+		result, err := create.CreateFromBytes([]byte(`{
+			"cniVersion": "1.1.0",
+			"interfaces": [
+				{"name": "eth0", "mac": "00:11:22:33:44:55", "mtu": 1500, "sandbox": "/var/run/netns/blue", "vfIndex": 0}
+			],
+			"ips": [
+				{"interface": 0, "address": "10.1.2.3/24", "gateway": "10.1.2.1"},
+				{"address": "abcd:1234::3/64"}
+			],
+			"routes": [
+				{"dst": "0.0.0.0/0", "gw": "10.1.2.1", "table": 100},
+				{"dst": "15.5.6.0/24", "priority": 5}
+			],
+			"dns": {"nameservers": ["1.1.1.1"], "options": ["ndots:5"]}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := cnipb.MarshalResult(result)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalResult(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(result))
+	})
+
+	It("round-trips result extensions", func() {
+		result, err := create.CreateFromBytes([]byte(`{
+			"cniVersion": "1.2.0",
+			"extensions": {"io.example.vendor": {"id": 5}}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := cnipb.MarshalResult(result)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalResult(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered.CNIVersion).To(Equal("1.2.0"))
+		Expect(recovered.Extensions).To(HaveKey("io.example.vendor"))
+		Expect(recovered.Extensions["io.example.vendor"]).To(MatchJSON(`{"id": 5}`))
+	})
+
+	It("round-trips interface annotations", func() {
+		result, err := create.CreateFromBytes([]byte(`{
+			"cniVersion": "1.2.0",
+			"interfaces": [
+				{"name": "eth0", "annotations": {"io.example.role": "primary", "io.example.empty": ""}},
+				{"name": "eth1"}
+			]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := cnipb.MarshalResult(result)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalResult(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(result))
+		Expect(recovered.Interfaces[0].Annotations).To(Equal(map[string]string{
+			"io.example.role":  "primary",
+			"io.example.empty": "",
+		}))
+	})
+
+	It("converts older results to the current version", func() {
+		result, err := create.Create("0.4.0", []byte(`{
+			"cniVersion": "0.4.0",
+			"ips": [{"version": "4", "address": "10.1.2.3/24"}]
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := cnipb.MarshalResult(result)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalResult(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered.CNIVersion).To(Equal("1.1.0"))
+		Expect(recovered.IPs).To(HaveLen(1))
+		Expect(recovered.IPs[0].Address.String()).To(Equal("10.1.2.3/24"))
+	})
+
+	It("returns an error for truncated messages", func() {
+		_, err := cnipb.UnmarshalResult([]byte{0x0a, 0x10, 'a'})
+		Expect(err).To(MatchError("failed to decode result: truncated message"))
+	})
+
+	It("round-trips a network configuration", func() {
+		conf, err := libcni.ConfFromBytes([]byte(`{"cniVersion": "1.0.0", "name": "mynet", "type": "bridge", "bridge": "cni0"}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		data, err := cnipb.MarshalNetworkConfig(conf)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalNetworkConfig(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(conf))
+	})
+
+	It("round-trips a runtime configuration", func() {
+		rt := &libcni.RuntimeConf{
+			ContainerID: "some-container-id",
+			NetNS:       "/var/run/netns/blue",
+			IfName:      "eth0",
+			Args:        [][2]string{{"K8S_POD_NAME", "web"}},
+			CapabilityArgs: map[string]interface{}{
+				"mac": "c2:11:22:33:44:55",
+			},
+			CDIDevices: []string{"intel.com/sriov=vf-3", "nvidia.com/gpu=0"},
+			Metadata:   map[string]string{"pod": "web", "tenant": "blue"},
+			CNIVersion: "1.0.0",
+		}
+
+		data, err := cnipb.MarshalRuntimeConf(rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		recovered, err := cnipb.UnmarshalRuntimeConf(data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(rt))
+	})
+})
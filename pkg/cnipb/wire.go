@@ -0,0 +1,151 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cnipb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Protobuf wire types used by the messages in cni.proto
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+var errTruncated = errors.New("truncated message")
+
+// encoder appends protobuf wire format fields to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) tag(field int, wireType int) {
+	e.buf = binary.AppendUvarint(e.buf, uint64(field)<<3|uint64(wireType))
+}
+
+// int32 always writes the field, which is how proto3 "optional" fields
+// and repeated scalars are encoded
+func (e *encoder) int32(field int, v int) {
+	e.tag(field, wireVarint)
+	// negative values are sign extended to 64 bits, as protoc does
+	e.buf = binary.AppendUvarint(e.buf, uint64(int64(int32(v))))
+}
+
+func (e *encoder) optionalInt32(field int, v *int) {
+	if v != nil {
+		e.int32(field, *v)
+	}
+}
+
+func (e *encoder) scalarInt32(field int, v int) {
+	if v != 0 {
+		e.int32(field, v)
+	}
+}
+
+func (e *encoder) bytes(field int, b []byte) {
+	e.tag(field, wireBytes)
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) string(field int, s string) {
+	if s != "" {
+		e.bytes(field, []byte(s))
+	}
+}
+
+func (e *encoder) strings(field int, ss []string) {
+	for _, s := range ss {
+		e.bytes(field, []byte(s))
+	}
+}
+
+func (e *encoder) message(field int, fn func(*encoder)) {
+	sub := &encoder{}
+	fn(sub)
+	e.bytes(field, sub.buf)
+}
+
+// decodeFields calls fn for every field in a message. For varint fields
+// value is nil and num holds the value; for length-delimited fields value
+// holds the payload.
+func decodeFields(data []byte, fn func(field int, num uint64, value []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+		data = data[n:]
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+			if err := fn(field, v, nil); err != nil {
+				return err
+			}
+		case wireBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return errTruncated
+			}
+			value := data[n : n+int(l)]
+			data = data[n+int(l):]
+			if err := fn(field, 0, value); err != nil {
+				return err
+			}
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errTruncated
+			}
+			data = data[8:]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errTruncated
+			}
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d for field %d", key&7, field)
+		}
+	}
+	return nil
+}
+
+func toInt(num uint64) int {
+	return int(int32(num))
+}
+
+func intPtr(num uint64) *int {
+	v := toInt(num)
+	return &v
+}
+
+// sortedKeys returns the keys of m in order, so that map fields are
+// encoded deterministically
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/create"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+const (
+	CaseVersion     = "VERSION output"
+	CaseErrorShape  = "error JSON shape"
+	CaseCheckGating = "CHECK rejected before 0.4.0"
+	CaseDelFirst    = "DEL before ADD"
+	CaseAdd         = "ADD"
+	CaseCheck       = "CHECK after ADD"
+	CaseDel         = "DEL after ADD"
+	CaseDelAgain    = "repeated DEL"
+	CaseGCEmpty     = "GC with no valid attachments"
+)
+
+// versionOutput checks that VERSION prints the versions the plugin
+// supports, and that each is a valid version
+func (r *runner) versionOutput(ctx context.Context) {
+	stdout, err := r.exec(ctx, "VERSION", []byte(fmt.Sprintf(`{"cniVersion":%q}`, version.Current())))
+	if err != nil {
+		r.fail(CaseVersion, err)
+		return
+	}
+	info, err := (&version.PluginDecoder{}).Decode(stdout)
+	if err != nil {
+		r.fail(CaseVersion, err)
+		return
+	}
+
+	r.report.SupportedVersions = info.SupportedVersions()
+	known := map[string]bool{}
+	for _, v := range version.All.SupportedVersions() {
+		known[v] = true
+	}
+	for _, v := range r.report.SupportedVersions {
+		if _, err := version.ParseSemver(v); err != nil {
+			r.fail(CaseVersion, fmt.Errorf("supported version %q: %w", v, err))
+			return
+		}
+		if known[v] {
+			r.versions = append(r.versions, v)
+		}
+	}
+	sort.Slice(r.versions, func(i, j int) bool {
+		vi, _ := version.ParseSemver(r.versions[i])
+		vj, _ := version.ParseSemver(r.versions[j])
+		return vi.Compare(vj) < 0
+	})
+	if len(r.versions) == 0 {
+		r.fail(CaseVersion, fmt.Errorf("plugin supports none of %v", version.All.SupportedVersions()))
+		return
+	}
+	r.pass(CaseVersion)
+}
+
+// errorShape checks that a plugin given a malformed configuration fails
+// with a well-formed error
+func (r *runner) errorShape(ctx context.Context) {
+	_, err := r.exec(ctx, "ADD", []byte(`{"cniVersion":`))
+	switch {
+	case err == nil:
+		r.fail(CaseErrorShape, errors.New("ADD with a malformed configuration succeeded"))
+	case isPluginError(err):
+		r.pass(CaseErrorShape)
+	default:
+		r.fail(CaseErrorShape, err)
+	}
+}
+
+// checkGating checks that CHECK fails with an incompatible version error
+// for every supported version that predates CHECK
+func (r *runner) checkGating(ctx context.Context) {
+	if r.cfg.NetNS == "" {
+		r.skip(CaseCheckGating, "no NetNS configured")
+		return
+	}
+
+	tested := 0
+	for _, v := range r.versions {
+		if supported, _ := version.Supports(version.FeatureCheck, v); supported {
+			continue
+		}
+		tested++
+		_, err := r.exec(ctx, "CHECK", r.conf(v, nil))
+		var cniErr *types.Error
+		switch {
+		case err == nil:
+			r.fail(CaseCheckGating, fmt.Errorf("CHECK with version %s succeeded", v))
+			return
+		case !errors.As(err, &cniErr):
+			r.fail(CaseCheckGating, err)
+			return
+		case cniErr.Code != types.ErrIncompatibleCNIVersion:
+			r.fail(CaseCheckGating, fmt.Errorf("CHECK with version %s failed with code %d, not %d: %v", v, cniErr.Code, types.ErrIncompatibleCNIVersion, cniErr))
+			return
+		}
+	}
+	if tested == 0 {
+		r.skip(CaseCheckGating, "plugin supports no version before 0.4.0")
+		return
+	}
+	r.pass(CaseCheckGating)
+}
+
+// lifecycle checks, at the latest supported version, that DEL of an
+// attachment that does not exist succeeds, that ADD returns a result of
+// the configuration's version, that CHECK accepts it and that DEL may be
+// repeated
+func (r *runner) lifecycle(ctx context.Context) {
+	cases := []string{CaseDelFirst, CaseAdd, CaseCheck, CaseDel, CaseDelAgain}
+	if r.cfg.NetNS == "" || len(r.versions) == 0 {
+		reason := "no NetNS configured"
+		if len(r.versions) == 0 {
+			reason = "no supported version"
+		}
+		for _, name := range cases {
+			r.skip(name, reason)
+		}
+		return
+	}
+	v := r.versions[len(r.versions)-1]
+
+	r.expectSuccess(ctx, CaseDelFirst, "DEL", r.conf(v, nil))
+
+	var prevResult map[string]interface{}
+	stdout, err := r.exec(ctx, "ADD", r.conf(v, nil))
+	if err == nil {
+		prevResult, err = decodeResult(v, stdout)
+	}
+	if err != nil {
+		r.fail(CaseAdd, err)
+	} else {
+		r.pass(CaseAdd)
+	}
+
+	withPrev := map[string]interface{}{}
+	if prevResult != nil {
+		withPrev["prevResult"] = prevResult
+	}
+	switch supported, _ := version.Supports(version.FeatureCheck, v); {
+	case !supported:
+		r.skip(CaseCheck, fmt.Sprintf("CHECK is not defined for version %s", v))
+	case prevResult == nil:
+		r.skip(CaseCheck, "ADD failed")
+	default:
+		r.expectSuccess(ctx, CaseCheck, "CHECK", r.conf(v, withPrev))
+	}
+
+	if supported, _ := version.Supports(version.FeatureDelPrevResult, v); !supported {
+		withPrev = nil
+	}
+	r.expectSuccess(ctx, CaseDel, "DEL", r.conf(v, withPrev))
+	r.expectSuccess(ctx, CaseDelAgain, "DEL", r.conf(v, withPrev))
+}
+
+// gcEmpty checks that GC succeeds when no attachment is valid
+func (r *runner) gcEmpty(ctx context.Context) {
+	if len(r.versions) == 0 {
+		r.skip(CaseGCEmpty, "no supported version")
+		return
+	}
+	v := r.versions[len(r.versions)-1]
+	if supported, _ := version.Supports(version.FeatureGC, v); !supported {
+		r.skip(CaseGCEmpty, fmt.Sprintf("GC is not defined for version %s", v))
+		return
+	}
+	r.expectSuccess(ctx, CaseGCEmpty, "GC", r.conf(v, map[string]interface{}{
+		types.ValidAttachmentsKey: []types.GCAttachment{},
+	}))
+}
+
+func (r *runner) expectSuccess(ctx context.Context, name, command string, stdin []byte) {
+	if _, err := r.exec(ctx, command, stdin); err != nil {
+		r.fail(name, err)
+		return
+	}
+	r.pass(name)
+}
+
+// decodeResult checks that stdout is a result of the given version and
+// returns it as a generic map, to be passed on as prevResult
+func decodeResult(cniVersion string, stdout []byte) (map[string]interface{}, error) {
+	result, err := create.CreateFromBytes(stdout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result %q: %w", stdout, err)
+	}
+	if result.Version() != cniVersion {
+		return nil, fmt.Errorf("result version %q does not match configuration version %q", result.Version(), cniVersion)
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(stdout, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isPluginError(err error) bool {
+	var cniErr *types.Error
+	return errors.As(err, &cniErr)
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package conformance runs a plugin binary through the behaviors the CNI
+// specification requires of every plugin, so plugin authors can check
+// their plugin in CI:
+//
+//	report, err := conformance.Run(ctx, conformance.Config{
+//		PluginPath: "./bin/myplugin",
+//		NetConf:    []byte(`{"type": "myplugin"}`),
+//		NetNS:      "/var/run/netns/conformance",
+//	})
+//	if err != nil || !report.Passed() {
+//		...
+//	}
+//
+// The GC case tells the plugin that no attachment of the network is valid,
+// so the plugin must be configured with a network dedicated to testing.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Outcome is the outcome of a single case
+type Outcome string
+
+const (
+	Pass Outcome = "pass"
+	Fail Outcome = "fail"
+	// Skip means the case does not apply to the plugin, or could not run
+	// because an earlier case failed
+	Skip Outcome = "skip"
+)
+
+// Config describes the plugin under test and how to run it
+type Config struct {
+	// PluginPath is the path of the plugin binary
+	PluginPath string
+	// NetConf is the plugin's network configuration. Its cniVersion is set
+	// by each case, and its name defaults to "conformance-test".
+	NetConf []byte
+	// NetNS is the network namespace ADD, CHECK and DEL run against. The
+	// cases that need one are skipped if it is empty.
+	NetNS string
+	// ContainerID defaults to "conformance-test"
+	ContainerID string
+	// IfName defaults to "eth0"
+	IfName string
+	// CNIPath is passed to the plugin as CNI_PATH, for plugins that
+	// delegate, e.g. to an IPAM plugin. It defaults to the directory of
+	// PluginPath.
+	CNIPath []string
+}
+
+// CaseResult is the outcome of a single case
+type CaseResult struct {
+	Name    string  `json:"name"`
+	Outcome Outcome `json:"outcome"`
+	// Message explains a failure or skip
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the outcome of every case run against a plugin
+type Report struct {
+	Plugin string `json:"plugin"`
+	// SupportedVersions are the versions the plugin reported by VERSION
+	SupportedVersions []string     `json:"supportedVersions,omitempty"`
+	Results           []CaseResult `json:"results"`
+}
+
+// Passed is whether no case failed
+func (r *Report) Passed() bool {
+	for _, res := range r.Results {
+		if res.Outcome == Fail {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes one line per case followed by a summary
+func (r *Report) Print(w io.Writer) error {
+	counts := map[Outcome]int{}
+	for _, res := range r.Results {
+		counts[res.Outcome]++
+		line := fmt.Sprintf("%-4s %s", res.Outcome, res.Name)
+		if res.Message != "" {
+			line += ": " + res.Message
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s: %d passed, %d failed, %d skipped\n", r.Plugin, counts[Pass], counts[Fail], counts[Skip])
+	return err
+}
+
+// Run runs every case against the plugin. It only returns an error if the
+// plugin cannot be run at all; failures of the plugin are in the report.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if _, err := os.Stat(cfg.PluginPath); err != nil {
+		return nil, fmt.Errorf("plugin not found: %w", err)
+	}
+	if cfg.ContainerID == "" {
+		cfg.ContainerID = "conformance-test"
+	}
+	if cfg.IfName == "" {
+		cfg.IfName = "eth0"
+	}
+	if len(cfg.CNIPath) == 0 {
+		cfg.CNIPath = []string{filepath.Dir(cfg.PluginPath)}
+	}
+
+	netConf := map[string]interface{}{}
+	if err := json.Unmarshal(cfg.NetConf, &netConf); err != nil {
+		return nil, fmt.Errorf("invalid network configuration: %w", err)
+	}
+	if _, ok := netConf["name"]; !ok {
+		netConf["name"] = "conformance-test"
+	}
+
+	r := &runner{
+		cfg:     cfg,
+		netConf: netConf,
+		report:  &Report{Plugin: filepath.Base(cfg.PluginPath)},
+	}
+	r.versionOutput(ctx)
+	r.errorShape(ctx)
+	r.checkGating(ctx)
+	r.lifecycle(ctx)
+	r.gcEmpty(ctx)
+	return r.report, nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/conformance"
+	noop_debug "github.com/containernetworking/cni/plugins/test/noop/debug"
+)
+
+func outcomes(report *conformance.Report) map[string]conformance.Outcome {
+	m := map[string]conformance.Outcome{}
+	for _, res := range report.Results {
+		m[res.Name] = res.Outcome
+	}
+	return m
+}
+
+var _ = Describe("Run", func() {
+	var (
+		debugFilePath string
+		debug         *noop_debug.Debug
+		cfg           conformance.Config
+	)
+
+	BeforeEach(func() {
+		debugFile, err := os.CreateTemp("", "cni_debug")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debugFile.Close()).To(Succeed())
+		debugFilePath = debugFile.Name()
+
+		debug = &noop_debug.Debug{
+			ReportResult: `{"cniVersion": "1.1.0", "ips": [{"address": "10.1.2.3/24"}]}`,
+		}
+		Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+
+		cfg = conformance.Config{
+			PluginPath: noopPath,
+			NetConf:    []byte(fmt.Sprintf(`{"type": "noop", "debugFile": %q}`, debugFilePath)),
+			NetNS:      "/some/netns/path",
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(debugFilePath)).To(Succeed())
+	})
+
+	It("passes a conforming plugin", func() {
+		report, err := conformance.Run(context.TODO(), cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Plugin).To(Equal("noop"))
+		Expect(report.SupportedVersions).To(ContainElement("1.1.0"))
+		for _, res := range report.Results {
+			Expect(res.Outcome).To(Equal(conformance.Pass), res.Name+": "+res.Message)
+		}
+		Expect(report.Passed()).To(BeTrue())
+
+		debug, err := noop_debug.ReadDebug(debugFilePath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debug.Command).To(Equal("GC"))
+		Expect(string(debug.CmdArgs.StdinData)).To(ContainSubstring(`"cni.dev/valid-attachments":[]`))
+	})
+
+	It("prints a line per case and a summary", func() {
+		report, err := conformance.Run(context.TODO(), cfg)
+		Expect(err).NotTo(HaveOccurred())
+
+		var buf bytes.Buffer
+		Expect(report.Print(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("pass VERSION output\n"))
+		Expect(buf.String()).To(HaveSuffix(fmt.Sprintf("noop: %d passed, 0 failed, 0 skipped\n", len(report.Results))))
+	})
+
+	Context("when ADD returns a result of another version", func() {
+		BeforeEach(func() {
+			debug.ReportResult = `{"cniVersion": "0.4.0", "ips": [{"version": "4", "address": "10.1.2.3/24"}]}`
+			Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+		})
+
+		It("fails ADD and skips CHECK", func() {
+			report, err := conformance.Run(context.TODO(), cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Passed()).To(BeFalse())
+			Expect(outcomes(report)).To(HaveKeyWithValue(conformance.CaseAdd, conformance.Fail))
+			Expect(outcomes(report)).To(HaveKeyWithValue(conformance.CaseCheck, conformance.Skip))
+			Expect(outcomes(report)).To(HaveKeyWithValue(conformance.CaseDel, conformance.Pass))
+		})
+	})
+
+	Context("when the plugin fails every command", func() {
+		BeforeEach(func() {
+			debug.ReportError = "banana"
+			Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+		})
+
+		It("reports the plugin's errors", func() {
+			report, err := conformance.Run(context.TODO(), cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Passed()).To(BeFalse())
+			for _, res := range report.Results {
+				if res.Name == conformance.CaseDelFirst {
+					Expect(res.Outcome).To(Equal(conformance.Fail))
+					Expect(res.Message).To(Equal("banana"))
+				}
+			}
+		})
+	})
+
+	Context("when the plugin prints a malformed error", func() {
+		BeforeEach(func() {
+			debug.ReportResult = "not json"
+			debug.ExitWithCode = 1
+			Expect(debug.WriteDebug(debugFilePath)).To(Succeed())
+		})
+
+		It("fails the command", func() {
+			report, err := conformance.Run(context.TODO(), cfg)
+			Expect(err).NotTo(HaveOccurred())
+			for _, res := range report.Results {
+				if res.Name == conformance.CaseDelFirst {
+					Expect(res.Outcome).To(Equal(conformance.Fail))
+					Expect(res.Message).To(ContainSubstring("without a valid error on stdout"))
+				}
+			}
+		})
+	})
+
+	Context("when no namespace is configured", func() {
+		BeforeEach(func() {
+			cfg.NetNS = ""
+		})
+
+		It("skips the cases that need one", func() {
+			report, err := conformance.Run(context.TODO(), cfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(report.Passed()).To(BeTrue())
+			Expect(outcomes(report)).To(Equal(map[string]conformance.Outcome{
+				conformance.CaseVersion:     conformance.Pass,
+				conformance.CaseErrorShape:  conformance.Pass,
+				conformance.CaseCheckGating: conformance.Skip,
+				conformance.CaseDelFirst:    conformance.Skip,
+				conformance.CaseAdd:         conformance.Skip,
+				conformance.CaseCheck:       conformance.Skip,
+				conformance.CaseDel:         conformance.Skip,
+				conformance.CaseDelAgain:    conformance.Skip,
+				conformance.CaseGCEmpty:     conformance.Pass,
+			}))
+		})
+	})
+
+	It("returns an error if the plugin does not exist", func() {
+		cfg.PluginPath = "/does/not/exist"
+		_, err := conformance.Run(context.TODO(), cfg)
+		Expect(err).To(MatchError(ContainSubstring("plugin not found")))
+	})
+
+	It("returns an error if the configuration is not JSON", func() {
+		cfg.NetConf = []byte("{")
+		_, err := conformance.Run(context.TODO(), cfg)
+		Expect(err).To(MatchError(ContainSubstring("invalid network configuration")))
+	})
+})
@@ -0,0 +1,132 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+type runner struct {
+	cfg     Config
+	netConf map[string]interface{}
+	report  *Report
+	// versions are the versions the plugin supports that this library
+	// also knows, in ascending order
+	versions []string
+}
+
+func (r *runner) record(name string, outcome Outcome, message string) {
+	r.report.Results = append(r.report.Results, CaseResult{Name: name, Outcome: outcome, Message: message})
+}
+
+func (r *runner) pass(name string) {
+	r.record(name, Pass, "")
+}
+
+func (r *runner) fail(name string, err error) {
+	r.record(name, Fail, err.Error())
+}
+
+func (r *runner) skip(name, reason string) {
+	r.record(name, Skip, reason)
+}
+
+// conf returns the network configuration for cniVersion, with the given
+// keys added
+func (r *runner) conf(cniVersion string, extra map[string]interface{}) []byte {
+	conf := make(map[string]interface{}, len(r.netConf)+len(extra)+1)
+	for k, v := range r.netConf {
+		conf[k] = v
+	}
+	for k, v := range extra {
+		conf[k] = v
+	}
+	conf["cniVersion"] = cniVersion
+	// The map only holds values decoded from JSON, so this cannot fail
+	data, _ := json.Marshal(conf)
+	return data
+}
+
+// errBadErrorJSON means the plugin failed without printing a well-formed
+// error to stdout
+var errBadErrorJSON = errors.New("plugin failed without a valid error on stdout")
+
+// exec runs the plugin and returns its stdout. If the plugin fails, the
+// error is the *types.Error it printed or, if that is not a valid CNI
+// error, wraps errBadErrorJSON.
+func (r *runner) exec(ctx context.Context, command string, stdin []byte) ([]byte, error) {
+	env := []string{}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "CNI_") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		"CNI_COMMAND="+command,
+		"CNI_CONTAINERID="+r.cfg.ContainerID,
+		"CNI_NETNS="+r.cfg.NetNS,
+		"CNI_IFNAME="+r.cfg.IfName,
+		"CNI_PATH="+strings.Join(r.cfg.CNIPath, string(os.PathListSeparator)),
+	)
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	c := exec.CommandContext(ctx, r.cfg.PluginPath)
+	c.Env = env
+	c.Stdin = bytes.NewReader(stdin)
+	c.Stdout = stdout
+	c.Stderr = stderr
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		if perr := parseError(stdout.Bytes()); perr != nil {
+			return nil, fmt.Errorf("%w: %v (stderr %q)", errBadErrorJSON, perr, stderr.String())
+		}
+		emsg := &types.Error{}
+		_ = json.Unmarshal(stdout.Bytes(), emsg)
+		return nil, emsg
+	}
+	return stdout.Bytes(), nil
+}
+
+// parseError checks that stdout holds an error that runtimes can decode:
+// a JSON object with a nonzero code and a message
+func parseError(stdout []byte) error {
+	var e struct {
+		Code *uint  `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(stdout, &e); err != nil {
+		return fmt.Errorf("invalid error JSON %q: %v", stdout, err)
+	}
+	switch {
+	case e.Code == nil || *e.Code == 0:
+		return fmt.Errorf("error %q has no code", stdout)
+	case e.Msg == "":
+		return fmt.Errorf("error %q has no msg", stdout)
+	}
+	return nil
+}
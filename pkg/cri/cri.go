@@ -0,0 +1,170 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cri converts the outcomes of libcni operations into the network
+// status shapes of the CRI (Container Runtime Interface) API, so that CRI
+// implementations can report pod IPs and network readiness to kubelet
+// straight from this library.
+//
+// The types mirror those of k8s.io/cri-api/pkg/apis/runtime/v1 field by
+// field, so no dependency is forced on users of this library:
+//
+//	status, err := cri.PodNetworkStatus(result, "eth0")
+//	...
+//	resp.Status.Network = &runtime.PodSandboxNetworkStatus{Ip: status.Ip}
+//	for _, ip := range status.AdditionalIps {
+//		resp.Status.Network.AdditionalIps = append(resp.Status.Network.AdditionalIps, &runtime.PodIP{Ip: ip.Ip})
+//	}
+package cri
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// NetworkReady is the type of the runtime condition kubelet checks before
+// starting pods which are not in the host's network namespace
+const NetworkReady = "NetworkReady"
+
+// ReasonNetworkPluginNotReady is the reason of a false NetworkReady
+// condition, as reported by CRI implementations
+const ReasonNetworkPluginNotReady = "NetworkPluginNotReady"
+
+// PodIP is an address of a pod
+type PodIP struct {
+	Ip string //nolint:revive,stylecheck // named as in the CRI API
+}
+
+// PodSandboxNetworkStatus is the network status of a pod
+type PodSandboxNetworkStatus struct {
+	// Ip is the primary address of the pod
+	Ip string //nolint:revive,stylecheck // named as in the CRI API
+	// AdditionalIps are its other addresses, such as the IPv6 address of a
+	// dual-stack pod
+	AdditionalIps []*PodIP
+}
+
+// RuntimeCondition is a condition of the runtime, such as NetworkReady
+type RuntimeCondition struct {
+	Type    string
+	Status  bool
+	Reason  string
+	Message string
+}
+
+// PodNetworkStatus returns the network status of a pod from the result of
+// attaching it, such as that of AddNetworkList or the combined result of
+// AddNetworks. Only the addresses of the interface named ifName in the
+// pod's network namespace are reported, or those of every interface in it
+// if ifName is empty; addresses of results without interfaces, as from
+// plugins of CNI versions before 0.3.0, are always reported. As in CRI
+// implementations, the first IPv4 address is the primary one, if any.
+func PodNetworkStatus(result types.Result, ifName string) (*PodSandboxNetworkStatus, error) {
+	res, err := current.GetResult(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert result: %w", err)
+	}
+
+	var ipv4, ipv6 []string
+	seen := make(map[string]bool)
+	for _, ipc := range res.IPs {
+		if ipc.Interface != nil {
+			idx := *ipc.Interface
+			if idx < 0 || idx >= len(res.Interfaces) {
+				continue
+			}
+			intf := res.Interfaces[idx]
+			if intf.Sandbox == "" || (ifName != "" && intf.Name != ifName) {
+				continue
+			}
+		}
+		ip := ipc.Address.IP.String()
+		if seen[ip] {
+			continue
+		}
+		seen[ip] = true
+		if ipc.Address.IP.To4() != nil {
+			ipv4 = append(ipv4, ip)
+		} else {
+			ipv6 = append(ipv6, ip)
+		}
+	}
+
+	ips := append(ipv4, ipv6...)
+	if len(ips) == 0 {
+		if ifName != "" {
+			return nil, fmt.Errorf("result has no address for interface %q", ifName)
+		}
+		return nil, errors.New("result has no address in the pod's network namespace")
+	}
+	status := &PodSandboxNetworkStatus{Ip: ips[0]}
+	for _, ip := range ips[1:] {
+		status.AdditionalIps = append(status.AdditionalIps, &PodIP{Ip: ip})
+	}
+	return status, nil
+}
+
+// PodIPs returns the addresses of a network status, primary first, parsed
+func (s *PodSandboxNetworkStatus) PodIPs() []net.IP {
+	ips := []net.IP{net.ParseIP(s.Ip)}
+	for _, ip := range s.AdditionalIps {
+		ips = append(ips, net.ParseIP(ip.Ip))
+	}
+	return ips
+}
+
+// NetworkReadyCondition returns the NetworkReady condition of a runtime
+// attaching pods to the given networks: true if there are networks and
+// the STATUS of each succeeds, as it does for configurations of CNI
+// versions before 1.1.0, which have no STATUS.
+func NetworkReadyCondition(ctx context.Context, cni libcni.CNI, networks ...*libcni.NetworkConfigList) *RuntimeCondition {
+	if len(networks) == 0 {
+		return notReady("no network config found")
+	}
+	for _, network := range networks {
+		if err := cni.GetStatusNetworkList(ctx, network); err != nil {
+			return notReady(statusMessage(network.Name, err))
+		}
+	}
+	return &RuntimeCondition{Type: NetworkReady, Status: true}
+}
+
+func notReady(message string) *RuntimeCondition {
+	return &RuntimeCondition{
+		Type:    NetworkReady,
+		Status:  false,
+		Reason:  ReasonNetworkPluginNotReady,
+		Message: "Network plugin returns error: " + message,
+	}
+}
+
+// statusMessage describes the failed STATUS of a network
+func statusMessage(network string, err error) string {
+	var cniErr *types.Error
+	if errors.As(err, &cniErr) {
+		switch cniErr.Code {
+		case types.ErrPluginNotAvailable:
+			return fmt.Sprintf("network %q is not available: %v", network, cniErr)
+		case types.ErrLimitedConnectivity:
+			return fmt.Sprintf("network %q cannot attach new pods: %v", network, cniErr)
+		}
+	}
+	return fmt.Sprintf("network %q failed STATUS: %v", network, err)
+}
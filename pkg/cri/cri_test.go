@@ -0,0 +1,192 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cri_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/cri"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+	types040 "github.com/containernetworking/cni/pkg/types/040"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+func mustParseCIDR(s string) net.IPNet {
+	ip, ipNet, err := net.ParseCIDR(s)
+	Expect(err).NotTo(HaveOccurred())
+	ipNet.IP = ip
+	return *ipNet
+}
+
+var _ = Describe("PodNetworkStatus", func() {
+	var result *current.Result
+
+	BeforeEach(func() {
+		result = &current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			Interfaces: []*current.Interface{
+				{Name: "cni0"},
+				{Name: "eth0", Sandbox: "/var/run/netns/pod"},
+				{Name: "net1", Sandbox: "/var/run/netns/pod"},
+			},
+			IPs: []*current.IPConfig{
+				{Address: mustParseCIDR("10.0.0.1/24"), Interface: current.Int(0)},
+				{Address: mustParseCIDR("fd00::2/64"), Interface: current.Int(1)},
+				{Address: mustParseCIDR("10.0.0.2/24"), Interface: current.Int(1)},
+				{Address: mustParseCIDR("10.1.0.2/24"), Interface: current.Int(2)},
+			},
+		}
+	})
+
+	It("reports the addresses of the named interface, IPv4 first", func() {
+		status, err := cri.PodNetworkStatus(result, "eth0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(&cri.PodSandboxNetworkStatus{
+			Ip:            "10.0.0.2",
+			AdditionalIps: []*cri.PodIP{{Ip: "fd00::2"}},
+		}))
+		Expect(status.PodIPs()).To(Equal([]net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("fd00::2")}))
+	})
+
+	It("reports the addresses of every sandbox interface without a name", func() {
+		status, err := cri.PodNetworkStatus(result, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(&cri.PodSandboxNetworkStatus{
+			Ip:            "10.0.0.2",
+			AdditionalIps: []*cri.PodIP{{Ip: "10.1.0.2"}, {Ip: "fd00::2"}},
+		}))
+	})
+
+	It("reports IPv6 addresses of single-stack pods", func() {
+		status, err := cri.PodNetworkStatus(&current.Result{
+			CNIVersion: current.ImplementedSpecVersion,
+			Interfaces: []*current.Interface{{Name: "eth0", Sandbox: "/var/run/netns/pod"}},
+			IPs: []*current.IPConfig{
+				{Address: mustParseCIDR("fd00::2/64"), Interface: current.Int(0)},
+				{Address: mustParseCIDR("fd00::3/64"), Interface: current.Int(0)},
+			},
+		}, "eth0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Ip).To(Equal("fd00::2"))
+		Expect(status.AdditionalIps).To(Equal([]*cri.PodIP{{Ip: "fd00::3"}}))
+	})
+
+	It("reports addresses without interfaces and of older results", func() {
+		status, err := cri.PodNetworkStatus(&types040.Result{
+			CNIVersion: "0.4.0",
+			IPs:        []*types040.IPConfig{{Address: mustParseCIDR("10.0.0.2/24")}},
+		}, "eth0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(&cri.PodSandboxNetworkStatus{Ip: "10.0.0.2"}))
+	})
+
+	It("skips duplicate addresses and invalid interface indices", func() {
+		result.IPs = append(result.IPs,
+			&current.IPConfig{Address: mustParseCIDR("10.0.0.2/16"), Interface: current.Int(1)},
+			&current.IPConfig{Address: mustParseCIDR("10.9.9.9/16"), Interface: current.Int(7)},
+		)
+		status, err := cri.PodNetworkStatus(result, "eth0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.AdditionalIps).To(HaveLen(1))
+	})
+
+	It("fails without addresses", func() {
+		_, err := cri.PodNetworkStatus(result, "eth1")
+		Expect(err).To(MatchError(`result has no address for interface "eth1"`))
+
+		result.IPs = result.IPs[:1]
+		_, err = cri.PodNetworkStatus(result, "")
+		Expect(err).To(MatchError("result has no address in the pod's network namespace"))
+	})
+})
+
+var _ = Describe("NetworkReadyCondition", func() {
+	var (
+		ctx       context.Context
+		pluginDir string
+		cniConfig *libcni.CNIConfig
+	)
+
+	network := func(name, cniVersion, pluginType string) *libcni.NetworkConfigList {
+		list, err := libcni.ConfListFromBytes([]byte(fmt.Sprintf(`{
+			"cniVersion": %q,
+			"name": %q,
+			"plugins": [{"type": %q}]
+		}`, cniVersion, name, pluginType)))
+		Expect(err).NotTo(HaveOccurred())
+		return list
+	}
+
+	// withStatus returns a fake plugin answering STATUS with status
+	withStatus := func(name string, status plugintest.Response) plugintest.Plugin {
+		return plugintest.Plugin{
+			Name:      name,
+			Responses: map[string]plugintest.Response{"STATUS": status},
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pluginDir = GinkgoT().TempDir()
+		cniConfig = libcni.NewCNIConfig([]string{pluginDir}, nil)
+		_, err := plugintest.InstallAll(pluginDir,
+			withStatus("ready", plugintest.Response{}),
+			withStatus("unavailable", plugintest.Response{
+				ErrorCode: types.ErrPluginNotAvailable, ErrorMsg: "daemon down",
+			}),
+			withStatus("limited", plugintest.Response{
+				ErrorCode: types.ErrLimitedConnectivity, ErrorMsg: "no addresses left",
+			}),
+			withStatus("broken", plugintest.Response{ErrorCode: 999, ErrorMsg: "oops"}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("is true when every network is ready", func() {
+		cond := cri.NetworkReadyCondition(ctx, cniConfig,
+			network("a", "1.1.0", "ready"), network("b", "1.0.0", "unavailable"))
+		Expect(cond).To(Equal(&cri.RuntimeCondition{Type: cri.NetworkReady, Status: true}))
+	})
+
+	It("is false without networks", func() {
+		cond := cri.NetworkReadyCondition(ctx, cniConfig)
+		Expect(cond).To(Equal(&cri.RuntimeCondition{
+			Type:    cri.NetworkReady,
+			Reason:  cri.ReasonNetworkPluginNotReady,
+			Message: "Network plugin returns error: no network config found",
+		}))
+	})
+
+	DescribeTable("is false when a network's STATUS fails",
+		func(pluginType, message string) {
+			cond := cri.NetworkReadyCondition(ctx, cniConfig,
+				network("a", "1.1.0", "ready"), network("b", "1.1.0", pluginType))
+			Expect(cond.Type).To(Equal(cri.NetworkReady))
+			Expect(cond.Status).To(BeFalse())
+			Expect(cond.Reason).To(Equal(cri.ReasonNetworkPluginNotReady))
+			Expect(cond.Message).To(Equal("Network plugin returns error: " + message))
+		},
+		Entry("not available", "unavailable", `network "b" is not available: daemon down`),
+		Entry("limited connectivity", "limited", `network "b" cannot attach new pods: no addresses left`),
+		Entry("other errors", "broken", `network "b" failed STATUS: oops`),
+	)
+})
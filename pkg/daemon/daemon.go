@@ -0,0 +1,97 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package daemon runs CNI plugins as long-running processes, for plugins
+// which keep state between invocations, such as DHCP leases.
+//
+// The runtime executes a shim, which forwards the CNI_* environment and
+// stdin of the invocation to the daemon over a unix socket, and prints the
+// daemon's response as its own. The generic shim binary, cni-daemon-shim,
+// is installed in CNI_PATH under the plugin's name, or a plugin may call
+// ShimMain from its own main.
+//
+// The daemon hosts the plugin's skel.CNIFuncs in a Server. They run as
+// with skel.PluginMainFuncs, except that results must be printed with
+// skel.PrintResult.
+//
+// Plugins built with PluginMain can also run as workers of a Pool, an
+// invoke.Exec of the runtime which starts them ahead of invocations and
+// forwards those to them, such as the WorkerPool of a libcni.CNIConfig.
+package daemon
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// DefaultSocketDir holds the sockets of daemons which do not set their own
+const DefaultSocketDir = "/run/cni"
+
+// SocketKey is the network configuration key which overrides the socket
+// path the shim connects to
+const SocketKey = "daemonSocket"
+
+// SocketPath returns the default socket of the daemon of the named plugin
+func SocketPath(plugin string) string {
+	return filepath.Join(DefaultSocketDir, plugin+".sock")
+}
+
+// Request is an invocation forwarded by the shim to the daemon
+type Request struct {
+	// Env holds the CNI_* environment variables of the invocation
+	Env map[string]string `json:"env"`
+	// Stdin is the network configuration
+	Stdin []byte `json:"stdin,omitempty"`
+}
+
+// Response is the outcome of a forwarded invocation
+type Response struct {
+	Stdout []byte `json:"stdout,omitempty"`
+	Stderr []byte `json:"stderr,omitempty"`
+	// Error, if set, is printed by the shim, which then exits with 1
+	Error *types.Error `json:"error,omitempty"`
+}
+
+// cniEnv returns the CNI_* variables of environ
+func cniEnv(environ []string) map[string]string {
+	env := map[string]string{}
+	for _, kv := range environ {
+		if k, v, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(k, "CNI_") {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// socketFromConfig returns the socket path set in the network
+// configuration, if any
+func socketFromConfig(stdin []byte) string {
+	conf := map[string]interface{}{}
+	if err := json.Unmarshal(stdin, &conf); err != nil {
+		return ""
+	}
+	path, _ := conf[SocketKey].(string)
+	return path
+}
+
+// pluginName returns the name the process was executed as, without the
+// extension of Windows executables
+func pluginName() string {
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
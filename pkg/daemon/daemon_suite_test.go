@@ -0,0 +1,66 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/daemon"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// TestMain runs the test binary as the plugin of the Pool specs when the
+// Pool, or its fallback Exec, executes it
+func TestMain(m *testing.M) {
+	if os.Getenv(daemon.WorkerSocketEnv) != "" || os.Getenv("CNI_COMMAND") != "" {
+		daemon.PluginMain(skel.CNIFuncs{
+			Add: func(args *skel.CmdArgs) error {
+				conf := types.NetConf{}
+				if err := json.Unmarshal(args.StdinData, &conf); err != nil {
+					return err
+				}
+				// The interface is named after the process, which tells
+				// the invocations of a worker from those of a binary
+				result := &current.Result{
+					CNIVersion: conf.CNIVersion,
+					Interfaces: []*current.Interface{{Name: fmt.Sprint(os.Getpid()), Sandbox: args.Netns}},
+				}
+				return skel.PrintResult(args, result, conf.CNIVersion)
+			},
+			Del: func(args *skel.CmdArgs) error {
+				if args.ContainerID == "fail" {
+					return types.NewError(types.ErrTryAgainLater, "failing as asked", args.IfName)
+				}
+				return nil
+			},
+		}, version.All, "pool test plugin")
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestDaemon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Daemon Suite")
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/daemon"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+const netconf = `{"cniVersion": "1.0.0", "name": "mynet", "type": "mydaemon"}`
+
+func environ(command, containerID string) []string {
+	return []string{
+		"CNI_COMMAND=" + command,
+		"CNI_CONTAINERID=" + containerID,
+		"CNI_NETNS=/some/netns",
+		"CNI_NETNS_OVERRIDE=1",
+		"CNI_IFNAME=eth0",
+		"CNI_PATH=/opt/cni/bin",
+		"HOME=/root",
+	}
+}
+
+func request(command, containerID string) *daemon.Request {
+	env := map[string]string{}
+	for _, kv := range environ(command, containerID) {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	return &daemon.Request{Env: env, Stdin: []byte(netconf)}
+}
+
+var _ = Describe("Daemon", func() {
+	var (
+		socketPath string
+		server     *daemon.Server
+		served     chan error
+		funcs      skel.CNIFuncs
+	)
+
+	serve := func() {
+		server = daemon.NewServer(funcs, version.All, "CNI mydaemon plugin")
+		l, err := daemon.Listen(socketPath)
+		Expect(err).NotTo(HaveOccurred())
+		served = make(chan error, 1)
+		go func() {
+			defer GinkgoRecover()
+			served <- server.Serve(l)
+		}()
+	}
+
+	shim := func(env []string, stdin string) (int, string, string) {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+		code := daemon.Shim(socketPath, env, strings.NewReader(stdin), stdout, stderr)
+		return code, stdout.String(), stderr.String()
+	}
+
+	BeforeEach(func() {
+		socketPath = filepath.Join(GinkgoT().TempDir(), "mydaemon.sock")
+		funcs = skel.CNIFuncs{
+			Add: func(args *skel.CmdArgs) error {
+				result := &current.Result{
+					CNIVersion: current.ImplementedSpecVersion,
+					Interfaces: []*current.Interface{{Name: args.IfName, Sandbox: args.Netns}},
+				}
+				return skel.PrintResult(args, result, "1.0.0")
+			},
+			Del: func(args *skel.CmdArgs) error {
+				return types.NewError(types.ErrTryAgainLater, "busy", args.ContainerID)
+			},
+		}
+	})
+
+	AfterEach(func() {
+		if server != nil {
+			Expect(server.Shutdown(context.Background())).To(Succeed())
+			Eventually(served).Should(Receive(BeNil()))
+			server = nil
+		}
+	})
+
+	It("forwards invocations to the daemon and prints its response", func() {
+		serve()
+		code, stdout, _ := shim(environ("ADD", "ctr1"), netconf)
+		Expect(code).To(Equal(0))
+		Expect(stdout).To(MatchJSON(`{"cniVersion": "1.0.0", "interfaces": [{"name": "eth0", "sandbox": "/some/netns"}]}`))
+
+		code, stdout, _ = shim(environ("VERSION", ""), "")
+		Expect(code).To(Equal(0))
+		Expect(stdout).To(ContainSubstring(`"supportedVersions"`))
+	})
+
+	It("prints the errors of the plugin", func() {
+		serve()
+		code, stdout, _ := shim(environ("DEL", "ctr1"), netconf)
+		Expect(code).To(Equal(1))
+		Expect(stdout).To(MatchJSON(`{"code": 11, "msg": "busy", "details": "ctr1"}`))
+
+		// and those of skel
+		code, stdout, _ = shim(environ("ADD", "ctr1"), `{"cniVersion": "1.0.0"}`)
+		Expect(code).To(Equal(1))
+		Expect(stdout).To(ContainSubstring(`"code": 7`))
+	})
+
+	It("only forwards the CNI variables", func() {
+		var seen string
+		funcs.Add = func(args *skel.CmdArgs) error {
+			seen = args.Path
+			return skel.PrintResult(args, &current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+		}
+		serve()
+		code, _, _ := shim(environ("ADD", "ctr1"), netconf)
+		Expect(code).To(Equal(0))
+		Expect(seen).To(Equal("/opt/cni/bin"))
+	})
+
+	It("reports a daemon which is not running", func() {
+		code, stdout, _ := shim(environ("ADD", "ctr1"), netconf)
+		Expect(code).To(Equal(1))
+		e := &types.Error{}
+		Expect(json.Unmarshal([]byte(stdout), e)).To(Succeed())
+		Expect(e.Code).To(Equal(types.ErrPluginNotAvailable))
+	})
+
+	It("uses the socket set in the network configuration", func() {
+		serve()
+		conf := fmt.Sprintf(`{"cniVersion": "1.0.0", "name": "mynet", "type": "mydaemon", %q: %q}`, daemon.SocketKey, socketPath)
+		stdout := &bytes.Buffer{}
+		code := daemon.Shim("", environ("ADD", "ctr1"), strings.NewReader(conf), stdout, &bytes.Buffer{})
+		Expect(code).To(Equal(0), stdout.String())
+	})
+
+	It("serializes the invocations of an attachment and limits concurrency", func() {
+		var running, maxRunning, ctr1Running, maxCtr1 int32
+		funcs.Add = func(args *skel.CmdArgs) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			if args.ContainerID == "ctr1" {
+				if atomic.AddInt32(&ctr1Running, 1) > 1 {
+					atomic.StoreInt32(&maxCtr1, 2)
+				}
+				defer atomic.AddInt32(&ctr1Running, -1)
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return skel.PrintResult(args, &current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+		}
+		server = daemon.NewServer(funcs, version.All, "")
+		server.MaxConcurrent = 2
+		defer func() { server = nil }()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			containerID := "ctr1"
+			if i%2 == 1 {
+				containerID = fmt.Sprintf("ctr%d", i+10)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp := server.Run(context.Background(), request("ADD", containerID))
+				Expect(resp.Error).To(BeNil())
+			}()
+		}
+		wg.Wait()
+		Expect(atomic.LoadInt32(&maxRunning)).To(BeNumerically("<=", 2))
+		Expect(atomic.LoadInt32(&maxCtr1)).To(BeZero())
+	})
+
+	It("finishes running invocations on shutdown", func() {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		funcs.Add = func(args *skel.CmdArgs) error {
+			close(started)
+			<-release
+			return skel.PrintResult(args, &current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+		}
+		serve()
+
+		result := make(chan int, 1)
+		go func() {
+			code, _, _ := shim(environ("ADD", "ctr1"), netconf)
+			result <- code
+		}()
+		<-started
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- server.Shutdown(context.Background()) }()
+		Consistently(shutdown, "100ms").ShouldNot(Receive())
+
+		close(release)
+		Eventually(result).Should(Receive(Equal(0)))
+		Eventually(shutdown).Should(Receive(BeNil()))
+		Eventually(served).Should(Receive(BeNil()))
+		server = nil
+
+		// new invocations are refused
+		code, _, _ := shim(environ("ADD", "ctr1"), netconf)
+		Expect(code).To(Equal(1))
+	})
+})
@@ -0,0 +1,473 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+const (
+	// DefaultPoolWorkers is the number of workers of each plugin of a Pool
+	// when PoolOptions.Workers is zero
+	DefaultPoolWorkers = 1
+	// DefaultPoolIdleTimeout is how long a worker may be idle before it is
+	// stopped when PoolOptions.IdleTimeout is zero
+	DefaultPoolIdleTimeout = 5 * time.Minute
+	// DefaultPoolStartTimeout is how long a worker may take to listen on
+	// its socket when PoolOptions.StartTimeout is zero
+	DefaultPoolStartTimeout = 10 * time.Second
+
+	// workerStopTimeout is how long a stopped worker may take to exit
+	// before it is killed, which covers its own wait for running
+	// invocations
+	workerStopTimeout = workerShutdownTimeout + 5*time.Second
+)
+
+// PoolOptions control NewPool
+type PoolOptions struct {
+	// Plugins are the names of the plugins, as found in CNI_PATH, which
+	// are built with PluginMain and so can run as workers. Other plugins
+	// are executed as usual.
+	Plugins []string
+	// Workers is the number of workers of each plugin, among which
+	// invocations are spread; DefaultPoolWorkers if zero. A worker runs
+	// invocations of different attachments concurrently.
+	Workers int
+	// IdleTimeout stops the workers which ran no invocation for that long;
+	// DefaultPoolIdleTimeout if zero, never if negative
+	IdleTimeout time.Duration
+	// StartTimeout is how long a worker may take to listen on its socket;
+	// DefaultPoolStartTimeout if zero
+	StartTimeout time.Duration
+	// Stderr receives the output of the workers and the stderr of the
+	// invocations they run; os.Stderr if nil
+	Stderr io.Writer
+	// SocketDir holds the sockets of the workers. If empty, a temporary
+	// directory is created, and removed by Close.
+	SocketDir string
+}
+
+// Pool is an invoke.Exec which keeps plugins running as workers and
+// forwards invocations to them, as to the daemon of a shim, saving the
+// cost of starting a process and initializing the Go runtime for every
+// attachment on busy nodes. Workers are started on the first invocation
+// of their plugin, replaced when the plugin binary changes or exits, and
+// stopped when idle. VERSION and the invocations of other plugins are
+// executed by the fallback Exec.
+//
+// Workers are started by the Pool rather than the fallback Exec, in the
+// network namespace of the runtime, so they would escape the options of an
+// invoke.RawExec applying to plugin processes, such as invoke.WithSeccomp,
+// invoke.WithNetNS, invoke.WithMaxOutput or invoke.WithCapture. A Pool
+// whose fallback Exec has such options, or cannot tell as it is not an
+// invoke.ProcessOptionsExec, refuses to start workers, and fails the
+// invocations of their plugins.
+type Pool struct {
+	exec    invoke.Exec
+	opts    PoolOptions
+	plugins map[string]bool
+	// refused is set if the fallback Exec may have options workers would
+	// escape
+	refused bool
+
+	mu        sync.Mutex
+	socketDir string
+	workers   map[string][]*worker
+	next      map[string]int
+	started   int
+	closed    bool
+}
+
+// Pool implements the invoke.Exec interface
+var _ invoke.Exec = &Pool{}
+
+// worker is a running plugin serving invocations on its socket
+type worker struct {
+	path    string
+	socket  string
+	modTime time.Time
+	// client keeps its connections to the worker open between invocations
+	client *http.Client
+
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	ready  chan struct{}
+	exited chan struct{}
+	// err is set if the worker failed to start, once ready is closed
+	err error
+
+	// The following are guarded by the Pool's mutex
+	inflight int
+	retired  bool
+	idle     *time.Timer
+}
+
+// NewPool returns a Pool running the plugins of opts as workers, and the
+// others with exec, or the default exec if nil. Close stops the workers.
+func NewPool(exec invoke.Exec, opts *PoolOptions) *Pool {
+	if exec == nil {
+		exec = &invoke.DefaultExec{
+			RawExec:       &invoke.RawExec{Stderr: os.Stderr},
+			PluginDecoder: version.PluginDecoder{},
+		}
+	}
+	p := &Pool{
+		exec:    exec,
+		plugins: map[string]bool{},
+		workers: map[string][]*worker{},
+		next:    map[string]int{},
+	}
+	if opts != nil {
+		p.opts = *opts
+	}
+	if p.opts.Workers <= 0 {
+		p.opts.Workers = DefaultPoolWorkers
+	}
+	if p.opts.IdleTimeout == 0 {
+		p.opts.IdleTimeout = DefaultPoolIdleTimeout
+	}
+	if p.opts.StartTimeout <= 0 {
+		p.opts.StartTimeout = DefaultPoolStartTimeout
+	}
+	if p.opts.Stderr == nil {
+		p.opts.Stderr = os.Stderr
+	}
+	for _, name := range p.opts.Plugins {
+		p.plugins[name] = true
+	}
+	p.refused = invoke.HasProcessOptions(exec)
+	return p
+}
+
+// pooled reports whether the plugin runs as workers
+func (p *Pool) pooled(pluginPath string) bool {
+	name := filepath.Base(pluginPath)
+	return p.plugins[strings.TrimSuffix(name, filepath.Ext(name))]
+}
+
+// ExecPlugin forwards the invocation to a worker of the plugin, or executes
+// the plugin with the fallback Exec if it does not run as workers. As
+// with the plugin binary, a failed invocation returns its *types.Error.
+func (p *Pool) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	env := cniEnv(environ)
+	if env["CNI_COMMAND"] == "VERSION" || !p.pooled(pluginPath) {
+		return p.exec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+	}
+	if p.refused {
+		return nil, fmt.Errorf("plugin worker %s would escape the process options of the fallback exec", pluginPath)
+	}
+
+	w, err := p.acquire(ctx, pluginPath)
+	if err != nil {
+		return nil, err
+	}
+	defer p.release(w)
+
+	resp, err := forward(ctx, w.client, &Request{Env: env, Stdin: stdinData})
+	if err != nil {
+		return nil, fmt.Errorf("plugin worker %s failed: %w", pluginPath, err)
+	}
+	if len(resp.Stderr) > 0 {
+		_, _ = p.opts.Stderr.Write(resp.Stderr)
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	if len(resp.Stdout) > invoke.DefaultMaxOutput {
+		return nil, &invoke.OutputTooLargeError{PluginPath: pluginPath, Limit: invoke.DefaultMaxOutput}
+	}
+	return resp.Stdout, nil
+}
+
+// FindInPath finds the plugin with the fallback Exec
+func (p *Pool) FindInPath(plugin string, paths []string) (string, error) {
+	return p.exec.FindInPath(plugin, paths)
+}
+
+// Decode decodes VERSION output with the fallback Exec
+func (p *Pool) Decode(jsonBytes []byte) (version.PluginInfo, error) {
+	return p.exec.Decode(jsonBytes)
+}
+
+// acquire returns a ready worker of the plugin, starting one if there are
+// fewer than opts.Workers, and counts the invocation as in flight
+func (p *Pool) acquire(ctx context.Context, pluginPath string) (*worker, error) {
+	info, err := os.Stat(pluginPath)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errors.New("plugin worker pool is closed")
+	}
+	if p.socketDir == "" {
+		if err := p.makeSocketDir(); err != nil {
+			p.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	// Retire the workers which exited or run a replaced binary
+	live := p.workers[pluginPath][:0]
+	for _, w := range p.workers[pluginPath] {
+		if w.hasExited() || !w.modTime.Equal(info.ModTime()) {
+			p.retire(w)
+			continue
+		}
+		live = append(live, w)
+	}
+
+	var w *worker
+	var start bool
+	if len(live) < p.opts.Workers {
+		p.started++
+		name := filepath.Base(pluginPath)
+		socket := filepath.Join(p.socketDir, fmt.Sprintf("%s-%d.sock", strings.TrimSuffix(name, filepath.Ext(name)), p.started))
+		w = &worker{
+			path:    pluginPath,
+			socket:  socket,
+			modTime: info.ModTime(),
+			client:  newClient(socket),
+			ready:   make(chan struct{}),
+			exited:  make(chan struct{}),
+		}
+		live = append(live, w)
+		start = true
+	} else {
+		w = live[p.next[pluginPath]%len(live)]
+		p.next[pluginPath]++
+	}
+	p.workers[pluginPath] = live
+	w.inflight++
+	if w.idle != nil {
+		w.idle.Stop()
+		w.idle = nil
+	}
+	p.mu.Unlock()
+
+	if start {
+		w.err = p.start(w)
+		close(w.ready)
+	}
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		p.release(w)
+		return nil, ctx.Err()
+	}
+	if w.err != nil {
+		p.release(w)
+		return nil, w.err
+	}
+	return w, nil
+}
+
+// makeSocketDir creates the directory of the sockets. The Pool's mutex
+// must be held.
+func (p *Pool) makeSocketDir() error {
+	if p.opts.SocketDir != "" {
+		if err := os.MkdirAll(p.opts.SocketDir, 0o700); err != nil {
+			return err
+		}
+		p.socketDir = p.opts.SocketDir
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "cni-workers-")
+	if err != nil {
+		return err
+	}
+	p.socketDir = dir
+	return nil
+}
+
+// release counts the invocation of the worker as done, and stops the
+// worker if it is retired or, after IdleTimeout, if it stays idle
+func (p *Pool) release(w *worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	w.inflight--
+	if w.inflight > 0 {
+		return
+	}
+	if w.err != nil && !w.retired {
+		p.remove(w)
+		w.retired = true
+	}
+	if w.retired {
+		go w.stop()
+	} else if p.opts.IdleTimeout > 0 {
+		w.idle = time.AfterFunc(p.opts.IdleTimeout, func() { p.reap(w) })
+	}
+}
+
+// reap stops the worker if it is still idle
+func (p *Pool) reap(w *worker) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w.inflight > 0 || w.retired || w.idle == nil {
+		return
+	}
+	w.idle = nil
+	p.remove(w)
+	w.retired = true
+	go w.stop()
+}
+
+// retire marks a worker which was removed from the pool for stopping, once
+// its invocations are done. The Pool's mutex must be held.
+func (p *Pool) retire(w *worker) {
+	w.retired = true
+	if w.idle != nil {
+		w.idle.Stop()
+		w.idle = nil
+	}
+	if w.inflight == 0 {
+		go w.stop()
+	}
+}
+
+// remove takes the worker out of the pool. The Pool's mutex must be held.
+func (p *Pool) remove(w *worker) {
+	workers := p.workers[w.path]
+	for i, other := range workers {
+		if other == w {
+			p.workers[w.path] = append(workers[:i:i], workers[i+1:]...)
+			return
+		}
+	}
+}
+
+// start runs the plugin as a worker and waits for it to listen on its
+// socket
+func (p *Pool) start(w *worker) error {
+	w.cmd = exec.Command(w.path)
+	w.cmd.Env = append(os.Environ(), WorkerSocketEnv+"="+w.socket)
+	w.cmd.Stdout = p.opts.Stderr
+	w.cmd.Stderr = p.opts.Stderr
+	stdin, err := w.cmd.StdinPipe()
+	if err != nil {
+		close(w.exited)
+		return err
+	}
+	w.stdin = stdin
+	if err := w.cmd.Start(); err != nil {
+		close(w.exited)
+		return fmt.Errorf("failed to start plugin worker %s: %w", w.path, err)
+	}
+	go func() {
+		_ = w.cmd.Wait()
+		close(w.exited)
+	}()
+
+	deadline := time.NewTimer(p.opts.StartTimeout)
+	defer deadline.Stop()
+	for {
+		conn, err := net.Dial("unix", w.socket)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+		select {
+		case <-w.exited:
+			return fmt.Errorf("plugin worker %s exited while starting; is it built with daemon.PluginMain?", w.path)
+		case <-deadline.C:
+			w.stop()
+			return fmt.Errorf("plugin worker %s did not listen on %s within %v", w.path, w.socket, p.opts.StartTimeout)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (w *worker) hasExited() bool {
+	select {
+	case <-w.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop closes the stdin of the worker, which makes it exit once its
+// running invocations are done, and kills it if it does not
+func (w *worker) stop() {
+	w.client.CloseIdleConnections()
+	if w.cmd == nil || w.cmd.Process == nil {
+		return
+	}
+	_ = w.stdin.Close()
+	select {
+	case <-w.exited:
+	case <-time.After(workerStopTimeout):
+		_ = w.cmd.Process.Kill()
+		<-w.exited
+	}
+	_ = os.Remove(w.socket)
+}
+
+// Close stops the workers, waiting for their running invocations, and
+// makes further invocations of pooled plugins fail
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	var workers []*worker
+	for path, ws := range p.workers {
+		for _, w := range ws {
+			if w.idle != nil {
+				w.idle.Stop()
+				w.idle = nil
+			}
+			w.retired = true
+		}
+		workers = append(workers, ws...)
+		delete(p.workers, path)
+	}
+	socketDir := p.socketDir
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			<-w.ready
+			w.stop()
+		}(w)
+	}
+	wg.Wait()
+	if socketDir != "" && p.opts.SocketDir == "" {
+		return os.RemoveAll(socketDir)
+	}
+	return nil
+}
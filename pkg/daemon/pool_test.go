@@ -0,0 +1,196 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing/fstest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/daemon"
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+var _ = Describe("Pool", func() {
+	var (
+		ctx        context.Context
+		pluginDir  string
+		pluginPath string
+		socketDir  string
+		pool       *daemon.Pool
+	)
+
+	// install copies the test binary, which TestMain runs as the plugin
+	install := func(name string) string {
+		self, err := os.Executable()
+		Expect(err).NotTo(HaveOccurred())
+		src, err := os.Open(self)
+		Expect(err).NotTo(HaveOccurred())
+		defer src.Close()
+		path := filepath.Join(pluginDir, name)
+		dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.Copy(dst, src)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dst.Close()).To(Succeed())
+		return path
+	}
+
+	environ := func(command, containerID string) []string {
+		return append((&invoke.Args{
+			Command:     command,
+			ContainerID: containerID,
+			NetNS:       "/var/run/netns/pool",
+			IfName:      "eth0",
+			Path:        pluginDir,
+		}).AsEnv(), "CNI_NETNS_OVERRIDE=1")
+	}
+
+	// add returns the process which ran the ADD
+	add := func(path, containerID string) string {
+		stdout, err := pool.ExecPlugin(ctx, path, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("ADD", containerID))
+		Expect(err).NotTo(HaveOccurred())
+		result, err := current.NewResult(stdout)
+		Expect(err).NotTo(HaveOccurred())
+		return result.(*current.Result).Interfaces[0].Name
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		pluginDir = GinkgoT().TempDir()
+		pluginPath = install("worker")
+		// unix socket paths are short: keep them out of the deep temp dirs
+		var err error
+		socketDir, err = os.MkdirTemp("", "pool")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, socketDir)
+	})
+
+	AfterEach(func() {
+		Expect(pool.Close()).To(Succeed())
+	})
+
+	It("runs the invocations of a plugin in a worker", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		first := add(pluginPath, "ctr1")
+		Expect(first).NotTo(Equal(fmt.Sprint(os.Getpid())))
+		Expect(add(pluginPath, "ctr2")).To(Equal(first))
+	})
+
+	It("spreads invocations among the workers", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Workers: 2, Stderr: GinkgoWriter})
+		pids := map[string]bool{}
+		for _, containerID := range []string{"ctr1", "ctr2", "ctr3", "ctr4"} {
+			pids[add(pluginPath, containerID)] = true
+		}
+		Expect(pids).To(HaveLen(2))
+	})
+
+	It("executes other plugins as binaries", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"other"}, Stderr: GinkgoWriter})
+		Expect(add(pluginPath, "ctr1")).NotTo(Equal(add(pluginPath, "ctr2")))
+	})
+
+	It("returns the errors of the plugin", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		_, err := pool.ExecPlugin(ctx, pluginPath, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("DEL", "fail"))
+		var e *types.Error
+		Expect(errors.As(err, &e)).To(BeTrue())
+		Expect(e.Code).To(BeEquivalentTo(types.ErrTryAgainLater))
+		Expect(e.Msg).To(Equal("failing as asked"))
+	})
+
+	It("answers VERSION with the binary", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		info, err := invoke.GetVersionInfo(ctx, pluginPath, pool)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.SupportedVersions()).To(ContainElement("1.0.0"))
+	})
+
+	It("replaces the workers of a changed binary", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		first := add(pluginPath, "ctr1")
+		mtime := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(pluginPath, mtime, mtime)).To(Succeed())
+		Expect(add(pluginPath, "ctr2")).NotTo(Equal(first))
+	})
+
+	It("stops idle workers", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, IdleTimeout: 50 * time.Millisecond, Stderr: GinkgoWriter, SocketDir: socketDir})
+		first := add(pluginPath, "ctr1")
+		Expect(filepath.Join(socketDir, "worker-1.sock")).To(BeAnExistingFile())
+		Eventually(filepath.Join(socketDir, "worker-1.sock")).ShouldNot(BeAnExistingFile())
+		Expect(add(pluginPath, "ctr2")).NotTo(Equal(first))
+	})
+
+	It("fails for plugins which cannot run as workers", func() {
+		plain := filepath.Join(pluginDir, "plain")
+		Expect(os.WriteFile(plain, []byte("#!/bin/sh\nexit 0\n"), 0o755)).To(Succeed())
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"plain"}, Stderr: GinkgoWriter})
+		_, err := pool.ExecPlugin(ctx, plain, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "plain"}`), environ("ADD", "ctr1"))
+		Expect(err).To(MatchError(ContainSubstring("exited while starting")))
+	})
+
+	It("refuses to run workers outside the process options of the fallback exec", func() {
+		exec := &invoke.DefaultExec{RawExec: invoke.NewRawExec(invoke.WithMaxOutput(1 << 20))}
+		pool = daemon.NewPool(exec, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		_, err := pool.ExecPlugin(ctx, pluginPath, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("ADD", "ctr1"))
+		Expect(err).To(MatchError(fmt.Sprintf("plugin worker %s would escape the process options of the fallback exec", pluginPath)))
+
+		info, err := invoke.GetVersionInfo(ctx, pluginPath, pool)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.SupportedVersions()).To(ContainElement("1.0.0"))
+	})
+
+	It("refuses to run workers outside the process options of a wrapped exec", func() {
+		raw := invoke.NewRawExec(invoke.WithMaxOutput(1 << 20))
+		exec := invoke.NewMemfdExec(invoke.TracedExec(&invoke.DefaultExec{RawExec: raw}, nil), invoke.FSSource{FS: fstest.MapFS{}})
+		pool = daemon.NewPool(exec, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		_, err := pool.ExecPlugin(ctx, pluginPath, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("ADD", "ctr1"))
+		Expect(err).To(MatchError(fmt.Sprintf("plugin worker %s would escape the process options of the fallback exec", pluginPath)))
+	})
+
+	It("refuses to run workers when the fallback exec cannot tell its process options", func() {
+		exec := struct{ invoke.Exec }{&invoke.DefaultExec{RawExec: &invoke.RawExec{Stderr: GinkgoWriter}}}
+		pool = daemon.NewPool(exec, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		_, err := pool.ExecPlugin(ctx, pluginPath, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("ADD", "ctr1"))
+		Expect(err).To(MatchError(fmt.Sprintf("plugin worker %s would escape the process options of the fallback exec", pluginPath)))
+	})
+
+	It("runs workers when a wrapped exec has no process options", func() {
+		exec := invoke.ObservedExec(&invoke.DefaultExec{RawExec: &invoke.RawExec{Stderr: GinkgoWriter}},
+			func(string, string, time.Duration, error) {})
+		pool = daemon.NewPool(exec, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		Expect(add(pluginPath, "ctr1")).NotTo(Equal(fmt.Sprint(os.Getpid())))
+	})
+
+	It("refuses invocations once closed", func() {
+		pool = daemon.NewPool(nil, &daemon.PoolOptions{Plugins: []string{"worker"}, Stderr: GinkgoWriter})
+		add(pluginPath, "ctr1")
+		Expect(pool.Close()).To(Succeed())
+		_, err := pool.ExecPlugin(ctx, pluginPath, []byte(`{"cniVersion": "1.0.0", "name": "pool", "type": "worker"}`), environ("ADD", "ctr2"))
+		Expect(err).To(MatchError("plugin worker pool is closed"))
+	})
+})
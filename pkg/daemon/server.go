@@ -0,0 +1,209 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// Server runs the invocations forwarded by shims
+type Server struct {
+	funcs       skel.CNIFuncs
+	versionInfo version.PluginInfo
+	about       string
+
+	// MaxConcurrent limits the invocations run at once; further ones wait.
+	// Zero means no limit. It must be set before serving.
+	MaxConcurrent int
+	// Logger, if set, logs the requests which could not be run
+	Logger *slog.Logger
+
+	initOnce sync.Once
+	slots    chan struct{}
+	locks    attachmentLocks
+	http     *http.Server
+}
+
+// NewServer returns a Server hosting the plugin's funcs
+func NewServer(funcs skel.CNIFuncs, versionInfo version.PluginInfo, about string) *Server {
+	s := &Server{
+		funcs:       funcs,
+		versionInfo: versionInfo,
+		about:       about,
+	}
+	s.http = &http.Server{Handler: s}
+	return s
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (s *Server) log() *slog.Logger {
+	if s.Logger == nil {
+		return discardLogger
+	}
+	return s.Logger
+}
+
+// Listen creates the unix socket at path, replacing a stale one, and makes
+// it only accessible by the owner, as invocations come from the runtime
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// ListenAndServe listens on the unix socket at path and serves until
+// Shutdown, then removes the socket
+func (s *Server) ListenAndServe(path string) error {
+	l, err := Listen(path)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+	return s.Serve(l)
+}
+
+// Serve serves the listener until Shutdown, which makes it return nil
+func (s *Server) Serve(l net.Listener) error {
+	if err := s.http.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting invocations and waits for those running to
+// finish, or for ctx to be done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.http.Shutdown(ctx)
+}
+
+// ServeHTTP runs one forwarded invocation
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	req := &Request{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		s.log().Warn("invalid request", "error", err)
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	resp := s.Run(r.Context(), req)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// Run runs an invocation once a slot is free and no other invocation of
+// the same attachment is running
+func (s *Server) Run(ctx context.Context, req *Request) *Response {
+	s.initOnce.Do(func() {
+		if s.MaxConcurrent > 0 {
+			s.slots = make(chan struct{}, s.MaxConcurrent)
+		}
+	})
+
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		case <-ctx.Done():
+			return &Response{Error: types.NewError(types.ErrTryAgainLater, "daemon busy", ctx.Err().Error())}
+		}
+	}
+	if containerID := req.Env["CNI_CONTAINERID"]; containerID != "" {
+		unlock := s.locks.lock(containerID + "/" + req.Env["CNI_IFNAME"])
+		defer unlock()
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	pio := skel.IO{
+		Getenv: func(key string) string { return req.Env[key] },
+		Stdin:  bytes.NewReader(req.Stdin),
+		Stdout: stdout,
+		Stderr: stderr,
+	}
+	resp := &Response{}
+	if err := skel.PluginMainFuncsWithIO(pio, s.funcs, s.versionInfo, s.about); err != nil {
+		resp.Error = err
+	}
+	resp.Stdout = stdout.Bytes()
+	resp.Stderr = stderr.Bytes()
+	return resp
+}
+
+// attachmentLocks serializes the invocations of each attachment
+type attachmentLocks struct {
+	mu    sync.Mutex
+	locks map[string]*attachmentLock
+}
+
+type attachmentLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (a *attachmentLocks) lock(key string) func() {
+	a.mu.Lock()
+	if a.locks == nil {
+		a.locks = map[string]*attachmentLock{}
+	}
+	l, ok := a.locks[key]
+	if !ok {
+		l = &attachmentLock{}
+		a.locks[key] = l
+	}
+	l.refs++
+	a.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		a.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(a.locks, key)
+		}
+		a.mu.Unlock()
+	}
+}
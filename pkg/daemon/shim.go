@@ -0,0 +1,127 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// Forward sends an invocation to the daemon listening on socketPath and
+// returns its response
+func Forward(ctx context.Context, socketPath string, req *Request) (*Response, error) {
+	client := newClient(socketPath)
+	defer client.CloseIdleConnections()
+	return forward(ctx, client, req)
+}
+
+// newClient returns a client sending every request to the unix socket
+func newClient(socketPath string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+}
+
+func forward(ctx context.Context, client *http.Client, req *Request) (*Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	// The host is ignored: every request goes to the socket
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://daemon/", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(httpResp.Body, 4096))
+		return nil, fmt.Errorf("daemon returned %s: %s", httpResp.Status, bytes.TrimSpace(msg))
+	}
+	resp := &Response{}
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("invalid daemon response: %w", err)
+	}
+	return resp, nil
+}
+
+// Shim forwards the invocation of the running process to the daemon on
+// socketPath, and writes the daemon's response to stdout and stderr. It
+// returns the exit code of the invocation. An empty socketPath means the
+// socket set in the network configuration, or else the default socket of
+// the plugin the process was executed as.
+func Shim(socketPath string, environ []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	req := &Request{Env: cniEnv(environ)}
+	if stdin != nil && req.Env["CNI_COMMAND"] != "VERSION" && req.Env["CNI_COMMAND"] != "" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return printError(stdout, types.NewError(types.ErrIOFailure, "error reading from stdin", err.Error()))
+		}
+		req.Stdin = data
+	}
+	if socketPath == "" {
+		socketPath = socketFromConfig(req.Stdin)
+	}
+	if socketPath == "" {
+		socketPath = SocketPath(pluginName())
+	}
+
+	resp, err := Forward(context.Background(), socketPath, req)
+	if err != nil {
+		return printError(stdout, types.NewError(types.ErrPluginNotAvailable, "plugin daemon not available", err.Error()))
+	}
+	_, _ = stdout.Write(resp.Stdout)
+	_, _ = stderr.Write(resp.Stderr)
+	if resp.Error != nil {
+		return printError(stdout, resp.Error)
+	}
+	return 0
+}
+
+// printError prints the error as skel.PluginMainFuncs does
+func printError(stdout io.Writer, e *types.Error) int {
+	data, err := json.MarshalIndent(e, "", "    ")
+	if err == nil {
+		_, err = stdout.Write(data)
+	}
+	if err != nil {
+		log.Print("Error writing error JSON to stdout: ", err)
+	}
+	return 1
+}
+
+// ShimMain is the main function of a shim binary: it forwards the
+// invocation to the daemon of the plugin and exits
+func ShimMain(socketPath string) {
+	os.Exit(Shim(socketPath, os.Environ(), os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// WorkerSocketEnv is the environment variable which starts a plugin built
+// with PluginMain as a worker of a Pool, serving on the socket it names
+const WorkerSocketEnv = "CNI_WORKER_SOCKET"
+
+// workerShutdownTimeout is how long a worker waits for running
+// invocations once its Pool lets it go
+const workerShutdownTimeout = 30 * time.Second
+
+// PluginMain is the main function of plugins which can run as workers of a
+// Pool. Executed with WorkerSocketEnv set, the plugin serves invocations
+// on that socket until its stdin is closed; otherwise it runs a single
+// invocation as skel.PluginMainFuncs does. As with a Server, results must
+// be printed with skel.PrintResult.
+func PluginMain(funcs skel.CNIFuncs, versionInfo version.PluginInfo, about string) {
+	socketPath := os.Getenv(WorkerSocketEnv)
+	if socketPath == "" {
+		skel.PluginMainFuncs(funcs, versionInfo, about)
+		return
+	}
+	if err := ServeWorker(socketPath, NewServer(funcs, versionInfo, about), os.Stdin); err != nil {
+		log.Printf("worker: %v", err)
+		os.Exit(1)
+	}
+}
+
+// ServeWorker serves the server on the unix socket at socketPath until
+// stdin is closed, as it is when the Pool which started the worker stops
+// it or exits, then waits for running invocations and removes the socket
+func ServeWorker(socketPath string, s *Server, stdin io.Reader) error {
+	l, err := Listen(socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+
+	go func() {
+		_, _ = io.Copy(io.Discard, stdin)
+		ctx, cancel := context.WithTimeout(context.Background(), workerShutdownTimeout)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			s.log().Warn("worker shutdown interrupted", "error", err)
+		}
+	}()
+	return s.Serve(l)
+}
@@ -18,6 +18,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/containernetworking/cni/pkg/audit"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
 )
 
 type CNIArgs interface {
@@ -48,6 +51,10 @@ type Args struct {
 	PluginArgsStr string
 	IfName        string
 	Path          string
+	// CDIDevices are passed in CNI_CDI_DEVICES, if any
+	CDIDevices []string
+	// CorrelationID is passed in audit.CorrelationIDEnv, if set
+	CorrelationID string
 }
 
 // Args implements the CNIArgs interface
@@ -70,6 +77,12 @@ func (args *Args) AsEnv() []string {
 		"CNI_IFNAME="+args.IfName,
 		"CNI_PATH="+args.Path,
 	)
+	if len(args.CDIDevices) > 0 {
+		env = append(env, capabilities.CDIDevicesEnv+"="+strings.Join(args.CDIDevices, ","))
+	}
+	if args.CorrelationID != "" {
+		env = append(env, audit.CorrelationIDEnv+"="+args.CorrelationID)
+	}
 	return dedupEnv(env)
 }
 
@@ -68,6 +68,22 @@ var _ = Describe("CNIArgs AsEnv", func() {
 			Expect(inStringSlice("CNI_PATH=testpath", cniEnvs)).To(BeFalse())
 		})
 
+		It("passes CDI devices, if any", func() {
+			args := invoke.Args{Command: "ADD"}
+			Expect(args.AsEnv()).NotTo(ContainElement(HavePrefix("CNI_CDI_DEVICES=")))
+
+			args.CDIDevices = []string{"intel.com/sriov=vf-3", "nvidia.com/gpu=0"}
+			Expect(args.AsEnv()).To(ContainElement("CNI_CDI_DEVICES=intel.com/sriov=vf-3,nvidia.com/gpu=0"))
+		})
+
+		It("passes the correlation ID, if any", func() {
+			args := invoke.Args{Command: "ADD"}
+			Expect(args.AsEnv()).NotTo(ContainElement(HavePrefix("CNI_CORRELATION_ID=")))
+
+			args.CorrelationID = "abc123"
+			Expect(args.AsEnv()).To(ContainElement("CNI_CORRELATION_ID=abc123"))
+		})
+
 		AfterEach(func() {
 			os.Unsetenv("CNI_COMMAND")
 			os.Unsetenv("CNI_IFNAME")
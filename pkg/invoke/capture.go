@@ -0,0 +1,179 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+const (
+	// DefaultCaptureMaxBytes is the default size cap of each captured
+	// stream
+	DefaultCaptureMaxBytes = 1 << 20
+	// DefaultCaptureMaxCaptures is the default number of captures kept
+	DefaultCaptureMaxCaptures = 100
+
+	captureTimeFormat = "20060102T150405.000000000Z"
+)
+
+// CaptureOptions bound what WithCapture keeps
+type CaptureOptions struct {
+	// MaxBytes caps each captured stream; longer streams are truncated.
+	// DefaultCaptureMaxBytes if zero.
+	MaxBytes int
+	// MaxCaptures is the number of executions kept; the oldest are
+	// removed. DefaultCaptureMaxCaptures if zero.
+	MaxCaptures int
+	// Redact masks the sensitive fields of the network configuration in
+	// the captured stdin, as types.Redact does. Stdin which cannot be
+	// redacted is not captured.
+	Redact bool
+}
+
+// CaptureMeta describes a captured execution, in the meta.json file of
+// its capture
+type CaptureMeta struct {
+	PluginPath string `json:"pluginPath"`
+	// Environ are the CNI_ variables the plugin was given
+	Environ   []string      `json:"environ"`
+	StartTime time.Time     `json:"startTime"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	// Truncated names the streams cut at MaxBytes
+	Truncated []string `json:"truncated,omitempty"`
+}
+
+// WithCapture makes a RawExec record every plugin execution under dir, as
+// a flight recorder for failures nobody was watching. Each execution gets
+// a directory named after its start time, plugin type and command, e.g.
+// "20260102T150405.000000000Z-bridge-ADD-1", holding the plugin's "stdin",
+// "stdout" and "stderr" and a "meta.json" CaptureMeta. Unless Redact is
+// set, captures may hold credentials from the network configuration; they
+// are only readable by their owner either way. Failing to record does not fail the execution.
+func WithCapture(dir string, opts CaptureOptions) ExecOption {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultCaptureMaxBytes
+	}
+	if opts.MaxCaptures <= 0 {
+		opts.MaxCaptures = DefaultCaptureMaxCaptures
+	}
+	return func(e *RawExec) {
+		e.capture = &capturer{dir: dir, opts: opts}
+	}
+}
+
+type capturer struct {
+	dir  string
+	opts CaptureOptions
+	seq  atomic.Uint64
+	// rotating serializes the removal of old captures
+	rotating sync.Mutex
+}
+
+// record writes a capture of an execution, then removes the oldest ones
+func (c *capturer) record(pluginPath string, environ []string, stdin, stdout, stderr []byte, start time.Time, execErr error) {
+	pluginType, command := describeExec(pluginPath, environ)
+	name := fmt.Sprintf("%s-%s-%s-%d", start.UTC().Format(captureTimeFormat), pluginType, command, c.seq.Add(1))
+	if err := c.write(filepath.Join(c.dir, name), pluginPath, environ, stdin, stdout, stderr, start, execErr); err != nil {
+		_ = os.RemoveAll(filepath.Join(c.dir, name))
+		return
+	}
+	c.rotate()
+}
+
+func (c *capturer) write(path, pluginPath string, environ []string, stdin, stdout, stderr []byte, start time.Time, execErr error) error {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return err
+	}
+	meta := CaptureMeta{
+		PluginPath: pluginPath,
+		Environ:    []string{},
+		StartTime:  start,
+		Duration:   time.Since(start),
+	}
+	for _, env := range environ {
+		if strings.HasPrefix(env, "CNI_") {
+			meta.Environ = append(meta.Environ, env)
+		}
+	}
+	if execErr != nil {
+		meta.Error = execErr.Error()
+	}
+	if c.opts.Redact {
+		redacted, err := types.Redact(stdin)
+		if err != nil {
+			redacted = nil
+		}
+		stdin = redacted
+	}
+	for _, stream := range []struct {
+		name string
+		data []byte
+	}{{"stdin", stdin}, {"stdout", stdout}, {"stderr", stderr}} {
+		data := stream.data
+		if len(data) > c.opts.MaxBytes {
+			data = data[:c.opts.MaxBytes]
+			meta.Truncated = append(meta.Truncated, stream.name)
+		}
+		if err := os.WriteFile(filepath.Join(path, stream.name), data, 0o600); err != nil {
+			return err
+		}
+	}
+	data, err := json.MarshalIndent(&meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, "meta.json"), data, 0o600)
+}
+
+// rotate removes the oldest captures beyond MaxCaptures. Other processes
+// may rotate the same directory, so captures which are already gone are
+// not an error.
+func (c *capturer) rotate() {
+	c.rotating.Lock()
+	defer c.rotating.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var captures []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			captures = append(captures, entry.Name())
+		}
+	}
+	if len(captures) <= c.opts.MaxCaptures {
+		return
+	}
+	// Names start with the start time, so they sort oldest first
+	sort.Strings(captures)
+	for _, name := range captures[:len(captures)-c.opts.MaxCaptures] {
+		if err := os.RemoveAll(filepath.Join(c.dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return
+		}
+	}
+}
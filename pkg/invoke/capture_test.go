@@ -0,0 +1,152 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	noop_debug "github.com/containernetworking/cni/plugins/test/noop/debug"
+)
+
+var _ = Describe("Capturing plugin executions", func() {
+	var (
+		debugFileName string
+		captureDir    string
+		environ       []string
+		stdin         []byte
+		ctx           context.Context
+	)
+
+	captures := func() []string {
+		entries, err := os.ReadDir(captureDir)
+		Expect(err).NotTo(HaveOccurred())
+		names := []string{}
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	readMeta := func(name string) invoke.CaptureMeta {
+		data, err := os.ReadFile(filepath.Join(captureDir, name, "meta.json"))
+		Expect(err).NotTo(HaveOccurred())
+		meta := invoke.CaptureMeta{}
+		Expect(json.Unmarshal(data, &meta)).To(Succeed())
+		return meta
+	}
+
+	BeforeEach(func() {
+		debugFile, err := os.CreateTemp("", "cni_debug")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debugFile.Close()).To(Succeed())
+		debugFileName = debugFile.Name()
+		Expect((&noop_debug.Debug{
+			ReportResult: `{ "some": "result" }`,
+			ReportStderr: "some stderr message",
+		}).WriteDebug(debugFileName)).To(Succeed())
+
+		captureDir = filepath.Join(GinkgoT().TempDir(), "captures")
+		environ = []string{
+			"CNI_COMMAND=ADD",
+			"CNI_CONTAINERID=some-container-id",
+			"CNI_ARGS=DEBUG=" + debugFileName,
+			"CNI_NETNS=/some/netns/path",
+			"CNI_PATH=/some/bin/path",
+			"CNI_IFNAME=some-eth0",
+			"HOME=/root",
+		}
+		stdin = []byte(`{"name": "capture-test", "cniVersion": "0.3.1"}`)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(debugFileName)).To(Succeed())
+	})
+
+	It("records the stdio and metadata of each execution", func() {
+		execer := invoke.NewRawExec(invoke.WithStderr(nil), invoke.WithCapture(captureDir, invoke.CaptureOptions{}))
+		_, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).NotTo(HaveOccurred())
+
+		names := captures()
+		Expect(names).To(HaveLen(1))
+		Expect(names[0]).To(MatchRegexp(`^\d{8}T\d{6}\.\d{9}Z-.+-ADD-\d+$`))
+		dir := filepath.Join(captureDir, names[0])
+		Expect(filepath.Join(dir, "stdin")).To(BeARegularFile())
+		Expect(os.ReadFile(filepath.Join(dir, "stdin"))).To(Equal(stdin))
+		Expect(os.ReadFile(filepath.Join(dir, "stdout"))).To(Equal([]byte(`{ "some": "result" }`)))
+		Expect(os.ReadFile(filepath.Join(dir, "stderr"))).To(Equal([]byte("some stderr message")))
+
+		meta := readMeta(names[0])
+		Expect(meta.PluginPath).To(Equal(pathToPlugin))
+		Expect(meta.Environ).To(ContainElement("CNI_COMMAND=ADD"))
+		Expect(meta.Environ).NotTo(ContainElement("HOME=/root"))
+		Expect(meta.Error).To(BeEmpty())
+		Expect(meta.Truncated).To(BeEmpty())
+	})
+
+	It("redacts the credentials in stdin when asked to", func() {
+		stdin = []byte(`{"name": "capture-test", "cniVersion": "0.3.1", "ipam": {"apiToken": "hunter2"}}`)
+		execer := invoke.NewRawExec(invoke.WithStderr(nil), invoke.WithCapture(captureDir, invoke.CaptureOptions{Redact: true}))
+		_, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).NotTo(HaveOccurred())
+
+		names := captures()
+		Expect(names).To(HaveLen(1))
+		captured, err := os.ReadFile(filepath.Join(captureDir, names[0], "stdin"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(captured).To(MatchJSON(`{"name": "capture-test", "cniVersion": "0.3.1", "ipam": {"apiToken": "[REDACTED]"}}`))
+	})
+
+	It("records failed executions", func() {
+		Expect((&noop_debug.Debug{ReportError: "banana"}).WriteDebug(debugFileName)).To(Succeed())
+		execer := invoke.NewRawExec(invoke.WithCapture(captureDir, invoke.CaptureOptions{}))
+		_, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).To(HaveOccurred())
+
+		names := captures()
+		Expect(names).To(HaveLen(1))
+		Expect(readMeta(names[0]).Error).To(ContainSubstring("exit status"))
+		stdout, err := os.ReadFile(filepath.Join(captureDir, names[0], "stdout"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stdout)).To(ContainSubstring("banana"))
+	})
+
+	It("truncates streams and keeps the newest captures", func() {
+		execer := invoke.NewRawExec(invoke.WithStderr(nil), invoke.WithCapture(captureDir, invoke.CaptureOptions{
+			MaxBytes:    8,
+			MaxCaptures: 2,
+		}))
+		for i := 0; i < 3; i++ {
+			_, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		names := captures()
+		Expect(names).To(HaveLen(2))
+		Expect(strings.HasSuffix(names[0], "-2")).To(BeTrue())
+		Expect(strings.HasSuffix(names[1], "-3")).To(BeTrue())
+		Expect(os.ReadFile(filepath.Join(captureDir, names[1], "stdin"))).To(Equal(stdin[:8]))
+		Expect(readMeta(names[1]).Truncated).To(Equal([]string{"stdin", "stdout", "stderr"}))
+	})
+})
@@ -0,0 +1,37 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import "runtime"
+
+// startConfined runs start, which starts a process, on a new thread after
+// the setups, so that the process inherits the confinement they apply to
+// the thread. The thread is discarded afterwards since it cannot be
+// undone.
+func startConfined(setups []func() error, start func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		// Never unlocked, so the thread exits with the goroutine
+		runtime.LockOSThread()
+		for _, setup := range setups {
+			if err := setup(); err != nil {
+				errCh <- err
+				return
+			}
+		}
+		errCh <- start()
+	}()
+	return <-errCh
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+
+package invoke
+
+import "errors"
+
+var errConfineUnsupported = errors.New("confining plugins with seccomp, SELinux or AppArmor is only supported on Linux")
+
+func seccompSetup(_ *SeccompProfile) (func() error, error) {
+	return nil, errConfineUnsupported
+}
+
+func selinuxSetup(_ string) func() error {
+	return func() error { return errConfineUnsupported }
+}
+
+func apparmorSetup(_ string) func() error {
+	return func() error { return errConfineUnsupported }
+}
+
+func startConfined(_ []func() error, _ func() error) error {
+	return errConfineUnsupported
+}
@@ -34,6 +34,23 @@ type Exec interface {
 	Decode(jsonBytes []byte) (version.PluginInfo, error)
 }
 
+// ProcessOptionsExec is implemented by Execs which report whether they set
+// up the plugin processes they start, as RawExec does with its options.
+// Execs wrapping another Exec, such as MemfdExec and TracedExec, forward
+// the report of the wrapped one.
+type ProcessOptionsExec interface {
+	Exec
+	HasProcessOptions() bool
+}
+
+// HasProcessOptions reports whether exec sets up the plugin processes it
+// starts, so that plugins run by other means would escape it. An Exec
+// which is not a ProcessOptionsExec is assumed to.
+func HasProcessOptions(exec Exec) bool {
+	po, ok := exec.(ProcessOptionsExec)
+	return !ok || po.HasProcessOptions()
+}
+
 // Plugin must return result in same version as specified in netconf; but
 // for backwards compatibility reasons if the result version is empty use
 // config version (rather than technically correct 0.1.0).
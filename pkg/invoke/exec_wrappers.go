@@ -0,0 +1,124 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/tracing"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// TracedExec returns an Exec that runs each plugin through exec within a
+// span started with tracer. The span is named after the CNI command and
+// carries the plugin's type and path; the exit code is added by RawExec.
+func TracedExec(exec Exec, tracer tracing.Tracer) Exec {
+	return &tracedExec{Exec: exec, tracer: tracer}
+}
+
+type tracedExec struct {
+	Exec
+	tracer tracing.Tracer
+}
+
+func (e *tracedExec) HasProcessOptions() bool {
+	return HasProcessOptions(e.Exec)
+}
+
+func (e *tracedExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	pluginType, command := describeExec(pluginPath, environ)
+	ctx, span := tracing.Start(ctx, e.tracer, "CNI "+command,
+		tracing.String(tracing.AttrCommand, command),
+		tracing.String(tracing.AttrPluginType, pluginType),
+		tracing.String(tracing.AttrPluginPath, pluginPath),
+	)
+	stdout, err := e.Exec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+	var cniErr *types.Error
+	if errors.As(err, &cniErr) {
+		span.SetAttributes(tracing.Int(tracing.AttrErrorCode, int(cniErr.Code)))
+	}
+	tracing.End(span, err)
+	return stdout, err
+}
+
+// ObservedExec returns an Exec that runs each plugin through exec and then
+// calls observe with the plugin's type, the CNI command, how long the
+// plugin ran and the error it returned, if any
+func ObservedExec(exec Exec, observe func(pluginType, command string, duration time.Duration, err error)) Exec {
+	return &observedExec{Exec: exec, observe: observe}
+}
+
+type observedExec struct {
+	Exec
+	observe func(pluginType, command string, duration time.Duration, err error)
+}
+
+func (e *observedExec) HasProcessOptions() bool {
+	return HasProcessOptions(e.Exec)
+}
+
+func (e *observedExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	start := time.Now()
+	stdout, err := e.Exec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+	pluginType, command := describeExec(pluginPath, environ)
+	e.observe(pluginType, command, time.Since(start), err)
+	return stdout, err
+}
+
+// CheckedExec returns an Exec that runs each plugin through exec and then
+// calls check with the output of successful plugins, failing the execution
+// with a decoding error if check rejects it. Empty output, such as that of
+// DEL, is not checked.
+func CheckedExec(exec Exec, check func([]byte) error) Exec {
+	return &checkedExec{Exec: exec, check: check}
+}
+
+type checkedExec struct {
+	Exec
+	check func([]byte) error
+}
+
+func (e *checkedExec) HasProcessOptions() bool {
+	return HasProcessOptions(e.Exec)
+}
+
+func (e *checkedExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	stdout, err := e.Exec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+	if err != nil || len(bytes.TrimSpace(stdout)) == 0 {
+		return stdout, err
+	}
+	if err := e.check(stdout); err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, fmt.Sprintf("invalid output of plugin %s", pluginPath), err.Error())
+	}
+	return stdout, nil
+}
+
+// describeExec returns the plugin type and CNI command of an execution
+func describeExec(pluginPath string, environ []string) (string, string) {
+	command := "UNKNOWN"
+	for _, env := range environ {
+		if value, ok := strings.CutPrefix(env, "CNI_COMMAND="); ok {
+			command = value
+		}
+	}
+	pluginPath = strings.TrimPrefix(pluginPath, MemfdPathPrefix)
+	return strings.TrimSuffix(filepath.Base(pluginPath), ExecutableFileExtensions[0]), command
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/invoke/fakes"
+	"github.com/containernetworking/cni/pkg/strictjson"
+	"github.com/containernetworking/cni/pkg/tracing"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+type fakeSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...tracing.Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *fakeSpan) RecordError(err error) { s.err = err }
+func (s *fakeSpan) End()                  { s.ended = true }
+
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+	span := &fakeSpan{name: name, attrs: map[string]interface{}{}}
+	span.SetAttributes(attrs...)
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+var _ = Describe("TracedExec", func() {
+	var (
+		rawExec    *fakes.RawExec
+		tracer     *fakeTracer
+		pluginExec invoke.Exec
+		environ    []string
+	)
+
+	BeforeEach(func() {
+		rawExec = &fakes.RawExec{}
+		rawExec.ExecPluginCall.Returns.ResultBytes = []byte(`{"cniVersion": "1.0.0"}`)
+		tracer = &fakeTracer{}
+		pluginExec = invoke.TracedExec(&struct {
+			*fakes.RawExec
+			*fakes.VersionDecoder
+		}{RawExec: rawExec}, tracer)
+		environ = []string{"CNI_COMMAND=ADD", "CNI_IFNAME=eth0"}
+	})
+
+	It("runs the plugin within a span", func() {
+		stdout, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+		Expect(rawExec.ExecPluginCall.Received.PluginPath).To(Equal("/opt/cni/bin/bridge"))
+
+		Expect(tracer.spans).To(HaveLen(1))
+		span := tracer.spans[0]
+		Expect(span.name).To(Equal("CNI ADD"))
+		Expect(span.attrs).To(Equal(map[string]interface{}{
+			tracing.AttrCommand:    "ADD",
+			tracing.AttrPluginType: "bridge",
+			tracing.AttrPluginPath: "/opt/cni/bin/bridge",
+		}))
+		Expect(span.err).NotTo(HaveOccurred())
+		Expect(span.ended).To(BeTrue())
+	})
+
+	It("records the plugin's error", func() {
+		rawExec.ExecPluginCall.Returns.Error = types.NewError(types.ErrTryAgainLater, "busy", "")
+
+		_, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), environ)
+		Expect(err).To(HaveOccurred())
+
+		span := tracer.spans[0]
+		Expect(span.err).To(MatchError(err))
+		Expect(span.attrs).To(HaveKeyWithValue(tracing.AttrErrorCode, int(types.ErrTryAgainLater)))
+		Expect(span.ended).To(BeTrue())
+	})
+})
+
+var _ = Describe("ObservedExec", func() {
+	It("observes each plugin execution", func() {
+		rawExec := &fakes.RawExec{}
+		rawExec.ExecPluginCall.Returns.Error = types.NewError(types.ErrTryAgainLater, "busy", "")
+
+		var observed []string
+		var observedErr error
+		pluginExec := invoke.ObservedExec(&struct {
+			*fakes.RawExec
+			*fakes.VersionDecoder
+		}{RawExec: rawExec}, func(pluginType, command string, duration time.Duration, err error) {
+			observed = append(observed, pluginType, command)
+			observedErr = err
+			Expect(duration).To(BeNumerically(">=", 0))
+		})
+
+		_, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), []string{"CNI_COMMAND=DEL"})
+		Expect(err).To(HaveOccurred())
+		Expect(observed).To(Equal([]string{"bridge", "DEL"}))
+		Expect(observedErr).To(BeIdenticalTo(err))
+	})
+})
+
+var _ = Describe("CheckedExec", func() {
+	var (
+		rawExec    *fakes.RawExec
+		pluginExec invoke.Exec
+	)
+
+	BeforeEach(func() {
+		rawExec = &fakes.RawExec{}
+		pluginExec = invoke.CheckedExec(&struct {
+			*fakes.RawExec
+			*fakes.VersionDecoder
+		}{RawExec: rawExec}, func(stdout []byte) error {
+			return strictjson.Check(stdout, strictjson.DefaultLimits)
+		})
+	})
+
+	It("returns output which passes the check", func() {
+		rawExec.ExecPluginCall.Returns.ResultBytes = []byte(`{"cniVersion": "1.0.0"}`)
+
+		stdout, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), []string{"CNI_COMMAND=ADD"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+	})
+
+	It("does not check empty output", func() {
+		_, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), []string{"CNI_COMMAND=DEL"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails executions whose output is rejected", func() {
+		rawExec.ExecPluginCall.Returns.ResultBytes = []byte(`{"cniVersion": "1.0.0", "cniVersion": "0.4.0"}`)
+
+		_, err := pluginExec.ExecPlugin(context.TODO(), "/opt/cni/bin/bridge", []byte("{}"), []string{"CNI_COMMAND=ADD"})
+		Expect(err).To(Equal(&types.Error{
+			Code:    types.ErrDecodingFailure,
+			Msg:     "invalid output of plugin /opt/cni/bin/bridge",
+			Details: `duplicate key "cniVersion" at $`,
+		}))
+	})
+})
+
+var _ = Describe("HasProcessOptions", func() {
+	It("forwards the report of the wrapped exec", func() {
+		plain := &invoke.DefaultExec{RawExec: &invoke.RawExec{}}
+		confined := &invoke.DefaultExec{RawExec: invoke.NewRawExec(invoke.WithMaxOutput(1 << 20))}
+		for _, wrap := range []func(invoke.Exec) invoke.Exec{
+			func(e invoke.Exec) invoke.Exec { return invoke.TracedExec(e, nil) },
+			func(e invoke.Exec) invoke.Exec {
+				return invoke.ObservedExec(e, func(string, string, time.Duration, error) {})
+			},
+			func(e invoke.Exec) invoke.Exec { return invoke.CheckedExec(e, func([]byte) error { return nil }) },
+			func(e invoke.Exec) invoke.Exec { return invoke.NewMemfdExec(e, nil) },
+		} {
+			Expect(invoke.HasProcessOptions(wrap(plain))).To(BeFalse())
+			Expect(invoke.HasProcessOptions(wrap(confined))).To(BeTrue())
+		}
+	})
+
+	It("assumes execs which cannot tell have process options", func() {
+		fake := &struct {
+			*fakes.RawExec
+			*fakes.VersionDecoder
+		}{}
+		Expect(invoke.HasProcessOptions(fake)).To(BeTrue())
+		Expect(invoke.HasProcessOptions(invoke.TracedExec(fake, nil))).To(BeTrue())
+	})
+})
@@ -0,0 +1,34 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+// WithSELinuxLabel makes a RawExec start plugin processes in the SELinux
+// context label, such as "system_u:system_r:cni_plugin_t:s0", as if the
+// policy defined a transition to it on exec. It is only supported on
+// Linux with SELinux enabled.
+func WithSELinuxLabel(label string) ExecOption {
+	return func(e *RawExec) {
+		e.selinuxLabel = label
+	}
+}
+
+// WithAppArmorProfile makes a RawExec start plugin processes confined by
+// the loaded AppArmor profile. It is only supported on Linux with AppArmor
+// enabled.
+func WithAppArmorProfile(profile string) ExecOption {
+	return func(e *RawExec) {
+		e.apparmorProfile = profile
+	}
+}
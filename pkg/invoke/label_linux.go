@@ -0,0 +1,78 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// selinuxEnabled reports whether selinuxfs is mounted, as it is when
+// SELinux is enabled
+func selinuxEnabled() bool {
+	_, err := os.Stat("/sys/fs/selinux/enforce")
+	return err == nil
+}
+
+func apparmorEnabled() bool {
+	data, err := os.ReadFile("/sys/module/apparmor/parameters/enabled")
+	return err == nil && strings.HasPrefix(string(data), "Y")
+}
+
+// writeThreadAttr writes the LSM attribute of the calling thread
+func writeThreadAttr(path, value string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(value)
+	return err
+}
+
+// selinuxSetup returns the thread setup making the next exec of the
+// thread, or of the processes it starts, enter the label
+func selinuxSetup(label string) func() error {
+	return func() error {
+		if !selinuxEnabled() {
+			return fmt.Errorf("failed to set SELinux label %q: SELinux is not enabled", label)
+		}
+		if err := writeThreadAttr("/proc/thread-self/attr/exec", label); err != nil {
+			return fmt.Errorf("failed to set SELinux label %q: %w", label, err)
+		}
+		return nil
+	}
+}
+
+// apparmorSetup returns the thread setup making the next exec of the
+// thread, or of the processes it starts, change to the profile
+func apparmorSetup(profile string) func() error {
+	return func() error {
+		if !apparmorEnabled() {
+			return fmt.Errorf("failed to set AppArmor profile %q: AppArmor is not enabled", profile)
+		}
+		// Kernels with LSM stacking have an AppArmor specific attribute
+		err := writeThreadAttr("/proc/thread-self/attr/apparmor/exec", "exec "+profile)
+		if errors.Is(err, os.ErrNotExist) {
+			err = writeThreadAttr("/proc/thread-self/attr/exec", "exec "+profile)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set AppArmor profile %q: %w", profile, err)
+		}
+		return nil
+	}
+}
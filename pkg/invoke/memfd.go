@@ -0,0 +1,156 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// MemfdPathPrefix prefixes the plugin paths found by a MemfdExec in its
+// PluginSource rather than on disk
+const MemfdPathPrefix = "memfd:"
+
+// PluginSource provides plugin binaries from somewhere other than the
+// plugin paths, such as assets embedded in the runtime or an OCI layer
+type PluginSource interface {
+	// OpenPlugin returns the binary of the named plugin, or an error
+	// matching fs.ErrNotExist if the source has no such plugin
+	OpenPlugin(name string) (io.ReadCloser, error)
+}
+
+// FSSource is a PluginSource serving the binaries at the root of FS, for
+// example an embed.FS or an fs.FS over an unpacked image layer
+type FSSource struct {
+	FS fs.FS
+}
+
+func (s FSSource) OpenPlugin(name string) (io.ReadCloser, error) {
+	if !fs.ValidPath(name) || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid plugin name: %s", name)
+	}
+	return s.FS.Open(name)
+}
+
+// MemfdExec is an Exec running the plugins it cannot find in the plugin
+// paths from a PluginSource instead, for hosts where the plugin paths are
+// read-only or absent. Such a plugin is copied once into a sealed
+// anonymous memory file (memfd_create) which is then executed. This is
+// only supported on Linux.
+type MemfdExec struct {
+	Exec
+
+	source PluginSource
+	lock   sync.Mutex
+	loaded map[string]*os.File
+}
+
+// NewMemfdExec returns a MemfdExec running plugins through exec and
+// falling back to source for those missing from the plugin paths
+func NewMemfdExec(exec Exec, source PluginSource) *MemfdExec {
+	return &MemfdExec{
+		Exec:   exec,
+		source: source,
+		loaded: make(map[string]*os.File),
+	}
+}
+
+// FindInPath returns the path of the plugin in paths if it is there, or
+// else MemfdPathPrefix followed by its name if the source provides it
+func (e *MemfdExec) FindInPath(plugin string, paths []string) (string, error) {
+	path, err := e.Exec.FindInPath(plugin, paths)
+	if err == nil {
+		return path, nil
+	}
+	binary, srcErr := e.source.OpenPlugin(plugin)
+	if srcErr != nil {
+		if errors.Is(srcErr, fs.ErrNotExist) {
+			return "", err
+		}
+		return "", fmt.Errorf("%v; failed to open plugin %q from source: %w", err, plugin, srcErr)
+	}
+	binary.Close()
+	return MemfdPathPrefix + plugin, nil
+}
+
+// HasProcessOptions reports whether the wrapped Exec sets up the plugin
+// processes it starts
+func (e *MemfdExec) HasProcessOptions() bool {
+	return HasProcessOptions(e.Exec)
+}
+
+func (e *MemfdExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	name, ok := strings.CutPrefix(pluginPath, MemfdPathPrefix)
+	if !ok {
+		return e.Exec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+	}
+	f, err := e.load(name)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, memfdPluginKey{}, name)
+	return e.Exec.ExecPlugin(ctx, fmt.Sprintf("/proc/self/fd/%d", f.Fd()), stdinData, environ)
+}
+
+// memfdPluginKey is the context key of the name of the plugin a MemfdExec
+// runs from a memory file, whose path only names a descriptor
+type memfdPluginKey struct{}
+
+// memfdPluginName returns the name of the plugin a MemfdExec runs with
+// the context, if any
+func memfdPluginName(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(memfdPluginKey{}).(string)
+	return name, ok
+}
+
+// load returns the memory file holding the named plugin, creating it from
+// the source the first time
+func (e *MemfdExec) load(name string) (*os.File, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if f, ok := e.loaded[name]; ok {
+		return f, nil
+	}
+	binary, err := e.source.OpenPlugin(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin %q from source: %w", name, err)
+	}
+	defer binary.Close()
+	f, err := openMemfd(name, binary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plugin %q into memory: %w", name, err)
+	}
+	e.loaded[name] = f
+	return f, nil
+}
+
+// Close releases the memory files of the plugins loaded so far. It must
+// not be called concurrently with ExecPlugin.
+func (e *MemfdExec) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var errs []error
+	for name, f := range e.loaded {
+		errs = append(errs, f.Close())
+		delete(e.loaded, name)
+	}
+	return errors.Join(errs...)
+}
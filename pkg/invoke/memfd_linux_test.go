@@ -0,0 +1,137 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	noop_debug "github.com/containernetworking/cni/plugins/test/noop/debug"
+)
+
+var _ = Describe("MemfdExec", func() {
+	var (
+		debugFileName string
+		environ       []string
+		stdin         []byte
+		pluginName    string
+		execer        *invoke.MemfdExec
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		debugFile, err := os.CreateTemp("", "cni_debug")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debugFile.Close()).To(Succeed())
+		debugFileName = debugFile.Name()
+		Expect((&noop_debug.Debug{ReportResult: `{ "some": "result" }`}).WriteDebug(debugFileName)).To(Succeed())
+
+		environ = []string{
+			"CNI_COMMAND=ADD",
+			"CNI_CONTAINERID=some-container-id",
+			"CNI_ARGS=DEBUG=" + debugFileName,
+			"CNI_NETNS=/some/netns/path",
+			"CNI_PATH=/some/bin/path",
+			"CNI_IFNAME=some-eth0",
+		}
+		stdin = []byte(`{"name": "memfd-test", "some":"stdin-json", "cniVersion": "0.3.1"}`)
+		pluginName = filepath.Base(pathToPlugin)
+		source := invoke.FSSource{FS: os.DirFS(filepath.Dir(pathToPlugin))}
+		execer = invoke.NewMemfdExec(&invoke.DefaultExec{RawExec: &invoke.RawExec{}}, source)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(execer.Close()).To(Succeed())
+		Expect(os.Remove(debugFileName)).To(Succeed())
+	})
+
+	It("prefers plugins found in the plugin paths", func() {
+		path, err := execer.FindInPath(pluginName, []string{filepath.Dir(pathToPlugin)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal(pathToPlugin))
+	})
+
+	It("runs plugins missing from the plugin paths from the source", func() {
+		path, err := execer.FindInPath(pluginName, []string{"/some/missing/path"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(path).To(Equal(invoke.MemfdPathPrefix + pluginName))
+
+		for i := 0; i < 2; i++ {
+			stdout, err := execer.ExecPlugin(ctx, path, stdin, environ)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(MatchJSON(`{ "some": "result" }`))
+		}
+
+		debug, err := noop_debug.ReadDebug(debugFileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debug.Command).To(Equal("ADD"))
+		Expect(debug.CmdArgs.StdinData).To(Equal(stdin))
+	})
+
+	It("exempts, captures and reports plugins from the source by name", func() {
+		captureDir := GinkgoT().TempDir()
+		// the plugin cannot read its debug file when confined
+		profile := &invoke.SeccompProfile{Name: "no-open", Denied: []string{"openat"}}
+		source := invoke.FSSource{FS: os.DirFS(filepath.Dir(pathToPlugin))}
+		newExecer := func(exempt ...string) *invoke.MemfdExec {
+			return invoke.NewMemfdExec(&invoke.DefaultExec{RawExec: invoke.NewRawExec(
+				invoke.WithStderr(nil),
+				invoke.WithSeccomp(profile, exempt...),
+				invoke.WithCapture(captureDir, invoke.CaptureOptions{}),
+			)}, source)
+		}
+		path := invoke.MemfdPathPrefix + pluginName
+
+		confined := newExecer()
+		defer confined.Close()
+		_, err := confined.ExecPlugin(ctx, path, stdin, environ)
+		Expect(err).To(HaveOccurred())
+
+		exempt := newExecer(pluginName)
+		defer exempt.Close()
+		stdout, err := exempt.ExecPlugin(ctx, path, stdin, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(MatchJSON(`{ "some": "result" }`))
+
+		entries, err := os.ReadDir(captureDir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(2))
+		for _, entry := range entries {
+			Expect(entry.Name()).To(ContainSubstring("-" + pluginName + "-ADD-"))
+			data, err := os.ReadFile(filepath.Join(captureDir, entry.Name(), "meta.json"))
+			Expect(err).NotTo(HaveOccurred())
+			meta := invoke.CaptureMeta{}
+			Expect(json.Unmarshal(data, &meta)).To(Succeed())
+			Expect(meta.PluginPath).To(Equal(path))
+		}
+	})
+
+	It("returns the lookup error for plugins the source lacks", func() {
+		_, err := execer.FindInPath("missing-plugin", []string{"/some/missing/path"})
+		Expect(err).To(MatchError(ContainSubstring(`failed to find plugin "missing-plugin"`)))
+	})
+
+	It("rejects plugin names that are not in the source root", func() {
+		_, err := execer.ExecPlugin(ctx, invoke.MemfdPathPrefix+"../"+pluginName, stdin, environ)
+		Expect(err).To(MatchError(ContainSubstring("invalid plugin name")))
+	})
+})
@@ -0,0 +1,27 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"os/exec"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+// startInNetNS starts the command from a thread switched into the network
+// namespace at nsPath, so that the process is created in it
+func startInNetNS(c *exec.Cmd, nsPath string) error {
+	return ns.Do(nsPath, c.Start)
+}
@@ -18,31 +18,135 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/containernetworking/cni/pkg/tracing"
 	"github.com/containernetworking/cni/pkg/types"
 )
 
 type RawExec struct {
 	Stderr io.Writer
+
+	// inNetNS makes plugins start in the network namespace netns, or
+	// in their CNI_NETNS if netns is empty
+	inNetNS bool
+	netns   string
+	// capture, if set, records every execution
+	capture *capturer
+	// maxOutput caps the stdout of plugins; DefaultMaxOutput if zero,
+	// unlimited if negative
+	maxOutput int
+	// seccomp, if set, confines the plugins not in seccompExempt
+	seccomp       *SeccompProfile
+	seccompExempt map[string]bool
+	// selinuxLabel and apparmorProfile, if set, label plugin processes
+	selinuxLabel    string
+	apparmorProfile string
+}
+
+// DefaultMaxOutput is the most a RawExec reads from the stdout of a plugin
+// unless configured otherwise with WithMaxOutput
+const DefaultMaxOutput = 16 << 20
+
+// OutputTooLargeError is returned when a plugin prints more than the
+// maximum output of a RawExec. The plugin's stdout is closed once the
+// maximum is reached.
+type OutputTooLargeError struct {
+	PluginPath string
+	Limit      int
+}
+
+func (e *OutputTooLargeError) Error() string {
+	return fmt.Sprintf("output of plugin %s exceeds %d bytes", e.PluginPath, e.Limit)
+}
+
+// ExecOption configures a RawExec
+type ExecOption func(*RawExec)
+
+// NewRawExec returns a RawExec writing the stderr of plugins to os.Stderr,
+// configured by opts
+func NewRawExec(opts ...ExecOption) *RawExec {
+	e := &RawExec{Stderr: os.Stderr}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithStderr makes a RawExec copy the stderr of successful plugins to w
+func WithStderr(w io.Writer) ExecOption {
+	return func(e *RawExec) {
+		e.Stderr = w
+	}
+}
+
+// WithMaxOutput sets the most a RawExec reads from the stdout of a plugin,
+// DefaultMaxOutput by default. A negative limit disables it.
+func WithMaxOutput(limit int) ExecOption {
+	return func(e *RawExec) {
+		e.maxOutput = limit
+	}
+}
+
+// WithNetNS makes a RawExec start plugin processes in the network
+// namespace at nsPath or, if nsPath is empty, in the CNI_NETNS of each
+// execution. Plugins whose work happens entirely inside the container
+// namespace then need not switch into it themselves. Executions without
+// a CNI_NETNS, such as VERSION or GC, run in the caller's namespace. It
+// is only supported on Linux.
+func WithNetNS(nsPath string) ExecOption {
+	return func(e *RawExec) {
+		e.inNetNS = true
+		e.netns = nsPath
+	}
+}
+
+// HasProcessOptions reports whether the RawExec was given options which
+// apply to the plugin processes it starts: WithNetNS, WithCapture,
+// WithMaxOutput, WithSeccomp, WithSELinuxLabel or WithAppArmorProfile.
+// Plugins run by other means, such as the workers of a daemon.Pool, would
+// escape them.
+func (e *RawExec) HasProcessOptions() bool {
+	return e.inNetNS || e.capture != nil || e.maxOutput != 0 || e.seccomp != nil ||
+		e.selinuxLabel != "" || e.apparmorProfile != ""
 }
 
 func (e *RawExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
-	stdout := &bytes.Buffer{}
+	limit := e.maxOutput
+	if limit == 0 {
+		limit = DefaultMaxOutput
+	}
+	stdout := &limitedBuffer{limit: limit}
 	stderr := &bytes.Buffer{}
 	c := exec.CommandContext(ctx, pluginPath)
+	// A plugin run by a MemfdExec is started, confined and reported under
+	// its name rather than the path of its memory file
+	if name, ok := memfdPluginName(ctx); ok {
+		c.Args[0] = name
+		pluginPath = MemfdPathPrefix + name
+	}
 	c.Env = environ
 	c.Stdin = bytes.NewBuffer(stdinData)
 	c.Stdout = stdout
 	c.Stderr = stderr
+	start := time.Now()
 
 	// Retry the command on "text file busy" errors
 	for i := 0; i <= 5; i++ {
-		err := c.Run()
+		err := e.run(c, pluginPath, environ)
+		if stdout.exceeded {
+			err = &OutputTooLargeError{PluginPath: pluginPath, Limit: limit}
+			if e.capture != nil {
+				e.capture.record(pluginPath, environ, stdinData, stdout.Bytes(), stderr.Bytes(), start, err)
+			}
+			return nil, err
+		}
 
 		// Command succeeded
 		if err == nil {
@@ -57,8 +161,19 @@ func (e *RawExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData [
 		}
 
 		// All other errors except than the busy text file
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			tracing.SpanFromContext(ctx).SetAttributes(tracing.Int(tracing.AttrExitCode, exitErr.ExitCode()))
+		}
+		if e.capture != nil {
+			e.capture.record(pluginPath, environ, stdinData, stdout.Bytes(), stderr.Bytes(), start, err)
+		}
 		return nil, e.pluginErr(err, stdout.Bytes(), stderr.Bytes())
 	}
+	tracing.SpanFromContext(ctx).SetAttributes(tracing.Int(tracing.AttrExitCode, 0))
+	if e.capture != nil {
+		e.capture.record(pluginPath, environ, stdinData, stdout.Bytes(), stderr.Bytes(), start, nil)
+	}
 
 	// Copy stderr to caller's buffer in case plugin printed to both
 	// stdout and stderr for some reason. Ignore failures as stderr is
@@ -69,6 +184,82 @@ func (e *RawExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData [
 	return stdout.Bytes(), nil
 }
 
+// limitedBuffer is a buffer failing writes beyond its limit, unless the
+// limit is negative. The buffer is not embedded so that io.Copy cannot
+// bypass Write through bytes.Buffer.ReadFrom.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+var errOutputLimit = errors.New("output limit exceeded")
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit >= 0 && b.buf.Len()+len(p) > b.limit {
+		n, _ := b.buf.Write(p[:b.limit-b.buf.Len()])
+		b.exceeded = true
+		return n, errOutputLimit
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+// run runs the command, in the network namespace chosen by WithNetNS and
+// confined as chosen by WithSeccomp, WithSELinuxLabel and
+// WithAppArmorProfile
+func (e *RawExec) run(c *exec.Cmd, pluginPath string, environ []string) error {
+	start := c.Start
+	if e.inNetNS {
+		nsPath := e.netns
+		if nsPath == "" {
+			for _, env := range environ {
+				if value, ok := strings.CutPrefix(env, "CNI_NETNS="); ok {
+					nsPath = value
+				}
+			}
+		}
+		if nsPath != "" {
+			start = func() error { return startInNetNS(c, nsPath) }
+		}
+	}
+	setups, err := e.confinement(pluginPath)
+	if err != nil {
+		return err
+	}
+	if len(setups) > 0 {
+		startUnconfined := start
+		start = func() error { return startConfined(setups, startUnconfined) }
+	}
+	if err := start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// confinement returns the setups of the thread starting the plugin which
+// confine it
+func (e *RawExec) confinement(pluginPath string) ([]func() error, error) {
+	var setups []func() error
+	if e.selinuxLabel != "" {
+		setups = append(setups, selinuxSetup(e.selinuxLabel))
+	}
+	if e.apparmorProfile != "" {
+		setups = append(setups, apparmorSetup(e.apparmorProfile))
+	}
+	if profile := e.seccompFor(pluginPath); profile != nil {
+		setup, err := seccompSetup(profile)
+		if err != nil {
+			return nil, err
+		}
+		setups = append(setups, setup)
+	}
+	return setups, nil
+}
+
 func (e *RawExec) pluginErr(err error, stdout, stderr []byte) error {
 	emsg := types.Error{}
 	if len(stdout) == 0 {
@@ -0,0 +1,181 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	noop_debug "github.com/containernetworking/cni/plugins/test/noop/debug"
+)
+
+var _ = Describe("RawExec in a network namespace", func() {
+	var (
+		debugFileName string
+		environ       []string
+		stdin         []byte
+		ctx           context.Context
+	)
+
+	BeforeEach(func() {
+		debugFile, err := os.CreateTemp("", "cni_debug")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debugFile.Close()).To(Succeed())
+		debugFileName = debugFile.Name()
+		Expect((&noop_debug.Debug{ReportResult: `{ "some": "result" }`}).WriteDebug(debugFileName)).To(Succeed())
+
+		environ = []string{
+			"CNI_COMMAND=ADD",
+			"CNI_CONTAINERID=some-container-id",
+			"CNI_ARGS=DEBUG=" + debugFileName,
+			"CNI_NETNS=/some/netns/path",
+			"CNI_PATH=/some/bin/path",
+			"CNI_IFNAME=some-eth0",
+		}
+		stdin = []byte(`{"name": "raw-exec-test", "some":"stdin-json", "cniVersion": "0.3.1"}`)
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(debugFileName)).To(Succeed())
+	})
+
+	It("starts the plugin in the CNI_NETNS of the execution", func() {
+		_, err := invoke.NewRawExec(invoke.WithNetNS("")).ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).To(MatchError(ContainSubstring(`failed to open netns "/some/netns/path"`)))
+
+		debug, err := noop_debug.ReadDebug(debugFileName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(debug.Command).To(BeEmpty())
+	})
+
+	It("starts the plugin in the given namespace", func() {
+		if os.Geteuid() != 0 {
+			Skip("switching network namespaces requires root")
+		}
+		stdout, err := invoke.NewRawExec(invoke.WithNetNS("/proc/self/ns/net")).ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(MatchJSON(`{ "some": "result" }`))
+	})
+
+	It("runs executions without a CNI_NETNS in the caller's namespace", func() {
+		environ = []string{"CNI_COMMAND=VERSION"}
+		stdout, err := invoke.NewRawExec(invoke.WithNetNS("")).ExecPlugin(ctx, pathToPlugin, stdin, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stdout)).To(ContainSubstring("supportedVersions"))
+	})
+})
+
+var _ = Describe("RawExec with seccomp", func() {
+	var (
+		pluginPath string
+		environ    []string
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		// The plugin reports its seccomp state and whether syslog, which
+		// dmesg -S uses, is denied
+		pluginPath = filepath.Join(GinkgoT().TempDir(), "confined")
+		Expect(os.WriteFile(pluginPath, []byte(`#!/bin/sh
+grep -E '^Seccomp:' /proc/self/status
+if dmesg -S >/dev/null 2>&1; then echo "syslog: allowed"; else echo "syslog: denied"; fi
+`), 0o755)).To(Succeed())
+		environ = []string{"CNI_COMMAND=VERSION"}
+		ctx = context.TODO()
+	})
+
+	It("confines plugins with the profile", func() {
+		if _, err := exec.LookPath("dmesg"); err != nil {
+			Skip("dmesg is not installed")
+		}
+		stdout, err := invoke.NewRawExec(invoke.WithSeccomp(invoke.NetworkPluginProfile)).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stdout)).To(MatchRegexp(`Seccomp:\s+2`))
+		Expect(string(stdout)).To(ContainSubstring("syslog: denied"))
+	})
+
+	It("does not confine exempt plugins", func() {
+		stdout, err := invoke.NewRawExec(invoke.WithSeccomp(invoke.NetworkPluginProfile, "confined")).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(stdout)).To(MatchRegexp(`Seccomp:\s+0`))
+	})
+
+	It("leaves the calling process unconfined", func() {
+		_, err := invoke.NewRawExec(invoke.WithSeccomp(invoke.NetworkPluginProfile)).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).NotTo(HaveOccurred())
+		status, err := os.ReadFile("/proc/self/status")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(status)).To(MatchRegexp(`Seccomp:\s+0`))
+	})
+
+	It("rejects profiles denying unknown system calls", func() {
+		profile := &invoke.SeccompProfile{Name: "broken", Denied: []string{"frobnicate"}}
+		_, err := invoke.NewRawExec(invoke.WithSeccomp(profile)).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).To(MatchError(ContainSubstring(`seccomp profile "broken" denies unknown system call "frobnicate"`)))
+	})
+})
+
+var _ = Describe("RawExec with security labels", func() {
+	var (
+		pluginPath string
+		environ    []string
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		pluginPath = filepath.Join(GinkgoT().TempDir(), "labeled")
+		Expect(os.WriteFile(pluginPath, []byte("#!/bin/sh\ncat /proc/self/attr/current\n"), 0o755)).To(Succeed())
+		environ = []string{"CNI_COMMAND=VERSION"}
+		ctx = context.TODO()
+	})
+
+	It("fails when SELinux is not enabled", func() {
+		if _, err := os.Stat("/sys/fs/selinux/enforce"); err == nil {
+			Skip("SELinux is enabled")
+		}
+		_, err := invoke.NewRawExec(invoke.WithSELinuxLabel("system_u:system_r:cni_plugin_t:s0")).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).To(MatchError(ContainSubstring(`failed to set SELinux label "system_u:system_r:cni_plugin_t:s0": SELinux is not enabled`)))
+	})
+
+	It("fails when AppArmor is not enabled", func() {
+		if data, err := os.ReadFile("/sys/module/apparmor/parameters/enabled"); err == nil && strings.HasPrefix(string(data), "Y") {
+			Skip("AppArmor is enabled")
+		}
+		_, err := invoke.NewRawExec(invoke.WithAppArmorProfile("cni-plugin")).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).To(MatchError(ContainSubstring(`failed to set AppArmor profile "cni-plugin": AppArmor is not enabled`)))
+	})
+
+	It("starts the plugin in the SELinux label", func() {
+		if _, err := os.Stat("/sys/fs/selinux/enforce"); err != nil {
+			Skip("SELinux is not enabled")
+		}
+		current, err := os.ReadFile("/proc/self/attr/current")
+		Expect(err).NotTo(HaveOccurred())
+		label := strings.TrimRight(string(current), "\x00\n")
+
+		// The caller's own label needs no transition rule
+		stdout, err := invoke.NewRawExec(invoke.WithSELinuxLabel(label)).ExecPlugin(ctx, pluginPath, nil, environ)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(strings.TrimRight(string(stdout), "\x00\n")).To(Equal(label))
+	})
+})
@@ -17,7 +17,9 @@ package invoke_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -139,6 +141,30 @@ var _ = Describe("RawExec", func() {
 		})
 	})
 
+	Context("when the plugin prints more than the maximum output", func() {
+		BeforeEach(func() {
+			debug.ReportResult = strings.Repeat("x", 4096)
+			Expect(debug.WriteDebug(debugFileName)).To(Succeed())
+		})
+
+		It("returns an OutputTooLargeError", func() {
+			execer = invoke.NewRawExec(invoke.WithMaxOutput(1024))
+			_, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+
+			var tooLarge *invoke.OutputTooLargeError
+			Expect(errors.As(err, &tooLarge)).To(BeTrue())
+			Expect(tooLarge.PluginPath).To(Equal(pathToPlugin))
+			Expect(tooLarge.Limit).To(Equal(1024))
+		})
+
+		It("returns the output when the limit is disabled", func() {
+			execer = invoke.NewRawExec(invoke.WithMaxOutput(-1))
+			resultBytes, err := execer.ExecPlugin(ctx, pathToPlugin, stdin, environ)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resultBytes).To(HaveLen(4096))
+		})
+	})
+
 	Context("when the system is unable to execute the plugin", func() {
 		It("returns the error", func() {
 			_, err := execer.ExecPlugin(ctx, "/tmp/some/invalid/plugin/path", stdin, environ)
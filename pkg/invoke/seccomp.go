@@ -0,0 +1,100 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// SeccompProfile is a seccomp filter confining plugin processes: the
+// system calls they may not make. Plugins must be built for the
+// architecture of the runtime; other system call ABIs are denied
+// altogether.
+type SeccompProfile struct {
+	// Name identifies the profile in errors
+	Name string
+	// Denied are the names of the system calls plugins may not make,
+	// such as "ptrace"
+	Denied []string
+	// Errno is what denied system calls fail with, EPERM if zero
+	Errno syscall.Errno
+}
+
+// NetworkPluginProfile is the default seccomp profile for plugins. It
+// denies system calls which network plugins have no use for and which
+// would let a compromised plugin take over the host beyond its network:
+// loading kernel modules or images, rebooting, tracing other processes,
+// setting the clock, changing the root or the swap, and using the kernel
+// keyring.
+var NetworkPluginProfile = &SeccompProfile{
+	Name: "network plugin",
+	Denied: []string{
+		"acct",
+		"add_key",
+		"adjtimex",
+		"clock_adjtime",
+		"clock_settime",
+		"delete_module",
+		"finit_module",
+		"init_module",
+		"kexec_load",
+		"keyctl",
+		"lookup_dcookie",
+		"name_to_handle_at",
+		"open_by_handle_at",
+		"perf_event_open",
+		"pivot_root",
+		"process_vm_readv",
+		"process_vm_writev",
+		"ptrace",
+		"quotactl",
+		"reboot",
+		"request_key",
+		"settimeofday",
+		"swapoff",
+		"swapon",
+		"syslog",
+		"userfaultfd",
+	},
+}
+
+// WithSeccomp makes a RawExec start plugin processes confined by the
+// seccomp profile, such as NetworkPluginProfile, except for the plugins
+// named in exempt, which are matched against the base name of the
+// plugin's path, or the name of a plugin run by a MemfdExec. The filter is inherited by any process the plugin
+// starts. It is only supported on Linux.
+func WithSeccomp(profile *SeccompProfile, exempt ...string) ExecOption {
+	return func(e *RawExec) {
+		e.seccomp = profile
+		e.seccompExempt = map[string]bool{}
+		for _, name := range exempt {
+			e.seccompExempt[name] = true
+		}
+	}
+}
+
+// seccompFor returns the seccomp profile confining the plugin, or nil
+func (e *RawExec) seccompFor(pluginPath string) *SeccompProfile {
+	if e.seccomp == nil {
+		return nil
+	}
+	name := strings.TrimSuffix(filepath.Base(strings.TrimPrefix(pluginPath, MemfdPathPrefix)), ".exe")
+	if e.seccompExempt[name] {
+		return nil
+	}
+	return e.seccomp
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// The seccomp return actions, which golang.org/x/sys/unix does not define
+const (
+	seccompRetAllow = 0x7fff0000
+	seccompRetErrno = 0x00050000
+)
+
+// x32SyscallBit marks the system calls of the x32 ABI on amd64
+const x32SyscallBit = 0x40000000
+
+// auditArches are the seccomp architectures of the Go architectures
+var auditArches = map[string]uint32{
+	"386":      unix.AUDIT_ARCH_I386,
+	"amd64":    unix.AUDIT_ARCH_X86_64,
+	"arm":      unix.AUDIT_ARCH_ARM,
+	"arm64":    unix.AUDIT_ARCH_AARCH64,
+	"loong64":  unix.AUDIT_ARCH_LOONGARCH64,
+	"mips":     unix.AUDIT_ARCH_MIPS,
+	"mipsle":   unix.AUDIT_ARCH_MIPSEL,
+	"mips64":   unix.AUDIT_ARCH_MIPS64,
+	"mips64le": unix.AUDIT_ARCH_MIPSEL64,
+	"ppc64":    unix.AUDIT_ARCH_PPC64,
+	"ppc64le":  unix.AUDIT_ARCH_PPC64LE,
+	"riscv64":  unix.AUDIT_ARCH_RISCV64,
+	"s390x":    unix.AUDIT_ARCH_S390X,
+}
+
+// syscallNumbers are the system calls seccomp profiles may deny
+var syscallNumbers = map[string]uint32{
+	"acct":              unix.SYS_ACCT,
+	"add_key":           unix.SYS_ADD_KEY,
+	"adjtimex":          unix.SYS_ADJTIMEX,
+	"bpf":               unix.SYS_BPF,
+	"clock_adjtime":     unix.SYS_CLOCK_ADJTIME,
+	"clock_settime":     unix.SYS_CLOCK_SETTIME,
+	"delete_module":     unix.SYS_DELETE_MODULE,
+	"finit_module":      unix.SYS_FINIT_MODULE,
+	"init_module":       unix.SYS_INIT_MODULE,
+	"kexec_load":        unix.SYS_KEXEC_LOAD,
+	"keyctl":            unix.SYS_KEYCTL,
+	"lookup_dcookie":    unix.SYS_LOOKUP_DCOOKIE,
+	"mount":             unix.SYS_MOUNT,
+	"name_to_handle_at": unix.SYS_NAME_TO_HANDLE_AT,
+	"open_by_handle_at": unix.SYS_OPEN_BY_HANDLE_AT,
+	"perf_event_open":   unix.SYS_PERF_EVENT_OPEN,
+	"pivot_root":        unix.SYS_PIVOT_ROOT,
+	"process_vm_readv":  unix.SYS_PROCESS_VM_READV,
+	"process_vm_writev": unix.SYS_PROCESS_VM_WRITEV,
+	"ptrace":            unix.SYS_PTRACE,
+	"quotactl":          unix.SYS_QUOTACTL,
+	"reboot":            unix.SYS_REBOOT,
+	"request_key":       unix.SYS_REQUEST_KEY,
+	"setns":             unix.SYS_SETNS,
+	"settimeofday":      unix.SYS_SETTIMEOFDAY,
+	"swapoff":           unix.SYS_SWAPOFF,
+	"swapon":            unix.SYS_SWAPON,
+	"syslog":            unix.SYS_SYSLOG,
+	"unshare":           unix.SYS_UNSHARE,
+	"userfaultfd":       unix.SYS_USERFAULTFD,
+}
+
+// compileSeccomp returns the BPF program of the profile: it fails the
+// denied system calls, and all those of other architectures, with the
+// profile's errno and allows the rest
+func compileSeccomp(profile *SeccompProfile) ([]unix.SockFilter, error) {
+	arch, ok := auditArches[runtime.GOARCH]
+	if !ok {
+		return nil, fmt.Errorf("seccomp is not supported on %s", runtime.GOARCH)
+	}
+	// jumps are relative and at most 255 instructions long
+	if len(profile.Denied) > 250 {
+		return nil, fmt.Errorf("seccomp profile %q denies too many system calls", profile.Name)
+	}
+	errno := profile.Errno
+	if errno == 0 {
+		errno = unix.EPERM
+	}
+
+	deny := unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: seccompRetErrno | uint32(errno)}
+	prog := []unix.SockFilter{
+		// seccomp_data.arch
+		{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: 4},
+		{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: 1, K: arch},
+		deny,
+		// seccomp_data.nr
+		{Code: unix.BPF_LD | unix.BPF_W | unix.BPF_ABS, K: 0},
+	}
+	if runtime.GOARCH == "amd64" {
+		prog = append(prog,
+			unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K, Jf: 1, K: x32SyscallBit},
+			deny,
+		)
+	}
+	for i, name := range profile.Denied {
+		nr, ok := syscallNumbers[name]
+		if !ok {
+			return nil, fmt.Errorf("seccomp profile %q denies unknown system call %q", profile.Name, name)
+		}
+		// jump over the remaining comparisons and the allow
+		jt := len(profile.Denied) - i
+		prog = append(prog, unix.SockFilter{Code: unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K, Jt: uint8(jt), K: nr})
+	}
+	return append(prog,
+		unix.SockFilter{Code: unix.BPF_RET | unix.BPF_K, K: seccompRetAllow},
+		deny,
+	), nil
+}
+
+// seccompSetup returns the thread setup installing the profile. Without
+// the privilege to install filters, it sets no_new_privs first, which
+// prevents SELinux and AppArmor transitions on exec that are not bounded.
+func seccompSetup(profile *SeccompProfile) (func() error, error) {
+	prog, err := compileSeccomp(profile)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		fprog := unix.SockFprog{Len: uint16(len(prog)), Filter: &prog[0]}
+		install := func() error {
+			return unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&fprog)), 0, 0)
+		}
+		err := install()
+		if errors.Is(err, unix.EACCES) {
+			if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+				return fmt.Errorf("failed to set no_new_privs: %w", err)
+			}
+			err = install()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to install seccomp profile %q: %w", profile.Name, err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// VersionCache caches the VERSION response of plugin binaries, so that
+// busy runtimes do not execute each plugin's VERSION command for every
+// operation. Entries are keyed by the binary's path and identified by its
+// file identity, size and modification time, so a plugin which is
+// replaced on disk is asked again. A nil *VersionCache caches nothing.
+type VersionCache struct {
+	lock    sync.Mutex
+	entries map[string]versionCacheEntry
+}
+
+type versionCacheEntry struct {
+	info os.FileInfo
+	vi   version.PluginInfo
+}
+
+// NewVersionCache returns an empty VersionCache
+func NewVersionCache() *VersionCache {
+	return &VersionCache{entries: make(map[string]versionCacheEntry)}
+}
+
+// GetVersionInfo returns the cached version information of the plugin
+// binary at pluginPath, calling GetVersionInfo if there is no entry or
+// the binary changed since it was cached. Errors are not cached.
+func (c *VersionCache) GetVersionInfo(ctx context.Context, pluginPath string, exec Exec) (version.PluginInfo, error) {
+	if c == nil {
+		return GetVersionInfo(ctx, pluginPath, exec)
+	}
+
+	info, err := os.Stat(pluginPath)
+	if err != nil {
+		// Nothing to identify the binary by; don't cache
+		return GetVersionInfo(ctx, pluginPath, exec)
+	}
+
+	c.lock.Lock()
+	entry, ok := c.entries[pluginPath]
+	c.lock.Unlock()
+	if ok && sameBinary(entry.info, info) {
+		return entry.vi, nil
+	}
+
+	vi, err := GetVersionInfo(ctx, pluginPath, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	c.entries[pluginPath] = versionCacheEntry{info: info, vi: vi}
+	c.lock.Unlock()
+	return vi, nil
+}
+
+// Flush removes all entries from the cache
+func (c *VersionCache) Flush() {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	c.entries = make(map[string]versionCacheEntry)
+	c.lock.Unlock()
+}
+
+func sameBinary(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
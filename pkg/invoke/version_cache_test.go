@@ -0,0 +1,119 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package invoke_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/invoke"
+	"github.com/containernetworking/cni/pkg/invoke/fakes"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// countingExec counts the number of times a plugin is executed
+type countingExec struct {
+	*fakes.RawExec
+	*fakes.VersionDecoder
+	calls int
+}
+
+func (e *countingExec) ExecPlugin(ctx context.Context, pluginPath string, stdinData []byte, environ []string) ([]byte, error) {
+	e.calls++
+	return e.RawExec.ExecPlugin(ctx, pluginPath, stdinData, environ)
+}
+
+var _ = Describe("Caching plugin versions", func() {
+	var (
+		pluginExec *countingExec
+		pluginDir  string
+		pluginPath string
+		cache      *invoke.VersionCache
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		pluginExec = &countingExec{
+			RawExec:        &fakes.RawExec{},
+			VersionDecoder: &fakes.VersionDecoder{},
+		}
+		pluginExec.RawExec.ExecPluginCall.Returns.ResultBytes = []byte(`{}`)
+		pluginExec.VersionDecoder.DecodeCall.Returns.PluginInfo = version.PluginSupports("1.0.0", "1.1.0")
+
+		var err error
+		pluginDir, err = os.MkdirTemp("", "cni-version-cache")
+		Expect(err).NotTo(HaveOccurred())
+		pluginPath = filepath.Join(pluginDir, "plugin")
+		Expect(os.WriteFile(pluginPath, []byte("#!/bin/sh\n"), 0o755)).To(Succeed())
+
+		cache = invoke.NewVersionCache()
+		ctx = context.TODO()
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(pluginDir)).To(Succeed())
+	})
+
+	It("only executes VERSION once for an unchanged binary", func() {
+		for i := 0; i < 3; i++ {
+			vi, err := cache.GetVersionInfo(ctx, pluginPath, pluginExec)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vi.SupportedVersions()).To(Equal([]string{"1.0.0", "1.1.0"}))
+		}
+		Expect(pluginExec.calls).To(Equal(1))
+	})
+
+	It("asks again when the binary changes", func() {
+		_, err := cache.GetVersionInfo(ctx, pluginPath, pluginExec)
+		Expect(err).NotTo(HaveOccurred())
+
+		later := time.Now().Add(time.Hour)
+		Expect(os.Chtimes(pluginPath, later, later)).To(Succeed())
+		_, err = cache.GetVersionInfo(ctx, pluginPath, pluginExec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pluginExec.calls).To(Equal(2))
+	})
+
+	It("asks again after a flush", func() {
+		_, err := cache.GetVersionInfo(ctx, pluginPath, pluginExec)
+		Expect(err).NotTo(HaveOccurred())
+		cache.Flush()
+		_, err = cache.GetVersionInfo(ctx, pluginPath, pluginExec)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pluginExec.calls).To(Equal(2))
+	})
+
+	It("does not cache binaries which cannot be found", func() {
+		for i := 0; i < 2; i++ {
+			_, err := cache.GetVersionInfo(ctx, "/does/not/exist", pluginExec)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(pluginExec.calls).To(Equal(2))
+	})
+
+	It("does not cache when nil", func() {
+		var nilCache *invoke.VersionCache
+		for i := 0; i < 2; i++ {
+			_, err := nilCache.GetVersionInfo(ctx, pluginPath, pluginExec)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(pluginExec.calls).To(Equal(2))
+	})
+})
@@ -0,0 +1,153 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8sargs parses the CNI_ARGS that Kubernetes runtimes pass to
+// plugins, so that plugins need not each declare them.
+//
+// Plugins that take further CNI_ARGS embed Args in their own arguments,
+// instead of types.CommonArgs, which Args already embeds:
+//
+//	type MyArgs struct {
+//		k8sargs.Args
+//		IP types.UnmarshallableString
+//	}
+//
+//	args := MyArgs{}
+//	err := types.LoadArgs(cmdArgs.Args, &args)
+package k8sargs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// Keys of the Kubernetes arguments in CNI_ARGS
+const (
+	KeyPodName             = "K8S_POD_NAME"
+	KeyPodNamespace        = "K8S_POD_NAMESPACE"
+	KeyPodUID              = "K8S_POD_UID"
+	KeyPodInfraContainerID = "K8S_POD_INFRA_CONTAINER_ID"
+)
+
+var (
+	dns1123Label     = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	dns1123Subdomain = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// PodNamespace is the namespace of a pod, which Kubernetes requires to be
+// a DNS-1123 label
+type PodNamespace string
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (n *PodNamespace) UnmarshalText(data []byte) error {
+	if len(data) > 63 || !dns1123Label.Match(data) {
+		return fmt.Errorf("invalid pod namespace %q: not a DNS-1123 label", data)
+	}
+	*n = PodNamespace(data)
+	return nil
+}
+
+// PodName is the name of a pod, which Kubernetes requires to be a DNS-1123
+// subdomain
+type PodName string
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface
+func (n *PodName) UnmarshalText(data []byte) error {
+	if len(data) > 253 || !dns1123Subdomain.Match(data) {
+		return fmt.Errorf("invalid pod name %q: not a DNS-1123 subdomain", data)
+	}
+	*n = PodName(data)
+	return nil
+}
+
+// Args are the arguments Kubernetes runtimes pass in CNI_ARGS. Runtimes
+// also pass IgnoreUnknown=1, so that plugins accept arguments they do not
+// know; without it, Load rejects them as types.LoadArgs does.
+type Args struct {
+	types.CommonArgs
+	K8S_POD_NAME               PodName
+	K8S_POD_NAMESPACE          PodNamespace
+	K8S_POD_UID                types.UnmarshallableString
+	K8S_POD_INFRA_CONTAINER_ID types.UnmarshallableString
+}
+
+// Load parses the Kubernetes arguments from a CNI_ARGS string
+func Load(cniArgs string) (*Args, error) {
+	args := &Args{}
+	if err := types.LoadArgs(cniArgs, args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// Namespace returns the pod's namespace
+func (a *Args) Namespace() string {
+	return string(a.K8S_POD_NAMESPACE)
+}
+
+// Name returns the pod's name
+func (a *Args) Name() string {
+	return string(a.K8S_POD_NAME)
+}
+
+// UID returns the pod's UID
+func (a *Args) UID() string {
+	return string(a.K8S_POD_UID)
+}
+
+// InfraContainerID returns the ID of the pod's infra (sandbox) container
+func (a *Args) InfraContainerID() string {
+	return string(a.K8S_POD_INFRA_CONTAINER_ID)
+}
+
+// PodRef returns the pod as "namespace/name"
+func (a *Args) PodRef() string {
+	return a.Namespace() + "/" + a.Name()
+}
+
+// RequirePod returns an error unless the pod's namespace and name are set,
+// for plugins that only work with Kubernetes
+func (a *Args) RequirePod() error {
+	var missing []string
+	if a.K8S_POD_NAMESPACE == "" {
+		missing = append(missing, KeyPodNamespace)
+	}
+	if a.K8S_POD_NAME == "" {
+		missing = append(missing, KeyPodName)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("ARGS: missing Kubernetes args %q", missing)
+	}
+	return nil
+}
+
+// Pairs returns the arguments as a runtime passes them, in
+// libcni.RuntimeConf.Args, with IgnoreUnknown=1 first. Unset arguments are
+// left out.
+func (a *Args) Pairs() [][2]string {
+	pairs := [][2]string{{"IgnoreUnknown", "1"}}
+	for _, kv := range [][2]string{
+		{KeyPodNamespace, a.Namespace()},
+		{KeyPodName, a.Name()},
+		{KeyPodInfraContainerID, a.InfraContainerID()},
+		{KeyPodUID, a.UID()},
+	} {
+		if kv[1] != "" {
+			pairs = append(pairs, kv)
+		}
+	}
+	return pairs
+}
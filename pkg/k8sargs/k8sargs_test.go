@@ -0,0 +1,86 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sargs_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/k8sargs"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+const kubeletArgs = "IgnoreUnknown=1;K8S_POD_NAMESPACE=kube-system;K8S_POD_NAME=coredns-5d78c9869d-9xk2p;" +
+	"K8S_POD_INFRA_CONTAINER_ID=8a3f1c;K8S_POD_UID=0b5e7b0e-8c1a-4b7e-9d6f-2f1e3c4d5a6b"
+
+var _ = Describe("Kubernetes args", func() {
+	It("parses the args passed by Kubernetes runtimes", func() {
+		args, err := k8sargs.Load(kubeletArgs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.Namespace()).To(Equal("kube-system"))
+		Expect(args.Name()).To(Equal("coredns-5d78c9869d-9xk2p"))
+		Expect(args.UID()).To(Equal("0b5e7b0e-8c1a-4b7e-9d6f-2f1e3c4d5a6b"))
+		Expect(args.InfraContainerID()).To(Equal("8a3f1c"))
+		Expect(args.PodRef()).To(Equal("kube-system/coredns-5d78c9869d-9xk2p"))
+		Expect(args.RequirePod()).To(Succeed())
+	})
+
+	It("ignores unknown args when IgnoreUnknown is set", func() {
+		args, err := k8sargs.Load("IgnoreUnknown=true;K8S_POD_NAME=web;FOO=bar")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.Name()).To(Equal("web"))
+	})
+
+	It("rejects unknown args without IgnoreUnknown", func() {
+		_, err := k8sargs.Load("K8S_POD_NAME=web;FOO=bar")
+		Expect(err).To(MatchError(`ARGS: unknown args ["FOO=bar"]`))
+	})
+
+	It("rejects invalid pod names and namespaces", func() {
+		_, err := k8sargs.Load("K8S_POD_NAMESPACE=Kube_System")
+		Expect(err).To(MatchError(ContainSubstring(`invalid pod namespace "Kube_System"`)))
+
+		_, err = k8sargs.Load("K8S_POD_NAME=-web")
+		Expect(err).To(MatchError(ContainSubstring(`invalid pod name "-web"`)))
+	})
+
+	It("reports missing pod args", func() {
+		args, err := k8sargs.Load("K8S_POD_UID=1234")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.RequirePod()).To(MatchError(`ARGS: missing Kubernetes args ["K8S_POD_NAMESPACE" "K8S_POD_NAME"]`))
+	})
+
+	It("parses args embedded in a plugin's args", func() {
+		var args struct {
+			k8sargs.Args
+			IP types.UnmarshallableString
+		}
+		Expect(types.LoadArgs(kubeletArgs+";IP=10.1.2.3", &args)).To(Succeed())
+		Expect(args.Name()).To(Equal("coredns-5d78c9869d-9xk2p"))
+		Expect(string(args.IP)).To(Equal("10.1.2.3"))
+	})
+
+	It("returns the args a runtime passes", func() {
+		args, err := k8sargs.Load(kubeletArgs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.Pairs()).To(Equal([][2]string{
+			{"IgnoreUnknown", "1"},
+			{"K8S_POD_NAMESPACE", "kube-system"},
+			{"K8S_POD_NAME", "coredns-5d78c9869d-9xk2p"},
+			{"K8S_POD_INFRA_CONTAINER_ID", "8a3f1c"},
+			{"K8S_POD_UID", "0b5e7b0e-8c1a-4b7e-9d6f-2f1e3c4d5a6b"},
+		}))
+	})
+})
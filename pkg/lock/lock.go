@@ -0,0 +1,157 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lock provides advisory file locks shared between processes, such
+// as concurrent invocations of an IPAM plugin, using flock(2) on Unix and
+// LockFileEx on Windows.
+//
+// The process holding a lock records its PID in the lock file, so that a
+// process waiting for the lock can tell who holds it, and whether the
+// holder has exited while the lock is still held, as happens when the lock
+// file's descriptor leaked to a child process that outlives it.
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PollInterval is how often Lock tries to take a lock held by another
+// process
+var PollInterval = 10 * time.Millisecond
+
+// FileLock is an advisory lock on a file. It is not safe for concurrent
+// use; each goroutine should use its own FileLock.
+type FileLock struct {
+	path string
+	file *os.File
+}
+
+// Holder is the process recorded as holding a lock
+type Holder struct {
+	PID int
+	// Stale is true if the process has exited, so the lock file's
+	// descriptor is held by another process, typically one of its children
+	Stale bool
+}
+
+// New returns a lock on the file at path, which is created with its parent
+// directories if needed
+func New(path string) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileLock{path: path, file: file}, nil
+}
+
+// Path returns the lock file's path
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+// TryLock takes the lock if no other process holds it, and reports whether
+// it did
+func (l *FileLock) TryLock() (bool, error) {
+	locked, err := tryLock(l.file)
+	if err != nil || !locked {
+		return false, err
+	}
+	if err := l.recordHolder(); err != nil {
+		_ = unlock(l.file)
+		return false, err
+	}
+	return true, nil
+}
+
+// Lock waits until it takes the lock or ctx is done. If ctx is done first,
+// the error says which process holds the lock. A lock whose holder is
+// stale is not broken: the lock file's descriptor is still open in a live
+// process, which may rely on the lock, so callers that must not block
+// forever need a ctx with a deadline.
+func (l *FileLock) Lock(ctx context.Context) error {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		locked, err := l.TryLock()
+		if err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return l.waitError(ctx.Err())
+		}
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	return unlock(l.file)
+}
+
+// Close releases the lock, if held, and closes the lock file
+func (l *FileLock) Close() error {
+	return l.file.Close()
+}
+
+// Holder returns the process that last took the lock, or nil if none did.
+// The lock may since have been released.
+func (l *FileLock) Holder() (*Holder, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return nil, nil
+	}
+	pid, err := strconv.Atoi(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lock file %s: %w", l.path, err)
+	}
+	return &Holder{PID: pid, Stale: !processExists(pid)}, nil
+}
+
+func (l *FileLock) recordHolder() error {
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := l.file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+func (l *FileLock) waitError(err error) error {
+	holder, _ := l.Holder()
+	switch {
+	case holder == nil:
+		return fmt.Errorf("failed to lock %s: %w", l.path, err)
+	case holder.Stale:
+		return fmt.Errorf("failed to lock %s: held by exited process %d, possibly through a child: %w", l.path, holder.PID, err)
+	default:
+		return fmt.Errorf("failed to lock %s: held by process %d: %w", l.path, holder.PID, err)
+	}
+}
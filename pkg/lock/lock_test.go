@@ -0,0 +1,118 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/lock"
+)
+
+var _ = Describe("FileLock", func() {
+	var (
+		path         string
+		first, other *lock.FileLock
+	)
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "some", "dir", "lock")
+
+		var err error
+		first, err = lock.New(path)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = first.Close() })
+		// flock locks belong to an open file, so a second FileLock in
+		// the same process contends like another process would
+		other, err = lock.New(path)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(func() { _ = other.Close() })
+	})
+
+	It("is taken by one FileLock at a time", func() {
+		Expect(first.Lock(context.TODO())).To(Succeed())
+
+		locked, err := other.TryLock()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(locked).To(BeFalse())
+
+		Expect(first.Unlock()).To(Succeed())
+		locked, err = other.TryLock()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(locked).To(BeTrue())
+	})
+
+	It("waits for the lock to be released", func() {
+		Expect(first.Lock(context.TODO())).To(Succeed())
+		unlocked := make(chan error)
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			unlocked <- first.Unlock()
+		}()
+
+		Expect(other.Lock(context.TODO())).To(Succeed())
+		Expect(<-unlocked).To(Succeed())
+	})
+
+	It("is released when closed", func() {
+		Expect(first.Lock(context.TODO())).To(Succeed())
+		Expect(first.Close()).To(Succeed())
+
+		locked, err := other.TryLock()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(locked).To(BeTrue())
+	})
+
+	It("records its holder", func() {
+		holder, err := first.Holder()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(holder).To(BeNil())
+
+		Expect(first.Lock(context.TODO())).To(Succeed())
+		holder, err = other.Holder()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(holder).To(Equal(&lock.Holder{PID: os.Getpid()}))
+	})
+
+	It("says who holds the lock when giving up", func() {
+		Expect(first.Lock(context.TODO())).To(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+		err := other.Lock(ctx)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("held by process %d", os.Getpid()))))
+	})
+
+	It("detects a lock held on behalf of an exited process", func() {
+		Expect(first.Lock(context.TODO())).To(Succeed())
+		// no process has this PID, as it exceeds the Linux maximum
+		Expect(os.WriteFile(path, []byte("1073741823\n"), 0o600)).To(Succeed())
+
+		holder, err := other.Holder()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(holder).To(Equal(&lock.Holder{PID: 1073741823, Stale: true}))
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 50*time.Millisecond)
+		defer cancel()
+		Expect(other.Lock(ctx)).To(MatchError(ContainSubstring("held by exited process 1073741823")))
+	})
+})
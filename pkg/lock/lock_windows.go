@@ -0,0 +1,61 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lock
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows locks are mandatory, so the lock covers a byte past the PID
+// recorded in the file, which other processes can still read
+const (
+	lockOffsetHigh = 0x7fffffff
+	lockLength     = 1
+)
+
+func tryLock(file *os.File) (bool, error) {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	err := windows.LockFileEx(windows.Handle(file.Fd()),
+		windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, lockLength, 0, ol)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, windows.ERROR_LOCK_VIOLATION):
+		return false, nil
+	default:
+		return false, &os.PathError{Op: "LockFileEx", Path: file.Name(), Err: err}
+	}
+}
+
+func unlock(file *os.File) error {
+	ol := &windows.Overlapped{OffsetHigh: lockOffsetHigh}
+	if err := windows.UnlockFileEx(windows.Handle(file.Fd()), 0, lockLength, 0, ol); err != nil {
+		return &os.PathError{Op: "UnlockFileEx", Path: file.Name(), Err: err}
+	}
+	return nil
+}
+
+func processExists(pid int) bool {
+	h, err := windows.OpenProcess(windows.SYNCHRONIZE, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(h)
+	event, err := windows.WaitForSingleObject(h, 0)
+	return err == nil && event == uint32(windows.WAIT_TIMEOUT)
+}
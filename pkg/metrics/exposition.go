@@ -0,0 +1,180 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// family holds the labelled series of one metric
+type family struct {
+	name   string
+	help   string
+	labels []string
+}
+
+// key returns the key of the series with the given label values
+func key(values []string) string {
+	return strings.Join(values, "\xff")
+}
+
+// valueVec is a counter or gauge
+type valueVec struct {
+	family
+	metricType string
+	series     map[string]*valueSeries
+}
+
+type valueSeries struct {
+	values []string
+	value  float64
+}
+
+func newCounterVec(name, help string, labels ...string) *valueVec {
+	return &valueVec{family{name, help, labels}, "counter", map[string]*valueSeries{}}
+}
+
+func newGaugeVec(name, help string, labels ...string) *valueVec {
+	return &valueVec{family{name, help, labels}, "gauge", map[string]*valueSeries{}}
+}
+
+func (v *valueVec) add(delta float64, values ...string) {
+	s, ok := v.series[key(values)]
+	if !ok {
+		s = &valueSeries{values: values}
+		v.series[key(values)] = s
+	}
+	s.value += delta
+}
+
+func (v *valueVec) inc(values ...string) {
+	v.add(1, values...)
+}
+
+func (v *valueVec) write(w *expositionWriter) {
+	w.header(&v.family, v.metricType)
+	for _, k := range sortedKeys(v.series) {
+		s := v.series[k]
+		w.sample(v.name, v.labels, s.values, "", "", s.value)
+	}
+}
+
+type histogramVec struct {
+	family
+	buckets []float64
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	values []string
+	// counts[i] is the number of observations in buckets[i], and not in
+	// a lower bucket; the last count is for those above every bucket
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{family{name, help, labels}, buckets, map[string]*histogramSeries{}}
+}
+
+func (v *histogramVec) observe(value float64, values ...string) {
+	s, ok := v.series[key(values)]
+	if !ok {
+		s = &histogramSeries{values: values, counts: make([]uint64, len(v.buckets)+1)}
+		v.series[key(values)] = s
+	}
+	s.counts[sort.SearchFloat64s(v.buckets, value)]++
+	s.sum += value
+	s.count++
+}
+
+func (v *histogramVec) write(w *expositionWriter) {
+	w.header(&v.family, "histogram")
+	for _, k := range sortedKeys(v.series) {
+		s := v.series[k]
+		var cumulative uint64
+		for i, bound := range v.buckets {
+			cumulative += s.counts[i]
+			w.sample(v.name+"_bucket", v.labels, s.values, "le", formatFloat(bound), float64(cumulative))
+		}
+		w.sample(v.name+"_bucket", v.labels, s.values, "le", "+Inf", float64(s.count))
+		w.sample(v.name+"_sum", v.labels, s.values, "", "", s.sum)
+		w.sample(v.name+"_count", v.labels, s.values, "", "", float64(s.count))
+	}
+}
+
+func sortedKeys[T any](series map[string]T) []string {
+	keys := make([]string, 0, len(series))
+	for k := range series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// expositionWriter writes the Prometheus text format, keeping the first
+// error and the number of bytes written
+type expositionWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *expositionWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
+
+func (w *expositionWriter) header(f *family, metricType string) {
+	w.printf("# HELP %s %s\n", f.name, f.help)
+	w.printf("# TYPE %s %s\n", f.name, metricType)
+}
+
+// sample writes a sample, with an extra label if extraName is set
+func (w *expositionWriter) sample(name string, labels, values []string, extraName, extraValue string, value float64) {
+	pairs := make([]string, 0, len(labels)+1)
+	for i, label := range labels {
+		pairs = append(pairs, label+`="`+labelEscaper.Replace(values[i])+`"`)
+	}
+	if extraName != "" {
+		pairs = append(pairs, extraName+`="`+labelEscaper.Replace(extraValue)+`"`)
+	}
+	if len(pairs) > 0 {
+		name += "{" + strings.Join(pairs, ",") + "}"
+	}
+	w.printf("%s %s\n", name, formatFloat(value))
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
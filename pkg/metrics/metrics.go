@@ -0,0 +1,190 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides Prometheus metrics for CNI runtimes and plugins,
+// so that agents share metric names instead of each designing their own.
+//
+// A Collector plugs into the libcni and skel hooks and serves its metrics
+// in the Prometheus text format:
+//
+//	collector := metrics.NewCollector()
+//	collector.WatchCache(cniConfig)
+//	cniConfig.Metrics = collector
+//	http.Handle("/metrics", collector)
+//
+// A runtime exposes these metrics:
+//
+//	cni_operation_duration_seconds{operation,network}  histogram
+//	cni_operation_errors_total{operation,network,code} counter
+//	cni_plugin_duration_seconds{plugin,command}        histogram
+//	cni_plugin_errors_total{plugin,command,code}       counter
+//	cni_cached_attachments{network}                    gauge
+//
+// A plugin passing its Collector to skel.WithObserver records the commands it
+// runs in the cni_plugin_* metrics, with itself as the plugin. As a plugin
+// process handles a single command, it usually writes them with WriteTo to
+// a file collected by the node exporter.
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// DurationBuckets are the upper bounds, in seconds, of the duration
+// histograms' buckets. Plugins that set up interfaces commonly take tens
+// of milliseconds to seconds.
+var DurationBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// CodeNotCNI is the code label of errors that are not CNI errors, such as
+// a plugin that could not be found
+const CodeNotCNI = "none"
+
+// AttachmentLister lists cached attachments, as libcni.CNIConfig does
+type AttachmentLister interface {
+	GetCachedAttachments(containerID string) ([]*libcni.NetworkAttachment, error)
+}
+
+// Collector collects CNI metrics. It implements libcni.Metrics and
+// skel.CommandObserver, and is safe for concurrent use.
+type Collector struct {
+	mu sync.Mutex
+
+	operationDuration *histogramVec
+	operationErrors   *valueVec
+	pluginDuration    *histogramVec
+	pluginErrors      *valueVec
+
+	caches []AttachmentLister
+}
+
+var (
+	_ libcni.Metrics       = &Collector{}
+	_ skel.CommandObserver = &Collector{}
+	_ http.Handler         = &Collector{}
+)
+
+// NewCollector returns a Collector with no samples
+func NewCollector() *Collector {
+	return &Collector{
+		operationDuration: newHistogramVec("cni_operation_duration_seconds",
+			"Duration of CNI network list operations.", DurationBuckets, "operation", "network"),
+		operationErrors: newCounterVec("cni_operation_errors_total",
+			"Number of failed CNI network list operations, by CNI error code.", "operation", "network", "code"),
+		pluginDuration: newHistogramVec("cni_plugin_duration_seconds",
+			"Duration of CNI plugin executions.", DurationBuckets, "plugin", "command"),
+		pluginErrors: newCounterVec("cni_plugin_errors_total",
+			"Number of failed CNI plugin executions, by CNI error code.", "plugin", "command", "code"),
+	}
+}
+
+// WatchCache makes the Collector report the number of attachments cached
+// by cache, per network, whenever it is scraped
+func (c *Collector) WatchCache(cache AttachmentLister) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.caches = append(c.caches, cache)
+}
+
+// ObserveOperation records a network list operation
+func (c *Collector) ObserveOperation(operation, network string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.operationDuration.observe(duration.Seconds(), operation, network)
+	if err != nil {
+		c.operationErrors.inc(operation, network, errorCode(err))
+	}
+}
+
+// ObservePlugin records a plugin execution
+func (c *Collector) ObservePlugin(pluginType, command string, duration time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pluginDuration.observe(duration.Seconds(), pluginType, command)
+	if err != nil {
+		c.pluginErrors.inc(pluginType, command, errorCode(err))
+	}
+}
+
+// ObserveCommand records a command run by the plugin this process is, as
+// a plugin execution
+func (c *Collector) ObserveCommand(command string, duration time.Duration, err error) {
+	c.ObservePlugin(filepath.Base(os.Args[0]), command, duration, err)
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	cached := c.cachedAttachments()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ew := &expositionWriter{w: w}
+	c.operationDuration.write(ew)
+	c.operationErrors.write(ew)
+	c.pluginDuration.write(ew)
+	c.pluginErrors.write(ew)
+	if cached != nil {
+		cached.write(ew)
+	}
+	return ew.n, ew.err
+}
+
+// ServeHTTP serves the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// cachedAttachments counts the attachments of the watched caches, or
+// returns nil if none are watched. A cache that cannot be read is skipped,
+// so that the other metrics are still served.
+func (c *Collector) cachedAttachments() *valueVec {
+	c.mu.Lock()
+	caches := append([]AttachmentLister(nil), c.caches...)
+	c.mu.Unlock()
+	if len(caches) == 0 {
+		return nil
+	}
+
+	gauge := newGaugeVec("cni_cached_attachments",
+		"Number of attachments in the CNI result cache.", "network")
+	for _, cache := range caches {
+		attachments, err := cache.GetCachedAttachments("")
+		if err != nil {
+			continue
+		}
+		for _, attachment := range attachments {
+			gauge.add(1, attachment.Network)
+		}
+	}
+	return gauge
+}
+
+func errorCode(err error) string {
+	var cniErr *types.Error
+	if errors.As(err, &cniErr) {
+		return strconv.FormatUint(uint64(cniErr.Code), 10)
+	}
+	return CodeNotCNI
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/metrics"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+func scrape(collector *metrics.Collector) string {
+	var buf bytes.Buffer
+	_, err := collector.WriteTo(&buf)
+	Expect(err).NotTo(HaveOccurred())
+	return buf.String()
+}
+
+var _ = Describe("Collector", func() {
+	var collector *metrics.Collector
+
+	BeforeEach(func() {
+		collector = metrics.NewCollector()
+	})
+
+	It("writes histograms of operation durations", func() {
+		collector.ObserveOperation("AddNetworkList", "net1", 30*time.Millisecond, nil)
+		collector.ObserveOperation("AddNetworkList", "net1", 3*time.Second, nil)
+
+		out := scrape(collector)
+		Expect(out).To(ContainSubstring("# TYPE cni_operation_duration_seconds histogram\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_bucket{operation="AddNetworkList",network="net1",le="0.025"} 0` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_bucket{operation="AddNetworkList",network="net1",le="0.05"} 1` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_bucket{operation="AddNetworkList",network="net1",le="5"} 2` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_bucket{operation="AddNetworkList",network="net1",le="+Inf"} 2` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_sum{operation="AddNetworkList",network="net1"} 3.03` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_count{operation="AddNetworkList",network="net1"} 2` + "\n"))
+	})
+
+	It("counts errors by CNI error code", func() {
+		collector.ObservePlugin("bridge", "ADD", time.Second, types.NewError(types.ErrTryAgainLater, "busy", ""))
+		collector.ObservePlugin("bridge", "ADD", time.Second, types.NewError(types.ErrTryAgainLater, "busy", ""))
+		collector.ObservePlugin("bridge", "DEL", time.Second, errors.New("failed to find plugin"))
+		collector.ObservePlugin("bridge", "CHECK", time.Second, nil)
+
+		out := scrape(collector)
+		Expect(out).To(ContainSubstring("# TYPE cni_plugin_errors_total counter\n"))
+		Expect(out).To(ContainSubstring(`cni_plugin_errors_total{plugin="bridge",command="ADD",code="11"} 2` + "\n"))
+		Expect(out).To(ContainSubstring(`cni_plugin_errors_total{plugin="bridge",command="DEL",code="none"} 1` + "\n"))
+		Expect(out).NotTo(ContainSubstring(`cni_plugin_errors_total{plugin="bridge",command="CHECK"`))
+	})
+
+	It("escapes label values", func() {
+		collector.ObserveOperation("DelNetworkList", "a \"quoted\"\\name\n", time.Second, nil)
+
+		Expect(scrape(collector)).To(ContainSubstring(`network="a \"quoted\"\\name\n"`))
+	})
+
+	It("serves the metrics over HTTP", func() {
+		collector.ObserveOperation("AddNetworkList", "net1", time.Second, nil)
+
+		rec := httptest.NewRecorder()
+		collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+		Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+		Expect(rec.Body.String()).To(Equal(scrape(collector)))
+	})
+
+	Context("plugged into libcni", func() {
+		var (
+			cniConfig *libcni.CNIConfig
+			list      *libcni.NetworkConfigList
+			rt        *libcni.RuntimeConf
+			ctx       context.Context
+		)
+
+		BeforeEach(func() {
+			pluginDir := GinkgoT().TempDir()
+			_, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: "fake"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = plugintest.Install(pluginDir, plugintest.Plugin{
+				Name: "flaky",
+				Responses: map[string]plugintest.Response{
+					"DEL": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			cniConfig = libcni.NewCNIConfigWithCacheDir([]string{pluginDir}, GinkgoT().TempDir(), nil)
+			cniConfig.Metrics = collector
+			collector.WatchCache(cniConfig)
+
+			list, err = libcni.ConfListFromBytes([]byte(`{
+				"cniVersion": "1.0.0",
+				"name": "some-network",
+				"plugins": [{"type": "fake"}, {"type": "flaky"}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			rt = &libcni.RuntimeConf{
+				ContainerID: "some-container-id",
+				NetNS:       "/some/netns/path",
+				IfName:      "eth0",
+			}
+			ctx = context.TODO()
+		})
+
+		It("records operations, plugin executions and the cache size", func() {
+			_, err := cniConfig.AddNetworkList(ctx, list, rt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cniConfig.DelNetworkList(ctx, list, rt)).NotTo(Succeed())
+
+			out := scrape(collector)
+			Expect(out).To(ContainSubstring(`cni_operation_duration_seconds_count{operation="AddNetworkList",network="some-network"} 1` + "\n"))
+			Expect(out).To(ContainSubstring(`cni_operation_errors_total{operation="DelNetworkList",network="some-network",code="11"} 1` + "\n"))
+			Expect(out).To(ContainSubstring(`cni_plugin_duration_seconds_count{plugin="fake",command="ADD"} 1` + "\n"))
+			Expect(out).To(ContainSubstring(`cni_plugin_duration_seconds_count{plugin="flaky",command="DEL"} 1` + "\n"))
+			Expect(out).To(ContainSubstring(`cni_plugin_errors_total{plugin="flaky",command="DEL",code="11"} 1` + "\n"))
+			Expect(out).To(ContainSubstring("# TYPE cni_cached_attachments gauge\n"))
+			Expect(out).To(ContainSubstring(`cni_cached_attachments{network="some-network"} 1` + "\n"))
+		})
+	})
+})
@@ -0,0 +1,229 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nri drives libcni from the pod lifecycle events of NRI, the Node
+// Resource Interface of containerd and CRI-O, so that pods are attached to
+// CNI networks by an NRI plugin instead of by the runtime itself.
+//
+// PodSandbox holds the fields of NRI's api.PodSandbox that CNI needs, so an
+// Adapter is wired into an NRI stub with a few lines and no dependency is
+// forced on users of this library:
+//
+//	func (p *plugin) RunPodSandbox(ctx context.Context, pod *api.PodSandbox) error {
+//		return p.adapter.RunPodSandbox(ctx, podSandbox(pod))
+//	}
+//
+//	func podSandbox(pod *api.PodSandbox) *nri.PodSandbox {
+//		sandbox := &nri.PodSandbox{
+//			ID:          pod.GetId(),
+//			Name:        pod.GetName(),
+//			Namespace:   pod.GetNamespace(),
+//			UID:         pod.GetUid(),
+//			Labels:      pod.GetLabels(),
+//			Annotations: pod.GetAnnotations(),
+//		}
+//		for _, ns := range pod.GetLinux().GetNamespaces() {
+//			if ns.Type == "network" {
+//				sandbox.NetNS = ns.Path
+//			}
+//		}
+//		return sandbox
+//	}
+//
+// and likewise for StopPodSandbox and RemovePodSandbox.
+package nri
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/k8sargs"
+)
+
+// Keys of the RuntimeConf.Metadata the Adapter stores with each attachment,
+// for finding them with libcni's GetCachedAttachmentsByMetadata
+const (
+	MetadataPodName      = "io.kubernetes.pod.name"
+	MetadataPodNamespace = "io.kubernetes.pod.namespace"
+	MetadataPodUID       = "io.kubernetes.pod.uid"
+)
+
+// PodSandbox is a pod as described by NRI
+type PodSandbox struct {
+	ID          string
+	Name        string
+	Namespace   string
+	UID         string
+	Labels      map[string]string
+	Annotations map[string]string
+	// NetNS is the path of the pod's network namespace, or empty for pods
+	// sharing the host's network namespace, which are not attached
+	NetNS string
+}
+
+// Adapter attaches pods to CNI networks when NRI reports them started and
+// detaches them when NRI reports them stopped or removed. It is safe for
+// concurrent use.
+type Adapter struct {
+	cni      *libcni.CNIConfig
+	networks func(*PodSandbox) ([]*libcni.NetworkSelection, error)
+
+	// RuntimeConf, if set, completes the RuntimeConf of each pod before
+	// it is attached or detached, e.g. with capability arguments such as
+	// port mappings taken from the pod's annotations
+	RuntimeConf func(*PodSandbox, *libcni.RuntimeConf) error
+
+	mu sync.Mutex
+	// results holds the results of the pods attached since the adapter
+	// was created
+	results map[string]*libcni.MultiNetworkResult
+}
+
+// NewAdapter returns an Adapter which attaches every pod to the given
+// networks, the first being its default network
+func NewAdapter(cni *libcni.CNIConfig, networks ...*libcni.NetworkConfigList) *Adapter {
+	selections := make([]*libcni.NetworkSelection, 0, len(networks))
+	for _, network := range networks {
+		selections = append(selections, &libcni.NetworkSelection{Network: network})
+	}
+	return NewAdapterFunc(cni, func(*PodSandbox) ([]*libcni.NetworkSelection, error) {
+		return selections, nil
+	})
+}
+
+// NewAdapterFunc returns an Adapter which attaches each pod to the networks
+// networks returns for it, e.g. according to its annotations. It must
+// return the same networks for a pod every time it is called.
+func NewAdapterFunc(cni *libcni.CNIConfig, networks func(*PodSandbox) ([]*libcni.NetworkSelection, error)) *Adapter {
+	return &Adapter{
+		cni:      cni,
+		networks: networks,
+		results:  make(map[string]*libcni.MultiNetworkResult),
+	}
+}
+
+// runtimeConf returns the networks and RuntimeConf of a pod, with the
+// CNI_ARGS Kubernetes runtimes pass
+func (a *Adapter) runtimeConf(pod *PodSandbox) ([]*libcni.NetworkSelection, *libcni.RuntimeConf, error) {
+	if pod.ID == "" {
+		return nil, nil, errors.New("pod has no ID")
+	}
+	networks, err := a.networks(pod)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to select the networks of pod %s: %w", pod.ID, err)
+	}
+	rt := &libcni.RuntimeConf{
+		ContainerID: pod.ID,
+		NetNS:       pod.NetNS,
+		Args:        [][2]string{{"IgnoreUnknown", "1"}},
+		Metadata:    map[string]string{},
+	}
+	for _, arg := range [][2]string{
+		{k8sargs.KeyPodNamespace, pod.Namespace},
+		{k8sargs.KeyPodName, pod.Name},
+		{k8sargs.KeyPodInfraContainerID, pod.ID},
+		{k8sargs.KeyPodUID, pod.UID},
+	} {
+		if arg[1] != "" {
+			rt.Args = append(rt.Args, arg)
+		}
+	}
+	for key, value := range map[string]string{
+		MetadataPodName:      pod.Name,
+		MetadataPodNamespace: pod.Namespace,
+		MetadataPodUID:       pod.UID,
+	} {
+		if value != "" {
+			rt.Metadata[key] = value
+		}
+	}
+	if a.RuntimeConf != nil {
+		if err := a.RuntimeConf(pod, rt); err != nil {
+			return nil, nil, fmt.Errorf("failed to configure pod %s: %w", pod.ID, err)
+		}
+	}
+	return networks, rt, nil
+}
+
+// RunPodSandbox attaches the pod to its networks, all or none of them.
+// Pods without a network namespace are left alone.
+func (a *Adapter) RunPodSandbox(ctx context.Context, pod *PodSandbox) error {
+	if pod.NetNS == "" {
+		return nil
+	}
+	networks, rt, err := a.runtimeConf(pod)
+	if err != nil || len(networks) == 0 {
+		return err
+	}
+	result, err := a.cni.AddNetworks(ctx, networks, rt)
+	if err != nil {
+		return fmt.Errorf("failed to attach pod %s: %w", pod.ID, err)
+	}
+	a.mu.Lock()
+	a.results[pod.ID] = result
+	a.mu.Unlock()
+	return nil
+}
+
+// StopPodSandbox detaches the pod from its networks while its network
+// namespace still exists. Detaching a pod which is not attached succeeds,
+// as CNI DEL does.
+func (a *Adapter) StopPodSandbox(ctx context.Context, pod *PodSandbox) error {
+	networks, rt, err := a.runtimeConf(pod)
+	if err != nil || len(networks) == 0 {
+		return err
+	}
+	if err := a.cni.DelNetworks(ctx, networks, rt); err != nil {
+		return fmt.Errorf("failed to detach pod %s: %w", pod.ID, err)
+	}
+	a.mu.Lock()
+	delete(a.results, pod.ID)
+	a.mu.Unlock()
+	return nil
+}
+
+// RemovePodSandbox detaches the pod again, in case it was not stopped
+// cleanly, so that no address or cache entry of it is left behind
+func (a *Adapter) RemovePodSandbox(ctx context.Context, pod *PodSandbox) error {
+	return a.StopPodSandbox(ctx, pod)
+}
+
+// CheckPodSandbox checks that the pod is still attached to its networks as
+// it was, for runtimes which verify pods periodically. NRI has no event for
+// it.
+func (a *Adapter) CheckPodSandbox(ctx context.Context, pod *PodSandbox) error {
+	if pod.NetNS == "" {
+		return nil
+	}
+	networks, rt, err := a.runtimeConf(pod)
+	if err != nil || len(networks) == 0 {
+		return err
+	}
+	if err := a.cni.CheckNetworks(ctx, networks, rt); err != nil {
+		return fmt.Errorf("failed to check pod %s: %w", pod.ID, err)
+	}
+	return nil
+}
+
+// Result returns the result of attaching the pod with the given ID, if it
+// was attached by this adapter and is not detached since
+func (a *Adapter) Result(podID string) (*libcni.MultiNetworkResult, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	result, ok := a.results[podID]
+	return result, ok
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nri_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/nri"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+var _ = Describe("Adapter", func() {
+	var (
+		ctx       context.Context
+		cniConfig *libcni.CNIConfig
+		fakes     map[string]*plugintest.Fake
+		pod       *nri.PodSandbox
+		adapter   *nri.Adapter
+	)
+
+	network := func(name, pluginType string) *libcni.NetworkConfigList {
+		list, err := libcni.ConfListFromBytes([]byte(fmt.Sprintf(`{
+			"cniVersion": "1.0.0",
+			"name": %q,
+			"plugins": [{"type": %q, "capabilities": {"portMappings": true}}]
+		}`, name, pluginType)))
+		Expect(err).NotTo(HaveOccurred())
+		return list
+	}
+
+	invocations := func(pluginType string) []plugintest.Invocation {
+		invocations, err := fakes[pluginType].Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		return invocations
+	}
+
+	commands := func(pluginType string) []string {
+		cmds := []string{}
+		for _, inv := range invocations(pluginType) {
+			cmds = append(cmds, inv.Command+" "+inv.IfName)
+		}
+		return cmds
+	}
+
+	BeforeEach(func() {
+		pluginDir := GinkgoT().TempDir()
+		plugins := []plugintest.Plugin{}
+		for i, name := range []string{"fake-a", "fake-b"} {
+			plugins = append(plugins, plugintest.Plugin{
+				Name: name,
+				Responses: map[string]plugintest.Response{"ADD": {Result: fmt.Sprintf(`{
+					"cniVersion": "1.0.0",
+					"interfaces": [{"name": "{{.IfName}}", "sandbox": "{{.NetNS}}"}],
+					"ips": [{"address": "10.0.%d.2/24", "interface": 0}]
+				}`, i+1)}},
+			})
+		}
+		plugins = append(plugins, plugintest.Plugin{
+			Name:      "failing",
+			Responses: map[string]plugintest.Response{"ADD": {ErrorCode: types.ErrTryAgainLater, ErrorMsg: "busy"}},
+		})
+		var err error
+		fakes, err = plugintest.InstallAll(pluginDir, plugins...)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = context.TODO()
+		cniConfig = libcni.NewCNIConfigWithCacheDir([]string{pluginDir}, GinkgoT().TempDir(), nil)
+		adapter = nri.NewAdapter(cniConfig, network("net-a", "fake-a"), network("net-b", "fake-b"))
+		pod = &nri.PodSandbox{
+			ID:        "pod-id",
+			Name:      "web",
+			Namespace: "default",
+			UID:       "1234",
+			NetNS:     "/var/run/netns/cni-1234",
+		}
+	})
+
+	It("attaches a started pod to its networks", func() {
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(Succeed())
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0"}))
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1"}))
+
+		inv := invocations("fake-a")[0]
+		Expect(inv.ContainerID).To(Equal("pod-id"))
+		Expect(inv.NetNS).To(Equal("/var/run/netns/cni-1234"))
+		Expect(strings.Split(inv.Args, ";")).To(Equal([]string{
+			"IgnoreUnknown=1",
+			"K8S_POD_NAMESPACE=default",
+			"K8S_POD_NAME=web",
+			"K8S_POD_INFRA_CONTAINER_ID=pod-id",
+			"K8S_POD_UID=1234",
+		}))
+
+		result, ok := adapter.Result("pod-id")
+		Expect(ok).To(BeTrue())
+		Expect(result.IfNames).To(Equal([]string{"eth0", "net1"}))
+		Expect(result.Result.IPs).To(HaveLen(2))
+
+		attachments, err := cniConfig.GetCachedAttachmentsByMetadata(map[string]string{
+			nri.MetadataPodNamespace: "default",
+			nri.MetadataPodName:      "web",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachments).To(HaveLen(2))
+	})
+
+	It("leaves pods without a network namespace alone", func() {
+		pod.NetNS = ""
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(Succeed())
+		Expect(adapter.CheckPodSandbox(ctx, pod)).To(Succeed())
+		Expect(commands("fake-a")).To(BeEmpty())
+	})
+
+	It("detaches a stopped pod, and again when it is removed", func() {
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(Succeed())
+		Expect(adapter.StopPodSandbox(ctx, pod)).To(Succeed())
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1", "DEL net1"}))
+		_, ok := adapter.Result("pod-id")
+		Expect(ok).To(BeFalse())
+
+		Expect(adapter.RemovePodSandbox(ctx, pod)).To(Succeed())
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0", "DEL eth0", "DEL eth0"}))
+	})
+
+	It("checks an attached pod", func() {
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(Succeed())
+		Expect(adapter.CheckPodSandbox(ctx, pod)).To(Succeed())
+		Expect(commands("fake-b")).To(Equal([]string{"ADD net1", "CHECK net1"}))
+	})
+
+	It("attaches a pod to none of its networks if one fails", func() {
+		adapter = nri.NewAdapter(cniConfig, network("net-a", "fake-a"), network("net-f", "failing"))
+
+		err := adapter.RunPodSandbox(ctx, pod)
+		Expect(err).To(MatchError(ContainSubstring(`failed to attach pod pod-id: network "net-f" (net1) failed (add)`)))
+		Expect(commands("fake-a")).To(Equal([]string{"ADD eth0", "DEL eth0"}))
+		_, ok := adapter.Result("pod-id")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("selects networks and runtime configuration per pod", func() {
+		adapter = nri.NewAdapterFunc(cniConfig, func(pod *nri.PodSandbox) ([]*libcni.NetworkSelection, error) {
+			if pod.Annotations["example.com/network"] == "" {
+				return nil, errors.New("no network annotation")
+			}
+			return []*libcni.NetworkSelection{{Network: network(pod.Annotations["example.com/network"], "fake-b")}}, nil
+		})
+		adapter.RuntimeConf = func(pod *nri.PodSandbox, rt *libcni.RuntimeConf) error {
+			rt.CapabilityArgs = map[string]interface{}{
+				"portMappings": []map[string]interface{}{{"hostPort": 8080, "containerPort": 80, "protocol": "tcp"}},
+			}
+			return nil
+		}
+
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(MatchError("failed to select the networks of pod pod-id: no network annotation"))
+
+		pod.Annotations = map[string]string{"example.com/network": "net-x"}
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(Succeed())
+		inv := invocations("fake-b")[0]
+		Expect(string(inv.StdinData)).To(ContainSubstring(`"name":"net-x"`))
+		Expect(string(inv.StdinData)).To(ContainSubstring(`"portMappings":[{"containerPort":80,"hostPort":8080,"protocol":"tcp"}]`))
+	})
+
+	It("rejects pods without an ID", func() {
+		pod.ID = ""
+		Expect(adapter.RunPodSandbox(ctx, pod)).To(MatchError("pod has no ID"))
+	})
+})
@@ -0,0 +1,204 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// GCReport lists what GCPinnedNS did with each pinned namespace
+type GCReport struct {
+	// Removed are the stale namespaces that were unpinned
+	Removed []string
+	// InUse are the namespaces still used by a process or holding
+	// interfaces other than loopback
+	InUse []string
+	// Skipped are the namespaces that were not inspected because they are
+	// excluded or younger than the minimum age
+	Skipped []string
+	// Failed maps the namespaces that could not be inspected or removed to
+	// the reason
+	Failed map[string]error
+}
+
+// DefaultGCMinAge is the minimum age of a pinned namespace GCPinnedNS
+// removes when GCOptions.MinAge is zero
+const DefaultGCMinAge = 5 * time.Minute
+
+// GCOptions configures GCPinnedNS
+type GCOptions struct {
+	// MinAge keeps namespaces pinned more recently than this, since a
+	// runtime pins a namespace before it calls ADD and the namespace is
+	// empty until then. Zero uses DefaultGCMinAge and a negative value
+	// disables the threshold.
+	MinAge time.Duration
+	// Exclude lists paths of pinned namespaces that are never removed, such
+	// as those of sandboxes the runtime is setting up
+	Exclude []string
+}
+
+type nsID struct {
+	dev, ino uint64
+}
+
+// GCPinnedNS unpins the network namespaces bind-mounted in runDir, as by
+// NewPinnedNS, that no process runs in and that have no interfaces other
+// than loopback left, such as veth peers of a container. Leftover mount
+// points without a namespace are removed too. Namespaces younger than
+// opts.MinAge, by the modification time of the pinned namespace, and those
+// in opts.Exclude are skipped. The returned error joins the errors in the
+// report's Failed map.
+func GCPinnedNS(runDir string, opts GCOptions) (*GCReport, error) {
+	report := &GCReport{Failed: map[string]error{}}
+
+	minAge := opts.MinAge
+	if minAge == 0 {
+		minAge = DefaultGCMinAge
+	}
+	excluded := map[string]bool{}
+	for _, p := range opts.Exclude {
+		excluded[filepath.Clean(p)] = true
+	}
+
+	entries, err := os.ReadDir(runDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return report, nil
+		}
+		return nil, err
+	}
+
+	used, err := namespacesInUse()
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		nsPath := filepath.Join(runDir, entry.Name())
+		if excluded[nsPath] || !oldEnough(nsPath, minAge) {
+			report.Skipped = append(report.Skipped, nsPath)
+			continue
+		}
+		stale, err := isStaleNS(nsPath, used)
+		if err == nil && stale {
+			err = UnpinNS(nsPath)
+		}
+		switch {
+		case err != nil:
+			report.Failed[nsPath] = err
+			errs = append(errs, fmt.Errorf("%s: %w", nsPath, err))
+		case stale:
+			report.Removed = append(report.Removed, nsPath)
+		default:
+			report.InUse = append(report.InUse, nsPath)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// oldEnough returns whether the namespace pinned at nsPath was pinned at
+// least minAge ago. The modification time of a pinned namespace is the time
+// its nsfs inode was created, which is when it was first bind-mounted for a
+// namespace created by NewPinnedNS. A path that cannot be stat'ed is left
+// to isStaleNS to report.
+func oldEnough(nsPath string, minAge time.Duration) bool {
+	if minAge < 0 {
+		return true
+	}
+	fi, err := os.Stat(nsPath)
+	if err != nil {
+		return true
+	}
+	return time.Since(fi.ModTime()) >= minAge
+}
+
+// isStaleNS returns whether the pinned namespace at nsPath can be removed
+func isStaleNS(nsPath string, used map[nsID]bool) (bool, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(nsPath, &fs); err != nil {
+		return false, err
+	}
+	if fs.Type != unix.NSFS_MAGIC {
+		// a mount point whose namespace was already unmounted
+		return true, nil
+	}
+
+	st, err := statNS(nsPath)
+	if err != nil {
+		return false, err
+	}
+	if used[nsID{uint64(st.Dev), st.Ino}] {
+		return false, nil
+	}
+
+	hasLinks := false
+	err = Do(nsPath, func() error {
+		ifaces, err := net.Interfaces()
+		if err != nil {
+			return err
+		}
+		for _, iface := range ifaces {
+			if iface.Flags&net.FlagLoopback == 0 {
+				hasLinks = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return !hasLinks, nil
+}
+
+// namespacesInUse returns the network namespaces of every thread of every
+// process on the host
+func namespacesInUse() (map[nsID]bool, error) {
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	used := map[nsID]bool{}
+	for _, proc := range procs {
+		if _, err := strconv.Atoi(proc.Name()); err != nil {
+			continue
+		}
+		tasks, err := os.ReadDir(filepath.Join("/proc", proc.Name(), "task"))
+		if err != nil {
+			// the process exited
+			continue
+		}
+		for _, task := range tasks {
+			var st unix.Stat_t
+			if err := unix.Stat(filepath.Join("/proc", proc.Name(), "task", task.Name(), "ns", "net"), &st); err != nil {
+				continue
+			}
+			used[nsID{uint64(st.Dev), st.Ino}] = true
+		}
+	}
+	return used, nil
+}
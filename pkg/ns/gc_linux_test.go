@@ -0,0 +1,100 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+var _ = Describe("GCPinnedNS", func() {
+	var runDir string
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("pinning network namespaces requires root")
+		}
+		runDir = filepath.Join(GinkgoT().TempDir(), "netns")
+	})
+
+	It("keeps a freshly pinned namespace", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "fresh")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		report, err := ns.GCPinnedNS(runDir, ns.GCOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Skipped).To(ConsistOf(nsPath))
+		Expect(report.Removed).To(BeEmpty())
+		Expect(nsPath).To(BeAnExistingFile())
+	})
+
+	It("removes empty namespaces older than the minimum age", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "stale")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		report, err := ns.GCPinnedNS(runDir, ns.GCOptions{MinAge: -1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Removed).To(ConsistOf(nsPath))
+		Expect(nsPath).NotTo(BeAnExistingFile())
+	})
+
+	It("keeps excluded namespaces", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "excluded")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		report, err := ns.GCPinnedNS(runDir, ns.GCOptions{MinAge: -1, Exclude: []string{nsPath}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Skipped).To(ConsistOf(nsPath))
+		Expect(nsPath).To(BeAnExistingFile())
+	})
+
+	It("keeps namespaces a process runs in", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "used")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		var report *ns.GCReport
+		Expect(ns.Do(nsPath, func() error {
+			report, err = ns.GCPinnedNS(runDir, ns.GCOptions{MinAge: -1})
+			return err
+		})).To(Succeed())
+		Expect(report.InUse).To(ConsistOf(nsPath))
+		Expect(nsPath).To(BeAnExistingFile())
+	})
+
+	It("removes leftover mount points", func() {
+		Expect(os.MkdirAll(runDir, 0o755)).To(Succeed())
+		leftover := filepath.Join(runDir, "leftover")
+		Expect(os.WriteFile(leftover, nil, 0o444)).To(Succeed())
+
+		report, err := ns.GCPinnedNS(runDir, ns.GCOptions{MinAge: -1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Removed).To(ConsistOf(leftover))
+	})
+
+	It("does nothing for a missing run directory", func() {
+		report, err := ns.GCPinnedNS(filepath.Join(runDir, "missing"), ns.GCOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Removed).To(BeEmpty())
+	})
+})
@@ -15,36 +15,124 @@
 package ns
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"runtime"
 
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 
 	"github.com/containernetworking/cni/pkg/types"
 )
 
-// Returns an object representing the current OS thread's network namespace
-func getCurrentNS() (netns.NsHandle, error) {
+// statNS returns the stat of the namespace file at nsPath, following any
+// symlinks, or an error if it is not a namespace file. Two namespace files
+// refer to the same namespace exactly when their device and inode match.
+func statNS(nsPath string) (*unix.Stat_t, error) {
+	f, err := os.Open(nsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var fs unix.Statfs_t
+	if err := unix.Fstatfs(int(f.Fd()), &fs); err != nil {
+		return nil, fmt.Errorf("failed to stat %q: %w", nsPath, err)
+	}
+	if fs.Type != unix.NSFS_MAGIC {
+		return nil, fmt.Errorf("%q is not a namespace file", nsPath)
+	}
+
+	var st unix.Stat_t
+	if err := unix.Fstat(int(f.Fd()), &st); err != nil {
+		return nil, fmt.Errorf("failed to stat %q: %w", nsPath, err)
+	}
+	return &st, nil
+}
+
+// Returns the stat of the current OS thread's network namespace
+func statCurrentNS() (*unix.Stat_t, error) {
 	// Lock the thread in case other goroutine executes in it and changes its
-	// network namespace after getCurrentThreadNetNSPath(), otherwise it might
-	// return an unexpected network namespace.
+	// network namespace while we look it up, otherwise it might return an
+	// unexpected network namespace.
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
-	return netns.Get()
+	return statNS(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()))
+}
+
+func sameNS(a, b *unix.Stat_t) bool {
+	return a.Dev == b.Dev && a.Ino == b.Ino
+}
+
+// Equal returns whether the two paths refer to the same namespace. Paths
+// are compared by the device and inode of the namespace, so symlinks and
+// bind mounts of the same namespace are equal.
+func Equal(path1, path2 string) (bool, error) {
+	st1, err := statNS(path1)
+	if err != nil {
+		return false, err
+	}
+	st2, err := statNS(path2)
+	if err != nil {
+		return false, err
+	}
+	return sameNS(st1, st2), nil
 }
 
 func CheckNetNS(nsPath string) (bool, *types.Error) {
-	ns, err := netns.GetFromPath(nsPath)
+	st, err := statNS(nsPath)
 	// Let plugins check whether nsPath from args is valid. Also support CNI DEL for empty nsPath as already-deleted nsPath.
 	if err != nil {
 		return false, nil
 	}
-	defer ns.Close()
 
-	pluginNS, err := getCurrentNS()
+	pluginNS, err := statCurrentNS()
 	if err != nil {
 		return false, types.NewError(types.ErrInvalidNetNS, "get plugin's netns failed", "")
 	}
-	defer pluginNS.Close()
 
-	return pluginNS.Equal(ns), nil
+	return sameNS(pluginNS, st), nil
+}
+
+// Do runs fn with the calling goroutine's OS thread switched into the
+// network namespace at nsPath, then switches it back. The goroutine is
+// locked to its thread while fn runs; goroutines started by fn do not run
+// in the namespace. The namespace is switched back even if fn panics. If
+// the original namespace cannot be restored the thread stays locked, so
+// the runtime discards it when the goroutine exits instead of reusing a
+// thread in the wrong namespace.
+func Do(nsPath string, fn func() error) (err error) {
+	if _, err := statNS(nsPath); err != nil {
+		return fmt.Errorf("failed to open netns %q: %w", nsPath, err)
+	}
+	target, err := netns.GetFromPath(nsPath)
+	if err != nil {
+		return fmt.Errorf("failed to open netns %q: %w", nsPath, err)
+	}
+	defer target.Close()
+
+	runtime.LockOSThread()
+	origNS, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("failed to get current netns: %w", err)
+	}
+	defer origNS.Close()
+
+	if err := netns.Set(target); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("failed to switch to netns %q: %w", nsPath, err)
+	}
+	// Switch back even if fn panics, as the caller may recover and go on
+	// using the thread
+	defer func() {
+		if restoreErr := netns.Set(origNS); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to restore netns: %w", restoreErr))
+			return
+		}
+		runtime.UnlockOSThread()
+	}()
+
+	return fn()
 }
@@ -0,0 +1,127 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns_test
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+var _ = Describe("network namespaces", func() {
+	Describe("Equal", func() {
+		It("treats symlinks to the same namespace as equal", func() {
+			same, err := ns.Equal("/proc/self/ns/net", "/proc/thread-self/ns/net")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(same).To(BeTrue())
+		})
+
+		It("rejects files that are not namespaces", func() {
+			f := filepath.Join(GinkgoT().TempDir(), "file")
+			Expect(os.WriteFile(f, nil, 0o644)).To(Succeed())
+
+			_, err := ns.Equal(f, "/proc/self/ns/net")
+			Expect(err).To(MatchError(ContainSubstring("is not a namespace file")))
+		})
+	})
+
+	Describe("CheckNetNS", func() {
+		It("reports the plugin's own namespace", func() {
+			ok, err := ns.CheckNetNS("/proc/self/ns/net")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("treats a missing namespace as not the plugin's", func() {
+			ok, err := ns.CheckNetNS("/does/not/exist")
+			Expect(err).To(BeNil())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Do", func() {
+		var runDir string
+
+		BeforeEach(func() {
+			if os.Geteuid() != 0 {
+				Skip("switching network namespaces requires root")
+			}
+			runDir = filepath.Join(GinkgoT().TempDir(), "netns")
+		})
+
+		It("runs the function in the namespace and switches back", func() {
+			nsPath, err := ns.NewPinnedNS(runDir, "do")
+			Expect(err).NotTo(HaveOccurred())
+			defer ns.UnpinNS(nsPath)
+
+			var names []string
+			err = ns.Do(nsPath, func() error {
+				ifaces, err := net.Interfaces()
+				for _, iface := range ifaces {
+					names = append(names, iface.Name)
+				}
+				return err
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"lo"}))
+
+			ok, cniErr := ns.CheckNetNS("/proc/thread-self/ns/net")
+			Expect(cniErr).To(BeNil())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("returns the error of the function", func() {
+			nsPath, err := ns.NewPinnedNS(runDir, "do")
+			Expect(err).NotTo(HaveOccurred())
+			defer ns.UnpinNS(nsPath)
+
+			fnErr := errors.New("failed inside")
+			Expect(ns.Do(nsPath, func() error { return fnErr })).To(MatchError(fnErr))
+		})
+
+		It("switches back when the function panics", func() {
+			nsPath, err := ns.NewPinnedNS(runDir, "do")
+			Expect(err).NotTo(HaveOccurred())
+			defer ns.UnpinNS(nsPath)
+
+			func() {
+				defer func() {
+					Expect(recover()).To(Equal("failed inside"))
+				}()
+				_ = ns.Do(nsPath, func() error { panic("failed inside") })
+			}()
+
+			inside, err := ns.Equal("/proc/thread-self/ns/net", nsPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inside).To(BeFalse())
+		})
+
+		It("does not run the function for a path that is not a namespace", func() {
+			called := false
+			err := ns.Do("/does/not/exist", func() error {
+				called = true
+				return nil
+			})
+			Expect(err).To(MatchError(ContainSubstring(`failed to open netns "/does/not/exist"`)))
+			Expect(called).To(BeFalse())
+		})
+	})
+})
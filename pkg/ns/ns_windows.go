@@ -14,8 +14,85 @@
 
 package ns
 
-import "github.com/containernetworking/cni/pkg/types"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
 
+	"golang.org/x/sys/windows"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+var (
+	modcomputenetwork     = windows.NewLazySystemDLL("computenetwork.dll")
+	procHcnOpenNamespace  = modcomputenetwork.NewProc("HcnOpenNamespace")
+	procHcnCloseNamespace = modcomputenetwork.NewProc("HcnCloseNamespace")
+)
+
+// hrElementNotFound is HRESULT_FROM_WIN32(ERROR_NOT_FOUND), returned by HCN
+// for unknown namespaces
+const hrElementNotFound = 0x80070490
+
+var (
+	// ErrNamespaceNotFound is returned by ValidateNamespace for a
+	// well-formed GUID that HNS does not know about
+	ErrNamespaceNotFound = errors.New("HNS namespace does not exist")
+	// ErrHNSUnavailable is returned by ValidateNamespace when the host
+	// network service API cannot be loaded
+	ErrHNSUnavailable = errors.New("HNS is not available")
+)
+
+// ParseNamespaceID parses CNI_NETNS as an HNS namespace GUID, with or
+// without surrounding braces
+func ParseNamespaceID(nsPath string) (windows.GUID, error) {
+	s := nsPath
+	if !strings.HasPrefix(s, "{") {
+		s = "{" + s + "}"
+	}
+	guid, err := windows.GUIDFromString(s)
+	if err != nil {
+		return windows.GUID{}, fmt.Errorf("invalid HNS namespace %q: %w", nsPath, err)
+	}
+	return guid, nil
+}
+
+// ValidateNamespace checks that nsPath is the GUID of an existing HNS
+// namespace
+func ValidateNamespace(nsPath string) error {
+	guid, err := ParseNamespaceID(nsPath)
+	if err != nil {
+		return err
+	}
+	if err := procHcnOpenNamespace.Find(); err != nil {
+		return fmt.Errorf("%w: %v", ErrHNSUnavailable, err)
+	}
+
+	var handle uintptr
+	hr, _, _ := procHcnOpenNamespace.Call(uintptr(unsafe.Pointer(&guid)), uintptr(unsafe.Pointer(&handle)), 0)
+	if hr == hrElementNotFound {
+		return fmt.Errorf("%w: %q", ErrNamespaceNotFound, nsPath)
+	} else if hr != 0 {
+		return fmt.Errorf("failed to open HNS namespace %q: HRESULT 0x%08x", nsPath, uint32(hr))
+	}
+	_, _, _ = procHcnCloseNamespace.Call(handle)
+	return nil
+}
+
+// CheckNetNS reports whether nsPath is the plugin's own network namespace.
+// On Windows CNI_NETNS is an HNS namespace GUID, and plugins always run in
+// the host compartment, which is never such a namespace. A GUID that HNS
+// fails to look up is reported as ErrInvalidNetNS. As on Linux, values that
+// are not GUIDs and namespaces that no longer exist are not errors, so that
+// DEL of an already-deleted namespace succeeds.
 func CheckNetNS(nsPath string) (bool, *types.Error) {
+	if _, err := ParseNamespaceID(nsPath); err != nil {
+		return false, nil
+	}
+	err := ValidateNamespace(nsPath)
+	if err != nil && !errors.Is(err, ErrNamespaceNotFound) && !errors.Is(err, ErrHNSUnavailable) {
+		return false, types.NewError(types.ErrInvalidNetNS, "failed to look up CNI_NETNS", err.Error())
+	}
 	return false, nil
 }
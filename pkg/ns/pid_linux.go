@@ -0,0 +1,81 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+func pidNetNSPath(pid int) (string, error) {
+	if pid <= 0 {
+		return "", fmt.Errorf("invalid pid %d", pid)
+	}
+	return fmt.Sprintf("/proc/%d/ns/net", pid), nil
+}
+
+// GetNetNSPathFromPID returns the /proc path of the network namespace of
+// the given process, after checking that it refers to a network namespace.
+// The path is only valid for as long as the process runs; runtimes that
+// need a stable reference should use GetNetNSFromPID.
+func GetNetNSPathFromPID(pid int) (string, error) {
+	nsPath, err := pidNetNSPath(pid)
+	if err != nil {
+		return "", err
+	}
+	link, err := os.Readlink(nsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("process %d does not exist", pid)
+		}
+		return "", fmt.Errorf("failed to read %q: %w", nsPath, err)
+	}
+	if !strings.HasPrefix(link, "net:[") {
+		return "", fmt.Errorf("%q is not a network namespace: %s", nsPath, link)
+	}
+	return nsPath, nil
+}
+
+// GetNetNSFromPID opens the network namespace of the given process. The
+// returned file keeps the namespace alive and stays valid after the process
+// exits or its pid is reused; the caller must close it.
+func GetNetNSFromPID(pid int) (*os.File, error) {
+	nsPath, err := pidNetNSPath(pid)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(nsPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("process %d does not exist", pid)
+		}
+		return nil, fmt.Errorf("failed to open %q: %w", nsPath, err)
+	}
+
+	var st unix.Statfs_t
+	if err := unix.Fstatfs(int(f.Fd()), &st); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat %q: %w", nsPath, err)
+	}
+	if st.Type != unix.NSFS_MAGIC {
+		f.Close()
+		return nil, fmt.Errorf("%q is not a namespace file", nsPath)
+	}
+	return f, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns_test
+
+import (
+	"fmt"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+var _ = Describe("namespaces of processes", func() {
+	It("returns the namespace path of a process", func() {
+		nsPath, err := ns.GetNetNSPathFromPID(os.Getpid())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nsPath).To(Equal(fmt.Sprintf("/proc/%d/ns/net", os.Getpid())))
+	})
+
+	It("opens the namespace of a process", func() {
+		if os.Geteuid() != 0 {
+			Skip("comparing namespaces of other processes requires root")
+		}
+		f, err := ns.GetNetNSFromPID(os.Getpid())
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+
+		same, err := ns.Equal(fmt.Sprintf("/proc/self/fd/%d", f.Fd()), "/proc/self/ns/net")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(same).To(BeTrue())
+	})
+
+	DescribeTable("rejects invalid pids", func(pid int) {
+		_, err := ns.GetNetNSPathFromPID(pid)
+		Expect(err).To(MatchError(fmt.Sprintf("invalid pid %d", pid)))
+		_, err = ns.GetNetNSFromPID(pid)
+		Expect(err).To(MatchError(fmt.Sprintf("invalid pid %d", pid)))
+	},
+		Entry("zero", 0),
+		Entry("negative", -1),
+	)
+
+	It("reports processes that do not exist", func() {
+		// pid_max never exceeds 2^22
+		const pid = 1 << 23
+		_, err := ns.GetNetNSPathFromPID(pid)
+		Expect(err).To(MatchError(fmt.Sprintf("process %d does not exist", pid)))
+		_, err = ns.GetNetNSFromPID(pid)
+		Expect(err).To(MatchError(fmt.Sprintf("process %d does not exist", pid)))
+	})
+})
@@ -0,0 +1,128 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// NewPinnedNS creates a new network namespace and bind-mounts it at
+// runDir/name so it outlives the calling process, like `ip netns add`
+// does under /var/run/netns. It returns the path of the pinned namespace.
+// The calling goroutine's namespace is not changed.
+func NewPinnedNS(runDir, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid network namespace name %q", name)
+	}
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create netns run directory %q: %w", runDir, err)
+	}
+
+	nsPath := filepath.Join(runDir, name)
+	// The mount point must exist; O_EXCL keeps us from hiding an existing
+	// namespace or file under the new mount
+	f, err := os.OpenFile(nsPath, os.O_RDONLY|os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		return "", fmt.Errorf("failed to create netns mount point: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(nsPath)
+		return "", fmt.Errorf("failed to close netns mount point: %w", err)
+	}
+
+	// Create and pin the namespace from a dedicated OS thread, so no other
+	// goroutine ever runs in it
+	errCh := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		errCh <- pinNewNS(nsPath)
+	}()
+	if err := <-errCh; err != nil {
+		_ = os.Remove(nsPath)
+		return "", err
+	}
+	return nsPath, nil
+}
+
+// pinNewNS must be called on a locked OS thread. The thread is unlocked
+// only if it could be returned to its original namespace; otherwise it is
+// discarded when the goroutine exits.
+func pinNewNS(nsPath string) error {
+	origNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("failed to get current netns: %w", err)
+	}
+	defer origNS.Close()
+
+	newNS, err := netns.New()
+	if err != nil {
+		return fmt.Errorf("failed to create netns: %w", err)
+	}
+	defer newNS.Close()
+
+	threadNSPath := fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid())
+	mountErr := unix.Mount(threadNSPath, nsPath, "none", unix.MS_BIND, "")
+	if mountErr != nil {
+		mountErr = fmt.Errorf("failed to bind mount netns at %q: %w", nsPath, mountErr)
+	}
+
+	if err := netns.Set(origNS); err != nil {
+		return errors.Join(mountErr, fmt.Errorf("failed to restore netns: %w", err))
+	}
+	runtime.UnlockOSThread()
+	return mountErr
+}
+
+// UnpinNS unmounts a namespace pinned by NewPinnedNS and removes its mount
+// point. The namespace is destroyed once no process or open file refers to
+// it. It is not an error if nsPath does not exist, but UnpinNS refuses to
+// remove anything other than an unmounted regular file.
+func UnpinNS(nsPath string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(nsPath, &st); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat %q: %w", nsPath, err)
+	}
+	if st.Type == unix.NSFS_MAGIC {
+		if err := unix.Unmount(nsPath, unix.MNT_DETACH); err != nil {
+			return fmt.Errorf("failed to unmount netns at %q: %w", nsPath, err)
+		}
+	}
+
+	fi, err := os.Lstat(nsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%q is not a network namespace mount point", nsPath)
+	}
+	if err := os.Remove(nsPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove netns mount point %q: %w", nsPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ns_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/ns"
+)
+
+var _ = Describe("pinned network namespaces", func() {
+	var runDir string
+
+	BeforeEach(func() {
+		if os.Geteuid() != 0 {
+			Skip("pinning network namespaces requires root")
+		}
+		runDir = filepath.Join(GinkgoT().TempDir(), "netns")
+	})
+
+	It("creates a new namespace and pins it under the run directory", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "pinned")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		Expect(nsPath).To(Equal(filepath.Join(runDir, "pinned")))
+		same, err := ns.Equal(nsPath, "/proc/self/ns/net")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(same).To(BeFalse())
+
+		again, err := ns.Equal(nsPath, nsPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(BeTrue())
+	})
+
+	It("leaves the calling goroutine in its namespace", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "pinned")
+		Expect(err).NotTo(HaveOccurred())
+		defer ns.UnpinNS(nsPath)
+
+		ok, cniErr := ns.CheckNetNS(nsPath)
+		Expect(cniErr).To(BeNil())
+		Expect(ok).To(BeFalse())
+	})
+
+	It("refuses to replace an existing file", func() {
+		Expect(os.MkdirAll(runDir, 0o755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(runDir, "taken"), nil, 0o644)).To(Succeed())
+
+		_, err := ns.NewPinnedNS(runDir, "taken")
+		Expect(err).To(MatchError(ContainSubstring("failed to create netns mount point")))
+	})
+
+	DescribeTable("rejects invalid names", func(name string) {
+		_, err := ns.NewPinnedNS(runDir, name)
+		Expect(err).To(MatchError(ContainSubstring("invalid network namespace name")))
+	},
+		Entry("empty", ""),
+		Entry("dot", "."),
+		Entry("dot-dot", ".."),
+		Entry("path", "a/b"),
+	)
+
+	It("unpins and removes a pinned namespace", func() {
+		nsPath, err := ns.NewPinnedNS(runDir, "pinned")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(ns.UnpinNS(nsPath)).To(Succeed())
+		_, err = os.Lstat(nsPath)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("does not fail to unpin a missing namespace", func() {
+		Expect(ns.UnpinNS(filepath.Join(runDir, "missing"))).To(Succeed())
+	})
+
+	It("refuses to unpin anything other than a mount point", func() {
+		dir := filepath.Join(runDir, "dir")
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+
+		Expect(ns.UnpinNS(dir)).To(MatchError(ContainSubstring("is not a network namespace mount point")))
+		Expect(dir).To(BeADirectory())
+	})
+})
@@ -0,0 +1,198 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugintest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/daemon"
+	"github.com/containernetworking/cni/pkg/skel"
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/create"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// defaultAddResult is printed by ADD when the plugin has no response for it
+const defaultAddResult = `{{if .PrevResult}}{{.PrevResult}}{{else}}{"cniVersion": "{{.CNIVersion}}"}{{end}}`
+
+// Main runs the fake plugin and exits if the binary was started as a
+// plugin installed by Install. Otherwise it returns immediately.
+func Main() {
+	exe := os.Args[0]
+	if filepath.Base(exe) == exe {
+		if path, err := exec.LookPath(exe); err == nil {
+			exe = path
+		}
+	}
+	data, err := os.ReadFile(specPath(exe))
+	if err != nil {
+		return
+	}
+
+	var p Plugin
+	if err := json.Unmarshal(data, &p); err != nil {
+		fmt.Fprintf(os.Stderr, "plugintest: invalid description of %s: %v\n", exe, err)
+		os.Exit(2)
+	}
+	versions := version.All
+	if len(p.Versions) > 0 {
+		versions = version.PluginSupports(p.Versions...)
+	}
+	if p.Capabilities != nil {
+		versions = version.WithCapabilities(versions, p.Capabilities...)
+	}
+	if p.Features != nil {
+		versions = version.WithFeatures(versions, p.Features...)
+	}
+
+	f := &fake{plugin: &p, exe: exe}
+	funcs := skel.CNIFuncs{
+		Add:    f.handler("ADD"),
+		Check:  f.handler("CHECK"),
+		Del:    f.handler("DEL"),
+		GC:     f.handler("GC"),
+		Status: f.handler("STATUS"),
+	}
+	if p.Worker {
+		daemon.PluginMain(funcs, versions, "plugintest fake "+p.Name)
+	} else {
+		skel.PluginMainFuncs(funcs, versions, "plugintest fake "+p.Name)
+	}
+	os.Exit(0)
+}
+
+type fake struct {
+	plugin *Plugin
+	exe    string
+}
+
+func (f *fake) handler(command string) func(*skel.CmdArgs) error {
+	return func(args *skel.CmdArgs) error {
+		if err := f.record(command, args); err != nil {
+			return err
+		}
+
+		resp := f.plugin.Responses[command]
+		time.Sleep(resp.Delay)
+		if resp.Stderr != "" {
+			_, _ = os.Stderr.WriteString(resp.Stderr)
+		}
+		if resp.ErrorCode != 0 {
+			return types.NewError(resp.ErrorCode, resp.ErrorMsg, resp.ErrorDetails)
+		}
+
+		tmpl := resp.Result
+		if tmpl == "" && command == "ADD" {
+			tmpl = defaultAddResult
+		}
+		if tmpl != "" {
+			out, err := render(tmpl, command, args)
+			if err != nil {
+				return err
+			}
+			if err := f.print(args, out); err != nil {
+				return err
+			}
+		}
+		if resp.ExitCode != 0 {
+			os.Exit(resp.ExitCode)
+		}
+		return nil
+	}
+}
+
+// print writes the output of a command to stdout or, for workers, whose
+// stdout is not that of the invocation, with skel.PrintResult
+func (f *fake) print(args *skel.CmdArgs, out []byte) error {
+	if !f.plugin.Worker {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+	result, err := create.CreateFromBytes(out)
+	if err != nil {
+		return fmt.Errorf("invalid result of a worker: %w", err)
+	}
+	return skel.PrintResult(args, result, result.Version())
+}
+
+// record appends the invocation to the plugin's log as a line of JSON
+func (f *fake) record(command string, args *skel.CmdArgs) error {
+	stdin := json.RawMessage(args.StdinData)
+	if !json.Valid(stdin) {
+		stdin, _ = json.Marshal(string(args.StdinData))
+	}
+	data, err := json.Marshal(&Invocation{
+		Command:     command,
+		ContainerID: args.ContainerID,
+		NetNS:       args.Netns,
+		IfName:      args.IfName,
+		Args:        args.Args,
+		Path:        args.Path,
+		StdinData:   stdin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log, err := os.OpenFile(logPath(f.exe), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := log.Write(append(data, '\n')); err != nil {
+		log.Close()
+		return err
+	}
+	return log.Close()
+}
+
+func render(tmpl, command string, args *skel.CmdArgs) ([]byte, error) {
+	t, err := template.New("result").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid result template: %w", err)
+	}
+
+	data := &TemplateData{
+		Command:     command,
+		ContainerID: args.ContainerID,
+		NetNS:       args.Netns,
+		IfName:      args.IfName,
+		Args:        args.Args,
+	}
+	if err := json.Unmarshal(args.StdinData, &data.Config); err != nil {
+		return nil, fmt.Errorf("failed to parse network configuration: %w", err)
+	}
+	data.Name, _ = data.Config["name"].(string)
+	data.CNIVersion, _ = data.Config["cniVersion"].(string)
+	if prev, ok := data.Config["prevResult"]; ok {
+		prevJSON, err := json.Marshal(prev)
+		if err != nil {
+			return nil, err
+		}
+		data.PrevResult = string(prevJSON)
+	}
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to execute result template: %w", err)
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugintest provides fake plugin executables for testing code
+// that runs CNI plugins, such as runtimes built on libcni, without
+// installing real plugins.
+//
+// A fake plugin is a copy of the test binary itself. Call Main from
+// TestMain so that, when the binary is run as a plugin, it behaves as its
+// Plugin description says instead of running the tests:
+//
+//	func TestMain(m *testing.M) {
+//		plugintest.Main()
+//		os.Exit(m.Run())
+//	}
+//
+// Then install plugins into a directory used as CNI_PATH:
+//
+//	fake, err := plugintest.Install(dir, plugintest.Plugin{
+//		Name: "bridge",
+//		Responses: map[string]plugintest.Response{
+//			"ADD": {Result: `{"cniVersion": "{{.CNIVersion}}", "ips": [{"address": "10.1.2.3/24"}]}`},
+//		},
+//	})
+package plugintest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// specSuffix is appended to a fake plugin's path, without any ".exe"
+// extension, to find its description
+const specSuffix = ".plugintest.json"
+
+// Plugin describes how a fake plugin behaves
+type Plugin struct {
+	// Name is the plugin's type, i.e. its file name in CNI_PATH
+	Name string `json:"name"`
+	// Versions are the spec versions reported by VERSION. They default
+	// to every version this library supports.
+	Versions []string `json:"versions,omitempty"`
+	// Capabilities, if set, are advertised by VERSION
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Features, if set, are advertised by VERSION with version.WithFeatures
+	Features []version.Feature `json:"features,omitempty"`
+	// Responses maps a command ("ADD", "CHECK", "DEL", "GC" or "STATUS")
+	// to the plugin's response. Commands without a response succeed; ADD
+	// then returns the prevResult, or an empty result if there is none.
+	Responses map[string]Response `json:"responses,omitempty"`
+	// Worker makes the fake run as a worker of a daemon.Pool when started
+	// as one, as plugins built with daemon.PluginMain do. The results of
+	// a worker must be valid, as they are printed with skel.PrintResult.
+	Worker bool `json:"worker,omitempty"`
+}
+
+// Response is a fake plugin's response to a command
+type Response struct {
+	// Delay is how long the plugin sleeps before responding
+	Delay time.Duration `json:"delay,omitempty"`
+	// Stderr is written to stderr
+	Stderr string `json:"stderr,omitempty"`
+
+	// Result is a text/template for stdout, executed with a TemplateData.
+	// It is printed as is, so it may also be used for malformed output.
+	Result string `json:"result,omitempty"`
+
+	// ErrorCode and ErrorMsg, if ErrorCode is nonzero, make the plugin
+	// fail with a CNI error instead of printing Result
+	ErrorCode    uint   `json:"errorCode,omitempty"`
+	ErrorMsg     string `json:"errorMsg,omitempty"`
+	ErrorDetails string `json:"errorDetails,omitempty"`
+
+	// ExitCode, if nonzero, makes the plugin exit with this code after
+	// printing Result, without printing a CNI error
+	ExitCode int `json:"exitCode,omitempty"`
+}
+
+// TemplateData is what a Result template is executed with
+type TemplateData struct {
+	Command     string
+	ContainerID string
+	NetNS       string
+	IfName      string
+	Args        string
+	// Name and CNIVersion are those of the network configuration
+	Name       string
+	CNIVersion string
+	// Config is the network configuration
+	Config map[string]interface{}
+	// PrevResult is the JSON encoding of the configuration's prevResult,
+	// or empty if it has none
+	PrevResult string
+}
+
+// Invocation records one run of a fake plugin
+type Invocation struct {
+	Command     string `json:"command"`
+	ContainerID string `json:"containerId,omitempty"`
+	NetNS       string `json:"netns,omitempty"`
+	IfName      string `json:"ifName,omitempty"`
+	Args        string `json:"args,omitempty"`
+	Path        string `json:"path,omitempty"`
+	// StdinData is the network configuration the plugin was given
+	StdinData json.RawMessage `json:"stdinData"`
+}
+
+// Fake is an installed fake plugin
+type Fake struct {
+	// Path is the plugin executable
+	Path string
+}
+
+// Install creates a fake plugin in dir, which is created if needed, by
+// linking or copying the running test binary, and returns it. Installing
+// a plugin of the same name again replaces its description and clears
+// its recorded invocations.
+func Install(dir string, p Plugin) (*Fake, error) {
+	if p.Name == "" || strings.ContainsAny(p.Name, `/\`) {
+		return nil, fmt.Errorf("invalid plugin name %q", p.Name)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	f := &Fake{Path: filepath.Join(dir, p.Name)}
+	if runtime.GOOS == "windows" {
+		f.Path += ".exe"
+	}
+	if _, err := os.Stat(f.Path); os.IsNotExist(err) {
+		self, err := os.Executable()
+		if err != nil {
+			return nil, err
+		}
+		if err := linkOrCopy(self, f.Path); err != nil {
+			return nil, fmt.Errorf("failed to install fake plugin %q: %w", p.Name, err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(specPath(f.Path), data, 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(logPath(f.Path)); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return f, nil
+}
+
+// InstallAll installs each of plugins in dir and returns the fakes by
+// plugin name.
+func InstallAll(dir string, plugins ...Plugin) (map[string]*Fake, error) {
+	fakes := make(map[string]*Fake, len(plugins))
+	for _, p := range plugins {
+		f, err := Install(dir, p)
+		if err != nil {
+			return nil, err
+		}
+		fakes[p.Name] = f
+	}
+	return fakes, nil
+}
+
+// Invocations returns the recorded runs of the plugin, oldest first.
+// VERSION is answered without being recorded.
+func (f *Fake) Invocations() ([]Invocation, error) {
+	data, err := os.ReadFile(logPath(f.Path))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var invocations []Invocation
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	for {
+		var inv Invocation
+		if err := dec.Decode(&inv); err == io.EOF {
+			return invocations, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read invocations of %s: %w", f.Path, err)
+		}
+		invocations = append(invocations, inv)
+	}
+}
+
+func basePath(exe string) string {
+	return strings.TrimSuffix(exe, ".exe")
+}
+
+func specPath(exe string) string {
+	return basePath(exe) + specSuffix
+}
+
+func logPath(exe string) string {
+	return basePath(exe) + ".plugintest.log"
+}
+
+func linkOrCopy(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
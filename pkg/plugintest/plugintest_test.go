@@ -0,0 +1,194 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugintest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/libcni"
+	"github.com/containernetworking/cni/pkg/plugintest"
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+var _ = Describe("Fake plugins", func() {
+	var (
+		pluginDir string
+		cniConfig *libcni.CNIConfig
+		rt        *libcni.RuntimeConf
+		ctx       context.Context
+	)
+
+	BeforeEach(func() {
+		pluginDir = GinkgoT().TempDir()
+		cniConfig = libcni.NewCNIConfig([]string{pluginDir}, nil)
+		rt = &libcni.RuntimeConf{
+			ContainerID: "some-container-id",
+			NetNS:       "/some/netns/path",
+			IfName:      "eth0",
+			CacheDir:    GinkgoT().TempDir(),
+		}
+		ctx = context.TODO()
+	})
+
+	netConfList := func(types ...string) *libcni.NetworkConfigList {
+		plugins := []map[string]interface{}{}
+		for _, t := range types {
+			plugins = append(plugins, map[string]interface{}{"type": t})
+		}
+		conf, err := json.Marshal(map[string]interface{}{
+			"cniVersion": "1.0.0",
+			"name":       "some-network",
+			"plugins":    plugins,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		list, err := libcni.ConfListFromBytes(conf)
+		Expect(err).NotTo(HaveOccurred())
+		return list
+	}
+
+	It("returns the prevResult from ADD by default", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{
+			Name: "first",
+			Responses: map[string]plugintest.Response{
+				"ADD": {Result: `{"cniVersion": "{{.CNIVersion}}", "interfaces": [{"name": "{{.IfName}}"}]}`},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = plugintest.Install(pluginDir, plugintest.Plugin{Name: "second"})
+		Expect(err).NotTo(HaveOccurred())
+
+		r, err := cniConfig.AddNetworkList(ctx, netConfList("first", "second"), rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Version()).To(Equal("1.0.0"))
+		result, err := current.GetResult(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Interfaces).To(HaveLen(1))
+		Expect(result.Interfaces[0].Name).To(Equal("eth0"))
+	})
+
+	It("fails with the configured error", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{
+			Name: "broken",
+			Responses: map[string]plugintest.Response{
+				"ADD": {ErrorCode: 7, ErrorMsg: "bad config", ErrorDetails: "no subnet"},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cniConfig.AddNetworkList(ctx, netConfList("broken"), rt)
+		Expect(err).To(HaveOccurred())
+		var cniErr *types.Error
+		Expect(errors.As(err, &cniErr)).To(BeTrue(), "error %v is not a CNI error", err)
+		Expect(cniErr.Code).To(BeEquivalentTo(7))
+		Expect(cniErr.Msg).To(Equal("bad config"))
+		Expect(cniErr.Details).To(Equal("no subnet"))
+	})
+
+	It("fails when the plugin exits without a CNI error", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{
+			Name: "crashing",
+			Responses: map[string]plugintest.Response{
+				"ADD": {Result: "not json", ExitCode: 3},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = cniConfig.AddNetworkList(ctx, netConfList("crashing"), rt)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("reports the configured versions", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{
+			Name:     "old",
+			Versions: []string{"0.3.1", "0.4.0"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := cniConfig.GetVersionInfo(ctx, "old")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.SupportedVersions()).To(ConsistOf("0.3.1", "0.4.0"))
+	})
+
+	It("records its invocations", func() {
+		fake, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: "recorder"})
+		Expect(err).NotTo(HaveOccurred())
+		list := netConfList("recorder")
+
+		_, err = cniConfig.AddNetworkList(ctx, list, rt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cniConfig.DelNetworkList(ctx, list, rt)).To(Succeed())
+
+		invocations, err := fake.Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invocations).To(HaveLen(2))
+		Expect(invocations[0].Command).To(Equal("ADD"))
+		Expect(invocations[1].Command).To(Equal("DEL"))
+		for _, inv := range invocations {
+			Expect(inv.ContainerID).To(Equal("some-container-id"))
+			Expect(inv.NetNS).To(Equal("/some/netns/path"))
+			Expect(inv.IfName).To(Equal("eth0"))
+			Expect(inv.Path).To(Equal(pluginDir))
+
+			var conf map[string]interface{}
+			Expect(json.Unmarshal(inv.StdinData, &conf)).To(Succeed())
+			Expect(conf).To(HaveKeyWithValue("name", "some-network"))
+		}
+	})
+
+	It("clears recorded invocations when installed again", func() {
+		fake, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: "recorder"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = cniConfig.AddNetworkList(ctx, netConfList("recorder"), rt)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = plugintest.Install(pluginDir, plugintest.Plugin{Name: "recorder"})
+		Expect(err).NotTo(HaveOccurred())
+		invocations, err := fake.Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invocations).To(BeEmpty())
+	})
+
+	It("installs several plugins at once", func() {
+		fakes, err := plugintest.InstallAll(pluginDir,
+			plugintest.Plugin{Name: "first"}, plugintest.Plugin{Name: "second"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakes).To(HaveLen(2))
+		Expect(fakes["second"].Path).To(HavePrefix(filepath.Join(pluginDir, "second")))
+
+		_, err = cniConfig.AddNetworkList(ctx, netConfList("second"), rt)
+		Expect(err).NotTo(HaveOccurred())
+		invocations, err := fakes["second"].Invocations()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(invocations).To(HaveLen(1))
+
+		_, err = plugintest.InstallAll(pluginDir, plugintest.Plugin{Name: "third"}, plugintest.Plugin{})
+		Expect(err).To(MatchError(`invalid plugin name ""`))
+	})
+
+	It("rejects invalid plugin names", func() {
+		_, err := plugintest.Install(pluginDir, plugintest.Plugin{Name: "../escape"})
+		Expect(err).To(MatchError(ContainSubstring("invalid plugin name")))
+		_, err = os.Stat(filepath.Join(filepath.Dir(pluginDir), "escape"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
@@ -0,0 +1,103 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConfigArgs are the "args" of a network configuration: optional
+// arguments from the runtime, in namespaces such as "cni" for those
+// described in CONVENTIONS.md. Unlike CNI_ARGS they may be structured.
+type ConfigArgs map[string]json.RawMessage
+
+// Well-known keys of ConfigArgs
+const (
+	// ConfigArgsLabels is a list of {"key", "value"} labels
+	ConfigArgsLabels = "cni/labels"
+	// ConfigArgsIPs is a list of IPs the runtime requests, each with an
+	// optional prefix length
+	ConfigArgsIPs = "cni/ips"
+)
+
+// ArgsLabel is a label passed in ConfigArgsLabels
+type ArgsLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// ConfigArgs returns the "args" of the network configuration, which are
+// empty if it has none
+func (args *CmdArgs) ConfigArgs() (ConfigArgs, error) {
+	var conf struct {
+		Args ConfigArgs `json:"args"`
+	}
+	if err := json.Unmarshal(args.StdinData, &conf); err != nil {
+		return nil, fmt.Errorf("failed to decode args: %w", err)
+	}
+	return conf.Args, nil
+}
+
+// Get decodes the argument at key into v and reports whether it is
+// present. The key is either a namespace, such as "cni", or a namespace
+// and a name in it separated by a slash, such as "cni/labels" or
+// "example.com/priority".
+func (a ConfigArgs) Get(key string, v interface{}) (bool, error) {
+	namespace, name, nested := strings.Cut(key, "/")
+	data, ok := a[namespace]
+	if !ok {
+		return false, nil
+	}
+	if nested {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return true, fmt.Errorf("args namespace %q is not an object: %w", namespace, err)
+		}
+		if data, ok = values[name]; !ok {
+			return false, nil
+		}
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("failed to decode args %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Namespaces returns the namespaces of the arguments, sorted
+func (a ConfigArgs) Namespaces() []string {
+	namespaces := make([]string, 0, len(a))
+	for namespace := range a {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Labels returns the labels passed in ConfigArgsLabels
+func (a ConfigArgs) Labels() ([]ArgsLabel, error) {
+	var labels []ArgsLabel
+	_, err := a.Get(ConfigArgsLabels, &labels)
+	return labels, err
+}
+
+// IPs returns the IPs requested in ConfigArgsIPs
+func (a ConfigArgs) IPs() ([]string, error) {
+	var ips []string
+	_, err := a.Get(ConfigArgsIPs, &ips)
+	return ips, err
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/audit"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// audit writes the record of a command to the sink of WithAudit. The
+// command has already run, so failures are only logged.
+func (t *dispatcher) audit(cmd string, cmdArgs *CmdArgs, start time.Time, stdout []byte, cmdErr *types.Error) {
+	conf, _ := t.header(cmdArgs)
+	r := &audit.Record{
+		Time:          start,
+		CorrelationID: t.Getenv(audit.CorrelationIDEnv),
+		Source:        audit.SourcePlugin,
+		Actor:         audit.CurrentActor(),
+		Operation:     cmd,
+		Network:       conf.Name,
+		CNIVersion:    conf.CNIVersion,
+		Plugin:        conf.Type,
+		ContainerID:   cmdArgs.ContainerID,
+		IfName:        cmdArgs.IfName,
+		NetNS:         cmdArgs.Netns,
+		ConfigHash:    audit.Hash(cmdArgs.StdinData),
+		Duration:      time.Since(start).Seconds(),
+	}
+	if cmdErr != nil {
+		r.SetOutcome(cmdErr)
+	} else {
+		r.SetOutcome(nil)
+		if json.Valid(stdout) {
+			r.ResultHash = audit.Hash(stdout)
+		}
+	}
+	if err := t.Audit.Write(r); err != nil {
+		t.log().Warn("failed to write audit record", "command", cmd, "error", err)
+	}
+}
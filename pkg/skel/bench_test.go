@@ -0,0 +1,96 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+const benchConf = `{
+	"cniVersion": "1.0.0",
+	"name": "bench",
+	"type": "bridge",
+	"bridge": "cni0",
+	"isGateway": true,
+	"ipMasq": true,
+	"ipam": {
+		"type": "host-local",
+		"ranges": [[{"subnet": "10.22.0.0/16"}]],
+		"routes": [{"dst": "0.0.0.0/0"}]
+	}
+}`
+
+// benchmarkCommand runs the plugin main functions for a command of a
+// plugin which decodes its configuration and, for ADD, prints a result
+func benchmarkCommand(b *testing.B, command string) {
+	env := map[string]string{
+		"CNI_COMMAND":        command,
+		"CNI_CONTAINERID":    "bench-container",
+		"CNI_NETNS":          "/var/run/netns/bench",
+		"CNI_NETNS_OVERRIDE": "1",
+		"CNI_IFNAME":         "eth0",
+		"CNI_ARGS":           "K8S_POD_NAMESPACE=default;K8S_POD_NAME=bench",
+		"CNI_PATH":           "/opt/cni/bin",
+	}
+	result := &current.Result{
+		CNIVersion: "1.0.0",
+		Interfaces: []*current.Interface{{Name: "eth0", Mac: "00:11:22:33:44:55", Sandbox: "/var/run/netns/bench"}},
+	}
+	funcs := CNIFuncs{
+		Add: func(args *CmdArgs) error {
+			conf := &types.NetConf{}
+			if err := json.Unmarshal(args.StdinData, conf); err != nil {
+				return err
+			}
+			return PrintResult(args, result, conf.CNIVersion)
+		},
+		Del: func(args *CmdArgs) error {
+			return json.Unmarshal(args.StdinData, &types.NetConf{})
+		},
+	}
+	versionInfo := version.PluginSupports("0.4.0", "1.0.0")
+	var stdout bytes.Buffer
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		stdout.Reset()
+		err := PluginMainFuncsWithIO(IO{
+			Getenv: func(key string) string { return env[key] },
+			Stdin:  strings.NewReader(benchConf),
+			Stdout: &stdout,
+			Stderr: io.Discard,
+		}, funcs, versionInfo, "")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPluginMainAdd(b *testing.B) {
+	benchmarkCommand(b, "ADD")
+}
+
+func BenchmarkPluginMainDel(b *testing.B) {
+	benchmarkCommand(b, "DEL")
+}
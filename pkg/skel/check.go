@@ -0,0 +1,99 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// CheckVerifiers check the parts of a prevResult against the live state of
+// the host and container. Each may be nil to skip those parts. They run in
+// the plugin's network namespace; those checking the container typically
+// enter args.Netns, e.g. with ns.Do.
+type CheckVerifiers struct {
+	// Interface checks that an interface of the result exists as described
+	Interface func(args *CmdArgs, intf *current.Interface) error
+	// IP checks that an address is assigned to its interface, which is nil
+	// if the result does not say
+	IP func(args *CmdArgs, ip *current.IPConfig, intf *current.Interface) error
+	// Route checks that a route exists
+	Route func(args *CmdArgs, route *types.Route) error
+}
+
+// CheckPrevResult returns a CHECK implementation for plugins without logic
+// of their own: it decodes the prevResult the runtime passes and checks
+// each of its interfaces, addresses and routes with the verifiers,
+// failing with every mismatch found.
+//
+//	skel.PluginMainFuncs(skel.CNIFuncs{
+//		Add:   cmdAdd,
+//		Check: skel.CheckPrevResult(skel.CheckVerifiers{Interface: checkLink, IP: checkAddr}),
+//		Del:   cmdDel,
+//	}, version.All, "")
+func CheckPrevResult(verifiers CheckVerifiers) func(*CmdArgs) error {
+	return func(args *CmdArgs) error {
+		conf := &types.NetConf{}
+		if err := json.Unmarshal(args.StdinData, conf); err != nil {
+			return types.NewError(types.ErrDecodingFailure, "failed to decode network configuration", err.Error())
+		}
+		if conf.RawPrevResult == nil {
+			return types.NewError(types.ErrInvalidNetworkConfig, "CHECK requires a prevResult", "")
+		}
+		if err := version.ParsePrevResult(conf); err != nil {
+			return types.NewError(types.ErrDecodingFailure, "failed to decode prevResult", err.Error())
+		}
+		result, err := current.NewResultFromResult(conf.PrevResult)
+		if err != nil {
+			return types.NewError(types.ErrDecodingFailure, "failed to convert prevResult", err.Error())
+		}
+
+		var errs []error
+		if verifiers.Interface != nil {
+			for i, intf := range result.Interfaces {
+				if err := verifiers.Interface(args, intf); err != nil {
+					errs = append(errs, fmt.Errorf("interfaces[%d] %q: %w", i, intf.Name, err))
+				}
+			}
+		}
+		if verifiers.IP != nil {
+			for i, ip := range result.IPs {
+				var intf *current.Interface
+				if ip.Interface != nil && *ip.Interface >= 0 && *ip.Interface < len(result.Interfaces) {
+					intf = result.Interfaces[*ip.Interface]
+				}
+				if err := verifiers.IP(args, ip, intf); err != nil {
+					errs = append(errs, fmt.Errorf("ips[%d] %s: %w", i, ip.Address.String(), err))
+				}
+			}
+		}
+		if verifiers.Route != nil {
+			for i, route := range result.Routes {
+				if err := verifiers.Route(args, route); err != nil {
+					errs = append(errs, fmt.Errorf("routes[%d] %s: %w", i, route.Dst.String(), err))
+				}
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return types.NewError(types.ErrInternal, "live state does not match prevResult", err.Error())
+		}
+		return nil
+	}
+}
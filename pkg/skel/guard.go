@@ -0,0 +1,129 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/lock"
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// AddGuard detects duplicate ADDs of an attachment, its container ID and
+// interface name, with advisory marker files shared by every invocation
+// of the plugin: ADD fails with ErrTryAgainLater, without calling the
+// plugin, while another ADD of the attachment is running, or if one
+// finished less than Cooldown ago. DEL of the attachment clears its
+// markers.
+type AddGuard struct {
+	// Dir holds the markers, e.g. "/var/run/cni/myplugin/add". It is
+	// created if needed.
+	Dir string
+	// Cooldown is how long after an ADD finishes a repeated ADD is also
+	// refused. If zero, only concurrent ADDs are.
+	Cooldown time.Duration
+}
+
+// markerPath returns the lock file of the attachment. The names are
+// hashed, as they may hold characters which are not valid in file names.
+func (g *AddGuard) markerPath(args *CmdArgs) string {
+	sum := sha256.Sum256([]byte(args.ContainerID + "\x00" + args.IfName))
+	return filepath.Join(g.Dir, hex.EncodeToString(sum[:16]))
+}
+
+// doneMarker records, by its modification time, when the last ADD of the
+// attachment finished
+func doneMarker(path string) string {
+	return path + ".done"
+}
+
+// add wraps the plugin's ADD
+func (g *AddGuard) add(toCall func(*CmdArgs) error) func(*CmdArgs) error {
+	return func(args *CmdArgs) error {
+		path := g.markerPath(args)
+		l, err := lock.New(path)
+		if err != nil {
+			return types.NewError(types.ErrIOFailure, "failed to create ADD marker", err.Error())
+		}
+		defer l.Close()
+		if ok, err := l.TryLock(); err != nil {
+			return types.NewError(types.ErrIOFailure, "failed to take ADD marker", err.Error())
+		} else if !ok {
+			return types.NewError(types.ErrTryAgainLater, "ADD of this attachment is already in progress",
+				fmt.Sprintf("container %s, interface %s", args.ContainerID, args.IfName))
+		}
+
+		done := doneMarker(path)
+		if g.Cooldown > 0 {
+			if info, err := os.Stat(done); err == nil {
+				if since := time.Since(info.ModTime()); since < g.Cooldown {
+					return types.NewError(types.ErrTryAgainLater, "ADD of this attachment was repeated too soon",
+						fmt.Sprintf("container %s, interface %s, last ADD finished %v ago", args.ContainerID, args.IfName, since.Round(time.Millisecond)))
+				}
+			}
+		}
+
+		err = toCall(args)
+		if g.Cooldown > 0 {
+			// The ADD ran whatever the marker says; failing to write it
+			// only weakens the guard
+			_ = touch(done)
+		}
+		return err
+	}
+}
+
+// del wraps the plugin's DEL, clearing the markers of the attachment once
+// it succeeds. The markers of an attachment whose ADD is running are kept.
+func (g *AddGuard) del(toCall func(*CmdArgs) error) func(*CmdArgs) error {
+	return func(args *CmdArgs) error {
+		if err := toCall(args); err != nil {
+			return err
+		}
+		path := g.markerPath(args)
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		l, err := lock.New(path)
+		if err != nil {
+			return nil
+		}
+		defer l.Close()
+		if ok, _ := l.TryLock(); ok {
+			_ = os.Remove(doneMarker(path))
+			_ = os.Remove(path)
+		}
+		return nil
+	}
+}
+
+func touch(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
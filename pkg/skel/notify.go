@@ -0,0 +1,91 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package skel
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// NotifySocketEnv is the environment variable in which a runtime or node
+// agent may pass the path of a unix datagram socket. The plugin main
+// functions then send a Notification to it whenever ADD, CHECK or DEL
+// finishes, in addition to printing the outcome to stdout, so that node
+// agents can observe attachments as they happen.
+const NotifySocketEnv = "CNI_NOTIFY_SOCKET"
+
+// DefaultNotifyTimeout bounds how long sending a Notification may block
+// the plugin, unless WithNotifyTimeout is given
+const DefaultNotifyTimeout = 100 * time.Millisecond
+
+// Notification is the outcome of a command, sent as a JSON datagram to the
+// socket named by NotifySocketEnv
+type Notification struct {
+	Command     string `json:"command"`
+	ContainerID string `json:"containerId"`
+	IfName      string `json:"ifName"`
+	// Network and Plugin are the name and type of the network
+	// configuration
+	Network string `json:"network,omitempty"`
+	Plugin  string `json:"plugin,omitempty"`
+	// Result is what the plugin printed to stdout, if anything. Plugins run
+	// by PluginMainFuncsWithIO must print it with PrintResult.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error the command failed with, if any
+	Error *types.Error `json:"error,omitempty"`
+}
+
+// notifiedCommands are the commands of an attachment
+var notifiedCommands = map[string]bool{"ADD": true, "CHECK": true, "DEL": true}
+
+// notify sends the outcome of the command to the socket. The command has
+// already run, so failures are only logged.
+func (t *dispatcher) notify(socketPath, cmd string, cmdArgs *CmdArgs, stdout []byte, cmdErr *types.Error) {
+	conf, _ := t.header(cmdArgs)
+	n := &Notification{
+		Command:     cmd,
+		ContainerID: cmdArgs.ContainerID,
+		IfName:      cmdArgs.IfName,
+		Network:     conf.Name,
+		Plugin:      conf.Type,
+		Error:       cmdErr,
+	}
+	if cmdErr == nil && json.Valid(stdout) {
+		n.Result = stdout
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		t.log().Warn("failed to encode notification", "error", err)
+		return
+	}
+
+	timeout := t.NotifyTimeout
+	if timeout == 0 {
+		timeout = DefaultNotifyTimeout
+	}
+	conn, err := net.DialTimeout("unixgram", socketPath, timeout)
+	if err != nil {
+		t.log().Warn("failed to connect to notification socket", "socket", socketPath, "error", err)
+		return
+	}
+	defer conn.Close()
+	_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(data); err != nil {
+		t.log().Warn("failed to send notification", "socket", socketPath, "error", err)
+	}
+}
@@ -23,11 +23,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/containernetworking/cni/pkg/audit"
 	"github.com/containernetworking/cni/pkg/ns"
+	"github.com/containernetworking/cni/pkg/strictjson"
 	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
 	"github.com/containernetworking/cni/pkg/utils"
 	"github.com/containernetworking/cni/pkg/version"
 )
@@ -42,6 +48,14 @@ type CmdArgs struct {
 	Path          string
 	NetnsOverride string
 	StdinData     []byte
+	// CDIDevices are the CDI devices allocated to the attachment, from
+	// CNI_CDI_DEVICES
+	CDIDevices []string
+}
+
+// Devices returns the parsed CDI devices allocated to the attachment
+func (args *CmdArgs) Devices() ([]capabilities.CDIDevice, error) {
+	return capabilities.ParseCDIDevices(args.CDIDevices)
 }
 
 type dispatcher struct {
@@ -50,110 +64,149 @@ type dispatcher struct {
 	Stdout io.Writer
 	Stderr io.Writer
 
-	ConfVersionDecoder version.ConfigDecoder
-	VersionReconciler  version.Reconciler
+	VersionReconciler version.Reconciler
+
+	// WarnDeprecated enables a warning on Stderr for deprecated config versions
+	WarnDeprecated bool
+	// WarnVersionSkew enables a warning on Stderr for config versions older
+	// than the newest the plugin supports
+	WarnVersionSkew bool
+	// NamePolicy validates the container ID and network name; nil means
+	// utils.DefaultNamePolicy
+	NamePolicy *utils.NamePolicy
+	// Observer, if set, is told about every command run
+	Observer CommandObserver
+	// Logger, if set, logs commands, version checks and invalid
+	// invocations
+	Logger *slog.Logger
+	// StrictDecoding, if set, checks stdin with pkg/strictjson
+	StrictDecoding *strictjson.Limits
+	// FeatureGates enables commands of draft spec versions
+	FeatureGates version.FeatureGates
+	// Guard, if set, refuses duplicate ADDs
+	Guard *AddGuard
+	// CleanupFailedAdd enables calling DEL when ADD fails
+	CleanupFailedAdd bool
+	// Audit, if set, receives a record of every command but VERSION
+	Audit audit.Sink
+	// NotifyTimeout bounds sending a Notification; DefaultNotifyTimeout if
+	// zero
+	NotifyTimeout time.Duration
+	// RedirectStdout makes commands whose output is captured for Audit or
+	// notifications point os.Stdout at the capture while they run, so that
+	// results printed with types.PrintResult are captured too. It is only
+	// safe when Stdout is the process's stdout and no other invocation
+	// runs concurrently.
+	RedirectStdout bool
+
+	// conf is the header of the network configuration, once decoded
+	conf *netConfHeader
+}
+
+// CommandObserver receives measurements of the commands a plugin runs, such
+// as the Prometheus collector in pkg/metrics
+type CommandObserver interface {
+	// ObserveCommand is called when a command returns, with the error
+	// printed for it, if any
+	ObserveCommand(command string, duration time.Duration, err error)
+}
+
+// ConfigFileEnv is the environment variable naming a file the plugin main
+// functions read the network configuration from when stdin is empty, or a
+// terminal, so that a plugin can be run by hand or from a wrapper script:
+//
+//	CNI_COMMAND=ADD CNI_CONFIG_FILE=mynet.conf ... ./myplugin
+const ConfigFileEnv = "CNI_CONFIG_FILE"
+
+// isTerminal returns whether r is a terminal, which must not be read as
+// it would wait for the user to type a configuration
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// discardLogger is used when there is no Logger
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func (t *dispatcher) log() *slog.Logger {
+	if t.Logger == nil {
+		return discardLogger
+	}
+	return t.Logger
+}
+
+func (t *dispatcher) namePolicy() *utils.NamePolicy {
+	if t.NamePolicy == nil {
+		return &utils.DefaultNamePolicy
+	}
+	return t.NamePolicy
 }
 
 type reqForCmdEntry map[string]bool
 
+// cniEnvVar is an environment variable of a plugin invocation, with the
+// commands requiring it, for which it is also validated
+type cniEnvVar struct {
+	name      string
+	reqForCmd reqForCmdEntry
+}
+
+// The indexes of cniEnvVars
+const (
+	envCommand = iota
+	envContainerID
+	envNetNS
+	envIfName
+	envArgs
+	envPath
+	envNetNSOverride
+)
+
+// cniEnvVars are read by getCmdArgsFromEnv, in order. They are declared
+// once rather than per invocation to keep the hot path free of
+// allocations.
+var cniEnvVars = [...]cniEnvVar{
+	envCommand:       {"CNI_COMMAND", reqForCmdEntry{"ADD": true, "CHECK": true, "DEL": true, "GC": true, "STATUS": true}},
+	envContainerID:   {"CNI_CONTAINERID", reqForCmdEntry{"ADD": true, "CHECK": true, "DEL": true}},
+	envNetNS:         {"CNI_NETNS", reqForCmdEntry{"ADD": true, "CHECK": true}},
+	envIfName:        {"CNI_IFNAME", reqForCmdEntry{"ADD": true, "CHECK": true, "DEL": true}},
+	envArgs:          {"CNI_ARGS", nil},
+	envPath:          {"CNI_PATH", reqForCmdEntry{"ADD": true, "CHECK": true, "DEL": true, "GC": true, "STATUS": true}},
+	envNetNSOverride: {"CNI_NETNS_OVERRIDE", nil},
+}
+
+// validateEnv validates the value of a required environment variable
+func (t *dispatcher) validateEnv(index int, value string) *types.Error {
+	switch index {
+	case envContainerID:
+		return t.namePolicy().ValidateContainerID(value)
+	case envIfName:
+		return utils.ValidateInterfaceName(value)
+	}
+	return nil
+}
+
 func (t *dispatcher) getCmdArgsFromEnv() (string, *CmdArgs, *types.Error) {
-	var cmd, contID, netns, ifName, args, path, netnsOverride string
-
-	vars := []struct {
-		name       string
-		val        *string
-		reqForCmd  reqForCmdEntry
-		validateFn func(string) *types.Error
-	}{
-		{
-			"CNI_COMMAND",
-			&cmd,
-			reqForCmdEntry{
-				"ADD":    true,
-				"CHECK":  true,
-				"DEL":    true,
-				"GC":     true,
-				"STATUS": true,
-			},
-			nil,
-		},
-		{
-			"CNI_CONTAINERID",
-			&contID,
-			reqForCmdEntry{
-				"ADD":   true,
-				"CHECK": true,
-				"DEL":   true,
-			},
-			utils.ValidateContainerID,
-		},
-		{
-			"CNI_NETNS",
-			&netns,
-			reqForCmdEntry{
-				"ADD":   true,
-				"CHECK": true,
-				"DEL":   false,
-			},
-			nil,
-		},
-		{
-			"CNI_IFNAME",
-			&ifName,
-			reqForCmdEntry{
-				"ADD":   true,
-				"CHECK": true,
-				"DEL":   true,
-			},
-			utils.ValidateInterfaceName,
-		},
-		{
-			"CNI_ARGS",
-			&args,
-			reqForCmdEntry{
-				"ADD":   false,
-				"CHECK": false,
-				"DEL":   false,
-			},
-			nil,
-		},
-		{
-			"CNI_PATH",
-			&path,
-			reqForCmdEntry{
-				"ADD":    true,
-				"CHECK":  true,
-				"DEL":    true,
-				"GC":     true,
-				"STATUS": true,
-			},
-			nil,
-		},
-		{
-			"CNI_NETNS_OVERRIDE",
-			&netnsOverride,
-			reqForCmdEntry{
-				"ADD":   false,
-				"CHECK": false,
-				"DEL":   false,
-			},
-			nil,
-		},
-	}
-
-	argsMissing := make([]string, 0)
-	for _, v := range vars {
-		*v.val = t.Getenv(v.name)
-		if *v.val == "" {
-			if v.reqForCmd[cmd] || v.name == "CNI_COMMAND" {
+	var vals [len(cniEnvVars)]string
+	var argsMissing []string
+	for i, v := range cniEnvVars {
+		vals[i] = t.Getenv(v.name)
+		cmd := vals[envCommand]
+		if vals[i] == "" {
+			if v.reqForCmd[cmd] || i == envCommand {
 				argsMissing = append(argsMissing, v.name)
 			}
-		} else if v.reqForCmd[cmd] && v.validateFn != nil {
-			if err := v.validateFn(*v.val); err != nil {
+		} else if v.reqForCmd[cmd] {
+			if err := t.validateEnv(i, vals[i]); err != nil {
 				return "", nil, err
 			}
 		}
 	}
+	cmd := vals[envCommand]
 
 	if len(argsMissing) > 0 {
 		joined := strings.Join(argsMissing, ",")
@@ -164,38 +217,115 @@ func (t *dispatcher) getCmdArgsFromEnv() (string, *CmdArgs, *types.Error) {
 		t.Stdin = bytes.NewReader(nil)
 	}
 
-	stdinData, err := io.ReadAll(t.Stdin)
-	if err != nil {
-		return "", nil, types.NewError(types.ErrIOFailure, fmt.Sprintf("error reading from stdin: %v", err), "")
+	var cdiDevices []string
+	if devices := t.Getenv(capabilities.CDIDevicesEnv); devices != "" {
+		cdiDevices = strings.Split(devices, ",")
+		if _, err := capabilities.ParseCDIDevices(cdiDevices); err != nil {
+			return "", nil, types.NewError(types.ErrInvalidEnvironmentVariables, "invalid "+capabilities.CDIDevicesEnv, err.Error())
+		}
+	}
+
+	configFile := ""
+	if cmd != "VERSION" {
+		configFile = t.Getenv(ConfigFileEnv)
+	}
+	var stdinData []byte
+	if configFile == "" || !isTerminal(t.Stdin) {
+		var err error
+		stdinData, err = io.ReadAll(t.Stdin)
+		if err != nil {
+			return "", nil, types.NewError(types.ErrIOFailure, fmt.Sprintf("error reading from stdin: %v", err), "")
+		}
+	}
+	if configFile != "" && len(bytes.TrimSpace(stdinData)) == 0 {
+		var err error
+		stdinData, err = os.ReadFile(configFile)
+		if err != nil {
+			return "", nil, types.NewError(types.ErrIOFailure, fmt.Sprintf("error reading %s: %v", ConfigFileEnv, err), "")
+		}
 	}
 
 	if cmd != "VERSION" {
-		if err := validateConfig(stdinData); err != nil {
+		if t.StrictDecoding != nil {
+			if err := strictjson.Check(stdinData, *t.StrictDecoding); err != nil {
+				return "", nil, types.NewError(types.ErrDecodingFailure, "invalid network configuration", err.Error())
+			}
+		}
+		conf, err := validateConfig(stdinData, t.namePolicy())
+		if err != nil {
 			return "", nil, err
 		}
+		t.conf = conf
 	}
 
 	cmdArgs := &CmdArgs{
-		ContainerID:   contID,
-		Netns:         netns,
-		IfName:        ifName,
-		Args:          args,
-		Path:          path,
+		ContainerID:   vals[envContainerID],
+		Netns:         vals[envNetNS],
+		IfName:        vals[envIfName],
+		Args:          vals[envArgs],
+		Path:          vals[envPath],
 		StdinData:     stdinData,
-		NetnsOverride: netnsOverride,
+		NetnsOverride: vals[envNetNSOverride],
+		CDIDevices:    cdiDevices,
 	}
 	return cmd, cmdArgs, nil
 }
 
+// checkFeatureAndCall calls toCall for a command which was introduced in a
+// later version of the spec, if both the config version and the versions the
+// plugin supports allow it
+func (t *dispatcher) checkFeatureAndCall(command version.Feature, cmdArgs *CmdArgs, pluginVersionInfo version.PluginInfo, toCall func(*CmdArgs) error) *types.Error {
+	configVersion, err := t.configVersion(cmdArgs)
+	if err != nil {
+		return types.NewError(types.ErrDecodingFailure, err.Error(), "")
+	}
+	if supported, err := version.Supports(command, configVersion); err != nil {
+		return types.NewError(types.ErrDecodingFailure, err.Error(), "")
+	} else if supported && !t.FeatureGates.Enabled(command) {
+		t.log().Warn("command of a draft spec version is not enabled", "command", command)
+		return types.NewError(types.ErrIncompatibleCNIVersion, fmt.Sprintf("%s is a draft feature which is not enabled", command), "")
+	} else if !supported {
+		t.log().Warn("config version does not allow command", "command", command, "version", configVersion)
+		return types.NewError(types.ErrIncompatibleCNIVersion, fmt.Sprintf("config version does not allow %s", command), "")
+	}
+	for _, pluginVersion := range pluginVersionInfo.SupportedVersions() {
+		gtet, err := version.GreaterThanOrEqualTo(pluginVersion, configVersion)
+		if err != nil {
+			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
+		} else if gtet {
+			return t.checkVersionAndCall(cmdArgs, pluginVersionInfo, toCall)
+		}
+	}
+	t.log().Warn("plugin version does not allow command", "command", command, "supported", pluginVersionInfo.SupportedVersions())
+	return types.NewError(types.ErrIncompatibleCNIVersion, fmt.Sprintf("plugin version does not allow %s", command), "")
+}
+
 func (t *dispatcher) checkVersionAndCall(cmdArgs *CmdArgs, pluginVersionInfo version.PluginInfo, toCall func(*CmdArgs) error) *types.Error {
-	configVersion, err := t.ConfVersionDecoder.Decode(cmdArgs.StdinData)
+	configVersion, err := t.configVersion(cmdArgs)
 	if err != nil {
 		return types.NewError(types.ErrDecodingFailure, err.Error(), "")
 	}
 	verErr := t.VersionReconciler.Check(configVersion, pluginVersionInfo)
 	if verErr != nil {
+		t.log().Warn("incompatible CNI versions", "version", configVersion, "supported", pluginVersionInfo.SupportedVersions())
 		return types.NewError(types.ErrIncompatibleCNIVersion, "incompatible CNI versions", verErr.Details())
 	}
+	if t.WarnDeprecated {
+		// configVersion reports a missing cniVersion as 0.1.0; warn about
+		// the raw field so the warning can say it is missing
+		conf, _ := t.header(cmdArgs)
+		if warning := version.DeprecationWarning(conf.CNIVersion); warning != "" {
+			_, _ = fmt.Fprintf(t.Stderr, "WARNING: %s\n", warning)
+		}
+	}
+	if t.WarnVersionSkew {
+		if warning, latest := version.SkewWarning(configVersion, pluginVersionInfo); warning != "" {
+			_, _ = fmt.Fprintf(t.Stderr, "WARNING: %s\n", warning)
+			t.log().Warn("config version is older than the newest supported", "version", configVersion, "latest", latest)
+		}
+	}
+
+	t.log().Debug("config version is supported", "version", configVersion)
 
 	if toCall == nil {
 		return nil
@@ -213,20 +343,52 @@ func (t *dispatcher) checkVersionAndCall(cmdArgs *CmdArgs, pluginVersionInfo ver
 	return nil
 }
 
-func validateConfig(jsonBytes []byte) *types.Error {
-	var conf struct {
-		Name string `json:"name"`
+// netConfHeader holds the fields of the network configuration the plugin
+// main functions need themselves, decoded once per invocation
+type netConfHeader struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	CNIVersion string `json:"cniVersion"`
+}
+
+// header returns the header of the network configuration, decoding it
+// unless getCmdArgsFromEnv already did
+func (t *dispatcher) header(cmdArgs *CmdArgs) (*netConfHeader, error) {
+	if t.conf == nil {
+		conf := &netConfHeader{}
+		if err := json.Unmarshal(cmdArgs.StdinData, conf); err != nil {
+			return conf, err
+		}
+		t.conf = conf
 	}
-	if err := json.Unmarshal(jsonBytes, &conf); err != nil {
-		return types.NewError(types.ErrDecodingFailure, fmt.Sprintf("error unmarshall network config: %v", err), "")
+	return t.conf, nil
+}
+
+// configVersion returns the cniVersion of the network configuration, which
+// is 0.1.0 if missing
+func (t *dispatcher) configVersion(cmdArgs *CmdArgs) (string, error) {
+	conf, err := t.header(cmdArgs)
+	if err != nil {
+		return "", fmt.Errorf("decoding version from network config: %w", err)
+	}
+	if conf.CNIVersion == "" {
+		return "0.1.0", nil
+	}
+	return conf.CNIVersion, nil
+}
+
+func validateConfig(jsonBytes []byte, policy *utils.NamePolicy) (*netConfHeader, *types.Error) {
+	conf := &netConfHeader{}
+	if err := json.Unmarshal(jsonBytes, conf); err != nil {
+		return nil, types.NewError(types.ErrDecodingFailure, fmt.Sprintf("error unmarshall network config: %v", err), "")
 	}
 	if conf.Name == "" {
-		return types.NewError(types.ErrInvalidNetworkConfig, "missing network name", "")
+		return nil, types.NewError(types.ErrInvalidNetworkConfig, "missing network name", "")
 	}
-	if err := utils.ValidateNetworkName(conf.Name); err != nil {
-		return err
+	if err := policy.ValidateNetworkName(conf.Name); err != nil {
+		return nil, err
 	}
-	return nil
+	return conf, nil
 }
 
 func (t *dispatcher) pluginMain(funcs CNIFuncs, versionInfo version.PluginInfo, about string) *types.Error {
@@ -238,9 +400,97 @@ func (t *dispatcher) pluginMain(funcs CNIFuncs, versionInfo version.PluginInfo,
 			_, _ = fmt.Fprintf(t.Stderr, "CNI protocol versions supported: %s\n", strings.Join(versionInfo.SupportedVersions(), ", "))
 			return nil
 		}
+		t.log().Warn("invalid invocation", "command", t.Getenv("CNI_COMMAND"), "error", err)
 		return err
 	}
 
+	stdout := t.Stdout
+	var captured *bytes.Buffer
+	notifySocket := t.Getenv(NotifySocketEnv)
+	notify := notifySocket != "" && notifiedCommands[cmd]
+	audited := t.Audit != nil && cmd != "VERSION"
+	restoreStdout := func() {}
+	if notify || audited {
+		captured = &bytes.Buffer{}
+		stdout = io.MultiWriter(t.Stdout, captured)
+		if t.RedirectStdout {
+			pw, restore, redirectErr := redirectStdout(stdout)
+			if redirectErr != nil {
+				t.log().Warn("failed to redirect stdout, only results printed with skel.PrintResult are captured", "error", redirectErr)
+			} else {
+				stdout, restoreStdout = pw, restore
+			}
+		}
+	}
+
+	start := time.Now()
+	invocationStdouts.Store(cmdArgs, stdout)
+	err = t.runCommand(cmd, cmdArgs, funcs, versionInfo)
+	invocationStdouts.Delete(cmdArgs)
+	restoreStdout()
+	if notify {
+		t.notify(notifySocket, cmd, cmdArgs, captured.Bytes(), err)
+	}
+	if audited {
+		t.audit(cmd, cmdArgs, start, captured.Bytes(), err)
+	}
+	if err != nil {
+		t.log().Warn("command failed", "command", cmd, "containerID", cmdArgs.ContainerID,
+			"ifName", cmdArgs.IfName, "duration", time.Since(start), "error", err)
+	} else {
+		t.log().Debug("command succeeded", "command", cmd, "containerID", cmdArgs.ContainerID,
+			"ifName", cmdArgs.IfName, "duration", time.Since(start))
+	}
+	if t.Observer != nil {
+		var observed error
+		if err != nil {
+			observed = err
+		}
+		t.Observer.ObserveCommand(cmd, time.Since(start), observed)
+	}
+	return err
+}
+
+// redirectStdout points os.Stdout at a pipe whose contents are copied to
+// w, for plugins which print their result to os.Stdout rather than with
+// PrintResult. It returns the pipe, which PrintResult should write to so
+// that all output reaches w in order, and a function which restores
+// os.Stdout and waits for the copy to finish.
+func redirectStdout(w io.Writer) (io.Writer, func(), error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(w, pr)
+		pr.Close()
+		close(done)
+	}()
+
+	orig := os.Stdout
+	os.Stdout = pw
+	return pw, func() {
+		os.Stdout = orig
+		pw.Close()
+		<-done
+	}, nil
+}
+
+func (t *dispatcher) runCommand(cmd string, cmdArgs *CmdArgs, funcs CNIFuncs, versionInfo version.PluginInfo) *types.Error {
+	if t.CleanupFailedAdd && funcs.Add != nil && funcs.Del != nil {
+		funcs.Add = cleanupFailedAdd(funcs.Add, funcs.Del)
+	}
+	if t.Guard != nil {
+		if funcs.Add != nil {
+			funcs.Add = t.Guard.add(funcs.Add)
+		}
+		if funcs.Del != nil {
+			funcs.Del = t.Guard.del(funcs.Del)
+		}
+	}
+
+	var err *types.Error
 	switch cmd {
 	case "ADD":
 		err = t.checkVersionAndCall(cmdArgs, versionInfo, funcs.Add)
@@ -256,27 +506,7 @@ func (t *dispatcher) pluginMain(funcs CNIFuncs, versionInfo version.PluginInfo,
 			}
 		}
 	case "CHECK":
-		configVersion, err := t.ConfVersionDecoder.Decode(cmdArgs.StdinData)
-		if err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		}
-		if gtet, err := version.GreaterThanOrEqualTo(configVersion, "0.4.0"); err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		} else if !gtet {
-			return types.NewError(types.ErrIncompatibleCNIVersion, "config version does not allow CHECK", "")
-		}
-		for _, pluginVersion := range versionInfo.SupportedVersions() {
-			gtet, err := version.GreaterThanOrEqualTo(pluginVersion, configVersion)
-			if err != nil {
-				return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-			} else if gtet {
-				if err := t.checkVersionAndCall(cmdArgs, versionInfo, funcs.Check); err != nil {
-					return err
-				}
-				return nil
-			}
-		}
-		return types.NewError(types.ErrIncompatibleCNIVersion, "plugin version does not allow CHECK", "")
+		err = t.checkFeatureAndCall(version.FeatureCheck, cmdArgs, versionInfo, funcs.Check)
 	case "DEL":
 		err = t.checkVersionAndCall(cmdArgs, versionInfo, funcs.Del)
 		if err != nil {
@@ -291,49 +521,9 @@ func (t *dispatcher) pluginMain(funcs CNIFuncs, versionInfo version.PluginInfo,
 			}
 		}
 	case "GC":
-		configVersion, err := t.ConfVersionDecoder.Decode(cmdArgs.StdinData)
-		if err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		}
-		if gtet, err := version.GreaterThanOrEqualTo(configVersion, "1.1.0"); err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		} else if !gtet {
-			return types.NewError(types.ErrIncompatibleCNIVersion, "config version does not allow GC", "")
-		}
-		for _, pluginVersion := range versionInfo.SupportedVersions() {
-			gtet, err := version.GreaterThanOrEqualTo(pluginVersion, configVersion)
-			if err != nil {
-				return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-			} else if gtet {
-				if err := t.checkVersionAndCall(cmdArgs, versionInfo, funcs.GC); err != nil {
-					return err
-				}
-				return nil
-			}
-		}
-		return types.NewError(types.ErrIncompatibleCNIVersion, "plugin version does not allow GC", "")
+		err = t.checkFeatureAndCall(version.FeatureGC, cmdArgs, versionInfo, funcs.GC)
 	case "STATUS":
-		configVersion, err := t.ConfVersionDecoder.Decode(cmdArgs.StdinData)
-		if err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		}
-		if gtet, err := version.GreaterThanOrEqualTo(configVersion, "1.1.0"); err != nil {
-			return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-		} else if !gtet {
-			return types.NewError(types.ErrIncompatibleCNIVersion, "config version does not allow STATUS", "")
-		}
-		for _, pluginVersion := range versionInfo.SupportedVersions() {
-			gtet, err := version.GreaterThanOrEqualTo(pluginVersion, configVersion)
-			if err != nil {
-				return types.NewError(types.ErrDecodingFailure, err.Error(), "")
-			} else if gtet {
-				if err := t.checkVersionAndCall(cmdArgs, versionInfo, funcs.Status); err != nil {
-					return err
-				}
-				return nil
-			}
-		}
-		return types.NewError(types.ErrIncompatibleCNIVersion, "plugin version does not allow STATUS", "")
+		err = t.checkFeatureAndCall(version.FeatureStatus, cmdArgs, versionInfo, funcs.Status)
 	case "VERSION":
 		if err := versionInfo.Encode(t.Stdout); err != nil {
 			return types.NewError(types.ErrIOFailure, err.Error(), "")
@@ -345,6 +535,35 @@ func (t *dispatcher) pluginMain(funcs CNIFuncs, versionInfo version.PluginInfo,
 	return err
 }
 
+// cleanupFailedAdd wraps ADD to call DEL when it fails
+func cleanupFailedAdd(add, del func(*CmdArgs) error) func(*CmdArgs) error {
+	return func(args *CmdArgs) error {
+		err := add(args)
+		if err == nil {
+			return nil
+		}
+		delErr := del(args)
+		if delErr == nil {
+			return err
+		}
+
+		e := types.NewError(types.ErrInternal, err.Error(), "")
+		var typed *types.Error
+		if errors.As(err, &typed) {
+			// copy, so as not to change an error the plugin may reuse
+			copied := *typed
+			e = &copied
+		}
+		cleanup := fmt.Sprintf("cleanup with DEL failed: %v", delErr)
+		if e.Details == "" {
+			e.Details = cleanup
+		} else {
+			e.Details += "; " + cleanup
+		}
+		return e
+	}
+}
+
 // PluginMainWithError is the core "main" for a plugin. It accepts
 // callback functions for add, check, and del CNI commands and returns an error.
 //
@@ -373,6 +592,116 @@ type CNIFuncs struct {
 	Status func(_ *CmdArgs) error
 }
 
+// Option configures an invocation of the plugin main functions
+type Option func(*dispatcher)
+
+// WithStrictDecoding makes the plugin main functions reject network
+// configurations which strictjson.Check refuses within limits, such as
+// ones with duplicate keys, before the plugin decodes them.
+func WithStrictDecoding(limits *strictjson.Limits) Option {
+	return func(t *dispatcher) {
+		t.StrictDecoding = limits
+	}
+}
+
+// WithDeprecationWarnings makes the plugin main functions print a warning
+// to stderr when invoked with a configuration using a deprecated CNI
+// version, such as 0.1.0 or 0.2.0.
+func WithDeprecationWarnings() Option {
+	return func(t *dispatcher) {
+		t.WarnDeprecated = true
+	}
+}
+
+// WithVersionSkewWarnings makes the plugin main functions print a warning
+// to stderr, and log it with the logger of WithLogger, when invoked with a
+// configuration whose CNI version is older than the newest version the
+// plugin supports, so that operators notice outdated network
+// configurations.
+func WithVersionSkewWarnings() Option {
+	return func(t *dispatcher) {
+		t.WarnVersionSkew = true
+	}
+}
+
+// WithNamePolicy makes the plugin main functions validate the container ID
+// and network name with policy rather than utils.DefaultNamePolicy, for
+// plugins used by runtimes whose identifiers the default rules reject.
+func WithNamePolicy(policy *utils.NamePolicy) Option {
+	return func(t *dispatcher) {
+		t.NamePolicy = policy
+	}
+}
+
+// WithObserver makes the plugin main functions tell observer about every
+// command they run, including those rejected before calling the plugin.
+func WithObserver(observer CommandObserver) Option {
+	return func(t *dispatcher) {
+		t.Observer = observer
+	}
+}
+
+// WithLogger makes the plugin main functions log the commands they run,
+// their version checks and invalid invocations to logger. As stdout
+// carries the plugin's result, it should write to stderr or a file.
+func WithLogger(logger *slog.Logger) Option {
+	return func(t *dispatcher) {
+		t.Logger = logger
+	}
+}
+
+// WithFeatureGates enables features of draft spec versions in the plugin
+// main functions, which refuse commands of draft versions whose gate is
+// not set. Plugins should consult the same gates before emitting draft
+// result fields, such as extensions.
+func WithFeatureGates(gates version.FeatureGates) Option {
+	return func(t *dispatcher) {
+		t.FeatureGates = gates
+	}
+}
+
+// WithCleanupFailedAdd makes the plugin main functions call the plugin's
+// DEL, with the same arguments, whenever its ADD fails, as the spec
+// expects plugins to release what a failed ADD allocated. The cleanup is
+// best effort: ADD's error is returned either way, with the error of DEL,
+// if any, appended to its details.
+func WithCleanupFailedAdd() Option {
+	return func(t *dispatcher) {
+		t.CleanupFailedAdd = true
+	}
+}
+
+// WithGuard makes the plugin main functions refuse duplicate ADDs with
+// guard, protecting plugins that are not reentrant from runtimes retrying
+// ADD while it is still running.
+func WithGuard(guard *AddGuard) Option {
+	return func(t *dispatcher) {
+		t.Guard = guard
+	}
+}
+
+// WithAudit makes the plugin main functions write a record of every
+// command but VERSION they run to sink, as for the audit log in
+// pkg/audit, with the correlation ID the runtime passed in
+// audit.CorrelationIDEnv. Records which cannot be written are logged. The
+// result hash covers whatever the plugin printed to stdout, with
+// PrintResult or types.PrintResult; plugins run by PluginMainFuncsWithIO
+// must use PrintResult.
+func WithAudit(sink audit.Sink) Option {
+	return func(t *dispatcher) {
+		t.Audit = sink
+	}
+}
+
+// WithNotifyTimeout bounds how long sending a Notification may block the
+// plugin; DefaultNotifyTimeout if not given. Notifications which cannot be
+// sent in time are dropped.
+func WithNotifyTimeout(timeout time.Duration) Option {
+	return func(t *dispatcher) {
+		t.NotifyTimeout = timeout
+	}
+}
+
 // PluginMainFuncsWithError is the core "main" for a plugin. It accepts
 // callback functions defined within CNIFuncs and returns an error.
 //
@@ -385,13 +714,66 @@ type CNIFuncs struct {
 //
 // To let this package automatically handle errors and call os.Exit(1) for you,
 // use PluginMainFuncs() instead.
-func PluginMainFuncsWithError(funcs CNIFuncs, versionInfo version.PluginInfo, about string) *types.Error {
-	return (&dispatcher{
+func PluginMainFuncsWithError(funcs CNIFuncs, versionInfo version.PluginInfo, about string, opts ...Option) *types.Error {
+	t := &dispatcher{
 		Getenv: os.Getenv,
 		Stdin:  os.Stdin,
 		Stdout: os.Stdout,
 		Stderr: os.Stderr,
-	}).pluginMain(funcs, versionInfo, about)
+
+		RedirectStdout: true,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t.pluginMain(funcs, versionInfo, about)
+}
+
+// IO are the environment and standard streams of a plugin invocation
+type IO struct {
+	Getenv func(string) string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// PluginMainFuncsWithIO is PluginMainFuncsWithError for an invocation
+// whose environment and streams are not those of the process, such as
+// one forwarded to a long-running process by pkg/daemon. Several
+// invocations may run concurrently.
+//
+// The funcs must print their results with PrintResult, which writes to
+// the invocation's Stdout rather than to os.Stdout. Output written to
+// os.Stdout does not reach the runtime, notifications or the audit sink.
+func PluginMainFuncsWithIO(pio IO, funcs CNIFuncs, versionInfo version.PluginInfo, about string, opts ...Option) *types.Error {
+	t := &dispatcher{
+		Getenv: pio.Getenv,
+		Stdin:  pio.Stdin,
+		Stdout: pio.Stdout,
+		Stderr: pio.Stderr,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t.pluginMain(funcs, versionInfo, about)
+}
+
+// invocationStdouts maps the CmdArgs of running invocations to their stdout
+var invocationStdouts sync.Map
+
+// PrintResult prints the result, converted to the given version, to the
+// stdout of the invocation args were passed to: os.Stdout unless the
+// plugin is run by PluginMainFuncsWithIO.
+func PrintResult(args *CmdArgs, result types.Result, version string) error {
+	stdout, ok := invocationStdouts.Load(args)
+	if !ok {
+		return types.PrintResult(result, version)
+	}
+	newResult, err := result.GetAsVersion(version)
+	if err != nil {
+		return err
+	}
+	return newResult.PrintTo(stdout.(io.Writer))
 }
 
 // PluginMainFuncs is the core "main" for a plugin which includes automatic error handling.
@@ -407,8 +789,8 @@ func PluginMainFuncsWithError(funcs CNIFuncs, versionInfo version.PluginInfo, ab
 // as JSON to stdout and call os.Exit(1).
 //
 // To have more control over error handling, use PluginMainFuncsWithError() instead.
-func PluginMainFuncs(funcs CNIFuncs, versionInfo version.PluginInfo, about string) {
-	if e := PluginMainFuncsWithError(funcs, versionInfo, about); e != nil {
+func PluginMainFuncs(funcs CNIFuncs, versionInfo version.PluginInfo, about string, opts ...Option) {
+	if e := PluginMainFuncsWithError(funcs, versionInfo, about, opts...); e != nil {
 		if err := e.Print(); err != nil {
 			log.Print("Error writing error JSON to stdout: ", err)
 		}
@@ -16,14 +16,26 @@ package skel
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/containernetworking/cni/pkg/audit"
+	"github.com/containernetworking/cni/pkg/strictjson"
 	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+	"github.com/containernetworking/cni/pkg/utils"
 	"github.com/containernetworking/cni/pkg/version"
 )
 
@@ -141,6 +153,172 @@ var _ = Describe("dispatching to the correct callback", func() {
 			}))
 		})
 
+		It("accepts a containerID allowed by a custom name policy", func() {
+			environment["CNI_CONTAINERID"] = "{5C8B6B4E-1F2A-4C3D-9E8F-0A1B2C3D4E5F}"
+			dispatch.NamePolicy = &utils.RelaxedNamePolicy
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdAdd.CallCount).To(Equal(1))
+			Expect(cmdAdd.Received.CmdArgs.ContainerID).To(Equal("{5C8B6B4E-1F2A-4C3D-9E8F-0A1B2C3D4E5F}"))
+		})
+
+		It("passes the CDI devices of the attachment", func() {
+			environment["CNI_CDI_DEVICES"] = "intel.com/sriov=vf-3,nvidia.com/gpu=0"
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdAdd.Received.CmdArgs.CDIDevices).To(Equal([]string{"intel.com/sriov=vf-3", "nvidia.com/gpu=0"}))
+			devices, parseErr := cmdAdd.Received.CmdArgs.Devices()
+			Expect(parseErr).NotTo(HaveOccurred())
+			Expect(devices).To(Equal([]capabilities.CDIDevice{
+				{Vendor: "intel.com", Class: "sriov", Name: "vf-3"},
+				{Vendor: "nvidia.com", Class: "gpu", Name: "0"},
+			}))
+		})
+
+		It("returns an error for malformed CDI devices", func() {
+			environment["CNI_CDI_DEVICES"] = "intel.com/sriov"
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).To(Equal(&types.Error{
+				Code:    types.ErrInvalidEnvironmentVariables,
+				Msg:     "invalid CNI_CDI_DEVICES",
+				Details: `CDI device "intel.com/sriov" is not of the form vendor/class=name`,
+			}))
+			Expect(cmdAdd.CallCount).To(Equal(0))
+		})
+
+		It("rejects a duplicate key when decoding strictly", func() {
+			dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "name":"other", "cniVersion": "9.8.7" }`)
+			dispatch.StrictDecoding = &strictjson.DefaultLimits
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).To(Equal(&types.Error{
+				Code:    types.ErrDecodingFailure,
+				Msg:     "invalid network configuration",
+				Details: `duplicate key "name" at $`,
+			}))
+			Expect(cmdAdd.CallCount).To(Equal(0))
+		})
+
+		It("accepts a valid configuration when decoding strictly", func() {
+			dispatch.StrictDecoding = &strictjson.DefaultLimits
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdAdd.CallCount).To(Equal(1))
+		})
+
+		Context("when the config version is older than the plugin's newest", func() {
+			BeforeEach(func() {
+				dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "cniVersion": "0.4.0" }`)
+				versionInfo = version.PluginSupports("0.4.0", "1.0.0")
+			})
+
+			It("does not warn by default", func() {
+				Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+				Expect(stderr.String()).To(BeEmpty())
+			})
+
+			It("warns on stderr and in the log when enabled", func() {
+				logs := &bytes.Buffer{}
+				dispatch.Logger = slog.New(slog.NewTextHandler(logs, nil))
+				dispatch.WarnVersionSkew = true
+
+				Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+				Expect(stderr.String()).To(Equal("WARNING: CNI version 0.4.0 is older than 1.0.0, the newest version the plugin supports; consider upgrading the network configuration\n"))
+				Expect(logs.String()).To(ContainSubstring(`level=WARN msg="config version is older than the newest supported" version=0.4.0 latest=1.0.0`))
+				Expect(cmdAdd.CallCount).To(Equal(1))
+			})
+
+			It("does not warn about configs of the newest version", func() {
+				dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "cniVersion": "1.0.0" }`)
+				dispatch.WarnVersionSkew = true
+
+				Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+				Expect(stderr.String()).To(BeEmpty())
+			})
+		})
+
+		Context("when CleanupFailedAdd is set", func() {
+			BeforeEach(func() {
+				dispatch.CleanupFailedAdd = true
+			})
+
+			It("does not call cmdDel when cmdAdd succeeds", func() {
+				Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+				Expect(cmdDel.CallCount).To(Equal(0))
+			})
+
+			It("calls cmdDel with the same arguments when cmdAdd fails", func() {
+				cmdAdd.Returns.Error = types.NewError(types.ErrTryAgainLater, "busy", "")
+
+				err := dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).To(Equal(types.NewError(types.ErrTryAgainLater, "busy", "")))
+				Expect(cmdDel.CallCount).To(Equal(1))
+				Expect(cmdDel.Received.CmdArgs).To(BeIdenticalTo(cmdAdd.Received.CmdArgs))
+			})
+
+			It("appends the error of cmdDel to the details", func() {
+				addErr := types.NewError(types.ErrTryAgainLater, "busy", "retry")
+				cmdAdd.Returns.Error = addErr
+				cmdDel.Returns.Error = errors.New("no such link")
+
+				err := dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).To(Equal(types.NewError(types.ErrTryAgainLater, "busy", "retry; cleanup with DEL failed: no such link")))
+				Expect(addErr.Details).To(Equal("retry"))
+
+				cmdAdd.Returns.Error = errors.New("failed")
+				dispatch.Stdin = strings.NewReader(stdinData)
+				err = dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).To(Equal(types.NewError(types.ErrInternal, "failed", "cleanup with DEL failed: no such link")))
+			})
+
+			It("does not call cmdDel when the config is refused", func() {
+				versionInfo = version.PluginSupports("1.0.0")
+
+				Expect(dispatch.pluginMain(funcs, versionInfo, "")).NotTo(BeNil())
+				Expect(cmdDel.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when CNI_CONFIG_FILE is set", func() {
+			var configFile string
+
+			BeforeEach(func() {
+				configFile = filepath.Join(GinkgoT().TempDir(), "net.conf")
+				Expect(os.WriteFile(configFile, []byte(`{ "name":"from-file", "cniVersion": "9.8.7" }`), 0o600)).To(Succeed())
+				environment[ConfigFileEnv] = configFile
+			})
+
+			It("reads the configuration from the file when stdin is empty", func() {
+				dispatch.Stdin = strings.NewReader(" \n")
+
+				err := dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmdAdd.CallCount).To(Equal(1))
+				Expect(string(cmdAdd.Received.CmdArgs.StdinData)).To(Equal(`{ "name":"from-file", "cniVersion": "9.8.7" }`))
+			})
+
+			It("prefers stdin", func() {
+				err := dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cmdAdd.Received.CmdArgs).To(Equal(expectedCmdArgs))
+			})
+
+			It("fails if the file cannot be read", func() {
+				dispatch.Stdin = strings.NewReader("")
+				environment[ConfigFileEnv] = configFile + ".missing"
+
+				err := dispatch.pluginMain(funcs, versionInfo, "")
+				Expect(err).NotTo(BeNil())
+				Expect(err.Code).To(BeEquivalentTo(types.ErrIOFailure))
+				Expect(err.Msg).To(HavePrefix("error reading CNI_CONFIG_FILE: "))
+				Expect(cmdAdd.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("return errors when interface name is invalid", func() {
 			It("interface name is too long", func() {
 				environment["CNI_IFNAME"] = "1234567890123456"
@@ -268,6 +446,20 @@ var _ = Describe("dispatching to the correct callback", func() {
 					Expect(cmdAdd.CallCount).To(Equal(1))
 					Expect(cmdAdd.Received.CmdArgs).To(Equal(expectedCmdArgs))
 				})
+
+				It("does not warn about the deprecated version by default", func() {
+					err := dispatch.pluginMain(funcs, versionInfo, "")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stderr.String()).To(BeEmpty())
+				})
+
+				It("warns about the deprecated version when enabled", func() {
+					dispatch.WarnDeprecated = true
+					err := dispatch.pluginMain(funcs, versionInfo, "")
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stderr.String()).To(HavePrefix("WARNING: configuration has no cniVersion and is treated as 0.1.0; "))
+					Expect(cmdAdd.CallCount).To(Equal(1))
+				})
 			})
 
 			Context("when the plugin does not support 0.1.0", func() {
@@ -712,8 +904,679 @@ var _ = Describe("dispatching to the correct callback", func() {
 			})
 		})
 	})
+
+	Context("when an Observer is set", func() {
+		var observer *fakeObserver
+
+		BeforeEach(func() {
+			observer = &fakeObserver{}
+			dispatch.Observer = observer
+		})
+
+		It("observes a successful command", func() {
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+
+			Expect(observer.commands).To(Equal([]string{"ADD"}))
+			Expect(observer.errs).To(Equal([]error{nil}))
+		})
+
+		It("observes the error printed for a failed command", func() {
+			cmdAdd.Returns.Error = errors.New("potato")
+
+			err := dispatch.pluginMain(funcs, versionInfo, "")
+			Expect(err).To(HaveOccurred())
+
+			Expect(observer.commands).To(Equal([]string{"ADD"}))
+			Expect(observer.errs).To(Equal([]error{err}))
+		})
+
+		It("does not observe invocations without a valid command", func() {
+			delete(environment, "CNI_CONTAINERID")
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).NotTo(BeNil())
+			Expect(observer.commands).To(BeEmpty())
+		})
+	})
+	Context("when an Audit sink is set", func() {
+		var records []*audit.Record
+
+		BeforeEach(func() {
+			records = nil
+			dispatch.Audit = audit.SinkFunc(func(r *audit.Record) error {
+				records = append(records, r)
+				return nil
+			})
+			environment[audit.CorrelationIDEnv] = "some-correlation-id"
+		})
+
+		It("records a successful command", func() {
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+
+			Expect(records).To(HaveLen(1))
+			r := records[0]
+			Expect(r.Source).To(Equal(audit.SourcePlugin))
+			Expect(r.CorrelationID).To(Equal("some-correlation-id"))
+			Expect(r.Operation).To(Equal("ADD"))
+			Expect(r.Network).To(Equal("skel-test"))
+			Expect(r.CNIVersion).To(Equal("9.8.7"))
+			Expect(r.ContainerID).To(Equal("some-container-id"))
+			Expect(r.IfName).To(Equal("eth0"))
+			Expect(r.NetNS).To(Equal("/some/netns/path"))
+			Expect(r.ConfigHash).To(Equal(audit.Hash([]byte(stdinData))))
+			Expect(r.Outcome).To(Equal(audit.OutcomeSuccess))
+		})
+
+		It("hashes results printed to os.Stdout when redirecting stdout", func() {
+			result := `{"cniVersion": "1.0.0"}`
+			funcs.Add = func(*CmdArgs) error {
+				_, err := fmt.Fprint(os.Stdout, result)
+				return err
+			}
+			dispatch.RedirectStdout = true
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+			Expect(stdout.String()).To(Equal(result))
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].ResultHash).To(Equal(audit.Hash([]byte(result))))
+		})
+
+		It("records the error of a failed command", func() {
+			cmdAdd.Returns.Error = types.NewError(types.ErrTryAgainLater, "busy", "")
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).NotTo(BeNil())
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].Outcome).To(Equal(audit.OutcomeFailure))
+			Expect(records[0].ErrorCode).To(Equal(types.ErrTryAgainLater))
+			Expect(records[0].Error).To(Equal("busy"))
+		})
+
+		It("does not record VERSION", func() {
+			environment["CNI_COMMAND"] = "VERSION"
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+			Expect(records).To(BeEmpty())
+		})
+	})
+
+	Context("when a Logger is set", func() {
+		var logs *bytes.Buffer
+
+		BeforeEach(func() {
+			logs = &bytes.Buffer{}
+			dispatch.Logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		})
+
+		It("logs a successful command", func() {
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).To(BeNil())
+
+			Expect(logs.String()).To(ContainSubstring(`level=DEBUG msg="config version is supported" version=9.8.7`))
+			Expect(logs.String()).To(ContainSubstring(`level=DEBUG msg="command succeeded" command=ADD containerID=some-container-id ifName=eth0`))
+		})
+
+		It("logs incompatible versions", func() {
+			dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "cniVersion": "0.1.0" }`)
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).NotTo(BeNil())
+			Expect(logs.String()).To(ContainSubstring(`level=WARN msg="incompatible CNI versions" version=0.1.0 supported="[9.8.7 10.0.0]"`))
+			Expect(logs.String()).To(ContainSubstring(`level=WARN msg="command failed" command=ADD`))
+		})
+
+		It("logs invalid invocations", func() {
+			delete(environment, "CNI_CONTAINERID")
+
+			Expect(dispatch.pluginMain(funcs, versionInfo, "")).NotTo(BeNil())
+			Expect(logs.String()).To(ContainSubstring(`level=WARN msg="invalid invocation" command=ADD error="required env variables [CNI_CONTAINERID] missing"`))
+		})
+	})
+})
+
+var _ = Describe("commands of draft spec versions", func() {
+	var (
+		dispatch *dispatcher
+		cmd      *fakeCmd
+		cmdArgs  *CmdArgs
+	)
+
+	BeforeEach(func() {
+		dispatch = &dispatcher{
+			VersionReconciler: version.Reconciler{},
+		}
+		cmd = &fakeCmd{}
+		cmdArgs = &CmdArgs{StdinData: []byte(`{ "name":"skel-test", "cniVersion": "1.2.0" }`)}
+	})
+
+	It("refuses a draft feature whose gate is not set", func() {
+		err := dispatch.checkFeatureAndCall(version.FeatureExtensions, cmdArgs, version.PluginSupports("1.2.0"), cmd.Func)
+		Expect(err).To(Equal(&types.Error{
+			Code: types.ErrIncompatibleCNIVersion,
+			Msg:  "extensions is a draft feature which is not enabled",
+		}))
+		Expect(cmd.CallCount).To(Equal(0))
+	})
+
+	It("calls a draft feature whose gate is set", func() {
+		dispatch.FeatureGates = version.FeatureGates{version.FeatureExtensions: true}
+
+		err := dispatch.checkFeatureAndCall(version.FeatureExtensions, cmdArgs, version.PluginSupports("1.2.0"), cmd.Func)
+		Expect(err).To(BeNil())
+		Expect(cmd.CallCount).To(Equal(1))
+	})
+})
+
+var _ = Describe("ConfigArgs", func() {
+	var cmdArgs *CmdArgs
+
+	BeforeEach(func() {
+		cmdArgs = &CmdArgs{StdinData: []byte(`{
+			"name": "skel-test",
+			"cniVersion": "1.0.0",
+			"args": {
+				"cni": {
+					"labels": [{"key": "app", "value": "myapp"}],
+					"ips": ["10.2.2.42/24", "2001:db8::5"]
+				},
+				"example.com": {"priority": 3},
+				"flat": "value"
+			}
+		}`)}
+	})
+
+	It("decodes namespaced arguments", func() {
+		args, err := cmdArgs.ConfigArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.Namespaces()).To(Equal([]string{"cni", "example.com", "flat"}))
+
+		var priority int
+		found, err := args.Get("example.com/priority", &priority)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(priority).To(Equal(3))
+
+		var flat string
+		found, err = args.Get("flat", &flat)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(flat).To(Equal("value"))
+
+		found, err = args.Get("example.com/missing", &priority)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+		found, err = args.Get("missing/priority", &priority)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("decodes the conventional arguments", func() {
+		args, err := cmdArgs.ConfigArgs()
+		Expect(err).NotTo(HaveOccurred())
+
+		labels, err := args.Labels()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(Equal([]ArgsLabel{{Key: "app", Value: "myapp"}}))
+
+		ips, err := args.IPs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ips).To(Equal([]string{"10.2.2.42/24", "2001:db8::5"}))
+	})
+
+	It("reports arguments of the wrong shape", func() {
+		args, err := cmdArgs.ConfigArgs()
+		Expect(err).NotTo(HaveOccurred())
+
+		var priority string
+		_, err = args.Get("example.com/priority", &priority)
+		Expect(err).To(MatchError(HavePrefix(`failed to decode args "example.com/priority": `)))
+		_, err = args.Get("flat/name", &priority)
+		Expect(err).To(MatchError(HavePrefix(`args namespace "flat" is not an object: `)))
+	})
+
+	It("is empty without args", func() {
+		cmdArgs.StdinData = []byte(`{"name": "skel-test", "cniVersion": "1.0.0"}`)
+		args, err := cmdArgs.ConfigArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args.Namespaces()).To(BeEmpty())
+		labels, err := args.Labels()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(labels).To(BeEmpty())
+	})
+
+	It("fails if args is not an object", func() {
+		cmdArgs.StdinData = []byte(`{"name": "skel-test", "args": ["a"]}`)
+		_, err := cmdArgs.ConfigArgs()
+		Expect(err).To(MatchError(HavePrefix("failed to decode args: ")))
+	})
+})
+
+var _ = Describe("CheckPrevResult", func() {
+	var (
+		cmdArgs   *CmdArgs
+		verifiers CheckVerifiers
+		checked   []string
+	)
+
+	BeforeEach(func() {
+		cmdArgs = &CmdArgs{StdinData: []byte(`{
+			"name": "skel-test",
+			"cniVersion": "1.0.0",
+			"prevResult": {
+				"cniVersion": "1.0.0",
+				"interfaces": [{"name": "veth0"}, {"name": "eth0", "sandbox": "/some/netns/path"}],
+				"ips": [{"address": "10.0.0.2/24", "interface": 1}, {"address": "10.0.1.2/24"}],
+				"routes": [{"dst": "0.0.0.0/0", "gw": "10.0.0.1"}]
+			}
+		}`)}
+		checked = nil
+		verifiers = CheckVerifiers{
+			Interface: func(_ *CmdArgs, intf *current.Interface) error {
+				checked = append(checked, "interface "+intf.Name)
+				return nil
+			},
+			IP: func(_ *CmdArgs, ip *current.IPConfig, intf *current.Interface) error {
+				name := "none"
+				if intf != nil {
+					name = intf.Name
+				}
+				checked = append(checked, "ip "+ip.Address.String()+" on "+name)
+				return nil
+			},
+			Route: func(_ *CmdArgs, route *types.Route) error {
+				checked = append(checked, "route "+route.Dst.String())
+				return nil
+			},
+		}
+	})
+
+	It("verifies every part of the prevResult", func() {
+		Expect(CheckPrevResult(verifiers)(cmdArgs)).To(Succeed())
+		Expect(checked).To(Equal([]string{
+			"interface veth0",
+			"interface eth0",
+			"ip 10.0.0.2/24 on eth0",
+			"ip 10.0.1.2/24 on none",
+			"route 0.0.0.0/0",
+		}))
+	})
+
+	It("skips parts without a verifier", func() {
+		verifiers.IP = nil
+		verifiers.Route = nil
+		Expect(CheckPrevResult(verifiers)(cmdArgs)).To(Succeed())
+		Expect(checked).To(Equal([]string{"interface veth0", "interface eth0"}))
+	})
+
+	It("reports every mismatch", func() {
+		verifiers.Interface = func(_ *CmdArgs, intf *current.Interface) error {
+			if intf.Name == "eth0" {
+				return errors.New("link not found")
+			}
+			return nil
+		}
+		verifiers.Route = func(*CmdArgs, *types.Route) error {
+			return errors.New("route not found")
+		}
+
+		err := CheckPrevResult(verifiers)(cmdArgs)
+		Expect(err).To(Equal(types.NewError(types.ErrInternal, "live state does not match prevResult",
+			"interfaces[1] \"eth0\": link not found\nroutes[0] 0.0.0.0/0: route not found")))
+	})
+
+	It("fails without a prevResult", func() {
+		cmdArgs.StdinData = []byte(`{"name": "skel-test", "cniVersion": "1.0.0"}`)
+		Expect(CheckPrevResult(verifiers)(cmdArgs)).To(Equal(types.NewError(types.ErrInvalidNetworkConfig, "CHECK requires a prevResult", "")))
+		Expect(checked).To(BeEmpty())
+	})
+
+	It("fails with an invalid prevResult", func() {
+		cmdArgs.StdinData = []byte(`{"name": "skel-test", "cniVersion": "1.0.0", "prevResult": {"ips": "nope"}}`)
+		err := CheckPrevResult(verifiers)(cmdArgs)
+		Expect(err).To(HaveField("Code", BeEquivalentTo(types.ErrDecodingFailure)))
+		Expect(err).To(HaveField("Msg", "failed to decode prevResult"))
+	})
+})
+
+var _ = Describe("duplicate ADD detection", func() {
+	var (
+		environment map[string]string
+		dispatch    *dispatcher
+		funcs       CNIFuncs
+		cmdAdd      *fakeCmd
+		cmdDel      *fakeCmd
+		guard       *AddGuard
+	)
+
+	run := func(command string) *types.Error {
+		environment["CNI_COMMAND"] = command
+		dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "cniVersion": "1.0.0" }`)
+		return dispatch.pluginMain(funcs, version.All, "")
+	}
+
+	BeforeEach(func() {
+		environment = map[string]string{
+			"CNI_CONTAINERID": "some-container-id",
+			"CNI_NETNS":       "/some/netns/path",
+			"CNI_IFNAME":      "eth0",
+			"CNI_PATH":        "/some/cni/path",
+		}
+		guard = &AddGuard{Dir: GinkgoT().TempDir()}
+		dispatch = &dispatcher{
+			Getenv: func(key string) string { return environment[key] },
+			Stdout: &bytes.Buffer{},
+			Stderr: &bytes.Buffer{},
+			Guard:  guard,
+		}
+		cmdAdd = &fakeCmd{}
+		cmdDel = &fakeCmd{}
+		funcs = CNIFuncs{Add: cmdAdd.Func, Del: cmdDel.Func}
+	})
+
+	It("refuses an ADD of an attachment whose ADD is running", func() {
+		var nested, other *types.Error
+		funcs.Add = func(args *CmdArgs) error {
+			if args.IfName == "eth0" {
+				nested = run("ADD")
+				environment["CNI_IFNAME"] = "eth1"
+				other = run("ADD")
+				environment["CNI_IFNAME"] = "eth0"
+			}
+			return cmdAdd.Func(args)
+		}
+
+		Expect(run("ADD")).To(BeNil())
+		Expect(nested).To(Equal(&types.Error{
+			Code:    types.ErrTryAgainLater,
+			Msg:     "ADD of this attachment is already in progress",
+			Details: "container some-container-id, interface eth0",
+		}))
+		Expect(other).To(BeNil())
+		Expect(cmdAdd.CallCount).To(Equal(2))
+
+		funcs.Add = cmdAdd.Func
+		Expect(run("ADD")).To(BeNil())
+		Expect(cmdAdd.CallCount).To(Equal(3))
+	})
+
+	It("refuses repeated ADDs within the cooldown until DEL", func() {
+		guard.Cooldown = time.Hour
+		cmdAdd.Returns.Error = errors.New("failed")
+
+		Expect(run("ADD")).To(HaveField("Code", BeEquivalentTo(types.ErrInternal)))
+		err := run("ADD")
+		Expect(err).NotTo(BeNil())
+		Expect(err.Code).To(BeEquivalentTo(types.ErrTryAgainLater))
+		Expect(err.Msg).To(Equal("ADD of this attachment was repeated too soon"))
+		Expect(cmdAdd.CallCount).To(Equal(1))
+
+		Expect(run("DEL")).To(BeNil())
+		Expect(cmdDel.CallCount).To(Equal(1))
+		entries, readErr := os.ReadDir(guard.Dir)
+		Expect(readErr).NotTo(HaveOccurred())
+		Expect(entries).To(BeEmpty())
+
+		cmdAdd.Returns.Error = nil
+		Expect(run("ADD")).To(BeNil())
+		Expect(cmdAdd.CallCount).To(Equal(2))
+	})
+
+	It("allows ADDs once the cooldown has passed", func() {
+		guard.Cooldown = time.Millisecond
+		Expect(run("ADD")).To(BeNil())
+		time.Sleep(10 * time.Millisecond)
+		Expect(run("ADD")).To(BeNil())
+		Expect(cmdAdd.CallCount).To(Equal(2))
+	})
+
+	It("keeps the markers when DEL fails", func() {
+		guard.Cooldown = time.Hour
+		Expect(run("ADD")).To(BeNil())
+		cmdDel.Returns.Error = errors.New("failed")
+		Expect(run("DEL")).NotTo(BeNil())
+		Expect(run("ADD")).To(HaveField("Code", BeEquivalentTo(types.ErrTryAgainLater)))
+	})
+
+	It("does not guard configurations the plugin refuses", func() {
+		Expect(run("ADD")).To(BeNil())
+		dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "cniVersion": "0.0.1" }`)
+		environment["CNI_COMMAND"] = "ADD"
+		Expect(dispatch.pluginMain(funcs, version.PluginSupports("1.0.0"), "")).To(HaveField("Code", BeEquivalentTo(types.ErrIncompatibleCNIVersion)))
+	})
 })
 
+var _ = Describe("result notifications", func() {
+	var (
+		environment map[string]string
+		stdout      *bytes.Buffer
+		dispatch    *dispatcher
+		conn        *net.UnixConn
+		funcs       CNIFuncs
+	)
+
+	run := func(command string) *types.Error {
+		environment["CNI_COMMAND"] = command
+		dispatch.Stdin = strings.NewReader(`{ "name":"skel-test", "type": "fake", "cniVersion": "1.0.0" }`)
+		return dispatch.pluginMain(funcs, version.All, "")
+	}
+
+	receive := func() *Notification {
+		buf := make([]byte, 65536)
+		Expect(conn.SetReadDeadline(time.Now().Add(time.Second))).To(Succeed())
+		n, err := conn.Read(buf)
+		Expect(err).NotTo(HaveOccurred())
+		notification := &Notification{}
+		Expect(json.Unmarshal(buf[:n], notification)).To(Succeed())
+		return notification
+	}
+
+	BeforeEach(func() {
+		socketPath := filepath.Join(GinkgoT().TempDir(), "notify.sock")
+		var err error
+		conn, err = net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		environment = map[string]string{
+			"CNI_CONTAINERID": "some-container-id",
+			"CNI_NETNS":       "/some/netns/path",
+			"CNI_IFNAME":      "eth0",
+			"CNI_PATH":        "/some/cni/path",
+			NotifySocketEnv:   socketPath,
+		}
+		stdout = &bytes.Buffer{}
+		dispatch = &dispatcher{
+			Getenv: func(key string) string { return environment[key] },
+			Stdout: stdout,
+			Stderr: &bytes.Buffer{},
+		}
+		funcs = CNIFuncs{
+			Add: func(args *CmdArgs) error {
+				return PrintResult(args, &current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+			},
+			Del: func(*CmdArgs) error {
+				return types.NewError(types.ErrTryAgainLater, "busy", "")
+			},
+		}
+	})
+
+	It("sends the result of a command", func() {
+		Expect(run("ADD")).To(BeNil())
+		Expect(stdout.String()).To(ContainSubstring(`"cniVersion": "1.0.0"`))
+
+		notification := receive()
+		Expect(notification.Command).To(Equal("ADD"))
+		Expect(notification.ContainerID).To(Equal("some-container-id"))
+		Expect(notification.IfName).To(Equal("eth0"))
+		Expect(notification.Network).To(Equal("skel-test"))
+		Expect(notification.Plugin).To(Equal("fake"))
+		Expect(notification.Result).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+		Expect(notification.Error).To(BeNil())
+	})
+
+	It("sends the error of a command", func() {
+		Expect(run("DEL")).To(Equal(types.NewError(types.ErrTryAgainLater, "busy", "")))
+
+		notification := receive()
+		Expect(notification.Command).To(Equal("DEL"))
+		Expect(notification.Result).To(BeNil())
+		Expect(notification.Error).To(Equal(types.NewError(types.ErrTryAgainLater, "busy", "")))
+	})
+
+	It("does not send the outcome of commands without an attachment", func() {
+		Expect(run("VERSION")).To(BeNil())
+		Expect(conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))).To(Succeed())
+		_, err := conn.Read(make([]byte, 1024))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not fail the command when the socket is gone", func() {
+		environment[NotifySocketEnv] = filepath.Join(GinkgoT().TempDir(), "missing.sock")
+		Expect(run("ADD")).To(BeNil())
+	})
+
+	It("sends results printed to os.Stdout when redirecting stdout", func() {
+		funcs.Add = func(*CmdArgs) error {
+			return types.PrintResult(&current.Result{CNIVersion: "1.0.0"}, "1.0.0")
+		}
+		dispatch.RedirectStdout = true
+		origStdout := os.Stdout
+
+		Expect(run("ADD")).To(BeNil())
+		Expect(os.Stdout).To(BeIdenticalTo(origStdout))
+		Expect(stdout.String()).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+		Expect(receive().Result).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+	})
+})
+
+var _ = Describe("PluginMainFuncsWithIO", func() {
+	var (
+		environment map[string]string
+		pio         IO
+		stdout      *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		environment = map[string]string{
+			"CNI_COMMAND":     "ADD",
+			"CNI_CONTAINERID": "some-container-id",
+			"CNI_NETNS":       "/some/netns/path",
+			"CNI_IFNAME":      "eth0",
+			"CNI_PATH":        "/some/cni/path",
+		}
+		stdout = &bytes.Buffer{}
+		pio = IO{
+			Getenv: func(key string) string { return environment[key] },
+			Stdin:  strings.NewReader(`{ "name":"skel-test", "cniVersion": "1.0.0" }`),
+			Stdout: stdout,
+			Stderr: &bytes.Buffer{},
+		}
+	})
+
+	It("runs the invocation with the given environment and streams", func() {
+		funcs := CNIFuncs{
+			Add: func(args *CmdArgs) error {
+				Expect(args.ContainerID).To(Equal("some-container-id"))
+				return PrintResult(args, &current.Result{CNIVersion: "1.1.0"}, "1.0.0")
+			},
+		}
+		Expect(PluginMainFuncsWithIO(pio, funcs, version.All, "")).To(BeNil())
+		Expect(stdout.String()).To(MatchJSON(`{"cniVersion": "1.0.0"}`))
+	})
+
+	It("returns the errors of the invocation", func() {
+		funcs := CNIFuncs{
+			Add: func(_ *CmdArgs) error {
+				return types.NewError(types.ErrTryAgainLater, "busy", "")
+			},
+		}
+		Expect(PluginMainFuncsWithIO(pio, funcs, version.All, "")).To(Equal(types.NewError(types.ErrTryAgainLater, "busy", "")))
+		Expect(stdout.Len()).To(BeZero())
+	})
+
+	It("applies the options to the invocation only", func() {
+		called := 0
+		funcs := CNIFuncs{
+			Add: func(_ *CmdArgs) error {
+				called++
+				return nil
+			},
+		}
+		config := `{ "name":"skel-test", "name":"other", "cniVersion": "1.0.0" }`
+
+		pio.Stdin = strings.NewReader(config)
+		err := PluginMainFuncsWithIO(pio, funcs, version.All, "", WithStrictDecoding(&strictjson.DefaultLimits))
+		Expect(err).To(Equal(&types.Error{
+			Code:    types.ErrDecodingFailure,
+			Msg:     "invalid network configuration",
+			Details: `duplicate key "name" at $`,
+		}))
+		Expect(called).To(Equal(0))
+
+		pio.Stdin = strings.NewReader(config)
+		Expect(PluginMainFuncsWithIO(pio, funcs, version.All, "")).To(BeNil())
+		Expect(called).To(Equal(1))
+	})
+
+	It("runs the invocation with the observer and cleanup of the options", func() {
+		deleted := false
+		funcs := CNIFuncs{
+			Add: func(_ *CmdArgs) error { return errors.New("no addresses left") },
+			Del: func(_ *CmdArgs) error {
+				deleted = true
+				return nil
+			},
+		}
+		observer := &fakeObserver{}
+		err := PluginMainFuncsWithIO(pio, funcs, version.All, "", WithObserver(observer), WithCleanupFailedAdd())
+		Expect(err).To(HaveOccurred())
+		Expect(deleted).To(BeTrue())
+		Expect(observer.commands).To(Equal([]string{"ADD"}))
+	})
+
+	It("sets up the dispatcher with the options", func() {
+		logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+		observer := &fakeObserver{}
+		guard := &AddGuard{Dir: "/run/cni/guard"}
+		sink := &audit.FileSink{}
+		gates := version.FeatureGates{version.FeatureExtensions: true}
+
+		t := &dispatcher{}
+		for _, opt := range []Option{
+			WithDeprecationWarnings(),
+			WithVersionSkewWarnings(),
+			WithNamePolicy(&utils.RelaxedNamePolicy),
+			WithObserver(observer),
+			WithLogger(logger),
+			WithFeatureGates(gates),
+			WithCleanupFailedAdd(),
+			WithGuard(guard),
+			WithAudit(sink),
+			WithNotifyTimeout(time.Second),
+		} {
+			opt(t)
+		}
+		Expect(t).To(Equal(&dispatcher{
+			WarnDeprecated:   true,
+			WarnVersionSkew:  true,
+			NamePolicy:       &utils.RelaxedNamePolicy,
+			Observer:         observer,
+			Logger:           logger,
+			FeatureGates:     gates,
+			CleanupFailedAdd: true,
+			Guard:            guard,
+			Audit:            sink,
+			NotifyTimeout:    time.Second,
+		}))
+	})
+})
+
+type fakeObserver struct {
+	commands []string
+	errs     []error
+}
+
+func (o *fakeObserver) ObserveCommand(command string, _ time.Duration, err error) {
+	o.commands = append(o.commands, command)
+	o.errs = append(o.errs, err)
+}
+
 // BadReader is an io.Reader which always errors
 type BadReader struct {
 	Error     error
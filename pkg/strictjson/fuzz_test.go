@@ -0,0 +1,49 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strictjson_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/strictjson"
+)
+
+func FuzzCheck(f *testing.F) {
+	for _, seed := range []string{
+		`{"cniVersion": "1.0.0", "name": "net", "plugins": [{"type": "bridge", "mtu": 1500}]}`,
+		`{"a": 1, "a": 2}`,
+		`[[[[[]]]]]`,
+		`{"n": 1e400}`,
+		"\"\xff\"",
+		`{} []`,
+		``,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		limits := strictjson.Limits{MaxBytes: 4096, MaxDepth: 8}
+		if err := strictjson.Check(data, limits); err != nil {
+			return
+		}
+		if !json.Valid(data) {
+			t.Fatalf("accepted invalid JSON %q", data)
+		}
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			t.Fatalf("accepted %q which does not decode: %v", data, err)
+		}
+	})
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package strictjson checks JSON documents from untrusted sources before
+// they are decoded with encoding/json, which accepts documents that other
+// parsers read differently or that are costly to decode: duplicate keys
+// (the last one silently wins), invalid UTF-8 (silently replaced), numbers
+// which overflow and deeply nested values.
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits bound the documents Check accepts. Zero values mean no limit.
+type Limits struct {
+	// MaxBytes is the size of the document
+	MaxBytes int
+	// MaxDepth is the nesting of objects and arrays
+	MaxDepth int
+}
+
+// DefaultLimits are far above what network configurations and results
+// need, and low enough to bound the cost of decoding
+var DefaultLimits = Limits{
+	MaxBytes: 1 << 20,
+	MaxDepth: 32,
+}
+
+// frame is an object or array being checked
+type frame struct {
+	object bool
+	// keys are the keys seen in an object
+	keys map[string]bool
+	// expectKey is whether the next string of an object is a key
+	expectKey bool
+	// key is the current key of an object, index that of an array
+	key   string
+	index int
+}
+
+// Check returns an error if data is not a single valid JSON value within
+// limits, or if it has invalid UTF-8, an object with duplicate keys, or a
+// number that does not fit a float64, or an int64 for integers. Errors
+// locate the offending value with a path such as $.plugins[1].ipam.
+func Check(data []byte, limits Limits) error {
+	if limits.MaxBytes > 0 && len(data) > limits.MaxBytes {
+		return fmt.Errorf("document is %d bytes, more than the limit of %d", len(data), limits.MaxBytes)
+	}
+	if !utf8.Valid(data) {
+		return errors.New("document is not valid UTF-8")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var stack []*frame
+	values := 0
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON at %s: %w", path(stack), err)
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top == nil {
+			if values++; values > 1 {
+				return errors.New("document has more than one value")
+			}
+		}
+
+		if top != nil && top.object && top.expectKey {
+			key, ok := tok.(string)
+			if !ok {
+				// Only the closing delimiter can follow in place of a key
+				stack = stack[:len(stack)-1]
+				valueDone(stack)
+				continue
+			}
+			if top.keys[key] {
+				top.key = key
+				return fmt.Errorf("duplicate key %q at %s", key, path(stack[:len(stack)-1]))
+			}
+			top.keys[key] = true
+			top.key = key
+			top.expectKey = false
+			continue
+		}
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if limits.MaxDepth > 0 && len(stack) >= limits.MaxDepth {
+					return fmt.Errorf("value at %s is nested more than %d levels deep", path(stack), limits.MaxDepth)
+				}
+				stack = append(stack, &frame{object: t == '{', keys: map[string]bool{}, expectKey: t == '{'})
+				continue
+			default:
+				stack = stack[:len(stack)-1]
+			}
+		case json.Number:
+			if err := checkNumber(t); err != nil {
+				return fmt.Errorf("number at %s: %w", path(stack), err)
+			}
+		}
+		valueDone(stack)
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("invalid JSON at %s: %w", path(stack), io.ErrUnexpectedEOF)
+	}
+	if values == 0 {
+		return errors.New("document is empty")
+	}
+	return nil
+}
+
+// valueDone moves the innermost object or array past a complete value
+func valueDone(stack []*frame) {
+	if len(stack) == 0 {
+		return
+	}
+	top := stack[len(stack)-1]
+	if top.object {
+		top.expectKey = true
+	} else {
+		top.index++
+	}
+}
+
+func checkNumber(n json.Number) error {
+	s := string(n)
+	if strings.ContainsAny(s, ".eE") {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Errorf("%s is out of range", s)
+		}
+		return nil
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		return fmt.Errorf("%s is out of range", s)
+	}
+	return nil
+}
+
+// path describes the position of the value being read
+func path(stack []*frame) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, f := range stack {
+		if f.object {
+			if f.key != "" {
+				b.WriteString(".")
+				b.WriteString(f.key)
+			}
+		} else {
+			fmt.Fprintf(&b, "[%d]", f.index)
+		}
+	}
+	return b.String()
+}
+
+// Unmarshal checks data and then decodes it into v with encoding/json
+func Unmarshal(data []byte, v interface{}, limits Limits) error {
+	if err := Check(data, limits); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strictjson_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/strictjson"
+)
+
+var _ = Describe("Check", func() {
+	DescribeTable("accepts valid documents",
+		func(doc string) {
+			Expect(strictjson.Check([]byte(doc), strictjson.DefaultLimits)).To(Succeed())
+		},
+		Entry("an object", `{"cniVersion": "1.0.0", "name": "net", "plugins": [{"type": "bridge"}]}`),
+		Entry("an array", `[1, 2.5, -3e10, true, null, "x"]`),
+		Entry("a scalar", `"hello"`),
+		Entry("the same key in different objects", `{"a": {"a": 1}, "b": [{"a": 1}, {"a": 2}]}`),
+		Entry("empty containers", `{"a": {}, "b": []}`),
+		Entry("surrounding whitespace", " \n{}\n "),
+	)
+
+	DescribeTable("rejects invalid documents",
+		func(doc, message string) {
+			err := strictjson.Check([]byte(doc), strictjson.DefaultLimits)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("empty input", ``, "document is empty"),
+		Entry("whitespace only", "  \n", "document is empty"),
+		Entry("two values", `{} {}`, "more than one value"),
+		Entry("a syntax error", `{"a": }`, "invalid JSON at $.a"),
+		Entry("a truncated document", `{"a": [1, 2`, "invalid JSON at $.a[2]: unexpected EOF"),
+		Entry("invalid UTF-8", "{\"a\": \"\xff\"}", "not valid UTF-8"),
+		Entry("a duplicate key", `{"name": "a", "name": "b"}`, `duplicate key "name" at $`),
+		Entry("a nested duplicate key", `{"plugins": [{"type": "a"}, {"type": "b", "type": "c"}]}`, `duplicate key "type" at $.plugins[1]`),
+		Entry("an integer overflow", `{"mtu": 9223372036854775808}`, "number at $.mtu: 9223372036854775808 is out of range"),
+		Entry("a float overflow", `[0, 1e400]`, "number at $[1]: 1e400 is out of range"),
+	)
+
+	It("enforces the size limit", func() {
+		doc := []byte(`{"a": "` + strings.Repeat("x", 100) + `"}`)
+		Expect(strictjson.Check(doc, strictjson.Limits{MaxBytes: 50})).To(MatchError(ContainSubstring("more than the limit of 50")))
+		Expect(strictjson.Check(doc, strictjson.Limits{})).To(Succeed())
+	})
+
+	It("enforces the depth limit", func() {
+		doc := []byte(strings.Repeat("[", 4) + strings.Repeat("]", 4))
+		Expect(strictjson.Check(doc, strictjson.Limits{MaxDepth: 4})).To(Succeed())
+		Expect(strictjson.Check(doc, strictjson.Limits{MaxDepth: 3})).To(MatchError("value at $[0][0][0] is nested more than 3 levels deep"))
+
+		deep := []byte(strings.Repeat(`{"a":`, 100) + "1" + strings.Repeat("}", 100))
+		Expect(strictjson.Check(deep, strictjson.DefaultLimits)).To(MatchError(ContainSubstring("nested more than 32 levels deep")))
+	})
+})
+
+var _ = Describe("Unmarshal", func() {
+	It("decodes checked documents", func() {
+		var v struct{ Name string }
+		Expect(strictjson.Unmarshal([]byte(`{"name": "net"}`), &v, strictjson.DefaultLimits)).To(Succeed())
+		Expect(v.Name).To(Equal("net"))
+	})
+
+	It("does not decode rejected documents", func() {
+		var v struct{ Name string }
+		err := strictjson.Unmarshal([]byte(`{"name": "a", "name": "b"}`), &v, strictjson.DefaultLimits)
+		Expect(err).To(HaveOccurred())
+		Expect(v.Name).To(BeEmpty())
+	})
+})
@@ -0,0 +1,112 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"os"
+
+	"github.com/containernetworking/cni/pkg/skel"
+)
+
+// Env describes a plugin invocation: its CNI_* environment variables and
+// the network configuration given on stdin
+type Env struct {
+	Command     string
+	ContainerID string
+	NetNS       string
+	IfName      string
+	Args        string
+	Path        string
+	StdinData   []byte
+}
+
+// NewEnv returns the fixture of a plugin invocation of the command, with
+// placeholder values for the container, namespace, interface and plugin
+// path, to be overridden as the test requires
+func NewEnv(command string, stdinData []byte) *Env {
+	return &Env{
+		Command:     command,
+		ContainerID: "some-container-id",
+		NetNS:       "/some/netns/path",
+		IfName:      "eth0",
+		Path:        "/some/bin/path",
+		StdinData:   stdinData,
+	}
+}
+
+// vars returns the environment variables of the invocation, in order
+func (e *Env) vars() [][2]string {
+	return [][2]string{
+		{"CNI_COMMAND", e.Command},
+		{"CNI_CONTAINERID", e.ContainerID},
+		{"CNI_NETNS", e.NetNS},
+		{"CNI_IFNAME", e.IfName},
+		{"CNI_ARGS", e.Args},
+		{"CNI_PATH", e.Path},
+	}
+}
+
+// Environ returns the CNI_* variables of the invocation as "KEY=value"
+// strings, such as for exec.Cmd.Env. Empty variables are included, so
+// that they override any inherited from the test's environment.
+func (e *Env) Environ() []string {
+	env := []string{}
+	for _, kv := range e.vars() {
+		env = append(env, kv[0]+"="+kv[1])
+	}
+	return env
+}
+
+// Setenv sets the CNI_* variables of the invocation in the test process,
+// as skel.PluginMain reads them, and returns a function restoring their
+// previous values
+func (e *Env) Setenv() (restore func(), err error) {
+	type saved struct {
+		value string
+		set   bool
+	}
+	previous := map[string]saved{}
+	restore = func() {
+		for key, s := range previous {
+			if s.set {
+				os.Setenv(key, s.value)
+			} else {
+				os.Unsetenv(key)
+			}
+		}
+	}
+	for _, kv := range e.vars() {
+		value, set := os.LookupEnv(kv[0])
+		previous[kv[0]] = saved{value: value, set: set}
+		if err := os.Setenv(kv[0], kv[1]); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	return restore, nil
+}
+
+// CmdArgs returns the arguments skel passes to a plugin's functions for
+// the invocation, to call them directly
+func (e *Env) CmdArgs() *skel.CmdArgs {
+	return &skel.CmdArgs{
+		ContainerID: e.ContainerID,
+		Netns:       e.NetNS,
+		IfName:      e.IfName,
+		Args:        e.Args,
+		Path:        e.Path,
+		StdinData:   e.StdinData,
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// CheckErrorCode returns an error unless err is, or wraps, a *types.Error
+// with the given code, such as types.ErrInvalidNetworkConfig
+func CheckErrorCode(err error, code uint) error {
+	if err == nil {
+		return fmt.Errorf("expected an error with code %d, got none", code)
+	}
+	actual, ok := types.CodeOf(err)
+	if !ok {
+		return fmt.Errorf("expected an error with code %d, got %T without a code: %v", code, err, err)
+	}
+	if actual != code {
+		return fmt.Errorf("expected an error with code %d, got code %d: %v", code, actual, err)
+	}
+	return nil
+}
+
+// AssertErrorCode fails the test if CheckErrorCode does
+func AssertErrorCode(t TB, err error, code uint) {
+	t.Helper()
+	if cerr := CheckErrorCode(err, code); cerr != nil {
+		t.Fatalf("%v", cerr)
+	}
+}
+
+// ParseError decodes a CNI error printed by a plugin on stdout, and
+// returns an error if the output is not one
+func ParseError(stdout []byte) (*types.Error, error) {
+	e := &types.Error{}
+	if err := json.Unmarshal(stdout, e); err != nil {
+		return nil, fmt.Errorf("decoding plugin error %q: %w", stdout, err)
+	}
+	if e.Code == 0 {
+		return nil, fmt.Errorf("plugin output %q is not an error", stdout)
+	}
+	return e, nil
+}
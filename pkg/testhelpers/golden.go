@@ -0,0 +1,110 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set to a
+// non-empty value, makes CompareGolden write golden files instead of
+// comparing against them
+const UpdateGoldenEnv = "CNI_UPDATE_GOLDEN"
+
+// GoldenJSON returns the form of v stored in golden files: its canonical
+// JSON encoding, as types.CanonicalJSON returns it, indented by two spaces
+// and ending with a newline. v may also be JSON bytes, such as a plugin's
+// stdout, or a types.Result.
+func GoldenJSON(v interface{}) ([]byte, error) {
+	canonical, err := types.CanonicalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, canonical, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// CompareGolden compares the canonical JSON encoding of actual, such as a
+// result or a network configuration, with the golden file at path. Key
+// order, whitespace and number formatting do not matter. If
+// UpdateGoldenEnv is set, the file and its directory are written instead.
+func CompareGolden(path string, actual interface{}) error {
+	got, err := GoldenJSON(actual)
+	if err != nil {
+		return fmt.Errorf("encoding value for golden file %s: %w", path, err)
+	}
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, got, 0o644)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("golden file %s does not exist; set %s=1 to create it", path, UpdateGoldenEnv)
+	} else if err != nil {
+		return err
+	}
+	want, err := GoldenJSON(json.RawMessage(data))
+	if err != nil {
+		return fmt.Errorf("golden file %s: %w", path, err)
+	}
+	if bytes.Equal(got, want) {
+		return nil
+	}
+	line, wantLine, gotLine := firstDifference(want, got)
+	return fmt.Errorf("value does not match golden file %s at line %d:\n  golden: %s\n  actual: %s\nset %s=1 to update it",
+		path, line, wantLine, gotLine, UpdateGoldenEnv)
+}
+
+// AssertGolden fails the test if CompareGolden does
+func AssertGolden(t TB, path string, actual interface{}) {
+	t.Helper()
+	if err := CompareGolden(path, actual); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+// firstDifference returns the first line, counting from one, at which a
+// and b differ, with its content in each. A missing line is shown as
+// "<EOF>".
+func firstDifference(a, b []byte) (int, string, string) {
+	la := strings.Split(string(a), "\n")
+	lb := strings.Split(string(b), "\n")
+	line := func(lines []string, i int) string {
+		if i < len(lines) {
+			return strings.TrimSpace(lines[i])
+		}
+		return "<EOF>"
+	}
+	for i := 0; ; i++ {
+		if i >= len(la) || i >= len(lb) || la[i] != lb[i] {
+			return i + 1, line(la, i), line(lb, i)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testhelpers reduces the boilerplate of testing CNI plugins and
+// runtimes: golden files of results and configurations, fixtures of the
+// CNI_* environment of plugin invocations, and assertions on the codes of
+// *types.Error.
+//
+// The helpers returning errors work with any test framework. Those taking
+// a TB fail the test instead, and accept both a *testing.T and GinkgoT():
+//
+//	result, err := invokeAdd(env, conf)
+//	testhelpers.AssertGolden(t, "testdata/add.golden.json", result)
+//
+// Golden files are rewritten from the actual values, instead of compared,
+// when the CNI_UPDATE_GOLDEN environment variable is set.
+package testhelpers
+
+// TB is the part of testing.TB the helpers need, which GinkgoT() also
+// implements
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
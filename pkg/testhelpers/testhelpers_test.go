@@ -0,0 +1,170 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testhelpers_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/testhelpers"
+	"github.com/containernetworking/cni/pkg/types"
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// fakeTB records the failure of a helper
+type fakeTB struct {
+	failure string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Fatalf(format string, args ...interface{}) {
+	t.failure = fmt.Sprintf(format, args...)
+}
+
+var _ = Describe("Golden files", func() {
+	var (
+		dir    string
+		result *types100.Result
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		result = &types100.Result{
+			CNIVersion: "1.0.0",
+			Interfaces: []*types100.Interface{{Name: "eth0", Mac: "00:11:22:33:44:55"}},
+		}
+		GinkgoT().Setenv(testhelpers.UpdateGoldenEnv, "")
+	})
+
+	It("matches equivalent JSON regardless of formatting", func() {
+		path := filepath.Join(dir, "result.json")
+		Expect(os.WriteFile(path, []byte(`{"interfaces":[{"mac":"00:11:22:33:44:55","name":"eth0"}],"cniVersion":"1.0.0"}`), 0o644)).To(Succeed())
+
+		Expect(testhelpers.CompareGolden(path, result)).To(Succeed())
+		Expect(testhelpers.CompareGolden(path, []byte(`{"cniVersion": "1.0.0", "interfaces": [{"name": "eth0", "mac": "00:11:22:33:44:55"}]}`))).To(Succeed())
+		testhelpers.AssertGolden(GinkgoT(), path, result)
+	})
+
+	It("reports the first differing line", func() {
+		path := filepath.Join(dir, "result.json")
+		Expect(os.WriteFile(path, []byte(`{"cniVersion": "1.0.0", "interfaces": [{"name": "eth1", "mac": "00:11:22:33:44:55"}]}`), 0o644)).To(Succeed())
+
+		err := testhelpers.CompareGolden(path, result)
+		Expect(err).To(MatchError(fmt.Sprintf("value does not match golden file %s at line 6:\n"+
+			"  golden: \"name\": \"eth1\"\n"+
+			"  actual: \"name\": \"eth0\"\n"+
+			"set CNI_UPDATE_GOLDEN=1 to update it", path)))
+
+		t := &fakeTB{}
+		testhelpers.AssertGolden(t, path, result)
+		Expect(t.failure).To(Equal(err.Error()))
+	})
+
+	It("fails for missing golden files", func() {
+		path := filepath.Join(dir, "missing.json")
+		Expect(testhelpers.CompareGolden(path, result)).To(MatchError(ContainSubstring("golden file " + path + " does not exist")))
+	})
+
+	It("writes golden files when updating", func() {
+		GinkgoT().Setenv(testhelpers.UpdateGoldenEnv, "1")
+		path := filepath.Join(dir, "testdata", "result.json")
+		Expect(testhelpers.CompareGolden(path, result)).To(Succeed())
+
+		data, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`{
+  "cniVersion": "1.0.0",
+  "interfaces": [
+    {
+      "mac": "00:11:22:33:44:55",
+      "name": "eth0"
+    }
+  ]
+}
+`))
+
+		GinkgoT().Setenv(testhelpers.UpdateGoldenEnv, "")
+		Expect(testhelpers.CompareGolden(path, result)).To(Succeed())
+	})
+})
+
+var _ = Describe("Env", func() {
+	It("builds the environment and arguments of an invocation", func() {
+		env := testhelpers.NewEnv("ADD", []byte(`{"name": "net"}`))
+		env.Args = "IgnoreUnknown=true"
+
+		Expect(env.Environ()).To(Equal([]string{
+			"CNI_COMMAND=ADD",
+			"CNI_CONTAINERID=some-container-id",
+			"CNI_NETNS=/some/netns/path",
+			"CNI_IFNAME=eth0",
+			"CNI_ARGS=IgnoreUnknown=true",
+			"CNI_PATH=/some/bin/path",
+		}))
+		args := env.CmdArgs()
+		Expect(args.ContainerID).To(Equal("some-container-id"))
+		Expect(args.Netns).To(Equal("/some/netns/path"))
+		Expect(args.Args).To(Equal("IgnoreUnknown=true"))
+		Expect(args.StdinData).To(MatchJSON(`{"name": "net"}`))
+	})
+
+	It("sets and restores the environment of the process", func() {
+		GinkgoT().Setenv("CNI_IFNAME", "net1")
+		Expect(os.Unsetenv("CNI_ARGS")).To(Succeed())
+
+		restore, err := testhelpers.NewEnv("DEL", nil).Setenv()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Getenv("CNI_COMMAND")).To(Equal("DEL"))
+		Expect(os.Getenv("CNI_IFNAME")).To(Equal("eth0"))
+
+		restore()
+		Expect(os.Getenv("CNI_IFNAME")).To(Equal("net1"))
+		_, set := os.LookupEnv("CNI_ARGS")
+		Expect(set).To(BeFalse())
+	})
+})
+
+var _ = Describe("Error codes", func() {
+	It("accepts errors with the expected code, even wrapped", func() {
+		err := fmt.Errorf("adding: %w", types.NewError(types.ErrInvalidNetworkConfig, "bad", ""))
+		Expect(testhelpers.CheckErrorCode(err, types.ErrInvalidNetworkConfig)).To(Succeed())
+		testhelpers.AssertErrorCode(GinkgoT(), err, types.ErrInvalidNetworkConfig)
+	})
+
+	It("rejects other errors", func() {
+		Expect(testhelpers.CheckErrorCode(nil, types.ErrTryAgainLater)).To(MatchError("expected an error with code 11, got none"))
+		Expect(testhelpers.CheckErrorCode(errors.New("boom"), types.ErrTryAgainLater)).To(MatchError("expected an error with code 11, got *errors.errorString without a code: boom"))
+
+		t := &fakeTB{}
+		testhelpers.AssertErrorCode(t, types.NewError(types.ErrIOFailure, "io", ""), types.ErrTryAgainLater)
+		Expect(t.failure).To(Equal("expected an error with code 11, got code 5: io"))
+	})
+
+	It("parses errors printed by plugins", func() {
+		e, err := testhelpers.ParseError([]byte(`{"cniVersion": "1.0.0", "code": 7, "msg": "bad config"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(e.Code).To(Equal(types.ErrInvalidNetworkConfig))
+		Expect(e.Msg).To(Equal("bad config"))
+
+		_, err = testhelpers.ParseError([]byte(`{"cniVersion": "1.0.0"}`))
+		Expect(err).To(MatchError(ContainSubstring("is not an error")))
+	})
+})
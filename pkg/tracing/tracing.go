@@ -0,0 +1,113 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing defines the hooks libcni and invoke use to trace network
+// operations and plugin executions.
+//
+// The interfaces follow the shape of the OpenTelemetry trace API, so an
+// OpenTelemetry tracer is adapted with a few lines and no dependency is
+// forced on users of this library:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, tracing.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name, trace.WithAttributes(toKeyValues(attrs)...))
+//		return ctx, otelSpan{span}
+//	}
+package tracing
+
+import (
+	"context"
+)
+
+// Attribute names set on spans
+const (
+	AttrNetworkName = "cni.network.name"
+	AttrCNIVersion  = "cni.version"
+	AttrContainerID = "cni.container.id"
+	AttrIfName      = "cni.ifname"
+	AttrCommand     = "cni.command"
+	AttrPluginType  = "cni.plugin.type"
+	AttrPluginPath  = "cni.plugin.path"
+	AttrExitCode    = "cni.plugin.exit_code"
+	AttrErrorCode   = "cni.error.code"
+)
+
+// Attribute is a key/value pair describing a span. Value is a string, an
+// int or a bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String returns a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an int attribute
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx, and returns it
+	// with a context carrying it
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is one traced operation. Its duration is the time between its start
+// and End.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed with err
+	RecordError(err error)
+	End()
+}
+
+type spanKey struct{}
+
+// Start starts a span with tracer, which may be nil to not trace. The
+// returned context also carries the span for SpanFromContext.
+func Start(ctx context.Context, tracer Tracer, name string, attrs ...Attribute) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := tracer.Start(ctx, name, attrs...)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the span started by Start that ctx carries, or
+// a span that does nothing if there is none
+func SpanFromContext(ctx context.Context) Span {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span
+	}
+	return noopSpan{}
+}
+
+// End records err, if any, on span and ends it
+func End(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
@@ -169,17 +169,14 @@ func convertTo02x(from types.Result, toVersion string) (types.Result, error) {
 	}
 
 	for _, fromRoute := range fromResult.Routes {
+		// 0.2.0 routes share the route type, so keep all attributes
 		is4 := fromRoute.Dst.IP.To4() != nil
+		route := *fromRoute.Copy()
+		route.Interface = nil
 		if is4 && toResult.IP4 != nil {
-			toResult.IP4.Routes = append(toResult.IP4.Routes, types.Route{
-				Dst: fromRoute.Dst,
-				GW:  fromRoute.GW,
-			})
+			toResult.IP4.Routes = append(toResult.IP4.Routes, route)
 		} else if !is4 && toResult.IP6 != nil {
-			toResult.IP6.Routes = append(toResult.IP6.Routes, types.Route{
-				Dst: fromRoute.Dst,
-				GW:  fromRoute.GW,
-			})
+			toResult.IP6.Routes = append(toResult.IP6.Routes, route)
 		}
 	}
 
@@ -272,6 +272,20 @@ var _ = Describe("040 types operations", func() {
 		Expect(oldJson).To(MatchJSON(origJson))
 	})
 
+	It("keeps route attributes when converting to 0.2.0", func() {
+		res := testResult()
+		res.Routes[0].MTU = 1400
+		res.Routes[0].Priority = 10
+
+		oldRes, err := res.GetAsVersion("0.2.0")
+		Expect(err).NotTo(HaveOccurred())
+		result020, ok := oldRes.(*types020.Result)
+		Expect(ok).To(BeTrue())
+		Expect(result020.IP4.Routes).To(HaveLen(1))
+		Expect(result020.IP4.Routes[0].MTU).To(Equal(1400))
+		Expect(result020.IP4.Routes[0].Priority).To(Equal(10))
+	})
+
 	It("correctly round-trips a 0.2.0 Result without route gateways", func() {
 		ipv4, err := types.ParseCIDR("1.2.3.30/24")
 		Expect(err).NotTo(HaveOccurred())
@@ -0,0 +1,95 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types100_test
+
+import (
+	"io"
+	"testing"
+
+	current "github.com/containernetworking/cni/pkg/types/100"
+)
+
+const benchResult = `{
+	"cniVersion": "1.0.0",
+	"interfaces": [
+		{"name": "cni0", "mac": "00:11:22:33:44:55"},
+		{"name": "veth1234", "mac": "00:11:22:33:44:66"},
+		{"name": "eth0", "mac": "00:11:22:33:44:77", "sandbox": "/var/run/netns/bench"}
+	],
+	"ips": [
+		{"interface": 2, "address": "10.22.0.5/16", "gateway": "10.22.0.1"},
+		{"interface": 2, "address": "fd00::5/64", "gateway": "fd00::1"}
+	],
+	"routes": [{"dst": "0.0.0.0/0", "gw": "10.22.0.1"}, {"dst": "::/0", "gw": "fd00::1"}],
+	"dns": {"nameservers": ["10.22.0.1"], "search": ["cluster.local"]}
+}`
+
+func benchmarkResult(b *testing.B) *current.Result {
+	result, err := current.NewResult([]byte(benchResult))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return result.(*current.Result)
+}
+
+func BenchmarkNewResult(b *testing.B) {
+	data := []byte(benchResult)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := current.NewResult(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetAsVersion(b *testing.B) {
+	for _, v := range []string{"1.1.0", "0.4.0", "0.2.0"} {
+		b.Run(v, func(b *testing.B) {
+			result := benchmarkResult(b)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := result.GetAsVersion(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewResultFromResult(b *testing.B) {
+	prev, err := benchmarkResult(b).GetAsVersion("0.4.0")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := current.NewResultFromResult(prev); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrintTo(b *testing.B) {
+	result := benchmarkResult(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := result.PrintTo(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -16,20 +16,29 @@ package types100
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"regexp"
+	"sort"
 
 	"github.com/containernetworking/cni/pkg/types"
 	types040 "github.com/containernetworking/cni/pkg/types/040"
 	convert "github.com/containernetworking/cni/pkg/types/internal"
 )
 
-// The types did not change between v1.0 and v1.1
+// The types did not change between v1.0 and v1.1. The draft v1.2 adds only
+// the optional "extensions" map, route interfaces, interface annotations
+// and interface device info, so it shares this type as well.
 const ImplementedSpecVersion string = "1.1.0"
 
-var supportedVersions = []string{"1.0.0", "1.1.0"}
+var supportedVersions = []string{"1.0.0", "1.1.0", "1.2.0"}
+
+// Result versions which serialize the "extensions" map, route interfaces,
+// interface annotations and interface device info
+var versions120 = []string{"1.2.0"}
 
 // Register converters for all versions less than the implemented spec version
 func init() {
@@ -39,7 +48,8 @@ func init() {
 	convert.RegisterConverter("0.3.0", supportedVersions, convertFrom04x)
 	convert.RegisterConverter("0.3.1", supportedVersions, convertFrom04x)
 	convert.RegisterConverter("0.4.0", supportedVersions, convertFrom04x)
-	convert.RegisterConverter("1.0.0", []string{"1.1.0"}, convertFrom100)
+	convert.RegisterConverter("1.0.0", []string{"1.1.0", "1.2.0"}, convertFrom100)
+	convert.RegisterConverter("1.1.0", []string{"1.2.0"}, convertFrom100)
 
 	// Down-converters
 	convert.RegisterConverter("1.0.0", []string{"0.3.0", "0.3.1", "0.4.0"}, convertTo04x)
@@ -47,6 +57,9 @@ func init() {
 	convert.RegisterConverter("1.1.0", []string{"0.3.0", "0.3.1", "0.4.0"}, convertTo04x)
 	convert.RegisterConverter("1.1.0", []string{"0.1.0", "0.2.0"}, convertTo02x)
 	convert.RegisterConverter("1.1.0", []string{"1.0.0"}, convertFrom100)
+	convert.RegisterConverter("1.2.0", []string{"0.3.0", "0.3.1", "0.4.0"}, convertTo04x)
+	convert.RegisterConverter("1.2.0", []string{"0.1.0", "0.2.0"}, convertTo02x)
+	convert.RegisterConverter("1.2.0", []string{"1.0.0", "1.1.0"}, convertFrom100)
 
 	// Creator
 	convert.RegisterCreator(supportedVersions, NewResult)
@@ -93,6 +106,58 @@ type Result struct {
 	IPs        []*IPConfig    `json:"ips,omitempty"`
 	Routes     []*types.Route `json:"routes,omitempty"`
 	DNS        types.DNS      `json:"dns,omitempty"`
+
+	// Extensions holds vendor-specific data keyed by reverse-DNS names
+	// (e.g. "io.example.vendor"). It is carried through conversions
+	// between 1.x versions but only serialized for versions that define it.
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+}
+
+// extensionKeyRegexp matches reverse-DNS names with at least two labels
+var extensionKeyRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)+$`)
+
+// SetExtension stores the JSON encoding of value under the given
+// reverse-DNS key. Extensions are create-once: setting a key which
+// is already present is an error, so that chained plugins cannot
+// clobber data owned by another vendor.
+func (r *Result) SetExtension(key string, value interface{}) error {
+	if !extensionKeyRegexp.MatchString(key) {
+		return fmt.Errorf("invalid extension key %q: must be a reverse-DNS name", key)
+	}
+	if _, ok := r.Extensions[key]; ok {
+		return fmt.Errorf("extension %q is already set", key)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal extension %q: %w", key, err)
+	}
+	if r.Extensions == nil {
+		r.Extensions = make(map[string]json.RawMessage)
+	}
+	r.Extensions[key] = data
+	return nil
+}
+
+// GetExtension decodes the extension stored under key into value and
+// reports whether the extension was present
+func (r *Result) GetExtension(key string, value interface{}) (bool, error) {
+	data, ok := r.Extensions[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return true, fmt.Errorf("failed to unmarshal extension %q: %w", key, err)
+	}
+	return true, nil
+}
+
+func supports120(version string) bool {
+	for _, v := range versions120 {
+		if v == version {
+			return true
+		}
+	}
+	return false
 }
 
 // Note: DNS should be omit if DNS is empty but default Marshal function
@@ -115,10 +180,33 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 		delete(fixupObj, "dns")
 	}
 
+	if !supports120(r.CNIVersion) {
+		delete(fixupObj, "extensions")
+		if routes, ok := fixupObj["routes"].([]interface{}); ok {
+			for _, route := range routes {
+				if route, ok := route.(map[string]interface{}); ok {
+					delete(route, "interface")
+				}
+			}
+		}
+		if interfaces, ok := fixupObj["interfaces"].([]interface{}); ok {
+			for _, intf := range interfaces {
+				if intf, ok := intf.(map[string]interface{}); ok {
+					delete(intf, "annotations")
+					delete(intf, "pfIndex")
+					delete(intf, "vfIndex")
+					delete(intf, "rdmaDevice")
+				}
+			}
+		}
+	}
+
 	return json.Marshal(fixupObj)
 }
 
-// convertFrom100 does nothing except set the version; the types are the same
+// convertFrom100 does nothing except set the version; the types are the
+// same. Extensions are passed through so that they survive a round trip
+// through an older 1.x version.
 func convertFrom100(from types.Result, toVersion string) (types.Result, error) {
 	fromResult := from.(*Result)
 
@@ -129,6 +217,12 @@ func convertFrom100(from types.Result, toVersion string) (types.Result, error) {
 		Routes:     fromResult.Routes,
 		DNS:        fromResult.DNS,
 	}
+	if fromResult.Extensions != nil {
+		result.Extensions = make(map[string]json.RawMessage, len(fromResult.Extensions))
+		for k, v := range fromResult.Extensions {
+			result.Extensions[k] = v
+		}
+	}
 	return result, nil
 }
 
@@ -222,7 +316,10 @@ func convertTo04x(from types.Result, toVersion string) (types.Result, error) {
 		toResult.IPs = append(toResult.IPs, convertIPConfigTo040(fromIPC))
 	}
 	for _, fromRoute := range fromResult.Routes {
-		toResult.Routes = append(toResult.Routes, fromRoute.Copy())
+		// 0.x results cannot associate routes with interfaces
+		route := fromRoute.Copy()
+		route.Interface = nil
+		toResult.Routes = append(toResult.Routes, route)
 	}
 	return toResult, nil
 }
@@ -266,6 +363,106 @@ func (r *Result) PrintTo(writer io.Writer) error {
 	return err
 }
 
+// Validate checks the result for cross-field consistency: every IP
+// configuration must reference an existing interface, on the host or in
+// the sandbox, routes must reference existing interfaces, gateways and
+// route next hops must match the family of their address, and no address
+// may be assigned twice. All problems found are returned together.
+func (r *Result) Validate() error {
+	var errs []error
+
+	for i, intf := range r.Interfaces {
+		if intf == nil {
+			errs = append(errs, fmt.Errorf("interface %d is null", i))
+			continue
+		}
+		if intf.Name == "" {
+			errs = append(errs, fmt.Errorf("interface %d has no name", i))
+		}
+		if intf.Mtu < 0 {
+			errs = append(errs, fmt.Errorf("interface %d (%s) has negative MTU %d", i, intf.Name, intf.Mtu))
+		}
+		if intf.PFIndex != nil && *intf.PFIndex < 0 {
+			errs = append(errs, fmt.Errorf("interface %d (%s) has negative PF index %d", i, intf.Name, *intf.PFIndex))
+		}
+		if intf.VFIndex != nil && *intf.VFIndex < 0 {
+			errs = append(errs, fmt.Errorf("interface %d (%s) has negative VF index %d", i, intf.Name, *intf.VFIndex))
+		}
+		if len(intf.Annotations) > MaxInterfaceAnnotations {
+			errs = append(errs, fmt.Errorf("interface %d (%s) has %d annotations, more than %d", i, intf.Name, len(intf.Annotations), MaxInterfaceAnnotations))
+		}
+		for _, key := range sortedAnnotationKeys(intf.Annotations) {
+			switch {
+			case key == "" || len(key) > MaxAnnotationKeyLength:
+				errs = append(errs, fmt.Errorf("interface %d (%s) has annotation key %q which is empty or longer than %d bytes", i, intf.Name, key, MaxAnnotationKeyLength))
+			case len(intf.Annotations[key]) > MaxAnnotationValueLength:
+				errs = append(errs, fmt.Errorf("interface %d (%s) has annotation %q longer than %d bytes", i, intf.Name, key, MaxAnnotationValueLength))
+			}
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, ipc := range r.IPs {
+		if ipc == nil {
+			errs = append(errs, fmt.Errorf("ip %d is null", i))
+			continue
+		}
+		if ipc.Address.IP == nil {
+			errs = append(errs, fmt.Errorf("ip %d has no address", i))
+			continue
+		}
+		if ipc.Interface != nil {
+			idx := *ipc.Interface
+			if idx < 0 || idx >= len(r.Interfaces) {
+				errs = append(errs, fmt.Errorf("ip %d (%s) references interface %d but result has %d interfaces", i, ipc.Address.String(), idx, len(r.Interfaces)))
+			}
+		}
+		if ipc.Gateway != nil && isIPv4(ipc.Gateway) != isIPv4(ipc.Address.IP) {
+			errs = append(errs, fmt.Errorf("ip %d (%s) has gateway %s of a different address family", i, ipc.Address.String(), ipc.Gateway))
+		}
+		key := ipc.Address.IP.String()
+		if prev, ok := seen[key]; ok {
+			errs = append(errs, fmt.Errorf("ip %d (%s) duplicates ip %d", i, key, prev))
+		} else {
+			seen[key] = i
+		}
+	}
+
+	for i, route := range r.Routes {
+		if route == nil {
+			errs = append(errs, fmt.Errorf("route %d is null", i))
+			continue
+		}
+		if route.Dst.IP == nil {
+			errs = append(errs, fmt.Errorf("route %d has no destination", i))
+			continue
+		}
+		if route.GW != nil && isIPv4(route.GW) != isIPv4(route.Dst.IP) {
+			errs = append(errs, fmt.Errorf("route %d (%s) has next hop %s of a different address family", i, route.Dst.String(), route.GW))
+		}
+		if route.Interface != nil && (*route.Interface < 0 || *route.Interface >= len(r.Interfaces)) {
+			errs = append(errs, fmt.Errorf("route %d (%s) references interface %d but result has %d interfaces", i, route.Dst.String(), *route.Interface, len(r.Interfaces)))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// sortedAnnotationKeys returns the keys of annotations in order, so that
+// validation errors are reported in a stable order
+func sortedAnnotationKeys(annotations map[string]string) []string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isIPv4(ip net.IP) bool {
+	return ip.To4() != nil
+}
+
 // Interface contains values about the created interfaces
 type Interface struct {
 	Name       string `json:"name"`
@@ -274,8 +471,29 @@ type Interface struct {
 	Sandbox    string `json:"sandbox,omitempty"`
 	SocketPath string `json:"socketPath,omitempty"`
 	PciID      string `json:"pciID,omitempty"`
+
+	// Device metadata for interfaces backed by an SR-IOV VF or other
+	// host device consumed by the attachment. The indexes are never
+	// negative. They are only serialized for versions that define them.
+	PFIndex    *int   `json:"pfIndex,omitempty"`
+	VFIndex    *int   `json:"vfIndex,omitempty"`
+	RDMADevice string `json:"rdmaDevice,omitempty"`
+
+	// Annotations are hints about the interface for the plugins later in
+	// the chain, such as "bridge": "cni0". They are only serialized for
+	// versions that define them.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+const (
+	// MaxInterfaceAnnotations is the most annotations an interface may have
+	MaxInterfaceAnnotations = 32
+	// MaxAnnotationKeyLength is the longest an annotation key may be
+	MaxAnnotationKeyLength = 63
+	// MaxAnnotationValueLength is the longest an annotation value may be
+	MaxAnnotationValueLength = 256
+)
+
 func (i *Interface) String() string {
 	return fmt.Sprintf("%+v", *i)
 }
@@ -285,6 +503,20 @@ func (i *Interface) Copy() *Interface {
 		return nil
 	}
 	newIntf := *i
+	if i.PFIndex != nil {
+		pf := *i.PFIndex
+		newIntf.PFIndex = &pf
+	}
+	if i.VFIndex != nil {
+		vf := *i.VFIndex
+		newIntf.VFIndex = &vf
+	}
+	if i.Annotations != nil {
+		newIntf.Annotations = make(map[string]string, len(i.Annotations))
+		for k, v := range i.Annotations {
+			newIntf.Annotations[k] = v
+		}
+	}
 	return &newIntf
 }
 
@@ -350,3 +582,13 @@ func (c *IPConfig) UnmarshalJSON(data []byte) error {
 	c.Gateway = ipc.Gateway
 	return nil
 }
+
+// MarshalYAML encodes the result as its JSON encoding would
+func (r Result) MarshalYAML() (interface{}, error) {
+	return types.ToYAML(&r)
+}
+
+// UnmarshalYAML decodes the result as its JSON encoding would
+func (r *Result) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return types.FromYAML(unmarshal, r)
+}
@@ -16,12 +16,15 @@ package types100_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net"
 	"os"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
 
 	"github.com/containernetworking/cni/pkg/types"
 	current "github.com/containernetworking/cni/pkg/types/100"
@@ -56,6 +59,9 @@ func testResult() *current.Result {
 				Sandbox:    "/proc/3553/ns/net",
 				PciID:      "8086:9a01",
 				SocketPath: "/path/to/vhost/fd",
+				PFIndex:    current.Int(0),
+				VFIndex:    current.Int(3),
+				RDMADevice: "mlx5_3",
 			},
 		},
 		IPs: []*current.IPConfig{
@@ -170,6 +176,265 @@ var _ = Describe("Current types operations", func() {
 		Expect(trv11).To(Equal(testResult()))
 	})
 
+	It("deep copies interface device metadata", func() {
+		intf := testResult().Interfaces[0]
+		intfCopy := intf.Copy()
+		Expect(intfCopy).To(Equal(intf))
+
+		*intfCopy.VFIndex = 7
+		Expect(*intf.VFIndex).To(Equal(3))
+
+		intf.Annotations = map[string]string{"bridge": "cni0"}
+		intfCopy = intf.Copy()
+		intfCopy.Annotations["bridge"] = "cni1"
+		Expect(intf.Annotations).To(Equal(map[string]string{"bridge": "cni0"}))
+	})
+
+	Describe("Extensions", func() {
+		It("sets and gets extensions by reverse-DNS key", func() {
+			res := testResult()
+			Expect(res.SetExtension("io.example.vendor", map[string]int{"id": 5})).To(Succeed())
+
+			var ext map[string]int
+			found, err := res.GetExtension("io.example.vendor", &ext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(ext).To(Equal(map[string]int{"id": 5}))
+
+			found, err = res.GetExtension("io.example.other", &ext)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		It("rejects keys which are not reverse-DNS names", func() {
+			res := testResult()
+			Expect(res.SetExtension("vendor", 1)).To(MatchError(`invalid extension key "vendor": must be a reverse-DNS name`))
+		})
+
+		It("does not overwrite existing extensions", func() {
+			res := testResult()
+			Expect(res.SetExtension("io.example.vendor", 1)).To(Succeed())
+			Expect(res.SetExtension("io.example.vendor", 2)).To(MatchError(`extension "io.example.vendor" is already set`))
+		})
+
+		It("only serializes extensions for 1.2.0 results", func() {
+			res := testResult()
+			Expect(res.SetExtension("io.example.vendor", 1)).To(Succeed())
+
+			data, err := json.Marshal(res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("extensions"))
+
+			res12, err := res.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			data, err = json.Marshal(res12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"extensions":{"io.example.vendor":1}`))
+		})
+
+		It("passes extensions through 1.x conversions", func() {
+			res, err := current.NewResult([]byte(`{"cniVersion": "1.2.0", "extensions": {"io.example.vendor": {"a": "b"}}}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			res11, err := res.GetAsVersion("1.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			res12, err := res11.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res12.(*current.Result).Extensions["io.example.vendor"]).To(MatchJSON(`{"a": "b"}`))
+
+			res04, err := res.GetAsVersion("0.4.0")
+			Expect(err).NotTo(HaveOccurred())
+			res12, err = res04.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res12.(*current.Result).Extensions).To(BeNil())
+		})
+	})
+
+	Describe("Route interfaces", func() {
+		It("only serializes route interfaces for 1.2.0 results", func() {
+			res := testResult()
+			res.Routes[0].Interface = current.Int(0)
+
+			data, err := json.Marshal(res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`{"dst":"15.5.6.0/24","gw":"15.5.6.8"}`))
+
+			res12, err := res.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			data, err = json.Marshal(res12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`{"dst":"15.5.6.0/24","gw":"15.5.6.8","interface":0}`))
+		})
+
+		It("decodes route interfaces", func() {
+			res, err := current.NewResult([]byte(`{"cniVersion": "1.2.0", "interfaces": [{"name": "eth0"}], "routes": [{"dst": "0.0.0.0/0", "interface": 0}]}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.(*current.Result).Routes[0].Interface).To(Equal(current.Int(0)))
+		})
+
+		It("drops route interfaces when converting to 0.x", func() {
+			res := testResult()
+			res.Routes[0].Interface = current.Int(0)
+			for _, v := range []string{"0.4.0", "0.2.0"} {
+				old, err := res.GetAsVersion(v)
+				Expect(err).NotTo(HaveOccurred())
+				data, err := json.Marshal(old)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring(`{"dst":"15.5.6.0/24","gw":"15.5.6.8"}`))
+			}
+			Expect(res.Routes[0].Interface).To(Equal(current.Int(0)))
+		})
+	})
+
+	Describe("Interface annotations", func() {
+		It("only serializes interface annotations for 1.2.0 results", func() {
+			res := testResult()
+			res.Interfaces[0].Annotations = map[string]string{"bridge": "cni0", "vlan": "100"}
+
+			data, err := json.Marshal(res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("annotations"))
+
+			res12, err := res.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			data, err = json.Marshal(res12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"annotations":{"bridge":"cni0","vlan":"100"}`))
+
+			decoded, err := current.NewResult(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.(*current.Result).Interfaces[0].Annotations).To(Equal(res.Interfaces[0].Annotations))
+		})
+
+		It("drops interface annotations when converting to 0.x", func() {
+			res := testResult()
+			res.Interfaces[0].Annotations = map[string]string{"bridge": "cni0"}
+			old, err := res.GetAsVersion("0.4.0")
+			Expect(err).NotTo(HaveOccurred())
+			data, err := json.Marshal(old)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("annotations"))
+		})
+	})
+
+	Describe("Interface device info", func() {
+		It("only serializes device info for 1.2.0 results", func() {
+			res := testResult()
+
+			data, err := json.Marshal(res)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).NotTo(ContainSubstring("pfIndex"))
+			Expect(string(data)).NotTo(ContainSubstring("vfIndex"))
+			Expect(string(data)).NotTo(ContainSubstring("rdmaDevice"))
+
+			res12, err := res.GetAsVersion("1.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			data, err = json.Marshal(res12)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring(`"pfIndex":0`))
+			Expect(string(data)).To(ContainSubstring(`"vfIndex":3`))
+			Expect(string(data)).To(ContainSubstring(`"rdmaDevice":"mlx5_3"`))
+
+			decoded, err := current.NewResult(data)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded.(*current.Result).Interfaces[0]).To(Equal(res.Interfaces[0]))
+		})
+	})
+
+	It("round-trips a Result through YAML", func() {
+		res := testResult()
+		out, err := yaml.Marshal(res)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(ContainSubstring("address: 1.2.3.30/24"))
+
+		decoded := &current.Result{}
+		Expect(yaml.Unmarshal(out, decoded)).To(Succeed())
+		expected, err := json.Marshal(res)
+		Expect(err).NotTo(HaveOccurred())
+		actual, err := json.Marshal(decoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(MatchJSON(expected))
+	})
+
+	Describe("Validate", func() {
+		It("accepts a consistent result", func() {
+			Expect(testResult().Validate()).To(Succeed())
+		})
+
+		It("rejects out of range interface indexes", func() {
+			res := testResult()
+			res.IPs[0].Interface = current.Int(3)
+			Expect(res.Validate()).To(MatchError("ip 0 (1.2.3.30/24) references interface 3 but result has 1 interfaces"))
+		})
+
+		It("rejects negative interface MTUs", func() {
+			res := testResult()
+			res.Interfaces[0].Mtu = -1
+			Expect(res.Validate()).To(MatchError("interface 0 (eth0) has negative MTU -1"))
+		})
+
+		It("rejects negative PF and VF indexes", func() {
+			res := testResult()
+			res.Interfaces[0].PFIndex = current.Int(-1)
+			res.Interfaces[0].VFIndex = current.Int(-2)
+			err := res.Validate()
+			Expect(err).To(MatchError(ContainSubstring("interface 0 (eth0) has negative PF index -1")))
+			Expect(err).To(MatchError(ContainSubstring("interface 0 (eth0) has negative VF index -2")))
+		})
+
+		It("rejects oversized interface annotations", func() {
+			res := testResult()
+			res.Interfaces[0].Annotations = map[string]string{
+				"":                      "empty",
+				strings.Repeat("k", 64): "long key",
+				"vlan":                  strings.Repeat("1", 257),
+			}
+			Expect(res.Validate()).To(MatchError(ContainSubstring(`interface 0 (eth0) has annotation key "" which is empty or longer than 63 bytes`)))
+			Expect(res.Validate()).To(MatchError(ContainSubstring(`interface 0 (eth0) has annotation key "kkkk`)))
+			Expect(res.Validate()).To(MatchError(ContainSubstring(`interface 0 (eth0) has annotation "vlan" longer than 256 bytes`)))
+
+			res.Interfaces[0].Annotations = map[string]string{}
+			for i := 0; i <= current.MaxInterfaceAnnotations; i++ {
+				res.Interfaces[0].Annotations[fmt.Sprint(i)] = "x"
+			}
+			Expect(res.Validate()).To(MatchError("interface 0 (eth0) has 33 annotations, more than 32"))
+		})
+
+		It("accepts IPs on host-side interfaces", func() {
+			res := testResult()
+			res.Interfaces = append(res.Interfaces, &current.Interface{Name: "veth1234", Mac: "66:77:88:99:aa:bb"})
+			res.IPs = append(res.IPs, &current.IPConfig{
+				Interface: current.Int(1),
+				Address:   net.IPNet{IP: net.ParseIP("169.254.1.1"), Mask: net.CIDRMask(32, 32)},
+			})
+			Expect(res.Validate()).To(Succeed())
+		})
+
+		It("rejects gateways of the wrong address family", func() {
+			res := testResult()
+			res.IPs[0].Gateway = net.ParseIP("abcd:1234:ffff::1")
+			Expect(res.Validate()).To(MatchError("ip 0 (1.2.3.30/24) has gateway abcd:1234:ffff::1 of a different address family"))
+		})
+
+		It("rejects route next hops of the wrong address family", func() {
+			res := testResult()
+			res.Routes[0].GW = net.ParseIP("1111:dddd::1")
+			Expect(res.Validate()).To(MatchError("route 0 (15.5.6.0/24) has next hop 1111:dddd::1 of a different address family"))
+		})
+
+		It("rejects out of range route interface indexes", func() {
+			res := testResult()
+			res.Routes[0].Interface = current.Int(1)
+			Expect(res.Validate()).To(MatchError("route 0 (15.5.6.0/24) references interface 1 but result has 1 interfaces"))
+		})
+
+		It("rejects duplicate IPs", func() {
+			res := testResult()
+			res.IPs = append(res.IPs, res.IPs[0].Copy())
+			Expect(res.Validate()).To(MatchError("ip 2 (1.2.3.30) duplicates ip 0"))
+		})
+	})
+
 	It("correctly encodes a 0.1.0 Result", func() {
 		res, err := testResult().GetAsVersion("0.1.0")
 		Expect(err).NotTo(HaveOccurred())
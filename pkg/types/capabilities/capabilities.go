@@ -0,0 +1,190 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package capabilities provides Go types for the well-known capability
+// arguments described in CONVENTIONS.md. Runtimes pass these to plugins
+// inside the "runtimeConfig" dictionary of the network configuration.
+package capabilities
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+)
+
+// Well-known capability names. These are used both as keys in a plugin's
+// "capabilities" map and as keys in the "runtimeConfig" dictionary.
+const (
+	PortMappingsKey = "portMappings"
+	BandwidthKey    = "bandwidth"
+	IPRangesKey     = "ipRanges"
+	IPsKey          = "ips"
+	MACKey          = "mac"
+	DNSKey          = "dns"
+	AliasesKey      = "aliases"
+	CgroupPathKey   = "cgroupPath"
+	SysctlKey       = "sysctl"
+	// CDIDevicesKey carries the CDI (Container Device Interface) devices
+	// allocated to the attachment, such as SR-IOV virtual functions
+	// allocated by kubelet's device manager
+	CDIDevicesKey = "cdiDevices"
+)
+
+// PortMapping maps a port on the host to a port in the container
+// network namespace
+type PortMapping struct {
+	HostPort      int    `json:"hostPort"`
+	ContainerPort int    `json:"containerPort"`
+	Protocol      string `json:"protocol"`
+	HostIP        string `json:"hostIP,omitempty"`
+}
+
+// Bandwidth contains the desired bandwidth limits. Rates are in bits
+// per second, burst values are in bits.
+type Bandwidth struct {
+	IngressRate  uint64 `json:"ingressRate,omitempty"`
+	IngressBurst uint64 `json:"ingressBurst,omitempty"`
+	EgressRate   uint64 `json:"egressRate,omitempty"`
+	EgressBurst  uint64 `json:"egressBurst,omitempty"`
+}
+
+// IPRange is a single pool of addresses for one allocation
+type IPRange struct {
+	Subnet     types.IPNet `json:"subnet"`
+	RangeStart net.IP      `json:"rangeStart,omitempty"`
+	RangeEnd   net.IP      `json:"rangeEnd,omitempty"`
+	Gateway    net.IP      `json:"gateway,omitempty"`
+}
+
+// IPRangeSet is the list of pools a single address may be allocated from
+type IPRangeSet []IPRange
+
+// DNS is the runtime DNS configuration. Note that the keys differ from
+// those of types.DNS used in results.
+type DNS struct {
+	Servers  []string `json:"servers,omitempty"`
+	Searches []string `json:"searches,omitempty"`
+	Options  []string `json:"options,omitempty"`
+}
+
+// InterfaceNamePlaceholder may be used in place of the interface name in
+// per-interface sysctl names, e.g. "net.ipv4.conf.IFNAME.arp_notify".
+// Plugins substitute it with the name of the interface they create.
+const InterfaceNamePlaceholder = "IFNAME"
+
+// Sysctls maps sysctl names, in either dotted ("net.core.somaxconn") or
+// slash ("net/core/somaxconn") form, to the values to set inside the
+// container network namespace
+type Sysctls map[string]string
+
+// Validate checks that all sysctls are network sysctls, which are the
+// only ones scoped to the container network namespace
+func (s Sysctls) Validate() error {
+	for name := range s {
+		path := SysctlPath(name)
+		if !strings.HasPrefix(path, "/proc/sys/net/") {
+			return fmt.Errorf("sysctl %q is not a network sysctl", name)
+		}
+		for _, part := range strings.Split(strings.TrimPrefix(path, "/proc/sys/"), "/") {
+			if part == "" || part == "." || part == ".." {
+				return fmt.Errorf("invalid sysctl name %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// ForInterface returns the sysctls with InterfaceNamePlaceholder replaced
+// by ifName. If ifName contains a dot, as VLAN interfaces often do, the
+// affected names are returned in slash form so they remain unambiguous.
+func (s Sysctls) ForInterface(ifName string) Sysctls {
+	out := make(Sysctls, len(s))
+	for name, value := range s {
+		if strings.Contains(name, InterfaceNamePlaceholder) && strings.Contains(ifName, ".") && !strings.Contains(name, "/") {
+			name = strings.ReplaceAll(name, ".", "/")
+		}
+		out[strings.ReplaceAll(name, InterfaceNamePlaceholder, ifName)] = value
+	}
+	return out
+}
+
+// SysctlPath returns the /proc/sys path of the named sysctl
+func SysctlPath(name string) string {
+	if !strings.Contains(name, "/") {
+		name = strings.ReplaceAll(name, ".", "/")
+	}
+	return "/proc/sys/" + strings.TrimPrefix(name, "/")
+}
+
+// RuntimeConfig holds all well-known capability arguments. Unset fields
+// are omitted when encoding.
+type RuntimeConfig struct {
+	PortMappings []PortMapping `json:"portMappings,omitempty"`
+	Bandwidth    *Bandwidth    `json:"bandwidth,omitempty"`
+	IPRanges     []IPRangeSet  `json:"ipRanges,omitempty"`
+	IPs          []string      `json:"ips,omitempty"`
+	MAC          string        `json:"mac,omitempty"`
+	DNS          *DNS          `json:"dns,omitempty"`
+	Aliases      []string      `json:"aliases,omitempty"`
+	CgroupPath   string        `json:"cgroupPath,omitempty"`
+	Sysctl       Sysctls       `json:"sysctl,omitempty"`
+	CDIDevices   []string      `json:"cdiDevices,omitempty"`
+}
+
+// FromNetConf decodes the "runtimeConfig" dictionary of the given network
+// configuration JSON. A configuration without a runtimeConfig yields an
+// empty RuntimeConfig.
+func FromNetConf(stdinData []byte) (*RuntimeConfig, error) {
+	var conf struct {
+		RuntimeConfig *RuntimeConfig `json:"runtimeConfig"`
+	}
+	if err := json.Unmarshal(stdinData, &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse runtimeConfig: %w", err)
+	}
+	if conf.RuntimeConfig == nil {
+		return &RuntimeConfig{}, nil
+	}
+	return conf.RuntimeConfig, nil
+}
+
+// FromCapabilityArgs converts a map of capability arguments, as found in
+// libcni.RuntimeConf.CapabilityArgs, into a RuntimeConfig
+func FromCapabilityArgs(args map[string]interface{}) (*RuntimeConfig, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+	rc := &RuntimeConfig{}
+	if err := json.Unmarshal(data, rc); err != nil {
+		return nil, fmt.Errorf("failed to parse capability args: %w", err)
+	}
+	return rc, nil
+}
+
+// CapabilityArgs converts the RuntimeConfig into a map suitable for
+// libcni.RuntimeConf.CapabilityArgs. Only set fields are included.
+func (rc *RuntimeConfig) CapabilityArgs() (map[string]interface{}, error) {
+	data, err := json.Marshal(rc)
+	if err != nil {
+		return nil, err
+	}
+	args := make(map[string]interface{})
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
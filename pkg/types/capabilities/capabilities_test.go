@@ -0,0 +1,134 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+)
+
+var _ = Describe("Capability arguments", func() {
+	It("decodes runtimeConfig from a network configuration", func() {
+		rc, err := capabilities.FromNetConf([]byte(`{
+			"cniVersion": "1.0.0",
+			"name": "mynet",
+			"type": "portmap",
+			"runtimeConfig": {
+				"portMappings": [
+					{"hostPort": 8080, "containerPort": 80, "protocol": "tcp"}
+				],
+				"bandwidth": {"ingressRate": 2048, "egressBurst": 1600},
+				"ipRanges": [[{"subnet": "10.1.2.0/24", "rangeStart": "10.1.2.3", "gateway": "10.1.2.254"}]],
+				"ips": ["10.1.2.5/24"],
+				"mac": "c2:11:22:33:44:55",
+				"dns": {"servers": ["8.8.8.8"], "searches": ["corp.example.com"]},
+				"aliases": ["primary-db"],
+				"cgroupPath": "/kubepods/pod1234"
+			}
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(rc.PortMappings).To(Equal([]capabilities.PortMapping{
+			{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+		}))
+		Expect(rc.Bandwidth).To(Equal(&capabilities.Bandwidth{IngressRate: 2048, EgressBurst: 1600}))
+		Expect(rc.IPRanges).To(HaveLen(1))
+		Expect(rc.IPRanges[0]).To(HaveLen(1))
+		Expect(rc.IPRanges[0][0].Subnet.IP.String()).To(Equal("10.1.2.0"))
+		Expect(rc.IPRanges[0][0].RangeStart).To(Equal(net.ParseIP("10.1.2.3")))
+		Expect(rc.IPs).To(Equal([]string{"10.1.2.5/24"}))
+		Expect(rc.MAC).To(Equal("c2:11:22:33:44:55"))
+		Expect(rc.DNS).To(Equal(&capabilities.DNS{
+			Servers:  []string{"8.8.8.8"},
+			Searches: []string{"corp.example.com"},
+		}))
+		Expect(rc.Aliases).To(Equal([]string{"primary-db"}))
+		Expect(rc.CgroupPath).To(Equal("/kubepods/pod1234"))
+	})
+
+	It("returns an empty config when runtimeConfig is missing", func() {
+		rc, err := capabilities.FromNetConf([]byte(`{"name": "mynet", "type": "bridge"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rc).To(Equal(&capabilities.RuntimeConfig{}))
+	})
+
+	It("returns an error for a malformed runtimeConfig", func() {
+		_, err := capabilities.FromNetConf([]byte(`{"runtimeConfig": {"mac": 5}}`))
+		Expect(err).To(MatchError(HavePrefix("failed to parse runtimeConfig:")))
+	})
+
+	It("round-trips through capability args", func() {
+		rc := &capabilities.RuntimeConfig{
+			PortMappings: []capabilities.PortMapping{
+				{HostPort: 8000, ContainerPort: 8001, Protocol: "udp"},
+			},
+			MAC: "c2:11:22:33:44:55",
+		}
+		args, err := rc.CapabilityArgs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(args).To(HaveLen(2))
+		Expect(args).To(HaveKey(capabilities.PortMappingsKey))
+		Expect(args).To(HaveKey(capabilities.MACKey))
+
+		recovered, err := capabilities.FromCapabilityArgs(args)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recovered).To(Equal(rc))
+	})
+
+	Describe("sysctls", func() {
+		It("decodes sysctls from runtimeConfig", func() {
+			rc, err := capabilities.FromNetConf([]byte(`{"runtimeConfig": {"sysctl": {"net.core.somaxconn": "500"}}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rc.Sysctl).To(Equal(capabilities.Sysctls{"net.core.somaxconn": "500"}))
+
+			args, err := rc.CapabilityArgs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(args).To(HaveKey(capabilities.SysctlKey))
+		})
+
+		It("expands the interface name placeholder", func() {
+			s := capabilities.Sysctls{
+				"net.ipv4.conf.IFNAME.arp_notify": "1",
+				"net/ipv6/conf/IFNAME/accept_ra":  "0",
+				"net.core.somaxconn":              "500",
+			}
+			Expect(s.ForInterface("eth0")).To(Equal(capabilities.Sysctls{
+				"net.ipv4.conf.eth0.arp_notify": "1",
+				"net/ipv6/conf/eth0/accept_ra":  "0",
+				"net.core.somaxconn":            "500",
+			}))
+			Expect(s.ForInterface("eth0.100")).To(Equal(capabilities.Sysctls{
+				"net/ipv4/conf/eth0.100/arp_notify": "1",
+				"net/ipv6/conf/eth0.100/accept_ra":  "0",
+				"net.core.somaxconn":                "500",
+			}))
+		})
+
+		It("maps names to /proc/sys paths", func() {
+			Expect(capabilities.SysctlPath("net.core.somaxconn")).To(Equal("/proc/sys/net/core/somaxconn"))
+			Expect(capabilities.SysctlPath("net/ipv4/conf/eth0.100/forwarding")).To(Equal("/proc/sys/net/ipv4/conf/eth0.100/forwarding"))
+		})
+
+		It("only allows network sysctls", func() {
+			Expect(capabilities.Sysctls{"net.core.somaxconn": "500"}.Validate()).To(Succeed())
+			Expect(capabilities.Sysctls{"kernel.pid_max": "1"}.Validate()).To(MatchError(`sysctl "kernel.pid_max" is not a network sysctl`))
+			Expect(capabilities.Sysctls{"net/../kernel/pid_max": "1"}.Validate()).To(MatchError(`invalid sysctl name "net/../kernel/pid_max"`))
+		})
+	})
+})
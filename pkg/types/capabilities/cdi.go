@@ -0,0 +1,116 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CDIDevicesEnv is the environment variable in which runtimes pass the
+// CDI devices of an attachment to every plugin, as a comma-separated list
+const CDIDevicesEnv = "CNI_CDI_DEVICES"
+
+// CDIDevice is a fully-qualified CDI device name, vendor/class=name, such
+// as "nvidia.com/gpu=0" or "intel.com/sriov=vf-3"
+type CDIDevice struct {
+	Vendor string
+	Class  string
+	Name   string
+}
+
+func (d CDIDevice) String() string {
+	return d.Vendor + "/" + d.Class + "=" + d.Name
+}
+
+// Kind returns the vendor/class part of the name, which identifies the
+// CDI spec describing the device
+func (d CDIDevice) Kind() string {
+	return d.Vendor + "/" + d.Class
+}
+
+// ParseCDIDevice parses a fully-qualified CDI device name. As in the CDI
+// specification, the vendor is a domain-like name and the class an
+// identifier, both starting with a letter, while the name may also
+// contain dots and colons; all end with a letter or digit.
+func ParseCDIDevice(device string) (CDIDevice, error) {
+	kind, name, ok := strings.Cut(device, "=")
+	if !ok {
+		return CDIDevice{}, fmt.Errorf("CDI device %q is not of the form vendor/class=name", device)
+	}
+	vendor, class, ok := strings.Cut(kind, "/")
+	if !ok {
+		return CDIDevice{}, fmt.Errorf("CDI device %q is not of the form vendor/class=name", device)
+	}
+	if err := checkCDIPart(vendor, true, "-_."); err != nil {
+		return CDIDevice{}, fmt.Errorf("CDI device %q has an invalid vendor: %w", device, err)
+	}
+	if err := checkCDIPart(class, true, "-_"); err != nil {
+		return CDIDevice{}, fmt.Errorf("CDI device %q has an invalid class: %w", device, err)
+	}
+	if err := checkCDIPart(name, false, "-_.:"); err != nil {
+		return CDIDevice{}, fmt.Errorf("CDI device %q has an invalid name: %w", device, err)
+	}
+	return CDIDevice{Vendor: vendor, Class: class, Name: name}, nil
+}
+
+// ParseCDIDevices parses a list of fully-qualified CDI device names
+func ParseCDIDevices(devices []string) ([]CDIDevice, error) {
+	parsed := make([]CDIDevice, 0, len(devices))
+	for _, device := range devices {
+		d, err := ParseCDIDevice(device)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, d)
+	}
+	return parsed, nil
+}
+
+// Devices returns the parsed CDI devices of the runtime configuration
+func (rc *RuntimeConfig) Devices() ([]CDIDevice, error) {
+	return ParseCDIDevices(rc.CDIDevices)
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isAlphaNum(c byte) bool {
+	return isLetter(c) || ('0' <= c && c <= '9')
+}
+
+// checkCDIPart checks one part of a CDI device name, which may contain
+// letters, digits and the given punctuation
+func checkCDIPart(part string, letterFirst bool, punctuation string) error {
+	if part == "" {
+		return fmt.Errorf("it is empty")
+	}
+	if letterFirst && !isLetter(part[0]) {
+		return fmt.Errorf("it does not start with a letter")
+	}
+	if !isAlphaNum(part[0]) {
+		return fmt.Errorf("it does not start with a letter or digit")
+	}
+	if !isAlphaNum(part[len(part)-1]) {
+		return fmt.Errorf("it does not end with a letter or digit")
+	}
+	for i := 0; i < len(part); i++ {
+		if !isAlphaNum(part[i]) && !strings.ContainsRune(punctuation, rune(part[i])) {
+			return fmt.Errorf("it contains %q", part[i])
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+)
+
+var _ = Describe("CDI devices", func() {
+	It("parses fully-qualified device names", func() {
+		device, err := capabilities.ParseCDIDevice("intel.com/sriov-net_1=0000:3b:02.1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(device).To(Equal(capabilities.CDIDevice{Vendor: "intel.com", Class: "sriov-net_1", Name: "0000:3b:02.1"}))
+		Expect(device.Kind()).To(Equal("intel.com/sriov-net_1"))
+		Expect(device.String()).To(Equal("intel.com/sriov-net_1=0000:3b:02.1"))
+	})
+
+	DescribeTable("rejects malformed names",
+		func(device, message string) {
+			_, err := capabilities.ParseCDIDevice(device)
+			Expect(err).To(MatchError(message))
+		},
+		Entry("no name", "intel.com/sriov", `CDI device "intel.com/sriov" is not of the form vendor/class=name`),
+		Entry("no class", "intel.com=vf", `CDI device "intel.com=vf" is not of the form vendor/class=name`),
+		Entry("an empty vendor", "/sriov=vf", `CDI device "/sriov=vf" has an invalid vendor: it is empty`),
+		Entry("a vendor starting with a digit", "1intel.com/sriov=vf", `CDI device "1intel.com/sriov=vf" has an invalid vendor: it does not start with a letter`),
+		Entry("a class with a dot", "intel.com/sr.iov=vf", `CDI device "intel.com/sr.iov=vf" has an invalid class: it contains '.'`),
+		Entry("a name ending with a dash", "intel.com/sriov=vf-", `CDI device "intel.com/sriov=vf-" has an invalid name: it does not end with a letter or digit`),
+		Entry("a name with a slash", "intel.com/sriov=a/b", `CDI device "intel.com/sriov=a/b" has an invalid name: it contains '/'`),
+	)
+
+	It("decodes and validates devices in runtimeConfig", func() {
+		rc, err := capabilities.FromNetConf([]byte(`{"runtimeConfig": {"cdiDevices": ["nvidia.com/gpu=0", "nvidia.com/gpu=all"]}}`))
+		Expect(err).NotTo(HaveOccurred())
+		devices, err := rc.Devices()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(devices).To(HaveLen(2))
+		Expect(devices[1].Name).To(Equal("all"))
+
+		err = capabilities.ValidateCapabilityArgs(map[string]interface{}{"cdiDevices": []string{"nvidia.com/gpu=0", "gpu0"}})
+		Expect(err).To(MatchError(`cdiDevices[1]: CDI device "gpu0" is not of the form vendor/class=name`))
+	})
+})
@@ -0,0 +1,236 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+)
+
+// ValidationError is a problem with one value of the capability arguments
+type ValidationError struct {
+	// Path locates the value within the runtimeConfig dictionary, such
+	// as "portMappings[1].hostPort"
+	Path string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+func invalid(path, format string, args ...interface{}) error {
+	return &ValidationError{Path: path, Msg: fmt.Sprintf(format, args...)}
+}
+
+// maxBurst is the largest burst, in bits, which plugins can pass to the
+// kernel, where it is a 32-bit number of bytes
+const maxBurst = math.MaxUint32 * 8
+
+// Validate checks the well-known capability arguments which plugins
+// cannot apply as given: invalid ports and protocols, rates without
+// bursts, addresses outside their subnet, unusable MAC addresses and
+// malformed CDI device names. The returned error joins a
+// *ValidationError for each problem.
+func (rc *RuntimeConfig) Validate() error {
+	var errs []error
+	errs = append(errs, validatePortMappings(PortMappingsKey, rc.PortMappings)...)
+	if rc.Bandwidth != nil {
+		errs = append(errs, rc.Bandwidth.validate(BandwidthKey)...)
+	}
+	errs = append(errs, validateIPRanges(IPRangesKey, rc.IPRanges)...)
+	if rc.MAC != "" {
+		errs = append(errs, validateMAC(MACKey, rc.MAC)...)
+	}
+	if err := rc.Sysctl.Validate(); err != nil {
+		errs = append(errs, invalid(SysctlKey, "%v", err))
+	}
+	for i, device := range rc.CDIDevices {
+		if _, err := ParseCDIDevice(device); err != nil {
+			errs = append(errs, invalid(fmt.Sprintf("%s[%d]", CDIDevicesKey, i), "%v", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateCapabilityArgs checks the well-known capability arguments in
+// a map such as libcni.RuntimeConf.CapabilityArgs, as Validate does.
+// Values which cannot be decoded are reported at their key; other keys
+// are ignored.
+func ValidateCapabilityArgs(args map[string]interface{}) error {
+	var errs []error
+	decodable := make(map[string]interface{})
+	for _, key := range []string{PortMappingsKey, BandwidthKey, IPRangesKey, MACKey, SysctlKey, CDIDevicesKey} {
+		value, ok := args[key]
+		if !ok {
+			continue
+		}
+		if _, err := FromCapabilityArgs(map[string]interface{}{key: value}); err != nil {
+			if cause := errors.Unwrap(err); cause != nil {
+				err = cause
+			}
+			errs = append(errs, invalid(key, "%v", err))
+			continue
+		}
+		decodable[key] = value
+	}
+	rc, err := FromCapabilityArgs(decodable)
+	if err != nil {
+		return err
+	}
+	errs = append(errs, rc.Validate())
+	return errors.Join(errs...)
+}
+
+// ValidatePortMappings checks that ports are valid, protocols are tcp,
+// udp or sctp, host IPs are addresses and that no host port is mapped
+// twice
+func ValidatePortMappings(mappings []PortMapping) error {
+	return errors.Join(validatePortMappings(PortMappingsKey, mappings)...)
+}
+
+func validatePortMappings(path string, mappings []PortMapping) []error {
+	var errs []error
+	seen := make(map[string]int)
+	for i, pm := range mappings {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		if pm.HostPort < 1 || pm.HostPort > math.MaxUint16 {
+			errs = append(errs, invalid(p+".hostPort", "must be between 1 and 65535, not %d", pm.HostPort))
+		}
+		if pm.ContainerPort < 1 || pm.ContainerPort > math.MaxUint16 {
+			errs = append(errs, invalid(p+".containerPort", "must be between 1 and 65535, not %d", pm.ContainerPort))
+		}
+		protocol := strings.ToLower(pm.Protocol)
+		switch protocol {
+		case "":
+			protocol = "tcp"
+		case "tcp", "udp", "sctp":
+		default:
+			errs = append(errs, invalid(p+".protocol", "must be tcp, udp or sctp, not %q", pm.Protocol))
+		}
+		if pm.HostIP != "" && net.ParseIP(pm.HostIP) == nil {
+			errs = append(errs, invalid(p+".hostIP", "%q is not an IP address", pm.HostIP))
+		}
+
+		key := fmt.Sprintf("%s/%s/%d", pm.HostIP, protocol, pm.HostPort)
+		if first, ok := seen[key]; ok {
+			errs = append(errs, invalid(p, "maps the same host port as %s[%d]", path, first))
+		} else {
+			seen[key] = i
+		}
+	}
+	return errs
+}
+
+// Validate checks that each rate has a burst and each burst a rate, as
+// traffic shaping needs both, and that bursts fit the kernel's limit
+func (b *Bandwidth) Validate() error {
+	return errors.Join(b.validate(BandwidthKey)...)
+}
+
+func (b *Bandwidth) validate(path string) []error {
+	var errs []error
+	for _, dir := range []struct {
+		name        string
+		rate, burst uint64
+	}{
+		{"ingress", b.IngressRate, b.IngressBurst},
+		{"egress", b.EgressRate, b.EgressBurst},
+	} {
+		switch {
+		case dir.rate > 0 && dir.burst == 0:
+			errs = append(errs, invalid(path+"."+dir.name+"Burst", "must be set as %sRate is", dir.name))
+		case dir.rate == 0 && dir.burst > 0:
+			errs = append(errs, invalid(path+"."+dir.name+"Rate", "must be set as %sBurst is", dir.name))
+		}
+		if dir.burst > maxBurst {
+			errs = append(errs, invalid(path+"."+dir.name+"Burst", "must be at most %d bits, not %d", uint64(maxBurst), dir.burst))
+		}
+	}
+	return errs
+}
+
+// ValidateIPRanges checks that each set of ranges is not empty and of a
+// single address family, and that each range has a subnet which holds
+// its start, end and gateway, with the start not after the end
+func ValidateIPRanges(ranges []IPRangeSet) error {
+	return errors.Join(validateIPRanges(IPRangesKey, ranges)...)
+}
+
+func validateIPRanges(path string, ranges []IPRangeSet) []error {
+	var errs []error
+	for i, set := range ranges {
+		setPath := fmt.Sprintf("%s[%d]", path, i)
+		if len(set) == 0 {
+			errs = append(errs, invalid(setPath, "must not be empty"))
+		}
+		for j, r := range set {
+			p := fmt.Sprintf("%s[%d]", setPath, j)
+			if r.Subnet.IP == nil {
+				errs = append(errs, invalid(p+".subnet", "is required"))
+				continue
+			}
+			subnet := net.IPNet{IP: r.Subnet.IP.Mask(r.Subnet.Mask), Mask: r.Subnet.Mask}
+			if subnet.IP == nil {
+				errs = append(errs, invalid(p+".subnet", "has a mask which does not match its address"))
+				continue
+			}
+			if j > 0 && set[0].Subnet.IP != nil && (set[0].Subnet.IP.To4() == nil) != (subnet.IP.To4() == nil) {
+				errs = append(errs, invalid(p+".subnet", "is not of the same address family as %s[0]", setPath))
+			}
+			for _, addr := range []struct {
+				name string
+				ip   net.IP
+			}{
+				{"rangeStart", r.RangeStart},
+				{"rangeEnd", r.RangeEnd},
+				{"gateway", r.Gateway},
+			} {
+				if addr.ip != nil && !subnet.Contains(addr.ip) {
+					errs = append(errs, invalid(p+"."+addr.name, "%s is not in subnet %s", addr.ip, subnet.String()))
+				}
+			}
+			if r.RangeStart != nil && r.RangeEnd != nil && bytes.Compare(r.RangeStart.To16(), r.RangeEnd.To16()) > 0 {
+				errs = append(errs, invalid(p+".rangeEnd", "%s is before rangeStart %s", r.RangeEnd, r.RangeStart))
+			}
+		}
+	}
+	return errs
+}
+
+// ValidateMAC checks that mac is a unicast Ethernet address other than
+// all zeros, which is the only kind an interface can be given
+func ValidateMAC(mac string) error {
+	return errors.Join(validateMAC(MACKey, mac)...)
+}
+
+func validateMAC(path, mac string) []error {
+	hw, err := net.ParseMAC(mac)
+	switch {
+	case err != nil:
+		return []error{invalid(path, "%q is not a MAC address", mac)}
+	case len(hw) != 6:
+		return []error{invalid(path, "%q is not a 6-byte Ethernet address", mac)}
+	case hw[0]&1 == 1:
+		return []error{invalid(path, "%q is a multicast address", mac)}
+	case bytes.Equal(hw, make(net.HardwareAddr, 6)):
+		return []error{invalid(path, "%q is all zeros", mac)}
+	}
+	return nil
+}
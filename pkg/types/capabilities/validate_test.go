@@ -0,0 +1,142 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities_test
+
+import (
+	"errors"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+)
+
+// validationErrors returns the *ValidationErrors joined in err
+func validationErrors(err error) []string {
+	var msgs []string
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil
+	}
+	for _, e := range joined.Unwrap() {
+		var verr *capabilities.ValidationError
+		if errors.As(e, &verr) {
+			msgs = append(msgs, verr.Error())
+			continue
+		}
+		msgs = append(msgs, validationErrors(e)...)
+	}
+	return msgs
+}
+
+func mustSubnet(s string) types.IPNet {
+	ip, ipn, err := net.ParseCIDR(s)
+	Expect(err).NotTo(HaveOccurred())
+	return types.IPNet{IP: ip, Mask: ipn.Mask}
+}
+
+var _ = Describe("Validating capability arguments", func() {
+	It("accepts valid arguments", func() {
+		rc := &capabilities.RuntimeConfig{
+			PortMappings: []capabilities.PortMapping{
+				{HostPort: 8080, ContainerPort: 80, Protocol: "tcp"},
+				{HostPort: 8080, ContainerPort: 80, Protocol: "UDP"},
+				{HostPort: 8080, ContainerPort: 80, HostIP: "10.0.0.1"},
+			},
+			Bandwidth: &capabilities.Bandwidth{IngressRate: 1000, IngressBurst: 2000},
+			IPRanges: []capabilities.IPRangeSet{{
+				{Subnet: mustSubnet("10.1.2.0/24"), RangeStart: net.ParseIP("10.1.2.10"), RangeEnd: net.ParseIP("10.1.2.20"), Gateway: net.ParseIP("10.1.2.1")},
+				{Subnet: mustSubnet("10.1.3.0/24")},
+			}},
+			MAC:    "c2:11:22:33:44:55",
+			Sysctl: capabilities.Sysctls{"net.ipv4.ip_forward": "1"},
+		}
+		Expect(rc.Validate()).To(Succeed())
+		Expect((&capabilities.RuntimeConfig{}).Validate()).To(Succeed())
+	})
+
+	It("reports every problem in port mappings with its path", func() {
+		err := capabilities.ValidatePortMappings([]capabilities.PortMapping{
+			{HostPort: 0, ContainerPort: 70000, Protocol: "icmp"},
+			{HostPort: 80, ContainerPort: 80, HostIP: "not-an-ip"},
+			{HostPort: 443, ContainerPort: 443},
+			{HostPort: 443, ContainerPort: 8443, Protocol: "TCP"},
+		})
+		Expect(validationErrors(err)).To(Equal([]string{
+			"portMappings[0].hostPort: must be between 1 and 65535, not 0",
+			"portMappings[0].containerPort: must be between 1 and 65535, not 70000",
+			`portMappings[0].protocol: must be tcp, udp or sctp, not "icmp"`,
+			`portMappings[1].hostIP: "not-an-ip" is not an IP address`,
+			"portMappings[3]: maps the same host port as portMappings[2]",
+		}))
+	})
+
+	It("requires bandwidth rates and bursts together", func() {
+		err := (&capabilities.Bandwidth{IngressRate: 1000, EgressBurst: 1 << 40}).Validate()
+		Expect(validationErrors(err)).To(Equal([]string{
+			"bandwidth.ingressBurst: must be set as ingressRate is",
+			"bandwidth.egressRate: must be set as egressBurst is",
+			"bandwidth.egressBurst: must be at most 34359738360 bits, not 1099511627776",
+		}))
+	})
+
+	It("checks IP ranges against their subnets", func() {
+		err := capabilities.ValidateIPRanges([]capabilities.IPRangeSet{
+			{},
+			{
+				{Subnet: mustSubnet("10.1.2.0/24"), RangeStart: net.ParseIP("10.1.2.50"), RangeEnd: net.ParseIP("10.1.2.20"), Gateway: net.ParseIP("10.1.3.1")},
+				{Subnet: mustSubnet("fd00::/64")},
+				{},
+			},
+		})
+		Expect(validationErrors(err)).To(Equal([]string{
+			"ipRanges[0]: must not be empty",
+			"ipRanges[1][0].gateway: 10.1.3.1 is not in subnet 10.1.2.0/24",
+			"ipRanges[1][0].rangeEnd: 10.1.2.20 is before rangeStart 10.1.2.50",
+			"ipRanges[1][1].subnet: is not of the same address family as ipRanges[1][0]",
+			"ipRanges[1][2].subnet: is required",
+		}))
+	})
+
+	DescribeTable("rejects unusable MAC addresses",
+		func(mac, message string) {
+			Expect(capabilities.ValidateMAC(mac)).To(MatchError(message))
+		},
+		Entry("malformed", "c2:11:22", `mac: "c2:11:22" is not a MAC address`),
+		Entry("not Ethernet", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01", `mac: "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01" is not a 6-byte Ethernet address`),
+		Entry("multicast", "01:00:5e:00:00:01", `mac: "01:00:5e:00:00:01" is a multicast address`),
+		Entry("all zeros", "00:00:00:00:00:00", `mac: "00:00:00:00:00:00" is all zeros`),
+	)
+
+	Describe("ValidateCapabilityArgs", func() {
+		It("validates the well-known arguments of a map", func() {
+			err := capabilities.ValidateCapabilityArgs(map[string]interface{}{
+				"portMappings":  []map[string]interface{}{{"hostPort": 8080, "containerPort": 0}},
+				"mac":           5,
+				"somethingElse": "ignored",
+			})
+			Expect(validationErrors(err)).To(Equal([]string{
+				"mac: json: cannot unmarshal number into Go struct field RuntimeConfig.mac of type string",
+				"portMappings[0].containerPort: must be between 1 and 65535, not 0",
+			}))
+		})
+
+		It("accepts arguments it does not know", func() {
+			Expect(capabilities.ValidateCapabilityArgs(map[string]interface{}{"io.example.foo": 5})).To(Succeed())
+		})
+	})
+})
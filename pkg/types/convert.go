@@ -0,0 +1,80 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GetAsVersionWithWarnings converts result to the given version like
+// Result.GetAsVersion, and additionally returns a warning for each field
+// of result that cannot be represented in that version and so was dropped
+// or changed by the conversion. Callers that pass results between plugins
+// negotiating different versions can use the warnings to surface the
+// loss instead of discarding information silently.
+func GetAsVersionWithWarnings(result Result, version string) (Result, []string, error) {
+	converted, err := result.GetAsVersion(version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diffs, err := DiffResults(result, converted)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to compare converted result: %w", err)
+	}
+
+	var warnings []string
+	for _, d := range diffs {
+		switch d.Kind {
+		case DiffRemoved:
+			warnings = append(warnings, fmt.Sprintf("%s dropped converting to CNI version %s: %s", d.Field, version, d.Old))
+		case DiffChanged:
+			warnings = append(warnings, fmt.Sprintf("%s changed converting to CNI version %s: %s -> %s", d.Field, version, d.Old, d.New))
+		}
+	}
+
+	// Fields the converted result keeps but does not serialize for its
+	// version, such as extensions, are not found by DiffResults
+	before, err := topLevelKeys(result)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := topLevelKeys(converted)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range []string{"extensions"} {
+		if _, ok := before[key]; ok {
+			if _, ok := after[key]; !ok {
+				warnings = append(warnings, fmt.Sprintf("%s dropped converting to CNI version %s", key, version))
+			}
+		}
+	}
+
+	return converted, warnings, nil
+}
+
+func topLevelKeys(r Result) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
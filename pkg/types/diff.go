@@ -0,0 +1,277 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// diffVersion is the result version both sides are converted to before
+// comparison. It must be the newest result version, so that no field is
+// lost by the conversion, and its shape must match diffResult.
+const diffVersion = "1.2.0"
+
+// DiffKind describes how an element differs between two results
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+)
+
+// Difference is a single difference between two results. Field names
+// the element, e.g. "ips[10.1.2.3/24].gateway" or "dns.nameservers".
+// Old and New hold the JSON encoding of the values, and are empty for
+// added and removed elements respectively.
+type Difference struct {
+	Kind  DiffKind
+	Field string
+	Old   string
+	New   string
+}
+
+func (d Difference) String() string {
+	switch d.Kind {
+	case DiffAdded:
+		return fmt.Sprintf("+ %s: %s", d.Field, d.New)
+	case DiffRemoved:
+		return fmt.Sprintf("- %s: %s", d.Field, d.Old)
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", d.Field, d.Old, d.New)
+}
+
+// JSON shape of a 1.x result, so results can be compared without
+// depending on the versioned result packages
+type diffIPConfig struct {
+	Interface *int            `json:"interface,omitempty"`
+	Address   string          `json:"address"`
+	Gateway   json.RawMessage `json:"gateway,omitempty"`
+}
+
+type diffResult struct {
+	Interfaces []map[string]json.RawMessage `json:"interfaces,omitempty"`
+	IPs        []diffIPConfig               `json:"ips,omitempty"`
+	Routes     []json.RawMessage            `json:"routes,omitempty"`
+	DNS        map[string]json.RawMessage   `json:"dns,omitempty"`
+	Extensions map[string]json.RawMessage   `json:"extensions,omitempty"`
+}
+
+func decodeForDiff(r Result) (*diffResult, error) {
+	converted, err := r.GetAsVersion(diffVersion)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(converted)
+	if err != nil {
+		return nil, err
+	}
+	dr := &diffResult{}
+	if err := json.Unmarshal(data, dr); err != nil {
+		return nil, err
+	}
+	return dr, nil
+}
+
+// DiffResults compares two results and returns their differences, going
+// from a to b. Interfaces are matched by name and sandbox, IPs by address
+// and routes by their full value, with the interface they refer to by
+// name. Extensions are compared by key. Results of any version may be compared
+// as long as they can be converted to the current result version.
+func DiffResults(a, b Result) ([]Difference, error) {
+	ra, err := decodeForDiff(a)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert first result: %w", err)
+	}
+	rb, err := decodeForDiff(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert second result: %w", err)
+	}
+
+	var diffs []Difference
+	diffs = append(diffs, diffInterfaces(ra.Interfaces, rb.Interfaces)...)
+	diffs = append(diffs, diffIPs(ra, rb)...)
+	diffs = append(diffs, diffRoutes(ra, rb)...)
+	diffs = append(diffs, diffFields("dns", ra.DNS, rb.DNS)...)
+	diffs = append(diffs, diffFields("extensions", ra.Extensions, rb.Extensions)...)
+	return diffs, nil
+}
+
+func interfaceKey(intf map[string]json.RawMessage) string {
+	var name, sandbox string
+	_ = json.Unmarshal(intf["name"], &name)
+	_ = json.Unmarshal(intf["sandbox"], &sandbox)
+	if sandbox == "" {
+		return name
+	}
+	return name + "@" + sandbox
+}
+
+func interfaceName(interfaces []map[string]json.RawMessage, idx *int) string {
+	if idx == nil || *idx < 0 || *idx >= len(interfaces) {
+		return ""
+	}
+	return interfaceKey(interfaces[*idx])
+}
+
+func diffInterfaces(a, b []map[string]json.RawMessage) []Difference {
+	byKey := func(list []map[string]json.RawMessage) map[string]map[string]json.RawMessage {
+		m := make(map[string]map[string]json.RawMessage, len(list))
+		for _, intf := range list {
+			m[interfaceKey(intf)] = intf
+		}
+		return m
+	}
+	ma, mb := byKey(a), byKey(b)
+
+	var diffs []Difference
+	for _, key := range sortedKeys(ma, mb) {
+		field := fmt.Sprintf("interfaces[%s]", key)
+		ia, inA := ma[key]
+		ib, inB := mb[key]
+		switch {
+		case !inB:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, Field: field, Old: compact(ia)})
+		case !inA:
+			diffs = append(diffs, Difference{Kind: DiffAdded, Field: field, New: compact(ib)})
+		default:
+			diffs = append(diffs, diffFields(field, ia, ib)...)
+		}
+	}
+	return diffs
+}
+
+func diffIPs(ra, rb *diffResult) []Difference {
+	byAddr := func(list []diffIPConfig) map[string]diffIPConfig {
+		m := make(map[string]diffIPConfig, len(list))
+		for _, ipc := range list {
+			m[ipc.Address] = ipc
+		}
+		return m
+	}
+	ma, mb := byAddr(ra.IPs), byAddr(rb.IPs)
+
+	var diffs []Difference
+	for _, addr := range sortedKeys(ma, mb) {
+		field := fmt.Sprintf("ips[%s]", addr)
+		ia, inA := ma[addr]
+		ib, inB := mb[addr]
+		switch {
+		case !inB:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, Field: field, Old: compact(ia)})
+		case !inA:
+			diffs = append(diffs, Difference{Kind: DiffAdded, Field: field, New: compact(ib)})
+		default:
+			if !bytes.Equal(ia.Gateway, ib.Gateway) {
+				diffs = append(diffs, Difference{Kind: DiffChanged, Field: field + ".gateway", Old: string(ia.Gateway), New: string(ib.Gateway)})
+			}
+			// Compare interfaces by name, as indexes may shift
+			na, nb := interfaceName(ra.Interfaces, ia.Interface), interfaceName(rb.Interfaces, ib.Interface)
+			if na != nb {
+				diffs = append(diffs, Difference{Kind: DiffChanged, Field: field + ".interface", Old: na, New: nb})
+			}
+		}
+	}
+	return diffs
+}
+
+func diffRoutes(ra, rb *diffResult) []Difference {
+	byValue := func(r *diffResult) map[string]json.RawMessage {
+		m := make(map[string]json.RawMessage, len(r.Routes))
+		for _, route := range r.Routes {
+			m[routeKey(r.Interfaces, route)] = route
+		}
+		return m
+	}
+	ma, mb := byValue(ra), byValue(rb)
+
+	var diffs []Difference
+	for _, key := range sortedKeys(ma, mb) {
+		_, inA := ma[key]
+		_, inB := mb[key]
+		switch {
+		case !inB:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, Field: "routes", Old: key})
+		case !inA:
+			diffs = append(diffs, Difference{Kind: DiffAdded, Field: "routes", New: key})
+		}
+	}
+	return diffs
+}
+
+// routeKey returns the canonical JSON of a route, with the index of the
+// interface it refers to replaced by the interface's name, as indexes may
+// shift
+func routeKey(interfaces []map[string]json.RawMessage, route json.RawMessage) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(route, &fields); err != nil {
+		return compact(route)
+	}
+	if idx, ok := fields["interface"]; ok {
+		var i int
+		if err := json.Unmarshal(idx, &i); err == nil {
+			name, _ := json.Marshal(interfaceName(interfaces, &i))
+			fields["interface"] = name
+		}
+	}
+	return compact(fields)
+}
+
+// diffFields compares two JSON objects key by key
+func diffFields(prefix string, a, b map[string]json.RawMessage) []Difference {
+	var diffs []Difference
+	for _, key := range sortedKeys(a, b) {
+		field := prefix + "." + key
+		va, inA := a[key]
+		vb, inB := b[key]
+		switch {
+		case !inB:
+			diffs = append(diffs, Difference{Kind: DiffRemoved, Field: field, Old: compact(va)})
+		case !inA:
+			diffs = append(diffs, Difference{Kind: DiffAdded, Field: field, New: compact(vb)})
+		case compact(va) != compact(vb):
+			diffs = append(diffs, Difference{Kind: DiffChanged, Field: field, Old: compact(va), New: compact(vb)})
+		}
+	}
+	return diffs
+}
+
+// compact returns the canonical JSON of v, so that equivalent values
+// written differently compare equal
+func compact(v interface{}) string {
+	data, err := CanonicalJSON(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]struct{})
+	for _, m := range maps {
+		for k := range m {
+			seen[k] = struct{}{}
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
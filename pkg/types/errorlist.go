@@ -0,0 +1,128 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorList aggregates the errors of an operation which legitimately
+// fails several times, such as GC, the teardown of many attachments or a
+// rollback. It is an error when not empty: errors.Is and errors.As look
+// through each of its errors, and CodeOf returns the code of the first.
+//
+// Its JSON encoding is an error as defined by the spec, carrying the code,
+// message and details of the first error, with all the errors listed
+// under "errors", so that consumers unaware of the list still see a
+// well-formed error.
+type ErrorList struct {
+	Errors []*Error
+}
+
+// Append adds err to the list, unless it is nil. Errors which are not an
+// *Error are converted to one with the code of the first *Error in their
+// chain, or ErrInternal, and the lists in err are flattened into this one.
+func (l *ErrorList) Append(err error) {
+	if err == nil {
+		return
+	}
+	if list, ok := err.(*ErrorList); ok {
+		l.Errors = append(l.Errors, list.Errors...)
+		return
+	}
+	if e, ok := err.(*Error); ok {
+		l.Errors = append(l.Errors, e)
+		return
+	}
+	code, ok := CodeOf(err)
+	if !ok {
+		code = ErrInternal
+	}
+	l.Errors = append(l.Errors, &Error{Code: code, Msg: err.Error(), wrapped: err})
+}
+
+// ErrOrNil returns the list as an error, or nil if it is empty
+func (l *ErrorList) ErrOrNil() error {
+	if l == nil || len(l.Errors) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Error returns the messages of the errors on separate lines, like the
+// errors returned by errors.Join
+func (l *ErrorList) Error() string {
+	msgs := make([]string, 0, len(l.Errors))
+	for _, e := range l.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors of the list
+func (l *ErrorList) Unwrap() []error {
+	errs := make([]error, 0, len(l.Errors))
+	for _, e := range l.Errors {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+type errorListJSON struct {
+	Code    uint     `json:"code"`
+	Msg     string   `json:"msg"`
+	Details string   `json:"details,omitempty"`
+	Errors  []*Error `json:"errors,omitempty"`
+}
+
+func (l *ErrorList) MarshalJSON() ([]byte, error) {
+	if len(l.Errors) == 0 {
+		return nil, fmt.Errorf("cannot encode an empty error list")
+	}
+	first := l.Errors[0]
+	out := errorListJSON{
+		Code:    first.Code,
+		Msg:     first.Msg,
+		Details: first.Details,
+		Errors:  l.Errors,
+	}
+	if len(l.Errors) > 1 {
+		out.Msg = fmt.Sprintf("%s (and %d more errors)", first.Msg, len(l.Errors)-1)
+	}
+	return json.Marshal(&out)
+}
+
+// UnmarshalJSON decodes an encoded ErrorList, or a single spec error as a
+// list of one
+func (l *ErrorList) UnmarshalJSON(data []byte) error {
+	in := errorListJSON{}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	if len(in.Errors) > 0 {
+		l.Errors = in.Errors
+		return nil
+	}
+	l.Errors = []*Error{{Code: in.Code, Msg: in.Msg, Details: in.Details}}
+	return nil
+}
+
+// Print writes the JSON encoding of the list to stdout, for plugins
+// reporting several errors
+func (l *ErrorList) Print() error {
+	return prettyPrint(l)
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MinPluginErrorCode is the lowest error code available for plugin or
+// vendor specific errors; lower codes are reserved for well-known errors.
+const MinPluginErrorCode uint = 100
+
+// ErrorCode describes an error code for display by runtimes
+type ErrorCode struct {
+	Code        uint
+	Name        string
+	Description string
+}
+
+var wellKnownErrorCodes = map[uint]ErrorCode{
+	ErrUnknown:                     {ErrUnknown, "Unknown", "unknown error"},
+	ErrIncompatibleCNIVersion:      {ErrIncompatibleCNIVersion, "IncompatibleCNIVersion", "incompatible CNI version"},
+	ErrUnsupportedField:            {ErrUnsupportedField, "UnsupportedField", "unsupported field in network configuration"},
+	ErrUnknownContainer:            {ErrUnknownContainer, "UnknownContainer", "container unknown or does not exist"},
+	ErrInvalidEnvironmentVariables: {ErrInvalidEnvironmentVariables, "InvalidEnvironmentVariables", "invalid necessary environment variables"},
+	ErrIOFailure:                   {ErrIOFailure, "IOFailure", "I/O failure"},
+	ErrDecodingFailure:             {ErrDecodingFailure, "DecodingFailure", "failed to decode content"},
+	ErrInvalidNetworkConfig:        {ErrInvalidNetworkConfig, "InvalidNetworkConfig", "invalid network config"},
+	ErrInvalidNetNS:                {ErrInvalidNetNS, "InvalidNetNS", "invalid network namespace"},
+	ErrTryAgainLater:               {ErrTryAgainLater, "TryAgainLater", "transient condition, try again later"},
+	ErrPluginNotAvailable:          {ErrPluginNotAvailable, "PluginNotAvailable", "plugin is not available"},
+	ErrLimitedConnectivity:         {ErrLimitedConnectivity, "LimitedConnectivity", "plugin is not available and existing containers may have limited connectivity"},
+	ErrInternal:                    {ErrInternal, "Internal", "internal error"},
+}
+
+var (
+	pluginErrorCodesLock sync.RWMutex
+	pluginErrorCodes     = map[uint]ErrorCode{}
+)
+
+// RegisterErrorCode registers a plugin or vendor specific error code so
+// that runtimes can render a meaningful name and description for it. The
+// code must be at least MinPluginErrorCode and not already registered
+// with a different name.
+func RegisterErrorCode(code uint, name, description string) error {
+	if code < MinPluginErrorCode {
+		return fmt.Errorf("error code %d is reserved for well-known errors", code)
+	}
+	if _, ok := wellKnownErrorCodes[code]; ok {
+		return fmt.Errorf("error code %d is reserved for well-known errors", code)
+	}
+	if name == "" {
+		return fmt.Errorf("error code %d must have a name", code)
+	}
+
+	pluginErrorCodesLock.Lock()
+	defer pluginErrorCodesLock.Unlock()
+	if existing, ok := pluginErrorCodes[code]; ok && existing.Name != name {
+		return fmt.Errorf("error code %d is already registered as %q", code, existing.Name)
+	}
+	pluginErrorCodes[code] = ErrorCode{Code: code, Name: name, Description: description}
+	return nil
+}
+
+// LookupErrorCode returns the description of a well-known or registered
+// error code
+func LookupErrorCode(code uint) (ErrorCode, bool) {
+	if ec, ok := wellKnownErrorCodes[code]; ok {
+		return ec, true
+	}
+	pluginErrorCodesLock.RLock()
+	defer pluginErrorCodesLock.RUnlock()
+	ec, ok := pluginErrorCodes[code]
+	return ec, ok
+}
+
+// CodeName returns the name of the error's code, or the code number if
+// the code is neither well-known nor registered
+func (e *Error) CodeName() string {
+	if ec, ok := LookupErrorCode(e.Code); ok {
+		return ec.Name
+	}
+	return fmt.Sprintf("%d", e.Code)
+}
+
+// Unwrap returns the error wrapped by WrapError, if any
+func (e *Error) Unwrap() error {
+	return e.wrapped
+}
+
+// WrapError returns an Error with the given message which wraps err. If err
+// is or wraps an *Error, its code is preserved; otherwise code is used. The
+// wrapped error's message becomes the details of the new Error.
+func WrapError(err error, code uint, msg string) *Error {
+	if c, ok := CodeOf(err); ok {
+		code = c
+	}
+	return &Error{
+		Code:    code,
+		Msg:     msg,
+		Details: err.Error(),
+		wrapped: err,
+	}
+}
+
+// CodeOf returns the code of the first *Error in err's chain
+func CodeOf(err error) (uint, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code, true
+	}
+	return 0, false
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ValidAttachmentsKey is the network configuration key under which the
+// runtime passes the still-valid attachments to a GC request
+const ValidAttachmentsKey = "cni.dev/valid-attachments"
+
+// GCArgs are the arguments of a GC request
+type GCArgs struct {
+	ValidAttachments []GCAttachment `json:"cni.dev/valid-attachments,omitempty"`
+}
+
+// IsValid returns true if the attachment identified by containerID and
+// ifName is one of the valid attachments. A nil GCArgs has no valid
+// attachments.
+func (a *GCArgs) IsValid(containerID, ifName string) bool {
+	if a == nil {
+		return false
+	}
+	for _, va := range a.ValidAttachments {
+		if va.ContainerID == containerID && va.IfName == ifName {
+			return true
+		}
+	}
+	return false
+}
+
+// GCArgs returns the GC arguments passed in the network configuration
+func (n *NetConf) GCArgs() *GCArgs {
+	return &GCArgs{ValidAttachments: n.ValidAttachments}
+}
+
+// GCResponse is the optional response of a plugin to a GC request,
+// reporting the resources it deleted, so that runtimes can tell which
+// sandbox leaked what. Plugins which print nothing report nothing.
+type GCResponse struct {
+	CNIVersion string      `json:"cniVersion,omitempty"`
+	Deleted    []GCDeleted `json:"deleted,omitempty"`
+}
+
+// GCDeleted lists the resources a GC request deleted for one attachment
+// which was not valid
+type GCDeleted struct {
+	ContainerID string `json:"containerID"`
+	IfName      string `json:"ifname,omitempty"`
+	// Interfaces are the names of the deleted interfaces
+	Interfaces []string `json:"interfaces,omitempty"`
+	// IPs are the released addresses, with or without a prefix length
+	IPs []string `json:"ips,omitempty"`
+	// Other describes any other deleted resource, such as a firewall
+	// chain
+	Other []string `json:"other,omitempty"`
+}
+
+// Print writes the response to stdout
+func (r *GCResponse) Print() error {
+	return prettyPrint(r)
+}
+
+// ParseGCResponse parses what a plugin printed in response to GC. An
+// empty output is a nil response.
+func ParseGCResponse(stdout []byte) (*GCResponse, error) {
+	if len(bytes.TrimSpace(stdout)) == 0 {
+		return nil, nil
+	}
+	r := &GCResponse{}
+	if err := json.Unmarshal(stdout, r); err != nil {
+		return nil, fmt.Errorf("failed to parse GC response: %w", err)
+	}
+	for _, d := range r.Deleted {
+		if d.ContainerID == "" {
+			return nil, fmt.Errorf("GC response deletes resources without a containerID")
+		}
+	}
+	return r, nil
+}
+
+// NewPluginNotAvailableError returns the error a plugin should return from
+// STATUS when it cannot service ADD requests. If existing containers may
+// also have limited connectivity, ErrLimitedConnectivity is used.
+func NewPluginNotAvailableError(limitedConnectivity bool, msg, details string) *Error {
+	code := ErrPluginNotAvailable
+	if limitedConnectivity {
+		code = ErrLimitedConnectivity
+	}
+	return NewError(code, msg, details)
+}
+
+// IsPluginNotAvailable returns true if err carries one of the STATUS error
+// codes which report that the plugin cannot service ADD requests
+func IsPluginNotAvailable(err error) bool {
+	code, ok := CodeOf(err)
+	return ok && (code == ErrPluginNotAvailable || code == ErrLimitedConnectivity)
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hns carries the Host Networking Service identifiers of Windows
+// attachments in results, as the vendor extension ExtensionKey, instead of
+// in the Mac or Sandbox fields of their interfaces. As extensions are only
+// defined from spec version 1.2.0, so is this data: Set refuses results of
+// older versions, and runtimes see it only if they gate on
+// version.FeatureExtensions.
+//
+// A Windows plugin records the endpoint it created for an interface:
+//
+//	err := hns.Set(result, &hns.Info{Endpoints: []hns.Endpoint{{
+//		Interface: current.Int(0),
+//		ID:        endpoint.Id,
+//		NetworkID: network.Id,
+//	}}})
+//
+// and runtimes or chained plugins read it back with Get.
+package hns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+// ExtensionKey is the result extension under which Info is stored
+const ExtensionKey = "dev.cni.windows.hns"
+
+// NATPolicyType is the kind of an HNS NAT policy
+type NATPolicyType string
+
+const (
+	// NATPolicyOutBoundNAT masquerades traffic leaving the endpoint,
+	// except to the Exceptions
+	NATPolicyOutBoundNAT NATPolicyType = "OutBoundNAT"
+	// NATPolicyPortMapping forwards ExternalPort on the host to
+	// InternalPort of the endpoint
+	NATPolicyPortMapping NATPolicyType = "PortMapping"
+)
+
+// NATPolicy is a NAT policy applied to an HNS endpoint
+type NATPolicy struct {
+	Type NATPolicyType `json:"type"`
+
+	// VIP is the address OutBoundNAT translates to, if not the host's
+	VIP string `json:"vip,omitempty"`
+	// Exceptions are the CIDRs OutBoundNAT does not apply to
+	Exceptions []string `json:"exceptions,omitempty"`
+
+	// Protocol is "tcp" or "udp" for PortMapping
+	Protocol     string `json:"protocol,omitempty"`
+	InternalPort int    `json:"internalPort,omitempty"`
+	ExternalPort int    `json:"externalPort,omitempty"`
+}
+
+// Endpoint is the HNS endpoint of an interface of the result
+type Endpoint struct {
+	// Interface is the index of the interface in the result
+	Interface *int `json:"interface,omitempty"`
+	// ID is the GUID of the HNS endpoint
+	ID string `json:"id"`
+	// NetworkID is the GUID of the HNS network the endpoint is in
+	NetworkID string `json:"networkId,omitempty"`
+	// NATPolicies are the NAT policies applied to the endpoint
+	NATPolicies []NATPolicy `json:"natPolicies,omitempty"`
+}
+
+// Info is the HNS data of a result
+type Info struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// guidRegexp matches GUIDs as HNS formats them, optionally in braces
+var guidRegexp = regexp.MustCompile(`^\{?[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\}?$`)
+
+// IsGUID returns whether s is a GUID, such as the ID of an HNS endpoint
+func IsGUID(s string) bool {
+	if !guidRegexp.MatchString(s) {
+		return false
+	}
+	return strings.HasPrefix(s, "{") == strings.HasSuffix(s, "}")
+}
+
+// Validate checks the Info against the result it belongs to, returning
+// all problems found
+func (i *Info) Validate(result *current.Result) error {
+	var errs []error
+	seen := make(map[int]bool)
+	for n, ep := range i.Endpoints {
+		path := fmt.Sprintf("endpoints[%d]", n)
+		if !IsGUID(ep.ID) {
+			errs = append(errs, fmt.Errorf("%s: invalid endpoint ID %q", path, ep.ID))
+		}
+		if ep.NetworkID != "" && !IsGUID(ep.NetworkID) {
+			errs = append(errs, fmt.Errorf("%s: invalid network ID %q", path, ep.NetworkID))
+		}
+		if ep.Interface != nil {
+			idx := *ep.Interface
+			switch {
+			case idx < 0 || idx >= len(result.Interfaces):
+				errs = append(errs, fmt.Errorf("%s: interface index %d out of range", path, idx))
+			case seen[idx]:
+				errs = append(errs, fmt.Errorf("%s: interface %d already has an endpoint", path, idx))
+			}
+			seen[idx] = true
+		}
+		for m, policy := range ep.NATPolicies {
+			if err := policy.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s.natPolicies[%d]: %w", path, m, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *NATPolicy) validate() error {
+	switch p.Type {
+	case NATPolicyOutBoundNAT:
+		if p.VIP != "" && net.ParseIP(p.VIP) == nil {
+			return fmt.Errorf("invalid vip %q", p.VIP)
+		}
+		for _, exception := range p.Exceptions {
+			if _, _, err := net.ParseCIDR(exception); err != nil {
+				return fmt.Errorf("invalid exception %q", exception)
+			}
+		}
+	case NATPolicyPortMapping:
+		if p.Protocol != "tcp" && p.Protocol != "udp" {
+			return fmt.Errorf("invalid protocol %q", p.Protocol)
+		}
+		if p.InternalPort < 1 || p.InternalPort > 65535 {
+			return fmt.Errorf("invalid internal port %d", p.InternalPort)
+		}
+		if p.ExternalPort < 1 || p.ExternalPort > 65535 {
+			return fmt.Errorf("invalid external port %d", p.ExternalPort)
+		}
+	default:
+		return fmt.Errorf("unknown policy type %q", p.Type)
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint of the result's interface with the given
+// index, or nil if it has none
+func (i *Info) Endpoint(intf int) *Endpoint {
+	for n := range i.Endpoints {
+		if ep := &i.Endpoints[n]; ep.Interface != nil && *ep.Interface == intf {
+			return ep
+		}
+	}
+	return nil
+}
+
+// Set validates info and stores it in the result. It fails if the result's
+// version predates extensions, or if the result already has HNS data.
+func Set(result *current.Result, info *Info) error {
+	if ok, err := version.Supports(version.FeatureExtensions, result.CNIVersion); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("HNS data requires CNI version %s or later, result has %q",
+			version.MinVersion(version.FeatureExtensions), result.CNIVersion)
+	}
+	if err := info.Validate(result); err != nil {
+		return fmt.Errorf("invalid HNS data: %w", err)
+	}
+	return result.SetExtension(ExtensionKey, info)
+}
+
+// Get returns the HNS data of the result, or nil if it has none
+func Get(result *current.Result) (*Info, error) {
+	info := &Info{}
+	ok, err := result.GetExtension(ExtensionKey, info)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return info, nil
+}
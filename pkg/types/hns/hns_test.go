@@ -0,0 +1,134 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hns_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/hns"
+)
+
+const (
+	endpointID = "5b1e2b8a-3c1d-4e5f-8a9b-0c1d2e3f4a5b"
+	networkID  = "{0F8E7D6C-5B4A-3928-1706-F5E4D3C2B1A0}"
+)
+
+var _ = Describe("HNS result data", func() {
+	var (
+		result *current.Result
+		info   *hns.Info
+	)
+
+	BeforeEach(func() {
+		result = &current.Result{
+			CNIVersion: "1.2.0",
+			Interfaces: []*current.Interface{
+				{Name: "eth0", Sandbox: "c1"},
+				{Name: "eth1", Sandbox: "c1"},
+			},
+		}
+		info = &hns.Info{Endpoints: []hns.Endpoint{{
+			Interface: current.Int(1),
+			ID:        endpointID,
+			NetworkID: networkID,
+			NATPolicies: []hns.NATPolicy{
+				{Type: hns.NATPolicyOutBoundNAT, Exceptions: []string{"10.0.0.0/8"}},
+				{Type: hns.NATPolicyPortMapping, Protocol: "tcp", InternalPort: 80, ExternalPort: 8080},
+			},
+		}}}
+	})
+
+	It("round trips through the result's JSON", func() {
+		Expect(hns.Set(result, info)).To(Succeed())
+
+		data, err := json.Marshal(result)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring(`"dev.cni.windows.hns"`))
+		decoded := &current.Result{}
+		Expect(json.Unmarshal(data, decoded)).To(Succeed())
+
+		got, err := hns.Get(decoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(Equal(info))
+		Expect(got.Endpoint(1).ID).To(Equal(endpointID))
+		Expect(got.Endpoint(0)).To(BeNil())
+		Expect(decoded.Interfaces[1].Mac).To(BeEmpty())
+	})
+
+	It("returns nil for results without HNS data", func() {
+		got, err := hns.Get(result)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got).To(BeNil())
+	})
+
+	It("fails to decode malformed data", func() {
+		result.Extensions = map[string]json.RawMessage{hns.ExtensionKey: json.RawMessage(`[]`)}
+		_, err := hns.Get(result)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("refuses results of versions without extensions", func() {
+		result.CNIVersion = "1.1.0"
+		Expect(hns.Set(result, info)).To(MatchError(`HNS data requires CNI version 1.2.0 or later, result has "1.1.0"`))
+		Expect(result.Extensions).To(BeEmpty())
+	})
+
+	It("refuses to overwrite HNS data", func() {
+		Expect(hns.Set(result, info)).To(Succeed())
+		Expect(hns.Set(result, info)).To(MatchError(`extension "dev.cni.windows.hns" is already set`))
+	})
+
+	It("validates the data against the result", func() {
+		info.Endpoints = append(info.Endpoints,
+			hns.Endpoint{Interface: current.Int(1), ID: "not-a-guid", NetworkID: "{" + endpointID},
+			hns.Endpoint{Interface: current.Int(2), ID: endpointID, NATPolicies: []hns.NATPolicy{
+				{Type: hns.NATPolicyOutBoundNAT, VIP: "nope"},
+				{Type: hns.NATPolicyOutBoundNAT, Exceptions: []string{"10.0.0.0"}},
+				{Type: hns.NATPolicyPortMapping, Protocol: "sctp", InternalPort: 80, ExternalPort: 80},
+				{Type: hns.NATPolicyPortMapping, Protocol: "udp", ExternalPort: 80},
+				{Type: hns.NATPolicyPortMapping, Protocol: "udp", InternalPort: 80, ExternalPort: 70000},
+				{Type: "L4Proxy"},
+			}},
+		)
+		err := hns.Set(result, info)
+		Expect(err).To(HaveOccurred())
+		for _, msg := range []string{
+			`endpoints[1]: invalid endpoint ID "not-a-guid"`,
+			`endpoints[1]: invalid network ID "{` + endpointID + `"`,
+			`endpoints[1]: interface 1 already has an endpoint`,
+			`endpoints[2]: interface index 2 out of range`,
+			`endpoints[2].natPolicies[0]: invalid vip "nope"`,
+			`endpoints[2].natPolicies[1]: invalid exception "10.0.0.0"`,
+			`endpoints[2].natPolicies[2]: invalid protocol "sctp"`,
+			`endpoints[2].natPolicies[3]: invalid internal port 0`,
+			`endpoints[2].natPolicies[4]: invalid external port 70000`,
+			`endpoints[2].natPolicies[5]: unknown policy type "L4Proxy"`,
+		} {
+			Expect(err.Error()).To(ContainSubstring(msg))
+		}
+		Expect(result.Extensions).To(BeEmpty())
+	})
+
+	It("recognizes GUIDs", func() {
+		Expect(hns.IsGUID(endpointID)).To(BeTrue())
+		Expect(hns.IsGUID(networkID)).To(BeTrue())
+		Expect(hns.IsGUID("{" + endpointID)).To(BeFalse())
+		Expect(hns.IsGUID("00:11:22:33:44:55")).To(BeFalse())
+	})
+})
@@ -0,0 +1,106 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ipam provides Go types for the "ipam" section of the network
+// configuration used by the reference IPAM plugins, and helpers for
+// meta-plugins that need to read or rewrite that section.
+package ipam
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+)
+
+// HostLocal is the configuration of the host-local IPAM plugin
+type HostLocal struct {
+	Type       string                    `json:"type"`
+	Ranges     []capabilities.IPRangeSet `json:"ranges,omitempty"`
+	Routes     []*types.Route            `json:"routes,omitempty"`
+	ResolvConf string                    `json:"resolvConf,omitempty"`
+	DataDir    string                    `json:"dataDir,omitempty"`
+}
+
+// DHCP is the configuration of the dhcp IPAM plugin
+type DHCP struct {
+	Type             string `json:"type"`
+	DaemonSocketPath string `json:"daemonSocketPath,omitempty"`
+}
+
+// Remote is the configuration of an IPAM plugin which delegates
+// allocation to one of a set of remote endpoints
+type Remote struct {
+	Type      string   `json:"type"`
+	Endpoints []string `json:"endpoints"`
+}
+
+// Type returns the IPAM plugin type named by the given network
+// configuration JSON, or an empty string if it has no ipam section
+func Type(stdinData []byte) (string, error) {
+	conf := struct {
+		IPAM types.IPAM `json:"ipam"`
+	}{}
+	if err := json.Unmarshal(stdinData, &conf); err != nil {
+		return "", fmt.Errorf("failed to parse network configuration: %w", err)
+	}
+	return conf.IPAM.Type, nil
+}
+
+// FromNetConf decodes the ipam section of the given network configuration
+// JSON into v, which is typically a *HostLocal, *DHCP or *Remote. It is an
+// error for the configuration to have no ipam section.
+func FromNetConf(stdinData []byte, v interface{}) error {
+	raw, err := rawSections(stdinData)
+	if err != nil {
+		return err
+	}
+	section, ok := raw["ipam"]
+	if !ok {
+		return fmt.Errorf("network configuration has no ipam section")
+	}
+	if err := json.Unmarshal(section, v); err != nil {
+		return fmt.Errorf("failed to parse ipam section: %w", err)
+	}
+	return nil
+}
+
+// ReplaceInNetConf returns a copy of the given network configuration JSON
+// with its ipam section replaced by the encoding of v. All other keys are
+// kept as-is. A nil v removes the ipam section.
+func ReplaceInNetConf(stdinData []byte, v interface{}) ([]byte, error) {
+	raw, err := rawSections(stdinData)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		delete(raw, "ipam")
+	} else {
+		section, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode ipam section: %w", err)
+		}
+		raw["ipam"] = section
+	}
+	return json.Marshal(raw)
+}
+
+func rawSections(stdinData []byte) (map[string]json.RawMessage, error) {
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(stdinData, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse network configuration: %w", err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ipam_test
+
+import (
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types/ipam"
+)
+
+var _ = Describe("IPAM configuration", func() {
+	const hostLocalConf = `{
+		"cniVersion": "1.0.0",
+		"name": "mynet",
+		"type": "bridge",
+		"bridge": "cni0",
+		"ipam": {
+			"type": "host-local",
+			"ranges": [[{"subnet": "10.1.2.0/24", "rangeStart": "10.1.2.10", "gateway": "10.1.2.1"}]],
+			"routes": [{"dst": "0.0.0.0/0"}],
+			"resolvConf": "/etc/resolv.conf",
+			"dataDir": "/run/cni/ipam"
+		}
+	}`
+
+	It("reports the IPAM plugin type", func() {
+		t, err := ipam.Type([]byte(hostLocalConf))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(Equal("host-local"))
+
+		t, err = ipam.Type([]byte(`{"name": "mynet", "type": "bridge"}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(BeEmpty())
+	})
+
+	It("decodes a host-local configuration", func() {
+		conf := &ipam.HostLocal{}
+		Expect(ipam.FromNetConf([]byte(hostLocalConf), conf)).To(Succeed())
+
+		Expect(conf.Type).To(Equal("host-local"))
+		Expect(conf.Ranges).To(HaveLen(1))
+		Expect(conf.Ranges[0][0].Subnet.IP.String()).To(Equal("10.1.2.0"))
+		Expect(conf.Ranges[0][0].RangeStart).To(Equal(net.ParseIP("10.1.2.10")))
+		Expect(conf.Routes).To(HaveLen(1))
+		Expect(conf.Routes[0].Dst.String()).To(Equal("0.0.0.0/0"))
+		Expect(conf.ResolvConf).To(Equal("/etc/resolv.conf"))
+		Expect(conf.DataDir).To(Equal("/run/cni/ipam"))
+	})
+
+	It("decodes dhcp and remote configurations", func() {
+		dhcp := &ipam.DHCP{}
+		Expect(ipam.FromNetConf([]byte(`{"ipam": {"type": "dhcp", "daemonSocketPath": "/run/cni/dhcp.sock"}}`), dhcp)).To(Succeed())
+		Expect(dhcp).To(Equal(&ipam.DHCP{Type: "dhcp", DaemonSocketPath: "/run/cni/dhcp.sock"}))
+
+		remote := &ipam.Remote{}
+		Expect(ipam.FromNetConf([]byte(`{"ipam": {"type": "whereabouts", "endpoints": ["https://10.0.0.1:2379"]}}`), remote)).To(Succeed())
+		Expect(remote).To(Equal(&ipam.Remote{Type: "whereabouts", Endpoints: []string{"https://10.0.0.1:2379"}}))
+	})
+
+	It("fails when there is no ipam section", func() {
+		err := ipam.FromNetConf([]byte(`{"name": "mynet", "type": "bridge"}`), &ipam.HostLocal{})
+		Expect(err).To(MatchError("network configuration has no ipam section"))
+	})
+
+	It("replaces the ipam section and keeps other keys", func() {
+		out, err := ipam.ReplaceInNetConf([]byte(hostLocalConf), &ipam.DHCP{Type: "dhcp"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`{
+			"cniVersion": "1.0.0",
+			"name": "mynet",
+			"type": "bridge",
+			"bridge": "cni0",
+			"ipam": {"type": "dhcp"}
+		}`))
+	})
+
+	It("removes the ipam section when replaced with nil", func() {
+		out, err := ipam.ReplaceInNetConf([]byte(hostLocalConf), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(MatchJSON(`{"cniVersion": "1.0.0", "name": "mynet", "type": "bridge", "bridge": "cni0"}`))
+	})
+})
@@ -0,0 +1,185 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces the values of sensitive fields redacted by Redact
+const RedactedValue = "[REDACTED]"
+
+// defaultSensitiveNames end the names of the fields redacted without
+// registration, in any case, e.g. "password", "Password" or "apiToken"
+var defaultSensitiveNames = []string{"password", "token", "secret"}
+
+var (
+	sensitiveFieldsLock sync.RWMutex
+	sensitiveFields     = map[string][]string{}
+)
+
+// isDefaultSensitive tells whether the key names a field redacted without
+// registration
+func isDefaultSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, name := range defaultSensitiveNames {
+		if strings.HasSuffix(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterSensitiveField registers a field of network configurations
+// whose value Redact masks, such as the credentials of a vendor IPAM
+// section. The path is a dot-separated list of keys from the top of a
+// plugin configuration, where "*" matches any one key and "**" any number
+// of keys, e.g. "ipam.auth.password" or "**.apiKey". Arrays are traversed
+// transparently. Fields whose name ends with password, token or secret,
+// in any case, are redacted at any depth without registration.
+func RegisterSensitiveField(path string) error {
+	segments, err := parseSensitiveField(path)
+	if err != nil {
+		return err
+	}
+	sensitiveFieldsLock.Lock()
+	defer sensitiveFieldsLock.Unlock()
+	sensitiveFields[path] = segments
+	return nil
+}
+
+func parseSensitiveField(path string) ([]string, error) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, fmt.Errorf("invalid sensitive field %q: empty key", path)
+		}
+	}
+	if segments[len(segments)-1] == "**" {
+		return nil, fmt.Errorf("invalid sensitive field %q: must end with a key", path)
+	}
+	return segments, nil
+}
+
+// Redact returns the JSON encoding of a network configuration or
+// configuration list with the values of the registered sensitive fields
+// replaced by RedactedValue, for logging. The configuration may be given
+// as its JSON bytes, which keeps the plugin-specific fields a NetConf
+// does not hold. The fields of a list are matched both from the top of
+// the list and from the top of each of its plugins.
+func Redact(conf interface{}) ([]byte, error) {
+	var data []byte
+	switch conf := conf.(type) {
+	case json.RawMessage:
+		data = conf
+	case []byte:
+		data = conf
+	default:
+		var err error
+		if data, err = json.Marshal(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	value = redactDefaults(value)
+	sensitiveFieldsLock.RLock()
+	for _, segments := range sensitiveFields {
+		value = redactField(value, segments)
+		if obj, ok := value.(map[string]interface{}); ok {
+			if plugins, ok := obj["plugins"].([]interface{}); ok {
+				for i, plugin := range plugins {
+					plugins[i] = redactField(plugin, segments)
+				}
+			}
+		}
+	}
+	sensitiveFieldsLock.RUnlock()
+
+	return json.Marshal(value)
+}
+
+// redactDefaults masks the values of the fields redacted without
+// registration within value
+func redactDefaults(value interface{}) interface{} {
+	switch value := value.(type) {
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactDefaults(elem)
+		}
+	case map[string]interface{}:
+		for key, child := range value {
+			if isDefaultSensitive(key) {
+				value[key] = RedactedValue
+			} else {
+				value[key] = redactDefaults(child)
+			}
+		}
+	}
+	return value
+}
+
+// redactField masks the values at the path of segments within value
+func redactField(value interface{}, segments []string) interface{} {
+	if len(segments) == 0 {
+		return RedactedValue
+	}
+	switch value := value.(type) {
+	case []interface{}:
+		for i, elem := range value {
+			value[i] = redactField(elem, segments)
+		}
+	case map[string]interface{}:
+		segment := segments[0]
+		if segment == "**" {
+			// "**" matching no key, then one key or more
+			redacted := redactField(value, segments[1:]).(map[string]interface{})
+			for key, child := range redacted {
+				redacted[key] = redactField(child, segments)
+			}
+			return redacted
+		}
+		for key, child := range value {
+			if segment == "*" || segment == key {
+				value[key] = redactField(child, segments[1:])
+			}
+		}
+	}
+	return value
+}
+
+// RedactedConfig logs a network configuration or configuration list,
+// given as JSON, with its sensitive fields redacted
+type RedactedConfig []byte
+
+// LogValue implements slog.LogValuer
+func (c RedactedConfig) LogValue() slog.Value {
+	redacted, err := Redact([]byte(c))
+	if err != nil {
+		return slog.StringValue(fmt.Sprintf("<unloggable configuration: %v>", err))
+	}
+	return slog.StringValue(string(redacted))
+}
@@ -0,0 +1,313 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// TagName is the struct tag holding the constraints of the fields of the
+// structs written by GenerateGo, which ValidateStruct checks
+const TagName = "schema"
+
+// GoOptions are the options of GenerateGo
+type GoOptions struct {
+	// Package is the name of the package of the generated file
+	Package string
+	// TypeName is the name of the struct generated for the top of the
+	// schema; nested objects are named after it and their field
+	TypeName string
+	// EmbedNetConf embeds types.NetConf in the top struct in place of the
+	// standard configuration keys, such as cniVersion, name and type
+	EmbedNetConf bool
+	// Generator names the program in the "Code generated" header
+	Generator string
+}
+
+// netConfKeys are the configuration keys held by types.NetConf
+var netConfKeys = map[string]bool{
+	"cniVersion":                true,
+	"name":                      true,
+	"type":                      true,
+	"capabilities":              true,
+	"ipam":                      true,
+	"dns":                       true,
+	"prevResult":                true,
+	"cni.dev/valid-attachments": true,
+}
+
+// initialisms are written in upper case in Go field names
+var initialisms = map[string]string{
+	"api":  "API",
+	"cidr": "CIDR",
+	"cni":  "CNI",
+	"dns":  "DNS",
+	"id":   "ID",
+	"ip":   "IP",
+	"ipam": "IPAM",
+	"ips":  "IPs",
+	"mac":  "MAC",
+	"mtu":  "MTU",
+	"url":  "URL",
+	"vlan": "VLAN",
+}
+
+// GenerateGo returns the source of a Go file declaring structs for the
+// JSON described by s, typically the schema of a plugin's configuration.
+// Fields are tagged with their JSON key and, under TagName, with their
+// constraints: "required" for required keys, which ValidateStruct checks
+// are not zero, and "minimum=N" for bounded integers. Values of any type
+// are decoded as json.RawMessage, so that nothing is lost.
+func GenerateGo(s *Schema, opts GoOptions) ([]byte, error) {
+	if s == nil || s.Type != "object" || s.Properties == nil {
+		return nil, fmt.Errorf("schema must describe an object with properties")
+	}
+	if opts.Package == "" || opts.TypeName == "" {
+		return nil, fmt.Errorf("package and type names are required")
+	}
+	if opts.Generator == "" {
+		opts.Generator = "cni-schemagen"
+	}
+
+	g := &goGen{opts: opts, imports: map[string]bool{}, names: map[string]bool{}}
+	if _, err := g.structType(opts.TypeName, s, opts.EmbedNetConf); err != nil {
+		return nil, err
+	}
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "// Code generated by %s. DO NOT EDIT.\n\npackage %s\n\n", opts.Generator, opts.Package)
+	if len(g.imports) > 0 {
+		// Standard library imports first, as goimports groups them
+		var std, others []string
+		for imp := range g.imports {
+			if first, _, _ := strings.Cut(imp, "/"); strings.Contains(first, ".") {
+				others = append(others, imp)
+			} else {
+				std = append(std, imp)
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(others)
+		out.WriteString("import (\n")
+		for _, imp := range std {
+			fmt.Fprintf(out, "\t%q\n", imp)
+		}
+		if len(std) > 0 && len(others) > 0 {
+			out.WriteString("\n")
+		}
+		for _, imp := range others {
+			fmt.Fprintf(out, "\t%q\n", imp)
+		}
+		out.WriteString(")\n\n")
+	}
+	for _, decl := range g.decls {
+		out.WriteString(decl)
+	}
+	return format.Source(out.Bytes())
+}
+
+type goGen struct {
+	opts    GoOptions
+	imports map[string]bool
+	// names are the type names declared so far
+	names map[string]bool
+	decls []string
+}
+
+// structType declares a struct for the object schema s, named name or
+// the first free variant of it, and returns its name
+func (g *goGen) structType(name string, s *Schema, embedNetConf bool) (string, error) {
+	name = g.freeName(name)
+	// Declare the struct before those of its nested objects
+	slot := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	keys := make([]string, 0, len(s.Properties))
+	for key := range s.Properties {
+		if embedNetConf && netConfKeys[key] {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	required := make(map[string]bool, len(s.Required))
+	for _, key := range s.Required {
+		required[key] = true
+	}
+
+	body := &bytes.Buffer{}
+	if embedNetConf {
+		g.imports["github.com/containernetworking/cni/pkg/types"] = true
+		body.WriteString("\ttypes.NetConf\n\n")
+	}
+	fields := map[string]bool{}
+	for _, key := range keys {
+		prop := s.Properties[key]
+		field := fieldName(key)
+		for i := 2; fields[field]; i++ {
+			field = fmt.Sprintf("%s%d", fieldName(key), i)
+		}
+		fields[field] = true
+
+		typ, err := g.goType(name+field, prop)
+		if err != nil {
+			return "", fmt.Errorf("property %q: %w", key, err)
+		}
+		if !required[key] && prop.Type == "object" && prop.Properties != nil {
+			typ = "*" + typ
+		}
+
+		jsonTag := key
+		var constraints []string
+		if required[key] {
+			constraints = append(constraints, "required")
+		} else {
+			jsonTag += ",omitempty"
+		}
+		if prop.Minimum != nil {
+			constraints = append(constraints, fmt.Sprintf("minimum=%d", *prop.Minimum))
+		}
+		tag := fmt.Sprintf("json:%q", jsonTag)
+		if len(constraints) > 0 {
+			tag += fmt.Sprintf(" %s:%q", TagName, strings.Join(constraints, ","))
+		}
+
+		if prop.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(prop.Description), "\n") {
+				fmt.Fprintf(body, "\t// %s\n", line)
+			}
+		}
+		fmt.Fprintf(body, "\t%s %s `%s`\n", field, typ, tag)
+	}
+
+	decl := &bytes.Buffer{}
+	description := s.Description
+	if description == "" {
+		description = s.Title
+	}
+	if description != "" {
+		fmt.Fprintf(decl, "// %s %s\n", name, strings.TrimSpace(description))
+	}
+	fmt.Fprintf(decl, "type %s struct {\n%s}\n\n", name, body)
+	g.decls[slot] = decl.String()
+	return name, nil
+}
+
+// goType returns the Go type of values described by s, declaring a struct
+// named after name for objects with properties
+func (g *goGen) goType(name string, s *Schema) (string, error) {
+	switch s.Type {
+	case "boolean":
+		return "bool", nil
+	case "integer":
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "string":
+		switch s.Format {
+		case "cidr":
+			g.imports["github.com/containernetworking/cni/pkg/types"] = true
+			return "types.IPNet", nil
+		case "ip":
+			g.imports["net"] = true
+			return "net.IP", nil
+		}
+		return "string", nil
+	case "array":
+		if s.Items == nil {
+			g.imports["encoding/json"] = true
+			return "[]json.RawMessage", nil
+		}
+		elem, err := g.goType(name+"Item", s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	case "object":
+		switch {
+		case s.Properties != nil:
+			return g.structType(name, s, false)
+		case s.AdditionalProperties != nil:
+			elem, err := g.goType(name+"Value", s.AdditionalProperties)
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + elem, nil
+		}
+		g.imports["encoding/json"] = true
+		return "map[string]json.RawMessage", nil
+	case "":
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	return "", fmt.Errorf("unsupported type %q", s.Type)
+}
+
+func (g *goGen) freeName(name string) string {
+	free := name
+	for i := 2; g.names[free]; i++ {
+		free = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[free] = true
+	return free
+}
+
+// fieldName returns the exported Go identifier for a JSON key, splitting
+// it at punctuation and lower-to-upper case changes, e.g. "cniVersion"
+// becomes CNIVersion and "vlan-id" VLANID
+func fieldName(key string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	var prev rune
+	for _, r := range key {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	flush()
+
+	name := &strings.Builder{}
+	for _, w := range words {
+		if initialism, ok := initialisms[strings.ToLower(w)]; ok {
+			name.WriteString(initialism)
+			continue
+		}
+		runes := []rune(w)
+		name.WriteRune(unicode.ToUpper(runes[0]))
+		name.WriteString(string(runes[1:]))
+	}
+	if name.Len() == 0 || !unicode.IsLetter([]rune(name.String())[0]) {
+		return "X" + name.String()
+	}
+	return name.String()
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"net"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types"
+	"github.com/containernetworking/cni/pkg/types/schema"
+)
+
+const bridgeSchema = `{
+	"type": "object",
+	"description": "is the configuration of the bridge plugin",
+	"properties": {
+		"cniVersion": {"type": "string"},
+		"name": {"type": "string"},
+		"type": {"type": "string"},
+		"ipam": {"type": "object", "properties": {"type": {"type": "string"}}},
+		"bridge": {"type": "string", "description": "Name of the bridge"},
+		"mtu": {"type": "integer", "minimum": 0},
+		"vlan-id": {"type": "integer", "minimum": 1},
+		"gateway": {"type": "string", "format": "ip"},
+		"subnets": {"type": "array", "items": {"type": "string", "format": "cidr"}},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"vendor": {},
+		"uplink": {
+			"type": "object",
+			"properties": {"device": {"type": "string"}, "trunk": {"type": "boolean"}},
+			"required": ["device"]
+		}
+	},
+	"required": ["bridge", "uplink"]
+}`
+
+var _ = Describe("Go generation", func() {
+	var s *schema.Schema
+
+	BeforeEach(func() {
+		s = &schema.Schema{}
+		Expect(json.Unmarshal([]byte(bridgeSchema), s)).To(Succeed())
+	})
+
+	It("writes structs with JSON and constraint tags", func() {
+		src, err := schema.GenerateGo(s, schema.GoOptions{Package: "bridgeconf", TypeName: "NetConf", EmbedNetConf: true})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = parser.ParseFile(token.NewFileSet(), "types.go", src, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(src)).To(HavePrefix("// Code generated by cni-schemagen. DO NOT EDIT.\n\npackage bridgeconf\n"))
+		Expect(string(src)).To(ContainSubstring(`import (
+	"encoding/json"
+	"net"
+
+	"github.com/containernetworking/cni/pkg/types"
+)`))
+		Expect(string(src)).To(ContainSubstring(`// NetConf is the configuration of the bridge plugin
+type NetConf struct {
+	types.NetConf
+
+	// Name of the bridge
+	Bridge  string            ` + "`" + `json:"bridge" schema:"required"` + "`" + `
+	Gateway net.IP            ` + "`" + `json:"gateway,omitempty"` + "`" + `
+	Labels  map[string]string ` + "`" + `json:"labels,omitempty"` + "`" + `
+	MTU     int               ` + "`" + `json:"mtu,omitempty" schema:"minimum=0"` + "`" + `
+	Subnets []types.IPNet     ` + "`" + `json:"subnets,omitempty"` + "`" + `
+	Uplink  NetConfUplink     ` + "`" + `json:"uplink" schema:"required"` + "`" + `
+	Vendor  json.RawMessage   ` + "`" + `json:"vendor,omitempty"` + "`" + `
+	VLANID  int               ` + "`" + `json:"vlan-id,omitempty" schema:"minimum=1"` + "`" + `
+}`))
+		Expect(string(src)).To(ContainSubstring(`type NetConfUplink struct {
+	Device string ` + "`" + `json:"device" schema:"required"` + "`" + `
+	Trunk  bool   ` + "`" + `json:"trunk,omitempty"` + "`" + `
+}`))
+		Expect(string(src)).NotTo(ContainSubstring("CNIVersion"))
+	})
+
+	It("writes the standard keys unless embedding types.NetConf", func() {
+		src, err := schema.GenerateGo(s, schema.GoOptions{Package: "bridgeconf", TypeName: "Config"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(src)).To(ContainSubstring("CNIVersion string"))
+		Expect(string(src)).To(ContainSubstring("IPAM       *ConfigIPAM"))
+		Expect(string(src)).NotTo(ContainSubstring("types.NetConf"))
+	})
+
+	It("rejects schemas which are not objects", func() {
+		_, err := schema.GenerateGo(&schema.Schema{Type: "string"}, schema.GoOptions{Package: "p", TypeName: "T"})
+		Expect(err).To(MatchError("schema must describe an object with properties"))
+	})
+})
+
+type uplink struct {
+	Device string `json:"device" schema:"required"`
+	VLAN   int    `json:"vlan,omitempty" schema:"minimum=1"`
+}
+
+type bridgeConf struct {
+	types.NetConf
+
+	Bridge  string            `json:"bridge" schema:"required"`
+	Gateway net.IP            `json:"gateway,omitempty"`
+	MTU     int               `json:"mtu,omitempty" schema:"minimum=0"`
+	Uplinks []uplink          `json:"uplinks,omitempty"`
+	Peers   map[string]uplink `json:"peers,omitempty"`
+}
+
+var _ = Describe("Struct validation", func() {
+	It("accepts values meeting their constraints", func() {
+		conf := &bridgeConf{}
+		Expect(json.Unmarshal([]byte(`{
+			"cniVersion": "1.0.0", "name": "mynet", "type": "bridge",
+			"bridge": "cni0", "gateway": "10.0.0.1", "uplinks": [{"device": "eth0", "vlan": 100}]
+		}`), conf)).To(Succeed())
+		Expect(schema.ValidateStruct(conf)).To(Succeed())
+	})
+
+	It("reports every violated constraint by JSON path", func() {
+		conf := &bridgeConf{}
+		Expect(json.Unmarshal([]byte(`{
+			"mtu": -1,
+			"uplinks": [{"device": "eth0"}, {"vlan": -1}],
+			"peers": {"a": {"device": "eth1", "vlan": -5}}
+		}`), conf)).To(Succeed())
+		Expect(schema.ValidateStruct(conf)).To(MatchError(
+			"bridge: required\n" +
+				"mtu: -1 is less than the minimum 0\n" +
+				"uplinks[1].device: required\n" +
+				"uplinks[1].vlan: -1 is less than the minimum 1\n" +
+				"peers[a].vlan: -5 is less than the minimum 1"))
+	})
+
+	It("only validates structs", func() {
+		Expect(schema.ValidateStruct("nope")).To(MatchError("cannot validate string: not a struct"))
+		var conf *bridgeConf
+		Expect(schema.ValidateStruct(conf)).To(MatchError("cannot validate nil"))
+	})
+})
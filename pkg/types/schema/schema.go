@@ -0,0 +1,272 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package schema generates JSON Schema documents from the CNI Go types, so
+// that external validators and editors can check configurations and
+// results against the real definitions.
+package schema
+
+//go:generate go run gen.go -out ../../../Documentation/schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types"
+	types020 "github.com/containernetworking/cni/pkg/types/020"
+	types040 "github.com/containernetworking/cni/pkg/types/040"
+	types100 "github.com/containernetworking/cni/pkg/types/100"
+)
+
+// Draft is the JSON Schema dialect emitted by this package
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a subset of a JSON Schema document
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Minimum     *int               `json:"minimum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	// AdditionalProperties is only set for map types
+	AdditionalProperties *Schema `json:"additionalProperties,omitempty"`
+}
+
+// JSON shapes of types which implement their own (un)marshalling
+type route struct {
+	Dst      types.IPNet `json:"dst"`
+	GW       net.IP      `json:"gw,omitempty"`
+	MTU      int         `json:"mtu,omitempty"`
+	AdvMSS   int         `json:"advmss,omitempty"`
+	Priority int         `json:"priority,omitempty"`
+	Table    *int        `json:"table,omitempty"`
+	Scope    *int        `json:"scope,omitempty"`
+
+	Interface *int `json:"interface,omitempty"`
+}
+
+type ipConfig020 struct {
+	IP      types.IPNet   `json:"ip"`
+	Gateway net.IP        `json:"gateway,omitempty"`
+	Routes  []types.Route `json:"routes,omitempty"`
+}
+
+type ipConfig040 struct {
+	Version   string      `json:"version"`
+	Interface *int        `json:"interface,omitempty"`
+	Address   types.IPNet `json:"address"`
+	Gateway   net.IP      `json:"gateway,omitempty"`
+}
+
+type ipConfig100 struct {
+	Interface *int        `json:"interface,omitempty"`
+	Address   types.IPNet `json:"address"`
+	Gateway   net.IP      `json:"gateway,omitempty"`
+}
+
+var (
+	cidrSchema = Schema{Type: "string", Format: "cidr"}
+	ipSchema   = Schema{Type: "string", Format: "ip"}
+	anySchema  = Schema{}
+
+	// overrides maps types with custom JSON encodings to either a fixed
+	// schema or a struct describing their JSON shape
+	overrides = map[reflect.Type]interface{}{
+		reflect.TypeOf(types.IPNet{}):       cidrSchema,
+		reflect.TypeOf(net.IPNet{}):         cidrSchema,
+		reflect.TypeOf(net.IP{}):            ipSchema,
+		reflect.TypeOf(json.RawMessage{}):   anySchema,
+		reflect.TypeOf(types.Route{}):       route{},
+		reflect.TypeOf(types020.IPConfig{}): ipConfig020{},
+		reflect.TypeOf(types040.IPConfig{}): ipConfig040{},
+		reflect.TypeOf(types100.IPConfig{}): ipConfig100{},
+	}
+)
+
+// Generate returns the JSON Schema describing the JSON encoding of v
+func Generate(v interface{}) (*Schema, error) {
+	if v == nil {
+		return nil, fmt.Errorf("cannot generate schema for nil")
+	}
+	s, err := forType(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	s.Schema = Draft
+	return s, nil
+}
+
+func forType(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if o, ok := overrides[t]; ok {
+		if s, ok := o.(Schema); ok {
+			return &s, nil
+		}
+		t = reflect.TypeOf(o)
+	}
+
+	zero := 0
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings
+			return &Schema{Type: "string"}, nil
+		}
+		items, err := forType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := forType(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return forStruct(t)
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+func forStruct(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Untagged embedded structs have their fields promoted
+		if field.Anonymous && name == "" {
+			embedded, err := forType(field.Type)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fs, err := forType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s.%s: %w", t.Name(), field.Name, err)
+		}
+		s.Properties[name] = fs
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s, nil
+}
+
+// Documents returns the schemas for the core CNI types, keyed by a short
+// document name suitable for use as a file name
+func Documents() (map[string]*Schema, error) {
+	docs := map[string]struct {
+		title string
+		v     interface{}
+	}{
+		"netconf":     {"CNI network configuration", types.NetConf{}},
+		"netconflist": {"CNI network configuration list", types.NetConfList{}},
+		"gc":          {"CNI GC request", types.NetConf{}},
+		"status":      {"CNI STATUS request", types.NetConf{}},
+		"result-" + types020.ImplementedSpecVersion: {"CNI " + types020.ImplementedSpecVersion + " result", types020.Result{}},
+		"result-" + types040.ImplementedSpecVersion: {"CNI " + types040.ImplementedSpecVersion + " result", types040.Result{}},
+		"result-" + types100.ImplementedSpecVersion: {"CNI " + types100.ImplementedSpecVersion + " result", types100.Result{}},
+		"result-1.2.0": {"CNI 1.2.0 result", types100.Result{}},
+	}
+
+	out := make(map[string]*Schema, len(docs))
+	for name, doc := range docs {
+		s, err := Generate(doc.v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate schema %q: %w", name, err)
+		}
+		s.Title = doc.title
+		out[name] = s
+	}
+
+	// Extensions, route interfaces and interface annotations are only
+	// serialized from 1.2.0 onwards
+	delete(out["result-"+types100.ImplementedSpecVersion].Properties, "extensions")
+	for name, s := range out {
+		if name != "result-1.2.0" {
+			remove120Fields(s)
+		}
+	}
+
+	// A GC request must name the attachments that are still valid
+	out["gc"].Required = append(out["gc"].Required, types.ValidAttachmentsKey)
+	sort.Strings(out["gc"].Required)
+
+	return out, nil
+}
+
+// remove120Fields drops the "interface" property from all route schemas
+// and the "annotations" property from all interface schemas nested in s
+func remove120Fields(s *Schema) {
+	if s == nil {
+		return
+	}
+	if _, ok := s.Properties["dst"]; ok {
+		delete(s.Properties, "interface")
+	}
+	if _, ok := s.Properties["sandbox"]; ok {
+		delete(s.Properties, "annotations")
+	}
+	for _, p := range s.Properties {
+		remove120Fields(p)
+	}
+	remove120Fields(s.Items)
+	remove120Fields(s.AdditionalProperties)
+}
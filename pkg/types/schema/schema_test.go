@@ -0,0 +1,86 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/types"
+	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/schema"
+)
+
+var _ = Describe("Schema generation", func() {
+	It("describes the network configuration", func() {
+		s, err := schema.Generate(types.NetConf{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Schema).To(Equal(schema.Draft))
+		Expect(s.Type).To(Equal("object"))
+		Expect(s.Properties).To(HaveKey("cniVersion"))
+		Expect(s.Properties).To(HaveKey("cni.dev/valid-attachments"))
+		Expect(s.Properties).To(HaveKey("prevResult"))
+		Expect(s.Properties).NotTo(HaveKey("PrevResult"))
+		Expect(s.Properties["capabilities"].AdditionalProperties.Type).To(Equal("boolean"))
+	})
+
+	It("uses the JSON shape of custom marshalled types", func() {
+		s, err := schema.Generate(&current.Result{})
+		Expect(err).NotTo(HaveOccurred())
+
+		ips := s.Properties["ips"].Items
+		Expect(ips.Properties).To(HaveKey("address"))
+		Expect(ips.Properties["address"].Format).To(Equal("cidr"))
+		Expect(ips.Properties["gateway"].Format).To(Equal("ip"))
+		Expect(ips.Required).To(Equal([]string{"address"}))
+
+		routes := s.Properties["routes"].Items
+		Expect(routes.Properties).To(HaveKey("dst"))
+		Expect(routes.Properties).To(HaveKey("advmss"))
+		Expect(routes.Required).To(Equal([]string{"dst"}))
+
+		Expect(s.Properties["interfaces"].Items.Required).To(Equal([]string{"name"}))
+	})
+
+	It("requires valid attachments in GC requests", func() {
+		docs, err := schema.Documents()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(docs).To(HaveKey("netconf"))
+		Expect(docs).To(HaveKey("result-1.1.0"))
+		Expect(docs["gc"].Required).To(ContainElement("cni.dev/valid-attachments"))
+		Expect(docs["netconf"].Required).NotTo(ContainElement("cni.dev/valid-attachments"))
+	})
+
+	It("matches the generated documents", func() {
+		docs, err := schema.Documents()
+		Expect(err).NotTo(HaveOccurred())
+		for name, doc := range docs {
+			expected, err := os.ReadFile(filepath.Join("..", "..", "..", "Documentation", "schema", name+".json"))
+			Expect(err).NotTo(HaveOccurred())
+			actual, err := json.Marshal(doc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actual).To(MatchJSON(expected), "run 'go generate ./pkg/types/schema' to update %s.json", name)
+		}
+	})
+
+	It("returns an error for unsupported types", func() {
+		_, err := schema.Generate(map[int]string{})
+		Expect(err).To(MatchError("unsupported map key type int"))
+	})
+})
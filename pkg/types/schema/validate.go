@@ -0,0 +1,141 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidateStruct checks the constraints tagged under TagName on the fields
+// of the struct v points to and of the structs nested in it, such as
+// those written by GenerateGo. A required field must not be zero and a
+// numeric field with a minimum must not be lower, unless it is zero, as
+// the zero value of an optional field stands for its absence. Fields are
+// named by their JSON path in the errors.
+func ValidateStruct(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return fmt.Errorf("cannot validate nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot validate %s: not a struct", rv.Type())
+	}
+	return errors.Join(validateValue("", rv)...)
+}
+
+func validateValue(path string, v reflect.Value) []error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateValue(path, v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// Raw bytes, such as IPs and json.RawMessage
+			return nil
+		}
+		var errs []error
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%d]", path, i), v.Index(i))...)
+		}
+		return errs
+	case reflect.Map:
+		var errs []error
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateValue(fmt.Sprintf("%s[%v]", path, iter.Key()), iter.Value())...)
+		}
+		return errs
+	case reflect.Struct:
+		return validateStruct(path, v)
+	}
+	return nil
+}
+
+func validateStruct(path string, v reflect.Value) []error {
+	var errs []error
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		value := v.Field(i)
+		if field.Anonymous {
+			errs = append(errs, validateValue(path, value)...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+
+		if tag, ok := field.Tag.Lookup(TagName); ok {
+			for _, constraint := range strings.Split(tag, ",") {
+				if err := checkConstraint(name, constraint, value); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		errs = append(errs, validateValue(name, value)...)
+	}
+	return errs
+}
+
+func checkConstraint(name, constraint string, v reflect.Value) error {
+	key, arg, _ := strings.Cut(constraint, "=")
+	switch key {
+	case "required":
+		if v.IsZero() {
+			return fmt.Errorf("%s: required", name)
+		}
+	case "minimum":
+		minimum, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid minimum %q", name, arg)
+		}
+		if v.IsZero() {
+			return nil
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if v.Int() < minimum {
+				return fmt.Errorf("%s: %d is less than the minimum %d", name, v.Int(), minimum)
+			}
+		case reflect.Float32, reflect.Float64:
+			if v.Float() < float64(minimum) {
+				return fmt.Errorf("%s: %v is less than the minimum %d", name, v.Float(), minimum)
+			}
+		}
+	default:
+		return fmt.Errorf("%s: unknown constraint %q", name, key)
+	}
+	return nil
+}
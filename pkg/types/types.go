@@ -15,11 +15,13 @@
 package types
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"strings"
 )
 
 // like net.IPNet but adds JSON marshalling and unmarshalling
@@ -115,7 +117,8 @@ func (i *IPAM) IsEmpty() bool {
 
 // NetConfList describes an ordered list of networks.
 type NetConfList struct {
-	CNIVersion string `json:"cniVersion,omitempty"`
+	CNIVersion  string   `json:"cniVersion,omitempty"`
+	CNIVersions []string `json:"cniVersions,omitempty"`
 
 	Name         string     `json:"name,omitempty"`
 	DisableCheck bool       `json:"disableCheck,omitempty"`
@@ -147,6 +150,63 @@ func PrintResult(result Result, version string) error {
 	return newResult.Print()
 }
 
+// PrintOptions control the encoding used by EncodeResult and PrintResultTo
+type PrintOptions struct {
+	// Indent is the indentation of each nesting level. The result is
+	// encoded on a single line when empty.
+	Indent string
+	// Newline appends a trailing newline to the output
+	Newline bool
+}
+
+// EncodeResult converts the result to the given version and returns its
+// JSON encoding. The encoding is canonical: object keys are sorted at
+// every level, so equal results always encode to the same bytes whatever
+// the Go version or the order in which maps were populated. This makes
+// it suitable for cached results and golden test files.
+func EncodeResult(result Result, version string, opts PrintOptions) ([]byte, error) {
+	newResult, err := result.GetAsVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(newResult)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round-trip through generic values, which encoding/json always
+	// writes with sorted keys. UseNumber keeps numbers exactly as encoded.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	if opts.Indent != "" {
+		data, err = json.MarshalIndent(generic, "", opts.Indent)
+	} else {
+		data, err = json.Marshal(generic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Newline {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+// PrintResultTo writes the canonical encoding of the result, converted to
+// the given version, to writer
+func PrintResultTo(writer io.Writer, result Result, version string, opts PrintOptions) error {
+	data, err := EncodeResult(result, version, opts)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
 // DNS contains values interesting for DNS resolvers
 type DNS struct {
 	Nameservers []string `json:"nameservers,omitempty"`
@@ -163,6 +223,19 @@ func (d *DNS) IsEmpty() bool {
 	return false
 }
 
+// Option returns the value of the named resolver option (e.g. "ndots" in
+// "ndots:5") and whether the option is present. Options without a value,
+// like "rotate", return an empty string.
+func (d *DNS) Option(name string) (string, bool) {
+	for _, opt := range d.Options {
+		key, value, _ := strings.Cut(opt, ":")
+		if key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
 func (d *DNS) Copy() *DNS {
 	if d == nil {
 		return nil
@@ -183,6 +256,10 @@ type Route struct {
 	Priority int
 	Table    *int
 	Scope    *int
+	// Interface is the index into the result's Interfaces list of the
+	// link the route should be installed against. Only results of
+	// version 1.2.0 and later carry it.
+	Interface *int
 }
 
 func (r *Route) String() string {
@@ -223,6 +300,11 @@ func (r *Route) Copy() *Route {
 		route.Scope = &scope
 	}
 
+	if r.Interface != nil {
+		intf := *r.Interface
+		route.Interface = &intf
+	}
+
 	return route
 }
 
@@ -239,6 +321,8 @@ const (
 	ErrInvalidNetworkConfig                    // 7
 	ErrInvalidNetNS                            // 8
 	ErrTryAgainLater               uint = 11
+	ErrPluginNotAvailable          uint = 50
+	ErrLimitedConnectivity         uint = 51
 	ErrInternal                    uint = 999
 )
 
@@ -246,6 +330,9 @@ type Error struct {
 	Code    uint   `json:"code"`
 	Msg     string `json:"msg"`
 	Details string `json:"details,omitempty"`
+
+	// wrapped is the underlying error, if created by WrapError
+	wrapped error
 }
 
 func NewError(code uint, msg, details string) *Error {
@@ -280,6 +367,8 @@ type route struct {
 	Priority int    `json:"priority,omitempty"`
 	Table    *int   `json:"table,omitempty"`
 	Scope    *int   `json:"scope,omitempty"`
+
+	Interface *int `json:"interface,omitempty"`
 }
 
 func (r *Route) UnmarshalJSON(data []byte) error {
@@ -295,6 +384,7 @@ func (r *Route) UnmarshalJSON(data []byte) error {
 	r.Priority = rt.Priority
 	r.Table = rt.Table
 	r.Scope = rt.Scope
+	r.Interface = rt.Interface
 
 	return nil
 }
@@ -308,6 +398,8 @@ func (r Route) MarshalJSON() ([]byte, error) {
 		Priority: r.Priority,
 		Table:    r.Table,
 		Scope:    r.Scope,
+
+		Interface: r.Interface,
 	}
 
 	return json.Marshal(rt)
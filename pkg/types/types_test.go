@@ -15,15 +15,21 @@
 package types_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
 
 	"github.com/containernetworking/cni/pkg/types"
 	types040 "github.com/containernetworking/cni/pkg/types/040"
 	current "github.com/containernetworking/cni/pkg/types/100"
+	"github.com/containernetworking/cni/pkg/types/create"
 )
 
 var _ = Describe("Types", func() {
@@ -120,6 +126,525 @@ var _ = Describe("Types", func() {
 		})
 	})
 
+	Describe("DNS options", func() {
+		var dns types.DNS
+		BeforeEach(func() {
+			dns = types.DNS{
+				Nameservers: []string{"1.2.3.4"},
+				Options:     []string{"ndots:5", "timeout:2", "rotate"},
+			}
+		})
+
+		It("returns the value of a present option", func() {
+			value, ok := dns.Option("ndots")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal("5"))
+		})
+
+		It("reports options without a value", func() {
+			value, ok := dns.Option("rotate")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(BeEmpty())
+		})
+
+		It("reports missing options", func() {
+			_, ok := dns.Option("attempts")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("keeps options when converting to older result versions", func() {
+			// 0.2.0 results need an IPv4 or IPv6 address
+			result := &current.Result{
+				CNIVersion: "1.0.0",
+				IPs: []*current.IPConfig{{
+					Address: net.IPNet{IP: net.ParseIP("10.1.2.3"), Mask: net.CIDRMask(24, 32)},
+				}},
+				DNS: dns,
+			}
+			for _, v := range []string{"0.4.0", "0.2.0"} {
+				newResult, err := result.GetAsVersion(v)
+				Expect(err).NotTo(HaveOccurred())
+				jsonBytes, err := json.Marshal(newResult)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(jsonBytes)).To(ContainSubstring(`"options":["ndots:5","timeout:2","rotate"]`))
+			}
+		})
+	})
+
+	Describe("DiffResults", func() {
+		var base types.Result
+		BeforeEach(func() {
+			var err error
+			base, err = create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.0.0",
+				"interfaces": [{"name": "eth0", "mac": "00:11:22:33:44:55", "sandbox": "/var/run/netns/blue"}],
+				"ips": [
+					{"interface": 0, "address": "10.1.2.3/24", "gateway": "10.1.2.1"},
+					{"interface": 0, "address": "10.1.3.3/24"}
+				],
+				"routes": [{"dst": "0.0.0.0/0", "gw": "10.1.2.1"}],
+				"dns": {"nameservers": ["1.1.1.1"]}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns no differences for equal results", func() {
+			diffs, err := types.DiffResults(base, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(BeEmpty())
+		})
+
+		It("reports differences across result versions", func() {
+			other, err := create.CreateFromBytes([]byte(`{
+				"cniVersion": "0.4.0",
+				"interfaces": [{"name": "eth0", "mac": "00:11:22:33:44:66", "sandbox": "/var/run/netns/blue"}],
+				"ips": [
+					{"version": "4", "interface": 0, "address": "10.1.2.3/24", "gateway": "10.1.2.254"},
+					{"version": "4", "interface": 0, "address": "10.1.4.3/24"}
+				],
+				"routes": [{"dst": "0.0.0.0/0", "gw": "10.1.2.1"}],
+				"dns": {"nameservers": ["1.1.1.1"], "domain": "example.com"}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			diffs, err := types.DiffResults(base, other)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(Equal([]types.Difference{
+				{Kind: types.DiffChanged, Field: "interfaces[eth0@/var/run/netns/blue].mac", Old: `"00:11:22:33:44:55"`, New: `"00:11:22:33:44:66"`},
+				{Kind: types.DiffChanged, Field: "ips[10.1.2.3/24].gateway", Old: `"10.1.2.1"`, New: `"10.1.2.254"`},
+				{Kind: types.DiffRemoved, Field: "ips[10.1.3.3/24]", Old: `{"address":"10.1.3.3/24","interface":0}`},
+				{Kind: types.DiffAdded, Field: "ips[10.1.4.3/24]", New: `{"address":"10.1.4.3/24","interface":0}`},
+				{Kind: types.DiffAdded, Field: "dns.domain", New: `"example.com"`},
+			}))
+			Expect(diffs[0].String()).To(Equal(`~ interfaces[eth0@/var/run/netns/blue].mac: "00:11:22:33:44:55" -> "00:11:22:33:44:66"`))
+		})
+
+		It("reports changed routes as a removal and an addition", func() {
+			other, err := base.GetAsVersion("1.0.0")
+			Expect(err).NotTo(HaveOccurred())
+			changed, err := current.GetResult(other)
+			Expect(err).NotTo(HaveOccurred())
+			changed.Routes = []*types.Route{{Dst: changed.Routes[0].Dst, GW: net.ParseIP("10.1.2.254")}}
+
+			diffs, err := types.DiffResults(base, changed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(ConsistOf(
+				types.Difference{Kind: types.DiffRemoved, Field: "routes", Old: `{"dst":"0.0.0.0/0","gw":"10.1.2.1"}`},
+				types.Difference{Kind: types.DiffAdded, Field: "routes", New: `{"dst":"0.0.0.0/0","gw":"10.1.2.254"}`},
+			))
+		})
+
+		It("reports differences in fields of 1.2.0 results", func() {
+			a, err := create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.2.0",
+				"interfaces": [{"name": "eth0", "annotations": {"bridge": "cni0"}}, {"name": "eth1"}],
+				"routes": [{"dst": "0.0.0.0/0", "interface": 0}],
+				"extensions": {"io.example.vlan": 100}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.2.0",
+				"interfaces": [{"name": "eth0", "annotations": {"bridge": "cni1"}}, {"name": "eth1"}],
+				"routes": [{"dst": "0.0.0.0/0", "interface": 1}],
+				"extensions": {"io.example.vlan": 200}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			diffs, err := types.DiffResults(a, b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(Equal([]types.Difference{
+				{Kind: types.DiffChanged, Field: "interfaces[eth0].annotations", Old: `{"bridge":"cni0"}`, New: `{"bridge":"cni1"}`},
+				{Kind: types.DiffRemoved, Field: "routes", Old: `{"dst":"0.0.0.0/0","interface":"eth0"}`},
+				{Kind: types.DiffAdded, Field: "routes", New: `{"dst":"0.0.0.0/0","interface":"eth1"}`},
+				{Kind: types.DiffChanged, Field: "extensions.io.example.vlan", Old: "100", New: "200"},
+			}))
+		})
+
+		It("matches route interfaces by name", func() {
+			a, err := create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.2.0",
+				"interfaces": [{"name": "eth0"}, {"name": "eth1"}],
+				"routes": [{"dst": "0.0.0.0/0", "interface": 1}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.2.0",
+				"interfaces": [{"name": "eth1"}, {"name": "eth0"}],
+				"routes": [{"dst": "0.0.0.0/0", "interface": 0}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			diffs, err := types.DiffResults(a, b)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(BeEmpty())
+		})
+	})
+
+	Describe("CanonicalJSON", func() {
+		It("encodes equivalent JSON identically", func() {
+			a, err := types.CanonicalJSON(json.RawMessage(`{
+				"name": "mynet",
+				"mtu": 1500.0,
+				"ratio": 2.50,
+				"ipam": {"type": "host-local", "ranges": [[{"subnet": "10.0.0.0/24"}]]}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			b, err := types.CanonicalJSON(map[string]interface{}{
+				"ipam":  map[string]interface{}{"ranges": [][]map[string]string{{{"subnet": "10.0.0.0/24"}}}, "type": "host-local"},
+				"ratio": 2.5,
+				"mtu":   15e2,
+				"name":  "mynet",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(a)).To(Equal(`{"ipam":{"ranges":[[{"subnet":"10.0.0.0/24"}]],"type":"host-local"},"mtu":1500,"name":"mynet","ratio":2.5}`))
+			Expect(b).To(Equal(a))
+		})
+
+		It("does not escape HTML characters", func() {
+			out, err := types.CanonicalJSON(json.RawMessage(`["a<b>&c", "\u00e9", null, true]`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`["a<b>&c","é",null,true]`))
+		})
+
+		It("encodes structs through their JSON encoding", func() {
+			out, err := types.CanonicalJSON(&types.DNS{Nameservers: []string{"10.0.0.1"}, Domain: "example.com"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(`{"domain":"example.com","nameservers":["10.0.0.1"]}`))
+		})
+
+		It("rejects invalid JSON", func() {
+			_, err := types.CanonicalJSON(json.RawMessage(`{"a": 1} {"b": 2}`))
+			Expect(err).To(MatchError("unexpected data after JSON value"))
+
+			_, err = types.CanonicalJSON([]byte(`{"a":`))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Redact", func() {
+		It("masks passwords, tokens and secrets at any depth", func() {
+			out, err := types.Redact([]byte(`{
+				"cniVersion": "1.0.0",
+				"name": "mynet",
+				"type": "bridge",
+				"token": "t0p",
+				"ipam": {"type": "vendor-ipam", "auth": [{"user": "admin", "password": "hunter2"}], "secret": {"key": 1}}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(MatchJSON(`{
+				"cniVersion": "1.0.0",
+				"name": "mynet",
+				"type": "bridge",
+				"token": "[REDACTED]",
+				"ipam": {"type": "vendor-ipam", "auth": [{"user": "admin", "password": "[REDACTED]"}], "secret": "[REDACTED]"}
+			}`))
+		})
+
+		It("masks fields ending with those names in any case", func() {
+			out, err := types.Redact([]byte(`{
+				"name": "mynet",
+				"Password": "hunter2",
+				"ipam": {"apiToken": "abc", "clientSECRET": "def", "tokens": 3}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(MatchJSON(`{
+				"name": "mynet",
+				"Password": "[REDACTED]",
+				"ipam": {"apiToken": "[REDACTED]", "clientSECRET": "[REDACTED]", "tokens": 3}
+			}`))
+		})
+
+		It("masks registered fields in each plugin of a list", func() {
+			Expect(types.RegisterSensitiveField("ipam.*.apiKey")).To(Succeed())
+			out, err := types.Redact([]byte(`{
+				"cniVersion": "1.0.0",
+				"name": "mynet",
+				"plugins": [
+					{"type": "bridge", "ipam": {"type": "vendor-ipam", "endpoint": {"url": "https://ipam", "apiKey": "abc"}}},
+					{"type": "portmap", "apiKey": "kept", "mtu": 1500.0}
+				]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out).To(MatchJSON(`{
+				"cniVersion": "1.0.0",
+				"name": "mynet",
+				"plugins": [
+					{"type": "bridge", "ipam": {"type": "vendor-ipam", "endpoint": {"url": "https://ipam", "apiKey": "[REDACTED]"}}},
+					{"type": "portmap", "apiKey": "kept", "mtu": 1500.0}
+				]
+			}`))
+			Expect(string(out)).To(ContainSubstring(`"mtu":1500.0`))
+		})
+
+		It("rejects invalid paths", func() {
+			Expect(types.RegisterSensitiveField("ipam..key")).To(MatchError(`invalid sensitive field "ipam..key": empty key`))
+			Expect(types.RegisterSensitiveField("ipam.**")).To(MatchError(`invalid sensitive field "ipam.**": must end with a key`))
+		})
+
+		It("logs redacted configurations", func() {
+			buf := &bytes.Buffer{}
+			logger := slog.New(slog.NewTextHandler(buf, nil))
+			logger.Info("adding network", "config", types.RedactedConfig(`{"name": "mynet", "password": "hunter2"}`))
+			Expect(buf.String()).To(ContainSubstring(`config="{\"name\":\"mynet\",\"password\":\"[REDACTED]\"}"`))
+			Expect(buf.String()).NotTo(ContainSubstring("hunter2"))
+		})
+	})
+
+	Describe("EncodeResult", func() {
+		var result types.Result
+		BeforeEach(func() {
+			var err error
+			result, err = create.CreateFromBytes([]byte(`{
+				"cniVersion": "0.4.0",
+				"interfaces": [{"name": "eth0", "sandbox": "/var/run/netns/blue"}],
+				"ips": [{"version": "4", "interface": 0, "address": "10.1.2.3/24"}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("encodes compactly with sorted keys", func() {
+			data, err := types.EncodeResult(result, "1.0.0", types.PrintOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{"cniVersion":"1.0.0","interfaces":[{"name":"eth0","sandbox":"/var/run/netns/blue"}],"ips":[{"address":"10.1.2.3/24","interface":0}]}`))
+		})
+
+		It("indents and terminates the output when asked", func() {
+			data, err := types.EncodeResult(result, "0.4.0", types.PrintOptions{Indent: "  ", Newline: true})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal(`{
+  "cniVersion": "0.4.0",
+  "dns": {},
+  "interfaces": [
+    {
+      "name": "eth0",
+      "sandbox": "/var/run/netns/blue"
+    }
+  ],
+  "ips": [
+    {
+      "address": "10.1.2.3/24",
+      "interface": 0,
+      "version": "4"
+    }
+  ]
+}
+`))
+		})
+
+		It("is independent of map insertion order", func() {
+			a := &current.Result{CNIVersion: "1.2.0"}
+			b := &current.Result{CNIVersion: "1.2.0"}
+			keys := []string{"io.example.a", "io.example.b", "io.example.c"}
+			for i := range keys {
+				Expect(a.SetExtension(keys[i], i)).To(Succeed())
+				Expect(b.SetExtension(keys[len(keys)-1-i], len(keys)-1-i)).To(Succeed())
+			}
+
+			var bufA, bufB bytes.Buffer
+			Expect(types.PrintResultTo(&bufA, a, "1.2.0", types.PrintOptions{Indent: "    "})).To(Succeed())
+			Expect(types.PrintResultTo(&bufB, b, "1.2.0", types.PrintOptions{Indent: "    "})).To(Succeed())
+			Expect(bufA.Bytes()).To(Equal(bufB.Bytes()))
+		})
+	})
+
+	Describe("GetAsVersionWithWarnings", func() {
+		var result types.Result
+		BeforeEach(func() {
+			var err error
+			result, err = create.CreateFromBytes([]byte(`{
+				"cniVersion": "1.0.0",
+				"interfaces": [{"name": "eth0", "mac": "00:11:22:33:44:55", "mtu": 1500, "sandbox": "/var/run/netns/blue"}],
+				"ips": [
+					{"interface": 0, "address": "10.1.2.3/24", "gateway": "10.1.2.1"},
+					{"interface": 0, "address": "10.1.3.3/24"}
+				],
+				"routes": [{"dst": "0.0.0.0/0", "gw": "10.1.2.1", "mtu": 1400}],
+				"dns": {"nameservers": ["1.1.1.1"]}
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns no warnings for a lossless conversion", func() {
+			converted, warnings, err := types.GetAsVersionWithWarnings(result, "1.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted.Version()).To(Equal("1.1.0"))
+			Expect(warnings).To(BeEmpty())
+		})
+
+		It("lists fields dropped when downgrading", func() {
+			converted, warnings, err := types.GetAsVersionWithWarnings(result, "0.4.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(Equal([]string{
+				"interfaces[eth0@/var/run/netns/blue].mtu dropped converting to CNI version 0.4.0: 1500",
+			}))
+
+			By("keeping everything else across a round trip")
+			roundTripped, err := converted.GetAsVersion("1.0.0")
+			Expect(err).NotTo(HaveOccurred())
+			diffs, err := types.DiffResults(result, roundTripped)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(HaveLen(1))
+			Expect(diffs[0].Field).To(Equal("interfaces[eth0@/var/run/netns/blue].mtu"))
+		})
+
+		It("lists everything 0.2.0 cannot represent", func() {
+			_, warnings, err := types.GetAsVersionWithWarnings(result, "0.2.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(Equal([]string{
+				`interfaces[eth0@/var/run/netns/blue] dropped converting to CNI version 0.2.0: {"mac":"00:11:22:33:44:55","mtu":1500,"name":"eth0","sandbox":"/var/run/netns/blue"}`,
+				"ips[10.1.2.3/24].interface changed converting to CNI version 0.2.0: eth0@/var/run/netns/blue -> ",
+				`ips[10.1.3.3/24] dropped converting to CNI version 0.2.0: {"address":"10.1.3.3/24","interface":0}`,
+			}))
+		})
+
+		It("warns when extensions are dropped", func() {
+			r, err := current.NewResultFromResult(result)
+			Expect(err).NotTo(HaveOccurred())
+			r.CNIVersion = "1.2.0"
+			Expect(r.SetExtension("com.example.vlan", 100)).To(Succeed())
+
+			_, warnings, err := types.GetAsVersionWithWarnings(r, "1.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(Equal([]string{"extensions dropped converting to CNI version 1.1.0"}))
+		})
+	})
+
+	Describe("GC arguments", func() {
+		It("decodes valid attachments from the network configuration", func() {
+			conf := &types.NetConf{}
+			Expect(json.Unmarshal([]byte(`{
+				"cniVersion": "1.1.0",
+				"name": "mynet",
+				"type": "bridge",
+				"cni.dev/valid-attachments": [{"containerID": "abcd", "ifname": "eth0"}]
+			}`), conf)).To(Succeed())
+
+			args := conf.GCArgs()
+			Expect(args.ValidAttachments).To(Equal([]types.GCAttachment{{ContainerID: "abcd", IfName: "eth0"}}))
+			Expect(args.IsValid("abcd", "eth0")).To(BeTrue())
+			Expect(args.IsValid("abcd", "eth1")).To(BeFalse())
+		})
+
+		It("treats nil arguments as having no valid attachments", func() {
+			var args *types.GCArgs
+			Expect(args.IsValid("abcd", "eth0")).To(BeFalse())
+		})
+	})
+
+	Describe("GC responses", func() {
+		It("parses the deleted resources", func() {
+			r, err := types.ParseGCResponse([]byte(`{
+				"cniVersion": "1.1.0",
+				"deleted": [{"containerID": "abcd", "ifname": "eth0", "interfaces": ["veth1234"], "ips": ["10.0.0.5/24"]}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Deleted).To(Equal([]types.GCDeleted{{
+				ContainerID: "abcd",
+				IfName:      "eth0",
+				Interfaces:  []string{"veth1234"},
+				IPs:         []string{"10.0.0.5/24"},
+			}}))
+		})
+
+		It("treats an empty output as no response", func() {
+			r, err := types.ParseGCResponse([]byte(" \n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r).To(BeNil())
+		})
+
+		It("rejects deletions without a container ID", func() {
+			_, err := types.ParseGCResponse([]byte(`{"deleted": [{"ips": ["10.0.0.5"]}]}`))
+			Expect(err).To(MatchError("GC response deletes resources without a containerID"))
+
+			_, err = types.ParseGCResponse([]byte(`nope`))
+			Expect(err).To(MatchError(HavePrefix("failed to parse GC response: ")))
+		})
+	})
+
+	Describe("YAML", func() {
+		const listJSON = `{
+			"cniVersion": "1.0.0",
+			"name": "mynet",
+			"disableCheck": true,
+			"plugins": [
+				{"type": "bridge", "ipam": {"type": "host-local"}, "dns": {"nameservers": ["10.0.0.1"]}},
+				{"type": "portmap", "capabilities": {"portMappings": true}}
+			]
+		}`
+
+		It("encodes configuration lists as their JSON", func() {
+			list := &types.NetConfList{}
+			Expect(json.Unmarshal([]byte(listJSON), list)).To(Succeed())
+
+			out, err := yaml.Marshal(list)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(ContainSubstring("disableCheck: true"))
+			Expect(string(out)).NotTo(ContainSubstring("dns: {}"))
+
+			var generic interface{}
+			Expect(yaml.Unmarshal(out, &generic)).To(Succeed())
+			converted, err := json.Marshal(generic)
+			Expect(err).NotTo(HaveOccurred())
+			expected, err := json.Marshal(list)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(converted).To(MatchJSON(expected))
+		})
+
+		It("round-trips configuration lists", func() {
+			list := &types.NetConfList{}
+			Expect(json.Unmarshal([]byte(listJSON), list)).To(Succeed())
+			out, err := yaml.Marshal(list)
+			Expect(err).NotTo(HaveOccurred())
+
+			decoded := &types.NetConfList{}
+			Expect(yaml.Unmarshal(out, decoded)).To(Succeed())
+			Expect(decoded).To(Equal(list))
+		})
+
+		It("decodes configurations written as YAML", func() {
+			conf := &types.NetConf{}
+			Expect(yaml.Unmarshal([]byte(`
+cniVersion: 1.0.0
+name: mynet
+type: bridge
+capabilities:
+  ips: true
+ipam:
+  type: host-local
+`), conf)).To(Succeed())
+			Expect(conf).To(Equal(&types.NetConf{
+				CNIVersion:   "1.0.0",
+				Name:         "mynet",
+				Type:         "bridge",
+				Capabilities: map[string]bool{"ips": true},
+				IPAM:         types.IPAM{Type: "host-local"},
+			}))
+		})
+
+		It("rejects keys which are not strings", func() {
+			conf := &types.NetConf{}
+			Expect(yaml.Unmarshal([]byte("name: mynet\ncapabilities:\n  1: true\n"), conf)).To(
+				MatchError(ContainSubstring("keys must be strings")))
+		})
+	})
+
+	Describe("STATUS errors", func() {
+		It("uses the well-known codes", func() {
+			err := types.NewPluginNotAvailableError(false, "no IPs left", "")
+			Expect(err.Code).To(Equal(uint(50)))
+			Expect(types.IsPluginNotAvailable(err)).To(BeTrue())
+
+			err = types.NewPluginNotAvailableError(true, "daemon down", "")
+			Expect(err.Code).To(Equal(uint(51)))
+			Expect(types.IsPluginNotAvailable(fmt.Errorf("status: %w", err))).To(BeTrue())
+		})
+
+		It("does not match other errors", func() {
+			Expect(types.IsPluginNotAvailable(types.NewError(types.ErrTryAgainLater, "busy", ""))).To(BeFalse())
+			Expect(types.IsPluginNotAvailable(fmt.Errorf("plain"))).To(BeFalse())
+		})
+	})
+
 	Describe("Error type", func() {
 		var example *types.Error
 		BeforeEach(func() {
@@ -148,6 +673,131 @@ var _ = Describe("Types", func() {
 			err := types.NewError(1234, "some message", "some details")
 			Expect(err).To(Equal(example))
 		})
+
+		It("names well-known error codes", func() {
+			Expect(types.NewError(types.ErrTryAgainLater, "busy", "").CodeName()).To(Equal("TryAgainLater"))
+			Expect(types.NewError(1999, "unregistered", "").CodeName()).To(Equal("1999"))
+		})
+
+		Describe("error code registry", func() {
+			It("registers and looks up plugin error codes", func() {
+				Expect(types.RegisterErrorCode(1234, "ExampleQuotaExceeded", "the example quota was exceeded")).To(Succeed())
+				// Registering the same name again is harmless
+				Expect(types.RegisterErrorCode(1234, "ExampleQuotaExceeded", "the example quota was exceeded")).To(Succeed())
+
+				ec, ok := types.LookupErrorCode(1234)
+				Expect(ok).To(BeTrue())
+				Expect(ec).To(Equal(types.ErrorCode{Code: 1234, Name: "ExampleQuotaExceeded", Description: "the example quota was exceeded"}))
+				Expect(example.CodeName()).To(Equal("ExampleQuotaExceeded"))
+			})
+
+			It("rejects reserved codes", func() {
+				Expect(types.RegisterErrorCode(42, "Reserved", "")).To(MatchError("error code 42 is reserved for well-known errors"))
+				Expect(types.RegisterErrorCode(types.ErrInternal, "Internal", "")).To(MatchError("error code 999 is reserved for well-known errors"))
+			})
+
+			It("rejects conflicting registrations", func() {
+				Expect(types.RegisterErrorCode(1235, "First", "")).To(Succeed())
+				Expect(types.RegisterErrorCode(1235, "Second", "")).To(MatchError(`error code 1235 is already registered as "First"`))
+			})
+
+			It("reports unknown codes", func() {
+				_, ok := types.LookupErrorCode(4321)
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		Describe("ErrorList", func() {
+			It("aggregates errors keeping their codes", func() {
+				var list types.ErrorList
+				Expect(list.ErrOrNil()).To(BeNil())
+
+				cause := fmt.Errorf("disk full")
+				list.Append(nil)
+				list.Append(example)
+				list.Append(fmt.Errorf("deleting: %w", types.NewError(types.ErrTryAgainLater, "busy", "")))
+				list.Append(cause)
+
+				err := list.ErrOrNil()
+				Expect(err).To(MatchError("some message; some details\ndeleting: busy\ndisk full"))
+				Expect(list.Errors[1].Code).To(Equal(types.ErrTryAgainLater))
+				Expect(list.Errors[2].Code).To(Equal(types.ErrInternal))
+				Expect(errors.Is(err, cause)).To(BeTrue())
+				Expect(errors.Is(err, example)).To(BeTrue())
+
+				code, ok := types.CodeOf(fmt.Errorf("gc: %w", err))
+				Expect(ok).To(BeTrue())
+				Expect(code).To(Equal(uint(1234)))
+			})
+
+			It("flattens nested lists", func() {
+				var inner, outer types.ErrorList
+				inner.Append(example)
+				outer.Append(types.NewError(types.ErrIOFailure, "write failed", ""))
+				outer.Append(&inner)
+				Expect(outer.Errors).To(Equal([]*types.Error{types.NewError(types.ErrIOFailure, "write failed", ""), example}))
+			})
+
+			It("encodes as a spec error listing every error", func() {
+				var list types.ErrorList
+				list.Append(example)
+				list.Append(types.NewError(types.ErrTryAgainLater, "busy", ""))
+				data, err := json.Marshal(&list)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(MatchJSON(`{
+					"code": 1234,
+					"msg": "some message (and 1 more errors)",
+					"details": "some details",
+					"errors": [
+						{"code": 1234, "msg": "some message", "details": "some details"},
+						{"code": 11, "msg": "busy"}
+					]
+				}`))
+
+				decoded := &types.ErrorList{}
+				Expect(json.Unmarshal(data, decoded)).To(Succeed())
+				Expect(decoded.Errors).To(Equal(list.Errors))
+
+				_, err = json.Marshal(&types.ErrorList{})
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("decodes a single spec error as a list of one", func() {
+				decoded := &types.ErrorList{}
+				Expect(json.Unmarshal([]byte(`{"code": 11, "msg": "busy"}`), decoded)).To(Succeed())
+				Expect(decoded.Errors).To(Equal([]*types.Error{types.NewError(types.ErrTryAgainLater, "busy", "")}))
+			})
+		})
+
+		Describe("WrapError", func() {
+			It("uses the given code for plain errors", func() {
+				cause := fmt.Errorf("disk full")
+				err := types.WrapError(cause, types.ErrIOFailure, "failed to write state")
+				Expect(err.Code).To(Equal(types.ErrIOFailure))
+				Expect(err.Error()).To(Equal("failed to write state; disk full"))
+				Expect(errors.Is(err, cause)).To(BeTrue())
+			})
+
+			It("preserves the code of a wrapped Error", func() {
+				cause := fmt.Errorf("allocating: %w", example)
+				err := types.WrapError(cause, types.ErrInternal, "ADD failed")
+				Expect(err.Code).To(Equal(uint(1234)))
+
+				code, ok := types.CodeOf(fmt.Errorf("outer: %w", err))
+				Expect(ok).To(BeTrue())
+				Expect(code).To(Equal(uint(1234)))
+			})
+
+			It("does not serialize the wrapped error", func() {
+				err := types.WrapError(example, 0, "wrapped")
+				Expect(json.Marshal(err)).To(MatchJSON(`{"code": 1234, "msg": "wrapped", "details": "some message; some details"}`))
+			})
+		})
+
+		It("CodeOf reports errors without a code", func() {
+			_, ok := types.CodeOf(fmt.Errorf("plain"))
+			Expect(ok).To(BeFalse())
+		})
 	})
 
 	Describe("Result conversion", func() {
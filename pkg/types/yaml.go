@@ -0,0 +1,118 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The types of this package support YAML through the JSON encoding the
+// specification defines, so that YAML documents hold exactly what the
+// equivalent JSON would. Their MarshalYAML and UnmarshalYAML methods use
+// the signatures understood by the common YAML libraries (gopkg.in/yaml.v2
+// and v3, sigs.k8s.io/yaml) without depending on any of them.
+
+// ToYAML returns the generic value the JSON encoding of v decodes to, for
+// a MarshalYAML method to return
+func ToYAML(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// FromYAML decodes the YAML value read by an UnmarshalYAML method's
+// unmarshal function into v, through its JSON encoding
+func FromYAML(unmarshal func(interface{}) error, v interface{}) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	value, err := jsonValue(value)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// jsonValue converts the maps keyed by interface{} some YAML libraries
+// decode to into maps keyed by string, which JSON can encode
+func jsonValue(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported YAML key %v: keys must be strings", k)
+			}
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+		}
+		return m, nil
+	case map[string]interface{}:
+		for k, v := range value {
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			value[k] = v
+		}
+		return value, nil
+	case []interface{}:
+		for i, v := range value {
+			v, err := jsonValue(v)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = v
+		}
+		return value, nil
+	}
+	return value, nil
+}
+
+// MarshalYAML encodes the configuration as its JSON encoding would
+func (n NetConf) MarshalYAML() (interface{}, error) {
+	return ToYAML(&n)
+}
+
+// UnmarshalYAML decodes the configuration as its JSON encoding would
+func (n *NetConf) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return FromYAML(unmarshal, n)
+}
+
+// MarshalYAML encodes the list as its JSON encoding would
+func (l NetConfList) MarshalYAML() (interface{}, error) {
+	return ToYAML(&l)
+}
+
+// UnmarshalYAML decodes the list as its JSON encoding would
+func (l *NetConfList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return FromYAML(unmarshal, l)
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sysctl reads and writes kernel parameters under /proc/sys
+package sysctl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containernetworking/cni/pkg/types/capabilities"
+	"github.com/containernetworking/cni/pkg/utils"
+)
+
+// procSys is where sysctls are read from and written to
+var procSys = "/proc/sys"
+
+// path returns the /proc/sys path of the named sysctl, in dotted
+// ("net.ipv4.ip_forward") or slash ("net/ipv4/ip_forward") form, refusing
+// names that would escape /proc/sys
+func path(name string) (string, error) {
+	rel := strings.TrimPrefix(capabilities.SysctlPath(name), "/proc/sys/")
+	for _, part := range strings.Split(rel, "/") {
+		if part == "" || part == "." || part == ".." {
+			return "", fmt.Errorf("invalid sysctl name %q", name)
+		}
+	}
+	return filepath.Join(procSys, rel), nil
+}
+
+// Get returns the value of the named sysctl, without the trailing newline
+func Get(name string) (string, error) {
+	p, err := path(name)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to read sysctl %q: %w", name, err)
+	}
+	return string(bytes.TrimSpace(data)), nil
+}
+
+// Set sets the named sysctl to value
+func Set(name, value string) error {
+	p, err := path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(p, []byte(value), 0o644); err != nil {
+		return fmt.Errorf("failed to set sysctl %q to %q: %w", name, value, err)
+	}
+	return nil
+}
+
+// ForInterface returns the name of a per-interface sysctl, such as
+// ForInterface("ipv4", "eth0", "rp_filter") for net.ipv4.conf.eth0.rp_filter.
+// The name is in slash form so that interface names containing dots stay
+// unambiguous, and the interface name is validated.
+func ForInterface(family, ifName, key string) (string, error) {
+	if family != "ipv4" && family != "ipv6" {
+		return "", fmt.Errorf("invalid address family %q", family)
+	}
+	if err := utils.ValidateInterfaceName(ifName); err != nil {
+		return "", err
+	}
+	if key == "" || strings.Contains(key, "/") {
+		return "", fmt.Errorf("invalid sysctl key %q", key)
+	}
+	return fmt.Sprintf("net/%s/conf/%s/%s", family, ifName, key), nil
+}
+
+// IPv6Disabled reports whether IPv6 is unavailable in the current network
+// namespace, either because the kernel has no IPv6 support or because it
+// was disabled with net.ipv6.conf.all.disable_ipv6
+func IPv6Disabled() (bool, error) {
+	value, err := Get("net.ipv6.conf.all.disable_ipv6")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return true, nil
+		}
+		return false, err
+	}
+	return value == "1", nil
+}
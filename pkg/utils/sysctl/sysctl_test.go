@@ -0,0 +1,158 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sysctl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withProcSys(t *testing.T, files map[string]string) {
+	dir, err := os.MkdirTemp("", "sysctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, value := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(value), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	orig := procSys
+	procSys = dir
+	t.Cleanup(func() { procSys = orig })
+}
+
+func TestGetSet(t *testing.T) {
+	withProcSys(t, map[string]string{
+		"net/ipv4/ip_forward":               "0\n",
+		"net/ipv4/conf/eth0.100/arp_notify": "0\n",
+	})
+
+	value, err := Get("net.ipv4.ip_forward")
+	if err != nil || value != "0" {
+		t.Errorf("Expected '0' but got '%v' (%v)", value, err)
+	}
+	if err := Set("net.ipv4.ip_forward", "1"); err != nil {
+		t.Fatal(err)
+	}
+	value, err = Get("net/ipv4/ip_forward")
+	if err != nil || value != "1" {
+		t.Errorf("Expected '1' but got '%v' (%v)", value, err)
+	}
+
+	name, err := ForInterface("ipv4", "eth0.100", "arp_notify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(name, "1"); err != nil {
+		t.Fatal(err)
+	}
+	value, err = Get(name)
+	if err != nil || value != "1" {
+		t.Errorf("Expected '1' but got '%v' (%v)", value, err)
+	}
+}
+
+func TestInvalidNames(t *testing.T) {
+	withProcSys(t, nil)
+
+	for _, name := range []string{"", "net/../../etc/passwd", "net..ipv4", "/net/ipv4/", "net/./ipv4"} {
+		if _, err := Get(name); err == nil {
+			t.Errorf("Expected an error for '%v'", name)
+		}
+		if err := Set(name, "1"); err == nil {
+			t.Errorf("Expected an error for '%v'", name)
+		}
+	}
+}
+
+func TestForInterface(t *testing.T) {
+	testData := []struct {
+		description string
+		family      string
+		ifName      string
+		key         string
+		name        string
+		isErr       bool
+	}{
+		{
+			description: "IPv4 sysctl",
+			family:      "ipv4",
+			ifName:      "eth0",
+			key:         "rp_filter",
+			name:        "net/ipv4/conf/eth0/rp_filter",
+		},
+		{
+			description: "interface name with a dot",
+			family:      "ipv6",
+			ifName:      "eth0.100",
+			key:         "accept_ra",
+			name:        "net/ipv6/conf/eth0.100/accept_ra",
+		},
+		{
+			description: "unknown family",
+			family:      "ipx",
+			ifName:      "eth0",
+			key:         "rp_filter",
+			isErr:       true,
+		},
+		{
+			description: "invalid interface name",
+			family:      "ipv4",
+			ifName:      "..",
+			key:         "rp_filter",
+			isErr:       true,
+		},
+		{
+			description: "key with a slash",
+			family:      "ipv4",
+			ifName:      "eth0",
+			key:         "../../ip_forward",
+			isErr:       true,
+		},
+	}
+
+	for _, tt := range testData {
+		name, err := ForInterface(tt.family, tt.ifName, tt.key)
+		if tt.isErr != (err != nil) || name != tt.name {
+			t.Errorf("%s: expected '%v' but got '%v' (%v)", tt.description, tt.name, name, err)
+		}
+	}
+}
+
+func TestIPv6Disabled(t *testing.T) {
+	withProcSys(t, map[string]string{"net/ipv6/conf/all/disable_ipv6": "1\n"})
+	if disabled, err := IPv6Disabled(); err != nil || !disabled {
+		t.Errorf("Expected IPv6 to be disabled but got %v (%v)", disabled, err)
+	}
+
+	withProcSys(t, map[string]string{"net/ipv6/conf/all/disable_ipv6": "0\n"})
+	if disabled, err := IPv6Disabled(); err != nil || disabled {
+		t.Errorf("Expected IPv6 to be enabled but got %v (%v)", disabled, err)
+	}
+
+	withProcSys(t, map[string]string{"net/ipv4/ip_forward": "0\n"})
+	if disabled, err := IPv6Disabled(); err != nil || !disabled {
+		t.Errorf("Expected IPv6 to be unavailable but got %v (%v)", disabled, err)
+	}
+}
@@ -16,7 +16,10 @@ package utils
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
 	"regexp"
 	"unicode"
 
@@ -30,32 +33,113 @@ const (
 
 	// maxInterfaceNameLength is the length max of a valid interface name
 	maxInterfaceNameLength = 15
+
+	// minIfNameHashLength is the least number of hex digits of the hash in
+	// a generated interface name, leaving at most 7 characters of prefix
+	minIfNameHashLength = 8
+
+	// maxVLANID is the highest usable 802.1Q VLAN ID; 0 and 4095 are reserved
+	maxVLANID = 4094
+
+	// minMTU and maxMTU bound the MTU of an IP interface
+	minMTU = 68
+	maxMTU = 65535
 )
 
 var cniReg = regexp.MustCompile(`^` + cniValidNameChars + `*$`)
 
-// ValidateContainerID will validate that the supplied containerID is not empty does not contain invalid characters
-func ValidateContainerID(containerID string) *types.Error {
+// NamePolicy is a set of rules for container IDs and network names
+type NamePolicy struct {
+	// MaxLength is the longest allowed name, or 0 for no limit
+	MaxLength int
+	// Pattern must match the whole name
+	Pattern *regexp.Regexp
+	// ReservedNames may not be used
+	ReservedNames []string
+}
+
+var (
+	// DefaultNamePolicy is used by ValidateContainerID and
+	// ValidateNetworkName: an alphanumeric character followed by
+	// alphanumerics, '_', '.' and '-'
+	DefaultNamePolicy = NamePolicy{Pattern: cniReg}
+
+	// RelaxedNamePolicy accepts any printable ASCII characters other than
+	// '/' and spaces, such as braced UUIDs, but still rejects "." and ".."
+	// since names are used in file paths
+	RelaxedNamePolicy = NamePolicy{
+		Pattern:       regexp.MustCompile(`^[!-.0-~]+$`),
+		ReservedNames: []string{".", ".."},
+	}
+)
+
+// namePolicyViolation is the rule of a NamePolicy that a name breaks
+type namePolicyViolation int
+
+const (
+	nameAllowed namePolicyViolation = iota
+	nameTooLong
+	nameInvalidChars
+	nameReserved
+)
+
+func (p *NamePolicy) check(name string) namePolicyViolation {
+	if p.MaxLength > 0 && len(name) > p.MaxLength {
+		return nameTooLong
+	}
+	if p.Pattern != nil && !p.Pattern.MatchString(name) {
+		return nameInvalidChars
+	}
+	for _, reserved := range p.ReservedNames {
+		if name == reserved {
+			return nameReserved
+		}
+	}
+	return nameAllowed
+}
+
+// ValidateContainerID will validate that the supplied containerID is not empty and follows the policy
+func (p *NamePolicy) ValidateContainerID(containerID string) *types.Error {
 	if containerID == "" {
 		return types.NewError(types.ErrUnknownContainer, "missing containerID", "")
 	}
-	if !cniReg.MatchString(containerID) {
+	switch p.check(containerID) {
+	case nameTooLong:
+		return types.NewError(types.ErrInvalidEnvironmentVariables, "containerID is too long", fmt.Sprintf("containerID should be at most %d characters", p.MaxLength))
+	case nameInvalidChars:
 		return types.NewError(types.ErrInvalidEnvironmentVariables, "invalid characters in containerID", containerID)
+	case nameReserved:
+		return types.NewError(types.ErrInvalidEnvironmentVariables, "reserved containerID", containerID)
 	}
 	return nil
 }
 
-// ValidateNetworkName will validate that the supplied networkName does not contain invalid characters
-func ValidateNetworkName(networkName string) *types.Error {
+// ValidateNetworkName will validate that the supplied networkName is not empty and follows the policy
+func (p *NamePolicy) ValidateNetworkName(networkName string) *types.Error {
 	if networkName == "" {
 		return types.NewError(types.ErrInvalidNetworkConfig, "missing network name:", "")
 	}
-	if !cniReg.MatchString(networkName) {
+	switch p.check(networkName) {
+	case nameTooLong:
+		return types.NewError(types.ErrInvalidNetworkConfig, "network name is too long", fmt.Sprintf("network name should be at most %d characters", p.MaxLength))
+	case nameInvalidChars:
 		return types.NewError(types.ErrInvalidNetworkConfig, "invalid characters found in network name", networkName)
+	case nameReserved:
+		return types.NewError(types.ErrInvalidNetworkConfig, "reserved network name", networkName)
 	}
 	return nil
 }
 
+// ValidateContainerID will validate that the supplied containerID is not empty does not contain invalid characters
+func ValidateContainerID(containerID string) *types.Error {
+	return DefaultNamePolicy.ValidateContainerID(containerID)
+}
+
+// ValidateNetworkName will validate that the supplied networkName does not contain invalid characters
+func ValidateNetworkName(networkName string) *types.Error {
+	return DefaultNamePolicy.ValidateNetworkName(networkName)
+}
+
 // ValidateInterfaceName will validate the interface name based on the four rules below
 // 1. The name must not be empty
 // 2. The name must be less than 16 characters
@@ -80,3 +164,82 @@ func ValidateInterfaceName(ifName string) *types.Error {
 
 	return nil
 }
+
+// ValidateMACAddress will validate that the supplied address is a unicast
+// 48-bit Ethernet MAC address that can be assigned to an interface
+func ValidateMACAddress(mac string) *types.Error {
+	hwAddr, err := net.ParseMAC(mac)
+	if err != nil {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", err.Error())
+	}
+	if len(hwAddr) != 6 {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", fmt.Sprintf("%s is not a 48-bit Ethernet address", mac))
+	}
+	if hwAddr[0]&0x01 != 0 {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", fmt.Sprintf("%s is a multicast address", mac))
+	}
+	return nil
+}
+
+// ValidateVLANID will validate that the supplied VLAN ID is between 1 and 4094
+func ValidateVLANID(vlanID int) *types.Error {
+	if vlanID < 1 || vlanID > maxVLANID {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid VLAN ID", fmt.Sprintf("VLAN ID %d should be between 1 and %d", vlanID, maxVLANID))
+	}
+	return nil
+}
+
+// ValidateMTU will validate that the supplied MTU is between 68 and 65535.
+// An MTU of 0 means the default is used and is accepted.
+func ValidateMTU(mtu int) *types.Error {
+	if mtu == 0 {
+		return nil
+	}
+	if mtu < minMTU || mtu > maxMTU {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid MTU", fmt.Sprintf("MTU %d should be between %d and %d", mtu, minMTU, maxMTU))
+	}
+	return nil
+}
+
+// ValidateCIDR will validate that the supplied string is an IP address with
+// a prefix length, like "10.1.2.3/24" or "2001:db8::/64"
+func ValidateCIDR(cidr string) *types.Error {
+	if _, err := types.ParseCIDR(cidr); err != nil {
+		return types.NewError(types.ErrInvalidNetworkConfig, "invalid CIDR", err.Error())
+	}
+	return nil
+}
+
+// GenerateIfName returns an interface name made of prefix followed by a hash
+// of the container ID and network name. The same inputs always give the
+// same name and the name always fits in IFNAMSIZ, however long the
+// container ID is. The prefix may be at most 7 characters.
+func GenerateIfName(prefix, containerID, netName string) (string, error) {
+	return generateIfName(prefix, containerID, netName)
+}
+
+// GeneratePeerIfName is like GenerateIfName but also hashes the name of the
+// interface inside the container, so that the host-side peers of several
+// interfaces of one container get distinct names.
+func GeneratePeerIfName(prefix, containerID, netName, ifName string) (string, error) {
+	return generateIfName(prefix, containerID, netName, ifName)
+}
+
+func generateIfName(prefix string, parts ...string) (string, error) {
+	if len(prefix) > maxInterfaceNameLength-minIfNameHashLength {
+		return "", fmt.Errorf("interface name prefix %q is longer than %d characters", prefix, maxInterfaceNameLength-minIfNameHashLength)
+	}
+
+	h := sha256.New()
+	for _, part := range parts {
+		// the separator keeps ("ab", "c") and ("a", "bc") apart
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	name := prefix + hex.EncodeToString(h.Sum(nil))[:maxInterfaceNameLength-len(prefix)]
+
+	if err := ValidateInterfaceName(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
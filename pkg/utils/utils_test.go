@@ -16,6 +16,7 @@ package utils_test
 
 import (
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/containernetworking/cni/pkg/types"
@@ -129,3 +130,270 @@ func TestValidateInterfaceName(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateMACAddress(t *testing.T) {
+	testData := []struct {
+		description string
+		mac         string
+		err         *types.Error
+	}{
+		{
+			description: "normal MAC address",
+			mac:         "02:42:ac:11:00:02",
+			err:         nil,
+		},
+		{
+			description: "malformed MAC address",
+			mac:         "02:42:ac:11:00",
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", "address 02:42:ac:11:00: invalid MAC address"),
+		},
+		{
+			description: "EUI-64 address",
+			mac:         "02:42:ac:11:00:02:00:01",
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", "02:42:ac:11:00:02:00:01 is not a 48-bit Ethernet address"),
+		},
+		{
+			description: "multicast MAC address",
+			mac:         "01:00:5e:00:00:01",
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MAC address", "01:00:5e:00:00:01 is a multicast address"),
+		},
+	}
+
+	for _, tt := range testData {
+		err := utils.ValidateMACAddress(tt.mac)
+		if !reflect.DeepEqual(tt.err, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.err, err)
+		}
+	}
+}
+
+func TestValidateVLANID(t *testing.T) {
+	testData := []struct {
+		description string
+		vlanID      int
+		err         *types.Error
+	}{
+		{
+			description: "lowest VLAN ID",
+			vlanID:      1,
+			err:         nil,
+		},
+		{
+			description: "highest VLAN ID",
+			vlanID:      4094,
+			err:         nil,
+		},
+		{
+			description: "reserved VLAN ID 0",
+			vlanID:      0,
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid VLAN ID", "VLAN ID 0 should be between 1 and 4094"),
+		},
+		{
+			description: "reserved VLAN ID 4095",
+			vlanID:      4095,
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid VLAN ID", "VLAN ID 4095 should be between 1 and 4094"),
+		},
+	}
+
+	for _, tt := range testData {
+		err := utils.ValidateVLANID(tt.vlanID)
+		if !reflect.DeepEqual(tt.err, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.err, err)
+		}
+	}
+}
+
+func TestValidateMTU(t *testing.T) {
+	testData := []struct {
+		description string
+		mtu         int
+		err         *types.Error
+	}{
+		{
+			description: "unset MTU",
+			mtu:         0,
+			err:         nil,
+		},
+		{
+			description: "normal MTU",
+			mtu:         1500,
+			err:         nil,
+		},
+		{
+			description: "MTU too small",
+			mtu:         67,
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MTU", "MTU 67 should be between 68 and 65535"),
+		},
+		{
+			description: "negative MTU",
+			mtu:         -1,
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MTU", "MTU -1 should be between 68 and 65535"),
+		},
+		{
+			description: "MTU too large",
+			mtu:         65536,
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid MTU", "MTU 65536 should be between 68 and 65535"),
+		},
+	}
+
+	for _, tt := range testData {
+		err := utils.ValidateMTU(tt.mtu)
+		if !reflect.DeepEqual(tt.err, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.err, err)
+		}
+	}
+}
+
+func TestValidateCIDR(t *testing.T) {
+	testData := []struct {
+		description string
+		cidr        string
+		err         *types.Error
+	}{
+		{
+			description: "IPv4 CIDR",
+			cidr:        "10.1.2.3/24",
+			err:         nil,
+		},
+		{
+			description: "IPv6 CIDR",
+			cidr:        "2001:db8::/64",
+			err:         nil,
+		},
+		{
+			description: "address without prefix length",
+			cidr:        "10.1.2.3",
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid CIDR", "invalid CIDR address: 10.1.2.3"),
+		},
+		{
+			description: "prefix length out of range",
+			cidr:        "10.1.2.3/33",
+			err:         types.NewError(types.ErrInvalidNetworkConfig, "invalid CIDR", "invalid CIDR address: 10.1.2.3/33"),
+		},
+	}
+
+	for _, tt := range testData {
+		err := utils.ValidateCIDR(tt.cidr)
+		if !reflect.DeepEqual(tt.err, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.err, err)
+		}
+	}
+}
+
+func TestGenerateIfName(t *testing.T) {
+	longID := "a51debf7e1eb5d7c0b8f3e0a2c4d6e8f0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+
+	name, err := utils.GenerateIfName("veth", longID, "net1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(name) != 15 || name[:4] != "veth" {
+		t.Errorf("Expected a 15 character name starting with 'veth' but got '%v'", name)
+	}
+
+	again, _ := utils.GenerateIfName("veth", longID, "net1")
+	if again != name {
+		t.Errorf("Expected '%v' but got '%v'", name, again)
+	}
+
+	// container IDs which only differ after the truncation point of the
+	// naive approach must still get different names
+	other, _ := utils.GenerateIfName("veth", longID[:len(longID)-1]+"0", "net1")
+	if other == name {
+		t.Errorf("Expected different names but got '%v' twice", name)
+	}
+
+	otherNet, _ := utils.GenerateIfName("veth", longID, "net2")
+	if otherNet == name {
+		t.Errorf("Expected different names but got '%v' twice", name)
+	}
+
+	if _, err := utils.GenerateIfName("toolongpfx", longID, "net1"); err == nil {
+		t.Errorf("Expected an error for a long prefix")
+	}
+
+	if _, err := utils.GenerateIfName("a/b", longID, "net1"); err == nil {
+		t.Errorf("Expected an error for an invalid prefix")
+	}
+}
+
+func TestGeneratePeerIfName(t *testing.T) {
+	eth0, err := utils.GeneratePeerIfName("veth", "a51debf7e1eb", "net1", "eth0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eth1, err := utils.GeneratePeerIfName("veth", "a51debf7e1eb", "net1", "eth1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eth0 == eth1 {
+		t.Errorf("Expected different names but got '%v' twice", eth0)
+	}
+
+	container, _ := utils.GenerateIfName("veth", "a51debf7e1eb", "net1")
+	if eth0 == container {
+		t.Errorf("Expected the peer name to differ from '%v'", container)
+	}
+}
+
+func TestNamePolicy(t *testing.T) {
+	policy := &utils.NamePolicy{
+		MaxLength:     8,
+		Pattern:       regexp.MustCompile(`^[a-z]+$`),
+		ReservedNames: []string{"host"},
+	}
+	testData := []struct {
+		description string
+		name        string
+		idErr       *types.Error
+		netErr      *types.Error
+	}{
+		{
+			description: "allowed name",
+			name:        "pod",
+		},
+		{
+			description: "name too long",
+			name:        "abcdefghi",
+			idErr:       types.NewError(types.ErrInvalidEnvironmentVariables, "containerID is too long", "containerID should be at most 8 characters"),
+			netErr:      types.NewError(types.ErrInvalidNetworkConfig, "network name is too long", "network name should be at most 8 characters"),
+		},
+		{
+			description: "invalid characters",
+			name:        "Pod",
+			idErr:       types.NewError(types.ErrInvalidEnvironmentVariables, "invalid characters in containerID", "Pod"),
+			netErr:      types.NewError(types.ErrInvalidNetworkConfig, "invalid characters found in network name", "Pod"),
+		},
+		{
+			description: "reserved name",
+			name:        "host",
+			idErr:       types.NewError(types.ErrInvalidEnvironmentVariables, "reserved containerID", "host"),
+			netErr:      types.NewError(types.ErrInvalidNetworkConfig, "reserved network name", "host"),
+		},
+	}
+
+	for _, tt := range testData {
+		if err := policy.ValidateContainerID(tt.name); !reflect.DeepEqual(tt.idErr, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.idErr, err)
+		}
+		if err := policy.ValidateNetworkName(tt.name); !reflect.DeepEqual(tt.netErr, err) {
+			t.Errorf("%s: expected '%v' but got '%v'", tt.description, tt.netErr, err)
+		}
+	}
+}
+
+func TestRelaxedNamePolicy(t *testing.T) {
+	for _, id := range []string{"{5C8B6B4E-1F2A-4C3D-9E8F-0A1B2C3D4E5F}", "Container:1", "_hidden"} {
+		if err := utils.RelaxedNamePolicy.ValidateContainerID(id); err != nil {
+			t.Errorf("Expected '%v' to be valid but got '%v'", id, err)
+		}
+		if err := utils.ValidateContainerID(id); err == nil && id[0] == '{' {
+			t.Errorf("Expected '%v' to be rejected by the default policy", id)
+		}
+	}
+	for _, id := range []string{"a/b", "a b", ".", ".."} {
+		if err := utils.RelaxedNamePolicy.ValidateContainerID(id); err == nil {
+			t.Errorf("Expected '%v' to be rejected", id)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version_test
+
+import (
+	"testing"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+func BenchmarkConfigDecode(b *testing.B) {
+	data := []byte(`{"cniVersion": "1.0.0", "name": "bench", "type": "bridge", "ipam": {"type": "host-local"}}`)
+	decoder := &version.ConfigDecoder{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := decoder.Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReconcilerCheck(b *testing.B) {
+	info := version.All
+	reconciler := &version.Reconciler{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := reconciler.Check("1.0.0", info); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNegotiate(b *testing.B) {
+	configVersions := []string{"0.3.1", "0.4.0", "1.0.0", "1.1.0"}
+	pluginVersions := version.All.SupportedVersions()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := version.Negotiate(configVersions, pluginVersions); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGreaterThanOrEqualTo(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := version.GreaterThanOrEqualTo("1.1.0", "0.4.0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Constraint is a set of version ranges, such as ">=1.0.0 <1.2.0". Space
+// separated comparisons must all match; alternatives are separated by
+// "||", e.g. "0.4.0 || >=1.0.0".
+type Constraint struct {
+	expr string
+	// alternatives of comparisons which must all match
+	ranges [][]comparison
+}
+
+type comparison struct {
+	op      string
+	version string
+}
+
+var constraintOps = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// NewConstraint parses a constraint expression
+func NewConstraint(expr string) (*Constraint, error) {
+	c := &Constraint{expr: strings.TrimSpace(expr)}
+	for _, alt := range strings.Split(expr, "||") {
+		var r []comparison
+		fields := strings.Fields(alt)
+		for i := 0; i < len(fields); i++ {
+			op := "="
+			for _, o := range constraintOps {
+				if strings.HasPrefix(fields[i], o) {
+					op = o
+					break
+				}
+			}
+			version := strings.TrimPrefix(fields[i], op)
+			// Allow a space between the operator and the version
+			if version == "" && i+1 < len(fields) {
+				i++
+				version = fields[i]
+			}
+			if _, _, _, err := ParseVersion(version); err != nil || version == "" {
+				return nil, fmt.Errorf("invalid version constraint %q: bad version %q", expr, version)
+			}
+			r = append(r, comparison{op: op, version: version})
+		}
+		if len(r) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q: empty range", expr)
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+// MustConstraint is like NewConstraint but panics if the expression
+// cannot be parsed. It is intended for package-level constraints.
+func MustConstraint(expr string) *Constraint {
+	c, err := NewConstraint(expr)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Check returns true if version satisfies the constraint. Invalid
+// versions never satisfy a constraint.
+func (c *Constraint) Check(version string) bool {
+	for _, r := range c.ranges {
+		if c.checkRange(r, version) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) checkRange(r []comparison, version string) bool {
+	for _, cmp := range r {
+		res, err := compareVersions(version, cmp.version)
+		if err != nil {
+			return false
+		}
+		var ok bool
+		switch cmp.op {
+		case "=", "==":
+			ok = res == 0
+		case "!=":
+			ok = res != 0
+		case ">":
+			ok = res > 0
+		case ">=":
+			ok = res >= 0
+		case "<":
+			ok = res < 0
+		case "<=":
+			ok = res <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the versions which satisfy the constraint, in order
+func (c *Constraint) Filter(versions []string) []string {
+	out := []string{}
+	for _, v := range versions {
+		if c.Check(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (c *Constraint) String() string {
+	return c.expr
+}
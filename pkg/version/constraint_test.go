@@ -0,0 +1,68 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Version constraints", func() {
+	DescribeTable("checking versions",
+		func(expr, v string, expected bool) {
+			c, err := version.NewConstraint(expr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Check(v)).To(Equal(expected))
+		},
+		Entry("within a range", ">=1.0.0 <1.2.0", "1.1.0", true),
+		Entry("at the lower bound", ">=1.0.0 <1.2.0", "1.0.0", true),
+		Entry("at the upper bound", ">=1.0.0 <1.2.0", "1.2.0", false),
+		Entry("below the range", ">=1.0.0 <1.2.0", "0.4.0", false),
+		Entry("with spaces after operators", ">= 1.0.0 < 1.2.0", "1.1.0", true),
+		Entry("an exact version", "0.4.0", "0.4.0", true),
+		Entry("an excluded version", ">=0.3.0 !=0.3.1", "0.3.1", false),
+		Entry("one of several alternatives", "0.4.0 || >=1.1.0", "1.1.0", true),
+		Entry("none of several alternatives", "0.4.0 || >=1.1.0", "1.0.0", false),
+		Entry("an empty version as 0.1.0", "<0.2.0", "", true),
+		Entry("an invalid version", ">=1.0.0", "1.x", false),
+	)
+
+	It("rejects invalid expressions", func() {
+		_, err := version.NewConstraint(">=1.x")
+		Expect(err).To(MatchError(`invalid version constraint ">=1.x": bad version "1.x"`))
+
+		_, err = version.NewConstraint("1.0.0 ||")
+		Expect(err).To(MatchError(`invalid version constraint "1.0.0 ||": empty range`))
+	})
+
+	It("filters versions", func() {
+		c := version.MustConstraint(">=0.4.0")
+		Expect(c.Filter(version.All.SupportedVersions())).To(Equal([]string{"0.4.0", "1.0.0", "1.1.0"}))
+	})
+
+	It("is accepted by the reconciler", func() {
+		reconciler := &version.Reconciler{}
+		c := version.MustConstraint(">=1.0.0 <1.2.0")
+		Expect(reconciler.CheckConstraint("1.1.0", c)).To(BeNil())
+
+		err := reconciler.CheckConstraint("0.4.0", c)
+		Expect(err).To(Equal(&version.ErrorIncompatible{
+			Config:    "0.4.0",
+			Supported: []string{">=1.0.0 <1.2.0"},
+		}))
+	})
+})
@@ -0,0 +1,75 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import "fmt"
+
+// Feature is a part of the CNI specification that was introduced in a
+// particular spec version
+type Feature string
+
+const (
+	// FeatureCheck is the CHECK command
+	FeatureCheck Feature = "CHECK"
+	// FeatureDelPrevResult is passing the cached result of ADD to DEL as
+	// prevResult
+	FeatureDelPrevResult Feature = "DEL prevResult"
+	// FeatureGC is the GC command
+	FeatureGC Feature = "GC"
+	// FeatureStatus is the STATUS command
+	FeatureStatus Feature = "STATUS"
+	// FeatureCNIVersions is the plural "cniVersions" configuration key
+	FeatureCNIVersions Feature = "cniVersions"
+	// FeatureExtensions is the "extensions" result key
+	FeatureExtensions Feature = "extensions"
+	// FeatureRouteInterface is the "interface" key of result routes
+	FeatureRouteInterface Feature = "route interface"
+	// FeatureInterfaceAnnotations is the "annotations" key of result
+	// interfaces
+	FeatureInterfaceAnnotations Feature = "interface annotations"
+	// FeatureInterfaceDevice is the "pfIndex", "vfIndex" and "rdmaDevice"
+	// keys of result interfaces
+	FeatureInterfaceDevice Feature = "interface device"
+)
+
+// featureVersions maps each feature to the spec version that introduced it
+var featureVersions = map[Feature]string{
+	FeatureCheck:                "0.4.0",
+	FeatureDelPrevResult:        "0.4.0",
+	FeatureGC:                   "1.1.0",
+	FeatureStatus:               "1.1.0",
+	FeatureCNIVersions:          "1.1.0",
+	FeatureExtensions:           "1.2.0",
+	FeatureRouteInterface:       "1.2.0",
+	FeatureInterfaceAnnotations: "1.2.0",
+	FeatureInterfaceDevice:      "1.2.0",
+}
+
+// MinVersion returns the spec version that introduced the feature, or an
+// empty string if the feature is unknown
+func MinVersion(feature Feature) string {
+	return featureVersions[feature]
+}
+
+// Supports returns whether the feature is available in the given spec
+// version. It returns an error if the feature is unknown or the version
+// cannot be parsed.
+func Supports(feature Feature, version string) (bool, error) {
+	minVersion, ok := featureVersions[feature]
+	if !ok {
+		return false, fmt.Errorf("unknown feature %q", feature)
+	}
+	return GreaterThanOrEqualTo(version, minVersion)
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Feature availability", func() {
+	DescribeTable("by spec version",
+		func(feature version.Feature, v string, expected bool) {
+			supported, err := version.Supports(feature, v)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(supported).To(Equal(expected))
+		},
+		Entry("CHECK in 0.3.1", version.FeatureCheck, "0.3.1", false),
+		Entry("CHECK in 0.4.0", version.FeatureCheck, "0.4.0", true),
+		Entry("DEL prevResult in 0.3.0", version.FeatureDelPrevResult, "0.3.0", false),
+		Entry("DEL prevResult in 1.0.0", version.FeatureDelPrevResult, "1.0.0", true),
+		Entry("GC in 1.0.0", version.FeatureGC, "1.0.0", false),
+		Entry("GC in 1.1.0", version.FeatureGC, "1.1.0", true),
+		Entry("STATUS in 1.1.0", version.FeatureStatus, "1.1.0", true),
+		Entry("cniVersions in 1.0.0", version.FeatureCNIVersions, "1.0.0", false),
+		Entry("cniVersions in 1.1.0", version.FeatureCNIVersions, "1.1.0", true),
+		Entry("extensions in 1.1.0", version.FeatureExtensions, "1.1.0", false),
+		Entry("extensions in a 1.2.0 release candidate", version.FeatureExtensions, "1.2.0-rc1", false),
+		Entry("extensions in 1.2.0", version.FeatureExtensions, "1.2.0", true),
+		Entry("a missing version", version.FeatureCheck, "", false),
+	)
+
+	It("reports the version that introduced a feature", func() {
+		Expect(version.MinVersion(version.FeatureGC)).To(Equal("1.1.0"))
+		Expect(version.MinVersion("bogus")).To(BeEmpty())
+	})
+
+	It("returns an error for unknown features", func() {
+		_, err := version.Supports("bogus", "1.1.0")
+		Expect(err).To(MatchError(`unknown feature "bogus"`))
+	})
+
+	It("returns an error for malformed versions", func() {
+		_, err := version.Supports(version.FeatureCheck, "asdf")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,88 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FeatureGates enables features of draft spec versions, those introduced
+// in a version later than Current(). Draft features are off unless their
+// gate is set, so that they can be tried before the spec is released;
+// features of released versions are always on, so gates need no changes
+// when a draft is released. A nil FeatureGates enables no draft features.
+type FeatureGates map[Feature]bool
+
+// IsDraft returns whether the feature was introduced in a version of the
+// spec which is not released yet
+func IsDraft(feature Feature) bool {
+	minVersion, ok := featureVersions[feature]
+	if !ok {
+		return false
+	}
+	released, err := GreaterThanOrEqualTo(Current(), minVersion)
+	return err == nil && !released
+}
+
+// DraftFeatures returns the features which need a gate, sorted by name
+func DraftFeatures() []Feature {
+	var features []Feature
+	for feature := range featureVersions {
+		if IsDraft(feature) {
+			features = append(features, feature)
+		}
+	}
+	sort.Slice(features, func(i, j int) bool { return features[i] < features[j] })
+	return features
+}
+
+// Enabled returns whether the feature may be used: it is not a draft
+// feature, or its gate is set
+func (g FeatureGates) Enabled(feature Feature) bool {
+	return !IsDraft(feature) || g[feature]
+}
+
+// Supports is like the Supports function, but also returns false for
+// draft features whose gate is not set
+func (g FeatureGates) Supports(feature Feature, version string) (bool, error) {
+	supported, err := Supports(feature, version)
+	if err != nil || !supported {
+		return supported, err
+	}
+	return g.Enabled(feature), nil
+}
+
+// ParseFeatureGates parses a comma-separated list of the draft features
+// to enable, such as "extensions,route interface", for runtimes which
+// take gates from a flag or environment variable
+func ParseFeatureGates(s string) (FeatureGates, error) {
+	gates := FeatureGates{}
+	for _, name := range strings.Split(s, ",") {
+		feature := Feature(strings.TrimSpace(name))
+		if feature == "" {
+			continue
+		}
+		if _, ok := featureVersions[feature]; !ok {
+			return nil, fmt.Errorf("unknown feature %q; draft features are %q", feature, DraftFeatures())
+		}
+		if !IsDraft(feature) {
+			return nil, fmt.Errorf("feature %q is released in CNI version %s and needs no gate", feature, MinVersion(feature))
+		}
+		gates[feature] = true
+	}
+	return gates, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("FeatureGates", func() {
+	It("reports the features of unreleased spec versions as drafts", func() {
+		Expect(version.IsDraft(version.FeatureExtensions)).To(BeTrue())
+		Expect(version.IsDraft(version.FeatureRouteInterface)).To(BeTrue())
+		Expect(version.IsDraft(version.FeatureGC)).To(BeFalse())
+		Expect(version.IsDraft("bogus")).To(BeFalse())
+		Expect(version.DraftFeatures()).To(Equal([]version.Feature{version.FeatureExtensions, version.FeatureInterfaceAnnotations, version.FeatureInterfaceDevice, version.FeatureRouteInterface}))
+	})
+
+	It("enables released features without a gate", func() {
+		var gates version.FeatureGates
+		Expect(gates.Enabled(version.FeatureStatus)).To(BeTrue())
+
+		supported, err := gates.Supports(version.FeatureStatus, "1.1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeTrue())
+	})
+
+	It("enables draft features only with their gate", func() {
+		var gates version.FeatureGates
+		Expect(gates.Enabled(version.FeatureExtensions)).To(BeFalse())
+		supported, err := gates.Supports(version.FeatureExtensions, "1.2.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeFalse())
+
+		gates = version.FeatureGates{version.FeatureExtensions: true}
+		Expect(gates.Enabled(version.FeatureExtensions)).To(BeTrue())
+		Expect(gates.Enabled(version.FeatureRouteInterface)).To(BeFalse())
+		supported, err = gates.Supports(version.FeatureExtensions, "1.2.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeTrue())
+
+		supported, err = gates.Supports(version.FeatureExtensions, "1.1.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeFalse())
+	})
+
+	Describe("ParseFeatureGates", func() {
+		It("parses a list of draft features", func() {
+			gates, err := version.ParseFeatureGates(" extensions, route interface ,")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gates).To(Equal(version.FeatureGates{
+				version.FeatureExtensions:     true,
+				version.FeatureRouteInterface: true,
+			}))
+		})
+
+		It("parses an empty list", func() {
+			gates, err := version.ParseFeatureGates("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gates).To(BeEmpty())
+		})
+
+		It("rejects unknown features", func() {
+			_, err := version.ParseFeatureGates("extensions,bogus")
+			Expect(err).To(MatchError(`unknown feature "bogus"; draft features are ["extensions" "interface annotations" "interface device" "route interface"]`))
+		})
+
+		It("rejects released features", func() {
+			_, err := version.ParseFeatureGates("GC")
+			Expect(err).To(MatchError(`feature "GC" is released in CNI version 1.1.0 and needs no gate`))
+		})
+	})
+})
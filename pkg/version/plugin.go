@@ -33,9 +33,33 @@ type PluginInfo interface {
 	Encode(io.Writer) error
 }
 
+// CapabilityInfo is implemented by the PluginInfo of plugins which
+// advertise, in their VERSION output, the capabilities they support
+type CapabilityInfo interface {
+	// Capabilities returns the capabilities the plugin supports, or nil
+	// if it does not advertise them
+	Capabilities() []string
+}
+
+// FeatureInfo is implemented by the PluginInfo of plugins which
+// advertise, in their VERSION output, the optional features they offer
+// with configurations of each version
+type FeatureInfo interface {
+	// Features returns the features the plugin offers with configurations
+	// of the given version, and whether the plugin advertises features at
+	// all. If not, the features are only known from the spec version.
+	Features(version string) ([]Feature, bool)
+}
+
+// FeatureIPv6Only is a plugin feature outside the spec: the plugin can set
+// up attachments without any IPv4 address
+const FeatureIPv6Only Feature = "ipv6-only"
+
 type pluginInfo struct {
-	CNIVersion_        string   `json:"cniVersion"`
-	SupportedVersions_ []string `json:"supportedVersions,omitempty"`
+	CNIVersion_        string               `json:"cniVersion"`
+	SupportedVersions_ []string             `json:"supportedVersions,omitempty"`
+	Capabilities_      []string             `json:"capabilities,omitempty"`
+	Features_          map[string][]Feature `json:"features,omitempty"`
 }
 
 // pluginInfo implements the PluginInfo interface
@@ -49,6 +73,67 @@ func (p *pluginInfo) SupportedVersions() []string {
 	return p.SupportedVersions_
 }
 
+func (p *pluginInfo) Capabilities() []string {
+	return p.Capabilities_
+}
+
+func (p *pluginInfo) Features(version string) ([]Feature, bool) {
+	if p.Features_ == nil {
+		return nil, false
+	}
+	return p.Features_[version], true
+}
+
+// copyInfo returns a pluginInfo reporting what info does
+func copyInfo(info PluginInfo) *pluginInfo {
+	p := &pluginInfo{
+		CNIVersion_:        Current(),
+		SupportedVersions_: info.SupportedVersions(),
+	}
+	if ci, ok := info.(CapabilityInfo); ok {
+		p.Capabilities_ = ci.Capabilities()
+	}
+	if fi, ok := info.(*pluginInfo); ok {
+		p.Features_ = fi.Features_
+	}
+	return p
+}
+
+// WithCapabilities returns a PluginInfo reporting the versions and
+// features of info and advertising the given capabilities, such as
+// "portMappings", so that runtimes can check that configurations only
+// request capabilities the plugin supports.
+func WithCapabilities(info PluginInfo, capabilities ...string) PluginInfo {
+	p := copyInfo(info)
+	p.Capabilities_ = capabilities
+	return p
+}
+
+// WithFeatures returns a PluginInfo reporting the versions and
+// capabilities of info and advertising the given optional features, such
+// as FeatureGC, FeatureStatus or FeatureIPv6Only, so that runtimes know
+// which commands and behaviors they can rely on rather than inferring
+// them from version numbers. Each spec feature is advertised for the
+// supported versions which define it; other features for all of them.
+// Plugins advertising features must list all those they offer.
+func WithFeatures(info PluginInfo, features ...Feature) PluginInfo {
+	p := copyInfo(info)
+	p.Features_ = make(map[string][]Feature, len(p.SupportedVersions_))
+	for _, v := range p.SupportedVersions_ {
+		offered := []Feature{}
+		for _, f := range features {
+			if MinVersion(f) != "" {
+				if ok, err := Supports(f, v); err != nil || !ok {
+					continue
+				}
+			}
+			offered = append(offered, f)
+		}
+		p.Features_[v] = offered
+	}
+	return p
+}
+
 // PluginSupports returns a new PluginInfo that will report the given versions
 // as supported
 func PluginSupports(supportedVersions ...string) PluginInfo {
@@ -83,62 +168,185 @@ func (*PluginDecoder) Decode(jsonBytes []byte) (PluginInfo, error) {
 }
 
 // ParseVersion parses a version string like "3.0.1" or "0.4.5" into major,
-// minor, and micro numbers or returns an error
+// minor, and micro numbers or returns an error. Any pre-release or build
+// metadata suffix, as in "1.1.0-rc1" or "1.1.0+vendor.3", is validated but
+// not returned; use ParseSemver to get it.
 func ParseVersion(version string) (int, int, int, error) {
-	var major, minor, micro int
+	v, err := ParseSemver(version)
+	if err != nil {
+		return -1, -1, -1, err
+	}
+	return v.Major, v.Minor, v.Micro, nil
+}
+
+// Semver is a parsed version, including the optional pre-release and build
+// metadata suffixes of semantic versioning
+type Semver struct {
+	Major, Minor, Micro int
+
+	// PreRelease is the part after "-", e.g. "rc1" in "1.1.0-rc1"
+	PreRelease string
+	// Build is the part after "+", e.g. "vendor.3" in "1.1.0+vendor.3".
+	// It is ignored when comparing versions.
+	Build string
+}
+
+// ParseSemver parses a version string like "1.1.0", "1.1.0-rc1" or
+// "1.1.0+vendor.3" or returns an error
+func ParseSemver(version string) (Semver, error) {
+	var v Semver
 	if version == "" { // special case: no version declared == v0.1.0
-		return 0, 1, 0, nil
+		v.Minor = 1
+		return v, nil
 	}
 
-	parts := strings.Split(version, ".")
+	core, build, hasBuild := strings.Cut(version, "+")
+	if hasBuild {
+		if err := validateIdentifiers(build); err != nil {
+			return v, fmt.Errorf("invalid build metadata in version %q: %w", version, err)
+		}
+		v.Build = build
+	}
+	// The pre-release starts at the first "-" following a digit; any other
+	// "-" is the sign of a number, as in the "0.-42.0" used by test plugins
+	for i := 1; i < len(core); i++ {
+		if core[i] == '-' && core[i-1] >= '0' && core[i-1] <= '9' {
+			preRelease := core[i+1:]
+			if err := validateIdentifiers(preRelease); err != nil {
+				return v, fmt.Errorf("invalid pre-release in version %q: %w", version, err)
+			}
+			v.PreRelease = preRelease
+			core = core[:i]
+			break
+		}
+	}
+
+	parts := strings.Split(core, ".")
 	if len(parts) >= 4 {
-		return -1, -1, -1, fmt.Errorf("invalid version %q: too many parts", version)
+		return v, fmt.Errorf("invalid version %q: too many parts", version)
 	}
 
-	major, err := strconv.Atoi(parts[0])
+	var err error
+	v.Major, err = strconv.Atoi(parts[0])
 	if err != nil {
-		return -1, -1, -1, fmt.Errorf("failed to convert major version part %q: %w", parts[0], err)
+		return v, fmt.Errorf("failed to convert major version part %q: %w", parts[0], err)
 	}
 
 	if len(parts) >= 2 {
-		minor, err = strconv.Atoi(parts[1])
+		v.Minor, err = strconv.Atoi(parts[1])
 		if err != nil {
-			return -1, -1, -1, fmt.Errorf("failed to convert minor version part %q: %w", parts[1], err)
+			return v, fmt.Errorf("failed to convert minor version part %q: %w", parts[1], err)
 		}
 	}
 
 	if len(parts) >= 3 {
-		micro, err = strconv.Atoi(parts[2])
+		v.Micro, err = strconv.Atoi(parts[2])
 		if err != nil {
-			return -1, -1, -1, fmt.Errorf("failed to convert micro version part %q: %w", parts[2], err)
+			return v, fmt.Errorf("failed to convert micro version part %q: %w", parts[2], err)
 		}
 	}
 
-	return major, minor, micro, nil
+	return v, nil
+}
+
+// validateIdentifiers checks a dot-separated list of pre-release or build
+// identifiers, which must be non-empty and made of [0-9A-Za-z-]
+func validateIdentifiers(s string) error {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return fmt.Errorf("empty identifier")
+		}
+		for _, c := range id {
+			if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+				return fmt.Errorf("invalid character %q in identifier %q", c, id)
+			}
+		}
+	}
+	return nil
+}
+
+// Compare returns -1, 0 or 1 if v is respectively lower than, equal to or
+// greater than other, following semantic versioning precedence: a
+// pre-release is lower than its release and build metadata is ignored.
+func (v Semver) Compare(other Semver) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Micro, other.Micro}} {
+		if pair[0] < pair[1] {
+			return -1
+		} else if pair[0] > pair[1] {
+			return 1
+		}
+	}
+
+	switch {
+	case v.PreRelease == other.PreRelease:
+		return 0
+	case v.PreRelease == "":
+		return 1
+	case other.PreRelease == "":
+		return -1
+	}
+
+	ids, otherIDs := strings.Split(v.PreRelease, "."), strings.Split(other.PreRelease, ".")
+	for i := 0; i < len(ids) && i < len(otherIDs); i++ {
+		if cmp := compareIdentifiers(ids[i], otherIDs[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	switch {
+	case len(ids) < len(otherIDs):
+		return -1
+	case len(ids) > len(otherIDs):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifiers compares pre-release identifiers: numeric identifiers
+// compare numerically and are lower than alphanumeric ones, which compare
+// lexically
+func compareIdentifiers(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		if aNum < bNum {
+			return -1
+		} else if aNum > bNum {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
 }
 
 // GreaterThanOrEqualTo takes two string versions, parses them into major/minor/micro
 // numbers, and compares them to determine whether the first version is greater
-// than or equal to the second
+// than or equal to the second. Pre-release versions are lower than their
+// release and build metadata is ignored.
 func GreaterThanOrEqualTo(version, otherVersion string) (bool, error) {
-	firstMajor, firstMinor, firstMicro, err := ParseVersion(version)
+	cmp, err := compareVersions(version, otherVersion)
 	if err != nil {
 		return false, err
 	}
+	return cmp >= 0, nil
+}
 
-	secondMajor, secondMinor, secondMicro, err := ParseVersion(otherVersion)
+// compareVersions returns -1, 0 or 1 if version is respectively lower than,
+// equal to or greater than otherVersion
+func compareVersions(version, otherVersion string) (int, error) {
+	first, err := ParseSemver(version)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
-	if firstMajor > secondMajor {
-		return true, nil
-	} else if firstMajor == secondMajor {
-		if firstMinor > secondMinor {
-			return true, nil
-		} else if firstMinor == secondMinor && firstMicro >= secondMicro {
-			return true, nil
-		}
+	second, err := ParseSemver(otherVersion)
+	if err != nil {
+		return 0, err
 	}
-	return false, nil
+
+	return first.Compare(second), nil
 }
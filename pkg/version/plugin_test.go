@@ -15,6 +15,8 @@
 package version_test
 
 import (
+	"bytes"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -45,6 +47,73 @@ var _ = Describe("Decoding versions reported by a plugin", func() {
 		}))
 	})
 
+	It("does not report capabilities the plugin does not advertise", func() {
+		pluginInfo, err := decoder.Decode(versionStdout)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pluginInfo.(version.CapabilityInfo).Capabilities()).To(BeNil())
+	})
+
+	Context("when the plugin advertises its capabilities", func() {
+		It("round trips them", func() {
+			info := version.WithCapabilities(version.PluginSupports("0.4.0", "1.0.0"), "portMappings", "bandwidth")
+			buf := &bytes.Buffer{}
+			Expect(info.Encode(buf)).To(Succeed())
+			Expect(buf.String()).To(MatchJSON(`{
+				"cniVersion": "` + version.Current() + `",
+				"supportedVersions": ["0.4.0", "1.0.0"],
+				"capabilities": ["portMappings", "bandwidth"]
+			}`))
+
+			pluginInfo, err := decoder.Decode(buf.Bytes())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pluginInfo.SupportedVersions()).To(Equal([]string{"0.4.0", "1.0.0"}))
+			Expect(pluginInfo.(version.CapabilityInfo).Capabilities()).To(Equal([]string{"portMappings", "bandwidth"}))
+		})
+	})
+
+	Context("when the plugin advertises its features", func() {
+		It("lists each feature under the versions offering it", func() {
+			info := version.WithFeatures(version.PluginSupports("0.4.0", "1.0.0", "1.1.0"), version.FeatureGC, version.FeatureIPv6Only)
+			buf := &bytes.Buffer{}
+			Expect(info.Encode(buf)).To(Succeed())
+			Expect(buf.String()).To(MatchJSON(`{
+				"cniVersion": "` + version.Current() + `",
+				"supportedVersions": ["0.4.0", "1.0.0", "1.1.0"],
+				"features": {
+					"0.4.0": ["ipv6-only"],
+					"1.0.0": ["ipv6-only"],
+					"1.1.0": ["GC", "ipv6-only"]
+				}
+			}`))
+
+			pluginInfo, err := decoder.Decode(buf.Bytes())
+			Expect(err).NotTo(HaveOccurred())
+			features, advertised := pluginInfo.(version.FeatureInfo).Features("1.1.0")
+			Expect(advertised).To(BeTrue())
+			Expect(features).To(Equal([]version.Feature{version.FeatureGC, version.FeatureIPv6Only}))
+			features, advertised = pluginInfo.(version.FeatureInfo).Features("0.3.1")
+			Expect(advertised).To(BeTrue())
+			Expect(features).To(BeEmpty())
+		})
+
+		It("keeps the advertised capabilities", func() {
+			info := version.WithCapabilities(version.PluginSupports("1.1.0"), "portMappings")
+			info = version.WithFeatures(info, version.FeatureStatus)
+			info = version.WithCapabilities(info, "portMappings", "bandwidth")
+			Expect(info.(version.CapabilityInfo).Capabilities()).To(Equal([]string{"portMappings", "bandwidth"}))
+			features, advertised := info.(version.FeatureInfo).Features("1.1.0")
+			Expect(advertised).To(BeTrue())
+			Expect(features).To(Equal([]version.Feature{version.FeatureStatus}))
+		})
+
+		It("reports nothing advertised when the plugin omits them", func() {
+			pluginInfo, err := decoder.Decode([]byte(`{"cniVersion": "1.1.0", "supportedVersions": ["1.1.0"]}`))
+			Expect(err).NotTo(HaveOccurred())
+			_, advertised := pluginInfo.(version.FeatureInfo).Features("1.1.0")
+			Expect(advertised).To(BeFalse())
+		})
+	})
+
 	Context("when the bytes cannot be decoded as json", func() {
 		BeforeEach(func() {
 			versionStdout = []byte(`{{{`)
@@ -101,12 +170,43 @@ var _ = Describe("Decoding versions reported by a plugin", func() {
 		})
 
 		It("returns an error for malformed versions", func() {
-			badVersions := []string{"asdfasdf", "asdf.", ".asdfas", "asdf.adsf.", "0.", "..", "1.2.3.4.5"}
+			badVersions := []string{"asdfasdf", "asdf.", ".asdfas", "asdf.adsf.", "0.", "..", "1.2.3.4.5", "1.2.3-", "1.2.3+", "1.2.3-rc..1", "1.2.3+a_b"}
 			for _, v := range badVersions {
 				_, _, _, err := version.ParseVersion(v)
 				Expect(err).To(HaveOccurred())
 			}
 		})
+
+		It("parses negative parts", func() {
+			major, minor, micro, err := version.ParseVersion("0.-42.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect([]int{major, minor, micro}).To(Equal([]int{0, -42, 0}))
+		})
+
+		It("accepts pre-release and build metadata suffixes", func() {
+			major, minor, micro, err := version.ParseVersion("1.1.0-rc1+vendor.3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect([]int{major, minor, micro}).To(Equal([]int{1, 1, 0}))
+		})
+	})
+
+	Describe("ParseSemver", func() {
+		It("returns the pre-release and build metadata", func() {
+			v, err := version.ParseSemver("1.1.0-rc.1+vendor.3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v).To(Equal(version.Semver{Major: 1, Minor: 1, Micro: 0, PreRelease: "rc.1", Build: "vendor.3"}))
+		})
+
+		It("allows hyphens in the pre-release", func() {
+			v, err := version.ParseSemver("1.1.0-rc-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(v.PreRelease).To(Equal("rc-1"))
+		})
+
+		It("reports the malformed suffix", func() {
+			_, err := version.ParseSemver("1.1.0+vendor$")
+			Expect(err).To(MatchError(ContainSubstring(`invalid build metadata in version "1.1.0+vendor$"`)))
+		})
 	})
 
 	Describe("GreaterThanOrEqualTo", func() {
@@ -116,6 +216,12 @@ var _ = Describe("Decoding versions reported by a plugin", func() {
 				{"2.5.4", "2.4.4"},
 				{"1.2.3", "0.2.3"},
 				{"0.4.0", "0.3.1"},
+				{"1.1.0", "1.1.0-rc1"},
+				{"1.1.0-rc2", "1.1.0-rc1"},
+				{"1.1.0-rc.10", "1.1.0-rc.2"},
+				{"1.1.0-rc.1", "1.1.0-rc"},
+				{"1.1.0-alpha", "1.1.0-1"},
+				{"1.1.0-rc1", "1.0.0"},
 			}
 			for _, v := range versions {
 				// Make sure the first is greater than the second
@@ -136,6 +242,16 @@ var _ = Describe("Decoding versions reported by a plugin", func() {
 			Expect(gt).To(BeTrue())
 		})
 
+		It("ignores build metadata", func() {
+			gt, err := version.GreaterThanOrEqualTo("1.1.0", "1.1.0+vendor.3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gt).To(BeTrue())
+
+			gt, err = version.GreaterThanOrEqualTo("1.1.0+vendor.3", "1.1.0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gt).To(BeTrue())
+		})
+
 		It("returns an error for malformed versions", func() {
 			versions := [][2]string{
 				{"1.2.34", "asdadf"},
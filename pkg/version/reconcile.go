@@ -14,7 +14,11 @@
 
 package version
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 type ErrorIncompatible struct {
 	Config    string
@@ -36,10 +40,8 @@ func (r *Reconciler) Check(configVersion string, pluginInfo PluginInfo) *ErrorIn
 }
 
 func (*Reconciler) CheckRaw(configVersion string, supportedVersions []string) *ErrorIncompatible {
-	for _, supportedVersion := range supportedVersions {
-		if configVersion == supportedVersion {
-			return nil
-		}
+	if contains(supportedVersions, configVersion) {
+		return nil
 	}
 
 	return &ErrorIncompatible{
@@ -47,3 +49,57 @@ func (*Reconciler) CheckRaw(configVersion string, supportedVersions []string) *E
 		Supported: supportedVersions,
 	}
 }
+
+// CheckConstraint checks the config version against a constraint declared
+// by a plugin instead of a list of versions
+func (*Reconciler) CheckConstraint(configVersion string, constraint *Constraint) *ErrorIncompatible {
+	if constraint.Check(configVersion) {
+		return nil
+	}
+	return &ErrorIncompatible{
+		Config:    configVersion,
+		Supported: []string{constraint.String()},
+	}
+}
+
+// Negotiate returns the highest version present in both configVersions and
+// pluginVersions, along with all such versions ordered highest first. It
+// is used for configurations that list several versions in "cniVersions".
+// If there is no common version an *ErrorIncompatible is returned.
+func Negotiate(configVersions, pluginVersions []string) (string, []string, error) {
+	candidates := []string{}
+	for _, v := range configVersions {
+		if contains(pluginVersions, v) && !contains(candidates, v) {
+			candidates = append(candidates, v)
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(candidates, func(i, j int) bool {
+		cmp, err := compareVersions(candidates[i], candidates[j])
+		if err != nil {
+			sortErr = err
+		}
+		return cmp > 0
+	})
+	if sortErr != nil {
+		return "", nil, sortErr
+	}
+
+	if len(candidates) == 0 {
+		return "", nil, &ErrorIncompatible{
+			Config:    strings.Join(configVersions, ","),
+			Supported: pluginVersions,
+		}
+	}
+	return candidates[0], candidates, nil
+}
+
+func contains(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
@@ -49,4 +49,35 @@ var _ = Describe("Reconcile versions of net config with versions supported by pl
 			Expect(err.Error()).To(Equal(`incompatible CNI versions: config is "0.1.0", plugin supports ["1.2.3" "4.3.2"]`))
 		})
 	})
+	Describe("version.Negotiate", func() {
+		It("returns the best common version and all candidates, highest first", func() {
+			best, candidates, err := version.Negotiate(
+				[]string{"0.4.0", "1.1.0", "0.3.1", "1.0.0", "1.1.0"},
+				[]string{"0.3.1", "0.4.0", "1.0.0", "1.1.0"},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(best).To(Equal("1.1.0"))
+			Expect(candidates).To(Equal([]string{"1.1.0", "1.0.0", "0.4.0", "0.3.1"}))
+		})
+
+		It("ignores config versions the plugin does not support", func() {
+			best, candidates, err := version.Negotiate([]string{"0.1.0", "1.2.3", "4.3.2", "5.0.0"}, pluginInfo.SupportedVersions())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(best).To(Equal("4.3.2"))
+			Expect(candidates).To(Equal([]string{"4.3.2", "1.2.3"}))
+		})
+
+		It("returns an ErrorIncompatible when nothing is common", func() {
+			_, _, err := version.Negotiate([]string{"1.1.0"}, []string{"0.4.0"})
+			Expect(err).To(Equal(&version.ErrorIncompatible{
+				Config:    "1.1.0",
+				Supported: []string{"0.4.0"},
+			}))
+		})
+
+		It("fails on unparseable common versions", func() {
+			_, _, err := version.Negotiate([]string{"1.x", "1.0.0"}, []string{"1.x", "1.0.0"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
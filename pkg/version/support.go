@@ -0,0 +1,109 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version
+
+import "fmt"
+
+// SupportLevel describes how well a CNI spec version is supported by
+// this library
+type SupportLevel string
+
+const (
+	// SupportCurrent versions are fully supported
+	SupportCurrent SupportLevel = "current"
+	// SupportDeprecated versions still work but will be removed in a
+	// future release
+	SupportDeprecated SupportLevel = "deprecated"
+	// SupportUnsupported versions are not understood by this library
+	SupportUnsupported SupportLevel = "unsupported"
+)
+
+// minCurrentVersion is the lowest version that is not deprecated
+const minCurrentVersion = "0.3.0"
+
+// Support is the classification of a single CNI spec version
+type Support struct {
+	Version string
+	Level   SupportLevel
+	// Guidance is a human-readable explanation of what, if anything,
+	// operators should do about configurations using this version
+	Guidance string
+}
+
+// Classify reports whether the given config version is current, deprecated
+// or unsupported. A missing version is treated as 0.1.0, as the config
+// decoder does.
+func Classify(version string) Support {
+	if version == "" {
+		s := Classify("0.1.0")
+		s.Guidance = "configuration has no cniVersion and is treated as 0.1.0; " + s.Guidance
+		return s
+	}
+
+	switch {
+	case contains(Legacy.SupportedVersions(), version):
+		return Support{
+			Version:  version,
+			Level:    SupportDeprecated,
+			Guidance: fmt.Sprintf("CNI version %s is deprecated and will be removed in a future release; set cniVersion to %s or later", version, minCurrentVersion),
+		}
+	case contains(All.SupportedVersions(), version):
+		return Support{
+			Version:  version,
+			Level:    SupportCurrent,
+			Guidance: fmt.Sprintf("CNI version %s is supported", version),
+		}
+	default:
+		return Support{
+			Version:  version,
+			Level:    SupportUnsupported,
+			Guidance: fmt.Sprintf("CNI version %s is not supported; use one of %q", version, All.SupportedVersions()),
+		}
+	}
+}
+
+// DeprecationWarning returns the guidance for the given config version if
+// it is deprecated, or an empty string otherwise
+func DeprecationWarning(version string) string {
+	s := Classify(version)
+	if s.Level != SupportDeprecated {
+		return ""
+	}
+	return s.Guidance
+}
+
+// SkewWarning returns a warning if the config version is older than the
+// newest released version the plugin supports, or an empty string
+// otherwise, along with that newest version. Versions newer than Current,
+// which are drafts, and versions which cannot be parsed are ignored.
+func SkewWarning(configVersion string, pluginInfo PluginInfo) (warning, latest string) {
+	for _, v := range pluginInfo.SupportedVersions() {
+		if released, err := GreaterThanOrEqualTo(Current(), v); err != nil || !released {
+			continue
+		}
+		if latest == "" {
+			latest = v
+		} else if newer, _ := GreaterThanOrEqualTo(v, latest); newer {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", ""
+	}
+	if current, err := GreaterThanOrEqualTo(configVersion, latest); err != nil || current {
+		return "", latest
+	}
+	return fmt.Sprintf("CNI version %s is older than %s, the newest version the plugin supports; consider upgrading the network configuration", configVersion, latest), latest
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 CNI authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package version_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/containernetworking/cni/pkg/version"
+)
+
+var _ = Describe("Classifying versions", func() {
+	DescribeTable("support levels",
+		func(v string, expected version.SupportLevel) {
+			Expect(version.Classify(v).Level).To(Equal(expected))
+		},
+		Entry("0.1.0", "0.1.0", version.SupportDeprecated),
+		Entry("0.2.0", "0.2.0", version.SupportDeprecated),
+		Entry("a missing version", "", version.SupportDeprecated),
+		Entry("0.3.0", "0.3.0", version.SupportCurrent),
+		Entry("0.4.0", "0.4.0", version.SupportCurrent),
+		Entry("the current version", version.Current(), version.SupportCurrent),
+		Entry("an unknown version", "0.5.0", version.SupportUnsupported),
+		Entry("a future version", "9.8.7", version.SupportUnsupported),
+	)
+
+	It("gives guidance on migrating deprecated versions", func() {
+		s := version.Classify("0.2.0")
+		Expect(s.Version).To(Equal("0.2.0"))
+		Expect(s.Guidance).To(Equal("CNI version 0.2.0 is deprecated and will be removed in a future release; set cniVersion to 0.3.0 or later"))
+	})
+
+	It("explains how a missing version is interpreted", func() {
+		s := version.Classify("")
+		Expect(s.Version).To(Equal("0.1.0"))
+		Expect(s.Guidance).To(HavePrefix("configuration has no cniVersion and is treated as 0.1.0; "))
+	})
+
+	It("lists the supported versions for unsupported versions", func() {
+		Expect(version.Classify("2.0.0").Guidance).To(ContainSubstring(`"1.1.0"`))
+	})
+
+	It("only returns deprecation warnings for deprecated versions", func() {
+		Expect(version.DeprecationWarning("0.1.0")).NotTo(BeEmpty())
+		Expect(version.DeprecationWarning("1.0.0")).To(BeEmpty())
+		Expect(version.DeprecationWarning("9.8.7")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Version skew", func() {
+	It("warns about configs older than the newest version the plugin supports", func() {
+		warning, latest := version.SkewWarning("0.4.0", version.PluginSupports("0.3.1", "1.0.0", "0.4.0"))
+		Expect(latest).To(Equal("1.0.0"))
+		Expect(warning).To(Equal("CNI version 0.4.0 is older than 1.0.0, the newest version the plugin supports; consider upgrading the network configuration"))
+	})
+
+	It("does not warn about configs of the newest version", func() {
+		warning, latest := version.SkewWarning("1.1.0", version.All)
+		Expect(warning).To(BeEmpty())
+		Expect(latest).To(Equal("1.1.0"))
+	})
+
+	It("ignores draft and unparsable versions", func() {
+		warning, latest := version.SkewWarning("1.1.0", version.PluginSupports("1.0.0", "1.1.0", "1.2.0", "bogus"))
+		Expect(warning).To(BeEmpty())
+		Expect(latest).To(Equal("1.1.0"))
+
+		warning, latest = version.SkewWarning("1.0.0", version.PluginSupports("1.2.0"))
+		Expect(warning).To(BeEmpty())
+		Expect(latest).To(BeEmpty())
+	})
+
+	It("does not warn about unparsable config versions", func() {
+		warning, _ := version.SkewWarning("bogus", version.All)
+		Expect(warning).To(BeEmpty())
+	})
+})
@@ -0,0 +1,25 @@
+#!/usr/bin/env bash
+#
+# Run the benchmarks of the library, which sits on the pod-start critical
+# path, and compare them with a baseline.
+#
+# Usage: scripts/bench.sh [baseline]
+#
+# The results are written to $OUT (bench.txt by default). If a baseline
+# (the saved output of an earlier run) is given, the script fails if any
+# benchmark exceeds it by more than $TOLERANCE (0.1 by default).
+set -euo pipefail
+
+# switch into the repo root directory
+cd "$(dirname $0)/.."
+
+PKGS=${PKGS:-"./libcni ./pkg/skel ./pkg/types/100 ./pkg/version"}
+COUNT=${COUNT:-5}
+OUT=${OUT:-bench.txt}
+TOLERANCE=${TOLERANCE:-0.1}
+
+go test -run '^$' -bench . -benchmem -count "${COUNT}" ${PKGS} | tee "${OUT}"
+
+if [ -n "${1:-}" ]; then
+    go run ./cni-benchcmp -tolerance "${TOLERANCE}" "$1" "${OUT}"
+fi